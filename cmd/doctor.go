@@ -0,0 +1,367 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/mcp"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	"github.com/spf13/cobra"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// doctorTimeout bounds each network check, so that unreachable endpoints fail quickly.
+const doctorTimeout = 20 * time.Second
+
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "ok"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorCheck is the result of a check of the environment.
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	// Remediation tells how to fix a failed check
+	Remediation string
+}
+
+func newDoctorCommand(opt *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the environment is ready to run kubectl-ai",
+		Long: "doctor checks that the cluster is reachable with enough permissions, that the LLM provider accepts the " +
+			"credentials and serves the model, that the prerequisites of the sandbox are met and that the MCP servers " +
+			"answer, printing how to fix what fails. It exits with a non-zero status if a check fails.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doctorOpt := *opt
+			if err := resolveKubeConfigPath(&doctorOpt); err != nil {
+				return fmt.Errorf("failed to resolve kubeconfig path: %w", err)
+			}
+			checks := runDoctorChecks(cmd.Context(), doctorOpt)
+			if err := printDoctorChecks(cmd.OutOrStdout(), checks); err != nil {
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				return err
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// runDoctorChecks checks the cluster, the LLM provider, the sandbox and the MCP servers.
+func runDoctorChecks(ctx context.Context, opt Options) []doctorCheck {
+	checks, clientset := checkKubernetes(ctx, opt.KubeConfigPath)
+	checks = append(checks, checkLLM(ctx, opt)...)
+	checks = append(checks, checkSandbox(ctx, opt, clientset)...)
+	checks = append(checks, checkMCPServers(ctx, opt)...)
+	return checks
+}
+
+// printDoctorChecks writes the checks with the remediation of those that did not pass,
+// and returns an error if any failed.
+func printDoctorChecks(w io.Writer, checks []doctorCheck) error {
+	failed := 0
+	for _, check := range checks {
+		fmt.Fprintf(w, "[%s] %s", check.Status, check.Name)
+		if check.Detail != "" {
+			fmt.Fprintf(w, ": %s", check.Detail)
+		}
+		fmt.Fprintln(w)
+		if check.Status != doctorOK && check.Remediation != "" {
+			fmt.Fprintf(w, "       -> %s\n", check.Remediation)
+		}
+		if check.Status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkKubernetes checks that the cluster of kubeconfig is reachable and what the user
+// can do in it. It returns a client for the cluster if it is reachable.
+func checkKubernetes(ctx context.Context, kubeconfig string) ([]doctorCheck, kubernetes.Interface) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return []doctorCheck{{
+			Name:        "kubeconfig",
+			Status:      doctorFail,
+			Detail:      err.Error(),
+			Remediation: "set --kubeconfig or KUBECONFIG, or write ~/.kube/config, e.g. with `gcloud container clusters get-credentials` or `kind create cluster`",
+		}}, nil
+	}
+	config.Timeout = doctorTimeout
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return []doctorCheck{{Name: "kubeconfig", Status: doctorFail, Detail: err.Error()}}, nil
+	}
+
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return []doctorCheck{{
+			Name:        "cluster",
+			Status:      doctorFail,
+			Detail:      fmt.Sprintf("%s is not reachable: %v", config.Host, err),
+			Remediation: "check the current context (`kubectl config current-context`), the network or VPN, and that the credentials have not expired",
+		}}, nil
+	}
+	checks := []doctorCheck{{Name: "cluster", Status: doctorOK, Detail: fmt.Sprintf("%s at %s", version.GitVersion, config.Host)}}
+
+	for _, access := range []struct {
+		name        string
+		attributes  authorizationv1.ResourceAttributes
+		remediation string
+	}{
+		{
+			name:        "permission to read pods",
+			attributes:  authorizationv1.ResourceAttributes{Verb: "list", Resource: "pods"},
+			remediation: "kubectl-ai sees what your account sees; ask for the view role, e.g. a ClusterRoleBinding to `view`",
+		},
+		{
+			name:        "permission to change deployments",
+			attributes:  authorizationv1.ResourceAttributes{Verb: "update", Resource: "deployments", Group: "apps", Namespace: metav1.NamespaceDefault},
+			remediation: "kubectl-ai will only be able to inspect the cluster; ask for the edit role to let it make changes",
+		},
+	} {
+		allowed, err := canI(ctx, clientset, access.attributes)
+		switch {
+		case err != nil:
+			checks = append(checks, doctorCheck{Name: access.name, Status: doctorWarn, Detail: fmt.Sprintf("could not check: %v", err)})
+		case !allowed:
+			checks = append(checks, doctorCheck{Name: access.name, Status: doctorWarn, Detail: "denied", Remediation: access.remediation})
+		default:
+			checks = append(checks, doctorCheck{Name: access.name, Status: doctorOK})
+		}
+	}
+	return checks, clientset
+}
+
+// canI reports whether the user is allowed the access, like `kubectl auth can-i`.
+func canI(ctx context.Context, clientset kubernetes.Interface, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// checkLLM checks that the providers accept the credentials and serve the models.
+func checkLLM(ctx context.Context, opt Options) []doctorCheck {
+	targets := []gollm.RouteTarget{{Provider: opt.ProviderID, Model: opt.ModelID}}
+	if opt.LLMRouting != nil {
+		targets = opt.LLMRouting.Targets
+		for _, target := range []*gollm.RouteTarget{opt.LLMRouting.LongContext, opt.LLMRouting.Completion} {
+			if target != nil {
+				targets = append(targets, *target)
+			}
+		}
+	}
+
+	var checks []doctorCheck
+	for _, target := range targets {
+		if target.Model == "" {
+			target.Model = opt.ModelID
+		}
+		checks = append(checks, checkLLMTarget(ctx, target, opt.llmClientOptions())...)
+	}
+	return checks
+}
+
+func checkLLMTarget(ctx context.Context, target gollm.RouteTarget, clientOpts []gollm.Option) []doctorCheck {
+	name := fmt.Sprintf("LLM provider %s", target.Provider)
+	client, err := gollm.NewClient(ctx, target.Provider, clientOpts...)
+	if err != nil {
+		return []doctorCheck{{Name: name, Status: doctorFail, Detail: err.Error(), Remediation: providerRemediation(target.Provider)}}
+	}
+	defer client.Close()
+
+	listCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	models, err := client.ListModels(listCtx)
+	if err != nil {
+		var apiErr *gollm.APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+			return []doctorCheck{{Name: name, Status: doctorFail, Detail: "credentials rejected: " + apiErr.Message, Remediation: providerRemediation(target.Provider)}}
+		}
+		return []doctorCheck{{Name: name, Status: doctorWarn, Detail: fmt.Sprintf("could not list the models: %v", err), Remediation: "the provider may not support listing models; check that the model exists"}}
+	}
+	checks := []doctorCheck{{Name: name, Status: doctorOK, Detail: fmt.Sprintf("%d models available", len(models))}}
+
+	if target.Model == "" {
+		return append(checks, doctorCheck{Name: "model", Status: doctorOK, Detail: "the default model of the provider"})
+	}
+	available := slices.ContainsFunc(models, func(model string) bool {
+		return strings.TrimPrefix(model, "models/") == strings.TrimPrefix(target.Model, "models/")
+	})
+	if !available {
+		return append(checks, doctorCheck{
+			Name:        "model " + target.Model,
+			Status:      doctorFail,
+			Detail:      "not available to this account",
+			Remediation: fmt.Sprintf("pick one of the models of %s with --model, e.g. %s", target.Provider, strings.Join(models[:min(len(models), 5)], ", ")),
+		})
+	}
+	return append(checks, doctorCheck{Name: "model " + target.Model, Status: doctorOK})
+}
+
+// providerRemediation tells how to set up the credentials of a provider.
+func providerRemediation(provider string) string {
+	scheme, _, _ := strings.Cut(provider, ":")
+	switch scheme {
+	case "gemini":
+		return "set GEMINI_API_KEY, from https://aistudio.google.com/apikey"
+	case "vertexai":
+		return "run `gcloud auth application-default login` and set GOOGLE_CLOUD_PROJECT"
+	case "openai":
+		return "set OPENAI_API_KEY, and OPENAI_ENDPOINT for OpenAI compatible servers"
+	case "azopenai":
+		return "set AZURE_OPENAI_ENDPOINT, and AZURE_OPENAI_API_KEY unless you use Entra ID"
+	case "anthropic":
+		return "set ANTHROPIC_API_KEY"
+	case "grok":
+		return "set GROK_API_KEY"
+	case "bedrock":
+		return "configure AWS credentials, e.g. with `aws configure`, in a region where the model is enabled"
+	case "ollama":
+		return "start Ollama (`ollama serve`), and set OLLAMA_HOST if it runs on another host"
+	case "llamacpp":
+		return "start the llama.cpp server, and set LLAMACPP_HOST if it is not on localhost"
+	}
+	return "check the provider with --llm-provider"
+}
+
+// checkSandbox checks the prerequisites of the sandbox tools run in.
+func checkSandbox(ctx context.Context, opt Options, clientset kubernetes.Interface) []doctorCheck {
+	switch opt.Sandbox {
+	case "":
+		if _, err := exec.LookPath("kubectl"); err != nil {
+			return []doctorCheck{{Name: "kubectl", Status: doctorFail, Detail: "not found in PATH", Remediation: "install kubectl, see https://kubernetes.io/docs/tasks/tools/"}}
+		}
+		return []doctorCheck{{Name: "kubectl", Status: doctorOK}}
+
+	case "seatbelt":
+		if runtime.GOOS != "darwin" {
+			return []doctorCheck{{Name: "seatbelt sandbox", Status: doctorFail, Detail: "only supported on macOS", Remediation: "use --sandbox k8s, or no sandbox"}}
+		}
+		if _, err := exec.LookPath("sandbox-exec"); err != nil {
+			return []doctorCheck{{Name: "seatbelt sandbox", Status: doctorFail, Detail: "sandbox-exec not found in PATH"}}
+		}
+		return []doctorCheck{{Name: "seatbelt sandbox", Status: doctorOK}}
+
+	case "k8s":
+		name := "k8s sandbox"
+		if clientset == nil {
+			return []doctorCheck{{Name: name, Status: doctorFail, Detail: "needs a reachable cluster", Remediation: "fix the cluster checks above"}}
+		}
+		if _, err := clientset.CoreV1().Namespaces().Get(ctx, sandbox.DefaultNamespace, metav1.GetOptions{}); err != nil {
+			return []doctorCheck{{
+				Name:        name,
+				Status:      doctorFail,
+				Detail:      fmt.Sprintf("namespace %q: %v", sandbox.DefaultNamespace, err),
+				Remediation: fmt.Sprintf("create it with `kubectl create namespace %s`", sandbox.DefaultNamespace),
+			}}
+		}
+		for _, attributes := range []authorizationv1.ResourceAttributes{
+			{Verb: "create", Resource: "pods"},
+			{Verb: "create", Resource: "pods", Subresource: "exec"},
+			{Verb: "create", Resource: "configmaps"},
+		} {
+			attributes.Namespace = sandbox.DefaultNamespace
+			resource := attributes.Resource
+			if attributes.Subresource != "" {
+				resource += "/" + attributes.Subresource
+			}
+			allowed, err := canI(ctx, clientset, attributes)
+			if err != nil || !allowed {
+				return []doctorCheck{{
+					Name:        name,
+					Status:      doctorFail,
+					Detail:      fmt.Sprintf("cannot create %s in namespace %q", resource, sandbox.DefaultNamespace),
+					Remediation: fmt.Sprintf("ask for the edit role in namespace %q", sandbox.DefaultNamespace),
+				}}
+			}
+		}
+		image := opt.SandboxImage
+		if image == "" {
+			image = sandbox.DefaultImage
+		}
+		return []doctorCheck{{Name: name, Status: doctorOK, Detail: fmt.Sprintf("pods of %s in namespace %q", image, sandbox.DefaultNamespace)}}
+	}
+	return []doctorCheck{{Name: "sandbox", Status: doctorFail, Detail: fmt.Sprintf("unknown sandbox %q", opt.Sandbox), Remediation: "use --sandbox k8s or seatbelt, or no sandbox"}}
+}
+
+// checkMCPServers checks that the configured MCP servers answer, if the MCP client is enabled.
+func checkMCPServers(ctx context.Context, opt Options) []doctorCheck {
+	if !opt.MCPClient {
+		return nil
+	}
+	path, err := mcp.DefaultConfigPath()
+	if err != nil {
+		return []doctorCheck{{Name: "MCP config", Status: doctorFail, Detail: err.Error()}}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return []doctorCheck{{Name: "MCP config", Status: doctorWarn, Detail: fmt.Sprintf("%s not found", path), Remediation: "add MCP servers to it, it is created with defaults on the first run"}}
+	}
+	config, err := mcp.LoadConfig(path)
+	if err != nil {
+		return []doctorCheck{{Name: "MCP config", Status: doctorFail, Detail: err.Error(), Remediation: "fix the syntax of " + path}}
+	}
+	if len(opt.MCPServers) > 0 {
+		if err := config.SelectServers(opt.MCPServers); err != nil {
+			return []doctorCheck{{Name: "MCP config", Status: doctorFail, Detail: err.Error(), Remediation: "add the server to " + path + " or remove it from --mcp-servers"}}
+		}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	manager := mcp.NewManager(config)
+	defer manager.Close()
+	var checks []doctorCheck
+	for _, health := range manager.Check(checkCtx) {
+		name := "MCP server " + health.Name
+		if !health.Connected {
+			checks = append(checks, doctorCheck{Name: name, Status: doctorFail, Detail: health.LastError, Remediation: "check its command or URL, and credentials, in " + path})
+			continue
+		}
+		checks = append(checks, doctorCheck{Name: name, Status: doctorOK})
+	}
+	return checks
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/internal/mocks"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckLLMTarget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockClient(ctrl)
+	client.EXPECT().ListModels(gomock.Any()).Return([]string{"models/small", "models/large"}, nil).Times(2)
+	client.EXPECT().Close().Return(nil).Times(2)
+	if err := gollm.RegisterProvider("doctor-test", func(ctx context.Context, opts gollm.ClientOptions) (gollm.Client, error) {
+		return client, nil
+	}); err != nil {
+		t.Fatalf("RegisterProvider() error = %v", err)
+	}
+
+	checks := checkLLMTarget(context.Background(), gollm.RouteTarget{Provider: "doctor-test", Model: "large"}, nil)
+	if len(checks) != 2 || checks[1].Status != doctorOK {
+		t.Errorf("checks of an available model = %+v", checks)
+	}
+	checks = checkLLMTarget(context.Background(), gollm.RouteTarget{Provider: "doctor-test", Model: "huge"}, nil)
+	if len(checks) != 2 || checks[1].Status != doctorFail || !strings.Contains(checks[1].Remediation, "models/small") {
+		t.Errorf("checks of a missing model = %+v, want a failure listing the available models", checks)
+	}
+}
+
+func TestCheckSandboxNamespace(t *testing.T) {
+	opt := Options{Sandbox: "k8s"}
+	checks := checkSandbox(context.Background(), opt, fake.NewClientset())
+	if len(checks) != 1 || checks[0].Status != doctorFail || !strings.Contains(checks[0].Remediation, "kubectl create namespace") {
+		t.Errorf("checks without the sandbox namespace = %+v", checks)
+	}
+
+	// The fake clientset does not allow anything, so the permission check fails next
+	clientset := fake.NewClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "computer"}})
+	checks = checkSandbox(context.Background(), opt, clientset)
+	if len(checks) != 1 || checks[0].Status != doctorFail || !strings.Contains(checks[0].Detail, "cannot create pods") {
+		t.Errorf("checks without permissions = %+v", checks)
+	}
+}
+
+func TestPrintDoctorChecks(t *testing.T) {
+	var out bytes.Buffer
+	err := printDoctorChecks(&out, []doctorCheck{
+		{Name: "cluster", Status: doctorOK, Detail: "v1.31.0"},
+		{Name: "model x", Status: doctorFail, Detail: "not available", Remediation: "pick another"},
+	})
+	if err == nil || err.Error() != "1 of 2 checks failed" {
+		t.Errorf("printDoctorChecks() error = %v, want 1 of 2 checks failed", err)
+	}
+	want := "[ok] cluster: v1.31.0\n[fail] model x: not available\n       -> pick another\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
@@ -15,7 +15,6 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -27,13 +26,19 @@ import (
 	"os/signal"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/gitops"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/notify"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/ui"
@@ -58,6 +63,13 @@ func BuildRootCommand(opt *Options) (*cobra.Command, error) {
 		Short: "A CLI tool to interact with Kubernetes using natural language",
 		Long:  "kubectl-ai is a command-line tool that allows you to interact with your Kubernetes cluster using natural language queries. It leverages large language models to understand your intent and translate it into kubectl",
 		Args:  cobra.MaximumNArgs(1), // Only one positional arg is allowed.
+		// Flags are parsed by now, so the profile only sets the options they leave unset.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := opt.applyProfile(cmd.Flags(), os.Getenv); err != nil {
+				return fmt.Errorf("applying profile: %w", err)
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return RunRootCommand(cmd.Context(), *opt, args)
 		},
@@ -72,7 +84,16 @@ func BuildRootCommand(opt *Options) (*cobra.Command, error) {
 		},
 	})
 
-	if err := opt.bindCLIFlags(rootCmd.Flags()); err != nil {
+	rootCmd.AddCommand(newServeCommand(opt))
+	rootCmd.AddCommand(newMCPServeCommand(opt))
+	rootCmd.AddCommand(newTraceCommand(opt))
+	rootCmd.AddCommand(newDoctorCommand(opt))
+	rootCmd.AddCommand(newSuggestCommand(opt))
+	rootCmd.AddCommand(newShellInitCommand())
+	rootCmd.AddCommand(newScheduleCommand(opt))
+
+	// Flags are persistent so the serve subcommand accepts them too.
+	if err := opt.bindCLIFlags(rootCmd.PersistentFlags()); err != nil {
 		return nil, err
 	}
 	return rootCmd, nil
@@ -81,6 +102,36 @@ func BuildRootCommand(opt *Options) (*cobra.Command, error) {
 type Options struct {
 	ProviderID string `json:"llmProvider,omitempty"`
 	ModelID    string `json:"model,omitempty"`
+	// LLMRouting sends requests to several providers and models, with failover and
+	// routing rules, instead of ProviderID. It can only be set in the config file.
+	LLMRouting *gollm.RoutingConfig `json:"llmRouting,omitempty"`
+	// Profile is the name of the profile of Profiles to apply, also set by $KUBECTL_AI_PROFILE.
+	Profile string `json:"profile,omitempty"`
+	// Profiles are named sets of options, e.g. per cluster or task. They can only be
+	// defined in the config file.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// AllowedNamespaces limits kubectl commands of the agent to these namespaces.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// KubeContexts are the kubeconfig contexts the agent can switch to mid-session, matched
+	// by glob; switching to the ones labeled production needs approval. They can only be set
+	// in the config file.
+	KubeContexts []tools.KubeContext `json:"kubeContexts,omitempty"`
+	// MCPServers limits the MCP client to these servers of the MCP config, and enables it.
+	MCPServers []string `json:"mcpServers,omitempty"`
+	// LLMRetry are the retry policies of LLM requests by provider, overriding the defaults.
+	// It is only set in the config file.
+	LLMRetry map[string]gollm.RetryConfig `json:"llmRetry,omitempty"`
+
+	// Generation parameters of the LLM requests, unset ones keep the provider defaults
+	Temperature     *float32 `json:"temperature,omitempty"`
+	TopP            *float32 `json:"topP,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+	ReasoningEffort string   `json:"reasoningEffort,omitempty"`
+	// IncludeThoughts asks reasoning models for their reasoning, which is journaled
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+	// ShowThoughts shows the reasoning of the model in the UI, collapsed. It implies IncludeThoughts.
+	ShowThoughts bool `json:"showThoughts,omitempty"`
 	// SkipPermissions is a flag to skip asking for confirmation before executing kubectl commands
 	// that modifies resources in the cluster.
 	SkipPermissions bool `json:"skipPermissions,omitempty"`
@@ -88,11 +139,23 @@ type Options struct {
 	// TODO(droot): figure out a better way to discover if the model supports tool use
 	// and set this automatically.
 	EnableToolUseShim bool `json:"enableToolUseShim,omitempty"`
+	// ShimRepairModel is the model rewriting tool use shim responses that do not parse.
+	ShimRepairModel string `json:"shimRepairModel,omitempty"`
+	// SuggestModel is the model suggesting completions of the kubectl commands typed in the UI.
+	SuggestModel string `json:"suggestModel,omitempty"`
+	// Greeting is the message starting new sessions.
+	Greeting string `json:"greeting,omitempty"`
+	// Persona describes the character and tone of the assistant, added to the system prompt.
+	Persona string `json:"persona,omitempty"`
+	// Language is the language of the answers and of the built-in messages, e.g. es or pt-BR.
+	Language string `json:"language,omitempty"`
 	// Quiet flag indicates if the agent should run in non-interactive mode.
 	// It requires a query to be provided as a positional argument.
-	Quiet     bool `json:"quiet,omitempty"`
-	MCPServer bool `json:"mcpServer,omitempty"`
-	MCPClient bool `json:"mcpClient,omitempty"`
+	Quiet bool `json:"quiet,omitempty"`
+	// Output is the output format of quiet mode: text, or json for newline-delimited JSON events.
+	Output    string `json:"output,omitempty"`
+	MCPServer bool   `json:"mcpServer,omitempty"`
+	MCPClient bool   `json:"mcpClient,omitempty"`
 	// ExternalTools enables discovery and exposure of external MCP tools (only works with --mcp-server)
 	ExternalTools bool `json:"externalTools,omitempty"`
 	MaxIterations int  `json:"maxIterations,omitempty"`
@@ -100,6 +163,8 @@ type Options struct {
 	MCPServerMode string `json:"mcpServerMode,omitempty"`
 	// Set the HTTP endpoint port for the MCP server when using HTTP transports like streamable-http.
 	HTTPPort int `json:"httpPort,omitempty"`
+	// MCPReadOnly makes the MCP server refuse tool calls that may modify resources.
+	MCPReadOnly bool `json:"mcpReadOnly,omitempty"`
 	// KubeConfigPath is the path to the kubeconfig file.
 	// If not provided, the default kubeconfig path will be used.
 	KubeConfigPath string `json:"kubeConfigPath,omitempty"`
@@ -107,16 +172,55 @@ type Options struct {
 	PromptTemplateFilePath string   `json:"promptTemplateFilePath,omitempty"`
 	ExtraPromptPaths       []string `json:"extraPromptPaths,omitempty"`
 	TracePath              string   `json:"tracePath,omitempty"`
-	RemoveWorkDir          bool     `json:"removeWorkDir,omitempty"`
-	ToolConfigPaths        []string `json:"toolConfigPaths,omitempty"`
+	// TraceFormat is the format of the trace file: yaml, or jsonl to append JSON lines.
+	TraceFormat string `json:"traceFormat,omitempty"`
+	// TraceSinks are where trace events are written: file (TracePath), stdout and webhook.
+	TraceSinks []string `json:"traceSinks,omitempty"`
+	// TraceWebhookURL receives trace events as JSON POST requests with the webhook sink.
+	TraceWebhookURL string `json:"traceWebhookURL,omitempty"`
+	// TraceRawKeyFile is a file with a hex-encoded AES key. Trace events are always redacted;
+	// with a key, their raw payloads are also stored, encrypted, for break-glass debugging.
+	TraceRawKeyFile string `json:"traceRawKeyFile,omitempty"`
+	// OTelTracing exports OpenTelemetry traces of the agent over OTLP/HTTP.
+	OTelTracing     bool     `json:"otelTracing,omitempty"`
+	RemoveWorkDir   bool     `json:"removeWorkDir,omitempty"`
+	ToolConfigPaths []string `json:"toolConfigPaths,omitempty"`
 
 	// UIType is the type of user interface to use.
 	UIType ui.Type `json:"uiType,omitempty"`
 	// UIListenAddress is the address to listen for the web UI.
 	UIListenAddress string `json:"uiListenAddress,omitempty"`
+	// UIAuthToken is a static token required to access the web UI.
+	UIAuthToken string `json:"uiAuthToken,omitempty"`
+	// UIOIDCIssuerURL and UIOIDCClientID enable OIDC ID token authentication for the web UI.
+	UIOIDCIssuerURL string `json:"uiOIDCIssuerURL,omitempty"`
+	UIOIDCClientID  string `json:"uiOIDCClientID,omitempty"`
+	// UITLSCertFile and UITLSKeyFile serve the web UI over HTTPS.
+	UITLSCertFile string `json:"uiTLSCertFile,omitempty"`
+	UITLSKeyFile  string `json:"uiTLSKeyFile,omitempty"`
+	// UIUsersFile lists the users of a shared web UI server and their tokens (kubectl-ai serve).
+	UIUsersFile string `json:"uiUsersFile,omitempty"`
+	// UIKubeconfigDir holds per-user kubeconfigs that users of a shared server can choose from.
+	UIKubeconfigDir string `json:"uiKubeconfigDir,omitempty"`
+	// UITheme is the TUI color theme: auto, dark, light or custom.
+	UITheme string `json:"uiTheme,omitempty"`
+	// UICustomTheme is the theme used when UITheme is custom.
+	UICustomTheme *ui.Theme `json:"uiCustomTheme,omitempty"`
+	// UIPlainGlyphs draws the TUI with ASCII characters only.
+	UIPlainGlyphs bool `json:"uiPlainGlyphs,omitempty"`
+	// MultiUser is set by the serve subcommand to scope web UI sessions to users.
+	MultiUser bool `json:"-"`
+	// SessionIdleTimeout is how long an agent for a web UI session may stay idle
+	// before it is shut down. It is restarted on the next request for that session.
+	SessionIdleTimeout time.Duration `json:"sessionIdleTimeout,omitempty"`
+	// ShutdownTimeout bounds the graceful shutdown on exit or on SIGINT/SIGTERM: cancelling
+	// requests, saving sessions and tearing down sandboxes.
+	ShutdownTimeout time.Duration `json:"shutdownTimeout,omitempty"`
 
 	// SkipVerifySSL is a flag to skip verifying the SSL certificate of the LLM provider.
 	SkipVerifySSL bool `json:"skipVerifySSL,omitempty"`
+	// LLMCacheDir is a directory to cache LLM responses in, replaying them for identical requests.
+	LLMCacheDir string `json:"llmCacheDir,omitempty"`
 
 	// Session management options
 	ResumeSession  string `json:"resumeSession,omitempty"`
@@ -128,6 +232,39 @@ type Options struct {
 	// ShowToolOutput is a flag to disable truncation of tool output in the terminal UI.
 	ShowToolOutput bool `json:"showToolOutput,omitempty"`
 
+	// GitOpsMode proposes changes to the cluster as commits to its GitOps repository, and pull
+	// requests, instead of applying them. It requires GitOps.
+	GitOpsMode bool `json:"gitOpsMode,omitempty"`
+	// GitOps configures the repository and pull requests of GitOpsMode. It can only be set
+	// in the config file.
+	GitOps *gitops.Config `json:"gitOps,omitempty"`
+
+	// RequireJustification requires the model to explain every tool call in a sentence,
+	// shown in approval prompts and recorded in traces. Calls without are refused.
+	RequireJustification bool `json:"requireJustification,omitempty"`
+
+	// Attach are support bundles to troubleshoot from, directories or archives of manifests
+	// and logs, e.g. of kubectl cluster-info dump --output-directory.
+	Attach []string `json:"attach,omitempty"`
+
+	// Schedules are the checks run by kubectl-ai schedule. They can only be set in the config file.
+	Schedules []Schedule `json:"schedules,omitempty"`
+
+	// NotifyWebhookURL receives notifications as JSON POST requests when tasks complete,
+	// fail or wait for approval.
+	NotifyWebhookURL string `json:"notifyWebhookURL,omitempty"`
+	// NotifySlackWebhookURL is a Slack incoming webhook posting the notifications to its channel.
+	NotifySlackWebhookURL string `json:"notifySlackWebhookURL,omitempty"`
+	// NotifyEvents are the notifications to send: completed, failed, approval-required and
+	// findings-changed. All if empty.
+	NotifyEvents []string `json:"notifyEvents,omitempty"`
+	// NotifyMinDuration is how long interactive requests must run to be notified about.
+	// Tasks run with --quiet are always notified about.
+	NotifyMinDuration time.Duration `json:"notifyMinDuration,omitempty"`
+	// UIExternalURL is the URL users open the web UI at, to link notifications to sessions.
+	// It defaults to UIListenAddress when running the web UI.
+	UIExternalURL string `json:"uiExternalURL,omitempty"`
+
 	// Sandbox enables execution of tools in a sandbox environment.
 	// Supported values: "k8s", "seatbelt".
 	// If empty, tools are executed locally.
@@ -160,18 +297,28 @@ func (o *Options) InitDefaults() {
 	// so we don't need shim.
 	o.EnableToolUseShim = false
 	o.Quiet = false
+	o.Output = "text"
 	o.MCPServer = false
 	o.MaxIterations = 20
 	o.KubeConfigPath = ""
 	o.PromptTemplateFilePath = ""
 	o.ExtraPromptPaths = []string{}
 	o.TracePath = filepath.Join(os.TempDir(), "kubectl-ai-trace.txt")
+	o.TraceFormat = "yaml"
+	o.TraceSinks = []string{"file"}
 	o.RemoveWorkDir = false
 	o.ToolConfigPaths = defaultToolConfigPaths
 	// Default to terminal UI
 	o.UIType = ui.UITypeTerminal
 	// Default UI listen address for HTML UI
 	o.UIListenAddress = "localhost:8888"
+	// Keep the token out of shell history and process listings if desired
+	o.UIAuthToken = os.Getenv("KUBECTL_AI_UI_TOKEN")
+	// Shut down agents for web UI sessions after 30 minutes of inactivity
+	o.SessionIdleTimeout = 30 * time.Minute
+	o.ShutdownTimeout = 30 * time.Second
+	// Do not notify about interactive requests users are likely watching
+	o.NotifyMinDuration = time.Minute
 	// Default to not skipping SSL verification
 	o.SkipVerifySSL = false
 	// Default MCP server mode is stdio
@@ -305,15 +452,30 @@ func run(ctx context.Context) error {
 }
 
 func (opt *Options) bindCLIFlags(f *pflag.FlagSet) error {
+	f.StringVar(&opt.Profile, "profile", opt.Profile, "profile of the config file to apply (defaults to $KUBECTL_AI_PROFILE)")
+	f.StringSliceVar(&opt.AllowedNamespaces, "allowed-namespaces", opt.AllowedNamespaces, "refuse kubectl commands that do not set --namespace to one of these namespaces")
+	f.StringSliceVar(&opt.MCPServers, "mcp-servers", opt.MCPServers, "connect to these servers of the MCP config only (implies --mcp-client)")
 	f.IntVar(&opt.MaxIterations, "max-iterations", opt.MaxIterations, "maximum number of iterations agent will try before giving up")
 	f.StringVar(&opt.KubeConfigPath, "kubeconfig", opt.KubeConfigPath, "path to kubeconfig file")
 	f.StringVar(&opt.PromptTemplateFilePath, "prompt-template-file-path", opt.PromptTemplateFilePath, "path to custom prompt template file")
 	f.StringArrayVar(&opt.ExtraPromptPaths, "extra-prompt-paths", opt.ExtraPromptPaths, "extra prompt template paths")
 	f.StringVar(&opt.TracePath, "trace-path", opt.TracePath, "path to the trace file")
+	f.StringVar(&opt.TraceFormat, "trace-format", opt.TraceFormat, "format of the trace file: yaml, or jsonl to append one JSON event per line")
+	f.StringSliceVar(&opt.TraceSinks, "trace-sinks", opt.TraceSinks, "where to write trace events: file (--trace-path), stdout, webhook (--trace-webhook-url)")
+	f.StringVar(&opt.TraceWebhookURL, "trace-webhook-url", opt.TraceWebhookURL, "URL that trace events are POSTed to as JSON, with the webhook trace sink")
+	f.StringVar(&opt.TraceRawKeyFile, "trace-raw-key-file", opt.TraceRawKeyFile, "file with a hex-encoded AES key to store encrypted raw payloads of redacted trace events, for break-glass debugging")
+	f.BoolVar(&opt.OTelTracing, "otel-tracing", opt.OTelTracing, "export OpenTelemetry traces over OTLP/HTTP, configured with the standard OTEL_EXPORTER_OTLP_* environment variables")
 	f.BoolVar(&opt.RemoveWorkDir, "remove-workdir", opt.RemoveWorkDir, "remove the temporary working directory after execution")
 
 	f.StringVar(&opt.ProviderID, "llm-provider", opt.ProviderID, "language model provider")
 	f.StringVar(&opt.ModelID, "model", opt.ModelID, "language model e.g. gemini-2.0-flash-thinking-exp-01-21, gemini-2.0-flash")
+	f.Var(optionalFloat32{&opt.Temperature}, "temperature", "sampling temperature of the LLM, lower is more deterministic (defaults to the provider's)")
+	f.Var(optionalFloat32{&opt.TopP}, "top-p", "nucleus sampling probability mass of the LLM (defaults to the provider's)")
+	f.IntVar(&opt.MaxOutputTokens, "max-output-tokens", opt.MaxOutputTokens, "maximum tokens of each LLM response (defaults to the provider's)")
+	f.StringArrayVar(&opt.StopSequences, "stop-sequence", opt.StopSequences, "sequence ending LLM responses when generated, can be repeated")
+	f.StringVar(&opt.ReasoningEffort, "reasoning-effort", opt.ReasoningEffort, "how much reasoning models reason before answering: low, medium or high")
+	f.BoolVar(&opt.IncludeThoughts, "include-thoughts", opt.IncludeThoughts, "ask reasoning models for their reasoning and record it in the journal")
+	f.BoolVar(&opt.ShowThoughts, "show-thoughts", opt.ShowThoughts, "show the reasoning of reasoning models in the UI, collapsed (implies --include-thoughts)")
 	f.BoolVar(&opt.SkipPermissions, "skip-permissions", opt.SkipPermissions, "(dangerous) skip asking for confirmation before executing kubectl commands that modify resources")
 	f.BoolVar(&opt.MCPServer, "mcp-server", opt.MCPServer, "run in MCP server mode")
 	f.BoolVar(&opt.ExternalTools, "external-tools", opt.ExternalTools, "in MCP server mode, discover and expose external MCP tools")
@@ -322,13 +484,37 @@ func (opt *Options) bindCLIFlags(f *pflag.FlagSet) error {
 	f.StringVar(&opt.MCPServerMode, "mcp-server-mode", opt.MCPServerMode, "mode of the MCP server. Supported values: stdio, streamable-http")
 	f.IntVar(&opt.HTTPPort, "http-port", opt.HTTPPort, "port for the HTTP endpoint in MCP server mode (used with --mcp-server when --mcp-server-mode is streamable-http)")
 	f.BoolVar(&opt.EnableToolUseShim, "enable-tool-use-shim", opt.EnableToolUseShim, "enable tool use shim")
+	f.StringVar(&opt.ShimRepairModel, "shim-repair-model", opt.ShimRepairModel, "model rewriting tool use shim responses that do not parse (defaults to --model)")
+	f.StringVar(&opt.Greeting, "greeting", opt.Greeting, "message starting new sessions (defaults to a greeting in --language)")
+	f.StringVar(&opt.Persona, "persona", opt.Persona, "character and tone of the assistant, added to the system prompt, e.g. \"a terse SRE who answers in bullet points\"")
+	f.StringVar(&opt.Language, "language", opt.Language, "language of the answers and of the built-in messages, as a tag such as es or pt-BR (defaults to the language of the user)")
+	f.StringVar(&opt.SuggestModel, "suggest-model", opt.SuggestModel, "model suggesting completions of the kubectl commands typed in the terminal and web UIs (defaults to --model)")
 	f.BoolVar(&opt.Quiet, "quiet", opt.Quiet, "run in non-interactive mode, requires a query to be provided as a positional argument")
+	f.StringVar(&opt.Output, "output", opt.Output, "output format of --quiet mode: text, or json to write newline-delimited JSON events to stdout")
 
 	f.Var(&opt.UIType, "ui-type", "user interface type to use. Supported values: terminal, web, tui.")
 	f.StringVar(&opt.UIListenAddress, "ui-listen-address", opt.UIListenAddress, "address to listen for the HTML UI.")
+	f.StringVar(&opt.UIAuthToken, "ui-auth-token", opt.UIAuthToken, "token required to access the HTML UI (defaults to $KUBECTL_AI_UI_TOKEN)")
+	f.StringVar(&opt.UIOIDCIssuerURL, "ui-oidc-issuer-url", opt.UIOIDCIssuerURL, "OIDC issuer whose ID tokens grant access to the HTML UI")
+	f.StringVar(&opt.UIOIDCClientID, "ui-oidc-client-id", opt.UIOIDCClientID, "expected audience of OIDC ID tokens for the HTML UI")
+	f.StringVar(&opt.UITLSCertFile, "ui-tls-cert-file", opt.UITLSCertFile, "TLS certificate file for serving the HTML UI over HTTPS")
+	f.StringVar(&opt.UITLSKeyFile, "ui-tls-key-file", opt.UITLSKeyFile, "TLS key file for serving the HTML UI over HTTPS")
+	f.StringVar(&opt.UITheme, "ui-theme", opt.UITheme, "color theme of the TUI: auto, dark, light, or custom to use uiCustomTheme from the config file. NO_COLOR disables colors.")
+	f.BoolVar(&opt.UIPlainGlyphs, "ui-plain-glyphs", opt.UIPlainGlyphs, "draw the TUI with ASCII characters only, for terminals without box drawing or symbol glyphs")
+	f.DurationVar(&opt.SessionIdleTimeout, "session-idle-timeout", opt.SessionIdleTimeout, "shut down the agent of an idle web UI session after this duration (0 disables eviction)")
+	f.DurationVar(&opt.ShutdownTimeout, "shutdown-timeout", opt.ShutdownTimeout, "how long to wait on exit, or on SIGINT/SIGTERM, for requests to be cancelled, sessions saved and sandboxes torn down")
 	f.BoolVar(&opt.SkipVerifySSL, "skip-verify-ssl", opt.SkipVerifySSL, "skip verifying the SSL certificate of the LLM provider")
+	f.StringVar(&opt.LLMCacheDir, "llm-cache-dir", opt.LLMCacheDir, "cache LLM responses in this directory, and answer identical requests from it (for tests, benchmark replays and prompt iteration)")
 	f.BoolVar(&opt.ShowToolOutput, "show-tool-output", opt.ShowToolOutput, "show tool output in the terminal UI")
 
+	f.BoolVar(&opt.RequireJustification, "require-justification", opt.RequireJustification, "require the model to justify every tool call in a sentence, shown in approval prompts and traces, and refuse calls without")
+	f.StringArrayVar(&opt.Attach, "attach", opt.Attach, "support bundle to troubleshoot from, offline: a directory or archive (.tar.gz, .zip) of manifests and logs, e.g. of kubectl cluster-info dump (can be repeated)")
+	f.BoolVar(&opt.GitOpsMode, "gitops", opt.GitOpsMode, "propose changes as commits to the GitOps repository, and pull requests, instead of applying them (configured with gitOps in the config file)")
+	f.StringVar(&opt.NotifyWebhookURL, "notify-webhook-url", opt.NotifyWebhookURL, "URL that notifications of completed, failed and approval-requiring tasks are POSTed to as JSON")
+	f.StringVar(&opt.NotifySlackWebhookURL, "notify-slack-webhook-url", opt.NotifySlackWebhookURL, "Slack incoming webhook URL to post notifications of completed, failed and approval-requiring tasks to")
+	f.StringSliceVar(&opt.NotifyEvents, "notify-events", opt.NotifyEvents, "notifications to send: completed, failed, approval-required, findings-changed (defaults to all)")
+	f.DurationVar(&opt.NotifyMinDuration, "notify-min-duration", opt.NotifyMinDuration, "only notify about interactive requests running longer than this (tasks run with --quiet are always notified about)")
+	f.StringVar(&opt.UIExternalURL, "ui-external-url", opt.UIExternalURL, "URL of the HTML UI linked to from notifications (defaults to --ui-listen-address with the web UI)")
 	f.StringVar(&opt.Sandbox, "sandbox", opt.Sandbox, "execute tools in a sandbox environment (k8s, seatbelt)")
 	f.StringVar(&opt.SandboxImage, "sandbox-image", opt.SandboxImage, "container image to use for the sandbox")
 
@@ -342,6 +528,32 @@ func (opt *Options) bindCLIFlags(f *pflag.FlagSet) error {
 	return nil
 }
 
+// optionalFloat32 is a flag for a float that stays nil unless it is set.
+type optionalFloat32 struct {
+	value **float32
+}
+
+func (f optionalFloat32) String() string {
+	if f.value == nil || *f.value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(**f.value), 'g', -1, 32)
+}
+
+func (f optionalFloat32) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return err
+	}
+	value := float32(v)
+	*f.value = &value
+	return nil
+}
+
+func (f optionalFloat32) Type() string {
+	return "float"
+}
+
 func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 	var err error
 
@@ -355,6 +567,15 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 	if opt.ExternalTools && !opt.MCPServer {
 		return fmt.Errorf("--external-tools can only be used with --mcp-server")
 	}
+	switch opt.Output {
+	case "", "text":
+	case "json":
+		if !opt.Quiet {
+			return fmt.Errorf("--output json can only be used with --quiet")
+		}
+	default:
+		return fmt.Errorf("--output %q is not supported, must be text or json", opt.Output)
+	}
 
 	// resolve kubeconfig path with priority: flag/env > KUBECONFIG > default path
 	if err = resolveKubeConfigPath(&opt); err != nil {
@@ -376,6 +597,17 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 		return handleDeleteSession(opt)
 	}
 
+	var gitOpsProposer *gitops.Proposer
+	if opt.GitOpsMode {
+		if opt.GitOps == nil {
+			return fmt.Errorf("--gitops requires the gitOps section in the config file")
+		}
+		gitOpsProposer, err = gitops.NewProposer(*opt.GitOps)
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := handleCustomTools(opt.ToolConfigPaths); err != nil {
 		return fmt.Errorf("failed to process custom tools: %w", err)
 	}
@@ -388,31 +620,14 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 	}
 
 	// Handles positional args or stdin
-	var queryFromCmd string
-	queryFromCmd, err = resolveQueryInput(hasInputData, args)
+	queryFromCmd, pipedInput, err := resolveQueryInput(hasInputData, os.Stdin, args)
 	if err != nil {
 		return fmt.Errorf("failed to resolve query input %w", err)
 	}
 
 	klog.Info("Application started", "pid", os.Getpid())
 
-	var recorder journal.Recorder
-	if opt.TracePath != "" {
-		var fileRecorder journal.Recorder
-		fileRecorder, err = journal.NewFileRecorder(opt.TracePath)
-		if err != nil {
-			return fmt.Errorf("creating trace recorder: %w", err)
-		}
-		defer fileRecorder.Close()
-		recorder = fileRecorder
-	} else {
-		// Ensure we always have a recorder, to avoid nil checks
-		recorder = &journal.LogRecorder{}
-		defer recorder.Close()
-	}
-
 	// Initialize session management
-	var session *api.Session
 	var sessionManager *sessions.SessionManager
 
 	sessionManager, err = sessions.NewSessionManager(opt.SessionBackend)
@@ -420,15 +635,51 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 		return fmt.Errorf("failed to create session manager: %w", err)
 	}
 
+	// Repair the sessions of a previous process that crashed mid-request, from the journal
+	// it left, before the new recorder truncates it
+	if _, err := agent.RecoverInterruptedSessions(sessionManager, journalFilePath(opt)); err != nil {
+		klog.Warningf("error recovering interrupted sessions: %v", err)
+	}
+
+	recorder, err := newRecorder(opt)
+	if err != nil {
+		return fmt.Errorf("creating trace recorder: %w", err)
+	}
+	defer recorder.Close()
+
+	notifier, err := newNotifier(opt)
+	if err != nil {
+		return err
+	}
+	if notifier != nil {
+		defer notifier.Close()
+	}
+
+	if opt.OTelTracing {
+		shutdownTracing, err := setupTracing(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			// Flush spans even though ctx may have been cancelled by a signal
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(shutdownCtx); err != nil {
+				klog.Warningf("error flushing traces: %v", err)
+			}
+		}()
+	}
+
+	// The query from the command line only applies to the first agent; agents started
+	// later (other web UI sessions, or sessions restarted after idle eviction) begin idle.
+	var initialQueryOnce sync.Once
+
 	// Build agentFactory for new agents
 	agentFactory := func(ctx context.Context) (*agent.Agent, error) {
-		var client gollm.Client
-		var err error
-		if opt.SkipVerifySSL {
-			client, err = gollm.NewClient(ctx, opt.ProviderID, gollm.WithSkipVerifySSL())
-		} else {
-			client, err = gollm.NewClient(ctx, opt.ProviderID)
-		}
+		var initialQuery, initialPipedInput string
+		initialQueryOnce.Do(func() { initialQuery, initialPipedInput = queryFromCmd, pipedInput })
+
+		client, err := opt.newLLMClient(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("creating llm client: %w", err)
 		}
@@ -443,28 +694,231 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 			ExtraPromptPaths:   opt.ExtraPromptPaths,
 			Tools:              tools.Default(),
 			Recorder:           recorder,
+			Notifier:           notifier,
 			RemoveWorkDir:      opt.RemoveWorkDir,
 			SkipPermissions:    opt.SkipPermissions,
 			EnableToolUseShim:  opt.EnableToolUseShim,
+			ShimRepairModel:    opt.ShimRepairModel,
+			SuggestModel:       opt.SuggestModel,
+			Greeting:           opt.Greeting,
+			Persona:            opt.Persona,
+			Language:           opt.Language,
+			RetryConfigs:       opt.LLMRetry,
+			ShowThoughts:       opt.ShowThoughts,
 			MCPClientEnabled:   opt.MCPClient,
+			MCPServers:         opt.MCPServers,
+			AllowedNamespaces:  opt.AllowedNamespaces,
+			MultiUser:          opt.MultiUser,
+			KubeContexts:       opt.KubeContexts,
+			GitOps:             gitOpsProposer,
+			Attachments:        opt.Attach,
 			Sandbox:            opt.Sandbox,
 			SandboxImage:       opt.SandboxImage,
 			SessionBackend:     opt.SessionBackend,
 			RunOnce:            opt.Quiet,
-			InitialQuery:       queryFromCmd,
+			InitialQuery:       initialQuery,
+			PipedInput:         initialPipedInput,
+			// The web UI and TUI render model text incrementally as it streams in
+			StreamPartialResponses: opt.UIType == ui.UITypeWeb || opt.UIType == ui.UITypeTUI,
+			RequireJustification:   opt.RequireJustification,
 		}, nil
 	}
 
 	agentManager := agent.NewAgentManager(agentFactory, sessionManager)
 
-	// Register cleanup for all sessions and agents
-	defer agentManager.Close()
+	// Shut all agents down on exit, including when a signal cancelled ctx: cancel their
+	// requests, save their sessions and tear down their sandboxes, within a deadline. The
+	// recorder is closed after, flushing the journal.
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), opt.ShutdownTimeout)
+		defer cancel()
+		if err := agentManager.Shutdown(shutdownCtx); err != nil {
+			klog.Warningf("error shutting down agents: %v", err)
+		}
+	}()
+
+	// A shared server has no default session; every user creates their own.
+	var defaultAgent *agent.Agent
+	if !opt.MultiUser {
+		defaultAgent, err = startDefaultAgent(ctx, opt, sessionManager, agentManager)
+		if err != nil {
+			return err
+		}
+	}
+
+	var userInterface ui.UI
+	switch opt.UIType {
+	case ui.UITypeTerminal:
+		if opt.Output == "json" {
+			userInterface = ui.NewJSONUI(defaultAgent, os.Stdout)
+			break
+		}
+		// since stdin is already consumed, we use TTY for taking input from user
+		useTTYForInput := hasInputData
+		userInterface, err = ui.NewTerminalUI(defaultAgent, useTTYForInput, opt.ShowToolOutput, recorder)
+		if err != nil {
+			return fmt.Errorf("creating terminal UI: %w", err)
+		}
+	case ui.UITypeWeb:
+		var htmlOpts []html.Option
+		if opt.UIAuthToken != "" {
+			htmlOpts = append(htmlOpts, html.WithAuthToken(opt.UIAuthToken))
+		}
+		if opt.UIOIDCIssuerURL != "" || opt.UIOIDCClientID != "" {
+			htmlOpts = append(htmlOpts, html.WithOIDC(opt.UIOIDCIssuerURL, opt.UIOIDCClientID))
+		}
+		if opt.UITLSCertFile != "" || opt.UITLSKeyFile != "" {
+			htmlOpts = append(htmlOpts, html.WithTLS(opt.UITLSCertFile, opt.UITLSKeyFile))
+		}
+		if opt.MultiUser {
+			var userTokens map[string]string
+			if opt.UIUsersFile != "" {
+				userTokens, err = loadUserTokens(opt.UIUsersFile)
+				if err != nil {
+					return err
+				}
+			}
+			htmlOpts = append(htmlOpts, html.WithUsers(userTokens))
+			if opt.UIKubeconfigDir != "" {
+				htmlOpts = append(htmlOpts, html.WithKubeconfigDir(opt.UIKubeconfigDir))
+			}
+		}
+		userInterface, err = html.NewHTMLUserInterface(agentManager, sessionManager, opt.ModelID, opt.ProviderID, opt.UIListenAddress, recorder, htmlOpts...)
+		if err != nil {
+			return fmt.Errorf("creating web UI: %w", err)
+		}
+		agentManager.StartIdleEviction(ctx, opt.SessionIdleTimeout)
+	case ui.UITypeTUI:
+		theme, err := ui.ResolveTheme(opt.UITheme, opt.UICustomTheme)
+		if err != nil {
+			return fmt.Errorf("resolving TUI theme: %w", err)
+		}
+		theme.PlainGlyphs = theme.PlainGlyphs || opt.UIPlainGlyphs
+		userInterface = ui.NewTUI(defaultAgent, theme)
+	default:
+		return fmt.Errorf("ui-type mode %q is not known", opt.UIType)
+	}
+
+	err = userInterface.Run(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("running UI: %w", err)
+	}
+
+	return nil
+}
+
+// journalFilePath returns the path of the journal written by the file trace sink, or "" if
+// it is not configured.
+func journalFilePath(opt Options) string {
+	if !slices.Contains(opt.TraceSinks, "file") {
+		return ""
+	}
+	return opt.TracePath
+}
+
+// newRecorder creates the journal recorder for the configured trace sinks.
+func newRecorder(opt Options) (journal.Recorder, error) {
+	var recorders []journal.Recorder
+	fail := func(err error) (journal.Recorder, error) {
+		journal.NewMultiRecorder(recorders...).Close()
+		return nil, err
+	}
+	for _, sink := range opt.TraceSinks {
+		var recorder journal.Recorder
+		switch sink {
+		case "file":
+			if opt.TracePath == "" {
+				continue
+			}
+			var err error
+			switch opt.TraceFormat {
+			case "", "yaml":
+				recorder, err = journal.NewFileRecorder(opt.TracePath)
+			case "jsonl":
+				recorder, err = journal.NewJSONLRecorder(opt.TracePath)
+			default:
+				err = fmt.Errorf("trace format %q is not supported, must be yaml or jsonl", opt.TraceFormat)
+			}
+			if err != nil {
+				return fail(err)
+			}
+		case "stdout":
+			recorder = journal.NewStreamRecorder(os.Stdout)
+		case "webhook":
+			if opt.TraceWebhookURL == "" {
+				return fail(fmt.Errorf("the webhook trace sink requires --trace-webhook-url"))
+			}
+			recorder = journal.NewWebhookRecorder(opt.TraceWebhookURL)
+		default:
+			return fail(fmt.Errorf("trace sink %q is not supported, must be file, stdout or webhook", sink))
+		}
+		recorders = append(recorders, recorder)
+	}
+
+	var recorder journal.Recorder
+	switch len(recorders) {
+	case 0:
+		// Ensure we always have a recorder, to avoid nil checks
+		recorder = &journal.LogRecorder{}
+	case 1:
+		recorder = recorders[0]
+	default:
+		recorder = journal.NewMultiRecorder(recorders...)
+	}
+
+	// Keep secrets out of traces
+	var rawKey []byte
+	if opt.TraceRawKeyFile != "" {
+		var err error
+		if rawKey, err = journal.LoadRawKey(opt.TraceRawKeyFile); err != nil {
+			return fail(err)
+		}
+	}
+	redacting, err := journal.NewRedactingRecorder(recorder, rawKey)
+	if err != nil {
+		return fail(err)
+	}
+	return redacting, nil
+}
+
+// newNotifier creates the notifier of the configured webhooks, or returns nil if there are none.
+func newNotifier(opt Options) (*notify.Notifier, error) {
+	config := notify.Config{
+		WebhookURL:      opt.NotifyWebhookURL,
+		SlackWebhookURL: opt.NotifySlackWebhookURL,
+		MinDuration:     opt.NotifyMinDuration,
+		UIURL:           opt.UIExternalURL,
+	}
+	for _, event := range opt.NotifyEvents {
+		switch eventType := notify.EventType(event); eventType {
+		case notify.EventCompleted, notify.EventFailed, notify.EventApprovalRequired, notify.EventFindingsChanged:
+			config.Events = append(config.Events, eventType)
+		default:
+			return nil, fmt.Errorf("notification %q is not supported, must be completed, failed, approval-required or findings-changed", event)
+		}
+	}
+	if config.UIURL == "" && opt.UIType == ui.UITypeWeb {
+		scheme := "http"
+		if opt.UITLSCertFile != "" {
+			scheme = "https"
+		}
+		config.UIURL = scheme + "://" + opt.UIListenAddress
+	}
+	return notify.New(config), nil
+}
+
+// startDefaultAgent resumes or creates the session used by the terminal UIs
+// (and opened first in the web UI) and returns its agent.
+func startDefaultAgent(ctx context.Context, opt Options, sessionManager *sessions.SessionManager, agentManager *agent.AgentManager) (*agent.Agent, error) {
+	var session *api.Session
+	var defaultAgent *agent.Agent
+	var err error
 
 	if opt.ResumeSession != "" {
 		if opt.ResumeSession == "latest" {
 			session, err = sessionManager.GetLatestSession()
 			if err != nil {
-				return fmt.Errorf("failed to get latest session: %w", err)
+				return nil, fmt.Errorf("failed to get latest session: %w", err)
 			}
 			if session == nil {
 				// No latest session found, create a new one
@@ -473,13 +927,11 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 		} else {
 			session, err = sessionManager.FindSessionByID(opt.ResumeSession)
 			if err != nil {
-				return fmt.Errorf("session %s not found: %w", opt.ResumeSession, err)
+				return nil, fmt.Errorf("session %s not found: %w", opt.ResumeSession, err)
 			}
 		}
 	}
 
-	var defaultAgent *agent.Agent
-
 	// If no session loaded (or resume failed/not requested), create a new one
 	if session == nil {
 		meta := sessions.Metadata{
@@ -488,12 +940,12 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 		}
 		session, err = sessionManager.NewSession(meta)
 		if err != nil {
-			return fmt.Errorf("failed to create a new session: %w", err)
+			return nil, fmt.Errorf("failed to create a new session: %w", err)
 		}
 
 		defaultAgent, err = agentManager.GetAgent(ctx, session.ID)
 		if err != nil {
-			return fmt.Errorf("failed to get agent for new session: %w", err)
+			return nil, fmt.Errorf("failed to get agent for new session: %w", err)
 		}
 		klog.Infof("Created new session: %s\n", session.ID)
 	} else {
@@ -505,36 +957,11 @@ func RunRootCommand(ctx context.Context, opt Options, args []string) error {
 
 		defaultAgent, err = agentManager.GetAgent(ctx, session.ID)
 		if err != nil {
-			return fmt.Errorf("failed to get agent for session: %w", err)
-		}
-	}
-
-	var userInterface ui.UI
-	switch opt.UIType {
-	case ui.UITypeTerminal:
-		// since stdin is already consumed, we use TTY for taking input from user
-		useTTYForInput := hasInputData
-		userInterface, err = ui.NewTerminalUI(defaultAgent, useTTYForInput, opt.ShowToolOutput, recorder)
-		if err != nil {
-			return fmt.Errorf("creating terminal UI: %w", err)
-		}
-	case ui.UITypeWeb:
-		userInterface, err = html.NewHTMLUserInterface(agentManager, sessionManager, opt.ModelID, opt.ProviderID, opt.UIListenAddress, recorder)
-		if err != nil {
-			return fmt.Errorf("creating web UI: %w", err)
+			return nil, fmt.Errorf("failed to get agent for session: %w", err)
 		}
-	case ui.UITypeTUI:
-		userInterface = ui.NewTUI(defaultAgent)
-	default:
-		return fmt.Errorf("ui-type mode %q is not known", opt.UIType)
-	}
-
-	err = userInterface.Run(ctx)
-	if err != nil && !errors.Is(err, context.Canceled) {
-		return fmt.Errorf("running UI: %w", err)
 	}
 
-	return nil
+	return defaultAgent, nil
 }
 
 func handleCustomTools(toolConfigPaths []string) error {
@@ -613,50 +1040,67 @@ func hasStdInData() (bool, error) {
 // It supports:
 // - 1 positional arg only -> kubectl-ai "get pods"
 // - stdin only -> echo "get pods" | kubectl-ai
-// - 1 positional arg + stdin (combined) -> kubectl-ai get <<< "pods" or kubectl-ai "get" <<< "pods"
+// - 1 positional arg + stdin -> kubectl get pods -A | kubectl-ai "why are these pending?"
+// In the last case, stdin is returned as piped input, which the agent gets as context.
 // As default no positional arg nor stdin
-func resolveQueryInput(hasStdInData bool, args []string) (string, error) {
+func resolveQueryInput(hasStdInData bool, stdin io.Reader, args []string) (query string, pipedInput string, err error) {
 	switch {
 	case len(args) == 1 && !hasStdInData:
 		// Use argument directly
-		return args[0], nil
+		return args[0], "", nil
 
 	case len(args) == 1 && hasStdInData:
-		// Combine arg + stdin
-		var b strings.Builder
-		b.WriteString(args[0])
-		b.WriteString("\n")
-
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			b.WriteString(scanner.Text())
-			b.WriteString("\n")
-		}
-		if err := scanner.Err(); err != nil {
-			return "", fmt.Errorf("reading stdin: %w", err)
-		}
-		query := strings.TrimSpace(b.String())
-		if query == "" {
-			return "", fmt.Errorf("no query provided from stdin")
+		// The argument is the query, about the output piped to stdin
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", "", fmt.Errorf("reading stdin: %w", err)
 		}
-		return query, nil
+		return args[0], strings.TrimSpace(string(b)), nil
 
 	case len(args) == 0 && hasStdInData:
 		// Read stdin only
-		b, err := io.ReadAll(os.Stdin)
+		b, err := io.ReadAll(stdin)
 		if err != nil {
-			return "", fmt.Errorf("reading stdin: %w", err)
+			return "", "", fmt.Errorf("reading stdin: %w", err)
 		}
 		query := strings.TrimSpace(string(b))
 		if query == "" {
-			return "", fmt.Errorf("no query provided from stdin")
+			return "", "", fmt.Errorf("no query provided from stdin")
 		}
-		return query, nil
+		return query, "", nil
 
 	default:
 		// Case: No input at all — return empty string, no error
-		return "", nil
+		return "", "", nil
+	}
+}
+
+// newLLMClient creates the LLM client of the agents, routing requests if configured.
+func (o *Options) newLLMClient(ctx context.Context) (gollm.Client, error) {
+	if o.LLMRouting != nil {
+		return gollm.NewRoutingClient(ctx, *o.LLMRouting, o.llmClientOptions()...)
+	}
+	return gollm.NewClient(ctx, o.ProviderID, o.llmClientOptions()...)
+}
+
+// llmClientOptions returns the options of the LLM clients.
+func (o *Options) llmClientOptions() []gollm.Option {
+	var clientOpts []gollm.Option
+	if o.SkipVerifySSL {
+		clientOpts = append(clientOpts, gollm.WithSkipVerifySSL())
+	}
+	if o.LLMCacheDir != "" {
+		clientOpts = append(clientOpts, gollm.WithCacheDir(o.LLMCacheDir))
 	}
+	clientOpts = append(clientOpts, gollm.WithGenerationConfig(gollm.GenerationConfig{
+		Temperature:     o.Temperature,
+		TopP:            o.TopP,
+		MaxOutputTokens: o.MaxOutputTokens,
+		StopSequences:   o.StopSequences,
+		ReasoningEffort: gollm.ReasoningEffort(o.ReasoningEffort),
+		IncludeThoughts: o.IncludeThoughts || o.ShowThoughts,
+	}))
+	return clientOpts
 }
 
 func resolveKubeConfigPath(opt *Options) error {
@@ -694,7 +1138,34 @@ func startMCPServer(ctx context.Context, opt Options) error {
 	if err := os.MkdirAll(workDir, 0o755); err != nil {
 		return fmt.Errorf("error creating work directory: %w", err)
 	}
-	mcpServer, err := newKubectlMCPServer(ctx, opt.KubeConfigPath, tools.Default(), workDir, opt.ExternalTools, opt.MCPServerMode, opt.HTTPPort)
+
+	// Expose the same tools as the agent, running in the configured sandbox
+	executor, err := sandbox.NewExecutor(opt.Sandbox, opt.KubeConfigPath, opt.SandboxImage)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		if err := executor.Close(closeCtx); err != nil {
+			klog.Warningf("error cleaning up executor: %v", err)
+		}
+	}()
+	defaultTools := tools.Default()
+	toolset := defaultTools.CloneWithExecutor(executor)
+	toolset.RegisterTool(tools.NewBashTool(executor))
+	toolset.RegisterTool(tools.NewKubectlTool(executor))
+	toolset.RegisterTool(tools.NewDescribeWorkloadTool(executor))
+	toolset.RegisterTool(tools.NewNodeHealthTool(executor))
+	toolset.RegisterTool(tools.NewRBACCheckTool(executor))
+	toolset.RegisterTool(tools.NewCheckConnectivityTool(executor))
+	toolset.RegisterTool(tools.NewJobStatusTool(executor))
+	toolset.RegisterTool(tools.NewRolloutTool(executor))
+	toolset.RegisterTool(tools.NewObjectGraphTool(executor))
+	toolset.RegisterTool(tools.NewValidateManifestTool(executor))
+	toolset.RegisterTool(tools.NewResourceUsageTool(executor))
+
+	mcpServer, err := newKubectlMCPServer(ctx, opt.KubeConfigPath, toolset, workDir, opt.ExternalTools, opt.MCPServerMode, opt.HTTPPort, opt.MCPReadOnly)
 	if err != nil {
 		return fmt.Errorf("creating mcp server: %w", err)
 	}
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveQueryInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		stdin      string
+		hasStdin   bool
+		args       []string
+		wantQuery  string
+		wantPiped  string
+		wantErrMsg string
+	}{
+		{name: "argument", args: []string{"get pods"}, wantQuery: "get pods"},
+		{name: "stdin", stdin: "get pods\n", hasStdin: true, wantQuery: "get pods"},
+		{name: "empty stdin", stdin: "\n", hasStdin: true, wantErrMsg: "no query provided from stdin"},
+		{name: "argument and piped output", stdin: "NAME   STATUS\nweb-1  Pending\n", hasStdin: true, args: []string{"why are these pending?"}, wantQuery: "why are these pending?", wantPiped: "NAME   STATUS\nweb-1  Pending"},
+		{name: "nothing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, piped, err := resolveQueryInput(tt.hasStdin, strings.NewReader(tt.stdin), tt.args)
+			if tt.wantErrMsg != "" {
+				if err == nil || err.Error() != tt.wantErrMsg {
+					t.Fatalf("resolveQueryInput() error = %v, want %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveQueryInput() error = %v", err)
+			}
+			if query != tt.wantQuery || piped != tt.wantPiped {
+				t.Errorf("resolveQueryInput() = %q, %q, want %q, %q", query, piped, tt.wantQuery, tt.wantPiped)
+			}
+		})
+	}
+}
@@ -34,9 +34,10 @@ type kubectlMCPServer struct {
 	mcpManager    *mcp.Manager // Add MCP manager for external tool calls
 	mcpServerMode string       // Server mode (e.g., "streamable-http", "stdio")
 	httpPort      int          // Port for HTTP-based server modes
+	readOnly      bool         // Refuse tool calls that may modify resources
 }
 
-func newKubectlMCPServer(ctx context.Context, kubectlConfig string, tools tools.Tools, workDir string, exposeExternalTools bool, serverMode string, httpPort int) (*kubectlMCPServer, error) {
+func newKubectlMCPServer(ctx context.Context, kubectlConfig string, tools tools.Tools, workDir string, exposeExternalTools bool, serverMode string, httpPort int, readOnly bool) (*kubectlMCPServer, error) {
 	s := &kubectlMCPServer{
 		kubectlConfig: kubectlConfig,
 		workDir:       workDir,
@@ -48,6 +49,7 @@ func newKubectlMCPServer(ctx context.Context, kubectlConfig string, tools tools.
 		tools:         tools,
 		mcpServerMode: serverMode,
 		httpPort:      httpPort,
+		readOnly:      readOnly,
 	}
 
 	// Add built-in tools
@@ -57,11 +59,16 @@ func newKubectlMCPServer(ctx context.Context, kubectlConfig string, tools tools.
 		if err != nil {
 			return nil, fmt.Errorf("converting tool schema to json.RawMessage: %w", err)
 		}
-		s.server.AddTool(mcpgo.NewToolWithRawSchema(
+		mcpTool := mcpgo.NewToolWithRawSchema(
 			toolDefn.Name,
 			toolDefn.Description,
 			toolInputSchema,
-		), s.handleToolCall)
+		)
+		if readOnly {
+			mcpTool.Annotations.ReadOnlyHint = mcpgo.ToBoolPtr(true)
+			mcpTool.Annotations.DestructiveHint = mcpgo.ToBoolPtr(false)
+		}
+		s.server.AddTool(mcpTool, s.handleToolCall)
 	}
 
 	// Only discover external MCP tools if explicitly enabled
@@ -222,6 +229,20 @@ func (s *kubectlMCPServer) handleBuiltinToolCall(ctx context.Context, request mc
 		}, nil
 	}
 
+	// Apply the same safety checks as the agent before running the tool. There is
+	// no one to ask for confirmation here: clients are expected to ask their users.
+	if interactive, err := tool.IsInteractive(args); interactive {
+		return toolErrorResult(fmt.Sprintf("interactive commands are not supported: %v", err)), nil
+	}
+	modifies := tool.CheckModifiesResource(args)
+	if modifies != "no" {
+		if s.readOnly {
+			klog.Infof("Refused MCP call to %s that may modify resources (modifies=%s)", tool.Name(), modifies)
+			return toolErrorResult("the kubectl-ai MCP server is read-only, and this command may modify resources"), nil
+		}
+		klog.Infof("Running MCP call to %s that may modify resources (modifies=%s)", tool.Name(), modifies)
+	}
+
 	// Execute the built-in tool
 	result, err := tool.Run(ctx, args)
 	if err != nil {
@@ -368,3 +389,16 @@ func (s *kubectlMCPServer) handleExternalMCPToolCall(ctx context.Context, reques
 		},
 	}, nil
 }
+
+// toolErrorResult returns a tool result reporting an error to the client.
+func toolErrorResult(text string) *mcpgo.CallToolResult {
+	return &mcpgo.CallToolResult{
+		IsError: true,
+		Content: []mcpgo.Content{
+			mcpgo.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
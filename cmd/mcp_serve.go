@@ -0,0 +1,48 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newMCPServeCommand(opt *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp-serve",
+		Short: "Expose the kubectl-ai tools as an MCP server",
+		Long: "mcp-serve runs kubectl-ai as an MCP server, so that other agents and IDEs can call its tools (kubectl, bash and custom tools). " +
+			"Tools run in the sandbox selected with --sandbox, and interactive commands are refused. With --read-only, " +
+			"commands that may modify resources are refused too.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch opt.MCPServerMode {
+			case "stdio", "streamable-http":
+			default:
+				return fmt.Errorf("--transport %q is not supported, must be stdio or streamable-http", opt.MCPServerMode)
+			}
+			serveOpt := *opt
+			serveOpt.MCPServer = true
+			return RunRootCommand(cmd.Context(), serveOpt, nil)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opt.MCPServerMode, "transport", opt.MCPServerMode, "transport of the MCP server: stdio or streamable-http (listening on --http-port)")
+	f.BoolVar(&opt.MCPReadOnly, "read-only", opt.MCPReadOnly, "refuse tool calls that may modify resources")
+
+	return cmd
+}
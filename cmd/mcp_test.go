@@ -23,7 +23,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/mcp"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestKubectlMCPServerHTTPClientIntegration(t *testing.T) {
@@ -38,7 +40,7 @@ func TestKubectlMCPServerHTTPClientIntegration(t *testing.T) {
 
 	workDir := t.TempDir()
 
-	server, err := newKubectlMCPServer(ctx, "", toolset, workDir, false, "streamable-http", port)
+	server, err := newKubectlMCPServer(ctx, "", toolset, workDir, false, "streamable-http", port, false)
 	if err != nil {
 		t.Fatalf("failed to create MCP server: %v", err)
 	}
@@ -105,6 +107,37 @@ func TestKubectlMCPServerHTTPClientIntegration(t *testing.T) {
 	}
 }
 
+func TestKubectlMCPServerReadOnly(t *testing.T) {
+	toolset := tools.Tools{}
+	toolset.Init()
+	toolset.RegisterTool(tools.NewKubectlTool(sandbox.NewLocalExecutor()))
+
+	server, err := newKubectlMCPServer(context.Background(), "", toolset, t.TempDir(), false, "stdio", 0, true)
+	if err != nil {
+		t.Fatalf("failed to create MCP server: %v", err)
+	}
+
+	tests := []struct {
+		command string
+		refused bool
+	}{
+		{command: "kubectl delete pod nginx", refused: true},
+		{command: "kubectl edit deployment nginx", refused: true},
+	}
+	for _, tt := range tests {
+		request := mcpgo.CallToolRequest{}
+		request.Params.Name = "kubectl"
+		request.Params.Arguments = map[string]any{"command": tt.command}
+		result, err := server.handleToolCall(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handleToolCall(%q) error = %v", tt.command, err)
+		}
+		if result.IsError != tt.refused {
+			t.Errorf("handleToolCall(%q) IsError = %v, want %v", tt.command, result.IsError, tt.refused)
+		}
+	}
+}
+
 func waitForHTTPServer(t *testing.T, port int) {
 	t.Helper()
 
@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Profile is a named set of options in the config file, selected with --profile.
+type Profile struct {
+	ProviderID        string   `json:"llmProvider,omitempty"`
+	ModelID           string   `json:"model,omitempty"`
+	Sandbox           string   `json:"sandbox,omitempty"`
+	SkipPermissions   *bool    `json:"skipPermissions,omitempty"`
+	ExtraPromptPaths  []string `json:"extraPromptPaths,omitempty"`
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	MCPServers        []string `json:"mcpServers,omitempty"`
+	Greeting          string   `json:"greeting,omitempty"`
+	Persona           string   `json:"persona,omitempty"`
+	Language          string   `json:"language,omitempty"`
+}
+
+// profileEnv reads the environment variables overriding the options of profiles.
+// Lists are comma separated.
+func profileEnv(getenv func(string) string) (Profile, error) {
+	env := Profile{
+		ProviderID:        getenv("KUBECTL_AI_LLM_PROVIDER"),
+		ModelID:           getenv("KUBECTL_AI_MODEL"),
+		Sandbox:           getenv("KUBECTL_AI_SANDBOX"),
+		ExtraPromptPaths:  splitList(getenv("KUBECTL_AI_EXTRA_PROMPT_PATHS")),
+		AllowedNamespaces: splitList(getenv("KUBECTL_AI_ALLOWED_NAMESPACES")),
+		MCPServers:        splitList(getenv("KUBECTL_AI_MCP_SERVERS")),
+		Greeting:          getenv("KUBECTL_AI_GREETING"),
+		Persona:           getenv("KUBECTL_AI_PERSONA"),
+		Language:          getenv("KUBECTL_AI_LANGUAGE"),
+	}
+	if s := getenv("KUBECTL_AI_SKIP_PERMISSIONS"); s != "" {
+		skip, err := strconv.ParseBool(s)
+		if err != nil {
+			return Profile{}, fmt.Errorf("parsing KUBECTL_AI_SKIP_PERMISSIONS: %w", err)
+		}
+		env.SkipPermissions = &skip
+	}
+	return env, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// applyProfile applies the selected profile over the options of the config file, then the
+// environment variables over the profile. Options set with flags are kept.
+func (o *Options) applyProfile(flags *pflag.FlagSet, getenv func(string) string) error {
+	name := o.Profile
+	if env := getenv("KUBECTL_AI_PROFILE"); env != "" && !flags.Changed("profile") {
+		name = env
+	}
+	var profile Profile
+	if name != "" {
+		p, ok := o.Profiles[name]
+		if !ok {
+			return fmt.Errorf("profile %q is not defined in the config file, defined profiles: %s", name, strings.Join(slices.Sorted(maps.Keys(o.Profiles)), ", "))
+		}
+		profile = p
+	}
+
+	env, err := profileEnv(getenv)
+	if err != nil {
+		return err
+	}
+	for _, layer := range []Profile{profile, env} {
+		if layer.ProviderID != "" && !flags.Changed("llm-provider") {
+			o.ProviderID = layer.ProviderID
+		}
+		if layer.ModelID != "" && !flags.Changed("model") {
+			o.ModelID = layer.ModelID
+		}
+		if layer.Sandbox != "" && !flags.Changed("sandbox") {
+			o.Sandbox = layer.Sandbox
+		}
+		if layer.SkipPermissions != nil && !flags.Changed("skip-permissions") {
+			o.SkipPermissions = *layer.SkipPermissions
+		}
+		if layer.ExtraPromptPaths != nil && !flags.Changed("extra-prompt-paths") {
+			o.ExtraPromptPaths = layer.ExtraPromptPaths
+		}
+		if layer.AllowedNamespaces != nil && !flags.Changed("allowed-namespaces") {
+			o.AllowedNamespaces = layer.AllowedNamespaces
+		}
+		if layer.MCPServers != nil && !flags.Changed("mcp-servers") {
+			o.MCPServers = layer.MCPServers
+		}
+		if layer.Greeting != "" && !flags.Changed("greeting") {
+			o.Greeting = layer.Greeting
+		}
+		if layer.Persona != "" && !flags.Changed("persona") {
+			o.Persona = layer.Persona
+		}
+		if layer.Language != "" && !flags.Changed("language") {
+			o.Language = layer.Language
+		}
+	}
+	// Selecting MCP servers implies connecting to them
+	if len(o.MCPServers) > 0 {
+		o.MCPClient = true
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestApplyProfile(t *testing.T) {
+	var opt Options
+	opt.InitDefaults()
+	if err := opt.LoadConfiguration([]byte(`
+model: gemini-2.5-flash
+profile: dev
+profiles:
+  dev:
+    model: gemini-2.5-pro
+    skipPermissions: true
+    allowedNamespaces: [dev]
+  prod:
+    llmProvider: openai
+    model: gpt-4.1
+    sandbox: k8s
+    allowedNamespaces: [prod, monitoring]
+    mcpServers: [docs]
+    language: fr
+`)); err != nil {
+		t.Fatalf("LoadConfiguration() error = %v", err)
+	}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := opt.bindCLIFlags(flags); err != nil {
+		t.Fatalf("bindCLIFlags() error = %v", err)
+	}
+	// The flag wins over the profile, the environment over the profile selected in the config
+	if err := flags.Parse([]string{"--model", "gpt-5"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	env := map[string]string{
+		"KUBECTL_AI_PROFILE":            "prod",
+		"KUBECTL_AI_SKIP_PERMISSIONS":   "false",
+		"KUBECTL_AI_ALLOWED_NAMESPACES": "prod, staging",
+	}
+	if err := opt.applyProfile(flags, func(key string) string { return env[key] }); err != nil {
+		t.Fatalf("applyProfile() error = %v", err)
+	}
+
+	if opt.ProviderID != "openai" || opt.ModelID != "gpt-5" || opt.Sandbox != "k8s" || opt.SkipPermissions {
+		t.Errorf("options = provider %q, model %q, sandbox %q, skip permissions %v", opt.ProviderID, opt.ModelID, opt.Sandbox, opt.SkipPermissions)
+	}
+	if !slices.Equal(opt.AllowedNamespaces, []string{"prod", "staging"}) {
+		t.Errorf("AllowedNamespaces = %v, want the environment variable", opt.AllowedNamespaces)
+	}
+	if opt.Language != "fr" {
+		t.Errorf("Language = %q, want the language of the profile", opt.Language)
+	}
+	if !slices.Equal(opt.MCPServers, []string{"docs"}) || !opt.MCPClient {
+		t.Errorf("MCPServers = %v, MCPClient = %v, want the servers of the profile, enabled", opt.MCPServers, opt.MCPClient)
+	}
+
+	opt.Profile = "missing"
+	if err := opt.applyProfile(flags, func(string) string { return "" }); err == nil {
+		t.Errorf("applyProfile() of an undefined profile succeeded")
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/cron"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/notify"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// Schedule is a check run by kubectl-ai schedule, configured in the config file.
+type Schedule struct {
+	// Name identifies the check, and its sessions.
+	Name string `json:"name"`
+	// Cron is when to run the check, e.g. "0 2 * * *" or "@daily", in local time.
+	Cron string `json:"cron"`
+	// Prompt is what to check, e.g. "find pods without resource limits".
+	Prompt string `json:"prompt"`
+}
+
+// scheduledCheck is a Schedule with its parsed cron expression.
+type scheduledCheck struct {
+	Schedule
+	cron *cron.Schedule
+}
+
+// findingsInstructions are appended to the prompts of scheduled checks, so that their
+// answers can be compared from one run to the next.
+const findingsInstructions = "\n\nReport the findings as a list, one per line and sorted, without introduction or conclusion, " +
+	"or answer exactly \"No findings.\" if there are none. The findings are compared with those of the previous run."
+
+func newScheduleCommand(opt *Options) *cobra.Command {
+	var once bool
+	cmd := &cobra.Command{
+		Use:   "schedule [name...]",
+		Short: "Run the checks scheduled in the config file",
+		Long: "schedule runs the prompts of the schedules of the config file on their cron schedule, until interrupted. " +
+			"The checks run once each, with tools that do not modify resources, and are stored as sessions named \"Scheduled: <name>\". " +
+			"When the findings of a check differ from its previous run, or it fails, the configured notifications are sent.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checks, err := selectSchedules(opt.Schedules, args)
+			if err != nil {
+				return err
+			}
+			return runSchedules(cmd.Context(), *opt, checks, once, cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().BoolVar(&once, "once", false, "run the checks once now and exit, e.g. from a Kubernetes CronJob")
+	return cmd
+}
+
+// selectSchedules parses the schedules named, or all if names is empty.
+func selectSchedules(schedules []Schedule, names []string) ([]scheduledCheck, error) {
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("no schedules are configured, add them to the schedules of the config file")
+	}
+	var checks []scheduledCheck
+	for _, s := range schedules {
+		if len(names) > 0 && !slices.Contains(names, s.Name) {
+			continue
+		}
+		if s.Name == "" || s.Prompt == "" {
+			return nil, fmt.Errorf("schedule %q must have a name and a prompt", s.Name)
+		}
+		c, err := cron.Parse(s.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", s.Name, err)
+		}
+		checks = append(checks, scheduledCheck{Schedule: s, cron: c})
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(checks, func(c scheduledCheck) bool { return c.Name == name }) {
+			return nil, fmt.Errorf("schedule %q is not defined in the config file", name)
+		}
+	}
+	return checks, nil
+}
+
+func runSchedules(ctx context.Context, opt Options, checks []scheduledCheck, once bool, out io.Writer) error {
+	sessionManager, err := sessions.NewSessionManager(opt.SessionBackend)
+	if err != nil {
+		return fmt.Errorf("failed to create session manager: %w", err)
+	}
+	recorder, err := newRecorder(opt)
+	if err != nil {
+		return fmt.Errorf("creating trace recorder: %w", err)
+	}
+	defer recorder.Close()
+	notifier, err := newNotifier(opt)
+	if err != nil {
+		return err
+	}
+	if notifier != nil {
+		defer notifier.Close()
+	}
+
+	if once {
+		var errs []error
+		for _, check := range checks {
+			if err := runScheduledCheck(ctx, opt, check.Schedule, sessionManager, recorder, notifier, out); err != nil {
+				errs = append(errs, fmt.Errorf("schedule %q: %w", check.Name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	next := make([]time.Time, len(checks))
+	for i, check := range checks {
+		next[i] = check.cron.Next(time.Now())
+	}
+	for {
+		i := -1
+		for j, t := range next {
+			if !t.IsZero() && (i < 0 || t.Before(next[i])) {
+				i = j
+			}
+		}
+		if i < 0 {
+			return fmt.Errorf("none of the schedules will run again")
+		}
+		klog.Infof("Next scheduled check %q at %s", checks[i].Name, next[i].Format(time.RFC3339))
+		timer := time.NewTimer(time.Until(next[i]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		// Failures are notified about, and the other checks keep running
+		if err := runScheduledCheck(ctx, opt, checks[i].Schedule, sessionManager, recorder, notifier, out); err != nil {
+			klog.Errorf("Scheduled check %q failed: %v", checks[i].Name, err)
+		}
+		next[i] = checks[i].cron.Next(time.Now())
+	}
+}
+
+// runScheduledCheck runs the prompt of s in a new session with read-only tools, and
+// notifies if its findings differ from the previous session of s, or if it fails.
+func runScheduledCheck(ctx context.Context, opt Options, s Schedule, sessionManager *sessions.SessionManager, recorder journal.Recorder, notifier *notify.Notifier, out io.Writer) error {
+	name := scheduleSessionName(s.Name)
+	previous, err := previousFindings(sessionManager, name)
+	if err != nil {
+		return err
+	}
+	session, err := sessionManager.NewSession(sessions.Metadata{ProviderID: opt.ProviderID, ModelID: opt.ModelID})
+	if err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+	if err := sessionManager.RenameSession(session.ID, name); err != nil {
+		return fmt.Errorf("naming session: %w", err)
+	}
+	session.Name = name
+
+	started := time.Now()
+	findings, err := runCheck(ctx, opt, s.Prompt, session, recorder)
+	event := notify.Event{
+		SessionID:       session.ID,
+		Query:           s.Prompt,
+		DurationSeconds: time.Since(started).Seconds(),
+	}
+	if err != nil {
+		fmt.Fprintf(out, "%s: failed (session %s): %v\n", s.Name, session.ID, err)
+		if notifier != nil {
+			event.Type, event.Summary = notify.EventFailed, err.Error()
+			notifier.Notify(event)
+		}
+		return err
+	}
+	if normalizeFindings(findings) == normalizeFindings(previous) {
+		fmt.Fprintf(out, "%s: findings unchanged (session %s)\n", s.Name, session.ID)
+		return nil
+	}
+	// The findings of the first run are new too
+	fmt.Fprintf(out, "%s: findings changed (session %s)\n%s\n", s.Name, session.ID, findings)
+	if notifier != nil {
+		event.Type, event.Summary = notify.EventFindingsChanged, findings
+		notifier.Notify(event)
+	}
+	return nil
+}
+
+// runCheck runs prompt in session with an agent refusing commands that modify resources.
+func runCheck(ctx context.Context, opt Options, prompt string, session *api.Session, recorder journal.Recorder) (string, error) {
+	client, err := opt.newLLMClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("creating llm client: %w", err)
+	}
+	a := &agent.Agent{
+		Model:                opt.ModelID,
+		Provider:             opt.ProviderID,
+		Kubeconfig:           opt.KubeConfigPath,
+		LLM:                  client,
+		MaxIterations:        opt.MaxIterations,
+		PromptTemplateFile:   opt.PromptTemplateFilePath,
+		ExtraPromptPaths:     opt.ExtraPromptPaths,
+		Tools:                tools.Default(),
+		Recorder:             recorder,
+		RemoveWorkDir:        opt.RemoveWorkDir,
+		EnableToolUseShim:    opt.EnableToolUseShim,
+		ShimRepairModel:      opt.ShimRepairModel,
+		RetryConfigs:         opt.LLMRetry,
+		MCPClientEnabled:     opt.MCPClient,
+		MCPServers:           opt.MCPServers,
+		AllowedNamespaces:    opt.AllowedNamespaces,
+		ReadOnly:             true,
+		RequireJustification: opt.RequireJustification,
+		Language:             opt.Language,
+		Sandbox:              opt.Sandbox,
+		SandboxImage:         opt.SandboxImage,
+		SessionBackend:       opt.SessionBackend,
+		RunOnce:              true,
+		InitialQuery:         prompt + findingsInstructions,
+		Session:              session,
+	}
+	return runOnce(ctx, a)
+}
+
+func scheduleSessionName(name string) string {
+	return "Scheduled: " + name
+}
+
+// previousFindings returns the last answer of the most recent session named name, if any.
+func previousFindings(sessionManager *sessions.SessionManager, name string) (string, error) {
+	all, err := sessionManager.ListSessions()
+	if err != nil {
+		return "", fmt.Errorf("listing sessions: %w", err)
+	}
+	var latest *api.Session
+	for _, session := range all {
+		if session.Name == name && (latest == nil || session.CreatedAt.After(latest.CreatedAt)) {
+			latest = session
+		}
+	}
+	if latest == nil || latest.ChatMessageStore == nil {
+		return "", nil
+	}
+	var findings string
+	for _, m := range latest.ChatMessageStore.ChatMessages() {
+		if text, ok := m.Payload.(string); ok && m.Source == api.MessageSourceModel && m.Type == api.MessageTypeText {
+			findings = text
+		}
+	}
+	return findings, nil
+}
+
+// normalizeFindings ignores differences in whitespace between findings.
+func normalizeFindings(findings string) string {
+	var lines []string
+	for _, line := range strings.Split(findings, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
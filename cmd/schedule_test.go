@@ -0,0 +1,75 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+)
+
+func TestSelectSchedules(t *testing.T) {
+	schedules := []Schedule{
+		{Name: "limits", Cron: "@daily", Prompt: "find pods without resource limits"},
+		{Name: "restarts", Cron: "*/30 * * * *", Prompt: "find pods restarting often"},
+	}
+	checks, err := selectSchedules(schedules, []string{"restarts"})
+	if err != nil {
+		t.Fatalf("selectSchedules() error = %v", err)
+	}
+	if len(checks) != 1 || checks[0].Name != "restarts" {
+		t.Errorf("selectSchedules() = %v, want the restarts check", checks)
+	}
+	if _, err := selectSchedules(schedules, []string{"backups"}); err == nil {
+		t.Errorf("selectSchedules() of an undefined schedule succeeded")
+	}
+	if _, err := selectSchedules([]Schedule{{Name: "limits", Cron: "daily", Prompt: "find pods without resource limits"}}, nil); err == nil {
+		t.Errorf("selectSchedules() with an invalid cron expression succeeded")
+	}
+}
+
+func TestPreviousFindings(t *testing.T) {
+	sessionManager, err := sessions.NewSessionManager("memory")
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+	name := scheduleSessionName("limits")
+	for _, findings := range []string{"default/web has no limits", "default/web has no limits\ndefault/db has no limits"} {
+		session, err := sessionManager.NewSession(sessions.Metadata{})
+		if err != nil {
+			t.Fatalf("NewSession() error = %v", err)
+		}
+		if err := sessionManager.RenameSession(session.ID, name); err != nil {
+			t.Fatalf("RenameSession() error = %v", err)
+		}
+		session, _ = sessionManager.FindSessionByID(session.ID)
+		session.ChatMessageStore.AddChatMessage(&api.Message{Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: findings})
+		// Sessions are ordered by creation time
+		time.Sleep(time.Millisecond)
+	}
+
+	got, err := previousFindings(sessionManager, name)
+	if err != nil {
+		t.Fatalf("previousFindings() error = %v", err)
+	}
+	if want := "default/web has no limits\n  default/db has no limits  "; normalizeFindings(got) != normalizeFindings(want) {
+		t.Errorf("previousFindings() = %q, want the findings of the latest run", got)
+	}
+	if got, _ := previousFindings(sessionManager, scheduleSessionName("restarts")); got != "" {
+		t.Errorf("previousFindings() of a schedule that never ran = %q", got)
+	}
+}
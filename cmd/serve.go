@@ -0,0 +1,89 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/ui"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// usersFile is the format of the file passed to --users-file.
+//
+//	users:
+//	- name: alice
+//	  token: <random secret>
+type usersFile struct {
+	Users []struct {
+		Name  string `json:"name"`
+		Token string `json:"token"`
+	} `json:"users"`
+}
+
+func newServeCommand(opt *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the web UI to multiple users",
+		Long: "serve runs the web UI as a shared server. Every user authenticates with their own token (--users-file) " +
+			"or OIDC identity, only sees their own sessions, and can pick one of their kubeconfigs (--kubeconfig-dir) for each session. " +
+			"All agents run their tools on the server as the same OS user, so the bash tool and /run are not available, and kubectl commands " +
+			"that could reach past the kubeconfig of the user (other commands, local files, --kubeconfig, --context) are refused.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serveOpt := *opt
+			serveOpt.UIType = ui.UITypeWeb
+			serveOpt.MultiUser = true
+			return RunRootCommand(cmd.Context(), serveOpt, nil)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opt.UIUsersFile, "users-file", opt.UIUsersFile, "YAML file listing the users of the server and their tokens")
+	f.StringVar(&opt.UIKubeconfigDir, "kubeconfig-dir", opt.UIKubeconfigDir, "directory with a subdirectory of kubeconfig files per user, e.g. <dir>/alice/prod.yaml")
+
+	return cmd
+}
+
+// loadUserTokens reads a users file and returns a map of token to user name.
+func loadUserTokens(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading users file: %w", err)
+	}
+	var f usersFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parsing users file %q: %w", path, err)
+	}
+
+	tokens := make(map[string]string, len(f.Users))
+	names := make(map[string]bool, len(f.Users))
+	for i, user := range f.Users {
+		if user.Name == "" || user.Token == "" {
+			return nil, fmt.Errorf("user #%d in %q must have a name and a token", i+1, path)
+		}
+		if names[user.Name] {
+			return nil, fmt.Errorf("user %q is listed more than once in %q", user.Name, path)
+		}
+		if _, ok := tokens[user.Token]; ok {
+			return nil, fmt.Errorf("user %q reuses the token of another user in %q", user.Name, path)
+		}
+		names[user.Name] = true
+		tokens[user.Token] = user.Name
+	}
+	return tokens, nil
+}
@@ -0,0 +1,38 @@
+# kubectl-ai shell integration for bash, load it with: eval "$(kubectl-ai shell-init bash)"
+#
+# It records the command and error output of the last failed kubectl command, and binds
+# Ctrl-X Ctrl-K to replace the command line with the suggestion of kubectl-ai suggest:
+# a completion of the command being typed, or, on an empty line, a fix of the failed command.
+
+_kubectl_ai_dir="${TMPDIR:-/tmp}/kubectl-ai-${UID}"
+mkdir -p "$_kubectl_ai_dir" && chmod 700 "$_kubectl_ai_dir"
+_kubectl_ai_stderr="$_kubectl_ai_dir/stderr.$$"
+_kubectl_ai_command="$_kubectl_ai_dir/command.$$"
+
+kubectl() {
+  command kubectl "$@" 2> >(tee "$_kubectl_ai_stderr" >&2)
+  local exit_status=$?
+  if [ "$exit_status" -ne 0 ]; then
+    { printf 'kubectl'; printf ' %q' "$@"; printf '\n'; } > "$_kubectl_ai_command"
+  else
+    rm -f "$_kubectl_ai_command"
+  fi
+  return "$exit_status"
+}
+
+_kubectl_ai_suggest() {
+  local suggestion
+  if [ -n "$READLINE_LINE" ]; then
+    suggestion=$(kubectl-ai suggest "$READLINE_LINE" </dev/null)
+  elif [ -f "$_kubectl_ai_command" ]; then
+    suggestion=$(kubectl-ai suggest --command "$(cat "$_kubectl_ai_command")" --stderr-file "$_kubectl_ai_stderr" </dev/null)
+  else
+    return
+  fi
+  if [ -n "$suggestion" ]; then
+    READLINE_LINE=$suggestion
+    READLINE_POINT=${#READLINE_LINE}
+  fi
+}
+
+bind -x '"\C-x\C-k": _kubectl_ai_suggest'
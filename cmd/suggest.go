@@ -0,0 +1,228 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+//go:embed suggest_prompt.txt
+var suggestPromptTemplate string
+
+//go:embed shell/kubectl-ai.bash
+var bashIntegration string
+
+//go:embed shell/kubectl-ai.zsh
+var zshIntegration string
+
+// maxSuggestStderr limits the error output sent to the LLM, keeping its end.
+const maxSuggestStderr = 4096
+
+func newSuggestCommand(opt *Options) *cobra.Command {
+	var command, stderrFile string
+	cmd := &cobra.Command{
+		Use:   "suggest [partial command or error]",
+		Short: "Suggest a command completing a partial command or fixing a failed one",
+		Long: "suggest prints a single corrected or completed command, asking the LLM once without running any tool. " +
+			"Pass the failed command with --command and its error output with --stderr-file or on stdin. " +
+			"The shell integration of 'kubectl-ai shell-init' binds it to Ctrl-X Ctrl-K.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var text string
+			if len(args) == 1 {
+				text = args[0]
+			}
+			stderr, err := readSuggestStderr(stderrFile)
+			if err != nil {
+				return err
+			}
+			query := suggestQuery(text, command, stderr)
+			if query == "" {
+				return fmt.Errorf("nothing to suggest a command for, pass a partial command, an error or --command")
+			}
+
+			suggestion, err := suggest(cmd.Context(), *opt, query)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), suggestion)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&command, "command", "", "the command that failed")
+	f.StringVar(&stderrFile, "stderr-file", "", "file with the error output of the command (defaults to stdin, if it is not a terminal)")
+	return cmd
+}
+
+func newShellInitCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell-init bash|zsh",
+		Short: "Print the shell integration of kubectl-ai suggest",
+		Long: "shell-init prints a script to evaluate in the shell, e.g. eval \"$(kubectl-ai shell-init zsh)\" in ~/.zshrc. " +
+			"It records the error output of failed kubectl commands, and binds Ctrl-X Ctrl-K to replace the command line " +
+			"with a suggestion: a completion of the command being typed, or a fix of the last failed kubectl command.",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				fmt.Fprint(cmd.OutOrStdout(), bashIntegration)
+			case "zsh":
+				fmt.Fprint(cmd.OutOrStdout(), zshIntegration)
+			default:
+				return fmt.Errorf("unsupported shell %q, supported shells: bash, zsh", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+func readSuggestStderr(path string) (string, error) {
+	var b []byte
+	var err error
+	if path != "" {
+		b, err = os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("reading error output: %w", err)
+		}
+	} else if hasData, _ := hasStdInData(); hasData {
+		b, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("reading error output from stdin: %w", err)
+		}
+	}
+	stderr := strings.TrimSpace(string(b))
+	if len(stderr) > maxSuggestStderr {
+		stderr = "..." + stderr[len(stderr)-maxSuggestStderr:]
+	}
+	return stderr, nil
+}
+
+// suggestQuery describes what to suggest a command for, or returns "" if there is nothing.
+func suggestQuery(text, command, stderr string) string {
+	var b strings.Builder
+	if command != "" {
+		fmt.Fprintf(&b, "Failed command:\n%s\n\n", command)
+	}
+	if stderr != "" {
+		fmt.Fprintf(&b, "Error output:\n%s\n\n", stderr)
+	}
+	if text != "" {
+		if command == "" && stderr == "" {
+			fmt.Fprintf(&b, "Partial command or error:\n%s\n", text)
+		} else {
+			fmt.Fprintf(&b, "Note from the user:\n%s\n", text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// suggest asks the LLM for a command in a single turn of an agent without tools.
+func suggest(ctx context.Context, opt Options, query string) (string, error) {
+	client, err := opt.newLLMClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("creating llm client: %w", err)
+	}
+
+	a := &agent.Agent{
+		Model:          opt.ModelID,
+		Provider:       opt.ProviderID,
+		LLM:            client,
+		MaxIterations:  1,
+		PromptTemplate: suggestPromptTemplate,
+		DisableTools:   true,
+		RetryConfigs:   opt.LLMRetry,
+		RunOnce:        true,
+		InitialQuery:   query,
+		Session:        &api.Session{},
+	}
+	answer, err := runOnce(ctx, a)
+	if err != nil {
+		return "", err
+	}
+	suggestion := cleanSuggestion(answer)
+	if suggestion == "" {
+		return "", fmt.Errorf("the model did not suggest a command")
+	}
+	return suggestion, nil
+}
+
+// runOnce initializes and runs an agent in RunOnce mode, and returns the last answer of
+// the model, or the error reported after it, e.g. of a refused tool call the model did not
+// recover from. It closes the agent, and so its LLM client.
+func runOnce(ctx context.Context, a *agent.Agent) (string, error) {
+	if err := a.Init(ctx); err != nil {
+		a.LLM.Close()
+		return "", fmt.Errorf("initializing agent: %w", err)
+	}
+	defer a.Close()
+	if err := a.Run(ctx, ""); err != nil {
+		return "", fmt.Errorf("running agent: %w", err)
+	}
+
+	var answer string
+	var reported error
+	for msg := range a.Output {
+		m, ok := msg.(*api.Message)
+		if !ok {
+			continue
+		}
+		switch m.Type {
+		case api.MessageTypeText:
+			if text, ok := m.Payload.(string); ok && m.Source == api.MessageSourceModel {
+				answer, reported = text, nil
+			}
+		case api.MessageTypeError:
+			if payload, ok := m.Payload.(*api.ErrorPayload); ok {
+				reported = errors.New(strings.TrimSpace(payload.Message))
+			}
+		}
+	}
+	if reported != nil {
+		return "", reported
+	}
+	if err := a.LastErr(); err != nil {
+		return "", err
+	}
+	return answer, nil
+}
+
+// cleanSuggestion strips the Markdown code fence or backticks models tend to put around commands.
+func cleanSuggestion(answer string) string {
+	answer = strings.TrimSpace(answer)
+	if strings.HasPrefix(answer, "```") {
+		lines := strings.Split(answer, "\n")
+		var kept []string
+		for _, line := range lines[1:] {
+			if strings.HasPrefix(line, "```") {
+				break
+			}
+			kept = append(kept, line)
+		}
+		answer = strings.Join(kept, "\n")
+	}
+	return strings.TrimSpace(strings.Trim(answer, "`"))
+}
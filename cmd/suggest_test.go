@@ -0,0 +1,44 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggestQuery(t *testing.T) {
+	if got := suggestQuery("", "", ""); got != "" {
+		t.Errorf("suggestQuery() without input = %q, want empty", got)
+	}
+	got := suggestQuery("in staging", "kubectl get pdos", `error: the server doesn't have a resource type "pdos"`)
+	for _, want := range []string{"Failed command:\nkubectl get pdos", "Error output:\nerror: the server", "Note from the user:\nin staging"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("suggestQuery() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestCleanSuggestion(t *testing.T) {
+	for answer, want := range map[string]string{
+		"kubectl get pods\n":                      "kubectl get pods",
+		"`kubectl get pods`":                      "kubectl get pods",
+		"```bash\nkubectl get pods -A\n```\nDone": "kubectl get pods -A",
+	} {
+		if got := cleanSuggestion(answer); got != want {
+			t.Errorf("cleanSuggestion(%q) = %q, want %q", answer, got, want)
+		}
+	}
+}
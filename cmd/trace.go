@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+func newTraceCommand(opt *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trace",
+		Short: "Work with recorded traces (journals) of sessions",
+	}
+	cmd.AddCommand(newTraceReplayCommand(opt))
+	return cmd
+}
+
+func newTraceReplayCommand(opt *Options) *cobra.Command {
+	var format, sessionID, rawKeyFile string
+	cmd := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Replay a recorded session",
+		Long: "replay reconstructs a session from a trace file written with --trace-path, stepping through its " +
+			"messages, tool calls and LLM calls with their timings, in a read-only TUI or as Markdown.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log, err := loadReplaySession(args[0], sessionID)
+			if err != nil {
+				return err
+			}
+			if rawKeyFile != "" {
+				rawKey, err := journal.LoadRawKey(rawKeyFile)
+				if err != nil {
+					return err
+				}
+				if err := journal.DecryptRawPayloads(log.Events, rawKey); err != nil {
+					return err
+				}
+			}
+			steps := ui.ReplaySteps(log)
+
+			switch format {
+			case "markdown":
+				_, err := fmt.Fprint(os.Stdout, ui.RenderReplayMarkdown(log.SessionID, steps))
+				return err
+			case "tui":
+				theme, err := ui.ResolveTheme(opt.UITheme, opt.UICustomTheme)
+				if err != nil {
+					return fmt.Errorf("resolving TUI theme: %w", err)
+				}
+				theme.PlainGlyphs = theme.PlainGlyphs || opt.UIPlainGlyphs
+				return ui.NewReplayTUI(log.SessionID, steps, theme).Run()
+			default:
+				return fmt.Errorf("format %q is not known, must be tui or markdown", format)
+			}
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&format, "format", "tui", "how to show the session: tui or markdown")
+	f.StringVar(&sessionID, "session", "", "session to replay, required if the trace contains several sessions")
+	f.StringVar(&rawKeyFile, "raw-key-file", "", "key the trace was recorded with (--trace-raw-key-file), to show the raw values of redacted events")
+
+	return cmd
+}
+
+// loadReplaySession reads the events of a session from a trace file. Without a session
+// ID, the trace must contain a single session; events without a session are included.
+func loadReplaySession(path, sessionID string) (*journal.SessionLog, error) {
+	if sessionID != "" {
+		return journal.LoadSessionLog(path, sessionID)
+	}
+
+	events, err := journal.ParseEventsFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []string
+	seen := map[string]bool{}
+	for _, event := range events {
+		if event.SessionID != "" && !seen[event.SessionID] {
+			seen[event.SessionID] = true
+			sessions = append(sessions, event.SessionID)
+		}
+	}
+	if len(sessions) > 1 {
+		return nil, fmt.Errorf("trace %q contains %d sessions, pick one with --session: %s", path, len(sessions), strings.Join(sessions, ", "))
+	}
+
+	log := &journal.SessionLog{Events: events}
+	if len(sessions) == 1 {
+		log.SessionID = sessions[0]
+	}
+	return log, nil
+}
@@ -0,0 +1,756 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+const (
+	// anthropicDefaultBaseURL is the endpoint of the Anthropic API
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	// anthropicAPIVersion is the version of the Messages API the client speaks
+	anthropicAPIVersion = "2023-06-01"
+	// anthropicDefaultModel is used when neither --model nor ANTHROPIC_MODEL is set
+	anthropicDefaultModel = "claude-sonnet-4-20250514"
+	// anthropicDefaultMaxTokens caps the length of responses, which the API requires
+	anthropicDefaultMaxTokens = 8192
+)
+
+// Register the Anthropic provider factory on package initialization.
+func init() {
+	if err := RegisterProvider("anthropic", newAnthropicClientFactory); err != nil {
+		klog.Fatalf("Failed to register anthropic provider: %v", err)
+	}
+}
+
+// newAnthropicClientFactory is the factory function for creating Anthropic clients with options.
+func newAnthropicClientFactory(ctx context.Context, opts ClientOptions) (Client, error) {
+	return NewAnthropicClient(ctx, opts)
+}
+
+// AnthropicClient implements the gollm.Client interface for Anthropic's Claude models,
+// using the Messages API.
+type AnthropicClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	generation GenerationConfig
+}
+
+// Ensure AnthropicClient implements the Client interface.
+var _ Client = &AnthropicClient{}
+
+// NewAnthropicClient creates a new client for interacting with Anthropic models.
+// The API key is read from ANTHROPIC_API_KEY, and the endpoint can be overridden
+// with ANTHROPIC_BASE_URL, e.g. for a proxy.
+func NewAnthropicClient(ctx context.Context, opts ClientOptions) (*AnthropicClient, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("ANTHROPIC_API_KEY environment variable not set")
+	}
+
+	baseURL := anthropicDefaultBaseURL
+	if customURL := os.Getenv("ANTHROPIC_BASE_URL"); customURL != "" {
+		baseURL = customURL
+		klog.Infof("Using custom Anthropic base URL: %s", baseURL)
+	}
+
+	httpClient := createCustomHTTPClient(opts.SkipVerifySSL)
+	httpClient = withJournaling(httpClient)
+	return &AnthropicClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: httpClient,
+		generation: opts.Generation,
+	}, nil
+}
+
+// Close cleans up any resources used by the client.
+func (c *AnthropicClient) Close() error {
+	return nil
+}
+
+// StartChat starts a new chat session.
+func (c *AnthropicClient) StartChat(systemPrompt, model string) Chat {
+	selectedModel := getAnthropicModel(model)
+	klog.V(1).Infof("Starting new Anthropic chat session with model: %s", selectedModel)
+	return &anthropicChat{
+		client: c,
+		model:  selectedModel,
+		system: systemPrompt,
+	}
+}
+
+// GenerateCompletion generates a single completion for a given prompt.
+func (c *AnthropicClient) GenerateCompletion(ctx context.Context, req *CompletionRequest) (CompletionResponse, error) {
+	messagesReq := &anthropicMessagesRequest{
+		Model:     getAnthropicModel(req.Model),
+		MaxTokens: anthropicDefaultMaxTokens,
+		Messages: []anthropicMessage{
+			{Role: "user", Content: []anthropicContentBlock{{Type: "text", Text: req.Prompt}}},
+		},
+	}
+	applyAnthropicGenerationConfig(messagesReq, c.generation)
+	resp := &anthropicMessagesResponse{}
+	if err := c.doRequest(ctx, http.MethodPost, "/v1/messages", messagesReq, resp); err != nil {
+		return nil, fmt.Errorf("anthropic completion failed: %w", err)
+	}
+	return &anthropicCompletionResponse{response: resp}, nil
+}
+
+// SetResponseSchema is not supported by the Messages API.
+func (c *AnthropicClient) SetResponseSchema(schema *Schema) error {
+	return fmt.Errorf("response schema not supported by Anthropic")
+}
+
+// ListModels lists the models available to the API key.
+func (c *AnthropicClient) ListModels(ctx context.Context) ([]string, error) {
+	resp := &anthropicModelsResponse{}
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/models?limit=1000", nil, resp); err != nil {
+		return nil, fmt.Errorf("listing anthropic models: %w", err)
+	}
+	models := make([]string, 0, len(resp.Data))
+	for _, model := range resp.Data {
+		models = append(models, model.ID)
+	}
+	return models, nil
+}
+
+// newRequest builds a request to the Anthropic API, with a JSON body if body is not nil.
+func (c *AnthropicClient) newRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("building json body: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("building http request: %w", err)
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// do sends a request, and returns the response if it succeeded.
+func (c *AnthropicClient) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("performing http request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    anthropicErrorMessage(b),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return resp, nil
+}
+
+func (c *AnthropicClient) doRequest(ctx context.Context, method, path string, body any, response any) error {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("unmarshalling json response: %w", err)
+	}
+	return nil
+}
+
+// anthropicErrorMessage extracts the message of an error response, falling back to its body.
+func anthropicErrorMessage(body []byte) string {
+	var errResp struct {
+		Error anthropicError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+		return errResp.Error.Type + ": " + errResp.Error.Message
+	}
+	return string(body)
+}
+
+// getAnthropicModel returns the model to use: the explicit one, ANTHROPIC_MODEL, or the default.
+func getAnthropicModel(model string) string {
+	if model != "" {
+		return model
+	}
+	if envModel := os.Getenv("ANTHROPIC_MODEL"); envModel != "" {
+		return envModel
+	}
+	return anthropicDefaultModel
+}
+
+// --- Chat Session Implementation ---
+
+type anthropicChat struct {
+	client  *AnthropicClient
+	model   string
+	system  string
+	history []anthropicMessage
+	tools   []anthropicTool
+}
+
+var _ Chat = &anthropicChat{}
+
+// SetFunctionDefinitions sets the tools the model can call.
+func (c *anthropicChat) SetFunctionDefinitions(defs []*FunctionDefinition) error {
+	tools := make([]anthropicTool, 0, len(defs))
+	for _, def := range defs {
+		schema := def.Parameters
+		if schema == nil {
+			// The API requires an object schema, even for tools without parameters
+			schema = &Schema{Type: TypeObject}
+		}
+		tools = append(tools, anthropicTool{
+			Name:        def.Name,
+			Description: def.Description,
+			InputSchema: schema,
+		})
+	}
+	c.tools = tools
+	return nil
+}
+
+// Send sends the contents as a user message, and adds the response to the history.
+func (c *anthropicChat) Send(ctx context.Context, contents ...any) (ChatResponse, error) {
+	messages, err := c.messagesWith(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	klog.V(1).InfoS("Sending request to Anthropic Messages API", "model", c.model, "messages", len(messages), "tools", len(c.tools))
+	resp := &anthropicMessagesResponse{}
+	if err := c.client.doRequest(ctx, http.MethodPost, "/v1/messages", c.request(messages, false), resp); err != nil {
+		return nil, fmt.Errorf("anthropic chat failed: %w", err)
+	}
+	klog.V(1).InfoS("Received response from Anthropic Messages API", "id", resp.ID, "stopReason", resp.StopReason)
+
+	c.history = appendAnthropicBlocks(messages, "assistant", resp.Content...)
+	return &anthropicChatResponse{content: resp.Content, usage: resp.Usage, raw: resp}, nil
+}
+
+// SendStreaming sends the contents as a user message, and streams the response. The
+// response is added to the history once the stream completes.
+func (c *anthropicChat) SendStreaming(ctx context.Context, contents ...any) (ChatResponseIterator, error) {
+	messages, err := c.messagesWith(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	klog.V(1).InfoS("Sending streaming request to Anthropic Messages API", "model", c.model, "messages", len(messages), "tools", len(c.tools))
+	req, err := c.client.newRequest(ctx, http.MethodPost, "/v1/messages", c.request(messages, true))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.client.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic streaming chat failed: %w", err)
+	}
+
+	return func(yield func(ChatResponse, error) bool) {
+		defer resp.Body.Close()
+
+		stream := &anthropicStream{}
+		err := readServerSentEvents(resp.Body, func(event string, data []byte) (bool, error) {
+			response, err := stream.handle(event, data)
+			if err != nil || response == nil {
+				return err == nil, err
+			}
+			return yield(response, nil), nil
+		})
+		if err != nil {
+			yield(nil, fmt.Errorf("anthropic streaming error: %w", err))
+			return
+		}
+		if !stream.done {
+			// The consumer stopped early, or the stream was cut; don't keep a partial turn
+			return
+		}
+		c.history = appendAnthropicBlocks(messages, "assistant", stream.content...)
+	}, nil
+}
+
+// IsRetryableError determines if an error from the Anthropic API should be retried.
+// Overloaded (529) responses are retried, besides the usual HTTP codes.
+func (c *anthropicChat) IsRetryableError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 529 {
+		return true
+	}
+	return DefaultIsRetryableError(err)
+}
+
+// Initialize restores the text messages of a previous conversation.
+func (c *anthropicChat) Initialize(messages []*api.Message) error {
+	c.history = nil
+	for _, msg := range messages {
+		if msg.Type != api.MessageTypeText {
+			continue
+		}
+		text, ok := msg.Payload.(string)
+		if !ok || text == "" {
+			continue
+		}
+		role := "user"
+		if msg.Source == api.MessageSourceModel {
+			role = "assistant"
+		}
+		if len(c.history) == 0 && role == "assistant" {
+			// Conversations must start with a user message
+			continue
+		}
+		c.history = appendAnthropicBlocks(c.history, role, anthropicContentBlock{Type: "text", Text: text})
+	}
+	return nil
+}
+
+// request builds the request sending messages with the tools of the chat.
+func (c *anthropicChat) request(messages []anthropicMessage, stream bool) *anthropicMessagesRequest {
+	req := &anthropicMessagesRequest{
+		Model:     c.model,
+		MaxTokens: anthropicDefaultMaxTokens,
+		System:    c.system,
+		Messages:  messages,
+		Tools:     c.tools,
+		Stream:    stream,
+	}
+	applyAnthropicGenerationConfig(req, c.client.generation)
+	return req
+}
+
+// applyAnthropicGenerationConfig sets the parameters of generation that are set in req.
+// Reasoning efforts enable extended thinking, whose budget must be below max_tokens.
+func applyAnthropicGenerationConfig(req *anthropicMessagesRequest, generation GenerationConfig) {
+	req.Temperature = generation.Temperature
+	req.TopP = generation.TopP
+	req.StopSequences = generation.StopSequences
+	if generation.MaxOutputTokens > 0 {
+		req.MaxTokens = generation.MaxOutputTokens
+	}
+	if budget := generation.ReasoningEffort.thinkingBudget(); budget > 0 {
+		req.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: budget}
+		if req.MaxTokens <= budget {
+			req.MaxTokens = budget + anthropicDefaultMaxTokens
+		}
+	}
+}
+
+// messagesWith returns the history followed by contents, as a user message. The history
+// itself is only updated once the request succeeded, so that retries don't repeat it.
+func (c *anthropicChat) messagesWith(contents []any) ([]anthropicMessage, error) {
+	var results, texts []anthropicContentBlock
+	for _, content := range contents {
+		switch v := content.(type) {
+		case string:
+			if v != "" {
+				texts = append(texts, anthropicContentBlock{Type: "text", Text: v})
+			}
+		case FunctionCallResult:
+			resultJSON, err := json.Marshal(v.Result)
+			if err != nil {
+				return nil, fmt.Errorf("marshalling function call result: %w", err)
+			}
+			results = append(results, anthropicContentBlock{Type: "tool_result", ToolUseID: v.ID, Content: string(resultJSON)})
+		default:
+			return nil, fmt.Errorf("unsupported content type: %T", v)
+		}
+	}
+	if len(results)+len(texts) == 0 {
+		return nil, errors.New("no content to send")
+	}
+
+	messages := append([]anthropicMessage(nil), c.history...)
+	// Tool results must come first in the message following the tool calls
+	return appendAnthropicBlocks(messages, "user", append(results, texts...)...), nil
+}
+
+// appendAnthropicBlocks adds blocks to the last message if it has the same role, as the
+// API requires alternating roles, or as a new message otherwise. Empty text blocks,
+// which the API rejects, are dropped.
+func appendAnthropicBlocks(messages []anthropicMessage, role string, blocks ...anthropicContentBlock) []anthropicMessage {
+	var kept []anthropicContentBlock
+	for _, block := range blocks {
+		if block.Type != "text" || block.Text != "" {
+			kept = append(kept, block)
+		}
+	}
+	blocks = kept
+	if len(blocks) == 0 {
+		return messages
+	}
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		last := messages[n-1]
+		last.Content = append(append([]anthropicContentBlock(nil), last.Content...), blocks...)
+		messages[n-1] = last
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: blocks})
+}
+
+// --- Streaming ---
+
+// anthropicStream accumulates the events of a streamed message.
+type anthropicStream struct {
+	content []anthropicContentBlock
+	// partialJSON accumulates the input of tool calls, by content block index
+	partialJSON map[int]*strings.Builder
+	usage       anthropicUsage
+	done        bool
+}
+
+// handle processes an event, and returns the response to yield for it, if any.
+func (s *anthropicStream) handle(event string, data []byte) (*anthropicChatResponse, error) {
+	var ev anthropicStreamEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, fmt.Errorf("parsing %q event: %w", event, err)
+	}
+
+	switch ev.Type {
+	case "message_start":
+		if ev.Message != nil {
+			s.usage = ev.Message.Usage
+		}
+	case "content_block_start":
+		if ev.ContentBlock == nil {
+			return nil, fmt.Errorf("content_block_start event without content block")
+		}
+		for len(s.content) <= ev.Index {
+			s.content = append(s.content, anthropicContentBlock{})
+		}
+		s.content[ev.Index] = *ev.ContentBlock
+		if ev.ContentBlock.Type == "text" && ev.ContentBlock.Text != "" {
+			return s.response([]anthropicContentBlock{{Type: "text", Text: ev.ContentBlock.Text}}), nil
+		}
+	case "content_block_delta":
+		if ev.Delta == nil || ev.Index >= len(s.content) {
+			return nil, fmt.Errorf("content_block_delta event for unknown block %d", ev.Index)
+		}
+		switch ev.Delta.Type {
+		case "text_delta":
+			s.content[ev.Index].Text += ev.Delta.Text
+			return s.response([]anthropicContentBlock{{Type: "text", Text: ev.Delta.Text}}), nil
+		case "input_json_delta":
+			if s.partialJSON == nil {
+				s.partialJSON = make(map[int]*strings.Builder)
+			}
+			if s.partialJSON[ev.Index] == nil {
+				s.partialJSON[ev.Index] = &strings.Builder{}
+			}
+			s.partialJSON[ev.Index].WriteString(ev.Delta.PartialJSON)
+		case "thinking_delta":
+			s.content[ev.Index].Thinking += ev.Delta.Thinking
+			return s.response([]anthropicContentBlock{{Type: "thinking", Thinking: ev.Delta.Thinking}}), nil
+		case "signature_delta":
+			s.content[ev.Index].Signature += ev.Delta.Signature
+		}
+	case "content_block_stop":
+		if ev.Index >= len(s.content) || s.content[ev.Index].Type != "tool_use" {
+			return nil, nil
+		}
+		block := &s.content[ev.Index]
+		if partial := s.partialJSON[ev.Index]; partial != nil && partial.Len() > 0 {
+			block.Input = json.RawMessage(partial.String())
+		}
+		if len(block.Input) == 0 {
+			block.Input = json.RawMessage("{}")
+		}
+		return s.response([]anthropicContentBlock{*block}), nil
+	case "message_delta":
+		if ev.Usage != nil {
+			s.usage.OutputTokens = ev.Usage.OutputTokens
+		}
+	case "message_stop":
+		s.done = true
+	case "error":
+		if ev.Error != nil {
+			return nil, fmt.Errorf("%s: %s", ev.Error.Type, ev.Error.Message)
+		}
+		return nil, errors.New("unknown error in stream")
+	}
+	return nil, nil
+}
+
+func (s *anthropicStream) response(content []anthropicContentBlock) *anthropicChatResponse {
+	return &anthropicChatResponse{content: content, usage: s.usage}
+}
+
+// readServerSentEvents calls handle with the event type and data of each event of a
+// server-sent event stream, until the stream ends or handle returns false.
+func readServerSentEvents(r io.Reader, handle func(event string, data []byte) (bool, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var event string
+	var data bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				more, err := handle(event, data.Bytes())
+				if err != nil || !more {
+					return err
+				}
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return scanner.Err()
+}
+
+// --- Responses ---
+
+type anthropicCompletionResponse struct {
+	response *anthropicMessagesResponse
+}
+
+var _ CompletionResponse = &anthropicCompletionResponse{}
+
+func (r *anthropicCompletionResponse) Response() string {
+	var sb strings.Builder
+	for _, block := range r.response.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String()
+}
+
+func (r *anthropicCompletionResponse) UsageMetadata() any {
+	return r.response.Usage
+}
+
+// anthropicChatResponse is a message, or a chunk of a streamed message.
+type anthropicChatResponse struct {
+	content []anthropicContentBlock
+	usage   anthropicUsage
+	// raw is the response of non-streaming requests, recorded in journals
+	raw *anthropicMessagesResponse
+}
+
+var _ ChatResponse = &anthropicChatResponse{}
+
+func (r *anthropicChatResponse) MarshalJSON() ([]byte, error) {
+	formatted := RecordChatResponse{
+		Raw: r.raw,
+	}
+	if r.raw == nil {
+		formatted.Raw = r.content
+	}
+	return json.Marshal(&formatted)
+}
+
+// UsageMetadata returns the token usage of the message, so far for streamed messages.
+func (r *anthropicChatResponse) UsageMetadata() any {
+	return r.usage
+}
+
+func (r *anthropicChatResponse) Candidates() []Candidate {
+	return []Candidate{&anthropicCandidate{content: r.content}}
+}
+
+type anthropicCandidate struct {
+	content []anthropicContentBlock
+}
+
+func (c *anthropicCandidate) String() string {
+	var sb strings.Builder
+	for _, block := range c.content {
+		sb.WriteString(block.Text)
+	}
+	return sb.String()
+}
+
+// Parts returns the text, thinking and tool calls of the message. Other blocks, such as
+// redacted thinking blocks, are skipped.
+func (c *anthropicCandidate) Parts() []Part {
+	var parts []Part
+	for _, block := range c.content {
+		switch block.Type {
+		case "text":
+			if block.Text != "" {
+				parts = append(parts, &anthropicPart{text: block.Text})
+			}
+		case "thinking":
+			if block.Thinking != "" {
+				parts = append(parts, &anthropicPart{thought: block.Thinking})
+			}
+		case "tool_use":
+			arguments := make(map[string]any)
+			if len(block.Input) > 0 {
+				if err := json.Unmarshal(block.Input, &arguments); err != nil {
+					klog.Errorf("Failed to parse arguments of tool call %q: %v", block.Name, err)
+					continue
+				}
+			}
+			parts = append(parts, &anthropicPart{functionCalls: []FunctionCall{{ID: block.ID, Name: block.Name, Arguments: arguments}}})
+		}
+	}
+	return parts
+}
+
+type anthropicPart struct {
+	text          string
+	thought       string
+	functionCalls []FunctionCall
+}
+
+var _ ThoughtPart = &anthropicPart{}
+
+// AsThought returns the thinking of the part. Unlike other providers, thinking blocks
+// stay in the history, as the API requires them with the tool calls that follow them.
+func (p *anthropicPart) AsThought() (string, bool) {
+	return p.thought, p.thought != ""
+}
+
+func (p *anthropicPart) AsText() (string, bool) {
+	return p.text, p.text != ""
+}
+
+func (p *anthropicPart) AsFunctionCalls() ([]FunctionCall, bool) {
+	return p.functionCalls, len(p.functionCalls) > 0
+}
+
+// --- API types ---
+
+type anthropicMessagesRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicTool    `json:"tools,omitempty"`
+	Stream    bool               `json:"stream,omitempty"`
+
+	Temperature   *float32           `json:"temperature,omitempty"`
+	TopP          *float32           `json:"top_p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Thinking      *anthropicThinking `json:"thinking,omitempty"`
+}
+
+type anthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+	// Text is set for text blocks
+	Text string `json:"text,omitempty"`
+	// ID, Name and Input are set for tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// ToolUseID and Content are set for tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	// Thinking and Signature are set for thinking blocks, which must be sent back as they are
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	InputSchema *Schema `json:"input_schema"`
+}
+
+type anthropicMessagesResponse struct {
+	ID         string                  `json:"id"`
+	Model      string                  `json:"model"`
+	Role       string                  `json:"role"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+type anthropicStreamEvent struct {
+	Type         string                     `json:"type"`
+	Index        int                        `json:"index"`
+	Message      *anthropicMessagesResponse `json:"message,omitempty"`
+	ContentBlock *anthropicContentBlock     `json:"content_block,omitempty"`
+	Delta        *anthropicStreamDelta      `json:"delta,omitempty"`
+	Usage        *anthropicUsage            `json:"usage,omitempty"`
+	Error        *anthropicError            `json:"error,omitempty"`
+}
+
+type anthropicStreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type anthropicModelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
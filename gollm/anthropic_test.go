@@ -0,0 +1,238 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestAnthropicClient(t *testing.T, handler http.HandlerFunc) (*AnthropicClient, *[]anthropicMessagesRequest) {
+	t.Helper()
+	var requests []anthropicMessagesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("x-api-key = %q, want test-key", got)
+		}
+		var req anthropicMessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		requests = append(requests, req)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+	client, err := NewAnthropicClient(context.Background(), ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewAnthropicClient() error = %v", err)
+	}
+	return client, &requests
+}
+
+func TestAnthropicChatToolUse(t *testing.T) {
+	responses := []string{
+		`{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"Listing pods."},{"type":"tool_use","id":"toolu_1","name":"kubectl","input":{"command":"kubectl get pods"}}],"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":5}}`,
+		`{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"All pods are running."}],"stop_reason":"end_turn","usage":{"input_tokens":20,"output_tokens":6}}`,
+	}
+	client, requests := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, responses[0])
+		responses = responses[1:]
+	})
+
+	chat := client.StartChat("You are a helpful assistant.", "claude-test")
+	if err := chat.SetFunctionDefinitions([]*FunctionDefinition{{Name: "kubectl", Description: "Runs kubectl"}}); err != nil {
+		t.Fatalf("SetFunctionDefinitions() error = %v", err)
+	}
+
+	resp, err := chat.Send(context.Background(), "list pods")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	parts := resp.Candidates()[0].Parts()
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	calls, ok := parts[1].AsFunctionCalls()
+	want := []FunctionCall{{ID: "toolu_1", Name: "kubectl", Arguments: map[string]any{"command": "kubectl get pods"}}}
+	if !ok || !reflect.DeepEqual(calls, want) {
+		t.Errorf("function calls = %v, want %v", calls, want)
+	}
+	if usage := resp.UsageMetadata().(anthropicUsage); usage.InputTokens != 10 || usage.OutputTokens != 5 {
+		t.Errorf("usage = %+v, want 10 input and 5 output tokens", usage)
+	}
+
+	result := FunctionCallResult{ID: "toolu_1", Name: "kubectl", Result: map[string]any{"stdout": "nginx Running"}}
+	if _, err := chat.Send(context.Background(), result); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	first := (*requests)[0]
+	if first.System != "You are a helpful assistant." || first.Model != "claude-test" {
+		t.Errorf("system, model = %q, %q", first.System, first.Model)
+	}
+	if len(first.Tools) != 1 || first.Tools[0].InputSchema == nil || first.Tools[0].InputSchema.Type != TypeObject {
+		t.Errorf("tools = %+v, want kubectl with an object schema", first.Tools)
+	}
+	second := (*requests)[1]
+	if len(second.Messages) != 3 {
+		t.Fatalf("second request has %d messages, want 3", len(second.Messages))
+	}
+	toolResult := second.Messages[2].Content[0]
+	if toolResult.Type != "tool_result" || toolResult.ToolUseID != "toolu_1" || !strings.Contains(toolResult.Content, "nginx Running") {
+		t.Errorf("tool result = %+v", toolResult)
+	}
+}
+
+func TestAnthropicChatStreaming(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[],"usage":{"input_tokens":12,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" pods."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"kubectl","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"command\": \"kubectl"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":" get pods\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+		`{"type":"message_stop"}`,
+	}
+	client, requests := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			var ev anthropicStreamEvent
+			_ = json.Unmarshal([]byte(event), &ev)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, event)
+		}
+	})
+
+	chat := client.StartChat("", "claude-test")
+	stream, err := chat.SendStreaming(context.Background(), "list pods")
+	if err != nil {
+		t.Fatalf("SendStreaming() error = %v", err)
+	}
+	var text strings.Builder
+	var calls []FunctionCall
+	var lastUsage anthropicUsage
+	for resp, err := range stream {
+		if err != nil {
+			t.Fatalf("stream error = %v", err)
+		}
+		for _, part := range resp.Candidates()[0].Parts() {
+			if s, ok := part.AsText(); ok {
+				text.WriteString(s)
+			}
+			if c, ok := part.AsFunctionCalls(); ok {
+				calls = append(calls, c...)
+			}
+		}
+		lastUsage = resp.UsageMetadata().(anthropicUsage)
+	}
+
+	if text.String() != "Checking pods." {
+		t.Errorf("text = %q, want %q", text.String(), "Checking pods.")
+	}
+	want := []FunctionCall{{ID: "toolu_1", Name: "kubectl", Arguments: map[string]any{"command": "kubectl get pods"}}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("function calls = %v, want %v", calls, want)
+	}
+	if lastUsage.InputTokens != 12 {
+		t.Errorf("input tokens = %d, want 12", lastUsage.InputTokens)
+	}
+	if !(*requests)[0].Stream {
+		t.Errorf("request was not streaming")
+	}
+
+	history := chat.(*anthropicChat).history
+	if len(history) != 2 || history[1].Role != "assistant" || len(history[1].Content) != 2 {
+		t.Fatalf("history = %+v, want the user message and the assistant message with text and tool call", history)
+	}
+	if got := string(history[1].Content[1].Input); got != `{"command": "kubectl get pods"}` {
+		t.Errorf("tool call input in history = %s", got)
+	}
+}
+
+func TestAnthropicChatErrors(t *testing.T) {
+	client, _ := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	})
+
+	chat := client.StartChat("", "claude-test")
+	_, err := chat.Send(context.Background(), "hello")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 529 || !strings.Contains(apiErr.Message, "Overloaded") {
+		t.Fatalf("Send() error = %v, want an overloaded API error", err)
+	}
+	if !chat.IsRetryableError(err) {
+		t.Errorf("overloaded error is not retryable")
+	}
+	if history := chat.(*anthropicChat).history; len(history) != 0 {
+		t.Errorf("history = %+v, want it unchanged after a failed request", history)
+	}
+}
+
+func TestAnthropicGenerationConfig(t *testing.T) {
+	client, requests := newTestAnthropicClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"msg_1","role":"assistant","content":[{"type":"thinking","thinking":"Pods first.","signature":"sig"},{"type":"text","text":"Done."}],"stop_reason":"end_turn"}`)
+	})
+	temperature := float32(0.5)
+	client.generation = GenerationConfig{
+		Temperature:     &temperature,
+		MaxOutputTokens: 1000,
+		StopSequences:   []string{"STOP"},
+		ReasoningEffort: ReasoningEffortLow,
+	}
+
+	chat := client.StartChat("", "claude-test")
+	resp, err := chat.Send(context.Background(), "list pods")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	parts := resp.Candidates()[0].Parts()
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want the thought and the text", len(parts))
+	}
+	if thought, ok := parts[0].(ThoughtPart).AsThought(); !ok || thought != "Pods first." {
+		t.Errorf("thought = %q, %v, want %q", thought, ok, "Pods first.")
+	}
+	if text, ok := parts[0].AsText(); ok {
+		t.Errorf("thought part has text %q", text)
+	}
+	if text, _ := parts[1].AsText(); text != "Done." {
+		t.Errorf("text = %q, want %q", text, "Done.")
+	}
+	req := (*requests)[0]
+	if req.Temperature == nil || *req.Temperature != 0.5 || !reflect.DeepEqual(req.StopSequences, []string{"STOP"}) {
+		t.Errorf("temperature, stop sequences = %v, %v", req.Temperature, req.StopSequences)
+	}
+	if req.Thinking == nil || req.Thinking.BudgetTokens != 1024 || req.MaxTokens <= req.Thinking.BudgetTokens {
+		t.Errorf("thinking = %+v with max tokens %d, want a budget of 1024 below max tokens", req.Thinking, req.MaxTokens)
+	}
+	history := chat.(*anthropicChat).history
+	if len(history) != 2 || history[1].Content[0].Signature != "sig" {
+		t.Errorf("history = %+v, want the thinking block kept with its signature", history)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
@@ -49,8 +50,9 @@ func azureOpenAIFactory(ctx context.Context, opts ClientOptions) (Client, error)
 }
 
 type AzureOpenAIClient struct {
-	client   *azopenai.Client
-	endpoint string
+	client     *azopenai.Client
+	endpoint   string
+	generation GenerationConfig
 }
 
 var _ Client = &AzureOpenAIClient{}
@@ -67,7 +69,8 @@ func NewAzureOpenAIClient(ctx context.Context, opts ClientOptions) (*AzureOpenAI
 		return nil, fmt.Errorf("AZURE_OPENAI_ENDPOINT environment variable not set")
 	}
 	azureOpenAIClient := AzureOpenAIClient{
-		endpoint: azureOpenAIEndpoint,
+		endpoint:   azureOpenAIEndpoint,
+		generation: opts.Generation,
 	}
 
 	// Create a custom HTTP client (supports SkipVerifySSL)
@@ -112,6 +115,7 @@ func (c *AzureOpenAIClient) GenerateCompletion(ctx context.Context, request *Com
 		},
 		DeploymentName: &request.Model,
 	}
+	applyAzureOpenAIGenerationConfig(&req, c.generation)
 
 	resp, err := c.client.GetChatCompletions(ctx, req, nil)
 	if err != nil {
@@ -205,8 +209,9 @@ func (c *AzureOpenAIClient) SetResponseSchema(schema *Schema) error {
 
 func (c *AzureOpenAIClient) StartChat(systemPrompt string, model string) Chat {
 	return &AzureOpenAIChat{
-		client: c.client,
-		model:  model,
+		client:     c.client,
+		model:      model,
+		generation: c.generation,
 		history: []azopenai.ChatRequestMessageClassification{
 			&azopenai.ChatRequestSystemMessage{Content: azopenai.NewChatRequestSystemMessageContent(systemPrompt)},
 		},
@@ -230,6 +235,8 @@ type AzureOpenAIChat struct {
 	model   string
 	history []azopenai.ChatRequestMessageClassification
 	tools   []azopenai.ChatCompletionsToolDefinitionClassification
+
+	generation GenerationConfig
 }
 
 func (c *AzureOpenAIChat) Send(ctx context.Context, contents ...any) (ChatResponse, error) {
@@ -250,11 +257,13 @@ func (c *AzureOpenAIChat) Send(ctx context.Context, contents ...any) (ChatRespon
 		}
 	}
 
-	resp, err := c.client.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
+	req := azopenai.ChatCompletionsOptions{
 		DeploymentName: &c.model,
 		Messages:       c.history,
 		Tools:          c.tools,
-	}, nil)
+	}
+	applyAzureOpenAIGenerationConfig(&req, c.generation)
+	resp, err := c.client.GetChatCompletions(ctx, req, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -426,3 +435,16 @@ func fnDefToAzureOpenAITool(fnDef *FunctionDefinition) *azopenai.ChatCompletions
 
 	return &tool
 }
+
+// applyAzureOpenAIGenerationConfig sets the parameters of generation that are set in req.
+func applyAzureOpenAIGenerationConfig(req *azopenai.ChatCompletionsOptions, generation GenerationConfig) {
+	req.Temperature = generation.Temperature
+	req.TopP = generation.TopP
+	req.Stop = generation.StopSequences
+	if generation.MaxOutputTokens > 0 {
+		req.MaxCompletionTokens = to.Ptr(int32(generation.MaxOutputTokens))
+	}
+	if generation.ReasoningEffort != "" {
+		req.ReasoningEffort = to.Ptr(azopenai.ReasoningEffortValue(generation.ReasoningEffort))
+	}
+}
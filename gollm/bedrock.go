@@ -16,15 +16,21 @@ package gollm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
@@ -47,6 +53,14 @@ func newBedrockClientFactory(ctx context.Context, opts ClientOptions) (Client, e
 // BedrockClient implements the gollm.Client interface for AWS Bedrock models
 type BedrockClient struct {
 	client *bedrockruntime.Client
+
+	// cfg, httpClient and controlEndpoint are used to call the Bedrock control plane,
+	// which lists the models
+	cfg             aws.Config
+	httpClient      *http.Client
+	controlEndpoint string
+
+	generation GenerationConfig
 }
 
 // Ensure BedrockClient implements the Client interface
@@ -69,7 +83,11 @@ func NewBedrockClient(ctx context.Context, opts ClientOptions) (*BedrockClient,
 	}
 
 	return &BedrockClient{
-		client: bedrockruntime.NewFromConfig(cfg),
+		client:          bedrockruntime.NewFromConfig(cfg),
+		cfg:             cfg,
+		httpClient:      createCustomHTTPClient(opts.SkipVerifySSL),
+		controlEndpoint: fmt.Sprintf("https://bedrock.%s.amazonaws.com", cfg.Region),
+		generation:      opts.Generation,
 	}, nil
 }
 
@@ -123,12 +141,109 @@ func (c *BedrockClient) SetResponseSchema(schema *Schema) error {
 	return fmt.Errorf("response schema not supported by Bedrock")
 }
 
-// ListModels returns the list of supported Bedrock models
+// bedrockDefaultModels are listed when the account's models cannot be listed,
+// e.g. without the bedrock:ListInferenceProfiles permission
+var bedrockDefaultModels = []string{
+	"us.anthropic.claude-sonnet-4-20250514-v1:0",   // Claude Sonnet 4 (default)
+	"us.anthropic.claude-3-7-sonnet-20250219-v1:0", // Claude 3.7 Sonnet
+}
+
+// ListModels returns the models the account can use in its region: the active
+// inference profiles, and the text models invocable on demand.
 func (c *BedrockClient) ListModels(ctx context.Context) ([]string, error) {
-	return []string{
-		"us.anthropic.claude-sonnet-4-20250514-v1:0",   // Claude Sonnet 4 (default)
-		"us.anthropic.claude-3-7-sonnet-20250219-v1:0", // Claude 3.7 Sonnet
-	}, nil
+	models, err := c.listAccountModels(ctx)
+	if err != nil {
+		klog.Warningf("Failed to list Bedrock models, showing the default ones: %v", err)
+		return bedrockDefaultModels, nil
+	}
+	return models, nil
+}
+
+// listAccountModels calls the ListInferenceProfiles and ListFoundationModels APIs.
+func (c *BedrockClient) listAccountModels(ctx context.Context) ([]string, error) {
+	var models []string
+	nextToken := ""
+	for {
+		query := url.Values{"maxResults": {"1000"}}
+		if nextToken != "" {
+			query.Set("nextToken", nextToken)
+		}
+		var profiles struct {
+			InferenceProfileSummaries []struct {
+				InferenceProfileID string `json:"inferenceProfileId"`
+				Status             string `json:"status"`
+			} `json:"inferenceProfileSummaries"`
+			NextToken string `json:"nextToken"`
+		}
+		if err := c.controlPlaneGet(ctx, "/inference-profiles?"+query.Encode(), &profiles); err != nil {
+			return nil, fmt.Errorf("listing inference profiles: %w", err)
+		}
+		for _, profile := range profiles.InferenceProfileSummaries {
+			if profile.Status == "ACTIVE" {
+				models = append(models, profile.InferenceProfileID)
+			}
+		}
+		if profiles.NextToken == "" {
+			break
+		}
+		nextToken = profiles.NextToken
+	}
+
+	var foundationModels struct {
+		ModelSummaries []struct {
+			ModelID                 string   `json:"modelId"`
+			InferenceTypesSupported []string `json:"inferenceTypesSupported"`
+		} `json:"modelSummaries"`
+	}
+	if err := c.controlPlaneGet(ctx, "/foundation-models?byOutputModality=TEXT", &foundationModels); err != nil {
+		return nil, fmt.Errorf("listing foundation models: %w", err)
+	}
+	for _, model := range foundationModels.ModelSummaries {
+		for _, inferenceType := range model.InferenceTypesSupported {
+			if inferenceType == "ON_DEMAND" {
+				models = append(models, model.ModelID)
+				break
+			}
+		}
+	}
+	return models, nil
+}
+
+// controlPlaneGet sends a GET request signed with SigV4 to the Bedrock control plane,
+// and decodes the JSON response into out.
+func (c *BedrockClient) controlPlaneGet(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.controlEndpoint+path, nil)
+	if err != nil {
+		return fmt.Errorf("building http request: %w", err)
+	}
+	if c.cfg.Credentials == nil {
+		return errors.New("no AWS credentials configured")
+	}
+	creds, err := c.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieving AWS credentials: %w", err)
+	}
+	emptyPayloadHash := hex.EncodeToString(sha256.New().Sum(nil))
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, emptyPayloadHash, "bedrock", c.cfg.Region, time.Now()); err != nil {
+		return fmt.Errorf("signing request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("performing http request: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: string(b), RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("unmarshalling json response: %w", err)
+	}
+	return nil
 }
 
 // bedrockChat implements the Chat interface for Bedrock conversations
@@ -141,6 +256,22 @@ type bedrockChat struct {
 	functionDefs []*FunctionDefinition
 }
 
+// inferenceConfig returns the inference parameters of the requests, from the generation
+// parameters of the client. Reasoning efforts are not supported by the Converse API.
+func (c *bedrockChat) inferenceConfig() *types.InferenceConfiguration {
+	generation := c.client.generation
+	config := &types.InferenceConfiguration{
+		MaxTokens:     aws.Int32(4096),
+		Temperature:   generation.Temperature,
+		TopP:          generation.TopP,
+		StopSequences: generation.StopSequences,
+	}
+	if generation.MaxOutputTokens > 0 {
+		config.MaxTokens = aws.Int32(int32(generation.MaxOutputTokens))
+	}
+	return config
+}
+
 func (cs *bedrockChat) Initialize(history []*api.Message) error {
 	cs.messages = make([]types.Message, 0, len(history))
 
@@ -201,11 +332,9 @@ func (c *bedrockChat) Send(ctx context.Context, contents ...any) (ChatResponse,
 
 	// Prepare the request
 	input := &bedrockruntime.ConverseInput{
-		ModelId:  aws.String(c.model),
-		Messages: c.messages,
-		InferenceConfig: &types.InferenceConfiguration{
-			MaxTokens: aws.Int32(4096),
-		},
+		ModelId:         aws.String(c.model),
+		Messages:        c.messages,
+		InferenceConfig: c.inferenceConfig(),
 	}
 
 	// Add system prompt if provided
@@ -255,11 +384,9 @@ func (c *bedrockChat) SendStreaming(ctx context.Context, contents ...any) (ChatR
 
 	// Prepare the streaming request
 	input := &bedrockruntime.ConverseStreamInput{
-		ModelId:  aws.String(c.model),
-		Messages: c.messages,
-		InferenceConfig: &types.InferenceConfiguration{
-			MaxTokens: aws.Int32(4096),
-		},
+		ModelId:         aws.String(c.model),
+		Messages:        c.messages,
+		InferenceConfig: c.inferenceConfig(),
 	}
 
 	// Add system prompt if provided
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestBedrockListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(auth, "/us-west-2/bedrock/") {
+			t.Errorf("Authorization = %q, want a SigV4 signature for bedrock in us-west-2", auth)
+		}
+		switch {
+		case r.URL.Path == "/inference-profiles" && r.URL.Query().Get("nextToken") == "":
+			fmt.Fprint(w, `{"inferenceProfileSummaries":[{"inferenceProfileId":"us.anthropic.claude-sonnet-4-20250514-v1:0","status":"ACTIVE"}],"nextToken":"page2"}`)
+		case r.URL.Path == "/inference-profiles":
+			fmt.Fprint(w, `{"inferenceProfileSummaries":[{"inferenceProfileId":"us.meta.llama3-3-70b-instruct-v1:0","status":"ACTIVE"},{"inferenceProfileId":"us.old-model","status":"INACTIVE"}]}`)
+		case r.URL.Path == "/foundation-models":
+			fmt.Fprint(w, `{"modelSummaries":[{"modelId":"amazon.nova-pro-v1:0","inferenceTypesSupported":["ON_DEMAND"]},{"modelId":"anthropic.claude-3-7-sonnet-20250219-v1:0","inferenceTypesSupported":["INFERENCE_PROFILE"]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	credentials := aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret"}, nil
+	})
+	client := &BedrockClient{
+		cfg:             aws.Config{Region: "us-west-2", Credentials: credentials},
+		httpClient:      server.Client(),
+		controlEndpoint: server.URL,
+	}
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	want := []string{"us.anthropic.claude-sonnet-4-20250514-v1:0", "us.meta.llama3-3-70b-instruct-v1:0", "amazon.nova-pro-v1:0"}
+	if !reflect.DeepEqual(models, want) {
+		t.Errorf("ListModels() = %v, want %v", models, want)
+	}
+
+	// Without permission to list the models, the defaults are listed
+	client.controlEndpoint = server.URL + "/denied"
+	models, err = client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if !reflect.DeepEqual(models, bedrockDefaultModels) {
+		t.Errorf("ListModels() = %v, want the default models", models)
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+// CachingClient is a Client returning the responses of identical earlier requests from a
+// directory on disk, keyed by a hash of the system prompt, the conversation, the tools
+// and the model. It avoids spending tokens on test suites, benchmark replays and prompt
+// iterations. Cached responses have no usage metadata.
+type CachingClient struct {
+	client Client
+	dir    string
+	schema *Schema
+	// generation are the generation parameters of the client, which change responses
+	generation GenerationConfig
+}
+
+var _ Client = &CachingClient{}
+
+// NewCachingClient wraps client with a cache stored in dir, which is created if needed.
+func NewCachingClient(client Client, dir string) (*CachingClient, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating LLM cache directory: %w", err)
+	}
+	return &CachingClient{client: client, dir: dir}, nil
+}
+
+func (c *CachingClient) Close() error {
+	return c.client.Close()
+}
+
+func (c *CachingClient) StartChat(systemPrompt, model string) Chat {
+	return &cachingChat{
+		client:       c,
+		chat:         c.client.StartChat(systemPrompt, model),
+		systemPrompt: systemPrompt,
+		model:        model,
+		issuedCalls:  make(map[string]bool),
+	}
+}
+
+func (c *CachingClient) GenerateCompletion(ctx context.Context, req *CompletionRequest) (CompletionResponse, error) {
+	key, err := cacheKey(map[string]any{"model": req.Model, "prompt": req.Prompt, "schema": c.schema, "generation": c.generation})
+	if err != nil {
+		return nil, err
+	}
+	var cached cachedResponse
+	if c.load(key, &cached) {
+		return &cached, nil
+	}
+
+	resp, err := c.client.GenerateCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, &cachedResponse{Content: []cachedPart{{Text: resp.Response()}}})
+	return resp, nil
+}
+
+func (c *CachingClient) SetResponseSchema(schema *Schema) error {
+	c.schema = schema
+	return c.client.SetResponseSchema(schema)
+}
+
+func (c *CachingClient) ListModels(ctx context.Context) ([]string, error) {
+	return c.client.ListModels(ctx)
+}
+
+// load reads the cached response for key into v, and reports whether there was one.
+func (c *CachingClient) load(key string, v any) bool {
+	b, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			klog.Warningf("reading LLM cache entry %s: %v", key, err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		klog.Warningf("LLM cache entry %s is not valid, ignoring it: %v", key, err)
+		return false
+	}
+	klog.V(2).Infof("LLM cache hit for %s", key)
+	return true
+}
+
+// store saves the response for key. Failures are logged, the cache being best effort.
+func (c *CachingClient) store(key string, resp *cachedResponse) {
+	b, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		klog.Warningf("encoding LLM cache entry %s: %v", key, err)
+		return
+	}
+	// Write to a temporary file first, so that readers never see partial entries
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		klog.Warningf("writing LLM cache entry %s: %v", key, err)
+		return
+	}
+	_, err = tmp.Write(b)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, key+".json"))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		klog.Warningf("writing LLM cache entry %s: %v", key, err)
+	}
+}
+
+// cacheKey returns the hex SHA-256 of the JSON encoding of v.
+func cacheKey(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("computing LLM cache key: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cachingChat is a chat answering requests from the cache when it can. Requests answered
+// from the cache do not reach the underlying chat, so when a request is not in the cache
+// after some were, a new chat is initialized with the conversation so far, as text.
+type cachingChat struct {
+	client       *CachingClient
+	chat         Chat
+	systemPrompt string
+	model        string
+	functionDefs []*FunctionDefinition
+
+	// history is the requests and responses so far, which the cache keys are computed from
+	history []any
+	// transcript is the conversation so far, to initialize a new chat with
+	transcript chatTranscript
+	// behind is true when chat has not seen the whole conversation
+	behind bool
+	// issuedCalls are the IDs of the function calls requested by chat, whose results
+	// can be sent to it as such
+	issuedCalls map[string]bool
+}
+
+var _ Chat = &cachingChat{}
+
+// key returns the cache key of a request with contents.
+func (c *cachingChat) key(contents []any) (string, error) {
+	return cacheKey(map[string]any{
+		"model":        c.model,
+		"systemPrompt": c.systemPrompt,
+		"tools":        c.functionDefs,
+		"schema":       c.client.schema,
+		"generation":   c.client.generation,
+		"messages":     append(append([]any(nil), c.history...), contents),
+	})
+}
+
+// catchUp returns the contents to send to chat, starting a new chat with the conversation
+// so far if chat is behind. The results of function calls chat did not request are sent
+// as text, since chat does not know about the calls.
+func (c *cachingChat) catchUp(contents []any) ([]any, error) {
+	if c.behind {
+		chat := c.client.client.StartChat(c.systemPrompt, c.model)
+		if c.functionDefs != nil {
+			if err := chat.SetFunctionDefinitions(c.functionDefs); err != nil {
+				return nil, err
+			}
+		}
+		if err := chat.Initialize(c.transcript.messages); err != nil {
+			return nil, fmt.Errorf("initializing chat with the cached conversation: %w", err)
+		}
+		c.chat = chat
+		c.behind = false
+		c.issuedCalls = make(map[string]bool)
+	}
+
+	out := make([]any, 0, len(contents))
+	for _, content := range contents {
+		if result, ok := content.(FunctionCallResult); ok && !c.issuedCalls[result.ID] {
+			out = append(out, contentText(result))
+			continue
+		}
+		out = append(out, content)
+	}
+	return out, nil
+}
+
+// record adds a request and its response to the conversation.
+func (c *cachingChat) record(contents []any, resp *cachedResponse, fromCache bool) {
+	c.history = append(c.history, contents, resp)
+	c.transcript.addContents(contents)
+	calls := c.transcript.addResponse(resp)
+	if fromCache {
+		c.behind = true
+		return
+	}
+	for _, call := range calls {
+		c.issuedCalls[call.ID] = true
+	}
+}
+
+func (c *cachingChat) Send(ctx context.Context, contents ...any) (ChatResponse, error) {
+	key, err := c.key(contents)
+	if err != nil {
+		return nil, err
+	}
+	var cached cachedResponse
+	if c.client.load(key, &cached) {
+		c.record(contents, &cached, true)
+		return &cached, nil
+	}
+
+	chatContents, err := c.catchUp(contents)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.chat.Send(ctx, chatContents...)
+	if err != nil {
+		return nil, err
+	}
+	toCache := newCachedResponse(resp)
+	c.client.store(key, toCache)
+	c.record(contents, toCache, false)
+	return resp, nil
+}
+
+// SendStreaming returns a cached response as a single chunk. Streamed responses are
+// cached once the stream completes without errors.
+func (c *cachingChat) SendStreaming(ctx context.Context, contents ...any) (ChatResponseIterator, error) {
+	key, err := c.key(contents)
+	if err != nil {
+		return nil, err
+	}
+	var cached cachedResponse
+	if c.client.load(key, &cached) {
+		c.record(contents, &cached, true)
+		return singletonChatResponseIterator(&cached), nil
+	}
+
+	chatContents, err := c.catchUp(contents)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.chat.SendStreaming(ctx, chatContents...)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(ChatResponse, error) bool) {
+		toCache := &cachedResponse{}
+		complete := false
+		defer func() {
+			if complete {
+				c.client.store(key, toCache)
+			}
+			c.record(contents, toCache, false)
+		}()
+		for resp, err := range stream {
+			if err == nil && resp != nil {
+				toCache.add(resp)
+			}
+			if !yield(resp, err) || err != nil {
+				return
+			}
+		}
+		complete = true
+	}, nil
+}
+
+func (c *cachingChat) SetFunctionDefinitions(functionDefinitions []*FunctionDefinition) error {
+	c.functionDefs = functionDefinitions
+	return c.chat.SetFunctionDefinitions(functionDefinitions)
+}
+
+func (c *cachingChat) IsRetryableError(err error) bool {
+	return c.chat.IsRetryableError(err)
+}
+
+// Initialize starts the conversation over with messages, which are part of the cache keys.
+func (c *cachingChat) Initialize(messages []*api.Message) error {
+	if err := c.chat.Initialize(messages); err != nil {
+		return err
+	}
+	c.history = []any{messages}
+	c.transcript = chatTranscript{}
+	c.transcript.addMessages(messages)
+	c.behind = false
+	c.issuedCalls = make(map[string]bool)
+	return nil
+}
+
+// cachedResponse is a response stored in the cache. It is both a ChatResponse and a
+// CompletionResponse, with a single candidate.
+type cachedResponse struct {
+	Content []cachedPart `json:"parts"`
+}
+
+type cachedPart struct {
+	Text          string         `json:"text,omitempty"`
+	FunctionCalls []FunctionCall `json:"functionCalls,omitempty"`
+}
+
+var _ ChatResponse = &cachedResponse{}
+var _ CompletionResponse = &cachedResponse{}
+
+// newCachedResponse returns the first candidate of resp, to cache it.
+func newCachedResponse(resp ChatResponse) *cachedResponse {
+	out := &cachedResponse{}
+	out.add(resp)
+	return out
+}
+
+// add appends the parts of the first candidate of resp, merging consecutive texts, as
+// streamed responses come in chunks.
+func (r *cachedResponse) add(resp ChatResponse) {
+	candidates := resp.Candidates()
+	if len(candidates) == 0 {
+		return
+	}
+	for _, part := range candidates[0].Parts() {
+		if text, ok := part.AsText(); ok && text != "" {
+			if n := len(r.Content); n > 0 && r.Content[n-1].FunctionCalls == nil {
+				r.Content[n-1].Text += text
+			} else {
+				r.Content = append(r.Content, cachedPart{Text: text})
+			}
+		}
+		if calls, ok := part.AsFunctionCalls(); ok && len(calls) > 0 {
+			r.Content = append(r.Content, cachedPart{FunctionCalls: calls})
+		}
+	}
+}
+
+func (r *cachedResponse) UsageMetadata() any {
+	return nil
+}
+
+func (r *cachedResponse) Candidates() []Candidate {
+	return []Candidate{r}
+}
+
+func (r *cachedResponse) Response() string {
+	return r.String()
+}
+
+// String returns the text of the response.
+func (r *cachedResponse) String() string {
+	var text string
+	for _, part := range r.Content {
+		text += part.Text
+	}
+	return text
+}
+
+func (r *cachedResponse) Parts() []Part {
+	parts := make([]Part, len(r.Content))
+	for i := range r.Content {
+		parts[i] = &r.Content[i]
+	}
+	return parts
+}
+
+func (p *cachedPart) AsText() (string, bool) {
+	return p.Text, p.FunctionCalls == nil
+}
+
+func (p *cachedPart) AsFunctionCalls() ([]FunctionCall, bool) {
+	return p.FunctionCalls, p.FunctionCalls != nil
+}
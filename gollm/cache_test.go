@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCachingChat(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeRouteClient{name: "answer"}
+	client, err := NewCachingClient(fake, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCachingClient() error = %v", err)
+	}
+	result := FunctionCallResult{ID: "call-1", Name: "kubectl", Result: map[string]any{"stdout": "nginx"}}
+
+	// converse sends a query, answered with a function call, and then its result.
+	converse := func(query string) []ChatResponse {
+		chat := client.StartChat("system", "model")
+		fake.call = &FunctionCall{ID: "call-1", Name: "kubectl", Arguments: map[string]any{"command": "kubectl get pods"}}
+		first, err := chat.Send(ctx, query)
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		stream, err := chat.SendStreaming(ctx, result)
+		if err != nil {
+			t.Fatalf("SendStreaming() error = %v", err)
+		}
+		responses := []ChatResponse{first}
+		for resp, err := range stream {
+			if err != nil {
+				t.Fatalf("stream error = %v", err)
+			}
+			responses = append(responses, resp)
+		}
+		return responses
+	}
+
+	converse("list pods")
+	if got, want := fake.sends, []string{"model: list pods", "model: result:call-1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("requests = %q, want %q", got, want)
+	}
+
+	fake.sends = nil
+	fake.err = &APIError{StatusCode: 500, Message: "the cache should answer"}
+	responses := converse("list pods")
+	if len(fake.sends) != 0 {
+		t.Errorf("requests = %q, want none for a cached conversation", fake.sends)
+	}
+	calls, ok := responses[0].Candidates()[0].Parts()[1].AsFunctionCalls()
+	if !ok || len(calls) != 1 || calls[0].ID != "call-1" || calls[0].Arguments["command"] != "kubectl get pods" {
+		t.Errorf("cached function calls = %v, want the call of the first conversation", calls)
+	}
+
+	// A different first query misses the cache, and so does the function result after it
+	fake.err = nil
+	converse("list services")
+	if len(fake.sends) != 2 {
+		t.Errorf("requests = %q, want 2 for a new conversation", fake.sends)
+	}
+
+	// The cached conversation continues on a new chat, initialized with it
+	fake.sends = nil
+	chat := client.StartChat("system", "model")
+	if _, err := chat.Send(ctx, "list pods"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := chat.Send(ctx, result); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := chat.Send(ctx, "thanks"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got, want := fake.sends, []string{"model: thanks"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+	initialized := fake.initialized[len(fake.initialized)-1]
+	if len(initialized) != 4 || !strings.Contains(initialized[2].Payload.(string), `Result of running "kubectl"`) {
+		t.Errorf("chat initialized with %v, want the cached conversation", initialized)
+	}
+}
+
+func TestCachingClientGenerateCompletion(t *testing.T) {
+	fake := &fakeRouteClient{name: "answer"}
+	client, err := NewCachingClient(fake, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCachingClient() error = %v", err)
+	}
+	for range 2 {
+		resp, err := client.GenerateCompletion(context.Background(), &CompletionRequest{Model: "model", Prompt: "classify"})
+		if err != nil || resp.Response() != "answer" {
+			t.Fatalf("GenerateCompletion() = %v, %v, want answer", resp, err)
+		}
+	}
+	if len(fake.sends) != 1 {
+		t.Errorf("requests = %q, want 1", fake.sends)
+	}
+}
@@ -17,18 +17,23 @@ package gollm
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"math/rand/v2"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	openai "github.com/openai/openai-go"
+	"google.golang.org/genai"
 
 	"k8s.io/klog/v2"
 )
@@ -53,6 +58,10 @@ func (r *registry) listProviders() []string {
 type ClientOptions struct {
 	URL           *url.URL
 	SkipVerifySSL bool
+	// CacheDir is the directory of the response cache, see CachingClient. Empty disables it.
+	CacheDir string
+	// Generation are the generation parameters of the requests.
+	Generation GenerationConfig
 	// Extend with more options as needed
 }
 
@@ -66,6 +75,13 @@ func WithSkipVerifySSL() Option {
 	}
 }
 
+// WithCacheDir caches the responses of the client in dir, see CachingClient.
+func WithCacheDir(dir string) Option {
+	return func(o *ClientOptions) {
+		o.CacheDir = dir
+	}
+}
+
 type FactoryFunc func(ctx context.Context, opts ClientOptions) (Client, error)
 
 func RegisterProvider(id string, factoryFunc FactoryFunc) error {
@@ -114,17 +130,31 @@ func (r *registry) NewClient(ctx context.Context, providerID string, opts ...Opt
 	if v := os.Getenv("LLM_SKIP_VERIFY_SSL"); v == "1" || strings.ToLower(v) == "true" {
 		clientOpts.SkipVerifySSL = true
 	}
+	clientOpts.CacheDir = os.Getenv("LLM_CACHE_DIR")
 	for _, opt := range opts {
 		opt(&clientOpts)
 	}
+	if err := clientOpts.Generation.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid generation parameters: %w", err)
+	}
 
-	return factoryFunc(ctx, clientOpts)
+	client, err := factoryFunc(ctx, clientOpts)
+	if err != nil || clientOpts.CacheDir == "" {
+		return client, err
+	}
+	cachingClient, err := NewCachingClient(client, clientOpts.CacheDir)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	cachingClient.generation = clientOpts.Generation
+	return cachingClient, nil
 }
 
 /*
 NewClient builds a Client based on the LLM_CLIENT environment variable or the provided providerID.
 If providerID is not empty, it overrides the value from LLM_CLIENT.
-Supports Option parameters and the LLM_SKIP_VERIFY_SSL environment variable.
+Supports Option parameters and the LLM_SKIP_VERIFY_SSL and LLM_CACHE_DIR environment variables.
 */
 func NewClient(ctx context.Context, providerID string, opts ...Option) (Client, error) {
 	if providerID == "" {
@@ -143,6 +173,9 @@ type APIError struct {
 	StatusCode int
 	Message    string
 	Err        error
+	// RetryAfter is how long the provider asked to wait before retrying, from the
+	// Retry-After header, or 0.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -157,10 +190,61 @@ func (e *APIError) Unwrap() error {
 }
 
 // IsRetryableFunc defines the signature for functions that check if an error is retryable.
-// TODO (droot): Adjust the signature to allow underlying client to relay the backoff
-// delay etc. for example, Gemini's error codes contain retryDelay information.
+// The delay requested by providers for rate limit errors is read by RateLimitDelay.
 type IsRetryableFunc func(error) bool
 
+// RateLimitDelay reports whether err is a rate limit or quota error, and how long the
+// provider asked to wait before retrying, or 0 if it did not say.
+func RateLimitDelay(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter, apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	var openAIErr *openai.Error
+	if errors.As(err, &openAIErr) {
+		if openAIErr.StatusCode != http.StatusTooManyRequests {
+			return 0, false
+		}
+		if openAIErr.Response != nil {
+			return parseRetryAfter(openAIErr.Response.Header.Get("Retry-After")), true
+		}
+		return 0, true
+	}
+	var geminiErr genai.APIError
+	if errors.As(err, &geminiErr) {
+		if geminiErr.Code != http.StatusTooManyRequests {
+			return 0, false
+		}
+		// Quota errors carry a google.rpc.RetryInfo detail, e.g. {"retryDelay": "20s"}
+		for _, detail := range geminiErr.Details {
+			if delay, ok := detail["retryDelay"].(string); ok {
+				if d, err := time.ParseDuration(delay); err == nil {
+					return d, true
+				}
+			}
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an HTTP date. It returns
+// 0 if the header is empty or not valid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
 // DefaultIsRetryableError provides a default implementation based on common HTTP codes and network errors.
 func DefaultIsRetryableError(err error) bool {
 	if err == nil {
@@ -206,13 +290,114 @@ func createCustomHTTPClient(skipVerify bool) *http.Client {
 	}
 }
 
-// RetryConfig holds the configuration for the retry mechanism (same as before)
+// RetryConfig holds the configuration for the retry mechanism.
+// In JSON, durations are strings such as "10s".
 type RetryConfig struct {
-	MaxAttempts    int
-	InitialBackoff time.Duration
-	MaxBackoff     time.Duration
-	BackoffFactor  float64
-	Jitter         bool
+	MaxAttempts    int           `json:"maxAttempts,omitempty"`
+	InitialBackoff time.Duration `json:"initialBackoff,omitempty"`
+	MaxBackoff     time.Duration `json:"maxBackoff,omitempty"`
+	BackoffFactor  float64       `json:"backoffFactor,omitempty"`
+	Jitter         bool          `json:"jitter,omitempty"`
+	// MaxRateLimitWait is the longest wait for rate limited requests. Providers asking to
+	// wait longer fail the request instead. 0 waits as long as providers ask.
+	MaxRateLimitWait time.Duration `json:"maxRateLimitWait,omitempty"`
+
+	// OnRetry is called before waiting to retry a failed attempt, e.g. to tell users.
+	OnRetry func(RetryStatus) `json:"-"`
+}
+
+// RetryStatus describes a failed attempt that is about to be retried.
+type RetryStatus struct {
+	// Attempt is the number of the failed attempt, starting at 1.
+	Attempt int
+	// Wait is how long until the next attempt.
+	Wait time.Duration
+	// RateLimited is true when the provider rejected the attempt for rate limits or quota.
+	RateLimited bool
+	Err         error
+}
+
+// WithDefaults returns the config with the unset fields taken from defaults.
+func (c RetryConfig) WithDefaults(defaults RetryConfig) RetryConfig {
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = defaults.MaxAttempts
+	}
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = defaults.InitialBackoff
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = defaults.MaxBackoff
+	}
+	if c.BackoffFactor == 0 {
+		c.BackoffFactor = defaults.BackoffFactor
+	}
+	if c.MaxRateLimitWait == 0 {
+		c.MaxRateLimitWait = defaults.MaxRateLimitWait
+	}
+	if c.OnRetry == nil {
+		c.OnRetry = defaults.OnRetry
+	}
+	c.Jitter = c.Jitter || defaults.Jitter
+	return c
+}
+
+// retryConfigJSON is RetryConfig with durations as strings.
+type retryConfigJSON struct {
+	MaxAttempts      int     `json:"maxAttempts,omitempty"`
+	InitialBackoff   string  `json:"initialBackoff,omitempty"`
+	MaxBackoff       string  `json:"maxBackoff,omitempty"`
+	BackoffFactor    float64 `json:"backoffFactor,omitempty"`
+	Jitter           bool    `json:"jitter,omitempty"`
+	MaxRateLimitWait string  `json:"maxRateLimitWait,omitempty"`
+}
+
+func (c RetryConfig) MarshalJSON() ([]byte, error) {
+	formatDuration := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return d.String()
+	}
+	return json.Marshal(retryConfigJSON{
+		MaxAttempts:      c.MaxAttempts,
+		InitialBackoff:   formatDuration(c.InitialBackoff),
+		MaxBackoff:       formatDuration(c.MaxBackoff),
+		BackoffFactor:    c.BackoffFactor,
+		Jitter:           c.Jitter,
+		MaxRateLimitWait: formatDuration(c.MaxRateLimitWait),
+	})
+}
+
+func (c *RetryConfig) UnmarshalJSON(b []byte) error {
+	var in retryConfigJSON
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	out := RetryConfig{
+		MaxAttempts:   in.MaxAttempts,
+		BackoffFactor: in.BackoffFactor,
+		Jitter:        in.Jitter,
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		out   *time.Duration
+	}{
+		{"initialBackoff", in.InitialBackoff, &out.InitialBackoff},
+		{"maxBackoff", in.MaxBackoff, &out.MaxBackoff},
+		{"maxRateLimitWait", in.MaxRateLimitWait, &out.MaxRateLimitWait},
+	} {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("parsing %s of retry config: %w", d.name, err)
+		}
+		*d.out = parsed
+	}
+	*c = out
+	return nil
 }
 
 // DefaultRetryConfig provides sensible defaults (same as before)
@@ -258,7 +443,8 @@ func Retry[T any](
 			// Context not cancelled, proceed with error checking
 		}
 
-		if !isRetryable(lastErr) {
+		rateLimitDelay, rateLimited := RateLimitDelay(lastErr)
+		if !rateLimited && !isRetryable(lastErr) {
 			log.Info("Attempt failed with non-retryable error", "attempt", attempt, "error", lastErr)
 			return zero, lastErr // Return the non-retryable error immediately
 		}
@@ -270,11 +456,20 @@ func Retry[T any](
 			break
 		}
 
-		// Calculate wait time
+		// Calculate wait time, as requested by the provider for rate limits if it said
 		waitTime := backoff
 		if config.Jitter {
 			waitTime += time.Duration(rand.Float64() * float64(backoff) / 2)
 		}
+		if rateLimitDelay > 0 {
+			if config.MaxRateLimitWait > 0 && rateLimitDelay > config.MaxRateLimitWait {
+				return zero, fmt.Errorf("provider asked to retry in %v, longer than the %v limit: %w", rateLimitDelay, config.MaxRateLimitWait, lastErr)
+			}
+			waitTime = rateLimitDelay
+		}
+		if config.OnRetry != nil {
+			config.OnRetry(RetryStatus{Attempt: attempt, Wait: waitTime, RateLimited: rateLimited, Err: lastErr})
+		}
 
 		log.V(2).Info("Waiting before next retry attempt", "waitTime", waitTime, "nextAttempt", attempt+1, "maxAttempts", config.MaxAttempts)
 
@@ -330,9 +525,40 @@ func (rc *retryChat[C]) Send(ctx context.Context, contents ...any) (ChatResponse
 	return Retry[ChatResponse](ctx, rc.config, rc.underlying.IsRetryableError, operation)
 }
 
-// Embed implements the Client interface for the retryClient decorator.
+// SendStreaming retries requests failing before their first chunk. Failures after
+// that are returned by the iterator, since the chunks were already consumed.
 func (rc *retryChat[C]) SendStreaming(ctx context.Context, contents ...any) (ChatResponseIterator, error) {
-	return rc.underlying.SendStreaming(ctx, contents...)
+	operation := func(ctx context.Context) (ChatResponseIterator, error) {
+		stream, err := rc.underlying.SendStreaming(ctx, contents...)
+		if err != nil {
+			return nil, err
+		}
+		return peekStream(stream)
+	}
+	return Retry[ChatResponseIterator](ctx, rc.config, rc.underlying.IsRetryableError, operation)
+}
+
+// peekStream reads the first chunk of stream, returning its error if it failed, or an
+// iterator over the whole stream otherwise.
+func peekStream(stream ChatResponseIterator) (ChatResponseIterator, error) {
+	next, stop := iter.Pull2(iter.Seq2[ChatResponse, error](stream))
+	first, err, ok := next()
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	return func(yield func(ChatResponse, error) bool) {
+		defer stop()
+		if !ok || !yield(first, nil) {
+			return
+		}
+		for {
+			resp, err, ok := next()
+			if !ok || !yield(resp, err) {
+				return
+			}
+		}
+	}, nil
 }
 
 func (rc *retryChat[C]) SetFunctionDefinitions(functionDefinitions []*FunctionDefinition) error {
@@ -0,0 +1,144 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// flakyChat fails the first failures streaming requests with err, in the stream.
+type flakyChat struct {
+	*fakeRouteChat
+	failures int
+	err      error
+}
+
+func (c *flakyChat) SendStreaming(ctx context.Context, contents ...any) (ChatResponseIterator, error) {
+	if c.failures > 0 {
+		c.failures--
+		return func(yield func(ChatResponse, error) bool) {
+			yield(nil, c.err)
+		}, nil
+	}
+	return c.fakeRouteChat.SendStreaming(ctx, contents...)
+}
+
+func TestRetryChatStreamingRateLimit(t *testing.T) {
+	client := &fakeRouteClient{name: "model"}
+	chat := &flakyChat{
+		fakeRouteChat: &fakeRouteChat{client: client},
+		failures:      1,
+		err:           &APIError{StatusCode: 429, Message: "quota", RetryAfter: 5 * time.Millisecond},
+	}
+	var statuses []RetryStatus
+	retrying := NewRetryChat(chat, RetryConfig{
+		MaxAttempts:    2,
+		InitialBackoff: time.Hour,
+		OnRetry:        func(status RetryStatus) { statuses = append(statuses, status) },
+	})
+
+	stream, err := retrying.SendStreaming(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("SendStreaming() error = %v", err)
+	}
+	var text string
+	for resp, err := range stream {
+		if err != nil {
+			t.Fatalf("stream error = %v", err)
+		}
+		text += resp.Candidates()[0].String()
+	}
+	if text != "model" {
+		t.Errorf("text = %q, want the response of the retried request", text)
+	}
+	if len(statuses) != 1 || !statuses[0].RateLimited || statuses[0].Wait != 5*time.Millisecond {
+		t.Errorf("retry statuses = %+v, want one rate limited wait of the Retry-After delay", statuses)
+	}
+}
+
+func TestRetryRateLimitWaitLimit(t *testing.T) {
+	quotaErr := &APIError{StatusCode: 429, Message: "quota", RetryAfter: time.Hour}
+	attempts := 0
+	_, err := Retry(context.Background(), RetryConfig{MaxAttempts: 3, MaxRateLimitWait: time.Minute}, DefaultIsRetryableError,
+		func(ctx context.Context) (string, error) {
+			attempts++
+			return "", quotaErr
+		})
+	if !errors.Is(err, quotaErr) || attempts != 1 {
+		t.Errorf("Retry() = %v after %d attempts, want the quota error after 1 attempt", err, attempts)
+	}
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		wantDelay       time.Duration
+		wantRateLimited bool
+	}{
+		{
+			name:            "retry after",
+			err:             fmt.Errorf("sending: %w", &APIError{StatusCode: 429, RetryAfter: 20 * time.Second}),
+			wantDelay:       20 * time.Second,
+			wantRateLimited: true,
+		},
+		{
+			name: "server error",
+			err:  &APIError{StatusCode: 503},
+		},
+		{
+			name: "gemini retry info",
+			err: genai.APIError{Code: 429, Details: []map[string]any{
+				{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "31s"},
+			}},
+			wantDelay:       31 * time.Second,
+			wantRateLimited: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, rateLimited := RateLimitDelay(tt.err)
+			if delay != tt.wantDelay || rateLimited != tt.wantRateLimited {
+				t.Errorf("RateLimitDelay() = %v, %v, want %v, %v", delay, rateLimited, tt.wantDelay, tt.wantRateLimited)
+			}
+		})
+	}
+	if got := parseRetryAfter("120"); got != 2*time.Minute {
+		t.Errorf("parseRetryAfter(120) = %v, want 2m", got)
+	}
+}
+
+func TestRetryConfigJSON(t *testing.T) {
+	var config RetryConfig
+	if err := json.Unmarshal([]byte(`{"maxAttempts": 5, "initialBackoff": "2s", "maxRateLimitWait": "1m"}`), &config); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	config = config.WithDefaults(RetryConfig{MaxBackoff: time.Minute, BackoffFactor: 2, Jitter: true})
+	want := RetryConfig{MaxAttempts: 5, InitialBackoff: 2 * time.Second, MaxBackoff: time.Minute, BackoffFactor: 2, Jitter: true, MaxRateLimitWait: time.Minute}
+	if config.MaxAttempts != want.MaxAttempts || config.InitialBackoff != want.InitialBackoff || config.MaxBackoff != want.MaxBackoff ||
+		config.BackoffFactor != want.BackoffFactor || config.Jitter != want.Jitter || config.MaxRateLimitWait != want.MaxRateLimitWait {
+		t.Errorf("config = %+v, want %+v", config, want)
+	}
+	if err := json.Unmarshal([]byte(`{"initialBackoff": "soon"}`), &config); err == nil {
+		t.Errorf("Unmarshal() of an invalid duration succeeded")
+	}
+}
@@ -46,7 +46,7 @@ func init() {
 // geminiFactory is the provider factory function for Gemini.
 // Supports ClientOptions for consistency, but skipVerifySSL is not used.
 func geminiFactory(ctx context.Context, opts ClientOptions) (Client, error) {
-	opt := GeminiAPIClientOptions{}
+	opt := GeminiAPIClientOptions{Generation: opts.Generation}
 	return NewGeminiAPIClient(ctx, opt)
 }
 
@@ -54,6 +54,8 @@ func geminiFactory(ctx context.Context, opts ClientOptions) (Client, error) {
 type GeminiAPIClientOptions struct {
 	// API Key for GenAI. Required for BackendGeminiAPI.
 	APIKey string
+	// Generation are the generation parameters of the requests.
+	Generation GenerationConfig
 }
 
 // NewGeminiAPIClient builds a client for the Gemini API.
@@ -80,7 +82,8 @@ func NewGeminiAPIClient(ctx context.Context, opt GeminiAPIClientOptions) (*Googl
 	}
 
 	return &GoogleAIClient{
-		client: client,
+		client:     client,
+		generation: opt.Generation,
 	}, nil
 }
 
@@ -90,12 +93,14 @@ type VertexAIClientOptions struct {
 	Project string
 	// GCP Location/Region for Vertex AI. Required for BackendVertexAI. See https://cloud.google.com/vertex-ai/docs/general/locations
 	Location string
+	// Generation are the generation parameters of the requests.
+	Generation GenerationConfig
 }
 
 // vertexaiViaGeminiFactory is the provider factory function for VertexAI via Gemini.
 // Supports ClientOptions for consistency, but skipVerifySSL is not used.
 func vertexaiViaGeminiFactory(ctx context.Context, opts ClientOptions) (Client, error) {
-	opt := VertexAIClientOptions{}
+	opt := VertexAIClientOptions{Generation: opts.Generation}
 	return NewVertexAIClient(ctx, opt)
 }
 
@@ -182,7 +187,8 @@ func NewVertexAIClient(ctx context.Context, opt VertexAIClientOptions) (*GoogleA
 	}
 
 	return &GoogleAIClient{
-		client: client,
+		client:     client,
+		generation: opt.Generation,
 	}, nil
 }
 
@@ -193,6 +199,9 @@ type GoogleAIClient struct {
 
 	// responseSchema will constrain the output to match the given schema
 	responseSchema *genai.Schema
+
+	// generation overrides the default generation parameters
+	generation GenerationConfig
 }
 
 var _ Client = &GoogleAIClient{}
@@ -233,14 +242,12 @@ func (c *GoogleAIClient) SetResponseSchema(responseSchema *Schema) error {
 func (c *GoogleAIClient) GenerateCompletion(ctx context.Context, request *CompletionRequest) (CompletionResponse, error) {
 	log := klog.FromContext(ctx)
 
-	var config *genai.GenerateContentConfig
-
+	config := &genai.GenerateContentConfig{}
 	if c.responseSchema != nil {
-		config = &genai.GenerateContentConfig{
-			ResponseSchema:   c.responseSchema,
-			ResponseMIMEType: "application/json",
-		}
+		config.ResponseSchema = c.responseSchema
+		config.ResponseMIMEType = "application/json"
 	}
+	applyGeminiGenerationConfig(config, c.generation)
 
 	content := []*genai.Content{
 		{Role: "user", Parts: []*genai.Part{{Text: request.Prompt}}},
@@ -295,9 +302,57 @@ func (c *GoogleAIClient) StartChat(systemPrompt string, model string) Chat {
 		chat.genConfig.ResponseSchema = c.responseSchema
 		chat.genConfig.ResponseMIMEType = "application/json"
 	}
+	applyGeminiGenerationConfig(chat.genConfig, c.generation)
 	return chat
 }
 
+// applyGeminiGenerationConfig sets the parameters of generation that are set in config.
+func applyGeminiGenerationConfig(config *genai.GenerateContentConfig, generation GenerationConfig) {
+	if generation.Temperature != nil {
+		config.Temperature = generation.Temperature
+	}
+	if generation.TopP != nil {
+		config.TopP = generation.TopP
+	}
+	if generation.MaxOutputTokens > 0 {
+		config.MaxOutputTokens = int32(generation.MaxOutputTokens)
+	}
+	if len(generation.StopSequences) > 0 {
+		config.StopSequences = generation.StopSequences
+	}
+	if budget := generation.ReasoningEffort.thinkingBudget(); budget > 0 {
+		thinkingBudget := int32(budget)
+		config.ThinkingConfig = &genai.ThinkingConfig{ThinkingBudget: &thinkingBudget}
+	}
+	if generation.IncludeThoughts {
+		if config.ThinkingConfig == nil {
+			config.ThinkingConfig = &genai.ThinkingConfig{}
+		}
+		config.ThinkingConfig.IncludeThoughts = true
+	}
+}
+
+// withoutThoughts returns content without its thought parts, which are not sent back to
+// the model, or nil if it only has thoughts.
+func withoutThoughts(content *genai.Content) *genai.Content {
+	if content == nil {
+		return nil
+	}
+	var parts []*genai.Part
+	for _, part := range content.Parts {
+		if !part.Thought {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	if len(parts) == len(content.Parts) {
+		return content
+	}
+	return &genai.Content{Role: content.Role, Parts: parts}
+}
+
 // GeminiChat is a chat with the model.
 // It implements the Chat interface.
 type GeminiChat struct {
@@ -417,12 +472,16 @@ func (c *GeminiChat) Send(ctx context.Context, contents ...any) (ChatResponse, e
 	c.history = append(c.history, genaiContent)
 	result, err := c.client.Models.GenerateContent(ctx, c.model, c.history, c.genConfig)
 	if err != nil {
+		// Forget the request, so that it is not sent twice when retried
+		c.history = c.history[:len(c.history)-1]
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
 	if result == nil || len(result.Candidates) == 0 {
 		return nil, fmt.Errorf("no response from Gemini")
 	}
-	c.history = append(c.history, result.Candidates[0].Content)
+	if content := withoutThoughts(result.Candidates[0].Content); content != nil {
+		c.history = append(c.history, content)
+	}
 	geminiResponse := result
 	log.V(1).Info("got LLM response", "response", geminiResponse)
 	return &GeminiChatResponse{geminiResponse: geminiResponse}, nil
@@ -443,6 +502,7 @@ func (c *GeminiChat) SendStreaming(ctx context.Context, contents ...any) (ChatRe
 	}
 
 	c.history = append(c.history, genaiContent)
+	requestIndex := len(c.history) - 1
 	stream := c.client.Models.GenerateContentStream(ctx, c.model, c.history, c.genConfig)
 
 	return func(yield func(ChatResponse, error) bool) {
@@ -455,6 +515,11 @@ func (c *GeminiChat) SendStreaming(ctx context.Context, contents ...any) (ChatRe
 			}
 
 			if err != nil {
+				if len(c.history) == requestIndex+1 {
+					// Nothing was received, forget the request so that it is not sent
+					// twice when retried
+					c.history = c.history[:requestIndex]
+				}
 				// Always check for and yield an error first.
 				yield(nil, err)
 				return
@@ -480,7 +545,9 @@ func (c *GeminiChat) SendStreaming(ctx context.Context, contents ...any) (ChatRe
 				log.V(1).Info("empty response probably with STOP finishedReason")
 				return
 			}
-			c.history = append(c.history, content)
+			if content := withoutThoughts(content); content != nil {
+				c.history = append(c.history, content)
+			}
 			// yield only when we have a non-empty response
 			if !yield(&GeminiChatResponse{geminiResponse: geminiResponse}, err) {
 				return
@@ -607,7 +674,15 @@ type GeminiPart struct {
 
 // AsText returns the text of the part.
 func (p *GeminiPart) AsText() (string, bool) {
-	if p.part.Text != "" {
+	if p.part.Text != "" && !p.part.Thought {
+		return p.part.Text, true
+	}
+	return "", false
+}
+
+// AsThought returns the thought summary of the part, for thinking models.
+func (p *GeminiPart) AsThought() (string, bool) {
+	if p.part.Text != "" && p.part.Thought {
 		return p.part.Text, true
 	}
 	return "", false
@@ -0,0 +1,87 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"fmt"
+)
+
+// GenerationConfig are the sampling and length parameters of the requests to the LLM.
+// Unset parameters keep the defaults of the provider. Providers ignore the parameters
+// they do not support.
+type GenerationConfig struct {
+	// Temperature controls the randomness of responses, lower being more deterministic.
+	Temperature *float32 `json:"temperature,omitempty"`
+	// TopP is the nucleus sampling probability mass.
+	TopP *float32 `json:"topP,omitempty"`
+	// MaxOutputTokens caps the tokens of each response.
+	MaxOutputTokens int `json:"maxOutputTokens,omitempty"`
+	// StopSequences end responses when generated.
+	StopSequences []string `json:"stopSequences,omitempty"`
+	// ReasoningEffort is how much models that reason before answering should reason.
+	ReasoningEffort ReasoningEffort `json:"reasoningEffort,omitempty"`
+	// IncludeThoughts asks models that reason before answering to return their reasoning,
+	// or a summary of it, as ThoughtParts.
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+}
+
+// ReasoningEffort is how much a model reasons before answering.
+type ReasoningEffort string
+
+const (
+	ReasoningEffortLow    ReasoningEffort = "low"
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	ReasoningEffortHigh   ReasoningEffort = "high"
+)
+
+// thinkingBudget returns the tokens models with a thinking budget can spend on reasoning
+// for the effort, or 0 if the effort is not set.
+func (e ReasoningEffort) thinkingBudget() int {
+	switch e {
+	case ReasoningEffortLow:
+		return 1024
+	case ReasoningEffortMedium:
+		return 8192
+	case ReasoningEffortHigh:
+		return 24576
+	}
+	return 0
+}
+
+// Validate checks that the parameters are in range.
+func (c *GenerationConfig) Validate() error {
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > 2) {
+		return fmt.Errorf("temperature %v is not between 0 and 2", *c.Temperature)
+	}
+	if c.TopP != nil && (*c.TopP < 0 || *c.TopP > 1) {
+		return fmt.Errorf("top-p %v is not between 0 and 1", *c.TopP)
+	}
+	if c.MaxOutputTokens < 0 {
+		return fmt.Errorf("max output tokens %d is negative", c.MaxOutputTokens)
+	}
+	switch c.ReasoningEffort {
+	case "", ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh:
+	default:
+		return fmt.Errorf("reasoning effort %q is not known, must be low, medium or high", c.ReasoningEffort)
+	}
+	return nil
+}
+
+// WithGenerationConfig sets the generation parameters of the requests of the client.
+func WithGenerationConfig(config GenerationConfig) Option {
+	return func(o *ClientOptions) {
+		o.Generation = config
+	}
+}
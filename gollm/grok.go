@@ -43,7 +43,8 @@ func newGrokClientFactory(ctx context.Context, opts ClientOptions) (Client, erro
 
 // GrokClient implements the gollm.Client interface for X.AI's Grok model.
 type GrokClient struct {
-	client openai.Client
+	client     openai.Client
+	generation GenerationConfig
 }
 
 // Ensure GrokClient implements the Client interface.
@@ -70,6 +71,7 @@ func NewGrokClient(ctx context.Context, opts ClientOptions) (*GrokClient, error)
 	// Use the OpenAI client with custom base URL and custom HTTP client
 	httpClient := createCustomHTTPClient(opts.SkipVerifySSL)
 	return &GrokClient{
+		generation: opts.Generation,
 		client: openai.NewClient(
 			option.WithAPIKey(apiKey),
 			option.WithBaseURL(endpoint),
@@ -100,9 +102,10 @@ func (c *GrokClient) StartChat(systemPrompt, model string) Chat {
 	}
 
 	return &grokChatSession{
-		client:  c.client,
-		history: history,
-		model:   model,
+		client:     c.client,
+		history:    history,
+		model:      model,
+		generation: c.generation,
 	}
 }
 
@@ -134,6 +137,7 @@ func (c *GrokClient) GenerateCompletion(ctx context.Context, req *CompletionRequ
 			openai.UserMessage(req.Prompt),
 		},
 	}
+	applyOpenAIGenerationConfig(&chatReq, c.generation)
 
 	completion, err := c.client.Chat.Completions.New(ctx, chatReq)
 	if err != nil {
@@ -174,6 +178,7 @@ type grokChatSession struct {
 	model               string
 	functionDefinitions []*FunctionDefinition            // Stored in gollm format
 	tools               []openai.ChatCompletionToolParam // Stored in OpenAI format
+	generation          GenerationConfig
 }
 
 // Ensure grokChatSession implements the Chat interface.
@@ -247,6 +252,7 @@ func (cs *grokChatSession) Send(ctx context.Context, contents ...any) (ChatRespo
 		chatReq.Tools = cs.tools
 		// chatReq.ToolChoice = openai.ToolChoiceAuto // Or specify if needed
 	}
+	applyOpenAIGenerationConfig(&chatReq, cs.generation)
 
 	// Call the Grok API
 	klog.V(1).InfoS("Sending request to Grok Chat API", "model", cs.model, "messages", len(chatReq.Messages), "tools", len(chatReq.Tools))
@@ -309,6 +315,7 @@ func (cs *grokChatSession) SendStreaming(ctx context.Context, contents ...any) (
 	if len(cs.tools) > 0 {
 		chatReq.Tools = cs.tools
 	}
+	applyOpenAIGenerationConfig(&chatReq, cs.generation)
 
 	// Start the Grok streaming request
 	klog.V(1).InfoS("Sending streaming request to Grok API",
@@ -172,3 +172,14 @@ type Part interface {
 	// if the part is not a function call, it returns (nil, false)
 	AsFunctionCalls() ([]FunctionCall, bool)
 }
+
+// ThoughtPart is implemented by the parts of models that reason before answering. The
+// reasoning is not part of the answer: AsText returns false for reasoning parts, and
+// chats do not send reasoning back to the model unless its API requires it.
+type ThoughtPart interface {
+	Part
+
+	// AsThought returns the reasoning of the part.
+	// if the part is not reasoning, it returns ("", false)
+	AsThought() (string, bool)
+}
@@ -45,6 +45,7 @@ type LlamaCppClient struct {
 	baseURL        *url.URL
 	httpClient     *http.Client
 	responseSchema *llamacppSchema
+	generation     GenerationConfig
 }
 
 type LlamaCppChat struct {
@@ -75,6 +76,7 @@ func NewLlamaCppClient(ctx context.Context, opts ClientOptions) (*LlamaCppClient
 	return &LlamaCppClient{
 		baseURL:    baseURL,
 		httpClient: httpClient,
+		generation: opts.Generation,
 	}, nil
 }
 
@@ -84,8 +86,12 @@ func (c *LlamaCppClient) Close() error {
 
 func (c *LlamaCppClient) GenerateCompletion(ctx context.Context, request *CompletionRequest) (CompletionResponse, error) {
 	llamacppRequest := &llamacppCompletionRequest{
-		Prompt:     request.Prompt,
-		JSONSchema: c.responseSchema,
+		Prompt:      request.Prompt,
+		JSONSchema:  c.responseSchema,
+		Temperature: c.generation.Temperature,
+		TopP:        c.generation.TopP,
+		NPredict:    c.generation.MaxOutputTokens,
+		Stop:        c.generation.StopSequences,
 	}
 
 	llamacppResponse, err := c.doCompletion(ctx, llamacppRequest)
@@ -218,7 +224,11 @@ func (c *LlamaCppChat) Send(ctx context.Context, contents ...any) (ChatResponse,
 		Model:    c.model,
 		Messages: c.history,
 		// Stream:   ptrTo(false),
-		Tools: c.tools,
+		Tools:       c.tools,
+		Temperature: c.client.generation.Temperature,
+		TopP:        c.client.generation.TopP,
+		MaxTokens:   c.client.generation.MaxOutputTokens,
+		Stop:        c.client.generation.StopSequences,
 	}
 
 	var llmacppResponse *LlamaCppChatResponse
@@ -435,6 +445,11 @@ type llamacppCompletionRequest struct {
 	Prompt string `json:"prompt,omitempty"`
 
 	JSONSchema *llamacppSchema `json:"json_schema,omitempty"`
+
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+	NPredict    int      `json:"n_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
 }
 
 type llamacppCompletionResponse struct {
@@ -485,6 +500,11 @@ type llamacppChatRequest struct {
 	Model    string                `json:"model,omitempty"`
 	Messages []llamacppChatMessage `json:"messages,omitempty"`
 	Tools    []llamacppTool        `json:"tools,omitempty"`
+
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
 }
 
 type llamacppChatResponse struct {
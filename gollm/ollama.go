@@ -43,7 +43,10 @@ const (
 )
 
 type OllamaClient struct {
-	client *api.Client
+	client     *api.Client
+	generation GenerationConfig
+	// responseSchema constrains the output of chats started afterwards and of completions
+	responseSchema json.RawMessage
 }
 
 type OllamaChat struct {
@@ -51,6 +54,31 @@ type OllamaChat struct {
 	model   string
 	history []api.Message
 	tools   []api.Tool
+
+	generation     GenerationConfig
+	responseSchema json.RawMessage
+}
+
+// ollamaOptions returns the model options for the parameters of generation that are set,
+// or nil. Reasoning efforts are not supported.
+func ollamaOptions(generation GenerationConfig) map[string]any {
+	options := make(map[string]any)
+	if generation.Temperature != nil {
+		options["temperature"] = *generation.Temperature
+	}
+	if generation.TopP != nil {
+		options["top_p"] = *generation.TopP
+	}
+	if generation.MaxOutputTokens > 0 {
+		options["num_predict"] = generation.MaxOutputTokens
+	}
+	if len(generation.StopSequences) > 0 {
+		options["stop"] = generation.StopSequences
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return options
 }
 
 var _ Client = &OllamaClient{}
@@ -63,7 +91,8 @@ func NewOllamaClient(ctx context.Context, opts ClientOptions) (*OllamaClient, er
 	client := api.NewClient(envconfig.Host(), httpClient)
 
 	return &OllamaClient{
-		client: client,
+		client:     client,
+		generation: opts.Generation,
 	}, nil
 }
 
@@ -73,9 +102,11 @@ func (c *OllamaClient) Close() error {
 
 func (c *OllamaClient) GenerateCompletion(ctx context.Context, request *CompletionRequest) (CompletionResponse, error) {
 	req := &api.GenerateRequest{
-		Model:  request.Model,
-		Prompt: request.Prompt,
-		Stream: ptrTo(false),
+		Model:   request.Model,
+		Prompt:  request.Prompt,
+		Stream:  ptrTo(false),
+		Options: ollamaOptions(c.generation),
+		Format:  c.responseSchema,
 	}
 
 	var ollamaResponse *OllamaCompletionResponse
@@ -107,14 +138,28 @@ func (c *OllamaClient) ListModels(ctx context.Context) ([]string, error) {
 	return models, nil
 }
 
+// SetResponseSchema constrains LLM responses to match the provided schema, using
+// structured outputs. It applies to completions and to chats started afterwards.
+// Calling with nil will clear the current schema.
 func (c *OllamaClient) SetResponseSchema(schema *Schema) error {
+	if schema == nil {
+		c.responseSchema = nil
+		return nil
+	}
+	responseSchema, err := schema.ToRawSchema()
+	if err != nil {
+		return err
+	}
+	c.responseSchema = responseSchema
 	return nil
 }
 
 func (c *OllamaClient) StartChat(systemPrompt, model string) Chat {
 	return &OllamaChat{
-		client: c.client,
-		model:  model,
+		client:         c.client,
+		model:          model,
+		generation:     c.generation,
+		responseSchema: c.responseSchema,
 		history: []api.Message{
 			{
 				Role:    "system",
@@ -161,8 +206,10 @@ func (c *OllamaChat) Send(ctx context.Context, contents ...any) (ChatResponse, e
 		Model:    c.model,
 		Messages: c.history,
 		// set streaming to false
-		Stream: new(bool),
-		Tools:  c.tools,
+		Stream:  new(bool),
+		Tools:   c.tools,
+		Options: ollamaOptions(c.generation),
+		Format:  c.responseSchema,
 	}
 
 	var ollamaResponse *OllamaChatResponse
@@ -25,6 +25,7 @@ import (
 	openai "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/responses"
+	"github.com/openai/openai-go/shared"
 	"k8s.io/klog/v2"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
@@ -73,7 +74,10 @@ func init() {
 
 // OpenAIClient implements the gollm.Client interface for OpenAI models.
 type OpenAIClient struct {
-	client openai.Client
+	client     openai.Client
+	generation GenerationConfig
+	// responseSchema constrains the output of chats started afterwards and of completions
+	responseSchema map[string]any
 }
 
 // Ensure OpenAIClient implements the Client interface.
@@ -108,7 +112,8 @@ func NewOpenAIClient(ctx context.Context, opts ClientOptions) (*OpenAIClient, er
 	options = append(options, option.WithHTTPClient(httpClient))
 
 	return &OpenAIClient{
-		client: openai.NewClient(options...),
+		client:     openai.NewClient(options...),
+		generation: opts.Generation,
 	}, nil
 }
 
@@ -139,20 +144,27 @@ func (c *OpenAIClient) StartChat(systemPrompt, model string) Chat {
 			})
 		}
 
+		params := responses.ResponseNewParams{
+			Model:           selectedModel,
+			Temperature:     openai.Float(0.2),
+			MaxOutputTokens: openai.Int(2048),
+			Reasoning: responses.ReasoningParam{
+				Effort: responses.ReasoningEffortLow,
+			},
+			Store: openai.Bool(false),
+		}
+		applyOpenAIResponseGenerationConfig(&params, c.generation)
+		if c.responseSchema != nil {
+			params.Text = responses.ResponseTextConfigParam{
+				Format: responses.ResponseFormatTextConfigParamOfJSONSchema("response", c.responseSchema),
+			}
+		}
 		return &openAIResponseChatSession{
 			client:  c.client,
 			history: history,
 			model:   selectedModel,
 			// functionDefinitions and tools will be set later via SetFunctionDefinitions
-			params: responses.ResponseNewParams{
-				Model:           selectedModel,
-				Temperature:     openai.Float(0.2),
-				MaxOutputTokens: openai.Int(2048),
-				Reasoning: responses.ReasoningParam{
-					Effort: responses.ReasoningEffortLow,
-				},
-				Store: openai.Bool(false),
-			},
+			params: params,
 		}
 	}
 	// by default use completion endpoint
@@ -164,13 +176,56 @@ func (c *OpenAIClient) StartChat(systemPrompt, model string) Chat {
 	}
 
 	return &openAIChatSession{
-		client:  c.client,
-		history: history,
-		model:   selectedModel,
+		client:         c.client,
+		history:        history,
+		model:          selectedModel,
+		generation:     c.generation,
+		responseSchema: c.responseSchema,
 		// functionDefinitions and tools will be set later via SetFunctionDefinitions
 	}
 }
 
+// applyOpenAIGenerationConfig sets the parameters of generation that are set in req.
+// It is shared by the providers speaking the Chat Completions API.
+func applyOpenAIGenerationConfig(req *openai.ChatCompletionNewParams, generation GenerationConfig) {
+	if generation.Temperature != nil {
+		req.Temperature = openai.Float(float64(*generation.Temperature))
+	}
+	if generation.TopP != nil {
+		req.TopP = openai.Float(float64(*generation.TopP))
+	}
+	if generation.MaxOutputTokens > 0 {
+		req.MaxCompletionTokens = openai.Int(int64(generation.MaxOutputTokens))
+	}
+	if len(generation.StopSequences) > 0 {
+		req.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: generation.StopSequences}
+	}
+	if generation.ReasoningEffort != "" {
+		req.ReasoningEffort = openai.ReasoningEffort(generation.ReasoningEffort)
+	}
+}
+
+// applyOpenAIResponseGenerationConfig sets the parameters of generation that are set in
+// params, for the Responses API, which has no stop sequences. Only the Responses API
+// returns the reasoning of OpenAI models, as summaries.
+func applyOpenAIResponseGenerationConfig(params *responses.ResponseNewParams, generation GenerationConfig) {
+	if generation.Temperature != nil {
+		params.Temperature = openai.Float(float64(*generation.Temperature))
+	}
+	if generation.TopP != nil {
+		params.TopP = openai.Float(float64(*generation.TopP))
+	}
+	if generation.MaxOutputTokens > 0 {
+		params.MaxOutputTokens = openai.Int(int64(generation.MaxOutputTokens))
+	}
+	if generation.ReasoningEffort != "" {
+		params.Reasoning.Effort = responses.ReasoningEffort(generation.ReasoningEffort)
+	}
+	if generation.IncludeThoughts {
+		params.Reasoning.Summary = responses.ReasoningSummaryAuto
+	}
+}
+
 // simpleCompletionResponse is a basic implementation of CompletionResponse.
 type simpleCompletionResponse struct {
 	content string
@@ -192,12 +247,15 @@ func (c *OpenAIClient) GenerateCompletion(ctx context.Context, req *CompletionRe
 	klog.V(1).Infof("Prompt:\n%s", req.Prompt)
 
 	// Use the Chat Completions API with the new v1.0.0 API
-	completion, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+	chatReq := openai.ChatCompletionNewParams{
 		Model: openai.ChatModel(req.Model),
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(req.Prompt),
 		},
-	})
+	}
+	applyOpenAIGenerationConfig(&chatReq, c.generation)
+	chatReq.ResponseFormat = openAIResponseFormat(c.responseSchema)
+	completion, err := c.client.Chat.Completions.New(ctx, chatReq)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate OpenAI completion: %w", err)
@@ -216,12 +274,46 @@ func (c *OpenAIClient) GenerateCompletion(ctx context.Context, req *CompletionRe
 	return resp, nil
 }
 
-// SetResponseSchema is not implemented yet.
+// SetResponseSchema constrains LLM responses to match the provided schema, using
+// Structured Outputs. It applies to completions and to chats started afterwards.
+// Calling with nil will clear the current schema.
 func (c *OpenAIClient) SetResponseSchema(schema *Schema) error {
-	klog.Warning("OpenAIClient.SetResponseSchema is not implemented yet")
+	if schema == nil {
+		c.responseSchema = nil
+		return nil
+	}
+	validatedSchema, err := convertSchemaForOpenAI(schema)
+	if err != nil {
+		return fmt.Errorf("converting response schema: %w", err)
+	}
+	b, err := json.Marshal(validatedSchema)
+	if err != nil {
+		return fmt.Errorf("converting response schema to json: %w", err)
+	}
+	var responseSchema map[string]any
+	if err := json.Unmarshal(b, &responseSchema); err != nil {
+		return fmt.Errorf("converting response schema to json: %w", err)
+	}
+	c.responseSchema = responseSchema
 	return nil
 }
 
+// openAIResponseFormat returns the response format of Chat Completions requests
+// constrained by schema, or the default format if schema is nil.
+func openAIResponseFormat(schema map[string]any) openai.ChatCompletionNewParamsResponseFormatUnion {
+	if schema == nil {
+		return openai.ChatCompletionNewParamsResponseFormatUnion{}
+	}
+	return openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+				Name:   "response",
+				Schema: schema,
+			},
+		},
+	}
+}
+
 // ListModels returns a slice of strings with model IDs.
 // Note: This may not work with all OpenAI-compatible providers if they don't fully implement
 // the Models.List endpoint or return data in a different format.
@@ -247,6 +339,8 @@ type openAIChatSession struct {
 	model               string
 	functionDefinitions []*FunctionDefinition            // Stored in gollm format
 	tools               []openai.ChatCompletionToolParam // Stored in OpenAI format
+	generation          GenerationConfig
+	responseSchema      map[string]any
 }
 
 // Ensure openAIChatSession implements the Chat interface.
@@ -297,6 +391,8 @@ func (cs *openAIChatSession) Send(ctx context.Context, contents ...any) (ChatRes
 	if len(cs.tools) > 0 {
 		chatReq.Tools = cs.tools
 	}
+	applyOpenAIGenerationConfig(&chatReq, cs.generation)
+	chatReq.ResponseFormat = openAIResponseFormat(cs.responseSchema)
 
 	// Call the OpenAI API
 	klog.V(1).InfoS("Sending request to OpenAI Chat API", "model", cs.model, "messages", len(chatReq.Messages), "tools", len(chatReq.Tools))
@@ -345,6 +441,8 @@ func (cs *openAIChatSession) SendStreaming(ctx context.Context, contents ...any)
 	if len(cs.tools) > 0 {
 		chatReq.Tools = cs.tools
 	}
+	applyOpenAIGenerationConfig(&chatReq, cs.generation)
+	chatReq.ResponseFormat = openAIResponseFormat(cs.responseSchema)
 
 	// Start the OpenAI streaming request
 	klog.V(1).InfoS("Sending streaming request to OpenAI API",
@@ -160,81 +160,82 @@ func (r *openAIResponseChatResponse) UsageMetadata() any {
 	return nil
 }
 
+// Candidates returns the output items of the response as the parts of a single candidate,
+// as the agent only reads the first candidate.
 func (r *openAIResponseChatResponse) Candidates() []Candidate {
-	if r.resp == nil {
+	if r.resp == nil || len(r.resp.Output) == 0 {
 		return nil
 	}
-	var candidates []Candidate
-	for _, output := range r.resp.Output {
-		switch output.AsAny().(type) {
-		case responses.ResponseFunctionToolCall, responses.ResponseOutputMessage:
-			candidates = append(candidates, &openAIResponseCandidate{
-				candidate: &output,
-			})
-		default:
-			// skip reasoning messages because agentic loop doesn't know
-			// how to handle them yet.
-		}
-	}
-	return candidates
+	return []Candidate{&openAIResponseCandidate{outputs: r.resp.Output}}
 }
 
 type openAIResponseCandidate struct {
-	candidate *responses.ResponseOutputItemUnion
+	outputs []responses.ResponseOutputItemUnion
 }
 
 var _ Candidate = (*openAIResponseCandidate)(nil)
 
 func (c *openAIResponseCandidate) Parts() []Part {
-	if c.candidate == nil {
-		return nil
-	}
 	// OpenAI message can have Content AND ToolCalls
 	var parts []Part
-
-	output := c.candidate
-	switch output.AsAny().(type) {
-	case responses.ResponseFunctionToolCall:
-		fc := output.AsFunctionCall()
-		toolCall, err := convertResponseToolCallToFunctionCall(fc)
-		if err != nil {
-			//
+	for _, output := range c.outputs {
+		switch output.AsAny().(type) {
+		case responses.ResponseFunctionToolCall:
+			fc := output.AsFunctionCall()
+			toolCall, err := convertResponseToolCallToFunctionCall(fc)
+			if err != nil {
+				//
+			}
+			parts = append(parts, &openAIResponsePart{
+				toolCall: &toolCall,
+			})
+		case responses.ResponseReasoningItem:
+			// Summaries are only returned when requested, see GenerationConfig.IncludeThoughts
+			for _, summary := range output.AsReasoning().Summary {
+				if summary.Text != "" {
+					parts = append(parts, &openAIResponsePart{thought: summary.Text})
+				}
+			}
+		case responses.ResponseOutputMessage:
+			msg := output.AsMessage()
+			parts = append(parts, &openAIResponsePart{
+				content: msg.Content[0].AsOutputText().Text,
+			})
+		default:
+			log.Println("no variant present", output)
 		}
-		parts = append(parts, &openAIResponsePart{
-			toolCall: toolCall,
-		})
-	case responses.ResponseReasoningItem:
-		reason := output.AsReasoning()
-		log.Printf("Inspected Reasoning item: %+v", reason)
-	case responses.ResponseOutputMessage:
-		msg := output.AsMessage()
-		parts = append(parts, &openAIResponsePart{
-			content: msg.Content[0].AsOutputText().Text,
-		})
-	default:
-		log.Println("no variant present", output)
 	}
 	return parts
 }
 
 // String provides a simple string representation for logging/debugging.
 func (c *openAIResponseCandidate) String() string {
-	return fmt.Sprintf("%+v", c.candidate)
+	return fmt.Sprintf("%+v", c.outputs)
 }
 
 type openAIResponsePart struct {
 	content  string
-	toolCall FunctionCall
+	thought  string
+	toolCall *FunctionCall
 }
 
-var _ Part = (*openAIResponsePart)(nil)
+var _ ThoughtPart = (*openAIResponsePart)(nil)
 
 func (p *openAIResponsePart) AsText() (string, bool) {
 	return p.content, p.content != ""
 }
 
 func (p *openAIResponsePart) AsFunctionCalls() ([]FunctionCall, bool) {
-	return []FunctionCall{p.toolCall}, p.content == ""
+	if p.toolCall == nil {
+		return nil, false
+	}
+	return []FunctionCall{*p.toolCall}, true
+}
+
+// AsThought returns the reasoning summary of the part. Reasoning items stay in the
+// history, as the API requires them with the function calls that follow them.
+func (p *openAIResponsePart) AsThought() (string, bool) {
+	return p.thought, p.thought != ""
 }
 
 // convertFunctionParameters handles the conversion of gollm parameters to OpenAI format
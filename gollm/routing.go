@@ -0,0 +1,479 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+// RoutingConfig configures a RoutingClient.
+type RoutingConfig struct {
+	// Targets are the provider/model pairs to use, in order of preference. A request
+	// rejected by one of them with a rate limit or server error is sent to the next.
+	Targets []RouteTarget `json:"targets"`
+	// LongContext is preferred for conversations of more than LongContextTokens tokens,
+	// e.g. a model with a bigger context window. Targets are the fallbacks.
+	LongContext       *RouteTarget `json:"longContext,omitempty"`
+	LongContextTokens int          `json:"longContextTokens,omitempty"`
+	// Completion is preferred for single prompt completions (GenerateCompletion), such
+	// as classification calls, e.g. a small and cheap model. Targets are the fallbacks.
+	Completion *RouteTarget `json:"completion,omitempty"`
+}
+
+// RouteTarget is a provider and model requests can be sent to.
+type RouteTarget struct {
+	// Provider is the provider ID, as for NewClient, e.g. "gemini" or "openai://host".
+	Provider string `json:"provider"`
+	// Model is the model to use. The model the chat is started with if empty.
+	Model string `json:"model,omitempty"`
+}
+
+func (t RouteTarget) String() string {
+	return t.Provider + "/" + t.Model
+}
+
+// Validate checks that the configuration has targets, and that they have providers.
+func (c *RoutingConfig) Validate() error {
+	if len(c.Targets) == 0 {
+		return errors.New("routing needs at least one target")
+	}
+	for _, target := range c.targets() {
+		if target.Provider == "" {
+			return fmt.Errorf("routing target %q has no provider", target.String())
+		}
+	}
+	if c.LongContext != nil && c.LongContextTokens <= 0 {
+		return errors.New("longContextTokens must be positive when longContext is set")
+	}
+	return nil
+}
+
+// targets returns all the targets of the configuration.
+func (c *RoutingConfig) targets() []RouteTarget {
+	targets := append([]RouteTarget(nil), c.Targets...)
+	for _, target := range []*RouteTarget{c.LongContext, c.Completion} {
+		if target != nil {
+			targets = append(targets, *target)
+		}
+	}
+	return targets
+}
+
+// RoutingClient is a Client sending requests to several providers and models, per a
+// RoutingConfig: it fails over to the next target on rate limits and server errors,
+// and routes long conversations and single prompt completions to dedicated models.
+// Its users see a single Client.
+type RoutingClient struct {
+	config RoutingConfig
+	// clients are the clients of the providers of the targets, by provider ID
+	clients map[string]Client
+}
+
+var _ Client = &RoutingClient{}
+
+// NewRoutingClient creates the clients of the providers of the targets, with opts.
+func NewRoutingClient(ctx context.Context, config RoutingConfig, opts ...Option) (*RoutingClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	c := &RoutingClient{config: config, clients: make(map[string]Client)}
+	for _, target := range config.targets() {
+		if _, ok := c.clients[target.Provider]; ok {
+			continue
+		}
+		client, err := NewClient(ctx, target.Provider, opts...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("creating client for provider %q: %w", target.Provider, err)
+		}
+		c.clients[target.Provider] = client
+	}
+	return c, nil
+}
+
+func (c *RoutingClient) Close() error {
+	var errs []error
+	for _, client := range c.clients {
+		errs = append(errs, client.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// StartChat starts a chat that sends each request to the preferred target that accepts it.
+func (c *RoutingClient) StartChat(systemPrompt, model string) Chat {
+	return &routingChat{
+		client:       c,
+		systemPrompt: systemPrompt,
+		model:        model,
+	}
+}
+
+// GenerateCompletion sends the request to the completion target, falling back to the targets.
+func (c *RoutingClient) GenerateCompletion(ctx context.Context, req *CompletionRequest) (CompletionResponse, error) {
+	candidates := c.config.Targets
+	if c.config.Completion != nil {
+		candidates = append([]RouteTarget{*c.config.Completion}, candidates...)
+	}
+
+	var lastErr error
+	for _, target := range candidates {
+		targetReq := *req
+		if target.Model != "" {
+			targetReq.Model = target.Model
+		}
+		resp, err := c.clients[target.Provider].GenerateCompletion(ctx, &targetReq)
+		if err == nil {
+			return resp, nil
+		}
+		if !isFailoverError(nil, err) {
+			return nil, err
+		}
+		klog.Warningf("LLM target %s failed, trying the next one: %v", target, err)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all LLM targets failed: %w", lastErr)
+}
+
+func (c *RoutingClient) SetResponseSchema(schema *Schema) error {
+	var errs []error
+	for provider, client := range c.clients {
+		if err := client.SetResponseSchema(schema); err != nil {
+			errs = append(errs, fmt.Errorf("provider %q: %w", provider, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListModels lists the models of the providers of the targets.
+func (c *RoutingClient) ListModels(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	var models []string
+	for provider, client := range c.clients {
+		providerModels, err := client.ListModels(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing models of provider %q: %w", provider, err)
+		}
+		for _, model := range providerModels {
+			if !seen[model] {
+				seen[model] = true
+				models = append(models, model)
+			}
+		}
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// isFailoverError reports whether a request failing with err should be sent to the
+// next target: rate limits, server errors and timeouts. Errors of providers not using
+// APIError are checked with the IsRetryableError of their chat, if there is one.
+func isFailoverError(chat Chat, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	if chat != nil {
+		return chat.IsRetryableError(err)
+	}
+	return DefaultIsRetryableError(err)
+}
+
+// routingChat is a chat whose requests go to the first target of the candidates that
+// accepts them. It sticks to the target that last answered, so that a conversation
+// is not moved back and forth between models; when it moves to another target, the
+// new chat is initialized with the conversation so far, as text.
+type routingChat struct {
+	client       *RoutingClient
+	systemPrompt string
+	model        string
+	functionDefs []*FunctionDefinition
+
+	// transcript is the conversation so far, which any chat can be initialized with
+	transcript chatTranscript
+
+	// chat is the chat of the target that last answered, on activeTarget
+	chat         Chat
+	activeTarget RouteTarget
+	// issuedCalls are the IDs of the function calls requested by chat, whose results
+	// can be sent to it as such
+	issuedCalls map[string]bool
+}
+
+var _ Chat = &routingChat{}
+
+// candidates returns the targets to try for the next request, in order: the long
+// context target for long conversations, then the targets from the active one on.
+func (c *routingChat) candidates(contents []any) []RouteTarget {
+	config := c.client.config
+	candidates := config.Targets
+	for i, target := range candidates {
+		if c.chat != nil && target == c.activeTarget {
+			candidates = candidates[i:]
+			break
+		}
+	}
+	if config.LongContext != nil {
+		chars := c.transcript.chars
+		for _, content := range contents {
+			chars += len(contentText(content))
+		}
+		if chars/4 > config.LongContextTokens {
+			return append([]RouteTarget{*config.LongContext}, candidates...)
+		}
+	}
+	return candidates
+}
+
+// chatFor returns the chat of target, starting it with the conversation so far if
+// the chat is not already on that target.
+func (c *routingChat) chatFor(target RouteTarget) (Chat, error) {
+	if c.chat != nil && c.activeTarget == target {
+		return c.chat, nil
+	}
+	model := target.Model
+	if model == "" {
+		model = c.model
+	}
+	chat := c.client.clients[target.Provider].StartChat(c.systemPrompt, model)
+	if c.functionDefs != nil {
+		if err := chat.SetFunctionDefinitions(c.functionDefs); err != nil {
+			return nil, fmt.Errorf("setting function definitions for %s: %w", target, err)
+		}
+	}
+	if len(c.transcript.messages) > 0 {
+		klog.Infof("Moving the conversation to LLM target %s", target)
+		if err := chat.Initialize(c.transcript.messages); err != nil {
+			return nil, fmt.Errorf("initializing chat for %s: %w", target, err)
+		}
+	}
+	return chat, nil
+}
+
+// use makes chat, on target, the chat of the next requests.
+func (c *routingChat) use(target RouteTarget, chat Chat) {
+	if c.chat == chat {
+		return
+	}
+	c.chat = chat
+	c.activeTarget = target
+	c.issuedCalls = make(map[string]bool)
+}
+
+// contentsFor adapts contents for a chat: the results of function calls requested by
+// another chat are sent as text, since the chat does not know about the calls.
+func (c *routingChat) contentsFor(chat Chat, contents []any) []any {
+	out := make([]any, 0, len(contents))
+	for _, content := range contents {
+		if result, ok := content.(FunctionCallResult); ok && (c.chat != chat || !c.issuedCalls[result.ID]) {
+			out = append(out, contentText(result))
+			continue
+		}
+		out = append(out, content)
+	}
+	return out
+}
+
+func (c *routingChat) Send(ctx context.Context, contents ...any) (ChatResponse, error) {
+	var lastErr error
+	for _, target := range c.candidates(contents) {
+		chat, err := c.chatFor(target)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := chat.Send(ctx, c.contentsFor(chat, contents)...)
+		if err == nil {
+			c.use(target, chat)
+			c.transcript.addContents(contents)
+			c.recordResponse(resp)
+			return resp, nil
+		}
+		if !isFailoverError(chat, err) {
+			return nil, err
+		}
+		klog.Warningf("LLM target %s failed, trying the next one: %v", target, err)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all LLM targets failed: %w", lastErr)
+}
+
+// SendStreaming streams the response of the first target that accepts the request. Once
+// a target has streamed part of its response, its errors are returned as they are.
+func (c *routingChat) SendStreaming(ctx context.Context, contents ...any) (ChatResponseIterator, error) {
+	return func(yield func(ChatResponse, error) bool) {
+		var lastErr error
+		for _, target := range c.candidates(contents) {
+			chat, err := c.chatFor(target)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			stream, err := chat.SendStreaming(ctx, c.contentsFor(chat, contents)...)
+			if err != nil {
+				if !isFailoverError(chat, err) {
+					yield(nil, err)
+					return
+				}
+				klog.Warningf("LLM target %s failed, trying the next one: %v", target, err)
+				lastErr = err
+				continue
+			}
+
+			started := false
+			failedOver := false
+			for resp, err := range stream {
+				if err != nil && !started && isFailoverError(chat, err) {
+					klog.Warningf("LLM target %s failed, trying the next one: %v", target, err)
+					lastErr = err
+					failedOver = true
+					break
+				}
+				if !started {
+					started = true
+					c.use(target, chat)
+					c.transcript.addContents(contents)
+				}
+				if err == nil {
+					c.recordResponse(resp)
+				}
+				if !yield(resp, err) || err != nil {
+					return
+				}
+			}
+			if !failedOver {
+				if !started {
+					// An empty response still answers the request
+					c.use(target, chat)
+					c.transcript.addContents(contents)
+				}
+				return
+			}
+		}
+		yield(nil, fmt.Errorf("all LLM targets failed: %w", lastErr))
+	}, nil
+}
+
+func (c *routingChat) SetFunctionDefinitions(functionDefinitions []*FunctionDefinition) error {
+	c.functionDefs = functionDefinitions
+	if c.chat != nil {
+		return c.chat.SetFunctionDefinitions(functionDefinitions)
+	}
+	return nil
+}
+
+func (c *routingChat) IsRetryableError(err error) bool {
+	if c.chat != nil {
+		return c.chat.IsRetryableError(err)
+	}
+	return DefaultIsRetryableError(err)
+}
+
+// Initialize starts the conversation over with messages, on the preferred target.
+func (c *routingChat) Initialize(messages []*api.Message) error {
+	c.chat = nil
+	c.transcript = chatTranscript{}
+	c.transcript.addMessages(messages)
+	return nil
+}
+
+// recordResponse adds a response to the transcript, and remembers its function calls,
+// whose results can then be sent to the chat as such.
+func (c *routingChat) recordResponse(resp ChatResponse) {
+	for _, call := range c.transcript.addResponse(resp) {
+		c.issuedCalls[call.ID] = true
+	}
+}
+
+// chatTranscript is a conversation as text messages, which a chat of any provider can
+// be initialized with to carry the conversation over.
+type chatTranscript struct {
+	messages []*api.Message
+	// chars counts the characters of the conversation, to estimate its tokens
+	chars int
+}
+
+// addMessages adds the text messages of a conversation to the transcript.
+func (t *chatTranscript) addMessages(messages []*api.Message) {
+	for _, msg := range messages {
+		if text, ok := msg.Payload.(string); ok && msg.Type == api.MessageTypeText {
+			t.addText(msg.Source, text)
+		}
+	}
+}
+
+// addContents adds the contents of a request to the transcript.
+func (t *chatTranscript) addContents(contents []any) {
+	for _, content := range contents {
+		t.addText(api.MessageSourceUser, contentText(content))
+	}
+}
+
+// addResponse adds the text and function calls of a response to the transcript, and
+// returns the function calls.
+func (t *chatTranscript) addResponse(resp ChatResponse) []FunctionCall {
+	candidates := resp.Candidates()
+	if len(candidates) == 0 {
+		return nil
+	}
+	var functionCalls []FunctionCall
+	for _, part := range candidates[0].Parts() {
+		if text, ok := part.AsText(); ok {
+			t.addText(api.MessageSourceModel, text)
+		}
+		if calls, ok := part.AsFunctionCalls(); ok {
+			for _, call := range calls {
+				args, _ := json.Marshal(call.Arguments)
+				t.addText(api.MessageSourceModel, fmt.Sprintf("\nCalling %s with %s\n", call.Name, args))
+			}
+			functionCalls = append(functionCalls, calls...)
+		}
+	}
+	return functionCalls
+}
+
+// addText adds text to the transcript, merged with the previous message if it has the
+// same source, as streamed responses come in chunks.
+func (t *chatTranscript) addText(source api.MessageSource, text string) {
+	if text == "" {
+		return
+	}
+	t.chars += len(text)
+	if n := len(t.messages); n > 0 && t.messages[n-1].Source == source {
+		t.messages[n-1].Payload = t.messages[n-1].Payload.(string) + text
+		return
+	}
+	t.messages = append(t.messages, &api.Message{Source: source, Type: api.MessageTypeText, Payload: text})
+}
+
+// contentText returns the text of a content sent to a chat.
+func contentText(content any) string {
+	switch v := content.(type) {
+	case string:
+		return v
+	case FunctionCallResult:
+		result, _ := json.Marshal(v.Result)
+		return fmt.Sprintf("Result of running %q:\n%s", v.Name, result)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+// fakeRouteClient answers every request with its name, or fails with err.
+type fakeRouteClient struct {
+	name string
+	err  error
+	// sends records the contents of the requests of all the chats, prefixed by the model
+	sends []string
+	// initialized records the messages chats were initialized with
+	initialized [][]*api.Message
+	// call is returned as a function call by the next response, if set
+	call *FunctionCall
+}
+
+func (c *fakeRouteClient) Close() error { return nil }
+
+func (c *fakeRouteClient) StartChat(systemPrompt, model string) Chat {
+	return &fakeRouteChat{client: c, model: model}
+}
+
+func (c *fakeRouteClient) GenerateCompletion(ctx context.Context, req *CompletionRequest) (CompletionResponse, error) {
+	c.sends = append(c.sends, req.Model+": "+req.Prompt)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &fakeRouteResponse{text: c.name}, nil
+}
+
+func (c *fakeRouteClient) SetResponseSchema(schema *Schema) error { return nil }
+
+func (c *fakeRouteClient) ListModels(ctx context.Context) ([]string, error) {
+	return []string{c.name}, nil
+}
+
+type fakeRouteChat struct {
+	client *fakeRouteClient
+	model  string
+}
+
+func (c *fakeRouteChat) Send(ctx context.Context, contents ...any) (ChatResponse, error) {
+	var parts []string
+	for _, content := range contents {
+		if result, ok := content.(FunctionCallResult); ok {
+			parts = append(parts, "result:"+result.ID)
+			continue
+		}
+		parts = append(parts, contentText(content))
+	}
+	c.client.sends = append(c.client.sends, c.model+": "+strings.Join(parts, ","))
+	if c.client.err != nil {
+		return nil, c.client.err
+	}
+	resp := &fakeRouteResponse{text: c.client.name, call: c.client.call}
+	c.client.call = nil
+	return resp, nil
+}
+
+func (c *fakeRouteChat) SendStreaming(ctx context.Context, contents ...any) (ChatResponseIterator, error) {
+	resp, err := c.Send(ctx, contents...)
+	if err != nil {
+		return nil, err
+	}
+	return singletonChatResponseIterator(resp), nil
+}
+
+func (c *fakeRouteChat) SetFunctionDefinitions(functionDefinitions []*FunctionDefinition) error {
+	return nil
+}
+
+func (c *fakeRouteChat) IsRetryableError(err error) bool { return DefaultIsRetryableError(err) }
+
+func (c *fakeRouteChat) Initialize(messages []*api.Message) error {
+	c.client.initialized = append(c.client.initialized, messages)
+	return nil
+}
+
+type fakeRouteResponse struct {
+	text string
+	call *FunctionCall
+}
+
+func (r *fakeRouteResponse) Response() string   { return r.text }
+func (r *fakeRouteResponse) UsageMetadata() any { return nil }
+func (r *fakeRouteResponse) Candidates() []Candidate {
+	return []Candidate{r}
+}
+func (r *fakeRouteResponse) String() string { return r.text }
+func (r *fakeRouteResponse) Parts() []Part {
+	parts := []Part{fakeRoutePart{text: r.text}}
+	if r.call != nil {
+		parts = append(parts, fakeRoutePart{calls: []FunctionCall{*r.call}})
+	}
+	return parts
+}
+
+type fakeRoutePart struct {
+	text  string
+	calls []FunctionCall
+}
+
+func (p fakeRoutePart) AsText() (string, bool) { return p.text, p.calls == nil }
+func (p fakeRoutePart) AsFunctionCalls() ([]FunctionCall, bool) {
+	return p.calls, p.calls != nil
+}
+
+func newTestRoutingClient(config RoutingConfig, clients ...*fakeRouteClient) *RoutingClient {
+	c := &RoutingClient{config: config, clients: make(map[string]Client)}
+	for _, client := range clients {
+		c.clients[client.name] = client
+	}
+	return c
+}
+
+func responseText(t *testing.T, resp ChatResponse) string {
+	t.Helper()
+	text, _ := resp.Candidates()[0].Parts()[0].AsText()
+	return text
+}
+
+func TestRoutingChatFailover(t *testing.T) {
+	primary := &fakeRouteClient{name: "primary", call: &FunctionCall{ID: "call-1", Name: "kubectl"}}
+	secondary := &fakeRouteClient{name: "secondary"}
+	client := newTestRoutingClient(RoutingConfig{
+		Targets: []RouteTarget{{Provider: "primary"}, {Provider: "secondary", Model: "backup"}},
+	}, primary, secondary)
+
+	chat := client.StartChat("system", "main")
+	resp, err := chat.Send(context.Background(), "list pods")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := responseText(t, resp); got != "primary" {
+		t.Errorf("first response from %q, want primary", got)
+	}
+
+	primary.err = &APIError{StatusCode: 429, Message: "rate limited"}
+	resp, err = chat.Send(context.Background(), FunctionCallResult{ID: "call-1", Name: "kubectl", Result: map[string]any{"stdout": "nginx"}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := responseText(t, resp); got != "secondary" {
+		t.Errorf("second response from %q, want secondary", got)
+	}
+	if got, want := primary.sends, []string{"main: list pods", "main: result:call-1"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("primary requests = %q, want %q", got, want)
+	}
+	// The secondary chat does not know about the call, so it gets the result as text
+	if len(secondary.sends) != 1 || !strings.HasPrefix(secondary.sends[0], "backup: Result of running \"kubectl\"") {
+		t.Errorf("secondary requests = %q, want the function result as text", secondary.sends)
+	}
+	if len(secondary.initialized) != 1 || len(secondary.initialized[0]) != 2 {
+		t.Fatalf("secondary initialized with %v, want the user query and the model response", secondary.initialized)
+	}
+
+	// The chat sticks to the secondary target, even once the primary recovers
+	primary.err = nil
+	if resp, err := chat.Send(context.Background(), "thanks"); err != nil || responseText(t, resp) != "secondary" {
+		t.Errorf("third response = %v, %v, want from secondary", resp, err)
+	}
+
+	// Errors that are not rate limits or server errors are returned as they are
+	secondary.err = &APIError{StatusCode: 400, Message: "bad request"}
+	var apiErr *APIError
+	if _, err := chat.Send(context.Background(), "again"); !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+		t.Errorf("Send() error = %v, want the bad request error", err)
+	}
+}
+
+func TestRoutingChatLongContext(t *testing.T) {
+	small := &fakeRouteClient{name: "small"}
+	big := &fakeRouteClient{name: "big"}
+	client := newTestRoutingClient(RoutingConfig{
+		Targets:           []RouteTarget{{Provider: "small"}},
+		LongContext:       &RouteTarget{Provider: "big", Model: "big-model"},
+		LongContextTokens: 10,
+	}, small, big)
+
+	chat := client.StartChat("", "small-model")
+	if resp, err := chat.Send(context.Background(), "hi"); err != nil || responseText(t, resp) != "small" {
+		t.Fatalf("short query response = %v, %v, want from small", resp, err)
+	}
+	if resp, err := chat.Send(context.Background(), strings.Repeat("x", 100)); err != nil || responseText(t, resp) != "big" {
+		t.Fatalf("long query response = %v, %v, want from big", resp, err)
+	}
+	if len(big.sends) != 1 || !strings.HasPrefix(big.sends[0], "big-model: ") {
+		t.Errorf("big requests = %q, want one to big-model", big.sends)
+	}
+}
+
+func TestRoutingClientGenerateCompletion(t *testing.T) {
+	cheap := &fakeRouteClient{name: "cheap", err: &APIError{StatusCode: 503, Message: "unavailable"}}
+	main := &fakeRouteClient{name: "main"}
+	client := newTestRoutingClient(RoutingConfig{
+		Targets:    []RouteTarget{{Provider: "main"}},
+		Completion: &RouteTarget{Provider: "cheap", Model: "flash"},
+	}, cheap, main)
+
+	resp, err := client.GenerateCompletion(context.Background(), &CompletionRequest{Model: "pro", Prompt: "classify"})
+	if err != nil {
+		t.Fatalf("GenerateCompletion() error = %v", err)
+	}
+	if resp.Response() != "main" {
+		t.Errorf("response from %q, want main", resp.Response())
+	}
+	if got := strings.Join(cheap.sends, "|") + "|" + strings.Join(main.sends, "|"); got != "flash: classify|pro: classify" {
+		t.Errorf("requests = %q, want flash then pro", got)
+	}
+}
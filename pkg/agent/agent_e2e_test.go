@@ -16,12 +16,18 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
 	"github.com/GoogleCloudPlatform/kubectl-ai/internal/mocks"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/notify"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
 	"go.uber.org/mock/gomock"
@@ -230,6 +236,182 @@ func TestAgentEndToEndToolExecution(t *testing.T) {
 			t.Fatalf("unexpected state after tool run: %s (want Done or WaitingForInput)", st)
 		}
 	}
+
+	// The steps of both iterations were reported, the last one ending before the final text
+	var steps []api.AgentEventType
+	for len(a.Events) > 0 {
+		steps = append(steps, (<-a.Events).Type)
+	}
+	wantSteps := []api.AgentEventType{
+		api.AgentEventIterationStarted, api.AgentEventLLMCallStarted, api.AgentEventLLMCallFinished,
+		api.AgentEventToolsAnalyzed, api.AgentEventApprovalRequested, api.AgentEventToolsDispatched,
+		api.AgentEventIterationStarted, api.AgentEventLLMCallStarted, api.AgentEventLLMCallFinished,
+	}
+	if !reflect.DeepEqual(steps, wantSteps) {
+		t.Errorf("agent events = %v, want %v", steps, wantSteps)
+	}
+}
+
+func TestAgentRefusesCallsAgainstPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := sessions.NewInMemoryChatStore()
+	client := mocks.NewMockClient(ctrl)
+	chat := mocks.NewMockChat(ctrl)
+
+	client.EXPECT().StartChat(gomock.Any(), "test-model").Return(chat)
+	chat.EXPECT().Initialize(gomock.Any()).Return(nil)
+	chat.EXPECT().SetFunctionDefinitions(gomock.Any()).Return(nil)
+	gomock.InOrder(
+		chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).Return(gollm.ChatResponseIterator(func(yield func(gollm.ChatResponse, error) bool) {
+			yield(chatWith(fCalls("mocktool", map[string]any{"command": "delete"})), nil)
+		}), nil),
+		chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).Return(gollm.ChatResponseIterator(func(yield func(gollm.ChatResponse, error) bool) {
+			yield(chatWith(fText("cannot delete in read-only mode")), nil)
+		}), nil),
+	)
+
+	// The tool modifies resources, so it is never run
+	tool := mocks.NewMockTool(ctrl)
+	tool.EXPECT().Name().Return("mocktool").AnyTimes()
+	tool.EXPECT().Description().Return("mock tool").AnyTimes()
+	tool.EXPECT().FunctionDefinition().Return(&gollm.FunctionDefinition{Name: "mocktool"}).AnyTimes()
+	tool.EXPECT().IsInteractive(gomock.Any()).Return(false, nil).AnyTimes()
+	tool.EXPECT().CheckModifiesResource(gomock.Any()).Return("yes").AnyTimes()
+
+	var toolset tools.Tools
+	toolset.Init()
+	toolset.RegisterTool(tool)
+
+	a := &Agent{
+		ChatMessageStore: store,
+		LLM:              client,
+		Model:            "test-model",
+		Tools:            toolset,
+		MaxIterations:    4,
+		ReadOnly:         true,
+		Session: &api.Session{
+			ID:               "test-session",
+			ChatMessageStore: store,
+			AgentState:       api.AgentStateIdle,
+		},
+	}
+	if err := a.Init(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if err := a.Run(ctx, ""); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	recvUntil(t, ctx, a.Output, func(m *api.Message) bool { return m.Type == api.MessageTypeUserInputRequest })
+
+	a.Input <- &api.UserInputResponse{Query: "delete everything"}
+	m := recvUntil(t, ctx, a.Output, func(m *api.Message) bool { return m.Type == api.MessageTypeError })
+	if got := api.ErrorPayloadFrom(m.Payload); got.Category != api.ErrorCategoryPolicyRefused || got.Guidance == "" {
+		t.Errorf("error = %+v, want a policy refusal with guidance", got)
+	}
+	recvUntil(t, ctx, a.Output, func(m *api.Message) bool {
+		return m.Type == api.MessageTypeText && m.Source == api.MessageSourceModel
+	})
+}
+
+func TestAgentAnswersAllCallsOfRefusedBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := sessions.NewInMemoryChatStore()
+	client := mocks.NewMockClient(ctrl)
+	chat := mocks.NewMockChat(ctrl)
+
+	client.EXPECT().StartChat(gomock.Any(), "test-model").Return(chat)
+	chat.EXPECT().Initialize(gomock.Any()).Return(nil)
+	chat.EXPECT().SetFunctionDefinitions(gomock.Any()).Return(nil)
+	results := make(chan []any, 1)
+	gomock.InOrder(
+		chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).Return(gollm.ChatResponseIterator(func(yield func(gollm.ChatResponse, error) bool) {
+			yield(chatWith(fakePart{calls: []gollm.FunctionCall{
+				{ID: "1", Name: "mocktool", Arguments: map[string]any{"command": "get"}},
+				{ID: "2", Name: "mocktool", Arguments: map[string]any{"command": "delete"}},
+				{ID: "3", Name: "mocktool", Arguments: map[string]any{"command": "get"}},
+			}}), nil)
+		}), nil),
+		chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, contents ...any) (gollm.ChatResponseIterator, error) {
+			results <- contents
+			return gollm.ChatResponseIterator(func(yield func(gollm.ChatResponse, error) bool) {
+				yield(chatWith(fText("cannot delete in read-only mode")), nil)
+			}), nil
+		}),
+	)
+
+	// Only the delete call is refused, and none of the calls are run
+	tool := mocks.NewMockTool(ctrl)
+	tool.EXPECT().Name().Return("mocktool").AnyTimes()
+	tool.EXPECT().Description().Return("mock tool").AnyTimes()
+	tool.EXPECT().FunctionDefinition().Return(&gollm.FunctionDefinition{Name: "mocktool"}).AnyTimes()
+	tool.EXPECT().IsInteractive(gomock.Any()).Return(false, nil).AnyTimes()
+	tool.EXPECT().CheckModifiesResource(gomock.Any()).DoAndReturn(func(args map[string]any) string {
+		if args["command"] == "delete" {
+			return "yes"
+		}
+		return "no"
+	}).AnyTimes()
+
+	var toolset tools.Tools
+	toolset.Init()
+	toolset.RegisterTool(tool)
+
+	a := &Agent{
+		ChatMessageStore: store,
+		LLM:              client,
+		Model:            "test-model",
+		Tools:            toolset,
+		MaxIterations:    4,
+		ReadOnly:         true,
+		Session: &api.Session{
+			ID:               "test-session",
+			ChatMessageStore: store,
+			AgentState:       api.AgentStateIdle,
+		},
+	}
+	if err := a.Init(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if err := a.Run(ctx, ""); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	recvUntil(t, ctx, a.Output, func(m *api.Message) bool { return m.Type == api.MessageTypeUserInputRequest })
+	a.Input <- &api.UserInputResponse{Query: "check and clean up"}
+
+	var contents []any
+	select {
+	case contents = <-results:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the results of the tool calls")
+	}
+	var ids []string
+	for _, content := range contents {
+		result, ok := content.(gollm.FunctionCallResult)
+		if !ok {
+			t.Fatalf("content = %#v, want a function call result", content)
+		}
+		ids = append(ids, result.ID)
+		want := errSkippedWithRefusedCall.Error()
+		if result.ID == "2" {
+			want = errReadOnly.Error()
+		}
+		if result.Result["error"] != want {
+			t.Errorf("result of call %s = %v, want error %q", result.ID, result.Result, want)
+		}
+	}
+	if want := []string{"1", "2", "3"}; !slices.Equal(ids, want) {
+		t.Errorf("results for calls %v, want %v", ids, want)
+	}
 }
 
 func TestAgentEndToEndMetaClear(t *testing.T) {
@@ -321,3 +503,149 @@ func TestAgentEndToEndMetaClear(t *testing.T) {
 		t.Fatalf("second message type = %v, want user input request", msgs[1].Type)
 	}
 }
+
+func TestAgentWithoutTools(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := mocks.NewMockClient(ctrl)
+	chat := mocks.NewMockChat(ctrl)
+
+	// No function definitions are set, and the prompt template is used as is
+	client.EXPECT().StartChat("Only reply with a command.", "test-model").Return(chat)
+	client.EXPECT().Close().Return(nil)
+	chat.EXPECT().Initialize(gomock.Any()).Return(nil)
+	chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).Return(gollm.ChatResponseIterator(func(yield func(gollm.ChatResponse, error) bool) {
+		yield(chatWith(fText("kubectl get pods")), nil)
+	}), nil)
+
+	a := &Agent{
+		LLM:            client,
+		Model:          "test-model",
+		MaxIterations:  1,
+		PromptTemplate: "Only reply with a command.",
+		DisableTools:   true,
+		RunOnce:        true,
+		InitialQuery:   "kubectl get pdos",
+		Session:        &api.Session{},
+	}
+	if err := a.Init(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer a.Close()
+	if len(a.Tools.AllTools()) != 0 {
+		t.Errorf("tools = %v, want none", a.Tools.Names())
+	}
+	if err := a.Run(ctx, ""); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var answer any
+	for v := range a.Output {
+		if m, ok := v.(*api.Message); ok && m.Type == api.MessageTypeText && m.Source == api.MessageSourceModel {
+			answer = m.Payload
+		}
+	}
+	if answer != "kubectl get pods" {
+		t.Errorf("answer = %v, want kubectl get pods", answer)
+	}
+}
+
+func TestAgentMultiUserTools(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := mocks.NewMockClient(ctrl)
+	chat := mocks.NewMockChat(ctrl)
+	client.EXPECT().StartChat(gomock.Any(), "test-model").Return(chat)
+	client.EXPECT().Close().Return(nil)
+	chat.EXPECT().Initialize(gomock.Any()).Return(nil)
+	chat.EXPECT().SetFunctionDefinitions(gomock.Any()).Return(nil)
+
+	var toolset tools.Tools
+	toolset.Init()
+	a := &Agent{
+		LLM:       client,
+		Model:     "test-model",
+		Tools:     toolset,
+		MultiUser: true,
+		Session:   &api.Session{},
+	}
+	if err := a.Init(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer a.Close()
+	// The bash tool could read the kubeconfigs of the other users of the server
+	if a.Tools.Lookup("bash") != nil {
+		t.Errorf("tools = %v, want no bash", a.Tools.Names())
+	}
+	if a.Tools.Lookup("kubectl") == nil {
+		t.Errorf("tools = %v, want kubectl", a.Tools.Names())
+	}
+}
+
+func TestAgentNotifiesRunOnceCompletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan notify.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event notify.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding notification: %v", err)
+		}
+		events <- event
+	}))
+	defer server.Close()
+	// RunOnce tasks are notified about regardless of the minimum duration
+	notifier := notify.New(notify.Config{WebhookURL: server.URL, MinDuration: time.Hour, UIURL: "http://localhost:8888"})
+	defer notifier.Close()
+
+	client := mocks.NewMockClient(ctrl)
+	chat := mocks.NewMockChat(ctrl)
+	client.EXPECT().StartChat(gomock.Any(), "test-model").Return(chat)
+	client.EXPECT().Close().Return(nil)
+	chat.EXPECT().Initialize(gomock.Any()).Return(nil)
+	chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).Return(gollm.ChatResponseIterator(func(yield func(gollm.ChatResponse, error) bool) {
+		yield(chatWith(fText("All pods are running.")), nil)
+	}), nil)
+
+	a := &Agent{
+		LLM:            client,
+		Model:          "test-model",
+		MaxIterations:  1,
+		PromptTemplate: "You are a Kubernetes assistant.",
+		DisableTools:   true,
+		RunOnce:        true,
+		InitialQuery:   "are my pods healthy?",
+		Notifier:       notifier,
+		Session:        &api.Session{ID: "s1"},
+	}
+	if err := a.Init(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer a.Close()
+	if err := a.Run(ctx, ""); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	for range a.Output {
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != notify.EventCompleted || event.Query != "are my pods healthy?" || event.Summary != "All pods are running." || event.URL != "http://localhost:8888/?session=s1" {
+			t.Errorf("notification = %+v", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("no notification was sent")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/bundle"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+)
+
+// importAttachments imports the Attachments into the work directory, and registers
+// the tools retrieving their resources and logs.
+func (a *Agent) importAttachments() error {
+	b, err := bundle.Import(filepath.Join(a.workDir, "bundle"), a.Attachments...)
+	if err != nil {
+		return fmt.Errorf("importing attachments: %w", err)
+	}
+	a.bundle = b
+	for _, tool := range tools.BundleTools(b) {
+		a.Tools.RegisterTool(tool)
+	}
+	return nil
+}
+
+// attachmentsContext describes the attached bundle to the LLM before the first query,
+// or returns "" if there is none or it was described already.
+func (a *Agent) attachmentsContext() string {
+	if a.bundle == nil || a.bundleDescribed {
+		return ""
+	}
+	a.bundleDescribed = true
+	return "The user attached a support bundle, an offline snapshot of the resources and logs of the cluster, from " +
+		strings.Join(a.bundle.Sources, ", ") + ". It contains " + a.bundle.Summary() + "\n" +
+		"Troubleshoot from it with the bundle_* tools. The cluster may not be reachable, and may have changed since the snapshot."
+}
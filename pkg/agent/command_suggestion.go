@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/redact"
+)
+
+const (
+	// suggestionContextMessages is the number of recent messages of the session given as
+	// context for command suggestions, and suggestionContextChars the length kept of each.
+	suggestionContextMessages = 8
+	suggestionContextChars    = 300
+)
+
+// commandSuggestionPrompt asks to complete the kubectl command the user is typing.
+const commandSuggestionPrompt = `You complete the kubectl command a user is typing in a chat about their
+Kubernetes cluster. Use the recent conversation to fill in the resource names, namespaces
+and flags the user most likely means. Reply with the complete command only, on a single
+line, starting with exactly what the user typed, without explanations or code fences.
+
+Recent conversation:
+%s
+Command being typed:
+%s`
+
+// SuggestCommand completes the kubectl command the user is typing, e.g. "kubectl logs de"
+// into "kubectl logs deploy/web --namespace shop", using the recent messages of the session
+// as context. It asks SuggestModel once, outside of the chat, and returns the complete
+// command, or "" when the text is not a kubectl command or no completion extends it.
+// "/run kubectl ..." commands are completed too.
+func (c *Agent) SuggestCommand(ctx context.Context, partial string) (string, error) {
+	command := strings.TrimPrefix(partial, "/run ")
+	if !strings.HasPrefix(command, "kubectl ") || strings.TrimSpace(command) == "kubectl" || strings.Contains(partial, "\n") {
+		return "", nil
+	}
+
+	model := c.SuggestModel
+	if model == "" {
+		model = c.Model
+	}
+	resp, err := c.LLM.GenerateCompletion(ctx, &gollm.CompletionRequest{
+		Model:  model,
+		Prompt: fmt.Sprintf(commandSuggestionPrompt, c.suggestionContext(), command),
+	})
+	if err != nil {
+		return "", fmt.Errorf("asking %s for a command suggestion: %w", model, err)
+	}
+
+	suggestion := cleanCommandSuggestion(resp.Response())
+	if !strings.HasPrefix(suggestion, command) || len(suggestion) == len(command) {
+		return "", nil
+	}
+	return partial[:len(partial)-len(command)] + suggestion, nil
+}
+
+// suggestionContext returns the recent text messages and tool calls of the session, one per
+// line, with secrets redacted.
+func (c *Agent) suggestionContext() string {
+	c.sessionMu.Lock()
+	messages := c.Session.ChatMessageStore.ChatMessages()
+	c.sessionMu.Unlock()
+
+	var lines []string
+	for i := len(messages) - 1; i >= 0 && len(lines) < suggestionContextMessages; i-- {
+		m := messages[i]
+		text, ok := m.Payload.(string)
+		if !ok || text == "" {
+			continue
+		}
+		var speaker string
+		switch {
+		case m.Type == api.MessageTypeText && m.Source == api.MessageSourceUser:
+			speaker = "User"
+		case m.Type == api.MessageTypeText && m.Source == api.MessageSourceModel:
+			speaker = "Assistant"
+		case m.Type == api.MessageTypeToolCallRequest:
+			speaker = "Ran"
+		default:
+			continue
+		}
+		text = strings.Join(strings.Fields(redact.String(text)), " ")
+		if len(text) > suggestionContextChars {
+			text = text[:suggestionContextChars] + "..."
+		}
+		lines = append(lines, speaker+": "+text)
+	}
+
+	var b strings.Builder
+	for i := len(lines) - 1; i >= 0; i-- {
+		b.WriteString(lines[i] + "\n")
+	}
+	if b.Len() == 0 {
+		return "(none)\n"
+	}
+	return b.String()
+}
+
+// cleanCommandSuggestion returns the first line of the answer, without code fences and
+// backticks.
+func cleanCommandSuggestion(answer string) string {
+	for _, line := range strings.Split(answer, "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "`"))
+		if line == "" || strings.HasPrefix(line, "```") || line == "bash" || line == "sh" {
+			continue
+		}
+		return strings.TrimPrefix(line, "$ ")
+	}
+	return ""
+}
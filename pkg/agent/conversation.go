@@ -18,24 +18,32 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
+	"maps"
 	"os"
-	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/bundle"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/gitops"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/mcp"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/notify"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
 )
 
@@ -49,6 +57,10 @@ type Agent struct {
 	// Output is the channel to send messages to the UI.
 	Output chan any
 
+	// Events is the channel to send the steps of the agentic loop to the UI, such as LLM
+	// calls and tool calls, to show what the agent is doing.
+	Events chan *api.AgentEvent
+
 	// RunOnce indicates if the agent should run only once.
 	// If true, the agent will run only once and then exit.
 	// If false, the agent will run in a loop until the context is done.
@@ -58,6 +70,24 @@ type Agent struct {
 	// If provided, the agent will run only once and then exit.
 	InitialQuery string
 
+	// Attachments are support bundles to troubleshoot from, directories or archives of
+	// manifests and logs, e.g. of kubectl cluster-info dump. They are imported into the
+	// work directory, and the bundle_* tools retrieve their content.
+	Attachments []string
+
+	// PipedInput is command output piped to kubectl-ai by the user, e.g. of kubectl get pods -A.
+	// It is sent with the first query, truncated and redacted, so the command need not run again.
+	PipedInput string
+
+	// StreamPartialResponses makes the agent send the model's text to the Output
+	// channel as it streams in. Partial updates share the ID of the message that is
+	// eventually persisted, so UIs that opt in must replace messages by ID.
+	StreamPartialResponses bool
+
+	// ShowThoughts adds the reasoning of models that return it to the conversation, as
+	// thought messages. Otherwise the reasoning is only recorded in the journal.
+	ShowThoughts bool
+
 	// tool calls that are pending execution
 	// These will typically be all the tool calls suggested by the LLM in the
 	// previous iteration of the agentic loop.
@@ -74,6 +104,11 @@ type Agent struct {
 
 	// PromptTemplateFile allows specifying a custom template file
 	PromptTemplateFile string
+	// PromptTemplate replaces the default system prompt template, when PromptTemplateFile is not set
+	PromptTemplate string
+
+	// DisableTools runs the agent without any tool, so it only answers from the conversation
+	DisableTools bool
 	// ExtraPromptPaths allows specifying additional prompt templates
 	// to be combined with PromptTemplateFile
 	ExtraPromptPaths []string
@@ -98,12 +133,68 @@ type Agent struct {
 
 	EnableToolUseShim bool
 
+	// RetryConfigs are the retry policies of LLM requests, by provider ID. Unset fields,
+	// and providers without a policy, use DefaultLLMRetryConfig.
+	RetryConfigs map[string]gollm.RetryConfig
+
+	// ShimRepairModel is the model asked to rewrite ReAct responses that do not parse when
+	// EnableToolUseShim is set. It defaults to Model, but a small model is enough.
+	ShimRepairModel string
+
+	// SuggestModel is the model completing the kubectl commands the user is typing, see
+	// SuggestCommand. It defaults to Model, but should be a small, fast model.
+	SuggestModel string
+
+	// Greeting is the message starting new sessions. It defaults to the greeting in Language.
+	Greeting string
+	// Persona describes the character and tone of the assistant, added to the system prompt.
+	Persona string
+	// Language is the language the assistant responds in, as a tag such as "es" or a name.
+	// The built-in messages are in that language when translated. English by default.
+	Language string
+
 	// MCPClientEnabled indicates whether MCP client mode is enabled
 	MCPClientEnabled bool
+	// MCPServers limits the MCP client to these servers of the MCP config. All if empty.
+	MCPServers []string
+
+	// GitOps proposes changes to the cluster as commits and pull requests with the
+	// propose_change tool, and the other tool calls modifying resources are refused.
+	GitOps *gitops.Proposer
+
+	// KubeContexts are the kubeconfig contexts the model can switch to with the use_context
+	// tool; switching to the ones labeled production always needs approval. kubectl commands
+	// of the kubectl and bash tools may not pick other contexts or kubeconfigs themselves (see
+	// tools.CheckKubectlContext). The tool is not registered, and there is no restriction, if
+	// empty.
+	KubeContexts []tools.KubeContext
+
+	// ReadOnly refuses the tool calls that modify resources, or may.
+	ReadOnly bool
+
+	// RequireJustification requires the model to explain every tool call in a sentence,
+	// shown in approval prompts and recorded in the journal. Calls without are refused.
+	RequireJustification bool
+
+	// AllowedNamespaces refuses kubectl commands, of the kubectl and bash tools, that do
+	// not name one of these namespaces with --namespace or reach beyond them (see
+	// tools.CheckKubectlNamespaces), the calls of the other built-in tools in other
+	// namespaces, node_health calls and the tools of MCP servers. No restriction if empty.
+	AllowedNamespaces []string
+
+	// MultiUser is set for the agents of servers shared by several users, e.g. with the
+	// serve command. Their tools all run on the server as the same OS user, so the bash
+	// tool is not registered, /run is not available, and kubectl commands that could reach
+	// past the kubeconfig of the user, e.g. reading files or naming other kubeconfigs, are
+	// refused (see tools.CheckKubectlSharedCommand).
+	MultiUser bool
 
 	// Recorder captures events for diagnostics
 	Recorder journal.Recorder
 
+	// Notifier, if set, is notified when requests complete, fail or wait for approval.
+	Notifier *notify.Notifier
+
 	llmChat gollm.Chat
 
 	workDir string
@@ -123,6 +214,43 @@ type Agent struct {
 
 	// mcpManager manages MCP client connections
 	mcpManager *mcp.Manager
+	// mcpWatchCancel stops watching the MCP config for changes
+	mcpWatchCancel context.CancelFunc
+	// mcpToolsChanged is set when the MCP tool settings or servers changed, to register the tools again
+	mcpToolsChanged atomic.Bool
+	// mcpNotices are the MCP server status changes not yet reported in the session
+	mcpNotices   []string
+	mcpNoticesMu sync.Mutex
+
+	// toolsRunning is set while DispatchToolCalls runs tools, when MCP servers can
+	// ask the user to approve their sampling requests
+	toolsRunning atomic.Bool
+	// samplingApproved are the MCP servers whose sampling requests the user approved
+	// for the session; samplingMu serializes the requests
+	samplingApproved map[string]bool
+	samplingMu       sync.Mutex
+
+	// mcpResources is the content of the MCP resources sent in the conversation; it is
+	// only used by the agent loop
+	mcpResources map[mcp.Resource]string
+	// mcpResourcesStale are the MCP resources that changed since they were sent
+	mcpResourcesStale map[mcp.Resource]bool
+	mcpResourcesMu    sync.Mutex
+
+	// pipedInputSent is set once PipedInput was sent to the LLM
+	pipedInputSent bool
+
+	// userRuns are the results of the tool calls the user ran with /run, sent to the LLM
+	// with the next query
+	userRuns []string
+
+	// kubeconfigSource is the kubeconfig the agent was started with, Kubeconfig being a
+	// copy in the work directory once use_context switched contexts
+	kubeconfigSource string
+
+	// bundle is the imported Attachments; bundleDescribed is set once it was described to the LLM
+	bundle          *bundle.Bundle
+	bundleDescribed bool
 
 	// ChatMessageStore is the underlying session persistence layer.
 	ChatMessageStore api.ChatMessageStore
@@ -135,8 +263,48 @@ type Agent struct {
 
 	// cancel is the function to cancel the agent's context
 	cancel context.CancelFunc
+
+	// done is closed when the agent's context is cancelled
+	done <-chan struct{}
+
+	// runningChanged is called when the agent starts or stops processing a request
+	runningChanged func(running bool)
+
+	// output queues the messages for Output, see emit
+	output     *outputQueue
+	outputOnce sync.Once
+
+	// requestMu protects requestCtx, requestCancel and the tracing spans
+	requestMu sync.Mutex
+	// requestCtx is the context of the user request currently processed by the agentic loop
+	requestCtx context.Context
+	// requestCancel aborts the current user request
+	requestCancel context.CancelCauseFunc
+	// requestStarted and requestQuery describe the current request in notifications;
+	// they are reset once its completion was notified
+	requestStarted time.Time
+	requestQuery   string
+
+	// requestSpan and iterationSpan trace the current request and iteration of the
+	// agentic loop; iterationCtx carries the iteration span.
+	requestSpan   trace.Span
+	iterationSpan trace.Span
+	iterationCtx  context.Context
 }
 
+// ErrRequestCancelled is reported when the user cancels an in-flight request.
+var ErrRequestCancelled = errors.New("request cancelled by user")
+
+// errChangesProposedWithGitOps refuses commands modifying resources in GitOps mode.
+var errChangesProposedWithGitOps = errors.New("changes to the cluster are not applied directly in this session, propose them with the propose_change tool instead")
+
+// errReadOnly refuses commands modifying resources in read-only mode.
+var errReadOnly = errors.New("this session is read-only, commands that modify resources are not allowed")
+
+// errSkippedWithRefusedCall is the result of the tool calls not run because another call of
+// the same response was refused.
+var errSkippedWithRefusedCall = errors.New("not run because another tool call of the same response was refused, call it again if it is still needed")
+
 // Assert InMemoryChatStore implements ChatMessageStore
 var _ api.ChatMessageStore = &sessions.InMemoryChatStore{}
 
@@ -154,33 +322,140 @@ func (s *Agent) GetSession() *api.Session {
 
 // addMessage creates a new message, adds it to the session, and sends it to the output channel
 func (c *Agent) addMessage(source api.MessageSource, messageType api.MessageType, payload any) *api.Message {
-	c.sessionMu.Lock()
-	defer c.sessionMu.Unlock()
-	message := &api.Message{
+	return c.appendMessage(&api.Message{
 		ID:        uuid.New().String(),
 		Source:    source,
 		Type:      messageType,
 		Payload:   payload,
 		Timestamp: time.Now(),
+	})
+}
+
+// streamTextUpdate sends the model text accumulated so far to the output channel
+// without persisting it, so UIs can render a response while it streams.
+// The message ID is stable across updates: pass the returned message back in for the
+// next chunk, and persist the final text with appendMessage using the same ID.
+func (c *Agent) streamTextUpdate(prev *api.Message, text string) *api.Message {
+	update := &api.Message{
+		ID:        uuid.New().String(),
+		Source:    api.MessageSourceModel,
+		Type:      api.MessageTypeText,
+		Payload:   text,
+		Timestamp: time.Now(),
 	}
+	if prev != nil {
+		update.ID = prev.ID
+	}
+	c.emitStreamed(update)
+	return update
+}
+
+// appendMessage adds the message to the session and sends it to the output channel
+func (c *Agent) appendMessage(message *api.Message) *api.Message {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
 
 	// session should always have a ChatMessageStore at this point
 	c.Session.ChatMessageStore.AddChatMessage(message)
 	c.Session.LastModified = time.Now()
-	c.Output <- message
+	c.recordMessage(message)
+	c.emit(message)
 	return message
 }
 
+// modelHistory returns the messages to initialize the chat with: thoughts are shown to
+// users, but not sent back to the model.
+func modelHistory(messages []*api.Message) []*api.Message {
+	history := make([]*api.Message, 0, len(messages))
+	for _, message := range messages {
+		if message.Type != api.MessageTypeThought {
+			history = append(history, message)
+		}
+	}
+	return history
+}
+
+// DefaultLLMRetryConfig is the retry policy of LLM requests of providers without one.
+var DefaultLLMRetryConfig = gollm.RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: 10 * time.Second,
+	MaxBackoff:     60 * time.Second,
+	BackoffFactor:  2,
+	Jitter:         true,
+}
+
+// showRetryStatus tells the UIs that a failed LLM request will be retried, without
+// adding the status to the session.
+func (c *Agent) showRetryStatus(status gollm.RetryStatus) {
+	wait := status.Wait.Round(time.Second)
+	text := fmt.Sprintf("LLM request failed, retrying in %v", wait)
+	if status.RateLimited {
+		text = fmt.Sprintf("Waiting for provider quota (retry in %v)", wait)
+	}
+	c.emit(&api.Message{
+		ID:        uuid.New().String(),
+		Source:    api.MessageSourceAgent,
+		Type:      api.MessageTypeStatus,
+		Payload:   text,
+		Timestamp: time.Now(),
+	})
+}
+
+// addThought shows the reasoning of the model, or records it in the journal only.
+func (c *Agent) addThought(thought string) {
+	if c.ShowThoughts {
+		c.addMessage(api.MessageSourceModel, api.MessageTypeThought, thought)
+		return
+	}
+	c.recordEvent(&journal.Event{
+		Timestamp: time.Now(),
+		Action:    journal.ActionLLMThought,
+		Payload:   thought,
+	})
+}
+
+// recordMessage writes the message to the journal, so that the session can be replayed.
+func (c *Agent) recordMessage(message *api.Message) {
+	c.recordEvent(&journal.Event{
+		Timestamp: message.Timestamp,
+		Action:    journal.ActionAgentMessage,
+		Payload:   message,
+	})
+}
+
+// recordEvent writes an event of the session to the journal.
+func (c *Agent) recordEvent(event *journal.Event) {
+	if c.Recorder == nil {
+		return
+	}
+	ctx := journal.ContextWithSessionID(context.Background(), c.Session.ID)
+	if err := c.Recorder.Write(ctx, event); err != nil {
+		klog.Warningf("recording %s event to journal: %v", event.Action, err)
+	}
+}
+
 // setAgentState updates the agent state and ensures LastModified is updated
 func (c *Agent) setAgentState(newState api.AgentState) {
 	c.sessionMu.Lock()
-	defer c.sessionMu.Unlock()
 	currentState := c.agentState()
 	if currentState != newState {
 		klog.Infof("Agent state changing from %s to %s", currentState, newState)
 		c.Session.AgentState = newState
 		c.Session.LastModified = time.Now()
 	}
+	if newState == api.AgentStateDone || newState == api.AgentStateExited {
+		c.endRequestSpan()
+	}
+	c.sessionMu.Unlock()
+
+	if running := isRunningState(newState); c.runningChanged != nil && running != isRunningState(currentState) {
+		c.runningChanged(running)
+	}
+}
+
+// isRunningState reports whether the agent is in the middle of a request in the state.
+func isRunningState(state api.AgentState) bool {
+	return state == api.AgentStateRunning || state == api.AgentStateWaitingForInput
 }
 
 func (c *Agent) AgentState() api.AgentState {
@@ -200,6 +475,7 @@ func (s *Agent) Init(ctx context.Context) error {
 
 	s.Input = make(chan any, 10)
 	s.Output = make(chan any, 10)
+	s.Events = make(chan *api.AgentEvent, eventsQueueSize)
 	s.currIteration = 0
 	// when we support session, we will need to initialize this with the
 	// current history of the conversation.
@@ -237,80 +513,85 @@ func (s *Agent) Init(ctx context.Context) error {
 
 	log.Info("Created temporary working directory", "workDir", workDir)
 
-	switch s.Sandbox {
-	case "k8s":
-		sandboxName := fmt.Sprintf("kubectl-ai-sandbox-%s", uuid.New().String()[:8])
-
-		// Use default image if not specified
-		sandboxImage := s.SandboxImage
-		if sandboxImage == "" {
-			sandboxImage = "bitnami/kubectl:latest"
-		}
-
-		// Create sandbox with kubeconfig
-		sb, err := sandbox.NewKubernetesSandbox(sandboxName,
-			sandbox.WithKubeconfig(s.Kubeconfig),
-			sandbox.WithImage(sandboxImage),
-		)
-		if err != nil {
-			return fmt.Errorf("failed to create sandbox: %w", err)
-		}
-
-		s.executor = sb
-		log.Info("Created sandbox", "name", sandboxName, "image", sandboxImage)
-
-	case "seatbelt":
-		if runtime.GOOS != "darwin" {
-			return fmt.Errorf("seatbelt sandbox is only supported on macOS")
-		}
-		s.executor = sandbox.NewSeatbeltExecutor()
-		log.Info("Using Seatbelt executor")
-
-	case "":
-		// No sandbox, use local executor
-		s.executor = sandbox.NewLocalExecutor()
-
-	default:
-		return fmt.Errorf("unknown sandbox type: %s", s.Sandbox)
+	executor, err := sandbox.NewExecutor(s.Sandbox, s.Kubeconfig, s.SandboxImage)
+	if err != nil {
+		return err
 	}
+	s.executor = executor
 
 	s.workDir = workDir
 
 	// Register tools with executor if none registered yet
 	// We clone existing tools (e.g. custom tools) to ensure we have a fresh map
 	// This avoids polluting the global default tools and ensures thread safety.
-	s.Tools = s.Tools.CloneWithExecutor(s.executor)
+	if s.DisableTools {
+		s.Tools = tools.Tools{}
+		s.Tools.Init()
+	} else {
+		s.Tools = s.Tools.CloneWithExecutor(s.executor)
 
-	s.Tools.RegisterTool(tools.NewBashTool(s.executor))
-	s.Tools.RegisterTool(tools.NewKubectlTool(s.executor))
+		if !s.MultiUser {
+			s.Tools.RegisterTool(tools.NewBashTool(s.executor))
+		}
+		s.Tools.RegisterTool(tools.NewKubectlTool(s.executor))
+		s.Tools.RegisterTool(tools.NewDescribeWorkloadTool(s.executor))
+		s.Tools.RegisterTool(tools.NewNodeHealthTool(s.executor))
+		s.Tools.RegisterTool(tools.NewRBACCheckTool(s.executor))
+		s.Tools.RegisterTool(tools.NewCheckConnectivityTool(s.executor))
+		s.Tools.RegisterTool(tools.NewJobStatusTool(s.executor))
+		s.Tools.RegisterTool(tools.NewRolloutTool(s.executor))
+		s.Tools.RegisterTool(tools.NewObjectGraphTool(s.executor))
+		s.Tools.RegisterTool(tools.NewValidateManifestTool(s.executor))
+		s.Tools.RegisterTool(tools.NewResourceUsageTool(s.executor))
+		if s.GitOps != nil {
+			s.Tools.RegisterTool(tools.NewProposeChangeTool(s.GitOps))
+		}
+		if len(s.Attachments) > 0 {
+			if err := s.importAttachments(); err != nil {
+				return err
+			}
+		}
+		if len(s.KubeContexts) > 0 {
+			if err := s.registerUseContext(); err != nil {
+				return err
+			}
+		}
+	}
 
-	systemPrompt, err := s.generatePrompt(ctx, defaultSystemPromptTemplate, PromptData{
+	promptTemplate := defaultSystemPromptTemplate
+	if s.PromptTemplate != "" {
+		promptTemplate = s.PromptTemplate
+	}
+	systemPrompt, err := s.generatePrompt(ctx, promptTemplate, PromptData{
 		Tools:             s.Tools,
 		EnableToolUseShim: s.EnableToolUseShim,
 		// RunOnce is a good proxy to indicate the agentic session is non-interactive mode.
 		SessionIsInteractive: !s.RunOnce,
+		Persona:              s.Persona,
+		Language:             s.promptLanguage(),
 	})
 	if err != nil {
 		return fmt.Errorf("generating system prompt: %w", err)
 	}
 
 	// Start a new chat session
-	s.llmChat = gollm.NewRetryChat(
-		s.LLM.StartChat(systemPrompt, s.Model),
-		gollm.RetryConfig{
-			MaxAttempts:    3,
-			InitialBackoff: 10 * time.Second,
-			MaxBackoff:     60 * time.Second,
-			BackoffFactor:  2,
-			Jitter:         true,
-		},
-	)
-	err = s.llmChat.Initialize(s.Session.ChatMessageStore.ChatMessages())
+	var llmChat gollm.Chat
+	if s.EnableToolUseShim {
+		restore := s.useReActResponseSchema()
+		llmChat = s.LLM.StartChat(systemPrompt, s.Model)
+		restore()
+	} else {
+		llmChat = s.LLM.StartChat(systemPrompt, s.Model)
+	}
+	retryConfig := s.RetryConfigs[s.Provider].WithDefaults(DefaultLLMRetryConfig)
+	retryConfig.OnRetry = s.showRetryStatus
+	s.llmChat = gollm.NewRetryChat(llmChat, retryConfig)
+	err = s.llmChat.Initialize(modelHistory(s.Session.ChatMessageStore.ChatMessages()))
 	if err != nil {
 		return fmt.Errorf("initializing chat session: %w", err)
 	}
 
-	if s.MCPClientEnabled {
+	if s.MCPClientEnabled && !s.DisableTools {
 		if err := s.InitializeMCPClient(ctx); err != nil {
 			klog.Errorf("Failed to initialize MCP client: %v", err)
 			return fmt.Errorf("failed to initialize MCP client: %w", err)
@@ -322,24 +603,50 @@ func (s *Agent) Init(ctx context.Context) error {
 		}
 	}
 
-	if !s.EnableToolUseShim {
-		var functionDefinitions []*gollm.FunctionDefinition
-		for _, tool := range s.Tools.AllTools() {
-			functionDefinitions = append(functionDefinitions, tool.FunctionDefinition())
-		}
-		// Sort function definitions to help KV cache reuse
-		sort.Slice(functionDefinitions, func(i, j int) bool {
-			return functionDefinitions[i].Name < functionDefinitions[j].Name
-		})
-		if err := s.llmChat.SetFunctionDefinitions(functionDefinitions); err != nil {
-			return fmt.Errorf("setting function definitions: %w", err)
+	if !s.EnableToolUseShim && !s.DisableTools {
+		if err := s.setFunctionDefinitions(); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// setFunctionDefinitions tells the LLM about the registered tools.
+func (s *Agent) setFunctionDefinitions() error {
+	var functionDefinitions []*gollm.FunctionDefinition
+	for _, tool := range s.Tools.AllTools() {
+		definition := tool.FunctionDefinition()
+		if s.RequireJustification {
+			definition = withJustification(definition)
+		}
+		functionDefinitions = append(functionDefinitions, definition)
+	}
+	// Sort function definitions to help KV cache reuse
+	sort.Slice(functionDefinitions, func(i, j int) bool {
+		return functionDefinitions[i].Name < functionDefinitions[j].Name
+	})
+	if err := s.llmChat.SetFunctionDefinitions(functionDefinitions); err != nil {
+		return fmt.Errorf("setting function definitions: %w", err)
+	}
+	return nil
+}
+
+// Close releases the resources of the agent, see Shutdown, waiting up to two minutes for
+// its sandbox to be torn down.
 func (c *Agent) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return c.Shutdown(ctx)
+}
+
+// Shutdown cancels the request in progress, if any, and waits for the agentic loop to
+// record its outcome in the session. Then it releases the resources of the agent, tearing
+// down its sandbox and MCP connections. Waiting and tearing down stop when ctx expires.
+func (c *Agent) Shutdown(ctx context.Context) error {
+	if c.CancelRequest() {
+		c.waitForRequestEnd(ctx)
+	}
 	if c.workDir != "" {
 		if c.RemoveWorkDir {
 			if err := os.RemoveAll(c.workDir); err != nil {
@@ -355,8 +662,6 @@ func (c *Agent) Close() error {
 	// Close sandbox if enabled
 	// Close executor if it exists
 	if c.executor != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		defer cancel()
 		if err := c.executor.Close(ctx); err != nil {
 			klog.Warningf("error cleaning up executor: %v", err)
 		} else {
@@ -367,6 +672,7 @@ func (c *Agent) Close() error {
 	if c.cancel != nil {
 		c.cancel()
 	}
+	c.stopOutput()
 	// Close the LLM client
 	if c.LLM != nil {
 		if err := c.LLM.Close(); err != nil {
@@ -380,12 +686,87 @@ func (c *Agent) LastErr() error {
 	return c.lastErr
 }
 
+// CancelRequest aborts the request currently being processed by the agentic loop,
+// including any in-flight LLM call or tool execution and pending approvals.
+// It returns false if there is no request to cancel.
+func (c *Agent) CancelRequest() bool {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	if c.requestCancel == nil || c.requestCtx.Err() != nil {
+		return false
+	}
+	c.requestCancel(ErrRequestCancelled)
+	return true
+}
+
+// waitForRequestEnd waits until the agentic loop is done with the current request, or ctx
+// expires.
+func (c *Agent) waitForRequestEnd(ctx context.Context) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for c.Session != nil && c.AgentState() == api.AgentStateRunning {
+		select {
+		case <-ctx.Done():
+			klog.Warningf("gave up waiting for the request of session %s to end: %v", c.Session.ID, ctx.Err())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startRequest creates the cancellable context for a new user request.
+func (c *Agent) startRequest(ctx context.Context, query string) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	if c.requestCancel != nil {
+		c.requestCancel(context.Canceled)
+	}
+	// Tag journal events with the session the request runs in; it can change between requests.
+	if c.Session != nil {
+		ctx = journal.ContextWithSessionID(ctx, c.Session.ID)
+	}
+	ctx = c.startRequestSpan(ctx)
+	c.requestCtx, c.requestCancel = context.WithCancelCause(ctx)
+	c.requestStarted, c.requestQuery = time.Now(), query
+}
+
+// requestContext returns the context of the current user request,
+// falling back to ctx when no request has been started.
+func (c *Agent) requestContext(ctx context.Context) context.Context {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	if c.iterationCtx != nil {
+		return c.iterationCtx
+	}
+	if c.requestCtx == nil {
+		return ctx
+	}
+	return c.requestCtx
+}
+
+// requestError returns ErrRequestCancelled if the request was cancelled by the user, err otherwise.
+func requestError(reqCtx context.Context, err error) error {
+	if cause := context.Cause(reqCtx); errors.Is(cause, ErrRequestCancelled) {
+		return cause
+	}
+	return err
+}
+
+// Done returns a channel that is closed once the agent has been closed.
+// It returns nil (blocks forever) for agents not started by an AgentManager.
+func (c *Agent) Done() <-chan struct{} {
+	return c.done
+}
+
 func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 	log := klog.FromContext(ctx)
 
 	if c.Recorder != nil {
 		ctx = journal.ContextWithRecorder(ctx, c.Recorder)
 	}
+	if c.Session != nil {
+		ctx = journal.ContextWithSessionID(ctx, c.Session.ID)
+	}
 
 	// Save unexpected error and return it in for RunOnce mode
 	log.Info("Starting agent loop", "initialQuery", initialQuery, "runOnce", c.RunOnce)
@@ -402,29 +783,35 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 				log.Error(err, "error handling meta query")
 				c.setAgentState(api.AgentStateDone)
 				c.pendingFunctionCalls = []ToolCallAnalysis{}
-				c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Error: "+err.Error())
+				c.addErrorMessage(err)
 			} else if handled {
 				// initialQuery is the 'exit' or 'quit' metaquery
 				if c.AgentState() == api.AgentStateExited {
 					c.addMessage(api.MessageSourceAgent, api.MessageTypeText, answer)
-					close(c.Output)
+					c.closeOutput()
 					return
 				}
 				// we handled the meta query, so we don't need to run the agentic loop
 				c.setAgentState(api.AgentStateDone)
 				c.pendingFunctionCalls = []ToolCallAnalysis{}
 				c.addMessage(api.MessageSourceAgent, api.MessageTypeText, answer)
+			} else if initialQuery, err = c.resolvePrompt(ctx, initialQuery); err != nil {
+				log.Error(err, "error resolving prompt")
+				c.setAgentState(api.AgentStateDone)
+				c.pendingFunctionCalls = []ToolCallAnalysis{}
+				c.addErrorMessage(err)
 			} else {
 				// Start the agentic loop with the initial query
+				c.startRequest(ctx, initialQuery)
 				c.setAgentState(api.AgentStateRunning)
 				c.currIteration = 0
-				c.currChatContent = []any{initialQuery}
+				c.currChatContent = c.queryContent(ctx, initialQuery)
 				c.pendingFunctionCalls = []ToolCallAnalysis{}
 			}
 		} else {
 			if len(c.Session.Messages) == 0 {
 				// Starting new session
-				c.addMessage(api.MessageSourceAgent, api.MessageTypeText, "Hey there, what can I help you with today?")
+				c.addMessage(api.MessageSourceAgent, api.MessageTypeText, c.greeting())
 			}
 		}
 		c.lastErr = nil
@@ -433,10 +820,13 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 			log.Info("Agent loop iteration", "state", c.AgentState())
 			switch c.AgentState() {
 			case api.AgentStateIdle, api.AgentStateDone:
+				c.notifyRequestDone()
 				// In RunOnce mode, we are done, so exit
 				if c.RunOnce {
 					log.Info("RunOnce mode, exiting agent loop")
 					c.setAgentState(api.AgentStateExited)
+					// Nothing else is sent after the answer, so tell the UI we are done
+					c.closeOutput()
 					return
 				}
 				log.Info("initiating user input")
@@ -450,7 +840,7 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 					if userInput == io.EOF {
 						log.Info("Agent loop done, EOF received")
 						c.setAgentState(api.AgentStateExited)
-						c.addMessage(api.MessageSourceAgent, api.MessageTypeText, "It has been a pleasure assisting you. Have a great day!")
+						c.addMessage(api.MessageSourceAgent, api.MessageTypeText, c.locale().Goodbye)
 						return
 					}
 					query, ok := userInput.(*api.UserInputResponse)
@@ -462,6 +852,26 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 						log.Info("No query provided, skipping agentic loop")
 						continue
 					}
+					if query.EditMessageID != "" {
+						if err := c.rewindToMessage(query.EditMessageID); err != nil {
+							log.Error(err, "error editing message")
+							c.setAgentState(api.AgentStateDone)
+							c.pendingFunctionCalls = []ToolCallAnalysis{}
+							c.addErrorMessage(err)
+							continue
+						}
+					}
+					if len(query.QuotedMessageIDs) > 0 {
+						quoted, err := c.quoteMessages(query.QuotedMessageIDs)
+						if err != nil {
+							log.Error(err, "error quoting messages")
+							c.setAgentState(api.AgentStateDone)
+							c.pendingFunctionCalls = []ToolCallAnalysis{}
+							c.addErrorMessage(err)
+							continue
+						}
+						query.Query = quoted + "\n" + query.Query
+					}
 					c.addMessage(api.MessageSourceUser, api.MessageTypeText, query.Query)
 					// we don't need the agentic loop for meta queries
 					// for ex. model, tools, etc.
@@ -470,14 +880,14 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 						log.Error(err, "error handling meta query")
 						c.setAgentState(api.AgentStateDone)
 						c.pendingFunctionCalls = []ToolCallAnalysis{}
-						c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Error: "+err.Error())
+						c.addErrorMessage(err)
 						continue
 					}
 					if handled {
 						// metaquery set the state to 'Exited', so we should exit
 						if c.AgentState() == api.AgentStateExited {
 							c.addMessage(api.MessageSourceAgent, api.MessageTypeText, answer)
-							close(c.Output)
+							c.closeOutput()
 							return
 						}
 						// we handled the meta query, so we don't need to run the agentic loop
@@ -486,10 +896,20 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 						c.addMessage(api.MessageSourceAgent, api.MessageTypeText, answer)
 						continue
 					}
+					// A prompt from an MCP server is sent in place of the query
+					userQuery, err := c.resolvePrompt(ctx, query.Query)
+					if err != nil {
+						log.Error(err, "error resolving prompt")
+						c.setAgentState(api.AgentStateDone)
+						c.pendingFunctionCalls = []ToolCallAnalysis{}
+						c.addErrorMessage(err)
+						continue
+					}
 
+					c.startRequest(ctx, query.Query)
 					c.setAgentState(api.AgentStateRunning)
 					c.currIteration = 0
-					c.currChatContent = []any{query.Query}
+					c.currChatContent = c.queryContent(ctx, userQuery)
 					c.pendingFunctionCalls = []ToolCallAnalysis{}
 					log.Info("Set agent state to running, will process agentic loop", "currIteration", c.currIteration, "currChatContent", len(c.currChatContent))
 				}
@@ -498,18 +918,28 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 				if c.RunOnce {
 					log.Error(nil, "RunOnce mode cannot handle user choice requests")
 					c.setAgentState(api.AgentStateExited)
-					c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Error: RunOnce mode cannot handle user choice requests")
+					c.addErrorText(api.ErrorCategoryUnknown, "Error: RunOnce mode cannot handle user choice requests")
 					return
 				}
+				reqCtx := c.requestContext(ctx)
 				select {
 				case <-ctx.Done():
 					log.Info("Agent loop done")
 					return
+				case <-reqCtx.Done():
+					if ctx.Err() != nil {
+						log.Info("Agent loop done")
+						return
+					}
+					log.Info("Request cancelled while waiting for user choice")
+					c.setAgentState(api.AgentStateDone)
+					c.pendingFunctionCalls = []ToolCallAnalysis{}
+					c.addErrorMessage(ErrRequestCancelled)
 				case userInput = <-c.Input:
 					if userInput == io.EOF {
 						log.Info("Agent loop done, EOF received")
 						c.setAgentState(api.AgentStateExited)
-						c.addMessage(api.MessageSourceAgent, api.MessageTypeText, "It has been a pleasure assisting you. Have a great day!")
+						c.addMessage(api.MessageSourceAgent, api.MessageTypeText, c.locale().Goodbye)
 						return
 					}
 					choiceResponse, ok := userInput.(*api.UserChoiceResponse)
@@ -519,12 +949,13 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 					}
 					dispatchToolCalls := c.handleChoice(ctx, choiceResponse)
 					if dispatchToolCalls {
-						if err := c.DispatchToolCalls(ctx); err != nil {
+						if err := c.DispatchToolCalls(reqCtx); err != nil {
+							err = requestError(reqCtx, err)
 							log.Error(err, "error dispatching tool calls")
 							c.setAgentState(api.AgentStateDone)
 							c.pendingFunctionCalls = []ToolCallAnalysis{}
 							c.Session.LastModified = time.Now()
-							c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Error: "+err.Error())
+							c.addErrorMessage(err)
 							// In RunOnce mode, exit on tool execution error
 							if c.RunOnce {
 								c.setAgentState(api.AgentStateExited)
@@ -559,17 +990,44 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 				if c.currIteration >= c.MaxIterations {
 					c.setAgentState(api.AgentStateDone)
 					c.pendingFunctionCalls = []ToolCallAnalysis{}
-					c.addMessage(api.MessageSourceAgent, api.MessageTypeText, "Maximum number of iterations reached.")
+					c.addMessage(api.MessageSourceAgent, api.MessageTypeText, c.locale().MaxIterations)
+					continue
+				}
+
+				c.flushMCPNotices(ctx)
+				if c.mcpToolsChanged.Swap(false) {
+					if err := c.reloadMCPTools(ctx); err != nil {
+						log.Error(err, "error reloading MCP tools")
+					}
+				}
+
+				reqCtx := c.requestContext(ctx)
+				if reqCtx.Err() != nil {
+					// The request was cancelled between iterations
+					c.setAgentState(api.AgentStateDone)
+					c.pendingFunctionCalls = []ToolCallAnalysis{}
+					c.addErrorMessage(requestError(reqCtx, reqCtx.Err()))
 					continue
 				}
 
 				// we run the agentic loop for one iteration
-				stream, err := c.llmChat.SendStreaming(ctx, c.currChatContent...)
+				reqCtx = c.startIteration(ctx)
+				c.emitEvent(&api.AgentEvent{Type: api.AgentEventIterationStarted})
+				llmCtx, llmSpan := c.startLLMSpan(reqCtx)
+				llmStart := time.Now()
+				c.emitEvent(&api.AgentEvent{Type: api.AgentEventLLMCallStarted})
+				stream, err := c.llmChat.SendStreaming(llmCtx, c.currChatContent...)
 				if err != nil {
+					err = requestError(reqCtx, err)
+					endSpan(llmSpan, err)
+					c.emitStepFinished(api.AgentEventLLMCallFinished, llmStart, nil, err)
 					log.Error(err, "error sending streaming LLM response")
 					c.setAgentState(api.AgentStateDone)
 					c.pendingFunctionCalls = []ToolCallAnalysis{}
 					c.lastErr = err
+					if errors.Is(err, ErrRequestCancelled) {
+						c.addErrorMessage(err)
+					}
 					continue
 				}
 
@@ -578,8 +1036,10 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 
 				if c.EnableToolUseShim {
 					// convert the candidate response into a gollm.ChatResponse
-					stream, err = candidateToShimCandidate(stream)
+					stream, err = c.candidateToShimCandidate(llmCtx, stream)
 					if err != nil {
+						endSpan(llmSpan, err)
+						c.emitStepFinished(api.AgentEventLLMCallFinished, llmStart, nil, err)
 						c.setAgentState(api.AgentStateDone)
 						c.pendingFunctionCalls = []ToolCallAnalysis{}
 
@@ -597,6 +1057,16 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 
 				// accumulator for streamed text
 				var streamedText string
+				// accumulator for the reasoning of the model, which comes before its answer
+				var streamedThought string
+				flushThought := func() {
+					if streamedThought != "" {
+						c.addThought(streamedThought)
+						streamedThought = ""
+					}
+				}
+				// partialMessage is the last streamed update of the text, if any
+				var partialMessage *api.Message
 				var llmError error
 
 				for response, err := range stream {
@@ -625,10 +1095,21 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 					candidate := response.Candidates()[0]
 
 					for _, part := range candidate.Parts() {
+						// Check if it's the reasoning of the model
+						if thoughtPart, ok := part.(gollm.ThoughtPart); ok {
+							if thought, ok := thoughtPart.AsThought(); ok {
+								streamedThought += thought
+							}
+						}
+
 						// Check if it's a text response
 						if text, ok := part.AsText(); ok {
 							log.Info("text response", "text", text)
 							streamedText += text
+							if c.StreamPartialResponses && text != "" {
+								flushThought()
+								partialMessage = c.streamTextUpdate(partialMessage, streamedText)
+							}
 						}
 
 						// Check if it's a function call
@@ -638,18 +1119,33 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 						}
 					}
 				}
+				llmSpan.SetAttributes(attribute.Int("gen_ai.response.function_calls", len(functionCalls)))
+				endSpan(llmSpan, llmError)
+				c.emitStepFinished(api.AgentEventLLMCallFinished, llmStart, nil, llmError)
 				if llmError != nil {
+					llmError = requestError(reqCtx, llmError)
 					log.Error(llmError, "error streaming LLM response")
 					c.setAgentState(api.AgentStateDone)
 					c.pendingFunctionCalls = []ToolCallAnalysis{}
-					c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Error: "+llmError.Error())
+					c.addErrorMessage(llmError)
 					c.lastErr = llmError
 					continue
 				}
 				log.Info("streamedText", "streamedText", streamedText)
 
+				flushThought()
 				if streamedText != "" {
-					c.addMessage(api.MessageSourceModel, api.MessageTypeText, streamedText)
+					if partialMessage != nil {
+						c.appendMessage(&api.Message{
+							ID:        partialMessage.ID,
+							Source:    api.MessageSourceModel,
+							Type:      api.MessageTypeText,
+							Payload:   streamedText,
+							Timestamp: time.Now(),
+						})
+					} else {
+						c.addMessage(api.MessageSourceModel, api.MessageTypeText, streamedText)
+					}
 				}
 				// If no function calls to be made, we're done
 				if len(functionCalls) == 0 {
@@ -669,78 +1165,91 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 					continue
 				}
 
-				toolCallAnalysisResults, err := c.analyzeToolCalls(ctx, functionCalls)
+				toolCallAnalysisResults, err := c.analyzeToolCalls(reqCtx, functionCalls)
 				if err != nil {
 					log.Error(err, "error analyzing tool calls")
 					c.setAgentState(api.AgentStateDone)
 					c.pendingFunctionCalls = []ToolCallAnalysis{}
 					c.Session.LastModified = time.Now()
-					c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Error: "+err.Error())
+					c.addErrorMessage(err)
 					c.lastErr = err
 					continue
 				}
 
 				// mark the tools for dispatching
 				c.pendingFunctionCalls = toolCallAnalysisResults
+				c.emitEvent(&api.AgentEvent{Type: api.AgentEventToolsAnalyzed, Tools: describeToolCalls(toolCallAnalysisResults)})
 
-				interactiveToolCallIndex := -1
+				blocked := false
 				modifiesResourceToolCallIndex := -1
+				alwaysAsk := false
 				for i, result := range toolCallAnalysisResults {
 					if result.ModifiesResourceStr != "no" {
 						modifiesResourceToolCallIndex = i
 					}
-					if result.IsInteractive {
-						interactiveToolCallIndex = i
-					}
+					blocked = blocked || result.blockedError() != nil
+					alwaysAsk = alwaysAsk || result.AlwaysAsk
 				}
 
-				if interactiveToolCallIndex >= 0 {
-					// Show error block for both shim enabled and disabled modes
-					errorMessage := fmt.Sprintf("  %s\n", toolCallAnalysisResults[interactiveToolCallIndex].IsInteractiveError.Error())
-					c.addMessage(api.MessageSourceAgent, api.MessageTypeError, errorMessage)
-
-					if c.EnableToolUseShim {
-						// Add the error as an observation
-						observation := fmt.Sprintf("Result of running %q:\n%v",
-							toolCallAnalysisResults[interactiveToolCallIndex].FunctionCall.Name,
-							toolCallAnalysisResults[interactiveToolCallIndex].IsInteractiveError.Error())
-						c.currChatContent = append(c.currChatContent, observation)
-					} else {
-						// For models with tool-use support (shim disabled), use proper FunctionCallResult
-						// Note: This assumes the model supports sending FunctionCallResult
-						c.currChatContent = append(c.currChatContent, gollm.FunctionCallResult{
-							ID:     toolCallAnalysisResults[interactiveToolCallIndex].FunctionCall.ID,
-							Name:   toolCallAnalysisResults[interactiveToolCallIndex].FunctionCall.Name,
-							Result: map[string]any{"error": toolCallAnalysisResults[interactiveToolCallIndex].IsInteractiveError.Error()},
-						})
+				if blocked {
+					// None of the calls run, and each of them gets a result: providers like
+					// Anthropic reject requests with tool calls lacking one.
+					for _, call := range toolCallAnalysisResults {
+						err := call.blockedError()
+						if err != nil {
+							// Show error block for both shim enabled and disabled modes
+							c.addErrorText(api.ErrorCategoryPolicyRefused, fmt.Sprintf("  %s\n", err.Error()))
+						} else {
+							err = errSkippedWithRefusedCall
+						}
+
+						if c.EnableToolUseShim {
+							// Add the error as an observation
+							observation := fmt.Sprintf("Result of running %q:\n%v", call.FunctionCall.Name, err.Error())
+							c.currChatContent = append(c.currChatContent, observation)
+						} else {
+							// For models with tool-use support (shim disabled), use proper FunctionCallResult
+							c.currChatContent = append(c.currChatContent, gollm.FunctionCallResult{
+								ID:     call.FunctionCall.ID,
+								Name:   call.FunctionCall.Name,
+								Result: map[string]any{"error": err.Error()},
+							})
+						}
 					}
 					c.pendingFunctionCalls = []ToolCallAnalysis{} // reset pending function calls
 					c.currIteration = c.currIteration + 1
-					continue // Skip execution for interactive commands
+					continue // Skip execution for interactive and refused commands
 				}
 
-				if !c.SkipPermissions && modifiesResourceToolCallIndex >= 0 {
+				if (!c.SkipPermissions && modifiesResourceToolCallIndex >= 0) || alwaysAsk {
 					// In RunOnce mode, exit with error if permission is required
 					if c.RunOnce {
 						var commandDescriptions []string
 						for _, call := range c.pendingFunctionCalls {
 							commandDescriptions = append(commandDescriptions, call.ParsedToolCall.Description())
 						}
-						errorMessage := "RunOnce mode cannot handle permission requests. The following commands require approval:\n* " + strings.Join(commandDescriptions, "\n* ")
-						errorMessage += "\nUse --skip-permissions flag to bypass permission checks in RunOnce mode."
+						errorMessage := "RunOnce mode cannot handle permission requests. The following commands require approval:" + describeCalls(c.pendingFunctionCalls)
+						if alwaysAsk {
+							errorMessage += "\nSwitching to production contexts, and the tools of MCP servers with the ask-always trust level, need approval even with --skip-permissions."
+						} else {
+							errorMessage += "\nUse --skip-permissions flag to bypass permission checks in RunOnce mode."
+						}
 
 						log.Error(nil, "RunOnce mode cannot handle permission requests", "commands", commandDescriptions)
+						c.recordPermissionDecision(PermissionDecisionRefused)
+						c.notifyApprovalRequired(commandDescriptions)
 						c.setAgentState(api.AgentStateExited)
-						c.addMessage(api.MessageSourceAgent, api.MessageTypeError, errorMessage)
+						c.addErrorText(api.ErrorCategoryPolicyRefused, errorMessage)
 						c.lastErr = fmt.Errorf("%s", errorMessage)
 						return
 					}
 
-					var commandDescriptions []string
+					var commandDescriptions, justifications []string
 					for _, call := range c.pendingFunctionCalls {
 						commandDescriptions = append(commandDescriptions, call.ParsedToolCall.Description())
+						justifications = append(justifications, call.Justification)
 					}
-					confirmationPrompt := "The following commands require your approval to run:\n* " + strings.Join(commandDescriptions, "\n* ")
+					confirmationPrompt := "The following commands require your approval to run:" + describeCalls(c.pendingFunctionCalls)
 					confirmationPrompt += "\n\nDo you want to proceed ?"
 
 					choiceRequest := &api.UserChoiceRequest{
@@ -750,7 +1259,14 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 							{Value: "yes_and_dont_ask_me_again", Label: "Yes, and don't ask me again"},
 							{Value: "no", Label: "No"},
 						},
+						Commands: commandDescriptions,
 					}
+					if c.RequireJustification {
+						choiceRequest.Justifications = justifications
+					}
+					c.recordPermissionRequest(choiceRequest)
+					c.notifyApprovalRequired(commandDescriptions)
+					c.emitEvent(&api.AgentEvent{Type: api.AgentEventApprovalRequested, Tools: commandDescriptions})
 					c.setAgentState(api.AgentStateWaitingForInput)
 					c.addMessage(api.MessageSourceAgent, api.MessageTypeUserChoiceRequest, choiceRequest)
 					// Request input from the user by sending a message on the output channel.
@@ -759,13 +1275,18 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 					continue
 				}
 
+				if modifiesResourceToolCallIndex >= 0 {
+					c.recordPermissionDecision(PermissionDecisionAutoApproved)
+				}
+
 				// we are here means we are in the clear to dispatch the tool calls
-				if err := c.DispatchToolCalls(ctx); err != nil {
+				if err := c.DispatchToolCalls(reqCtx); err != nil {
+					err = requestError(reqCtx, err)
 					log.Error(err, "error dispatching tool calls")
 					c.setAgentState(api.AgentStateDone)
 					c.pendingFunctionCalls = []ToolCallAnalysis{}
 					c.Session.LastModified = time.Now()
-					c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Error: "+err.Error())
+					c.addErrorMessage(err)
 					c.lastErr = err
 					continue
 				}
@@ -780,6 +1301,12 @@ func (c *Agent) Run(ctx context.Context, initialQuery string) error {
 }
 
 func (c *Agent) handleMetaQuery(ctx context.Context, query string) (answer string, handled bool, err error) {
+	// Only /run runs tools: many requests start with "run"
+	if text, ok := strings.CutPrefix(query, "/run"); ok && (text == "" || text[0] == ' ') {
+		answer, err := c.runTool(ctx, strings.TrimSpace(text))
+		return answer, err == nil, err
+	}
+	query = trimMetaCommandPrefix(query)
 	switch query {
 	case "clear", "reset":
 		c.sessionMu.Lock()
@@ -787,12 +1314,15 @@ func (c *Agent) handleMetaQuery(ctx context.Context, query string) (answer strin
 		if err := c.Session.ChatMessageStore.ClearChatMessages(); err != nil {
 			return "Failed to clear the conversation", false, err
 		}
-		c.llmChat.Initialize(c.Session.ChatMessageStore.ChatMessages())
+		c.llmChat.Initialize(modelHistory(c.Session.ChatMessageStore.ChatMessages()))
 		c.sessionMu.Unlock()
-		return "Cleared the conversation.", true, nil
+		// The MCP resources are sent again with the next query
+		c.mcpResources = nil
+		c.userRuns = nil
+		return c.locale().Cleared, true, nil
 	case "exit", "quit":
 		c.setAgentState(api.AgentStateExited)
-		return "It has been a pleasure assisting you. Have a great day!", true, nil
+		return c.locale().Goodbye, true, nil
 	case "model":
 		return "Current model is `" + c.Model + "`", true, nil
 	case "models":
@@ -801,8 +1331,16 @@ func (c *Agent) handleMetaQuery(ctx context.Context, query string) (answer strin
 			return "", false, fmt.Errorf("listing models: %w", err)
 		}
 		return "Available models:\n\n  - " + strings.Join(models, "\n  - ") + "\n\n", true, nil
+	case "mcp", "mcp status":
+		return c.mcpStatusText(), true, nil
 	case "tools":
 		return "Available tools:\n\n  - " + strings.Join(c.Tools.Names(), "\n  - ") + "\n\n", true, nil
+	case "prompts":
+		answer, err := c.listPrompts(ctx)
+		if err != nil {
+			return "", false, fmt.Errorf("listing prompts: %w", err)
+		}
+		return answer, true, nil
 	case "session":
 		if c.SessionBackend != "filesystem" {
 			return "Ephemeral session (memory backed). No persistent info available.", true, nil
@@ -884,6 +1422,11 @@ func (c *Agent) NewSession() (string, error) {
 		return "", fmt.Errorf("failed to create new session: %w", err)
 	}
 
+	// New sessions start in the context the agent was started with
+	if c.kubeconfigSource != "" {
+		c.Kubeconfig = c.kubeconfigSource
+	}
+
 	// If we are using a sandbox, we should spin up a new one for the new session
 	if c.Sandbox == "k8s" {
 		sandboxName := fmt.Sprintf("kubectl-ai-sandbox-%s", uuid.New().String()[:8])
@@ -913,8 +1456,19 @@ func (c *Agent) NewSession() (string, error) {
 		// Re-bind all tools to the new executor
 		c.Tools = c.Tools.CloneWithExecutor(c.executor)
 
-		c.Tools.RegisterTool(tools.NewBashTool(c.executor))
+		if !c.MultiUser {
+			c.Tools.RegisterTool(tools.NewBashTool(c.executor))
+		}
 		c.Tools.RegisterTool(tools.NewKubectlTool(c.executor))
+		c.Tools.RegisterTool(tools.NewDescribeWorkloadTool(c.executor))
+		c.Tools.RegisterTool(tools.NewNodeHealthTool(c.executor))
+		c.Tools.RegisterTool(tools.NewRBACCheckTool(c.executor))
+		c.Tools.RegisterTool(tools.NewCheckConnectivityTool(c.executor))
+		c.Tools.RegisterTool(tools.NewJobStatusTool(c.executor))
+		c.Tools.RegisterTool(tools.NewRolloutTool(c.executor))
+		c.Tools.RegisterTool(tools.NewObjectGraphTool(c.executor))
+		c.Tools.RegisterTool(tools.NewValidateManifestTool(c.executor))
+		c.Tools.RegisterTool(tools.NewResourceUsageTool(c.executor))
 		c.sessionMu.Unlock()
 	}
 
@@ -963,7 +1517,7 @@ func (c *Agent) SaveSession() (string, error) {
 	c.Session.Messages = messages
 
 	if c.llmChat != nil {
-		_ = c.llmChat.Initialize(c.Session.ChatMessageStore.ChatMessages())
+		_ = c.llmChat.Initialize(modelHistory(c.Session.ChatMessageStore.ChatMessages()))
 	}
 
 	return newSession.ID, nil
@@ -1016,7 +1570,7 @@ func (c *Agent) LoadSession(sessionID string) error {
 	}
 
 	if c.llmChat != nil {
-		if err := c.llmChat.Initialize(c.Session.ChatMessageStore.ChatMessages()); err != nil {
+		if err := c.llmChat.Initialize(modelHistory(c.Session.ChatMessageStore.ChatMessages())); err != nil {
 			return fmt.Errorf("failed to re-initialize chat with new session: %w", err)
 		}
 	}
@@ -1035,8 +1589,12 @@ func (c *Agent) listModels(ctx context.Context) ([]string, error) {
 	return c.availableModels, nil
 }
 
-func (c *Agent) DispatchToolCalls(ctx context.Context) error {
+func (c *Agent) DispatchToolCalls(ctx context.Context) (err error) {
 	log := klog.FromContext(ctx)
+	c.toolsRunning.Store(true)
+	defer c.toolsRunning.Store(false)
+	start, calls := time.Now(), describeToolCalls(c.pendingFunctionCalls)
+	defer func() { c.emitStepFinished(api.AgentEventToolsDispatched, start, calls, err) }()
 	// execute all pending function calls
 	for _, call := range c.pendingFunctionCalls {
 		// Only show "Running" message and proceed with execution for non-interactive commands
@@ -1044,11 +1602,14 @@ func (c *Agent) DispatchToolCalls(ctx context.Context) error {
 
 		c.addMessage(api.MessageSourceModel, api.MessageTypeToolCallRequest, toolDescription)
 
-		output, err := call.ParsedToolCall.InvokeTool(ctx, tools.InvokeToolOptions{
-			Kubeconfig: c.Kubeconfig,
-			WorkDir:    c.workDir,
-			Executor:   c.executor,
+		toolCtx, toolSpan := c.startToolSpan(ctx, call)
+		output, err := call.ParsedToolCall.InvokeTool(toolCtx, tools.InvokeToolOptions{
+			Kubeconfig:    c.Kubeconfig,
+			WorkDir:       c.workDir,
+			Executor:      c.executor,
+			Justification: call.Justification,
 		})
+		endSpan(toolSpan, err)
 
 		if err != nil {
 			log.Error(err, "error executing action", "output", output)
@@ -1058,7 +1619,7 @@ func (c *Agent) DispatchToolCalls(ctx context.Context) error {
 
 		// Handle timeout message using UI blocks
 		if execResult, ok := output.(*sandbox.ExecResult); ok && execResult != nil && execResult.StreamType == "timeout" {
-			c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "\nTimeout reached after 7 seconds\n")
+			c.addErrorText(api.ErrorCategoryToolTimeout, "\nTimeout reached after 7 seconds\n")
 		}
 		// Add the tool call result to maintain conversation flow
 		var payload any
@@ -1093,6 +1654,7 @@ func (c *Agent) DispatchToolCalls(ctx context.Context) error {
 // if some of the tool calls are not readonly, then the interesting question is should the permission
 // be asked for each of the tool call or only once for all the tool calls.
 // I think treating all tool calls as atomic is the right thing to do.
+// UIs can still approve calls one by one with UserChoiceResponse.Selections.
 
 type ToolCallAnalysis struct {
 	FunctionCall        gollm.FunctionCall
@@ -1100,11 +1662,29 @@ type ToolCallAnalysis struct {
 	IsInteractive       bool
 	IsInteractiveError  error
 	ModifiesResourceStr string
+	// AlwaysAsk is set for calls that need approval even when permission checks are
+	// skipped, e.g. the tools of MCP servers with the ask-always trust level.
+	AlwaysAsk bool
+	// RefusedError is why the call is not allowed to run at all, if it is not.
+	RefusedError error
+	// Justification is why the model makes the call, with RequireJustification.
+	Justification string
+}
+
+// blockedError returns why the call cannot run, or nil if it can.
+func (a *ToolCallAnalysis) blockedError() error {
+	if a.IsInteractive {
+		return a.IsInteractiveError
+	}
+	return a.RefusedError
 }
 
 func (c *Agent) analyzeToolCalls(ctx context.Context, toolCalls []gollm.FunctionCall) ([]ToolCallAnalysis, error) {
 	toolCallAnalysis := make([]ToolCallAnalysis, len(toolCalls))
 	for i, call := range toolCalls {
+		if c.RequireJustification {
+			toolCallAnalysis[i].Justification, call.Arguments = c.splitJustification(call.Arguments)
+		}
 		toolCallAnalysis[i].FunctionCall = call
 		toolCall, err := c.Tools.ParseToolInvocation(ctx, call.Name, call.Arguments)
 		if err != nil {
@@ -1116,6 +1696,84 @@ func (c *Agent) analyzeToolCalls(ctx context.Context, toolCalls []gollm.Function
 		}
 		toolCallAnalysis[i].ModifiesResourceStr = toolCall.GetTool().CheckModifiesResource(call.Arguments)
 		toolCallAnalysis[i].ParsedToolCall = toolCall
+
+		if c.GitOps != nil && toolCallAnalysis[i].ModifiesResourceStr == "yes" {
+			if _, ok := toolCall.GetTool().(*tools.ProposeChange); !ok {
+				toolCallAnalysis[i].RefusedError = errChangesProposedWithGitOps
+			}
+		}
+
+		if c.RequireJustification && toolCallAnalysis[i].Justification == "" {
+			toolCallAnalysis[i].RefusedError = c.missingJustificationError()
+		}
+
+		if c.ReadOnly && toolCallAnalysis[i].ModifiesResourceStr != "no" {
+			toolCallAnalysis[i].RefusedError = errReadOnly
+		}
+
+		if len(c.AllowedNamespaces) > 0 {
+			switch toolCall.GetTool().(type) {
+			case *tools.Kubectl, *tools.BashTool:
+				command, _ := call.Arguments["command"].(string)
+				if err := tools.CheckKubectlNamespaces(command, c.AllowedNamespaces); err != nil {
+					toolCallAnalysis[i].RefusedError = err
+				}
+			case *tools.DescribeWorkload, *tools.RBACCheck, *tools.JobStatus, *tools.Rollout, *tools.ObjectGraph, *tools.ResourceUsage:
+				namespace, _ := call.Arguments["namespace"].(string)
+				if err := tools.CheckNamespace(namespace, c.AllowedNamespaces); err != nil {
+					toolCallAnalysis[i].RefusedError = err
+				}
+			case *tools.CheckConnectivity:
+				for _, arg := range []string{"source_namespace", "destination_namespace"} {
+					namespace, _ := call.Arguments[arg].(string)
+					if err := tools.CheckNamespace(namespace, c.AllowedNamespaces); err != nil {
+						toolCallAnalysis[i].RefusedError = err
+						break
+					}
+				}
+			case *tools.NodeHealth:
+				// It lists the pods of all namespaces
+				toolCallAnalysis[i].RefusedError = fmt.Errorf("node_health spans all namespaces, only these namespaces are allowed: %s", strings.Join(c.AllowedNamespaces, ", "))
+			case *tools.MCPTool:
+				// Nothing tells which namespaces the tools of MCP servers act on
+				toolCallAnalysis[i].RefusedError = fmt.Errorf("tools of MCP servers cannot be used when only these namespaces are allowed: %s", strings.Join(c.AllowedNamespaces, ", "))
+			}
+		}
+
+		// Contexts are switched with use_context only, which asks before switching to production
+		if len(c.KubeContexts) > 0 {
+			switch toolCall.GetTool().(type) {
+			case *tools.Kubectl, *tools.BashTool:
+				command, _ := call.Arguments["command"].(string)
+				if err := tools.CheckKubectlContext(command); err != nil {
+					toolCallAnalysis[i].RefusedError = err
+				}
+			}
+		}
+
+		if _, ok := toolCall.GetTool().(*tools.Kubectl); ok && c.MultiUser {
+			command, _ := call.Arguments["command"].(string)
+			if err := tools.CheckKubectlSharedCommand(command); err != nil {
+				toolCallAnalysis[i].RefusedError = err
+			}
+		}
+
+		// Switching to production contexts always needs approval
+		if useContext, ok := toolCall.GetTool().(*tools.UseContext); ok && useContext.IsProduction(call.Arguments) {
+			toolCallAnalysis[i].AlwaysAsk = true
+		}
+
+		// Tools of MCP servers are approved per the trust level of their server
+		if mcpTool, ok := toolCall.GetTool().(*tools.MCPTool); ok {
+			switch mcpTool.TrustLevel() {
+			case mcp.TrustAskAlways:
+				toolCallAnalysis[i].AlwaysAsk = true
+			case mcp.TrustReadOnly:
+				if !mcpTool.ReadOnly() {
+					toolCallAnalysis[i].RefusedError = fmt.Errorf("tool %q is not allowed: MCP server %q is read-only, and the tool is not annotated as read-only", call.Name, mcpTool.ServerName())
+				}
+			}
+		}
 	}
 	return toolCallAnalysis, nil
 }
@@ -1126,37 +1784,91 @@ func (c *Agent) handleChoice(ctx context.Context, choice *api.UserChoiceResponse
 	// we need to abort all pending function calls.
 	// update the currChatContent with the choice and keep the agent loop running.
 
+	c.recordPermissionChoice(choice)
+
 	// Normalize the input
 	switch choice.Choice {
 	case 1:
-		dispatchToolCalls = true
+		dispatchToolCalls = c.applySelections(ctx, choice.Selections)
 	case 2:
-		c.SkipPermissions = true
-		dispatchToolCalls = true
+		// Approving only some of the calls does not stop asking for the others
+		if c.approvesAllCalls(choice) {
+			c.SkipPermissions = true
+		}
+		dispatchToolCalls = c.applySelections(ctx, choice.Selections)
 	case 3:
-		c.currChatContent = append(c.currChatContent, gollm.FunctionCallResult{
-			ID:   c.pendingFunctionCalls[0].FunctionCall.ID,
-			Name: c.pendingFunctionCalls[0].FunctionCall.Name,
-			Result: map[string]any{
-				"error":     "User declined to run this operation.",
-				"status":    "declined",
-				"retryable": false,
-			},
-		})
+		for _, call := range c.pendingFunctionCalls {
+			c.declineToolCall(call)
+		}
 		c.pendingFunctionCalls = []ToolCallAnalysis{}
 		dispatchToolCalls = false
-		c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Operation was skipped. User declined to run this operation.")
+		c.addErrorText(api.ErrorCategoryCancelled, "Operation was skipped. User declined to run this operation.")
 	default:
 		// This case should technically not be reachable due to AskForConfirmation loop
 		err := fmt.Errorf("invalid confirmation choice: %q", choice.Choice)
 		log.Error(err, "Invalid choice received from AskForConfirmation")
 		c.pendingFunctionCalls = []ToolCallAnalysis{}
 		dispatchToolCalls = false
-		c.addMessage(api.MessageSourceAgent, api.MessageTypeError, "Invalid choice received. Cancelling operation.")
+		c.addErrorText(api.ErrorCategoryUnknown, "Invalid choice received. Cancelling operation.")
 	}
 	return dispatchToolCalls
 }
 
+// approvesAllCalls reports whether the choice selects all the pending function calls.
+func (c *Agent) approvesAllCalls(choice *api.UserChoiceResponse) bool {
+	if len(choice.Selections) == 0 {
+		return true
+	}
+	return len(choice.Selections) == len(c.pendingFunctionCalls) && !slices.Contains(choice.Selections, false)
+}
+
+// applySelections removes the pending function calls the user declined, telling the
+// LLM about each of them. It reports whether any calls are left to dispatch.
+func (c *Agent) applySelections(ctx context.Context, selections []bool) bool {
+	if len(selections) == 0 {
+		return true
+	}
+	if len(selections) != len(c.pendingFunctionCalls) {
+		// Don't guess which calls were meant; run none of them.
+		klog.FromContext(ctx).Error(nil, "Selections do not match the pending tool calls", "selections", len(selections), "pending", len(c.pendingFunctionCalls))
+		for _, call := range c.pendingFunctionCalls {
+			c.declineToolCall(call)
+		}
+		c.pendingFunctionCalls = []ToolCallAnalysis{}
+		c.addErrorText(api.ErrorCategoryUnknown, "Invalid selection received. Cancelling operation.")
+		return false
+	}
+
+	var approved []ToolCallAnalysis
+	for i, call := range c.pendingFunctionCalls {
+		if selections[i] {
+			approved = append(approved, call)
+			continue
+		}
+		c.declineToolCall(call)
+		c.addErrorText(api.ErrorCategoryCancelled, fmt.Sprintf("Skipped %q. User declined to run this operation.", call.ParsedToolCall.Description()))
+	}
+	c.pendingFunctionCalls = approved
+	return len(approved) > 0
+}
+
+// declineToolCall tells the LLM that the user declined to run the call.
+func (c *Agent) declineToolCall(call ToolCallAnalysis) {
+	if c.EnableToolUseShim {
+		c.currChatContent = append(c.currChatContent, fmt.Sprintf("User declined to run %q.", call.FunctionCall.Name))
+		return
+	}
+	c.currChatContent = append(c.currChatContent, gollm.FunctionCallResult{
+		ID:   call.FunctionCall.ID,
+		Name: call.FunctionCall.Name,
+		Result: map[string]any{
+			"error":     "User declined to run this operation.",
+			"status":    "declined",
+			"retryable": false,
+		},
+	})
+}
+
 // generateFromTemplate generates a prompt for LLM. It uses the prompt from the provides template file or default.
 func (a *Agent) generatePrompt(_ context.Context, defaultPromptTemplate string, data PromptData) (string, error) {
 	promptTemplate := defaultPromptTemplate
@@ -1196,6 +1908,11 @@ type PromptData struct {
 
 	EnableToolUseShim    bool
 	SessionIsInteractive bool
+
+	// Persona describes the character and tone of the assistant, if configured.
+	Persona string
+	// Language is the name of the language to respond in, if configured.
+	Language string
 }
 
 func (a *PromptData) ToolsAsJSON() string {
@@ -1242,14 +1959,97 @@ func extractJSON(s string) (string, bool) {
 	return data, true
 }
 
-// parseReActResponse parses the LLM response into a ReActResponse struct
-// This function assumes the input contains exactly one JSON code block
-// formatted with ```json and ``` markers. The JSON block is expected to
-// contain a valid ReActResponse object.
+// reActResponseSchema is the schema of ReActResponse, to constrain the responses of
+// providers with a JSON mode.
+var reActResponseSchema = &gollm.Schema{
+	Type: gollm.TypeObject,
+	Properties: map[string]*gollm.Schema{
+		"thought": {Type: gollm.TypeString, Description: "Your reasoning about what to do next"},
+		"answer":  {Type: gollm.TypeString, Description: "Your answer to the query, when you have enough information"},
+		"action": {
+			Type:        gollm.TypeObject,
+			Description: "The tool to use, when you need one",
+			Properties: map[string]*gollm.Schema{
+				"name":              {Type: gollm.TypeString},
+				"reason":            {Type: gollm.TypeString},
+				"command":           {Type: gollm.TypeString},
+				"modifies_resource": {Type: gollm.TypeString},
+			},
+			Required: []string{"name", "command"},
+		},
+	},
+	Required: []string{"thought"},
+}
+
+// reActResponseSchemaWithReason is reActResponseSchema requiring the reason of actions.
+func reActResponseSchemaWithReason() *gollm.Schema {
+	schema := *reActResponseSchema
+	schema.Properties = maps.Clone(schema.Properties)
+	action := *schema.Properties["action"]
+	action.Required = append(slices.Clone(action.Required), "reason")
+	schema.Properties["action"] = &action
+	return &schema
+}
+
+// useReActResponseSchema constrains the responses of the LLM to ReActResponse until the
+// returned function is called. Chats keep the schema they were started with. Providers
+// without a JSON mode rely on the instructions of the prompt instead.
+func (c *Agent) useReActResponseSchema() (restore func()) {
+	schema := reActResponseSchema
+	if c.RequireJustification {
+		schema = reActResponseSchemaWithReason()
+	}
+	if err := c.LLM.SetResponseSchema(schema); err != nil {
+		klog.V(2).Infof("LLM has no JSON mode, relying on the prompt for ReAct responses: %v", err)
+		return func() {}
+	}
+	return func() {
+		if err := c.LLM.SetResponseSchema(nil); err != nil {
+			klog.Warningf("clearing the response schema of the LLM: %v", err)
+		}
+	}
+}
+
+// reActRepairPrompt asks to rewrite a response that does not parse as a ReActResponse.
+const reActRepairPrompt = `Rewrite the following response of an assistant as a single JSON object with
+a "thought" string, and either an "answer" string or an "action" object with "name",
+"reason", "command" and "modifies_resource" strings. Keep the content of the response,
+and reply with the JSON object only.
+
+Response:
+%s`
+
+// repairReActResponse asks the LLM to rewrite a response that does not parse, so that a
+// badly formatted response does not fail the iteration.
+func (c *Agent) repairReActResponse(ctx context.Context, response string) (*ReActResponse, error) {
+	model := c.ShimRepairModel
+	if model == "" {
+		model = c.Model
+	}
+	restore := c.useReActResponseSchema()
+	defer restore()
+	resp, err := c.LLM.GenerateCompletion(ctx, &gollm.CompletionRequest{
+		Model:  model,
+		Prompt: fmt.Sprintf(reActRepairPrompt, response),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("asking %s to repair the response: %w", model, err)
+	}
+	return parseReActResponse(resp.Response())
+}
+
+// parseReActResponse parses the LLM response into a ReActResponse struct.
+// The response is expected to contain a ReActResponse object, in a JSON code block
+// formatted with ```json and ``` markers, or bare as responses constrained by a
+// response schema are.
 func parseReActResponse(input string) (*ReActResponse, error) {
 	cleaned, found := extractJSON(input)
 	if !found {
-		return nil, fmt.Errorf("no JSON code block found in %q", cleaned)
+		start, end := strings.Index(input, "{"), strings.LastIndex(input, "}")
+		if start == -1 || end < start {
+			return nil, fmt.Errorf("no JSON found in %q", input)
+		}
+		cleaned = input[start : end+1]
 	}
 
 	cleaned = strings.ReplaceAll(cleaned, "\n", "")
@@ -1275,7 +2075,9 @@ func toMap(v any) (map[string]any, error) {
 	return m, nil
 }
 
-func candidateToShimCandidate(iterator gollm.ChatResponseIterator) (gollm.ChatResponseIterator, error) {
+// candidateToShimCandidate converts the text responses of the LLM into ReAct responses,
+// asking the LLM to repair those that do not parse.
+func (c *Agent) candidateToShimCandidate(ctx context.Context, iterator gollm.ChatResponseIterator) (gollm.ChatResponseIterator, error) {
 	return func(yield func(gollm.ChatResponse, error) bool) {
 		buffer := ""
 		for response, err := range iterator {
@@ -1309,8 +2111,13 @@ func candidateToShimCandidate(iterator gollm.ChatResponseIterator) (gollm.ChatRe
 
 		parsedReActResp, err := parseReActResponse(buffer)
 		if err != nil {
-			yield(nil, fmt.Errorf("parsing ReAct response %q: %w", buffer, err))
-			return
+			klog.Warningf("ReAct response does not parse, asking the LLM to repair it: %v", err)
+			repaired, repairErr := c.repairReActResponse(ctx, buffer)
+			if repairErr != nil {
+				yield(nil, fmt.Errorf("parsing ReAct response %q: %w (repair failed: %v)", buffer, err, repairErr))
+				return
+			}
+			parsedReActResp = repaired
 		}
 		buffer = "" // TODO: any trailing text?
 		yield(&ShimResponse{candidate: parsedReActResp}, nil)
@@ -16,7 +16,10 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -24,8 +27,15 @@ import (
 	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
 	"github.com/GoogleCloudPlatform/kubectl-ai/internal/mocks"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/gitops"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/mcp"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
 	"go.uber.org/mock/gomock"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 func TestHandleMetaQuery(t *testing.T) {
@@ -412,3 +422,550 @@ func TestAgent_NewSession_NoDeadlock(t *testing.T) {
 		t.Fatal("NewSession timed out (potential deadlock)")
 	}
 }
+
+func TestHandleChoice_Selections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mt := mocks.NewMockTool(ctrl)
+	mt.EXPECT().Name().Return("kubectl").AnyTimes()
+	var toolset tools.Tools
+	toolset.Init()
+	toolset.RegisterTool(mt)
+
+	pending := func(t *testing.T, commands ...string) []ToolCallAnalysis {
+		var calls []ToolCallAnalysis
+		for i, command := range commands {
+			args := map[string]any{"command": command}
+			parsed, err := toolset.ParseToolInvocation(context.Background(), "kubectl", args)
+			if err != nil {
+				t.Fatalf("parsing tool call: %v", err)
+			}
+			calls = append(calls, ToolCallAnalysis{
+				FunctionCall:   gollm.FunctionCall{ID: string(rune('a' + i)), Name: "kubectl", Arguments: args},
+				ParsedToolCall: parsed,
+			})
+		}
+		return calls
+	}
+
+	tests := []struct {
+		name         string
+		choice       *api.UserChoiceResponse
+		wantDispatch bool
+		wantPending  []string
+		wantDeclined []string
+		wantDecision string
+		// wantSkipPermissions is whether the user is not asked again
+		wantSkipPermissions bool
+		// wantErrors are the categories of the error messages shown to the user
+		wantErrors []api.ErrorCategory
+	}{
+		{
+			name:         "approve all",
+			choice:       &api.UserChoiceResponse{Choice: 1},
+			wantDispatch: true,
+			wantPending:  []string{"a", "b"},
+			wantDecision: PermissionDecisionApproved,
+		},
+		{
+			name:         "approve some",
+			choice:       &api.UserChoiceResponse{Choice: 1, Selections: []bool{false, true}},
+			wantDispatch: true,
+			wantPending:  []string{"b"},
+			wantDeclined: []string{"a"},
+			wantDecision: PermissionDecisionApproved,
+			wantErrors:   []api.ErrorCategory{api.ErrorCategoryCancelled},
+		},
+		{
+			name:         "approve none",
+			choice:       &api.UserChoiceResponse{Choice: 1, Selections: []bool{false, false}},
+			wantDeclined: []string{"a", "b"},
+			wantDecision: PermissionDecisionApproved,
+			wantErrors:   []api.ErrorCategory{api.ErrorCategoryCancelled, api.ErrorCategoryCancelled},
+		},
+		{
+			name:                "approve all and don't ask again",
+			choice:              &api.UserChoiceResponse{Choice: 2, Selections: []bool{true, true}},
+			wantDispatch:        true,
+			wantPending:         []string{"a", "b"},
+			wantDecision:        PermissionDecisionApprovedAlways,
+			wantSkipPermissions: true,
+		},
+		{
+			name:         "approve some and don't ask again",
+			choice:       &api.UserChoiceResponse{Choice: 2, Selections: []bool{true, false}},
+			wantDispatch: true,
+			wantPending:  []string{"a"},
+			wantDeclined: []string{"b"},
+			wantDecision: PermissionDecisionApproved,
+			wantErrors:   []api.ErrorCategory{api.ErrorCategoryCancelled},
+		},
+		{
+			name:         "selections do not match",
+			choice:       &api.UserChoiceResponse{Choice: 1, Selections: []bool{true}},
+			wantDeclined: []string{"a", "b"},
+			wantDecision: PermissionDecisionInvalid,
+			wantErrors:   []api.ErrorCategory{api.ErrorCategoryUnknown},
+		},
+		{
+			name:         "decline",
+			choice:       &api.UserChoiceResponse{Choice: 3, Selections: []bool{true, true}},
+			wantDeclined: []string{"a", "b"},
+			wantDecision: PermissionDecisionDeclined,
+			wantErrors:   []api.ErrorCategory{api.ErrorCategoryCancelled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &captureRecorder{}
+			a := &Agent{
+				Output:   make(chan any, 10),
+				Recorder: recorder,
+				Session: &api.Session{
+					ChatMessageStore: sessions.NewInMemoryChatStore(),
+				},
+			}
+			a.pendingFunctionCalls = pending(t, "kubectl delete pod a", "kubectl delete pod b")
+
+			if got := a.handleChoice(context.Background(), tt.choice); got != tt.wantDispatch {
+				t.Errorf("handleChoice() = %v, want %v", got, tt.wantDispatch)
+			}
+			if a.SkipPermissions != tt.wantSkipPermissions {
+				t.Errorf("SkipPermissions = %v, want %v", a.SkipPermissions, tt.wantSkipPermissions)
+			}
+
+			var gotPending []string
+			for _, call := range a.pendingFunctionCalls {
+				gotPending = append(gotPending, call.FunctionCall.ID)
+			}
+			if strings.Join(gotPending, ",") != strings.Join(tt.wantPending, ",") {
+				t.Errorf("pending calls = %v, want %v", gotPending, tt.wantPending)
+			}
+
+			var gotDeclined []string
+			for _, content := range a.currChatContent {
+				if result, ok := content.(gollm.FunctionCallResult); ok && result.Result["status"] == "declined" {
+					gotDeclined = append(gotDeclined, result.ID)
+				}
+			}
+			if strings.Join(gotDeclined, ",") != strings.Join(tt.wantDeclined, ",") {
+				t.Errorf("declined calls = %v, want %v", gotDeclined, tt.wantDeclined)
+			}
+
+			var gotErrors []api.ErrorCategory
+			for _, message := range a.Session.AllMessages() {
+				if message.Type == api.MessageTypeError {
+					gotErrors = append(gotErrors, api.ErrorPayloadFrom(message.Payload).Category)
+				}
+			}
+			if !slices.Equal(gotErrors, tt.wantErrors) {
+				t.Errorf("error categories = %v, want %v", gotErrors, tt.wantErrors)
+			}
+
+			// The journal records which commands the user approved
+			var decision *PermissionDecisionEvent
+			for _, event := range recorder.events {
+				if event.Action == journal.ActionPermissionDecision {
+					payload := event.Payload.(PermissionDecisionEvent)
+					decision = &payload
+				}
+			}
+			if decision == nil {
+				t.Fatalf("no permission decision recorded")
+			}
+			if decision.Decision != tt.wantDecision || decision.AutoApproved {
+				t.Errorf("recorded decision = %q (auto-approved %v), want %q", decision.Decision, decision.AutoApproved, tt.wantDecision)
+			}
+			var gotApproved []string
+			for i, command := range decision.Commands {
+				if command.Approved {
+					gotApproved = append(gotApproved, string(rune('a'+i)))
+				}
+			}
+			if strings.Join(gotApproved, ",") != strings.Join(tt.wantPending, ",") {
+				t.Errorf("recorded approved commands = %v, want %v", gotApproved, tt.wantPending)
+			}
+		})
+	}
+}
+
+// captureRecorder keeps the journal events written to it.
+type captureRecorder struct {
+	events []*journal.Event
+}
+
+func (r *captureRecorder) Write(ctx context.Context, event *journal.Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *captureRecorder) Close() error {
+	return nil
+}
+
+func TestAnalyzeToolCalls_MCPTrust(t *testing.T) {
+	var toolset tools.Tools
+	toolset.Init()
+	register := func(server, tool string, opts ...tools.MCPToolOption) string {
+		mcpTool := tools.NewMCPTool(server, tool, "", &gollm.FunctionDefinition{Name: tool}, nil, opts...)
+		toolset.RegisterTool(mcpTool)
+		return mcpTool.UniqueToolName()
+	}
+
+	tests := []struct {
+		name          string
+		tool          string
+		wantModifies  string
+		wantAlwaysAsk bool
+		wantRefused   bool
+		// allowedNamespaces restricts the namespaces of the agent
+		allowedNamespaces []string
+	}{
+		{
+			name:         "default",
+			tool:         register("docs", "search"),
+			wantModifies: "unknown",
+		},
+		{
+			name:         "trusted",
+			tool:         register("inventory", "list", tools.WithTrustLevel(mcp.TrustTrusted)),
+			wantModifies: "no",
+		},
+		{
+			name:          "ask-always",
+			tool:          register("tickets", "create", tools.WithTrustLevel(mcp.TrustAskAlways)),
+			wantModifies:  "unknown",
+			wantAlwaysAsk: true,
+		},
+		{
+			name:         "read-only server, read-only tool",
+			tool:         register("metrics", "query", tools.WithTrustLevel(mcp.TrustReadOnly), tools.WithReadOnly(true)),
+			wantModifies: "no",
+		},
+		{
+			name:         "read-only server, other tool",
+			tool:         register("metrics", "delete", tools.WithTrustLevel(mcp.TrustReadOnly)),
+			wantModifies: "unknown",
+			wantRefused:  true,
+		},
+		{
+			name:              "trusted server, namespaces restricted",
+			tool:              register("inventory", "delete", tools.WithTrustLevel(mcp.TrustTrusted)),
+			wantModifies:      "no",
+			wantRefused:       true,
+			allowedNamespaces: []string{"dev"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Agent{Tools: toolset, AllowedNamespaces: tt.allowedNamespaces}
+			results, err := a.analyzeToolCalls(context.Background(), []gollm.FunctionCall{{Name: tt.tool, Arguments: map[string]any{}}})
+			if err != nil {
+				t.Fatalf("analyzeToolCalls() error = %v", err)
+			}
+			got := results[0]
+			if got.ModifiesResourceStr != tt.wantModifies {
+				t.Errorf("ModifiesResourceStr = %q, want %q", got.ModifiesResourceStr, tt.wantModifies)
+			}
+			if got.AlwaysAsk != tt.wantAlwaysAsk {
+				t.Errorf("AlwaysAsk = %v, want %v", got.AlwaysAsk, tt.wantAlwaysAsk)
+			}
+			if refused := got.blockedError() != nil; refused != tt.wantRefused {
+				t.Errorf("refused = %v, want %v (error: %v)", refused, tt.wantRefused, got.blockedError())
+			}
+		})
+	}
+}
+
+func TestAnalyzeToolCallsRestrictions(t *testing.T) {
+	var toolset tools.Tools
+	toolset.Init()
+	toolset.RegisterTool(tools.NewKubectlTool(nil))
+	toolset.RegisterTool(tools.NewBashTool(nil))
+	toolset.RegisterTool(tools.NewRolloutTool(nil))
+	toolset.RegisterTool(tools.NewProposeChangeTool(nil))
+
+	tests := []struct {
+		name    string
+		agent   *Agent
+		command string
+		// tool and args make a call of another tool than kubectl
+		tool        string
+		args        map[string]any
+		wantRefused bool
+	}{
+		{name: "gitops read", agent: &Agent{GitOps: &gitops.Proposer{}}, command: "kubectl get pods"},
+		{name: "gitops write", agent: &Agent{GitOps: &gitops.Proposer{}}, command: "kubectl scale deployment web --replicas 3", wantRefused: true},
+		{name: "gitops rollout status", agent: &Agent{GitOps: &gitops.Proposer{}}, tool: "rollout", args: map[string]any{"operation": "status", "name": "web", "namespace": "dev"}},
+		{name: "gitops rollout undo", agent: &Agent{GitOps: &gitops.Proposer{}}, tool: "rollout", args: map[string]any{"operation": "undo", "name": "web", "namespace": "dev"}, wantRefused: true},
+		{name: "gitops proposal", agent: &Agent{GitOps: &gitops.Proposer{}}, tool: "propose_change", args: map[string]any{"title": "Scale web"}},
+		{name: "read-only read", agent: &Agent{ReadOnly: true}, command: "kubectl get pods"},
+		{name: "read-only write", agent: &Agent{ReadOnly: true}, command: "kubectl delete pod web-0", wantRefused: true},
+		{name: "allowed namespace", agent: &Agent{AllowedNamespaces: []string{"dev"}}, command: "kubectl get pods -n dev"},
+		{name: "other namespace", agent: &Agent{AllowedNamespaces: []string{"dev"}}, command: "kubectl get pods -n prod", wantRefused: true},
+		{name: "contexts current", agent: &Agent{KubeContexts: []tools.KubeContext{{Name: "dev"}}}, command: "kubectl delete pod web-0"},
+		{name: "contexts other context", agent: &Agent{KubeContexts: []tools.KubeContext{{Name: "dev"}}}, command: "kubectl --context prod delete pod web-0", wantRefused: true},
+		{name: "contexts other kubeconfig", agent: &Agent{KubeContexts: []tools.KubeContext{{Name: "dev"}}}, command: "kubectl delete pod web-0 --kubeconfig /tmp/prod", wantRefused: true},
+		{name: "contexts switch", agent: &Agent{KubeContexts: []tools.KubeContext{{Name: "dev"}}}, command: "kubectl config use-context prod", wantRefused: true},
+		{name: "contexts bash", agent: &Agent{KubeContexts: []tools.KubeContext{{Name: "dev"}}}, tool: "bash", args: map[string]any{"command": "KUBECONFIG=/tmp/prod kubectl delete pod web-0"}, wantRefused: true},
+		{name: "multi-user kubectl", agent: &Agent{MultiUser: true}, command: "kubectl get pods -n dev"},
+		{name: "multi-user other kubeconfig", agent: &Agent{MultiUser: true}, command: "kubectl --kubeconfig /srv/kubeconfigs/bob/config get pods", wantRefused: true},
+		{name: "multi-user other context", agent: &Agent{MultiUser: true}, command: "kubectl get pods --context bob", wantRefused: true},
+		{name: "multi-user shell", agent: &Agent{MultiUser: true}, command: "cat /srv/kubeconfigs/bob/config", wantRefused: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.agent.Tools = toolset
+			call := gollm.FunctionCall{Name: "kubectl", Arguments: map[string]any{"command": tt.command}}
+			if tt.tool != "" {
+				call = gollm.FunctionCall{Name: tt.tool, Arguments: tt.args}
+			}
+			results, err := tt.agent.analyzeToolCalls(context.Background(), []gollm.FunctionCall{call})
+			if err != nil {
+				t.Fatalf("analyzeToolCalls() error = %v", err)
+			}
+			if refused := results[0].blockedError() != nil; refused != tt.wantRefused {
+				t.Errorf("refused = %v, want %v (error: %v)", refused, tt.wantRefused, results[0].blockedError())
+			}
+		})
+	}
+}
+
+type fakeCompletion string
+
+func (r fakeCompletion) Response() string   { return string(r) }
+func (r fakeCompletion) UsageMetadata() any { return nil }
+
+func TestParseReActResponse(t *testing.T) {
+	for _, input := range []string{
+		"```json\n{\"thought\": \"done\", \"answer\": \"All pods are running.\"}\n```",
+		`{"thought": "done", "answer": "All pods are running."}`,
+		"Here you go: {\"thought\": \"done\", \"answer\": \"All pods are running.\"}",
+	} {
+		got, err := parseReActResponse(input)
+		if err != nil {
+			t.Fatalf("parseReActResponse(%q) error = %v", input, err)
+		}
+		if got.Answer != "All pods are running." {
+			t.Errorf("parseReActResponse(%q) answer = %q", input, got.Answer)
+		}
+	}
+	if _, err := parseReActResponse("All pods are running."); err == nil {
+		t.Errorf("parseReActResponse() of text without JSON succeeded")
+	}
+}
+
+func TestShimRepairsUnparsableResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+	client.EXPECT().SetResponseSchema(gomock.Any()).Return(nil).AnyTimes()
+	client.EXPECT().GenerateCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *gollm.CompletionRequest) (gollm.CompletionResponse, error) {
+			if req.Model != "small-model" || !strings.Contains(req.Prompt, "Thought: list the pods") {
+				t.Errorf("repair request = %+v", req)
+			}
+			return fakeCompletion(`{"thought": "list the pods", "action": {"name": "kubectl", "command": "kubectl get pods"}}`), nil
+		})
+
+	a := &Agent{LLM: client, Model: "model", ShimRepairModel: "small-model"}
+	stream, err := a.candidateToShimCandidate(context.Background(), func(yield func(gollm.ChatResponse, error) bool) {
+		yield(chatWith(fText("Thought: list the pods\nAction: kubectl get pods")), nil)
+	})
+	if err != nil {
+		t.Fatalf("candidateToShimCandidate() error = %v", err)
+	}
+	var calls []gollm.FunctionCall
+	for resp, err := range stream {
+		if err != nil {
+			t.Fatalf("stream error = %v", err)
+		}
+		for _, part := range resp.Candidates()[0].Parts() {
+			if c, ok := part.AsFunctionCalls(); ok {
+				calls = append(calls, c...)
+			}
+		}
+	}
+	if len(calls) != 1 || calls[0].Arguments["command"] != "kubectl get pods" {
+		t.Errorf("function calls = %+v, want kubectl get pods", calls)
+	}
+}
+
+func TestAnalyzeToolCallsRequireJustification(t *testing.T) {
+	var toolset tools.Tools
+	toolset.Init()
+	toolset.RegisterTool(tools.NewKubectlTool(nil))
+	a := &Agent{Tools: toolset, RequireJustification: true}
+
+	results, err := a.analyzeToolCalls(context.Background(), []gollm.FunctionCall{
+		{Name: "kubectl", Arguments: map[string]any{"command": "kubectl get pods", "justification": "  Find the crashing pod. "}},
+		{Name: "kubectl", Arguments: map[string]any{"command": "kubectl get nodes"}},
+	})
+	if err != nil {
+		t.Fatalf("analyzeToolCalls() error = %v", err)
+	}
+	if results[0].Justification != "Find the crashing pod." || results[0].blockedError() != nil {
+		t.Errorf("justified call = %q, %v, want it allowed with its justification", results[0].Justification, results[0].blockedError())
+	}
+	if _, ok := results[0].FunctionCall.Arguments["justification"]; ok {
+		t.Errorf("the justification is passed to the tool: %v", results[0].FunctionCall.Arguments)
+	}
+	if !errors.Is(results[1].blockedError(), errMissingJustification) {
+		t.Errorf("call without justification error = %v, want it refused", results[1].blockedError())
+	}
+
+	definition := withJustification(tools.NewKubectlTool(nil).FunctionDefinition())
+	if definition.Parameters.Properties["justification"] == nil || !slices.Contains(definition.Parameters.Required, "justification") {
+		t.Errorf("withJustification() = %+v, want a required justification parameter", definition.Parameters)
+	}
+	if original := tools.NewKubectlTool(nil).FunctionDefinition(); original.Parameters.Properties["justification"] != nil {
+		t.Errorf("withJustification() modified the definition of the tool")
+	}
+}
+
+func TestUseContext(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	config := clientcmdapi.NewConfig()
+	config.Clusters["cluster"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+	for _, name := range []string{"dev", "prod-eu", "other"} {
+		config.Contexts[name] = &clientcmdapi.Context{Cluster: "cluster"}
+	}
+	config.CurrentContext = "dev"
+	if err := clientcmd.WriteToFile(*config, kubeconfig); err != nil {
+		t.Fatalf("writing kubeconfig: %v", err)
+	}
+
+	a := &Agent{
+		Kubeconfig:   kubeconfig,
+		KubeContexts: []tools.KubeContext{{Name: "dev"}, {Name: "prod-*", Labels: []string{tools.LabelProduction}}},
+		Session:      &api.Session{ChatMessageStore: sessions.NewInMemoryChatStore()},
+		Output:       make(chan any, 10),
+		workDir:      dir,
+	}
+	a.Tools.Init()
+	if err := a.registerUseContext(); err != nil {
+		t.Fatalf("registerUseContext() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		context       string
+		wantAlwaysAsk bool
+	}{
+		{context: "dev"},
+		{context: "prod-eu", wantAlwaysAsk: true},
+	} {
+		results, err := a.analyzeToolCalls(context.Background(), []gollm.FunctionCall{{Name: "use_context", Arguments: map[string]any{"context": tt.context}}})
+		if err != nil {
+			t.Fatalf("analyzeToolCalls() error = %v", err)
+		}
+		if results[0].AlwaysAsk != tt.wantAlwaysAsk {
+			t.Errorf("switching to %s: AlwaysAsk = %v, want %v", tt.context, results[0].AlwaysAsk, tt.wantAlwaysAsk)
+		}
+	}
+
+	tool := a.Tools.Lookup("use_context")
+	if result, _ := tool.Run(context.Background(), map[string]any{"context": "other"}); result.(map[string]any)["error"] == nil {
+		t.Errorf("switching to a context that is not allowed succeeded")
+	}
+	if _, err := tool.Run(context.Background(), map[string]any{"context": "prod-eu"}); err != nil {
+		t.Fatalf("switching context: %v", err)
+	}
+	switched, err := clientcmd.LoadFromFile(a.Kubeconfig)
+	if err != nil {
+		t.Fatalf("loading switched kubeconfig: %v", err)
+	}
+	if switched.CurrentContext != "prod-eu" {
+		t.Errorf("current context = %q, want prod-eu", switched.CurrentContext)
+	}
+	if original, _ := clientcmd.LoadFromFile(kubeconfig); original.CurrentContext != "dev" {
+		t.Errorf("the original kubeconfig was modified")
+	}
+	if messages := a.Session.ChatMessageStore.ChatMessages(); len(messages) != 1 || !strings.Contains(messages[0].Payload.(string), "prod-eu") {
+		t.Errorf("the switch was not announced, messages: %v", messages)
+	}
+}
+
+func TestRunTool(t *testing.T) {
+	executor := sandbox.NewLocalExecutor()
+	a := &Agent{
+		Session:  &api.Session{ChatMessageStore: sessions.NewInMemoryChatStore()},
+		Output:   make(chan any, 10),
+		executor: executor,
+		workDir:  t.TempDir(),
+	}
+	a.Tools.Init()
+	a.Tools.RegisterTool(tools.NewBashTool(executor))
+	a.Tools.RegisterTool(tools.NewKubectlTool(executor))
+
+	answer, handled, err := a.handleMetaQuery(context.Background(), "/run echo hello")
+	if err != nil || !handled {
+		t.Fatalf("handleMetaQuery(/run echo hello) = %q, %v, %v", answer, handled, err)
+	}
+	if got := a.userRunsContext(); !strings.Contains(got, "echo hello\n```\nhello\n```") {
+		t.Errorf("userRunsContext() = %q, want the output of echo", got)
+	}
+	if got := a.userRunsContext(); got != "" {
+		t.Errorf("userRunsContext() = %q after it was sent, want none", got)
+	}
+
+	// The policies of the calls of the model apply
+	a.ReadOnly = true
+	answer, _, err = a.handleMetaQuery(context.Background(), "/run kubectl delete pod web")
+	if err != nil || !strings.HasPrefix(answer, "Cannot run") {
+		t.Errorf("running kubectl delete in read-only mode = %q, %v, want it refused", answer, err)
+	}
+	if answer, _, _ := a.handleMetaQuery(context.Background(), "/run kubectl {not json"); answer != runUsage {
+		t.Errorf("running a tool with invalid arguments = %q, want the usage", answer)
+	}
+	if _, handled, _ := a.handleMetaQuery(context.Background(), "run the diagnostics"); handled {
+		t.Errorf("a request starting with run was handled as /run")
+	}
+
+	// Users of shared servers cannot run commands on the server themselves
+	a.ReadOnly = false
+	a.MultiUser = true
+	answer, handled, err = a.handleMetaQuery(context.Background(), "/run kubectl get pods")
+	if err != nil || !handled || answer != "/run is not available on a shared server." {
+		t.Errorf("running kubectl get on a shared server = %q, %v, %v, want it refused", answer, handled, err)
+	}
+}
+
+func TestSuggestCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+	client.EXPECT().GenerateCompletion(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *gollm.CompletionRequest) (gollm.CompletionResponse, error) {
+			if req.Model != "small-model" || !strings.Contains(req.Prompt, "User: why is the web deployment in shop crashing?") {
+				t.Errorf("suggestion request = %+v", req)
+			}
+			return fakeCompletion("```bash\nkubectl logs deploy/web --namespace shop\n```"), nil
+		}).Times(2)
+
+	a := &Agent{
+		LLM:          client,
+		Model:        "model",
+		SuggestModel: "small-model",
+		Session:      &api.Session{ChatMessageStore: sessions.NewInMemoryChatStore()},
+		Output:       make(chan any, 10),
+	}
+	a.addMessage(api.MessageSourceUser, api.MessageTypeText, "why is the web deployment in shop crashing?")
+
+	for _, tc := range []struct{ partial, want string }{
+		{"kubectl logs de", "kubectl logs deploy/web --namespace shop"},
+		{"/run kubectl logs", "/run kubectl logs deploy/web --namespace shop"},
+		// Neither kubectl commands nor completed by the suggestion: no LLM call
+		{"why is it crashing", ""},
+		{"kubectl", ""},
+	} {
+		got, err := a.SuggestCommand(context.Background(), tc.partial)
+		if err != nil || got != tc.want {
+			t.Errorf("SuggestCommand(%q) = %q, %v, want %q", tc.partial, got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// ErrAgentBusy is returned when the agent cannot take an edit while processing a request.
+	ErrAgentBusy = errors.New("the agent is processing a request")
+	// ErrNotEditable is returned when editing a message that is not a text message of the user.
+	ErrNotEditable = errors.New("only messages of the user can be edited")
+)
+
+// EditMessage replaces the earlier message of the user with the given ID by query, and
+// runs the agent again from there: the messages from the edited one on are removed from the
+// session, after keeping the whole conversation in a fork of the session.
+// It fails with ErrAgentBusy while a request is in progress.
+func (c *Agent) EditMessage(messageID, query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("the edited message is empty")
+	}
+	if state := c.AgentState(); state != api.AgentStateIdle && state != api.AgentStateDone {
+		return ErrAgentBusy
+	}
+	if editableMessageIndex(c.Session.AllMessages(), messageID) < 0 {
+		return ErrNotEditable
+	}
+	c.Input <- &api.UserInputResponse{Query: query, EditMessageID: messageID}
+	return nil
+}
+
+// editableMessageIndex returns the index of the text message of the user with the ID, or -1.
+func editableMessageIndex(messages []*api.Message, messageID string) int {
+	for i, message := range messages {
+		if message.ID != messageID {
+			continue
+		}
+		if message.Source == api.MessageSourceUser && message.Type == api.MessageTypeText {
+			return i
+		}
+		return -1
+	}
+	return -1
+}
+
+// rewindToMessage truncates the conversation before the message being edited, keeping it in
+// a new session named after the current one first.
+func (c *Agent) rewindToMessage(messageID string) error {
+	manager, err := sessions.NewSessionManager(c.SessionBackend)
+	if err != nil {
+		return fmt.Errorf("failed to create session manager: %w", err)
+	}
+
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	messages := c.Session.ChatMessageStore.ChatMessages()
+	i := editableMessageIndex(messages, messageID)
+	if i < 0 {
+		return ErrNotEditable
+	}
+
+	fork, err := manager.ForkSession(c.Session, c.Session.Name+" (before edit)")
+	if err != nil {
+		return fmt.Errorf("keeping the conversation before the edit: %w", err)
+	}
+	klog.Infof("Kept the conversation of session %s before the edit in session %s", c.Session.ID, fork.ID)
+
+	if err := c.Session.ChatMessageStore.SetChatMessages(messages[:i]); err != nil {
+		return fmt.Errorf("truncating the conversation: %w", err)
+	}
+	c.Session.Messages = messages[:i]
+	c.Session.LastModified = time.Now()
+	if c.llmChat != nil {
+		if err := c.llmChat.Initialize(modelHistory(messages[:i])); err != nil {
+			return fmt.Errorf("resetting the chat: %w", err)
+		}
+	}
+	// The MCP resources are sent again with the next query
+	c.mcpResources = nil
+
+	c.emit(&api.Message{
+		ID:        uuid.New().String(),
+		Source:    api.MessageSourceAgent,
+		Type:      api.MessageTypeStatus,
+		Payload:   fmt.Sprintf("Edited the message; the conversation before the edit is kept in session %s", fork.ID),
+		Timestamp: time.Now(),
+	})
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+)
+
+func TestEditMessage(t *testing.T) {
+	store := sessions.NewInMemoryChatStore()
+	store.SetChatMessages([]*api.Message{
+		{ID: "q1", Source: api.MessageSourceUser, Type: api.MessageTypeText, Payload: "list pods"},
+		{ID: "a1", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "There are 3 pods."},
+		{ID: "q2", Source: api.MessageSourceUser, Type: api.MessageTypeText, Payload: "delete web-1"},
+		{ID: "a2", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "Deleted web-1."},
+	})
+	a := &Agent{
+		Session:        &api.Session{ID: "edited", Name: "Pods", AgentState: api.AgentStateDone, ChatMessageStore: store},
+		SessionBackend: "memory",
+		Input:          make(chan any, 1),
+		Output:         make(chan any, 10),
+	}
+
+	if err := a.EditMessage("a1", "hi"); !errors.Is(err, ErrNotEditable) {
+		t.Errorf("editing an answer: got %v, want ErrNotEditable", err)
+	}
+	if err := a.EditMessage("q2", "delete web-2"); err != nil {
+		t.Fatalf("editing a message: %v", err)
+	}
+	input := (<-a.Input).(*api.UserInputResponse)
+	if input.EditMessageID != "q2" || input.Query != "delete web-2" {
+		t.Errorf("input = %+v, want the edit of q2", input)
+	}
+
+	if err := a.rewindToMessage("q2"); err != nil {
+		t.Fatalf("rewinding: %v", err)
+	}
+	if got := len(store.ChatMessages()); got != 2 {
+		t.Errorf("got %d messages after the rewind, want the 2 before the edited one", got)
+	}
+
+	status := (<-a.Output).(*api.Message)
+	forkID := status.Payload.(string)[strings.LastIndex(status.Payload.(string), " ")+1:]
+	sm, err := sessions.NewSessionManager("memory")
+	if err != nil {
+		t.Fatalf("creating session manager: %v", err)
+	}
+	fork, err := sm.FindSessionByID(forkID)
+	if err != nil {
+		t.Fatalf("finding the fork %q: %v", forkID, err)
+	}
+	if got := len(fork.AllMessages()); got != 4 {
+		t.Errorf("fork has %d messages, want the 4 of the original conversation", got)
+	}
+	if fork.Name != "Pods (before edit)" {
+		t.Errorf("fork name = %q", fork.Name)
+	}
+
+	a.Session.AgentState = api.AgentStateRunning
+	if err := a.EditMessage("q1", "list nodes"); !errors.Is(err, ErrAgentBusy) {
+		t.Errorf("editing while running: got %v, want ErrAgentBusy", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+// errorPatterns maps categories to substrings of the (lower-cased) messages of their
+// errors, for errors that do not carry a status code. Categories are checked in order.
+var errorPatterns = []struct {
+	category api.ErrorCategory
+	patterns []string
+}{
+	{api.ErrorCategoryLLMQuota, []string{"resource_exhausted", "quota", "rate limit", "too many requests"}},
+	{api.ErrorCategoryLLMSafetyBlock, []string{"safety", "content_filter", "content filter", "prohibited_content", "blocklist"}},
+	{api.ErrorCategoryKubeconfigInvalid, []string{"kubeconfig", "invalid configuration", "no configuration has been provided", "context was not found"}},
+	{api.ErrorCategoryPermissionDenied, []string{"forbidden", "permission denied", "permission_denied", "unauthorized", "access denied"}},
+	{api.ErrorCategoryToolTimeout, []string{"deadline exceeded", "timed out", "timeout"}},
+}
+
+var errorGuidance = map[api.ErrorCategory]string{
+	api.ErrorCategoryLLMQuota:          "The LLM provider rejected the request because of quota or rate limits. Wait a moment and retry, or pick another model with --model.",
+	api.ErrorCategoryLLMSafetyBlock:    "The LLM provider blocked the response with its safety filters. Try rephrasing the request.",
+	api.ErrorCategoryToolTimeout:       "A command did not finish in time. Check that the cluster is reachable, or ask for a narrower command.",
+	api.ErrorCategoryPermissionDenied:  "The operation was refused. Check your RBAC permissions with `kubectl auth can-i`, or the API key of the LLM provider.",
+	api.ErrorCategoryKubeconfigInvalid: "Check the kubeconfig passed with --kubeconfig or KUBECONFIG, and its current context.",
+	api.ErrorCategoryPolicyRefused:     "The session's policy does not allow this operation. Ask for something the policy allows, or start a session with different flags.",
+}
+
+// ClassifyError returns the category of an error of the agentic loop.
+func ClassifyError(err error) api.ErrorCategory {
+	if err == nil {
+		return api.ErrorCategoryUnknown
+	}
+	if errors.Is(err, ErrRequestCancelled) || errors.Is(err, context.Canceled) {
+		return api.ErrorCategoryCancelled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return api.ErrorCategoryToolTimeout
+	}
+
+	var apiErr *gollm.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return api.ErrorCategoryLLMQuota
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return api.ErrorCategoryPermissionDenied
+		}
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, p := range errorPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(message, pattern) {
+				return p.category
+			}
+		}
+	}
+	return api.ErrorCategoryUnknown
+}
+
+func newErrorPayload(category api.ErrorCategory, message string) *api.ErrorPayload {
+	return &api.ErrorPayload{
+		Category: category,
+		Message:  message,
+		Guidance: errorGuidance[category],
+	}
+}
+
+// addErrorMessage reports err to the user, along with guidance for its category.
+func (c *Agent) addErrorMessage(err error) {
+	c.addMessage(api.MessageSourceAgent, api.MessageTypeError, newErrorPayload(ClassifyError(err), "Error: "+err.Error()))
+}
+
+// addErrorText reports an error that is not a Go error to the user.
+func (c *Agent) addErrorText(category api.ErrorCategory, text string) {
+	c.addMessage(api.MessageSourceAgent, api.MessageTypeError, newErrorPayload(category, text))
+}
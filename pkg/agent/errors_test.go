@@ -0,0 +1,46 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want api.ErrorCategory
+	}{
+		{&gollm.APIError{StatusCode: 429, Message: "slow down"}, api.ErrorCategoryLLMQuota},
+		{fmt.Errorf("streaming: %w", errors.New("Error 429, Status: RESOURCE_EXHAUSTED")), api.ErrorCategoryLLMQuota},
+		{errors.New("response blocked: finish reason SAFETY"), api.ErrorCategoryLLMSafetyBlock},
+		{fmt.Errorf("running tool: %w", context.DeadlineExceeded), api.ErrorCategoryToolTimeout},
+		{errors.New(`pods is forbidden: User "dev" cannot list resource "pods"`), api.ErrorCategoryPermissionDenied},
+		{errors.New("invalid configuration: no configuration has been provided"), api.ErrorCategoryKubeconfigInvalid},
+		{fmt.Errorf("waiting: %w", ErrRequestCancelled), api.ErrorCategoryCancelled},
+		{errors.New("something else"), api.ErrorCategoryUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
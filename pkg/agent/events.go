@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"k8s.io/klog/v2"
+)
+
+// eventsQueueSize bounds the events waiting for the UI to read them.
+const eventsQueueSize = 100
+
+// emitEvent reports a step of the current iteration on the Events channel and in the
+// journal. Events are dropped while the channel is full: they only describe the progress
+// of the agent, and UIs that do not show it need not read them.
+func (c *Agent) emitEvent(event *api.AgentEvent) {
+	event.Iteration = c.currIteration
+	event.Timestamp = time.Now()
+	c.recordEvent(&journal.Event{
+		Timestamp: event.Timestamp,
+		Action:    journal.ActionAgentEvent,
+		Payload:   event,
+	})
+	if c.Events == nil {
+		return
+	}
+	select {
+	case c.Events <- event:
+	default:
+		klog.V(2).Infof("dropping agent event %s, the UI is not reading them", event.Type)
+	}
+}
+
+// emitStepFinished reports the end of the LLM call or the tool calls started at start.
+func (c *Agent) emitStepFinished(eventType api.AgentEventType, start time.Time, tools []string, err error) {
+	event := &api.AgentEvent{
+		Type:     eventType,
+		Tools:    tools,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	c.emitEvent(event)
+}
+
+// describeToolCalls returns the descriptions of the tool calls, as shown to users.
+func describeToolCalls(calls []ToolCallAnalysis) []string {
+	descriptions := make([]string, len(calls))
+	for i, call := range calls {
+		descriptions[i] = call.ParsedToolCall.Description()
+	}
+	return descriptions
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+)
+
+// justificationParameter is added to the parameters of every tool with
+// RequireJustification. The tool use shim has the reason of its actions instead.
+const justificationParameter = "justification"
+
+var (
+	errMissingJustification = errors.New("the call has no justification: explain in one sentence why it is needed in the justification parameter")
+	// errMissingReason is errMissingJustification for the tool use shim.
+	errMissingReason = errors.New("the action has no reason: explain in one sentence why it is needed in the reason of the action")
+)
+
+// withJustification returns a copy of definition with the required justification parameter.
+func withJustification(definition *gollm.FunctionDefinition) *gollm.FunctionDefinition {
+	d := *definition
+	params := gollm.Schema{Type: gollm.TypeObject}
+	if d.Parameters != nil {
+		params = *d.Parameters
+	}
+	params.Properties = maps.Clone(params.Properties)
+	if params.Properties == nil {
+		params.Properties = map[string]*gollm.Schema{}
+	}
+	params.Properties[justificationParameter] = &gollm.Schema{
+		Type:        gollm.TypeString,
+		Description: "One sentence explaining why this call is needed, shown to the user with the call.",
+	}
+	params.Required = append(slices.Clone(params.Required), justificationParameter)
+	d.Parameters = &params
+	return &d
+}
+
+// splitJustification returns the justification of a call, and its arguments for the
+// tool. The justification parameter is not passed to tools; the reason of shim actions
+// always was.
+func (c *Agent) splitJustification(args map[string]any) (string, map[string]any) {
+	if c.EnableToolUseShim {
+		reason, _ := args["reason"].(string)
+		return strings.TrimSpace(reason), args
+	}
+	justification, _ := args[justificationParameter].(string)
+	if _, ok := args[justificationParameter]; ok {
+		args = maps.Clone(args)
+		delete(args, justificationParameter)
+	}
+	return strings.TrimSpace(justification), args
+}
+
+// missingJustificationError is why calls without justification are refused.
+func (c *Agent) missingJustificationError() error {
+	if c.EnableToolUseShim {
+		return errMissingReason
+	}
+	return errMissingJustification
+}
+
+// describeCalls lists the calls as commands in approval prompts, with their justification.
+func describeCalls(calls []ToolCallAnalysis) string {
+	var b strings.Builder
+	for _, call := range calls {
+		b.WriteString("\n* " + call.ParsedToolCall.Description())
+		if call.Justification != "" {
+			b.WriteString("\n  Justification: " + call.Justification)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+)
+
+// registerUseContext registers the use_context tool, switching among the contexts of
+// the kubeconfig allowed by KubeContexts.
+func (a *Agent) registerUseContext() error {
+	if a.Sandbox == "k8s" {
+		// The sandbox pod has a kubeconfig of its own, with its service account
+		klog.Warning("Switching kubeconfig contexts is not supported in the k8s sandbox, use_context is disabled")
+		return nil
+	}
+	a.kubeconfigSource = a.Kubeconfig
+	config, err := a.kubeconfigLoadingRules().Load()
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	var available []string
+	for name := range config.Contexts {
+		if _, ok := tools.MatchKubeContext(a.KubeContexts, name); ok {
+			available = append(available, name)
+		}
+	}
+	if len(available) == 0 {
+		klog.Warning("None of the contexts of the kubeconfig are allowed, use_context is disabled")
+		return nil
+	}
+	slices.Sort(available)
+	a.Tools.RegisterTool(tools.NewUseContextTool(a.KubeContexts, available, a.switchContext))
+	return nil
+}
+
+// kubeconfigLoadingRules loads the kubeconfig the agent was started with, as kubectl would.
+func (a *Agent) kubeconfigLoadingRules() *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if a.kubeconfigSource != "" {
+		path, err := tools.ExpandShellVar(a.kubeconfigSource)
+		if err != nil {
+			path = a.kubeconfigSource
+		}
+		rules.ExplicitPath = path
+	}
+	return rules
+}
+
+// switchContext switches the tools to the context name, with a copy of the kubeconfig in
+// the work directory: the kubeconfig of the user is not modified. The switch is shown to
+// the user.
+func (a *Agent) switchContext(ctx context.Context, name string) error {
+	config, err := a.kubeconfigLoadingRules().Load()
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	if _, ok := config.Contexts[name]; !ok {
+		return fmt.Errorf("context %q is not in the kubeconfig", name)
+	}
+	config.CurrentContext = name
+	path := filepath.Join(a.workDir, "kubeconfig")
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		return fmt.Errorf("writing kubeconfig: %w", err)
+	}
+	a.Kubeconfig = path
+	a.addMessage(api.MessageSourceAgent, api.MessageTypeText, fmt.Sprintf("Switched to the kubeconfig context %q.", name))
+	return nil
+}
+
+// RunKubectl runs kubectl with args for a UI, e.g. to show an object of the conversation,
+// the way the tools run it: with the executor of the agent, in its work directory, and
+// with the kubeconfig of the current context. namespace is added to the command, and is
+// refused if it is not one of AllowedNamespaces.
+func (a *Agent) RunKubectl(ctx context.Context, namespace string, args ...string) (string, error) {
+	if len(a.AllowedNamespaces) > 0 {
+		if err := tools.CheckNamespace(namespace, a.AllowedNamespaces); err != nil {
+			return "", err
+		}
+	}
+	if a.executor == nil {
+		return "", fmt.Errorf("the agent is not initialized")
+	}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	command := "kubectl"
+	for _, arg := range args {
+		command += " '" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+
+	env := os.Environ()
+	if a.Kubeconfig != "" {
+		kubeconfig, err := tools.ExpandShellVar(a.Kubeconfig)
+		if err != nil {
+			return "", err
+		}
+		env = append(env, "KUBECONFIG="+kubeconfig)
+	}
+	result, err := a.executor.Execute(ctx, command, env, a.workDir)
+	if err != nil {
+		return "", fmt.Errorf("running %s: %w", command, err)
+	}
+	output := strings.TrimRight(result.Stdout, "\n")
+	if result.Error != "" || result.ExitCode != 0 {
+		return output, fmt.Errorf("%s: %s", command, strings.TrimSpace(result.Error+"\n"+result.Stderr))
+	}
+	return output, nil
+}
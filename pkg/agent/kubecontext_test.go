@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+)
+
+// recordingExecutor records the commands it runs and their environment.
+type recordingExecutor struct {
+	command string
+	env     []string
+	workDir string
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, command string, env []string, workDir string) (*sandbox.ExecResult, error) {
+	e.command, e.env, e.workDir = command, env, workDir
+	return &sandbox.ExecResult{Command: command, Stdout: "kind: Pod\n"}, nil
+}
+
+func (e *recordingExecutor) Close(ctx context.Context) error { return nil }
+
+func TestRunKubectl(t *testing.T) {
+	executor := &recordingExecutor{}
+	a := &Agent{
+		executor:          executor,
+		workDir:           "/tmp/work",
+		Kubeconfig:        "/tmp/work/kubeconfig",
+		AllowedNamespaces: []string{"dev"},
+	}
+
+	output, err := a.RunKubectl(context.Background(), "dev", "get", "pod", "it's", "-o", "yaml")
+	if err != nil {
+		t.Fatalf("RunKubectl() error = %v", err)
+	}
+	if output != "kind: Pod" {
+		t.Errorf("RunKubectl() = %q", output)
+	}
+	if want := `kubectl 'get' 'pod' 'it'\''s' '-o' 'yaml' '--namespace' 'dev'`; executor.command != want {
+		t.Errorf("command = %q, want %q", executor.command, want)
+	}
+	if executor.workDir != a.workDir || !slices.Contains(executor.env, "KUBECONFIG=/tmp/work/kubeconfig") {
+		t.Errorf("kubectl ran in %q with env %v, want the work directory and kubeconfig of the agent", executor.workDir, executor.env)
+	}
+
+	executor.command = ""
+	if _, err := a.RunKubectl(context.Background(), "prod", "get", "secret", "db"); err == nil || executor.command != "" {
+		t.Errorf("RunKubectl() in a namespace that is not allowed ran %q, error = %v", executor.command, err)
+	}
+}
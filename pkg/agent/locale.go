@@ -0,0 +1,118 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"strings"
+)
+
+// locale holds the built-in messages of the agent in a language.
+type locale struct {
+	// Name is the name of the language in English, as told to the model.
+	Name          string
+	Greeting      string
+	Goodbye       string
+	Cleared       string
+	MaxIterations string
+}
+
+// locales are the languages of the built-in messages, by language tag.
+var locales = map[string]locale{
+	"en": {
+		Name:          "English",
+		Greeting:      "Hey there, what can I help you with today?",
+		Goodbye:       "It has been a pleasure assisting you. Have a great day!",
+		Cleared:       "Cleared the conversation.",
+		MaxIterations: "Maximum number of iterations reached.",
+	},
+	"de": {
+		Name:          "German",
+		Greeting:      "Hallo! Wobei kann ich dir heute helfen?",
+		Goodbye:       "Es war mir eine Freude, dir zu helfen. Einen schönen Tag noch!",
+		Cleared:       "Die Unterhaltung wurde gelöscht.",
+		MaxIterations: "Maximale Anzahl an Iterationen erreicht.",
+	},
+	"es": {
+		Name:          "Spanish",
+		Greeting:      "¡Hola! ¿En qué puedo ayudarte hoy?",
+		Goodbye:       "Ha sido un placer ayudarte. ¡Que tengas un gran día!",
+		Cleared:       "Se borró la conversación.",
+		MaxIterations: "Se alcanzó el número máximo de iteraciones.",
+	},
+	"fr": {
+		Name:          "French",
+		Greeting:      "Bonjour ! Comment puis-je vous aider aujourd'hui ?",
+		Goodbye:       "Ce fut un plaisir de vous aider. Bonne journée !",
+		Cleared:       "La conversation a été effacée.",
+		MaxIterations: "Nombre maximal d'itérations atteint.",
+	},
+	"ja": {
+		Name:          "Japanese",
+		Greeting:      "こんにちは！今日はどのようなご用件でしょうか？",
+		Goodbye:       "お手伝いできて光栄でした。良い一日を！",
+		Cleared:       "会話を消去しました。",
+		MaxIterations: "反復回数の上限に達しました。",
+	},
+	"pt": {
+		Name:          "Portuguese",
+		Greeting:      "Olá! Como posso ajudar você hoje?",
+		Goodbye:       "Foi um prazer ajudar você. Tenha um ótimo dia!",
+		Cleared:       "A conversa foi apagada.",
+		MaxIterations: "Número máximo de iterações atingido.",
+	},
+}
+
+// localeFor returns the built-in messages for a language tag such as "es" or "pt-BR", or a
+// language name such as "Spanish". Languages without translations get the English messages,
+// with the language as given as their name, so that the model still answers in it.
+func localeFor(language string) locale {
+	if language == "" {
+		return locales["en"]
+	}
+	tag, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(language, "_", "-")), "-")
+	if l, ok := locales[tag]; ok {
+		return l
+	}
+	for _, l := range locales {
+		if strings.EqualFold(l.Name, language) {
+			return l
+		}
+	}
+	l := locales["en"]
+	l.Name = language
+	return l
+}
+
+// locale returns the built-in messages in the language of the agent.
+func (c *Agent) locale() locale {
+	return localeFor(c.Language)
+}
+
+// greeting returns the message starting new sessions.
+func (c *Agent) greeting() string {
+	if c.Greeting != "" {
+		return c.Greeting
+	}
+	return c.locale().Greeting
+}
+
+// promptLanguage returns the name of the language the model is asked to respond in, or ""
+// to let it answer in the language of the user.
+func (c *Agent) promptLanguage() string {
+	if c.Language == "" {
+		return ""
+	}
+	return c.locale().Name
+}
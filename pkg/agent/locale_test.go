@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLocaleFor(t *testing.T) {
+	tests := []struct {
+		language     string
+		wantName     string
+		wantGreeting string
+	}{
+		{language: "", wantName: "English", wantGreeting: "Hey there, what can I help you with today?"},
+		{language: "pt-BR", wantName: "Portuguese", wantGreeting: "Olá! Como posso ajudar você hoje?"},
+		{language: "es_MX", wantName: "Spanish", wantGreeting: "¡Hola! ¿En qué puedo ayudarte hoy?"},
+		{language: "french", wantName: "French", wantGreeting: "Bonjour ! Comment puis-je vous aider aujourd'hui ?"},
+		// Without translations, the built-in messages stay in English
+		{language: "Dutch", wantName: "Dutch", wantGreeting: "Hey there, what can I help you with today?"},
+	}
+	for _, tt := range tests {
+		l := localeFor(tt.language)
+		if l.Name != tt.wantName || l.Greeting != tt.wantGreeting {
+			t.Errorf("localeFor(%q) = %q, %q, want %q, %q", tt.language, l.Name, l.Greeting, tt.wantName, tt.wantGreeting)
+		}
+	}
+
+	a := &Agent{Language: "de", Greeting: "Willkommen im Cluster-Support."}
+	if got := a.greeting(); got != a.Greeting {
+		t.Errorf("greeting() = %q, want the configured greeting", got)
+	}
+}
+
+func TestGeneratePromptPersonaAndLanguage(t *testing.T) {
+	a := &Agent{Persona: "You are a terse SRE.", Language: "ja"}
+	prompt, err := a.generatePrompt(context.Background(), defaultSystemPromptTemplate, PromptData{
+		Persona:  a.Persona,
+		Language: a.promptLanguage(),
+	})
+	if err != nil {
+		t.Fatalf("generatePrompt() error = %v", err)
+	}
+	for _, want := range []string{"## Persona\nYou are a terse SRE.", "Always respond in Japanese"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q", want)
+		}
+	}
+
+	prompt, err = (&Agent{}).generatePrompt(context.Background(), defaultSystemPromptTemplate, PromptData{})
+	if err != nil {
+		t.Fatalf("generatePrompt() error = %v", err)
+	}
+	if strings.Contains(prompt, "## Persona") || strings.Contains(prompt, "## Language") {
+		t.Errorf("prompt has persona or language sections without them configured")
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
@@ -31,7 +32,8 @@ type Factory func(context.Context) (*Agent, error)
 type AgentManager struct {
 	factory        Factory
 	sessionManager *sessions.SessionManager
-	agents         map[string]*Agent // sessionID -> agent
+	agents         map[string]*Agent    // sessionID -> agent
+	lastAccessed   map[string]time.Time // sessionID -> last time the agent was requested
 	mu             sync.RWMutex
 	onAgentCreated func(*Agent)
 }
@@ -42,6 +44,7 @@ func NewAgentManager(factory Factory, sessionManager *sessions.SessionManager) *
 		factory:        factory,
 		sessionManager: sessionManager,
 		agents:         make(map[string]*Agent),
+		lastAccessed:   make(map[string]time.Time),
 	}
 }
 
@@ -58,9 +61,12 @@ func (sm *AgentManager) SetAgentCreatedCallback(cb func(*Agent)) {
 
 // GetAgent returns the agent for the given session ID, loading it if necessary.
 func (sm *AgentManager) GetAgent(ctx context.Context, sessionID string) (*Agent, error) {
-	sm.mu.RLock()
+	sm.mu.Lock()
 	agent, ok := sm.agents[sessionID]
-	sm.mu.RUnlock()
+	if ok {
+		sm.lastAccessed[sessionID] = time.Now()
+	}
+	sm.mu.Unlock()
 
 	if ok {
 		return agent, nil
@@ -79,20 +85,118 @@ func (sm *AgentManager) GetAgent(ctx context.Context, sessionID string) (*Agent,
 	return sm.startAgent(ctx, session, newAgent)
 }
 
-// Close closes all active agents.
+// Close closes all active agents, waiting up to two minutes for them to shut down.
 func (sm *AgentManager) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return sm.Shutdown(ctx)
+}
+
+// Shutdown shuts all active agents down in parallel, for the process to exit: their
+// requests are cancelled, their sessions saved and their sandboxes torn down. It returns
+// once they are done, or with the error of ctx when it expires first.
+func (sm *AgentManager) Shutdown(ctx context.Context) error {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
+	agents := sm.agents
+	sm.agents = make(map[string]*Agent)
+	sm.lastAccessed = make(map[string]time.Time)
+	sm.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for id, agent := range agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			klog.Infof("Closing agent for session %s", id)
+			if err := agent.Shutdown(ctx); err != nil {
+				klog.Errorf("Error closing agent %s: %v", id, err)
+			}
+			if err := sm.saveSession(id); err != nil {
+				klog.Errorf("Error saving session %s: %v", id, err)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("shutting down agents: %w", ctx.Err())
+	}
+}
+
+// saveSession records the session as last accessed now. The stored session is updated
+// rather than the one of the agent, to keep changes made by other clients, e.g. renames.
+// A copy is updated, as stores may hand out the session still shared with the agent.
+func (sm *AgentManager) saveSession(id string) error {
+	session, err := sm.sessionManager.FindSessionByID(id)
+	if err != nil {
+		return err
+	}
+	saved := *session
+	return sm.sessionManager.UpdateLastAccessed(&saved)
+}
+
+// StartIdleEviction periodically closes agents that have not been requested
+// for longer than idleTimeout. Agents that are in the middle of a task or waiting
+// for the user to approve a tool call are never evicted. An evicted agent is
+// started again lazily on the next GetAgent call for its session.
+// The eviction loop stops when ctx is cancelled.
+func (sm *AgentManager) StartIdleEviction(ctx context.Context, idleTimeout time.Duration) {
+	if idleTimeout <= 0 {
+		return
+	}
+
+	interval := idleTimeout / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				sm.evictIdleAgents(now, idleTimeout)
+			}
+		}
+	}()
+}
+
+func (sm *AgentManager) evictIdleAgents(now time.Time, idleTimeout time.Duration) {
+	var evicted []*Agent
 
+	sm.mu.Lock()
 	for id, agent := range sm.agents {
-		klog.Infof("Closing agent for session %s", id)
+		if now.Sub(sm.lastAccessed[id]) < idleTimeout {
+			continue
+		}
+		switch agent.AgentState() {
+		case api.AgentStateRunning, api.AgentStateWaitingForInput, api.AgentStateInitializing:
+			// Never drop an agent that is busy or holding pending tool calls.
+			continue
+		}
+		delete(sm.agents, id)
+		delete(sm.lastAccessed, id)
+		evicted = append(evicted, agent)
+	}
+	sm.mu.Unlock()
+
+	// Closing an agent may block on executor cleanup, so do it outside the lock.
+	for _, agent := range evicted {
+		klog.Infof("Evicting idle agent for session %s", agent.Session.ID)
 		if err := agent.Close(); err != nil {
-			klog.Errorf("Error closing agent %s: %v", id, err)
+			klog.Errorf("Error closing idle agent %s: %v", agent.Session.ID, err)
 		}
 	}
-	// Clear the map
-	sm.agents = make(map[string]*Agent)
-	return nil
 }
 
 // ListSessions delegates to the underlying store.
@@ -111,6 +215,7 @@ func (sm *AgentManager) DeleteSession(id string) error {
 	if agent, ok := sm.agents[id]; ok {
 		agent.Close()
 		delete(sm.agents, id)
+		delete(sm.lastAccessed, id)
 	}
 	sm.mu.Unlock()
 	return sm.sessionManager.DeleteSession(id)
@@ -123,25 +228,45 @@ func (sm *AgentManager) UpdateLastAccessed(session *api.Session) error {
 
 func (sm *AgentManager) startAgent(ctx context.Context, session *api.Session, agent *Agent) (*Agent, error) {
 	agent.Session = session
+	if session.Kubeconfig != "" {
+		agent.Kubeconfig = session.Kubeconfig
+	}
 
 	if err := agent.Init(ctx); err != nil {
 		return nil, fmt.Errorf("initializing agent: %w", err)
 	}
 
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if existing, ok := sm.agents[session.ID]; ok {
+		// Another request started an agent for this session while we were initializing.
+		sm.lastAccessed[session.ID] = time.Now()
+		if err := agent.Close(); err != nil {
+			klog.Warningf("Error closing duplicate agent for session %s: %v", session.ID, err)
+		}
+		return existing, nil
+	}
+
 	agentCtx, cancel := context.WithCancel(context.Background())
 	agent.cancel = cancel
+	agent.done = agentCtx.Done()
+	agent.runningChanged = func(running bool) {
+		if err := sm.sessionManager.SetSessionRunning(session.ID, running); err != nil {
+			klog.Warningf("Error marking session %s as running=%v: %v", session.ID, running, err)
+		}
+	}
 
 	if err := agent.Run(agentCtx, ""); err != nil {
 		cancel()
 		return nil, fmt.Errorf("starting agent loop: %w", err)
 	}
 
-	sm.mu.Lock()
 	sm.agents[session.ID] = agent
+	sm.lastAccessed[session.ID] = time.Now()
 	if sm.onAgentCreated != nil {
 		sm.onAgentCreated(agent)
 	}
-	sm.mu.Unlock()
 
 	return agent, nil
 }
@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+)
+
+func TestAgentManager_EvictIdleAgents(t *testing.T) {
+	newAgent := func(id string, state api.AgentState) *Agent {
+		ctx, cancel := context.WithCancel(context.Background())
+		return &Agent{
+			Session: &api.Session{ID: id, AgentState: state},
+			cancel:  cancel,
+			done:    ctx.Done(),
+		}
+	}
+
+	now := time.Now()
+	idle := newAgent("idle", api.AgentStateDone)
+	recent := newAgent("recent", api.AgentStateIdle)
+	running := newAgent("running", api.AgentStateRunning)
+	waiting := newAgent("waiting", api.AgentStateWaitingForInput)
+
+	sm := NewAgentManager(nil, nil)
+	for _, a := range []*Agent{idle, recent, running, waiting} {
+		sm.agents[a.Session.ID] = a
+		sm.lastAccessed[a.Session.ID] = now.Add(-time.Hour)
+	}
+	sm.lastAccessed[recent.Session.ID] = now.Add(-time.Minute)
+
+	sm.evictIdleAgents(now, 30*time.Minute)
+
+	if _, ok := sm.agents["idle"]; ok {
+		t.Errorf("expected idle agent to be evicted")
+	}
+	select {
+	case <-idle.Done():
+	default:
+		t.Errorf("expected evicted agent to be closed")
+	}
+	for _, id := range []string{"recent", "running", "waiting"} {
+		if _, ok := sm.agents[id]; !ok {
+			t.Errorf("expected agent %q to be kept", id)
+		}
+	}
+}
+
+func TestAgentManager_Shutdown(t *testing.T) {
+	sessionManager, err := sessions.NewSessionManager("memory")
+	if err != nil {
+		t.Fatalf("creating session manager: %v", err)
+	}
+	session, err := sessionManager.NewSession(sessions.Metadata{})
+	if err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	session.AgentState = api.AgentStateRunning
+	savedBefore := session.LastModified
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &Agent{Session: session, Output: make(chan any, 10), cancel: cancel, done: ctx.Done()}
+	a.startRequest(context.Background(), "list pods")
+	// The agentic loop records the outcome of the cancelled request
+	go func() {
+		<-a.requestContext(context.Background()).Done()
+		time.Sleep(100 * time.Millisecond)
+		a.addMessage(api.MessageSourceAgent, api.MessageTypeError, "request cancelled")
+		a.setAgentState(api.AgentStateDone)
+	}()
+
+	sm := NewAgentManager(nil, sessionManager)
+	sm.agents[session.ID] = a
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	if err := sm.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if messages := session.AllMessages(); len(messages) != 1 {
+		t.Errorf("session has %d messages after shutdown, want the outcome of the cancelled request", len(messages))
+	}
+	select {
+	case <-a.Done():
+	default:
+		t.Errorf("expected the agent to be closed")
+	}
+	if !session.LastModified.After(savedBefore) {
+		t.Errorf("expected the session to be saved")
+	}
+	if len(sm.agents) != 0 {
+		t.Errorf("expected no active agents after shutdown, got %d", len(sm.agents))
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/redact"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+)
+
+const runUsage = "Invalid command. Usage: /run <kubectl or shell command>, or /run <tool> [JSON arguments]"
+
+// runTool runs a tool call the user asked for with /run, e.g. /run kubectl get pods -n foo or
+// /run node_health {"node": "n1"}, through the executor of the agent. The call is refused like
+// the calls of the model, e.g. in read-only mode, but needs no approval: the user makes it.
+// Its result is sent to the LLM with the next query. /run is not available on shared
+// servers, where users must not run commands on the server without approval.
+func (c *Agent) runTool(ctx context.Context, text string) (string, error) {
+	if c.MultiUser {
+		return "/run is not available on a shared server.", nil
+	}
+	call, ok := c.parseRun(text)
+	if !ok {
+		return runUsage, nil
+	}
+	if c.Tools.Lookup(call.Name) == nil {
+		return fmt.Sprintf("Tool %q is not available in this session.", call.Name), nil
+	}
+	if c.RequireJustification {
+		key := justificationParameter
+		if c.EnableToolUseShim {
+			key = "reason"
+		}
+		call.Arguments[key] = "Run by the user with /run"
+	}
+	analysis, err := c.analyzeToolCalls(ctx, []gollm.FunctionCall{call})
+	if err != nil {
+		return "", err
+	}
+	if err := analysis[0].blockedError(); err != nil {
+		return fmt.Sprintf("Cannot run %q: %v", analysis[0].ParsedToolCall.Description(), err), nil
+	}
+
+	description := analysis[0].ParsedToolCall.Description()
+	c.addMessage(api.MessageSourceUser, api.MessageTypeToolCallRequest, description)
+	output, err := analysis[0].ParsedToolCall.InvokeTool(ctx, tools.InvokeToolOptions{
+		Kubeconfig:    c.Kubeconfig,
+		WorkDir:       c.workDir,
+		Executor:      c.executor,
+		Justification: analysis[0].Justification,
+	})
+	if err != nil {
+		c.addMessage(api.MessageSourceAgent, api.MessageTypeToolCallResponse, err.Error())
+		return "", fmt.Errorf("running %q: %w", description, err)
+	}
+	payload, err := tools.ToolResultToMap(output)
+	if err != nil {
+		return "", fmt.Errorf("converting the result of %q: %w", description, err)
+	}
+	c.addMessage(api.MessageSourceAgent, api.MessageTypeToolCallResponse, payload)
+
+	c.userRuns = append(c.userRuns, fmt.Sprintf("%s\n```\n%s\n```", description, truncatePipedInput(redact.String(formatRunOutput(output)))))
+	return "The output will be shared with the model along with your next message.", nil
+}
+
+// parseRun parses the text of /run into a call of a tool with JSON arguments, or into a
+// command of the kubectl or bash tools.
+func (c *Agent) parseRun(text string) (gollm.FunctionCall, bool) {
+	name, rest, _ := strings.Cut(text, " ")
+	rest = strings.TrimSpace(rest)
+	if name == "" {
+		return gollm.FunctionCall{}, false
+	}
+	if c.Tools.Lookup(name) != nil && (rest == "" || strings.HasPrefix(rest, "{")) {
+		args := map[string]any{}
+		if rest != "" {
+			if err := json.Unmarshal([]byte(rest), &args); err != nil {
+				return gollm.FunctionCall{}, false
+			}
+		}
+		return gollm.FunctionCall{Name: name, Arguments: args}, true
+	}
+	tool := "bash"
+	if name == "kubectl" {
+		tool = "kubectl"
+	}
+	return gollm.FunctionCall{Name: tool, Arguments: map[string]any{"command": text}}, true
+}
+
+// userRunsContext returns the results of the tool calls the user ran since the last query,
+// as an observation to send the LLM, or "" if there are none.
+func (c *Agent) userRunsContext() string {
+	if len(c.userRuns) == 0 {
+		return ""
+	}
+	text := "The user ran the following themselves, these are the results, so you do not need to run them again:\n\n" +
+		strings.Join(c.userRuns, "\n\n")
+	c.userRuns = nil
+	return text
+}
+
+// formatRunOutput returns the output of a tool as text: the output of commands, or the
+// result of other tools as JSON.
+func formatRunOutput(output any) string {
+	if result, ok := output.(*sandbox.ExecResult); ok && result != nil {
+		text := result.Stdout
+		if result.Stderr != "" {
+			text += "\n" + result.Stderr
+		}
+		if result.Error != "" {
+			text += "\nError: " + result.Error
+		}
+		return strings.TrimSpace(text)
+	}
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprint(output)
+	}
+	return string(b)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/mcp"
@@ -29,39 +30,150 @@ import (
 // It connects to servers and registers discovered tools with the kubectl-ai tool system.
 func (a *Agent) InitializeMCPClient(ctx context.Context) error {
 	// Initialize the MCP manager
-	manager, err := mcp.InitializeManager()
+	manager, err := mcp.InitializeManager(a.MCPServers...)
 	if err != nil {
 		return fmt.Errorf("failed to initialize MCP manager: %w", err)
 	}
 
+	// Servers with sampling enabled can use the LLM of the agent
+	a.samplingApproved = make(map[string]bool)
+	manager.SetSamplingHandler(a.handleSamplingRequest)
+	// Configured resources are sent with the queries, again when they change
+	manager.SetResourceUpdateHandler(a.onMCPResourceUpdate)
+
 	// Connect to servers and register tools
-	err = manager.RegisterWithToolSystem(ctx, func(serverName string, toolInfo mcp.Tool) error {
+	err = manager.RegisterWithToolSystem(ctx, a.mcpToolRegistrar(manager))
+	if err != nil {
+		return fmt.Errorf("failed to register MCP tools: %w", err)
+	}
+
+	// Store the manager for later use
+	a.mcpManager = manager
+
+	// Pick up changes to the tool settings of the servers; the tools are registered
+	// again by the agent loop, between iterations.
+	watchCtx, cancel := context.WithCancel(context.Background())
+	a.mcpWatchCancel = cancel
+	go manager.WatchConfig(watchCtx, func() {
+		a.mcpToolsChanged.Store(true)
+	})
+	// Reconnect servers that stop responding; their tools are registered again,
+	// and the change is reported in the session, by the agent loop.
+	go manager.MonitorHealth(watchCtx, mcp.DefaultHealthCheckInterval, a.onMCPHealthChange)
+
+	return nil
+}
+
+// mcpToolRegistrar returns the callback registering the MCP tools of manager with the agent.
+func (a *Agent) mcpToolRegistrar(manager *mcp.Manager) func(serverName string, toolInfo mcp.Tool) error {
+	return func(serverName string, toolInfo mcp.Tool) error {
 		// Create schema for the tool
 		schema, err := tools.ConvertToolToGollm(&toolInfo)
 		if err != nil {
 			return err
 		}
 
+		opts := []tools.MCPToolOption{
+			tools.WithTrustLevel(manager.TrustLevel(serverName)),
+			tools.WithReadOnly(toolInfo.ReadOnly),
+		}
+		if toolsConfig := manager.ToolsConfig(serverName); toolsConfig != nil {
+			opts = append(opts, tools.WithNamePrefix(toolsConfig.Prefix), tools.WithMaxResultSize(toolsConfig.MaxResultSize))
+		}
+
 		// Create an MCPTool wrapper first to get the unique name
-		mcpTool := tools.NewMCPTool(serverName, toolInfo.Name, toolInfo.Description, schema, manager)
+		mcpTool := tools.NewMCPTool(serverName, toolInfo.Name, toolInfo.Description, schema, manager, opts...)
 
 		// Update schema with unique name and better description to avoid conflicts
 		schema.Name = mcpTool.UniqueToolName()
 		schema.Description = fmt.Sprintf("%s (from %s)", toolInfo.Description, serverName)
 
 		// Create and register MCP tool wrapper
-		tools.RegisterTool(mcpTool)
+		a.Tools.RegisterTool(mcpTool)
 		return nil
-	})
+	}
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to register MCP tools: %w", err)
+// onMCPHealthChange queues a notice about a server that was disconnected or connected
+// again, and has its tools registered again.
+func (a *Agent) onMCPHealthChange(change mcp.HealthChange) {
+	var notice string
+	if change.Connected {
+		notice = fmt.Sprintf("Reconnected to MCP server %q, its tools are available again.", change.Server)
+		a.markMCPServerResourcesStale(change.Server)
+	} else {
+		notice = fmt.Sprintf("MCP server %q stopped responding (%v). Its tools are unavailable until it is reconnected.", change.Server, change.Err)
 	}
+	a.mcpNoticesMu.Lock()
+	a.mcpNotices = append(a.mcpNotices, notice)
+	a.mcpNoticesMu.Unlock()
+	a.mcpToolsChanged.Store(true)
+}
 
-	// Store the manager for later use
-	a.mcpManager = manager
+// flushMCPNotices adds the queued MCP server notices to the session.
+func (a *Agent) flushMCPNotices(ctx context.Context) {
+	a.mcpNoticesMu.Lock()
+	notices := a.mcpNotices
+	a.mcpNotices = nil
+	a.mcpNoticesMu.Unlock()
+	if len(notices) == 0 {
+		return
+	}
+	for _, notice := range notices {
+		a.addMessage(api.MessageSourceAgent, api.MessageTypeText, notice)
+	}
+	if err := a.UpdateMCPStatus(ctx, true); err != nil {
+		klog.Warningf("Failed to update MCP status: %v", err)
+	}
+}
 
-	return nil
+// mcpStatusText answers the "mcp status" meta command with the health of the servers.
+func (a *Agent) mcpStatusText() string {
+	if a.mcpManager == nil {
+		return "MCP client mode is not enabled, enable it with --mcp-client."
+	}
+
+	toolCounts := make(map[string]int)
+	for _, tool := range a.Tools.AllTools() {
+		if mcpTool, ok := tool.(*tools.MCPTool); ok {
+			toolCounts[mcpTool.ServerName()]++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("MCP servers:\n\n")
+	for _, health := range a.mcpManager.Health() {
+		if health.Connected {
+			fmt.Fprintf(&sb, "  - `%s`: connected, %d tools\n", health.Name, toolCounts[health.Name])
+			continue
+		}
+		fmt.Fprintf(&sb, "  - `%s`: disconnected", health.Name)
+		if health.LastError != "" {
+			fmt.Fprintf(&sb, " (%s)", health.LastError)
+		}
+		if !health.NextRetry.IsZero() {
+			fmt.Fprintf(&sb, ", reconnecting in %s", time.Until(health.NextRetry).Round(time.Second).String())
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// reloadMCPTools registers the MCP tools again after their settings changed, and
+// tells the LLM about the new tool set.
+func (a *Agent) reloadMCPTools(ctx context.Context) error {
+	if a.mcpManager == nil {
+		return nil
+	}
+	a.Tools.RemoveMCPTools()
+	if err := a.mcpManager.RegisterTools(ctx, a.mcpToolRegistrar(a.mcpManager)); err != nil {
+		return fmt.Errorf("registering MCP tools: %w", err)
+	}
+	if a.EnableToolUseShim {
+		// The tools are described in the system prompt, which cannot change mid-session
+		return nil
+	}
+	return a.setFunctionDefinitions()
 }
 
 // UpdateMCPStatus updates the MCP status in the agent's session
@@ -218,6 +330,10 @@ func extractCommandName(command string) string {
 
 // CloseMCPClient closes the MCP client connections
 func (a *Agent) CloseMCPClient() error {
+	if a.mcpWatchCancel != nil {
+		a.mcpWatchCancel()
+		a.mcpWatchCancel = nil
+	}
 	if a.mcpManager != nil {
 		err := a.mcpManager.Close()
 		a.mcpManager = nil
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import "strings"
+
+// MetaCommand describes a command handled by the agent itself rather than the LLM.
+// UIs use the registry for help, completion and command palettes.
+type MetaCommand struct {
+	// Name is what the user types, optionally prefixed with "/".
+	Name string
+	// Args describes the arguments, if the command takes any.
+	Args string
+	// Description is a one-line summary of what the command does.
+	Description string
+	// Aliases are alternative names for the command.
+	Aliases []string
+}
+
+var metaCommands = []MetaCommand{
+	{Name: "clear", Aliases: []string{"reset"}, Description: "Clear the conversation"},
+	{Name: "model", Description: "Show the current model"},
+	{Name: "models", Description: "List the models available from the provider"},
+	{Name: "tools", Description: "List the tools available to the agent"},
+	{Name: "mcp", Args: "status", Description: "Show the status of the MCP servers"},
+	{Name: "prompts", Description: "List the prompts provided by MCP servers"},
+	{Name: "prompt", Args: "<server>/<name> [arg=value ...]", Description: "Send a prompt provided by an MCP server"},
+	{Name: "run", Args: "<command> | <tool> [JSON arguments]", Description: "Run a command or tool yourself, sharing its output with the model"},
+	{Name: "session", Description: "Show information about the current session"},
+	{Name: "sessions", Description: "List saved sessions"},
+	{Name: "save-session", Description: "Save the current session"},
+	{Name: "resume-session", Args: "<session_id>", Description: "Resume a saved session"},
+	{Name: "exit", Aliases: []string{"quit"}, Description: "Exit kubectl-ai"},
+}
+
+// MetaCommands returns the commands the agent handles without calling the LLM.
+func MetaCommands() []MetaCommand {
+	return append([]MetaCommand(nil), metaCommands...)
+}
+
+// trimMetaCommandPrefix strips the optional "/" in front of a meta command,
+// so "/model" and "model" are equivalent. Other queries are returned unchanged.
+func trimMetaCommandPrefix(query string) string {
+	name, ok := strings.CutPrefix(query, "/")
+	if !ok {
+		return query
+	}
+	word, _, _ := strings.Cut(name, " ")
+	for _, cmd := range metaCommands {
+		if cmd.Name == word {
+			return name
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == word {
+				return name
+			}
+		}
+	}
+	return query
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/notify"
+)
+
+// notifyRequestDone notifies that the current request completed or failed, once the
+// agent is done with it. Interactive requests are only notified when they ran longer
+// than the minimum duration of the notifier, as users are likely watching short ones.
+func (c *Agent) notifyRequestDone() {
+	started, query, ok := c.takeNotifiedRequest()
+	if !ok {
+		return
+	}
+	duration := time.Since(started)
+	if !c.RunOnce && duration < c.Notifier.MinDuration() {
+		return
+	}
+	event := notify.Event{
+		Type:            notify.EventCompleted,
+		Query:           query,
+		DurationSeconds: duration.Seconds(),
+	}
+	event.Summary, event.Type = c.requestOutcome()
+	c.notify(event)
+}
+
+// notifyApprovalRequired notifies that the current request waits for the user to
+// approve commands, with the same minimum duration as notifyRequestDone.
+func (c *Agent) notifyApprovalRequired(commands []string) {
+	c.requestMu.Lock()
+	started, query := c.requestStarted, c.requestQuery
+	c.requestMu.Unlock()
+	if c.Notifier == nil || started.IsZero() {
+		return
+	}
+	duration := time.Since(started)
+	if !c.RunOnce && duration < c.Notifier.MinDuration() {
+		return
+	}
+	c.notify(notify.Event{
+		Type:            notify.EventApprovalRequired,
+		Query:           query,
+		Summary:         strings.Join(commands, "\n"),
+		DurationSeconds: duration.Seconds(),
+	})
+}
+
+// takeNotifiedRequest returns the start and query of the current request, if it was not notified yet.
+func (c *Agent) takeNotifiedRequest() (time.Time, string, bool) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	started, query := c.requestStarted, c.requestQuery
+	c.requestStarted, c.requestQuery = time.Time{}, ""
+	return started, query, c.Notifier != nil && !started.IsZero()
+}
+
+// requestOutcome returns the last answer or error of the current request, found in
+// the messages added since the query of the user.
+func (c *Agent) requestOutcome() (string, notify.EventType) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	messages := c.Session.ChatMessageStore.ChatMessages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		m := messages[i]
+		if m.Source == api.MessageSourceUser {
+			break
+		}
+		switch m.Type {
+		case api.MessageTypeError:
+			if payload, ok := m.Payload.(*api.ErrorPayload); ok {
+				return payload.Message, notify.EventFailed
+			}
+		case api.MessageTypeText:
+			if text, ok := m.Payload.(string); ok && m.Source == api.MessageSourceModel {
+				return text, notify.EventCompleted
+			}
+		}
+	}
+	return "", notify.EventCompleted
+}
+
+func (c *Agent) notify(event notify.Event) {
+	if c.Session != nil {
+		event.SessionID = c.Session.ID
+	}
+	c.Notifier.Notify(event)
+}
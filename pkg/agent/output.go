@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"sync"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"k8s.io/klog/v2"
+)
+
+// outputQueueSize bounds the messages waiting for the UI to read them. When a stuck UI lets
+// the queue fill up, the oldest status messages and streamed text updates are dropped. The
+// other messages are never dropped, since not all UIs reload the session: requests for
+// input, or choices, would never be shown and the agent would wait for them forever.
+const outputQueueSize = 1000
+
+// outputQueue delivers the messages of the agent to its Output channel in order, from a
+// goroutine of its own, so that a slow or stuck UI never blocks the agentic loop. Updates
+// of a message still waiting in the queue, such as streamed text, replace it: a slow UI gets
+// fewer, larger updates.
+type outputQueue struct {
+	mu    sync.Mutex
+	items []queuedOutput
+	// closing closes Output once the queued items are delivered
+	closing bool
+	closed  bool
+	dropped int
+
+	// wake is signalled when items are queued, and stop ends delivery
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// queuedOutput is an output waiting for the UI.
+type queuedOutput struct {
+	output any
+	// droppable is set for outputs that can be dropped when the queue is full
+	droppable bool
+}
+
+// emit queues a message, or any other output, for the UI.
+func (c *Agent) emit(output any) {
+	message, ok := output.(*api.Message)
+	c.queueOutput(output, ok && message.Type == api.MessageTypeStatus)
+}
+
+// emitStreamed queues an update of a message that is still streaming; it can be dropped
+// since the final version of the message follows.
+func (c *Agent) emitStreamed(message *api.Message) {
+	c.queueOutput(message, true)
+}
+
+func (c *Agent) queueOutput(output any, droppable bool) {
+	q := c.outputQueue()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closing {
+		klog.V(2).Infof("dropping output after the output channel was closed: %v", output)
+		return
+	}
+	if message, ok := output.(*api.Message); ok {
+		for i, queued := range q.items {
+			if m, ok := queued.output.(*api.Message); ok && m.ID == message.ID {
+				q.items[i] = queuedOutput{output: message, droppable: droppable}
+				return
+			}
+		}
+	}
+	if len(q.items) >= outputQueueSize {
+		q.dropOldest()
+	}
+	q.items = append(q.items, queuedOutput{output: output, droppable: droppable})
+	q.signal()
+}
+
+// dropOldest drops the oldest droppable output, if any; the caller holds q.mu.
+func (q *outputQueue) dropOldest() {
+	for i, queued := range q.items {
+		if !queued.droppable {
+			continue
+		}
+		q.items = append(q.items[:i], q.items[i+1:]...)
+		if q.dropped++; q.dropped%100 == 1 {
+			klog.Warningf("UI is not reading the output of the agent, dropped %d messages", q.dropped)
+		}
+		return
+	}
+}
+
+// closeOutput closes the Output channel once the messages queued so far are delivered.
+func (c *Agent) closeOutput() {
+	q := c.outputQueue()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closing = true
+	q.signal()
+}
+
+// stopOutput stops delivering messages, e.g. when the agent is shut down and no UI reads them.
+func (c *Agent) stopOutput() {
+	q := c.outputQueue()
+	q.stopOnce.Do(func() { close(q.stop) })
+}
+
+// outputQueue returns the output queue of the agent, starting its delivery on first use.
+func (c *Agent) outputQueue() *outputQueue {
+	c.outputOnce.Do(func() {
+		c.output = &outputQueue{
+			wake: make(chan struct{}, 1),
+			stop: make(chan struct{}),
+		}
+		go c.deliverOutput(c.output)
+	})
+	return c.output
+}
+
+// signal wakes up delivery; the caller holds q.mu.
+func (q *outputQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverOutput sends the queued messages to Output until the queue is closed or stopped.
+func (c *Agent) deliverOutput(q *outputQueue) {
+	for {
+		q.mu.Lock()
+		if len(q.items) == 0 {
+			if q.closing && !q.closed {
+				q.closed = true
+				q.mu.Unlock()
+				close(c.Output)
+				return
+			}
+			q.mu.Unlock()
+			select {
+			case <-q.wake:
+				continue
+			case <-q.stop:
+				return
+			}
+		}
+		item := q.items[0].output
+		q.items[0] = queuedOutput{}
+		q.items = q.items[1:]
+		q.mu.Unlock()
+
+		select {
+		case c.Output <- item:
+		case <-q.stop:
+			return
+		}
+	}
+}
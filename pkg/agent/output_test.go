@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+func TestOutputQueue(t *testing.T) {
+	a := &Agent{Output: make(chan any)}
+	defer a.stopOutput()
+
+	// Nobody reads the output yet: queuing must not block, and drops the oldest status messages,
+	// but never the request for a choice
+	choice := &api.Message{ID: "choice", Type: api.MessageTypeUserChoiceRequest}
+	emitted := make(chan struct{})
+	go func() {
+		for i := 0; i < outputQueueSize; i++ {
+			a.emit(&api.Message{ID: fmt.Sprint(i), Type: api.MessageTypeStatus})
+		}
+		a.emit(choice)
+		for i := outputQueueSize; i < 2*outputQueueSize; i++ {
+			a.emit(&api.Message{ID: fmt.Sprint(i), Type: api.MessageTypeStatus})
+		}
+		a.closeOutput()
+		close(emitted)
+	}()
+	select {
+	case <-emitted:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("emitting blocked on a UI not reading the output")
+	}
+
+	var received []*api.Message
+	for output := range a.Output {
+		received = append(received, output.(*api.Message))
+	}
+	// The message being delivered when the queue filled up comes on top of the queue
+	if len(received) < outputQueueSize || len(received) > outputQueueSize+1 {
+		t.Fatalf("received %d messages, want the %d the queue holds", len(received), outputQueueSize)
+	}
+	if last := received[len(received)-1]; last.ID != fmt.Sprint(2*outputQueueSize-1) {
+		t.Errorf("last message = %q, want the last emitted", last.ID)
+	}
+	if !slices.Contains(received, choice) {
+		t.Errorf("the request for a choice was dropped")
+	}
+
+	// Updates of a message waiting in the queue replace it
+	b := &Agent{Output: make(chan any)}
+	defer b.stopOutput()
+	b.emit(&api.Message{ID: "first"})
+	b.emit(&api.Message{ID: "stream", Payload: "There"})
+	b.emit(&api.Message{ID: "stream", Payload: "There are 3 pods"})
+	<-b.Output
+	if m := (<-b.Output).(*api.Message); m.Payload != "There are 3 pods" {
+		t.Errorf("streamed message = %q, want the latest update", m.Payload)
+	}
+}
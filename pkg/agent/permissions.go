@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+)
+
+// Decisions recorded in a PermissionDecisionEvent.
+const (
+	// PermissionDecisionApproved is for commands the user approved, some of them
+	// possibly individually declined.
+	PermissionDecisionApproved = "approved"
+	// PermissionDecisionApprovedAlways is for commands the user approved, turning off
+	// further approval requests for the session.
+	PermissionDecisionApprovedAlways = "approved-always"
+	// PermissionDecisionDeclined is for commands the user declined.
+	PermissionDecisionDeclined = "declined"
+	// PermissionDecisionInvalid is for an invalid answer, which runs none of the commands.
+	PermissionDecisionInvalid = "invalid"
+	// PermissionDecisionAutoApproved is for commands run without asking, because
+	// permission checks are skipped (--skip-permissions or "don't ask me again").
+	PermissionDecisionAutoApproved = "auto-approved"
+	// PermissionDecisionRefused is for commands not run because the agent could not ask
+	// for approval, in RunOnce mode.
+	PermissionDecisionRefused = "refused"
+)
+
+// PermissionCommand is a command subject to a permission decision.
+type PermissionCommand struct {
+	Command string `json:"command"`
+	// ModifiesResource is whether the command modifies resources: yes, no or unknown.
+	ModifiesResource string `json:"modifiesResource"`
+	Approved         bool   `json:"approved"`
+	// Justification is why the model runs the command, if it is required to say.
+	Justification string `json:"justification,omitempty"`
+}
+
+// PermissionRequestEvent is the payload of journal.ActionPermissionRequest events.
+type PermissionRequestEvent struct {
+	Prompt   string              `json:"prompt"`
+	Commands []PermissionCommand `json:"commands"`
+}
+
+// PermissionDecisionEvent is the payload of journal.ActionPermissionDecision events.
+type PermissionDecisionEvent struct {
+	Decision string `json:"decision"`
+	// Choice is the option picked by the user, if they were asked.
+	Choice   int                 `json:"choice,omitempty"`
+	Commands []PermissionCommand `json:"commands"`
+	// AutoApproved is true if no human was asked.
+	AutoApproved bool `json:"autoApproved"`
+}
+
+// pendingPermissionCommands describes the pending function calls, approved per approvals
+// (all, if nil).
+func (c *Agent) pendingPermissionCommands(approvals []bool) []PermissionCommand {
+	commands := make([]PermissionCommand, len(c.pendingFunctionCalls))
+	for i, call := range c.pendingFunctionCalls {
+		commands[i] = PermissionCommand{
+			Command:          call.ParsedToolCall.Description(),
+			ModifiesResource: call.ModifiesResourceStr,
+			Approved:         approvals == nil || (i < len(approvals) && approvals[i]),
+			Justification:    call.Justification,
+		}
+	}
+	return commands
+}
+
+func (c *Agent) recordPermissionRequest(request *api.UserChoiceRequest) {
+	c.recordEvent(&journal.Event{
+		Timestamp: time.Now(),
+		Action:    journal.ActionPermissionRequest,
+		Payload: PermissionRequestEvent{
+			Prompt:   request.Prompt,
+			Commands: c.pendingPermissionCommands(make([]bool, len(c.pendingFunctionCalls))),
+		},
+	})
+}
+
+// recordPermissionDecision records a decision taken without asking the user:
+// auto-approved or refused.
+func (c *Agent) recordPermissionDecision(decision string) {
+	var approvals []bool
+	if decision == PermissionDecisionRefused {
+		approvals = make([]bool, len(c.pendingFunctionCalls))
+	}
+	c.recordEvent(&journal.Event{
+		Timestamp: time.Now(),
+		Action:    journal.ActionPermissionDecision,
+		Payload: PermissionDecisionEvent{
+			Decision:     decision,
+			Commands:     c.pendingPermissionCommands(approvals),
+			AutoApproved: decision == PermissionDecisionAutoApproved,
+		},
+	})
+}
+
+// recordPermissionChoice records the answer of the user to a permission request,
+// before it is applied to the pending calls.
+func (c *Agent) recordPermissionChoice(choice *api.UserChoiceResponse) {
+	decision := PermissionDecisionInvalid
+	approvals := make([]bool, len(c.pendingFunctionCalls))
+	switch choice.Choice {
+	case 1, 2:
+		decision = PermissionDecisionApproved
+		if choice.Choice == 2 && c.approvesAllCalls(choice) {
+			decision = PermissionDecisionApprovedAlways
+		}
+		switch len(choice.Selections) {
+		case 0:
+			approvals = nil
+		case len(approvals):
+			approvals = choice.Selections
+		default:
+			decision = PermissionDecisionInvalid
+		}
+	case 3:
+		decision = PermissionDecisionDeclined
+	}
+
+	c.recordEvent(&journal.Event{
+		Timestamp: time.Now(),
+		Action:    journal.ActionPermissionDecision,
+		Payload: PermissionDecisionEvent{
+			Decision: decision,
+			Choice:   choice.Choice,
+			Commands: c.pendingPermissionCommands(approvals),
+		},
+	})
+}
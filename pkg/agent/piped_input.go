@@ -0,0 +1,49 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/redact"
+)
+
+// maxPipedInputSize is the size of piped input sent to the LLM. Larger input keeps
+// its beginning, e.g. the header of a table, and its end, e.g. the latest log lines.
+const maxPipedInputSize = 64 * 1024
+
+// pipedInputContext returns PipedInput as an observation to send the LLM before
+// the first query, or "" if there is none or it was sent already.
+func (a *Agent) pipedInputContext() string {
+	if a.PipedInput == "" || a.pipedInputSent {
+		return ""
+	}
+	a.pipedInputSent = true
+	return "The user piped the following output to you, it is the result of a command they ran already " +
+		"so you do not need to run it again:\n\n```\n" + truncatePipedInput(redact.String(a.PipedInput)) + "\n```"
+}
+
+// truncatePipedInput keeps the beginning and the end of input longer than maxPipedInputSize.
+func truncatePipedInput(input string) string {
+	if len(input) <= maxPipedInputSize {
+		return input
+	}
+	half := maxPipedInputSize / 2
+	omitted := len(input) - 2*half
+	return strings.ToValidUTF8(input[:half], "") +
+		fmt.Sprintf("\n[... %d bytes omitted ...]\n", omitted) +
+		strings.ToValidUTF8(input[len(input)-half:], "")
+}
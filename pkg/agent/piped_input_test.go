@@ -0,0 +1,50 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestQueryContentPipedInput(t *testing.T) {
+	a := &Agent{PipedInput: "NAME    STATUS\nweb-1   Pending\npassword=hunter2"}
+
+	content := a.queryContent(context.Background(), "why are these pending?")
+	if len(content) != 2 || content[1] != "why are these pending?" {
+		t.Fatalf("queryContent() = %v, want the piped input then the query", content)
+	}
+	piped := content[0].(string)
+	if !strings.Contains(piped, "web-1   Pending") || strings.Contains(piped, "hunter2") {
+		t.Errorf("piped input context = %q, want the redacted output", piped)
+	}
+
+	// It is only sent with the first query
+	if content := a.queryContent(context.Background(), "and now?"); len(content) != 1 {
+		t.Errorf("queryContent() of the next query = %v, want only the query", content)
+	}
+}
+
+func TestTruncatePipedInput(t *testing.T) {
+	input := "HEADER\n" + strings.Repeat("x", 2*maxPipedInputSize) + "\nLAST LINE"
+	got := truncatePipedInput(input)
+	if len(got) > maxPipedInputSize+100 {
+		t.Errorf("truncatePipedInput() kept %d bytes, want about %d", len(got), maxPipedInputSize)
+	}
+	if !strings.HasPrefix(got, "HEADER\n") || !strings.HasSuffix(got, "\nLAST LINE") || !strings.Contains(got, "bytes omitted") {
+		t.Errorf("truncatePipedInput() should keep the beginning and the end, and say what was omitted")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+// listPrompts answers the "prompts" meta command with the prompts of the MCP servers.
+func (c *Agent) listPrompts(ctx context.Context) (string, error) {
+	if c.mcpManager == nil {
+		return "No MCP servers are connected, enable them with --mcp-client.", nil
+	}
+	prompts, err := c.mcpManager.ListPrompts(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(prompts) == 0 {
+		return "The connected MCP servers provide no prompts.", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Available prompts, run them with `/prompt <server>/<name> [arg=value ...]`:\n\n")
+	for _, prompt := range prompts {
+		fmt.Fprintf(&sb, "  - `%s`", prompt.ID())
+		if prompt.Description != "" {
+			fmt.Fprintf(&sb, ": %s", prompt.Description)
+		}
+		sb.WriteString("\n")
+		for _, arg := range prompt.Arguments {
+			fmt.Fprintf(&sb, "    - `%s`", arg.Name)
+			if arg.Required {
+				sb.WriteString(" (required)")
+			}
+			if arg.Description != "" {
+				fmt.Fprintf(&sb, ": %s", arg.Description)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// resolvePrompt expands a "prompt <server>/<name> [arg=value ...]" query into the
+// text of the MCP prompt, and adds it to the conversation as a user message. Other
+// queries are returned unchanged.
+func (c *Agent) resolvePrompt(ctx context.Context, query string) (string, error) {
+	rest, ok := strings.CutPrefix(trimMetaCommandPrefix(query), "prompt ")
+	if !ok {
+		return query, nil
+	}
+	if c.mcpManager == nil {
+		return "", fmt.Errorf("no MCP servers are connected, enable them with --mcp-client")
+	}
+
+	fields, err := splitPromptArgs(rest)
+	if err != nil {
+		return "", err
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("usage: prompt <server>/<name> [arg=value ...]")
+	}
+	server, name, ok := strings.Cut(fields[0], "/")
+	if !ok || server == "" || name == "" {
+		return "", fmt.Errorf("prompt %q must be given as <server>/<name>", fields[0])
+	}
+	args := make(map[string]string, len(fields)-1)
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return "", fmt.Errorf("prompt argument %q must be given as arg=value", field)
+		}
+		args[key] = value
+	}
+
+	text, err := c.mcpManager.GetPrompt(ctx, server, name, args)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("prompt %s/%s has no text content", server, name)
+	}
+	c.addMessage(api.MessageSourceUser, api.MessageTypeText, text)
+	return text, nil
+}
+
+// splitPromptArgs splits the arguments of the prompt meta command on spaces. Values
+// containing spaces can be double quoted, e.g. question="why is my pod pending".
+func splitPromptArgs(s string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	inField, quoted := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inField = true
+		case r == ' ' && !quoted:
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
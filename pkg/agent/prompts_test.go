@@ -0,0 +1,43 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPromptArgs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "k8s/debug-pod", want: []string{"k8s/debug-pod"}},
+		{in: "  k8s/debug-pod   pod=web-0 namespace=prod ", want: []string{"k8s/debug-pod", "pod=web-0", "namespace=prod"}},
+		{in: `k8s/ask question="why is my pod pending" verbose=`, want: []string{"k8s/ask", "question=why is my pod pending", "verbose="}},
+		{in: `k8s/ask question="unterminated`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitPromptArgs(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitPromptArgs(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitPromptArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
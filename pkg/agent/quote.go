@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+// quoteMessages returns the full content of the earlier messages with the given IDs, to
+// prefix the query that quotes them with. UIs may only show the beginning of long messages
+// such as tool outputs, so the content is taken from the session.
+func (c *Agent) quoteMessages(messageIDs []string) (string, error) {
+	byID := map[string]*api.Message{}
+	for _, message := range c.Session.AllMessages() {
+		byID[message.ID] = message
+	}
+
+	var b strings.Builder
+	b.WriteString("Quoted from earlier in the conversation:\n")
+	for _, id := range messageIDs {
+		message, ok := byID[id]
+		if !ok {
+			return "", fmt.Errorf("quoted message %q is not in the session", id)
+		}
+		content := strings.TrimRight(quotedContent(message.Payload), "\n")
+		fmt.Fprintf(&b, "\n%s:\n```\n%s\n```\n", quotedSource(message), content)
+	}
+	return b.String(), nil
+}
+
+// quotedSource describes where a quoted message comes from.
+func quotedSource(message *api.Message) string {
+	switch {
+	case message.Type == api.MessageTypeToolCallRequest:
+		return "Tool call"
+	case message.Type == api.MessageTypeToolCallResponse:
+		return "Tool output"
+	case message.Type == api.MessageTypeText && message.Source == api.MessageSourceUser:
+		return "User"
+	case message.Type == api.MessageTypeText:
+		return "Assistant"
+	default:
+		return string(message.Type)
+	}
+}
+
+// quotedContent returns the payload of a message as text: tool results are shown by their
+// output streams when they have them, and as JSON otherwise.
+func quotedContent(payload any) string {
+	switch payload := payload.(type) {
+	case string:
+		return payload
+	case map[string]any:
+		var streams []string
+		for _, key := range []string{"content", "stdout", "stderr", "error"} {
+			if s, ok := payload[key].(string); ok && s != "" {
+				streams = append(streams, s)
+			}
+		}
+		if len(streams) > 0 {
+			return strings.Join(streams, "\n")
+		}
+	}
+	b, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return fmt.Sprint(payload)
+	}
+	return string(b)
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+)
+
+func TestQuoteMessages(t *testing.T) {
+	logs := strings.Repeat("line of logs\n", 500) + "panic: nil map"
+	store := sessions.NewInMemoryChatStore()
+	store.SetChatMessages([]*api.Message{
+		{ID: "call", Source: api.MessageSourceModel, Type: api.MessageTypeToolCallRequest, Payload: "kubectl logs web-1"},
+		{ID: "output", Source: api.MessageSourceAgent, Type: api.MessageTypeToolCallResponse, Payload: map[string]any{"stdout": logs, "exit_code": 0}},
+		{ID: "answer", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "web-1 crashed."},
+	})
+	a := &Agent{Session: &api.Session{ChatMessageStore: store}}
+
+	quoted, err := a.quoteMessages([]string{"call", "output", "answer"})
+	if err != nil {
+		t.Fatalf("quoting messages: %v", err)
+	}
+	for _, want := range []string{"Tool call:\n```\nkubectl logs web-1\n```", "panic: nil map", "Assistant:\n```\nweb-1 crashed.\n```"} {
+		if !strings.Contains(quoted, want) {
+			t.Errorf("quote does not contain %q:\n%s", want, quoted)
+		}
+	}
+	if strings.Contains(quoted, "exit_code") {
+		t.Errorf("quote of the tool output should only have its output streams:\n%s", quoted)
+	}
+
+	if _, err := a.quoteMessages([]string{"missing"}); err == nil {
+		t.Errorf("expected an error quoting a message not in the session")
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// RecoverInterruptedSessions repairs the sessions that were processing a request when the
+// process died. Messages and tool results recorded in the journal at journalPath but missing
+// from the session are appended, followed by a message telling that the request was
+// interrupted, and the session is left idle. journalPath may be "" when there is no journal,
+// and must be read before a new recorder truncates it.
+// It returns the IDs of the recovered sessions.
+func RecoverInterruptedSessions(sessionManager *sessions.SessionManager, journalPath string) ([]string, error) {
+	interrupted, err := sessionManager.InterruptedSessions()
+	if err != nil {
+		return nil, fmt.Errorf("listing interrupted sessions: %w", err)
+	}
+	if len(interrupted) == 0 {
+		return nil, nil
+	}
+
+	var events []*journal.Event
+	if journalPath != "" {
+		if events, err = journal.ParseEventsFromFile(journalPath); err != nil {
+			klog.Warningf("Reading journal %q to recover sessions, recovering without it: %v", journalPath, err)
+		}
+	}
+
+	var recovered []string
+	for _, session := range interrupted {
+		if err := recoverSession(session, events); err != nil {
+			return recovered, fmt.Errorf("recovering session %s: %w", session.ID, err)
+		}
+		if err := sessionManager.SetSessionRunning(session.ID, false); err != nil {
+			return recovered, fmt.Errorf("resetting state of session %s: %w", session.ID, err)
+		}
+		klog.Infof("Recovered session %s interrupted by a crash", session.ID)
+		recovered = append(recovered, session.ID)
+	}
+	return recovered, nil
+}
+
+// recoverSession appends to the session what the journal recorded after its last saved
+// message, and the crash marker.
+func recoverSession(session *api.Session, events []*journal.Event) error {
+	store := session.ChatMessageStore
+	if store == nil {
+		return nil
+	}
+
+	var sessionEvents []*journal.Event
+	for _, event := range events {
+		if event.SessionID == session.ID {
+			sessionEvents = append(sessionEvents, event)
+		}
+	}
+	sort.SliceStable(sessionEvents, func(i, j int) bool {
+		return sessionEvents[i].Timestamp.Before(sessionEvents[j].Timestamp)
+	})
+
+	saved := map[string]bool{}
+	for _, message := range store.ChatMessages() {
+		saved[message.ID] = true
+	}
+
+	// Only replay what happened after the last message that made it to the session
+	start := 0
+	for i, event := range sessionEvents {
+		var message api.Message
+		if event.Action == journal.ActionAgentMessage && decodeJournalPayload(event.Payload, &message) == nil && saved[message.ID] {
+			start = i + 1
+		}
+	}
+
+	var missing []*api.Message
+	// toolNames maps the call IDs of tools that started to their name; it only keeps the
+	// calls that did not finish.
+	toolNames := map[string]string{}
+	var toolOrder []string
+	// unsaved are the results of tools the agent did not get to add to the session
+	var unsaved []*api.Message
+	for _, event := range sessionEvents[start:] {
+		switch event.Action {
+		case journal.ActionAgentMessage:
+			message := &api.Message{}
+			if decodeJournalPayload(event.Payload, message) != nil || saved[message.ID] {
+				continue
+			}
+			if message.Type == api.MessageTypeToolCallResponse && len(unsaved) > 0 {
+				// The journal has the message for the oldest result
+				unsaved = unsaved[1:]
+			}
+			missing = append(missing, unsaved...)
+			unsaved = nil
+			missing = append(missing, message)
+
+		case journal.ActionToolRequest:
+			var request tools.ToolRequestEvent
+			if decodeJournalPayload(event.Payload, &request) != nil {
+				continue
+			}
+			toolNames[request.CallID] = request.Name
+			toolOrder = append(toolOrder, request.CallID)
+
+		case journal.ActionToolResponse:
+			var response tools.ToolResponseEvent
+			if decodeJournalPayload(event.Payload, &response) != nil {
+				continue
+			}
+			delete(toolNames, response.CallID)
+			var payload any = response.Error
+			if response.Error == "" {
+				result, err := tools.ToolResultToMap(response.Response)
+				if err != nil {
+					continue
+				}
+				payload = result
+			}
+			unsaved = append(unsaved, &api.Message{
+				ID:        uuid.New().String(),
+				Source:    api.MessageSourceAgent,
+				Type:      api.MessageTypeToolCallResponse,
+				Payload:   payload,
+				Timestamp: event.Timestamp,
+			})
+		}
+	}
+	missing = append(missing, unsaved...)
+
+	var interruptedTools []string
+	for _, callID := range toolOrder {
+		if name, ok := toolNames[callID]; ok {
+			interruptedTools = append(interruptedTools, name)
+		}
+	}
+	missing = append(missing, &api.Message{
+		ID:        uuid.New().String(),
+		Source:    api.MessageSourceAgent,
+		Type:      api.MessageTypeText,
+		Payload:   recoveryMarker(interruptedTools),
+		Timestamp: time.Now(),
+	})
+
+	for _, message := range missing {
+		if err := store.AddChatMessage(message); err != nil {
+			return fmt.Errorf("adding message: %w", err)
+		}
+	}
+	return nil
+}
+
+// recoveryMarker is the text appended to a session recovered after a crash.
+func recoveryMarker(interruptedTools []string) string {
+	text := "Recovered after a crash: the request in progress was interrupted and may not have completed."
+	if len(interruptedTools) > 0 {
+		text += fmt.Sprintf(" These tool calls were running and may or may not have made their changes: %s.", strings.Join(interruptedTools, ", "))
+	}
+	return text
+}
+
+// decodeJournalPayload converts the payload of an event read from the journal, which was
+// decoded as generic maps, into out.
+func decodeJournalPayload(payload any, out any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
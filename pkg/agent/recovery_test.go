@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+)
+
+func TestRecoverInterruptedSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sm, err := sessions.NewSessionManager("filesystem")
+	if err != nil {
+		t.Fatalf("creating session manager: %v", err)
+	}
+	session, err := sm.NewSession(sessions.Metadata{})
+	if err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	session, err = sm.FindSessionByID(session.ID)
+	if err != nil {
+		t.Fatalf("loading session: %v", err)
+	}
+	query := &api.Message{ID: "query", Source: api.MessageSourceUser, Type: api.MessageTypeText, Payload: "scale web to 3", Timestamp: time.Now()}
+	if err := session.ChatMessageStore.AddChatMessage(query); err != nil {
+		t.Fatalf("adding message: %v", err)
+	}
+	// The marker holds our PID, as left by an earlier process with the same PID
+	if err := sm.SetSessionRunning(session.ID, true); err != nil {
+		t.Fatalf("marking session running: %v", err)
+	}
+
+	journalPath := filepath.Join(t.TempDir(), "trace.jsonl")
+	recorder, err := journal.NewJSONLRecorder(journalPath)
+	if err != nil {
+		t.Fatalf("creating recorder: %v", err)
+	}
+	ctx := journal.ContextWithSessionID(context.Background(), session.ID)
+	start := time.Now()
+	for i, event := range []*journal.Event{
+		{Action: journal.ActionAgentMessage, Payload: query},
+		{Action: journal.ActionToolRequest, Payload: tools.ToolRequestEvent{CallID: "1", Name: "kubectl"}},
+		{Action: journal.ActionToolResponse, Payload: tools.ToolResponseEvent{CallID: "1", Response: "deployment.apps/web scaled"}},
+		{Action: journal.ActionToolRequest, Payload: tools.ToolRequestEvent{CallID: "2", Name: "bash"}},
+	} {
+		event.Timestamp = start.Add(time.Duration(i) * time.Second)
+		if err := recorder.Write(ctx, event); err != nil {
+			t.Fatalf("writing event: %v", err)
+		}
+	}
+	recorder.Close()
+
+	recovered, err := RecoverInterruptedSessions(sm, journalPath)
+	if err != nil {
+		t.Fatalf("recovering sessions: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0] != session.ID {
+		t.Fatalf("recovered sessions = %v, want [%s]", recovered, session.ID)
+	}
+
+	session, err = sm.FindSessionByID(session.ID)
+	if err != nil {
+		t.Fatalf("loading session: %v", err)
+	}
+	messages := session.ChatMessageStore.ChatMessages()
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want the query, the tool result and the marker", len(messages))
+	}
+	if messages[1].Type != api.MessageTypeToolCallResponse {
+		t.Errorf("second message type = %s, want the tool result from the journal", messages[1].Type)
+	}
+	marker, _ := messages[2].Payload.(string)
+	if !strings.HasPrefix(marker, "Recovered after a crash") || !strings.Contains(marker, "bash") {
+		t.Errorf("marker = %q, want the crash marker naming the interrupted bash call", marker)
+	}
+	if session.AgentState != api.AgentStateIdle {
+		t.Errorf("agent state = %s, want idle", session.AgentState)
+	}
+
+	interrupted, err := sm.InterruptedSessions()
+	if err != nil {
+		t.Fatalf("listing interrupted sessions: %v", err)
+	}
+	if len(interrupted) != 0 {
+		t.Errorf("sessions still interrupted after recovery: %d", len(interrupted))
+	}
+}
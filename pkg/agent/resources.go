@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/mcp"
+	"k8s.io/klog/v2"
+)
+
+// onMCPResourceUpdate marks a resource as changed, to send its new content with the
+// next query. It is called by the MCP client, which cannot be sent requests until it returns.
+func (a *Agent) onMCPResourceUpdate(resource mcp.Resource) {
+	a.mcpResourcesMu.Lock()
+	defer a.mcpResourcesMu.Unlock()
+	if a.mcpResourcesStale == nil {
+		a.mcpResourcesStale = make(map[mcp.Resource]bool)
+	}
+	a.mcpResourcesStale[resource] = true
+}
+
+// markMCPServerResourcesStale marks the resources of a server as changed, e.g. after
+// it was reconnected and its updates may have been missed.
+func (a *Agent) markMCPServerResourcesStale(server string) {
+	if a.mcpManager == nil {
+		return
+	}
+	for _, resource := range a.mcpManager.Resources() {
+		if resource.Server == server {
+			a.onMCPResourceUpdate(resource)
+		}
+	}
+}
+
+// queryContent returns the content to send the LLM for a query: the piped input and
+// the description of the attached bundle with the first query, the results of the tools the user ran with /run,
+// the configured MCP resources not sent yet in the conversation, and the ones that changed since they were sent,
+// followed by the query.
+func (a *Agent) queryContent(ctx context.Context, query string) []any {
+	var content []any
+	if text := a.pipedInputContext(); text != "" {
+		content = append(content, text)
+	}
+	if text := a.attachmentsContext(); text != "" {
+		content = append(content, text)
+	}
+	if text := a.userRunsContext(); text != "" {
+		content = append(content, text)
+	}
+	for _, text := range a.mcpResourceContext(ctx) {
+		content = append(content, text)
+	}
+	return append(content, query)
+}
+
+// mcpResourceContext reads the resources not sent yet and the ones that changed, and
+// returns their content. Resources that cannot be read are tried again with the next query.
+func (a *Agent) mcpResourceContext(ctx context.Context) []string {
+	if a.mcpManager == nil {
+		return nil
+	}
+
+	a.mcpResourcesMu.Lock()
+	stale := a.mcpResourcesStale
+	a.mcpResourcesStale = nil
+	a.mcpResourcesMu.Unlock()
+
+	if a.mcpResources == nil {
+		a.mcpResources = make(map[mcp.Resource]string)
+	}
+	var texts []string
+	for _, resource := range a.mcpManager.Resources() {
+		previous, sent := a.mcpResources[resource]
+		if sent && !stale[resource] {
+			continue
+		}
+		text, err := a.mcpManager.ReadResource(ctx, resource)
+		if err != nil {
+			klog.Warningf("Failed to read MCP resource %q: %v", resource.ID(), err)
+			if sent {
+				a.onMCPResourceUpdate(resource)
+			}
+			continue
+		}
+		a.mcpResources[resource] = text
+		switch {
+		case !sent:
+			texts = append(texts, fmt.Sprintf("Content of the MCP resource %q:\n\n%s", resource.ID(), text))
+		case text != previous:
+			texts = append(texts, fmt.Sprintf("[Resource updated] The MCP resource %q changed since it was provided earlier in this conversation. "+
+				"Use this content instead of the earlier one:\n\n%s", resource.ID(), text))
+		}
+	}
+	return texts
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/mcp"
+	"k8s.io/klog/v2"
+)
+
+// samplingPreviewLength caps the part of a sampling request shown to the user.
+const samplingPreviewLength = 500
+
+// handleSamplingRequest answers the request of an MCP server for an LLM completion
+// with the model of the agent, once the user approved it.
+//
+// Servers make these requests while handling a tool call, so the agent loop is
+// blocked in DispatchToolCalls and the user is asked from here. Requests made at
+// other times are refused unless the server is approved already.
+func (c *Agent) handleSamplingRequest(ctx context.Context, req *mcp.SamplingRequest) (*mcp.SamplingResult, error) {
+	c.samplingMu.Lock()
+	defer c.samplingMu.Unlock()
+
+	if !req.AutoApprove && !c.samplingApproved[req.Server] {
+		if c.RunOnce || !c.toolsRunning.Load() {
+			return nil, fmt.Errorf("sampling requests of MCP server %q need the user's approval, which can only be asked during its tool calls", req.Server)
+		}
+		approved, err := c.askSamplingApproval(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			return nil, fmt.Errorf("the user declined the sampling request")
+		}
+	}
+
+	klog.V(1).InfoS("Answering MCP sampling request", "server", req.Server, "messages", len(req.Messages), "maxTokens", req.MaxTokens)
+	chat := c.LLM.StartChat(req.SystemPrompt, c.Model)
+	response, err := chat.Send(ctx, samplingPrompt(req.Messages))
+	if err != nil {
+		return nil, fmt.Errorf("calling the LLM: %w", err)
+	}
+
+	var text strings.Builder
+	if candidates := response.Candidates(); len(candidates) > 0 {
+		for _, part := range candidates[0].Parts() {
+			if t, ok := part.AsText(); ok {
+				text.WriteString(t)
+			}
+		}
+	}
+	return &mcp.SamplingResult{Text: text.String(), Model: c.Model}, nil
+}
+
+// askSamplingApproval asks the user whether the server may use the LLM, and
+// remembers servers approved for the rest of the session.
+func (c *Agent) askSamplingApproval(ctx context.Context, req *mcp.SamplingRequest) (bool, error) {
+	var preview string
+	if len(req.Messages) > 0 {
+		preview = req.Messages[len(req.Messages)-1].Text
+		if len(preview) > samplingPreviewLength {
+			preview = preview[:samplingPreviewLength] + "..."
+		}
+	}
+	choiceRequest := &api.UserChoiceRequest{
+		Prompt: fmt.Sprintf("MCP server %q asks to use the LLM, for up to %d tokens:\n\n%s\n\nDo you want to allow it?",
+			req.Server, req.MaxTokens, preview),
+		Options: []api.UserChoiceOption{
+			{Value: "yes", Label: "Yes"},
+			{Value: "yes_and_dont_ask_me_again", Label: "Yes, and don't ask me again for this server"},
+			{Value: "no", Label: "No"},
+		},
+	}
+
+	// The loop is blocked running the tool call, so the answer can be read here
+	prevState := c.AgentState()
+	c.setAgentState(api.AgentStateWaitingForInput)
+	defer c.setAgentState(prevState)
+	c.addMessage(api.MessageSourceAgent, api.MessageTypeUserChoiceRequest, choiceRequest)
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case input := <-c.Input:
+		choice, ok := input.(*api.UserChoiceResponse)
+		if !ok {
+			klog.Warningf("Received unexpected input while asking for sampling approval: %T", input)
+			return false, nil
+		}
+		switch choice.Choice {
+		case 1:
+			return true, nil
+		case 2:
+			c.samplingApproved[req.Server] = true
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+}
+
+// samplingPrompt turns the messages of a sampling request into a single prompt.
+func samplingPrompt(messages []mcp.SamplingMessage) string {
+	if len(messages) == 1 {
+		return messages[0].Text
+	}
+	var sb strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&sb, "%s: %s\n\n", msg.Role, msg.Text)
+	}
+	return sb.String()
+}
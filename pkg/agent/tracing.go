@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer returns the tracer creating the spans of the agentic loop: a span per user
+// request, with a child per iteration, which in turn has children for the LLM call and
+// each tool call. Spans are only exported when a tracer provider is installed. The
+// tracer is looked up on every use, so that spans go to the current global provider.
+func tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer("github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent")
+}
+
+// startRequestSpan starts the span of a new user request. Callers hold requestMu.
+func (c *Agent) startRequestSpan(ctx context.Context) context.Context {
+	c.endRequestSpanLocked()
+	attrs := []attribute.KeyValue{
+		attribute.String("gen_ai.system", c.Provider),
+		attribute.String("gen_ai.request.model", c.Model),
+	}
+	if c.Session != nil {
+		attrs = append(attrs, attribute.String("session.id", c.Session.ID))
+	}
+	ctx, c.requestSpan = tracer().Start(ctx, "kubectl-ai.request", trace.WithAttributes(attrs...))
+	return ctx
+}
+
+// endRequestSpan ends the spans of the current request, once the agent is done with it.
+func (c *Agent) endRequestSpan() {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.endRequestSpanLocked()
+}
+
+func (c *Agent) endRequestSpanLocked() {
+	if c.iterationSpan != nil {
+		c.iterationSpan.End()
+		c.iterationSpan = nil
+		c.iterationCtx = nil
+	}
+	if c.requestSpan != nil {
+		c.requestSpan.End()
+		c.requestSpan = nil
+	}
+}
+
+// startIteration starts the span of an iteration of the agentic loop, ending the
+// previous one, and returns the context for the LLM and tool calls of the iteration.
+func (c *Agent) startIteration(ctx context.Context) context.Context {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	if c.iterationSpan != nil {
+		c.iterationSpan.End()
+	}
+	if c.requestCtx != nil {
+		ctx = c.requestCtx
+	}
+	c.iterationCtx, c.iterationSpan = tracer().Start(ctx, "kubectl-ai.iteration",
+		trace.WithAttributes(attribute.Int("iteration", c.currIteration)))
+	return c.iterationCtx
+}
+
+// startLLMSpan starts the span of a call to the LLM.
+func (c *Agent) startLLMSpan(ctx context.Context) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "gollm.SendStreaming", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("gen_ai.system", c.Provider),
+		attribute.String("gen_ai.request.model", c.Model),
+	))
+}
+
+// startToolSpan starts the span of a tool call.
+func (c *Agent) startToolSpan(ctx context.Context, call ToolCallAnalysis) (context.Context, trace.Span) {
+	executor := c.Sandbox
+	if executor == "" {
+		executor = "local"
+	}
+	return tracer().Start(ctx, "kubectl-ai.tool_call", trace.WithAttributes(
+		attribute.String("tool.name", call.FunctionCall.Name),
+		attribute.String("tool.command", call.ParsedToolCall.Description()),
+		attribute.Bool("tool.modifies_resource", call.ModifiesResourceStr != "no"),
+		attribute.String("tool.executor", executor),
+	))
+}
+
+// endSpan records err, if any, on the span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
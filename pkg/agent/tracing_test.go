@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx := context.Background()
+	a := &Agent{
+		Model:    "test-model",
+		Provider: "test-provider",
+		Session:  &api.Session{ID: "test-session", AgentState: api.AgentStateRunning},
+	}
+
+	a.startRequest(ctx, "list pods")
+	for range 2 {
+		iterCtx := a.startIteration(ctx)
+		_, llmSpan := a.startLLMSpan(iterCtx)
+		endSpan(llmSpan, errors.New("quota exceeded"))
+	}
+	if got := a.requestContext(ctx); got != a.iterationCtx {
+		t.Errorf("requestContext() should return the context of the current iteration")
+	}
+	a.setAgentState(api.AgentStateDone)
+
+	spans := recorder.Ended()
+	byName := map[string][]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+	if len(spans) != 5 || len(byName["kubectl-ai.request"]) != 1 || len(byName["kubectl-ai.iteration"]) != 2 || len(byName["gollm.SendStreaming"]) != 2 {
+		t.Fatalf("ended spans = %v, want a request, two iterations and two LLM calls", byName)
+	}
+
+	request := byName["kubectl-ai.request"][0]
+	for _, iteration := range byName["kubectl-ai.iteration"] {
+		if iteration.Parent().SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("iteration span is not a child of the request span")
+		}
+	}
+	for i, llm := range byName["gollm.SendStreaming"] {
+		if llm.Parent().SpanID() != byName["kubectl-ai.iteration"][i].SpanContext().SpanID() {
+			t.Errorf("LLM span %d is not a child of its iteration span", i)
+		}
+		if llm.Status().Code != codes.Error {
+			t.Errorf("LLM span %d status = %v, want error", i, llm.Status().Code)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ErrorCategory classifies the cause of an error, so that UIs can suggest a fix
+// and evaluations can break failures down.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryUnknown is for errors that do not fit any other category.
+	ErrorCategoryUnknown ErrorCategory = "unknown"
+	// ErrorCategoryLLMQuota is for LLM requests rejected for quota or rate limits.
+	ErrorCategoryLLMQuota ErrorCategory = "llm-quota"
+	// ErrorCategoryLLMSafetyBlock is for LLM responses blocked by safety filters.
+	ErrorCategoryLLMSafetyBlock ErrorCategory = "llm-safety-block"
+	// ErrorCategoryToolTimeout is for tool calls that did not finish in time.
+	ErrorCategoryToolTimeout ErrorCategory = "tool-timeout"
+	// ErrorCategoryPermissionDenied is for operations the cluster or LLM provider refused.
+	ErrorCategoryPermissionDenied ErrorCategory = "permission-denied"
+	// ErrorCategoryKubeconfigInvalid is for a missing or unusable kubeconfig.
+	ErrorCategoryKubeconfigInvalid ErrorCategory = "kubeconfig-invalid"
+	// ErrorCategoryCancelled is for requests cancelled by the user, and tool calls the
+	// user declined to run.
+	ErrorCategoryCancelled ErrorCategory = "cancelled"
+	// ErrorCategoryPolicyRefused is for tool calls refused by the policy of the session,
+	// e.g. read-only or GitOps mode, the allowed namespaces or the trust level of an MCP server.
+	ErrorCategoryPolicyRefused ErrorCategory = "policy-refused"
+)
+
+// ErrorPayload is the payload of MessageTypeError messages.
+type ErrorPayload struct {
+	Category ErrorCategory `json:"category"`
+	Message  string        `json:"message"`
+	// Guidance is an actionable hint for the category, if there is one.
+	Guidance string `json:"guidance,omitempty"`
+}
+
+func (e *ErrorPayload) String() string {
+	return e.Message
+}
+
+// ErrorPayloadFrom returns the payload of an error message. Besides *ErrorPayload, it
+// accepts the plain strings of older sessions and the maps of sessions loaded from disk.
+func ErrorPayloadFrom(payload any) *ErrorPayload {
+	switch p := payload.(type) {
+	case *ErrorPayload:
+		return p
+	case ErrorPayload:
+		return &p
+	case string:
+		return &ErrorPayload{Category: ErrorCategoryUnknown, Message: p}
+	}
+
+	out := &ErrorPayload{}
+	if b, err := json.Marshal(payload); err == nil && json.Unmarshal(b, out) == nil && out.Message != "" {
+		if out.Category == "" {
+			out.Category = ErrorCategoryUnknown
+		}
+		return out
+	}
+	return &ErrorPayload{Category: ErrorCategoryUnknown, Message: fmt.Sprint(payload)}
+}
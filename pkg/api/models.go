@@ -20,10 +20,16 @@ import (
 )
 
 type Session struct {
-	ID               string
-	Name             string
-	ProviderID       string
-	ModelID          string
+	ID         string
+	Name       string
+	ProviderID string
+	ModelID    string
+	// Owner is the user the session belongs to when the web UI serves multiple users.
+	Owner string
+	// Kubeconfig is the kubeconfig the session's agent uses, overriding the default one.
+	Kubeconfig string
+	// Pinned sessions are listed before all others.
+	Pinned           bool
 	Messages         []*Message
 	AgentState       AgentState
 	CreatedAt        time.Time
@@ -44,6 +50,32 @@ const (
 	AgentStateExited          AgentState = "exited"
 )
 
+// AgentEventType is a step of an iteration of the agentic loop.
+type AgentEventType string
+
+const (
+	AgentEventIterationStarted  AgentEventType = "iteration-started"
+	AgentEventLLMCallStarted    AgentEventType = "llm-call-started"
+	AgentEventLLMCallFinished   AgentEventType = "llm-call-finished"
+	AgentEventToolsAnalyzed     AgentEventType = "tools-analyzed"
+	AgentEventApprovalRequested AgentEventType = "approval-requested"
+	AgentEventToolsDispatched   AgentEventType = "tools-dispatched"
+)
+
+// AgentEvent reports what the agent is doing within a request, so that UIs can show more
+// than the agent state.
+type AgentEvent struct {
+	Type      AgentEventType `json:"type"`
+	Iteration int            `json:"iteration"`
+	Timestamp time.Time      `json:"timestamp"`
+	// Tools are the descriptions of the tool calls analyzed, to approve or dispatched.
+	Tools []string `json:"tools,omitempty"`
+	// Duration is how long the LLM call or the tool calls took, for the events ending them.
+	Duration time.Duration `json:"duration,omitempty"`
+	// Error is the error ending the step, if any.
+	Error string `json:"error,omitempty"`
+}
+
 type MessageType string
 
 const (
@@ -55,6 +87,15 @@ const (
 	MessageTypeUserInputResponse  MessageType = "user-input-response"
 	MessageTypeUserChoiceRequest  MessageType = "user-choice-request"
 	MessageTypeUserChoiceResponse MessageType = "user-choice-response"
+	MessageTypeDiff               MessageType = "diff"
+	// MessageTypeThought is the reasoning of the model before its answer, as text. It is
+	// shown to users but not sent back to the model.
+	MessageTypeThought MessageType = "thought"
+	// MessageTypeStatus is a transient status of the agent, as text, such as waiting for
+	// the quota of the provider. It is sent to UIs but not kept in the session.
+	MessageTypeStatus MessageType = "status"
+	// MessageTypePlan is the plan of the agent for a task, as a *Plan.
+	MessageTypePlan MessageType = "plan"
 )
 
 type Message struct {
@@ -76,6 +117,10 @@ const (
 type UserChoiceRequest struct {
 	Prompt  string
 	Options []UserChoiceOption
+	// Commands lists the commands awaiting approval, if the choice is a permission request.
+	Commands []string `json:"Commands,omitempty"`
+	// Justifications are why the model runs each of the Commands, if it is required to say.
+	Justifications []string `json:"Justifications,omitempty"`
 }
 
 type UserChoiceOption struct {
@@ -83,12 +128,43 @@ type UserChoiceOption struct {
 	Value string `json:"value,omitempty"`
 }
 
+// Diff is the payload of a MessageTypeDiff message, showing a change to a file or resource.
+type Diff struct {
+	// Title describes what changed, e.g. the resource or file name.
+	Title  string `json:"title,omitempty"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Plan is the payload of a MessageTypePlan message, the steps the agent intends to take.
+type Plan struct {
+	Title string     `json:"title,omitempty"`
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is a step of a Plan.
+type PlanStep struct {
+	Description string `json:"description"`
+	// Status is pending (the default), in-progress, done or failed.
+	Status string `json:"status,omitempty"`
+}
+
 type UserChoiceResponse struct {
 	Choice int `json:"choice"`
+	// Selections approves (true) or declines (false) each of the request's Commands
+	// individually. It is only consulted when the choice is to proceed; if empty, all
+	// commands are approved.
+	Selections []bool `json:"selections,omitempty"`
 }
 
 type UserInputResponse struct {
 	Query string `json:"query"`
+	// EditMessageID is the earlier message of the user that the query replaces, if any.
+	// The conversation is truncated before that message and regenerated from the query.
+	EditMessageID string `json:"editMessageID,omitempty"`
+	// QuotedMessageIDs are earlier messages or tool outputs quoted in the query. Their full
+	// content is added to the query sent to the model.
+	QuotedMessageIDs []string `json:"quotedMessageIDs,omitempty"`
 }
 
 // MCPStatus represents the overall status of MCP servers and tools
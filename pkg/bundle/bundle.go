@@ -0,0 +1,372 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bundle imports support bundles, e.g. the output of kubectl cluster-info dump,
+// so that the agent can troubleshoot a cluster from an offline snapshot of its resources
+// and logs.
+package bundle
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// manifestFile lists the files and resources of a bundle, in its directory.
+const manifestFile = "manifest.json"
+
+// maxReadSize limits the content of files returned by ReadFile, keeping their end.
+const maxReadSize = 64 * 1024
+
+// File types of bundles.
+const (
+	FileTypeManifest = "manifest"
+	FileTypeLog      = "log"
+	FileTypeOther    = "other"
+)
+
+// Bundle is an imported support bundle.
+type Bundle struct {
+	// Dir is where the bundle was imported.
+	Dir string `json:"-"`
+	// Sources are the paths the bundle was imported from.
+	Sources   []string   `json:"sources"`
+	Files     []File     `json:"files"`
+	Resources []Resource `json:"resources"`
+}
+
+// File is a file of a bundle.
+type File struct {
+	// Path is relative to the directory of the bundle, with forward slashes.
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Type is manifest for YAML and JSON files, log for .log and .txt files, other otherwise.
+	Type string `json:"type"`
+}
+
+// Resource is a Kubernetes resource found in the manifests of a bundle.
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// File is the path of the manifest the resource is in.
+	File string `json:"file"`
+}
+
+// index lists the files of dir and the resources of its manifests.
+func index(dir string) (*Bundle, error) {
+	b := &Bundle{Dir: dir}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == manifestFile {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		file := File{Path: rel, Size: info.Size(), Type: fileType(rel)}
+		if file.Type == FileTypeManifest {
+			objects, err := readObjects(p)
+			if err != nil {
+				// Not all YAML and JSON files are Kubernetes manifests
+				file.Type = FileTypeOther
+			}
+			for _, obj := range objects {
+				b.Resources = append(b.Resources, Resource{Kind: obj.kind(), Namespace: obj.namespace(), Name: obj.name(), File: rel})
+			}
+		}
+		b.Files = append(b.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("indexing bundle: %w", err)
+	}
+	return b, nil
+}
+
+func (b *Bundle) writeManifest() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling bundle manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(b.Dir, manifestFile), data, 0o644); err != nil {
+		return fmt.Errorf("writing bundle manifest: %w", err)
+	}
+	return nil
+}
+
+func fileType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return FileTypeManifest
+	case ".log", ".txt":
+		return FileTypeLog
+	default:
+		return FileTypeOther
+	}
+}
+
+// object is a Kubernetes object of a manifest.
+type object map[string]any
+
+func (o object) kind() string {
+	kind, _ := o["kind"].(string)
+	return kind
+}
+
+func (o object) metadata(field string) string {
+	metadata, _ := o["metadata"].(map[string]any)
+	value, _ := metadata[field].(string)
+	return value
+}
+
+func (o object) name() string      { return o.metadata("name") }
+func (o object) namespace() string { return o.metadata("namespace") }
+
+// readObjects reads the objects of a YAML or JSON file, expanding lists: kubectl
+// cluster-info dump writes a list per kind, e.g. a PodList of the pods of a namespace.
+func readObjects(p string) ([]object, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var objects []object
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bufio.NewReader(f), 4096)
+	for {
+		var obj object
+		if err := decoder.Decode(&obj); errors.Is(err, io.EOF) {
+			return objects, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if obj == nil || obj.kind() == "" {
+			continue
+		}
+		items, isList := obj["items"].([]any)
+		if !isList {
+			objects = append(objects, obj)
+			continue
+		}
+		itemKind := strings.TrimSuffix(obj.kind(), "List")
+		for _, item := range items {
+			itemObj, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			o := object(itemObj)
+			if o.kind() == "" && itemKind != "" {
+				o["kind"] = itemKind
+			}
+			objects = append(objects, o)
+		}
+	}
+}
+
+// sameKind compares kinds as the LLM may name them: Pod, pod or pods.
+func sameKind(a, b string) bool {
+	normalize := func(kind string) string { return strings.TrimSuffix(strings.ToLower(kind), "s") }
+	return normalize(a) == normalize(b)
+}
+
+// FindResources returns the resources of a kind and namespace, or all if they are empty.
+func (b *Bundle) FindResources(kind, namespace string) []Resource {
+	var found []Resource
+	for _, r := range b.Resources {
+		if (kind == "" || sameKind(r.Kind, kind)) && (namespace == "" || r.Namespace == namespace) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// GetResource returns the manifest of a resource as YAML. namespace can be empty if
+// the name is unique in the bundle.
+func (b *Bundle) GetResource(kind, namespace, name string) (string, error) {
+	var matches []Resource
+	for _, r := range b.FindResources(kind, namespace) {
+		if r.Name == name {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%s %q not found in the bundle", kind, name)
+	case 1:
+	default:
+		var namespaces []string
+		for _, r := range matches {
+			namespaces = append(namespaces, r.Namespace)
+		}
+		return "", fmt.Errorf("%s %q is in several namespaces of the bundle, pass one of: %s", kind, name, strings.Join(namespaces, ", "))
+	}
+	match := matches[0]
+	objects, err := readObjects(filepath.Join(b.Dir, filepath.FromSlash(match.File)))
+	if err != nil {
+		return "", fmt.Errorf("reading %q: %w", match.File, err)
+	}
+	for _, obj := range objects {
+		if obj.kind() == match.Kind && obj.namespace() == match.Namespace && obj.name() == match.Name {
+			out, err := yaml.Marshal(obj)
+			if err != nil {
+				return "", fmt.Errorf("marshalling %s %q: %w", kind, name, err)
+			}
+			return string(out), nil
+		}
+	}
+	return "", fmt.Errorf("%s %q not found in %q", kind, name, match.File)
+}
+
+// ReadFile returns the last lines of a file of the bundle, all if tail is 0, within
+// maxReadSize.
+func (b *Bundle) ReadFile(name string, tail int) (string, error) {
+	p, err := b.path(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("reading %q: %w", name, err)
+	}
+	content := string(data)
+	if tail > 0 {
+		lines := strings.SplitAfter(strings.TrimSuffix(content, "\n"), "\n")
+		if len(lines) > tail {
+			content = strings.Join(lines[len(lines)-tail:], "")
+		}
+	}
+	if len(content) > maxReadSize {
+		content = fmt.Sprintf("[... %d bytes omitted ...]\n", len(content)-maxReadSize) + strings.ToValidUTF8(content[len(content)-maxReadSize:], "")
+	}
+	return content, nil
+}
+
+// Match is a line of a file matching a pattern.
+type Match struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Grep returns the lines matching the regular expression pattern, in the files matching
+// the glob pattern (all if empty), and whether there were more than limit.
+func (b *Bundle) Grep(pattern, glob string, limit int) ([]Match, bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid pattern: %w", err)
+	}
+	var matches []Match
+	for _, file := range b.Files {
+		if glob != "" {
+			if ok, _ := path.Match(glob, file.Path); !ok {
+				if ok, _ := path.Match(glob, path.Base(file.Path)); !ok {
+					continue
+				}
+			}
+		}
+		more, err := grepFile(filepath.Join(b.Dir, filepath.FromSlash(file.Path)), file.Path, re, limit, &matches)
+		if err != nil {
+			return nil, false, err
+		}
+		if more {
+			return matches, true, nil
+		}
+	}
+	return matches, false, nil
+}
+
+func grepFile(p, name string, re *regexp.Regexp, limit int, matches *[]Match) (bool, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return false, fmt.Errorf("reading %q: %w", name, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if !re.Match(scanner.Bytes()) {
+			continue
+		}
+		if len(*matches) == limit {
+			return true, nil
+		}
+		*matches = append(*matches, Match{File: name, Line: line, Text: scanner.Text()})
+	}
+	// Lines too long to scan are skipped with the rest of the file
+	if err := scanner.Err(); err != nil && !errors.Is(err, bufio.ErrTooLong) {
+		return false, fmt.Errorf("reading %q: %w", name, err)
+	}
+	return false, nil
+}
+
+// path returns the path of a file of the bundle, refusing the others.
+func (b *Bundle) path(name string) (string, error) {
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if !slices.ContainsFunc(b.Files, func(f File) bool { return f.Path == name }) {
+		return "", fmt.Errorf("file %q is not in the bundle", name)
+	}
+	return filepath.Join(b.Dir, filepath.FromSlash(name)), nil
+}
+
+// Summary describes the content of the bundle in a few lines.
+func (b *Bundle) Summary() string {
+	kinds := map[string]int{}
+	var namespaces []string
+	for _, r := range b.Resources {
+		kinds[r.Kind]++
+		if r.Namespace != "" && !slices.Contains(namespaces, r.Namespace) {
+			namespaces = append(namespaces, r.Namespace)
+		}
+	}
+	var kindCounts []string
+	for kind, n := range kinds {
+		kindCounts = append(kindCounts, fmt.Sprintf("%d %s", n, kind))
+	}
+	slices.Sort(kindCounts)
+	slices.Sort(namespaces)
+	logs := 0
+	for _, f := range b.Files {
+		if f.Type == FileTypeLog {
+			logs++
+		}
+	}
+	summary := fmt.Sprintf("%d files, %d of them logs, and %d resources", len(b.Files), logs, len(b.Resources))
+	if len(kindCounts) > 0 {
+		summary += ": " + strings.Join(kindCounts, ", ")
+	}
+	if len(namespaces) > 0 {
+		summary += "\nNamespaces: " + strings.Join(namespaces, ", ")
+	}
+	return summary
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dumpFiles look like the output of kubectl cluster-info dump --output-directory.
+var dumpFiles = map[string]string{
+	"shop/pods.json": `{"kind": "PodList", "apiVersion": "v1", "items": [
+		{"metadata": {"name": "web-0", "namespace": "shop"}, "status": {"phase": "Running"}},
+		{"metadata": {"name": "db-0", "namespace": "shop"}, "status": {"phase": "Pending"}}
+	]}`,
+	"shop/web-0/logs.txt":   "starting\nlistening on :8080\nerror: connection refused to db-0\n",
+	"kube-system/dns.yaml":  "apiVersion: v1\nkind: Service\nmetadata:\n  name: kube-dns\n  namespace: kube-system\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: coredns\n  namespace: kube-system\n",
+	"kube-system/notes.txt": "collected by on-call\n",
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeTarGz(t *testing.T, p string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, filepath.Join(src, "dump"), dumpFiles)
+	writeTarGz(t, filepath.Join(src, "dump.tar.gz"), dumpFiles)
+
+	dir := filepath.Join(t.TempDir(), "bundle")
+	b, err := Import(dir, filepath.Join(src, "dump"), filepath.Join(src, "dump.tar.gz"))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(b.Files) != 8 || len(b.Resources) != 8 {
+		t.Fatalf("imported %d files and %d resources, want 8 of each: %+v", len(b.Files), len(b.Resources), b.Resources)
+	}
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err != nil {
+		t.Errorf("manifest was not written: %v", err)
+	}
+
+	if pods := b.FindResources("pods", "shop"); len(pods) != 4 || pods[0].Kind != "Pod" {
+		t.Errorf("FindResources(pods, shop) = %+v, want the pods of both copies", pods)
+	}
+	// The copies of the bundle are in different directories, but the same namespaces
+	if _, err := b.GetResource("Pod", "shop", "db-0"); err == nil || !strings.Contains(err.Error(), "several") {
+		t.Errorf("GetResource() of a pod in both copies error = %v", err)
+	}
+
+	logs, err := b.ReadFile("dump/shop/web-0/logs.txt", 1)
+	if err != nil || logs != "error: connection refused to db-0" {
+		t.Errorf("ReadFile() = %q, %v, want the last line", logs, err)
+	}
+	if _, err := b.ReadFile("../../etc/passwd", 0); err == nil {
+		t.Errorf("ReadFile() of a file outside of the bundle succeeded")
+	}
+
+	matches, truncated, err := b.Grep("(?i)error", "*.txt", 1)
+	if err != nil || len(matches) != 1 || !truncated || matches[0].Line != 3 {
+		t.Errorf("Grep() = %+v, %v, %v, want the first of two matches", matches, truncated, err)
+	}
+}
+
+func TestGetResource(t *testing.T) {
+	src := t.TempDir()
+	writeFiles(t, src, dumpFiles)
+	b, err := Import(t.TempDir(), src)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	manifest, err := b.GetResource("pod", "", "db-0")
+	if err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if !strings.Contains(manifest, "phase: Pending") || !strings.Contains(manifest, "kind: Pod") {
+		t.Errorf("GetResource() = %q, want the pod with its status", manifest)
+	}
+	if !strings.Contains(b.Summary(), "Namespaces: kube-system, shop") {
+		t.Errorf("Summary() = %q", b.Summary())
+	}
+}
+
+func TestImportRefusesEntriesOutsideArchive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+	writeTarGz(t, archive, map[string]string{"../escaped.txt": "gotcha"})
+	if _, err := Import(t.TempDir(), archive); err == nil {
+		t.Errorf("Import() of an archive with an entry outside of it succeeded")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxImportSize limits the size of the files imported, archives being extracted.
+const maxImportSize = 1 << 30
+
+// importer copies sources to a directory, within maxImportSize.
+type importer struct {
+	remaining int64
+}
+
+// Import copies sources, directories or files, into dir and indexes them. Archives
+// (.tar, .tar.gz, .tgz and .zip) are extracted. Each source is in its own directory
+// of dir, and a manifest.json lists the files and resources of the bundle.
+func Import(dir string, sources ...string) (*Bundle, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating bundle directory: %w", err)
+	}
+	im := &importer{remaining: maxImportSize}
+	used := map[string]bool{manifestFile: true}
+	for _, source := range sources {
+		name := archiveBase(filepath.Base(filepath.Clean(source)))
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d", archiveBase(filepath.Base(source)), i)
+		}
+		used[name] = true
+		if err := im.importSource(source, filepath.Join(dir, name)); err != nil {
+			return nil, fmt.Errorf("importing %q: %w", source, err)
+		}
+	}
+
+	b, err := index(dir)
+	if err != nil {
+		return nil, err
+	}
+	b.Sources = sources
+	if err := b.writeManifest(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (im *importer) importSource(source, dest string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return im.copyDir(source, dest)
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("reading gzip archive: %w", err)
+		}
+		defer gz.Close()
+		return im.extractTar(gz, dest)
+	case strings.HasSuffix(lower, ".tar"):
+		return im.extractTar(f, dest)
+	case strings.HasSuffix(lower, ".zip"):
+		return im.extractZip(f, info.Size(), dest)
+	default:
+		return im.copyFile(f, filepath.Join(dest, filepath.Base(source)))
+	}
+}
+
+func (im *importer) copyDir(source, dest string) error {
+	return filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			// Directories are created with their files; symlinks are not followed
+			return nil
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return im.copyFile(f, filepath.Join(dest, rel))
+	})
+}
+
+func (im *importer) extractTar(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		path, err := safeJoin(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := im.copyFile(tr, path); err != nil {
+			return err
+		}
+	}
+}
+
+func (im *importer) extractZip(r io.ReaderAt, size int64, dest string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("reading zip archive: %w", err)
+	}
+	for _, file := range zr.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		path, err := safeJoin(dest, file.Name)
+		if err != nil {
+			return err
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("reading %q of zip archive: %w", file.Name, err)
+		}
+		err = im.copyFile(rc, path)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *importer) copyFile(r io.Reader, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, im.remaining+1))
+	im.remaining -= n
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if im.remaining < 0 {
+		return fmt.Errorf("the bundle is larger than %d MiB", maxImportSize>>20)
+	}
+	return nil
+}
+
+// safeJoin joins the name of an archive entry to dest, refusing names outside of it.
+func safeJoin(dest, name string) (string, error) {
+	path := filepath.Join(dest, name)
+	if !strings.HasPrefix(path, filepath.Clean(dest)+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q is outside of the archive", name)
+	}
+	return path, nil
+}
+
+// archiveBase strips the extension of archives from name.
+func archiveBase(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gitops proposes changes to a cluster as commits to the Git repository its
+// manifests are deployed from, and as pull requests, instead of applying them.
+package gitops
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Config configures where the manifests of resources are in the repository, and how
+// changes are proposed.
+type Config struct {
+	// RepoPath is the path of a local clone of the repository.
+	RepoPath string `json:"repoPath"`
+	// BaseBranch is the branch changes are based on, and pull requests target.
+	// Defaults to the current branch of the clone.
+	BaseBranch string `json:"baseBranch,omitempty"`
+	// BranchPrefix is prepended to the names of the branches of changes. Defaults to "kubectl-ai/".
+	BranchPrefix string `json:"branchPrefix,omitempty"`
+	// Remote is the remote branches are pushed to, to open pull requests. Defaults to "origin".
+	Remote string `json:"remote,omitempty"`
+	// Paths map resources to the directories of their manifests. The first match is used.
+	Paths []PathMapping `json:"paths"`
+	// PullRequest opens pull requests for the changes, if set. Otherwise they are only committed.
+	PullRequest *PullRequestConfig `json:"pullRequest,omitempty"`
+}
+
+// PathMapping maps resources, by namespace and kind, to the directory of their manifests.
+type PathMapping struct {
+	// Namespace and Kind select resources with glob patterns, e.g. "team-*". Empty matches all.
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	// Path is the directory relative to the root of the repository. It may contain the
+	// placeholders {namespace}, {kind} (in lower case) and {name} of the resource.
+	// Manifests of the resource are looked up in its YAML files, and new resources
+	// are written to {kind}-{name}.yaml in it.
+	Path string `json:"path"`
+}
+
+// PullRequestConfig configures the API pull requests are opened with.
+type PullRequestConfig struct {
+	// Provider is "github" or "gitlab".
+	Provider string `json:"provider"`
+	// Repository is owner/name on GitHub, or the path or ID of the project on GitLab.
+	Repository string `json:"repository"`
+	// APIURL is the API endpoint, for GitHub Enterprise or self-managed GitLab.
+	APIURL string `json:"apiURL,omitempty"`
+	// TokenEnv is the environment variable with the API token.
+	// Defaults to GITHUB_TOKEN or GITLAB_TOKEN.
+	TokenEnv string `json:"tokenEnv,omitempty"`
+}
+
+// Validate checks the configuration.
+func (c *Config) Validate() error {
+	if c.RepoPath == "" {
+		return fmt.Errorf("repoPath is required")
+	}
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("at least one path mapping is required")
+	}
+	for i, mapping := range c.Paths {
+		if mapping.Path == "" {
+			return fmt.Errorf("path of mapping %d is required", i)
+		}
+		if strings.HasPrefix(mapping.Path, "/") || strings.Contains(mapping.Path, "..") {
+			return fmt.Errorf("path %q of mapping %d must be relative to the repository, within it", mapping.Path, i)
+		}
+	}
+	if pr := c.PullRequest; pr != nil {
+		if pr.Provider != ProviderGitHub && pr.Provider != ProviderGitLab {
+			return fmt.Errorf("pull request provider %q is not supported, supported providers: %s, %s", pr.Provider, ProviderGitHub, ProviderGitLab)
+		}
+		if pr.Repository == "" {
+			return fmt.Errorf("pull request repository is required")
+		}
+	}
+	return nil
+}
+
+// dir returns the directory of the manifests of a resource, or false if no mapping matches.
+func (c *Config) dir(namespace, kind, name string) (string, bool) {
+	for _, mapping := range c.Paths {
+		if !matches(mapping.Namespace, namespace) || !matches(mapping.Kind, kind) {
+			continue
+		}
+		dir := strings.NewReplacer("{namespace}", namespace, "{kind}", strings.ToLower(kind), "{name}", name).Replace(mapping.Path)
+		return path.Clean(dir), true
+	}
+	return "", false
+}
+
+func matches(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
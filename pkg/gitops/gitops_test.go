@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const webManifests = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# the web frontend
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`
+
+// newTestRepo creates a repository with the manifests of the web app in apps/prod.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		if _, err := git(context.Background(), repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "--quiet", "--initial-branch", "main")
+	run("config", "user.name", "test")
+	run("config", "user.email", "test@example.com")
+	if err := os.MkdirAll(filepath.Join(repo, "apps", "prod"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "apps", "prod", "web.yaml"), []byte(webManifests), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "--quiet", "-m", "initial")
+	return repo
+}
+
+func newTestProposer(t *testing.T, config Config) *Proposer {
+	t.Helper()
+	p, err := NewProposer(config)
+	if err != nil {
+		t.Fatalf("NewProposer() error = %v", err)
+	}
+	p.now = func() time.Time { return time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC) }
+	return p
+}
+
+func TestPropose(t *testing.T) {
+	repo := newTestRepo(t)
+	p := newTestProposer(t, Config{RepoPath: repo, Paths: []PathMapping{{Namespace: "prod", Path: "apps/{namespace}"}}})
+
+	proposal, err := p.Propose(context.Background(), Change{
+		Title: "Scale web to 3 replicas",
+		Manifests: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: prod
+`,
+	})
+	if err != nil {
+		t.Fatalf("Propose() error = %v", err)
+	}
+	if proposal.Branch != "kubectl-ai/scale-web-to-3-replicas-20250701-120000" {
+		t.Errorf("branch = %q", proposal.Branch)
+	}
+	if strings.Join(proposal.Files, ",") != "apps/prod/web.yaml,apps/prod/configmap-web-config.yaml" {
+		t.Errorf("files = %v", proposal.Files)
+	}
+
+	// The Deployment is replaced in its file, the Service is kept as is
+	web, err := git(context.Background(), repo, "show", proposal.Branch+":apps/prod/web.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(web, "  ports:\n  - port: 80\n---\n") || !strings.Contains(web, "replicas: 3") || strings.Contains(web, "replicas: 1") {
+		t.Errorf("web.yaml on the branch = %q", web)
+	}
+	// The working tree of the clone is untouched
+	if content, _ := os.ReadFile(filepath.Join(repo, "apps", "prod", "web.yaml")); string(content) != webManifests {
+		t.Errorf("web.yaml in the working tree changed to %q", content)
+	}
+	if branch, _ := git(context.Background(), repo, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("current branch = %q, want main", branch)
+	}
+}
+
+func TestProposeWithoutPath(t *testing.T) {
+	repo := newTestRepo(t)
+	p := newTestProposer(t, Config{RepoPath: repo, Paths: []PathMapping{{Namespace: "prod", Path: "apps/prod"}, {Kind: "Service", Path: "apps/prod"}}})
+
+	_, err := p.Propose(context.Background(), Change{Title: "Scale", Manifests: "kind: Deployment\nmetadata:\n  name: web\n  namespace: dev\n"})
+	if err == nil || !strings.Contains(err.Error(), "no path of the GitOps config matches") {
+		t.Errorf("Propose() error = %v, want no matching path", err)
+	}
+	// Nothing to change, and the branch is not left behind
+	_, err = p.Propose(context.Background(), Change{Title: "Noop", Manifests: "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  ports:\n  - port: 80\n"})
+	if err == nil || !strings.Contains(err.Error(), "nothing to change") {
+		t.Errorf("Propose() error = %v, want nothing to change", err)
+	}
+	if branches, _ := git(context.Background(), repo, "branch", "--list", "kubectl-ai/*"); branches != "" {
+		t.Errorf("branches left behind: %q", branches)
+	}
+}
+
+func TestProposePullRequest(t *testing.T) {
+	repo := newTestRepo(t)
+	remote := t.TempDir()
+	if _, err := git(context.Background(), remote, "init", "--quiet", "--bare"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git(context.Background(), repo, "remote", "add", "origin", remote); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/platform/pulls" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("request to %s with authorization %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://github.com/acme/platform/pull/7"}`))
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	p := newTestProposer(t, Config{
+		RepoPath:    repo,
+		BaseBranch:  "main",
+		Paths:       []PathMapping{{Path: "apps/prod"}},
+		PullRequest: &PullRequestConfig{Provider: ProviderGitHub, Repository: "acme/platform", APIURL: server.URL},
+	})
+	proposal, err := p.Propose(context.Background(), Change{
+		Title:       "Scale web",
+		Description: "Pods are CPU throttled.",
+		Manifests:   "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 2\n",
+	})
+	if err != nil {
+		t.Fatalf("Propose() error = %v", err)
+	}
+	if proposal.PullRequestURL != "https://github.com/acme/platform/pull/7" {
+		t.Errorf("pull request URL = %q", proposal.PullRequestURL)
+	}
+	if got["head"] != proposal.Branch || got["base"] != "main" || got["body"] != "Pods are CPU throttled." {
+		t.Errorf("pull request = %v", got)
+	}
+	if _, err := git(context.Background(), remote, "rev-parse", "--verify", proposal.Branch); err != nil {
+		t.Errorf("branch was not pushed: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// resource is a manifest of a Kubernetes resource, as written in a YAML document.
+type resource struct {
+	Kind      string
+	Name      string
+	Namespace string
+	// Text is the YAML document, ending with a newline.
+	Text string
+}
+
+type objectMeta struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+// manifestFile is a YAML file split in documents, keeping its text as is.
+type manifestFile struct {
+	docs []string
+	// separators[i] is the separator line between docs[i] and docs[i+1].
+	separators []string
+}
+
+func parseManifestFile(content string) *manifestFile {
+	f := &manifestFile{}
+	var doc strings.Builder
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed == "---" || strings.HasPrefix(trimmed, "--- ") {
+			f.docs = append(f.docs, doc.String())
+			f.separators = append(f.separators, line)
+			doc.Reset()
+			continue
+		}
+		doc.WriteString(line)
+	}
+	f.docs = append(f.docs, doc.String())
+	return f
+}
+
+func (f *manifestFile) String() string {
+	var b strings.Builder
+	for i, doc := range f.docs {
+		b.WriteString(doc)
+		if i < len(f.separators) {
+			b.WriteString(f.separators[i])
+		}
+	}
+	return b.String()
+}
+
+// find returns the index of the document of the resource, or -1. Documents without a
+// namespace match resources of any namespace, as the namespace is often set by tooling.
+func (f *manifestFile) find(r *resource) int {
+	for i, doc := range f.docs {
+		meta, err := parseObjectMeta(doc)
+		if err != nil || meta == nil {
+			continue
+		}
+		if meta.Kind == r.Kind && meta.Metadata.Name == r.Name && (meta.Metadata.Namespace == "" || meta.Metadata.Namespace == r.Namespace) {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseObjectMeta(doc string) (*objectMeta, error) {
+	if strings.TrimSpace(doc) == "" {
+		return nil, nil
+	}
+	var meta objectMeta
+	if err := yaml.Unmarshal([]byte(doc), &meta); err != nil {
+		return nil, err
+	}
+	if meta.Kind == "" {
+		return nil, nil
+	}
+	return &meta, nil
+}
+
+// parseResources splits manifests in the resources they define.
+func parseResources(manifests string) ([]*resource, error) {
+	var resources []*resource
+	for _, doc := range parseManifestFile(manifests).docs {
+		meta, err := parseObjectMeta(doc)
+		if err != nil {
+			return nil, fmt.Errorf("parsing manifest: %w", err)
+		}
+		if meta == nil {
+			continue
+		}
+		r := &resource{Kind: meta.Kind, Name: meta.Metadata.Name, Namespace: meta.Metadata.Namespace, Text: doc}
+		if r.Name == "" {
+			return nil, fmt.Errorf("manifest of a %s has no metadata.name", r.Kind)
+		}
+		for _, value := range []string{r.Kind, r.Name, r.Namespace} {
+			if strings.ContainsAny(value, `/\`) || strings.Contains(value, "..") {
+				return nil, fmt.Errorf("invalid kind, name or namespace %q", value)
+			}
+		}
+		if !strings.HasSuffix(r.Text, "\n") {
+			r.Text += "\n"
+		}
+		resources = append(resources, r)
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("no Kubernetes resource found in the manifests")
+	}
+	return resources, nil
+}
+
+// writeResource replaces the manifest of r in the YAML files of dir, or writes it to a new
+// file if it is not found. It returns the path of the file, relative to root.
+func writeResource(root, dir string, r *resource) (string, error) {
+	absDir := filepath.Join(root, filepath.FromSlash(dir))
+	var found string
+	var file *manifestFile
+	err := filepath.WalkDir(absDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || (filepath.Ext(p) != ".yaml" && filepath.Ext(p) != ".yml") {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		f := parseManifestFile(string(content))
+		if i := f.find(r); i >= 0 {
+			f.docs[i] = r.Text
+			found, file = p, f
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("looking up the manifest of %s %s: %w", r.Kind, r.Name, err)
+	}
+
+	content := r.Text
+	if found == "" {
+		found = filepath.Join(absDir, strings.ToLower(r.Kind)+"-"+r.Name+".yaml")
+		if err := os.MkdirAll(absDir, 0o755); err != nil {
+			return "", fmt.Errorf("creating directory %q: %w", dir, err)
+		}
+	} else {
+		content = file.String()
+	}
+	if err := os.WriteFile(found, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("writing manifest: %w", err)
+	}
+	rel, err := filepath.Rel(root, found)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Change is a change to propose.
+type Change struct {
+	// Title is the subject of the commit and the title of the pull request.
+	Title string
+	// Description is the body of the commit message and of the pull request.
+	Description string
+	// Manifests are the full YAML manifests of the resources, as they should be.
+	Manifests string
+}
+
+// Proposal is where a change was proposed.
+type Proposal struct {
+	Branch string   `json:"branch"`
+	Commit string   `json:"commit"`
+	Files  []string `json:"files"`
+	// PullRequestURL is the pull request of the change, if pull requests are configured.
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+}
+
+// Proposer proposes changes as commits to a branch of a local clone, and pull requests.
+type Proposer struct {
+	config     Config
+	httpClient *http.Client
+	// now returns the current time, to name branches.
+	now func() time.Time
+}
+
+// NewProposer returns a Proposer, after checking that config.RepoPath is a Git repository.
+func NewProposer(config Config) (*Proposer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid GitOps config: %w", err)
+	}
+	repoPath, err := expandHome(config.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	config.RepoPath = repoPath
+	if config.BranchPrefix == "" {
+		config.BranchPrefix = "kubectl-ai/"
+	}
+	if config.Remote == "" {
+		config.Remote = "origin"
+	}
+	if _, err := git(context.Background(), repoPath, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("repoPath %q is not a Git repository: %w", repoPath, err)
+	}
+	return &Proposer{config: config, httpClient: http.DefaultClient, now: time.Now}, nil
+}
+
+func expandHome(p string) (string, error) {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting user home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~")), nil
+}
+
+// Propose writes the manifests of the change to their files in a new branch, commits them,
+// and opens a pull request if configured. The working tree of the clone is not touched:
+// the branch is checked out in a temporary worktree.
+func (p *Proposer) Propose(ctx context.Context, change Change) (*Proposal, error) {
+	if strings.TrimSpace(change.Title) == "" {
+		return nil, fmt.Errorf("the change needs a title")
+	}
+	resources, err := parseResources(change.Manifests)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, len(resources))
+	for i, r := range resources {
+		dir, ok := p.config.dir(r.Namespace, r.Kind, r.Name)
+		if !ok {
+			return nil, fmt.Errorf("no path of the GitOps config matches %s %s in namespace %q", r.Kind, r.Name, r.Namespace)
+		}
+		dirs[i] = dir
+	}
+
+	base := p.config.BaseBranch
+	if base == "" {
+		base, err = git(ctx, p.config.RepoPath, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("getting the current branch: %w", err)
+		}
+	}
+	branch := p.config.BranchPrefix + slug(change.Title) + "-" + p.now().Format("20060102-150405")
+
+	worktree, err := os.MkdirTemp("", "kubectl-ai-gitops-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating worktree directory: %w", err)
+	}
+	defer os.RemoveAll(worktree)
+	if _, err := git(ctx, p.config.RepoPath, "worktree", "add", "--quiet", "-b", branch, worktree, base); err != nil {
+		return nil, fmt.Errorf("creating branch %q from %q: %w", branch, base, err)
+	}
+	committed := false
+	defer func() {
+		if _, err := git(context.Background(), p.config.RepoPath, "worktree", "remove", "--force", worktree); err != nil {
+			klog.Warningf("Failed to remove GitOps worktree %q: %v", worktree, err)
+		}
+		// Do not leave branches of changes that failed behind
+		if !committed {
+			if _, err := git(context.Background(), p.config.RepoPath, "branch", "-D", branch); err != nil {
+				klog.Warningf("Failed to delete GitOps branch %q: %v", branch, err)
+			}
+		}
+	}()
+
+	proposal := &Proposal{Branch: branch}
+	for i, r := range resources {
+		file, err := writeResource(worktree, dirs[i], r)
+		if err != nil {
+			return nil, err
+		}
+		proposal.Files = append(proposal.Files, file)
+	}
+	if _, err := git(ctx, worktree, append([]string{"add", "--"}, proposal.Files...)...); err != nil {
+		return nil, fmt.Errorf("staging the manifests: %w", err)
+	}
+	if status, err := git(ctx, worktree, "status", "--porcelain"); err == nil && status == "" {
+		return nil, fmt.Errorf("the manifests are already up to date in %q, there is nothing to change", base)
+	}
+	message := change.Title
+	if change.Description != "" {
+		message += "\n\n" + change.Description
+	}
+	if _, err := git(ctx, worktree, "commit", "--quiet", "-m", message); err != nil {
+		return nil, fmt.Errorf("committing the change: %w", err)
+	}
+	committed = true
+	if proposal.Commit, err = git(ctx, worktree, "rev-parse", "HEAD"); err != nil {
+		return nil, err
+	}
+
+	if p.config.PullRequest == nil {
+		return proposal, nil
+	}
+	if _, err := git(ctx, worktree, "push", "--quiet", "--set-upstream", p.config.Remote, branch); err != nil {
+		return nil, fmt.Errorf("pushing branch %q: %w", branch, err)
+	}
+	proposal.PullRequestURL, err = p.openPullRequest(ctx, branch, base, change)
+	if err != nil {
+		return nil, fmt.Errorf("branch %q was pushed, but the pull request could not be opened: %w", branch, err)
+	}
+	return proposal, nil
+}
+
+// git runs a git command in dir, and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug returns the first words of title, in a form usable in branch names.
+func slug(title string) string {
+	s := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	if s == "" {
+		s = "change"
+	}
+	return s
+}
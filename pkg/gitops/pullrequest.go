@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Supported pull request providers
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// openPullRequest opens a pull request (a merge request on GitLab) from branch to base,
+// and returns its URL.
+func (p *Proposer) openPullRequest(ctx context.Context, branch, base string, change Change) (string, error) {
+	config := p.config.PullRequest
+	tokenEnv := config.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = strings.ToUpper(config.Provider) + "_TOKEN"
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return "", fmt.Errorf("$%s is not set", tokenEnv)
+	}
+
+	var endpoint string
+	var body any
+	header := http.Header{"Content-Type": {"application/json"}}
+	switch config.Provider {
+	case ProviderGitHub:
+		apiURL := config.APIURL
+		if apiURL == "" {
+			apiURL = "https://api.github.com"
+		}
+		endpoint = strings.TrimSuffix(apiURL, "/") + "/repos/" + config.Repository + "/pulls"
+		body = map[string]string{"title": change.Title, "body": change.Description, "head": branch, "base": base}
+		header.Set("Accept", "application/vnd.github+json")
+		header.Set("Authorization", "Bearer "+token)
+	case ProviderGitLab:
+		apiURL := config.APIURL
+		if apiURL == "" {
+			apiURL = "https://gitlab.com/api/v4"
+		}
+		endpoint = strings.TrimSuffix(apiURL, "/") + "/projects/" + url.PathEscape(config.Repository) + "/merge_requests"
+		body = map[string]string{"title": change.Title, "description": change.Description, "source_branch": branch, "target_branch": base}
+		header.Set("PRIVATE-TOKEN", token)
+	default:
+		return "", fmt.Errorf("pull request provider %q is not supported", config.Provider)
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header = header
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	// GitHub returns html_url, GitLab web_url
+	var created struct {
+		HTMLURL string `json:"html_url"`
+		WebURL  string `json:"web_url"`
+	}
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return "", fmt.Errorf("parsing response: %w", err)
+	}
+	if created.HTMLURL != "" {
+		return created.HTMLURL, nil
+	}
+	return created.WebURL, nil
+}
@@ -22,6 +22,8 @@ type contextKey string
 
 const RecorderKey contextKey = "journal-recorder"
 
+const SessionIDKey contextKey = "journal-session-id"
+
 // RecorderFromContext extracts the recorder from the given context
 func RecorderFromContext(ctx context.Context) Recorder {
 	recorder, ok := ctx.Value(RecorderKey).(Recorder)
@@ -35,3 +37,14 @@ func RecorderFromContext(ctx context.Context) Recorder {
 func ContextWithRecorder(ctx context.Context, recorder Recorder) context.Context {
 	return context.WithValue(ctx, RecorderKey, recorder)
 }
+
+// ContextWithSessionID tags events written with the returned context with the given session.
+func ContextWithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, SessionIDKey, sessionID)
+}
+
+// SessionIDFromContext returns the session ID set by ContextWithSessionID, if any.
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(SessionIDKey).(string)
+	return sessionID
+}
@@ -17,6 +17,7 @@ package journal
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -35,11 +36,17 @@ func ParseEventsFromFile(p string) ([]*Event, error) {
 	return ParseEvents(f)
 }
 
-// ParseEvents will read the events from the reader
+// ParseEvents will read the events from the reader, written either by a FileRecorder
+// (YAML documents) or a JSONLRecorder (JSON lines).
 func ParseEvents(r io.Reader) ([]*Event, error) {
+	br := bufio.NewReader(r)
+	if isJSONLines(br) {
+		return parseJSONLines(br)
+	}
+
 	var events []*Event
 
-	scanner := bufio.NewScanner(r)
+	scanner := bufio.NewScanner(br)
 	scanner.Split(splitYAML)
 	for scanner.Scan() {
 		b := scanner.Bytes()
@@ -58,6 +65,36 @@ func ParseEvents(r io.Reader) ([]*Event, error) {
 	return events, nil
 }
 
+// isJSONLines reports whether the journal starts with a JSON object rather than YAML.
+func isJSONLines(r *bufio.Reader) bool {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		default:
+			return b[0] == '{'
+		}
+	}
+}
+
+func parseJSONLines(r io.Reader) ([]*Event, error) {
+	var events []*Event
+	decoder := json.NewDecoder(r)
+	for {
+		event := &Event{}
+		if err := decoder.Decode(event); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing json: %w", err)
+		}
+		events = append(events, event)
+	}
+}
+
 var yamlSep = []byte("\n---\n")
 
 // splitYAML is a split function for a Scanner that returns each object in a yaml multi-object doc.
@@ -76,3 +113,28 @@ func splitYAML(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	// Request more data.
 	return 0, nil, nil
 }
+
+// SessionLog is the journal of a single session.
+type SessionLog struct {
+	SessionID string   `json:"sessionID"`
+	Events    []*Event `json:"events"`
+}
+
+// LoadSessionLog reads the journal at path and returns the events recorded for sessionID.
+func LoadSessionLog(path string, sessionID string) (*SessionLog, error) {
+	events, err := ParseEventsFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &SessionLog{
+		SessionID: sessionID,
+		Events:    []*Event{},
+	}
+	for _, event := range events {
+		if event.SessionID == sessionID {
+			log.Events = append(log.Events, event)
+		}
+	}
+	return log, nil
+}
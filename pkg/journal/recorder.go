@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
 )
 
@@ -35,7 +37,11 @@ type Recorder interface {
 
 // FileRecorder writes a structured log of the agent's actions and observations to a file.
 type FileRecorder struct {
-	f *os.File
+	path string
+
+	// mu serializes writes from concurrent sessions
+	mu sync.Mutex
+	f  *os.File
 }
 
 // NewFileRecorder creates a new FileRecorder that writes to the given file.
@@ -45,19 +51,28 @@ func NewFileRecorder(path string) (*FileRecorder, error) {
 		return nil, fmt.Errorf("opening file: %w", err)
 	}
 	return &FileRecorder{
-		f: file,
+		path: path,
+		f:    file,
 	}, nil
 }
 
-// Close closes the file.
+// Path returns the path of the file the events are written to.
+func (r *FileRecorder) Path() string {
+	return r.path
+}
+
+// Close flushes the file to disk and closes it.
 func (r *FileRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.f.Sync(); err != nil {
+		klog.Warningf("syncing %s: %v", r.path, err)
+	}
 	return r.f.Close()
 }
 
 func (r *FileRecorder) Write(ctx context.Context, event *Event) error {
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
-	}
+	prepareEvent(ctx, event)
 
 	yamlBytes, err := yaml.Marshal(event)
 	if err != nil {
@@ -66,14 +81,21 @@ func (r *FileRecorder) Write(ctx context.Context, event *Event) error {
 	var b bytes.Buffer
 	b.Write(yamlBytes)
 	b.Write([]byte("\n\n---\n\n"))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	_, err = r.f.Write(b.Bytes())
 	return err
 }
 
 type Event struct {
 	Timestamp time.Time `json:"timestamp"`
-	Action    string    `json:"action"`
-	Payload   any       `json:"payload,omitempty"`
+	// SessionID is the session the event belongs to, if known.
+	SessionID string `json:"sessionID,omitempty"`
+	Action    string `json:"action"`
+	Payload   any    `json:"payload,omitempty"`
+	// RawPayload is the encrypted payload before redaction, see RedactingRecorder.
+	RawPayload string `json:"rawPayload,omitempty"`
 }
 
 const (
@@ -85,6 +107,23 @@ const (
 // ActionUIRender is for an event that indicates we wrote output to the UI
 const ActionUIRender = "ui.render"
 
+const (
+	// ActionToolRequest is recorded before a tool runs, with a tools.ToolRequestEvent
+	ActionToolRequest = "tool-request"
+	// ActionToolResponse is recorded after a tool ran, with a tools.ToolResponseEvent
+	ActionToolResponse = "tool-response"
+	// ActionAgentMessage is recorded for every message added to the conversation, with the api.Message
+	ActionAgentMessage = "agent.message"
+	// ActionPermissionRequest is recorded when the user is asked to approve commands, with an agent.PermissionRequestEvent
+	ActionPermissionRequest = "permission.request"
+	// ActionPermissionDecision is recorded when commands are approved or declined, with an agent.PermissionDecisionEvent
+	ActionPermissionDecision = "permission.decision"
+	// ActionLLMThought is recorded for the reasoning of the model that is not shown to users, with its text
+	ActionLLMThought = "llm.thought"
+	// ActionAgentEvent is recorded for the steps of the iterations of the agentic loop, with the api.AgentEvent
+	ActionAgentEvent = "agent.event"
+)
+
 // GetString is a helper to get a string value from the Payload
 func (e *Event) GetString(key string) (string, bool) {
 	if e.Payload == nil {
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/redact"
+)
+
+// RedactingRecorder removes secrets from the payloads of events before writing them
+// to another recorder. With a raw key, the original payload of redacted events is
+// kept encrypted in RawPayload, for break-glass debugging with DecryptRawPayloads.
+type RedactingRecorder struct {
+	next Recorder
+	// aead encrypts raw payloads; nil if they are dropped
+	aead cipher.AEAD
+}
+
+// NewRedactingRecorder creates a RedactingRecorder writing to next. rawKey is an
+// AES key (16, 24 or 32 bytes) to keep encrypted raw payloads with, or nil.
+func NewRedactingRecorder(next Recorder, rawKey []byte) (*RedactingRecorder, error) {
+	r := &RedactingRecorder{next: next}
+	if rawKey != nil {
+		aead, err := newAEAD(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		r.aead = aead
+	}
+	return r, nil
+}
+
+func (r *RedactingRecorder) Write(ctx context.Context, event *Event) error {
+	redacted, changed := redact.Value(event.Payload)
+	if !changed {
+		return r.next.Write(ctx, event)
+	}
+
+	out := *event
+	out.Payload = redacted
+	if r.aead != nil {
+		raw, err := r.encrypt(event.Payload)
+		if err != nil {
+			return fmt.Errorf("encrypting raw payload: %w", err)
+		}
+		out.RawPayload = raw
+	}
+	return r.next.Write(ctx, &out)
+}
+
+func (r *RedactingRecorder) Close() error {
+	return r.next.Close()
+}
+
+func (r *RedactingRecorder) encrypt(payload any) (string, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, r.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(r.aead.Seal(nonce, nonce, b, nil)), nil
+}
+
+// DecryptRawPayloads replaces the redacted payloads of events with their raw payloads,
+// decrypted with the key the journal was written with.
+func DecryptRawPayloads(events []*Event, rawKey []byte) error {
+	aead, err := newAEAD(rawKey)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if event.RawPayload == "" {
+			continue
+		}
+		sealed, err := base64.StdEncoding.DecodeString(event.RawPayload)
+		if err != nil || len(sealed) < aead.NonceSize() {
+			return fmt.Errorf("raw payload of %q event is malformed", event.Action)
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		b, err := aead.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			return fmt.Errorf("decrypting raw payload of %q event: %w", event.Action, err)
+		}
+		var payload any
+		if err := json.Unmarshal(b, &payload); err != nil {
+			return fmt.Errorf("parsing raw payload of %q event: %w", event.Action, err)
+		}
+		event.Payload = payload
+		event.RawPayload = ""
+	}
+	return nil
+}
+
+// LoadRawKey reads a hex-encoded AES key from a file, e.g. one created with
+// `openssl rand -hex 32`.
+func LoadRawKey(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading raw payload key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("raw payload key in %q must be hex encoded: %w", path, err)
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher for raw payloads: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
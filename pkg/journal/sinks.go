@@ -0,0 +1,237 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// prepareEvent fills in the timestamp and session of an event, if unset.
+func prepareEvent(ctx context.Context, event *Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.SessionID == "" {
+		event.SessionID = SessionIDFromContext(ctx)
+	}
+}
+
+// JSONLRecorder appends events to a file or stream as JSON, one event per line.
+// Unlike FileRecorder, it never truncates the file, so a journal can span several runs.
+type JSONLRecorder struct {
+	path string
+
+	// mu serializes writes from concurrent sessions
+	mu sync.Mutex
+	w  io.Writer
+	// closer is nil for streams we do not own, such as stdout
+	closer io.Closer
+}
+
+// NewJSONLRecorder creates a JSONLRecorder appending to the given file.
+func NewJSONLRecorder(path string) (*JSONLRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	return &JSONLRecorder{path: path, w: file, closer: file}, nil
+}
+
+// NewStreamRecorder creates a JSONLRecorder writing to w, e.g. os.Stdout.
+// Closing the recorder does not close w.
+func NewStreamRecorder(w io.Writer) *JSONLRecorder {
+	return &JSONLRecorder{w: w}
+}
+
+// Path returns the path of the file the events are written to, or "" for streams.
+func (r *JSONLRecorder) Path() string {
+	return r.path
+}
+
+func (r *JSONLRecorder) Write(ctx context.Context, event *Event) error {
+	prepareEvent(ctx, event)
+
+	b, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshalling event: %w", err)
+	}
+	b = append(b, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.w.Write(b)
+	return err
+}
+
+// Close flushes the file to disk and closes it, if the recorder owns one.
+func (r *JSONLRecorder) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.closer.(*os.File); ok {
+		if err := f.Sync(); err != nil {
+			klog.Warningf("syncing %s: %v", r.path, err)
+		}
+	}
+	return r.closer.Close()
+}
+
+// webhookQueueSize is the number of events a WebhookRecorder buffers before dropping them.
+const webhookQueueSize = 1000
+
+// WebhookRecorder POSTs each event as JSON to a URL. Events are sent in the background
+// so that a slow endpoint does not slow down the agent; if the endpoint cannot keep up,
+// events are dropped.
+type WebhookRecorder struct {
+	url    string
+	client *http.Client
+	// queue holds the marshalled events, as callers may reuse an event once written
+	queue chan []byte
+	done  chan struct{}
+
+	// mu guards closed, so that events written after Close are not sent on the closed queue
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewWebhookRecorder creates a WebhookRecorder sending events to url.
+func NewWebhookRecorder(url string) *WebhookRecorder {
+	r := &WebhookRecorder{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan []byte, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+func (r *WebhookRecorder) Write(ctx context.Context, event *Event) error {
+	prepareEvent(ctx, event)
+	b, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshalling event: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return fmt.Errorf("webhook %q is closed, dropped %q event", r.url, event.Action)
+	}
+	select {
+	case r.queue <- b:
+		return nil
+	default:
+		return fmt.Errorf("webhook %q is not keeping up, dropped %q event", r.url, event.Action)
+	}
+}
+
+func (r *WebhookRecorder) run() {
+	defer close(r.done)
+	for b := range r.queue {
+		if err := r.send(b); err != nil {
+			klog.Warningf("sending journal event to webhook: %v", err)
+		}
+	}
+}
+
+func (r *WebhookRecorder) send(b []byte) error {
+	resp, err := r.client.Post(r.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("posting event to %q: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("posting event to %q: unexpected status %s", r.url, resp.Status)
+	}
+	return nil
+}
+
+// Close sends the queued events and stops the recorder. Events written afterwards are
+// dropped with an error.
+func (r *WebhookRecorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	<-r.done
+	return nil
+}
+
+// MultiRecorder writes every event to several recorders.
+type MultiRecorder struct {
+	recorders []Recorder
+}
+
+// NewMultiRecorder creates a recorder writing to all the given recorders.
+func NewMultiRecorder(recorders ...Recorder) *MultiRecorder {
+	return &MultiRecorder{recorders: recorders}
+}
+
+func (r *MultiRecorder) Write(ctx context.Context, event *Event) error {
+	prepareEvent(ctx, event)
+	var errs []error
+	for _, recorder := range r.recorders {
+		if err := recorder.Write(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all the recorders.
+func (r *MultiRecorder) Close() error {
+	var errs []error
+	for _, recorder := range r.recorders {
+		if err := recorder.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RecordingPath returns the path of a file that r writes events to, which can be read
+// back with ParseEventsFromFile. It reports false if r does not write to a file.
+func RecordingPath(r Recorder) (string, bool) {
+	switch r := r.(type) {
+	case *MultiRecorder:
+		for _, recorder := range r.recorders {
+			if path, ok := RecordingPath(recorder); ok {
+				return path, true
+			}
+		}
+	case *RedactingRecorder:
+		return RecordingPath(r.next)
+	case interface{ Path() string }:
+		if path := r.Path(); path != "" {
+			return path, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestJSONLRecorderAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	ctx := ContextWithSessionID(context.Background(), "s1")
+
+	// Each run reopens the journal; events of earlier runs must be kept
+	for _, action := range []string{"first", "second"} {
+		r, err := NewJSONLRecorder(path)
+		if err != nil {
+			t.Fatalf("NewJSONLRecorder() failed: %v", err)
+		}
+		if err := r.Write(ctx, &Event{Action: action, Payload: map[string]any{"n": 1}}); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+	}
+
+	log, err := LoadSessionLog(path, "s1")
+	if err != nil {
+		t.Fatalf("LoadSessionLog() failed: %v", err)
+	}
+	if len(log.Events) != 2 || log.Events[0].Action != "first" || log.Events[1].Action != "second" {
+		t.Fatalf("events = %+v, want first and second", log.Events)
+	}
+	if log.Events[0].Timestamp.IsZero() {
+		t.Errorf("event timestamp was not set")
+	}
+}
+
+func TestMultiRecorder(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		var event Event
+		if err := json.Unmarshal(b, &event); err != nil {
+			t.Errorf("webhook received invalid event %q: %v", b, err)
+		}
+		mu.Lock()
+		received = append(received, event.Action)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "trace.yaml")
+	file, err := NewFileRecorder(path)
+	if err != nil {
+		t.Fatalf("NewFileRecorder() failed: %v", err)
+	}
+	r := NewMultiRecorder(file, NewWebhookRecorder(server.URL))
+
+	if got, ok := RecordingPath(r); !ok || got != path {
+		t.Errorf("RecordingPath() = %q, %v, want %q", got, ok, path)
+	}
+	if _, ok := RecordingPath(NewMultiRecorder(NewStreamRecorder(io.Discard))); ok {
+		t.Errorf("RecordingPath() found a file for a stream recorder")
+	}
+
+	// The event is reused, as callers may do once Write returns
+	event := &Event{}
+	for _, action := range []string{"a", "b"} {
+		event.Action = action
+		if err := r.Write(context.Background(), event); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	// Close waits for the webhook to receive the queued events
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	events, err := ParseEventsFromFile(path)
+	if err != nil {
+		t.Fatalf("ParseEventsFromFile() failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("file has %d events, want 2", len(events))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 || received[0] != "a" || received[1] != "b" {
+		t.Errorf("webhook received %v, want [a b]", received)
+	}
+}
+
+func TestWebhookRecorderWriteAfterClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+
+	r := NewWebhookRecorder(server.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Agents may still write events while the recorder is closed on shutdown
+			for j := 0; j < 100; j++ {
+				_ = r.Write(context.Background(), &Event{Action: "a"})
+			}
+		}()
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	wg.Wait()
+
+	if err := r.Write(context.Background(), &Event{Action: "late"}); err == nil {
+		t.Errorf("Write() after Close() succeeded, want an error")
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("second Close() failed: %v", err)
+	}
+}
+
+func TestRedactingRecorderRawPayloads(t *testing.T) {
+	key := make([]byte, 32)
+	var received []*Event
+	next := recorderFunc(func(ctx context.Context, event *Event) error {
+		received = append(received, event)
+		return nil
+	})
+	r, err := NewRedactingRecorder(next, key)
+	if err != nil {
+		t.Fatalf("NewRedactingRecorder() failed: %v", err)
+	}
+
+	ctx := context.Background()
+	r.Write(ctx, &Event{Action: "plain", Payload: map[string]any{"command": "kubectl get pods"}})
+	r.Write(ctx, &Event{Action: "secret", Payload: map[string]any{"command": "kubectl login --token=abc"}})
+
+	if received[0].RawPayload != "" {
+		t.Errorf("raw payload kept for an event without secrets")
+	}
+	redacted := received[1].Payload.(map[string]any)["command"]
+	if redacted != "kubectl login --token=[REDACTED]" || received[1].RawPayload == "" {
+		t.Fatalf("event = %+v, want redacted command and encrypted raw payload", received[1])
+	}
+
+	if err := DecryptRawPayloads(received, key); err != nil {
+		t.Fatalf("DecryptRawPayloads() failed: %v", err)
+	}
+	if raw := received[1].Payload.(map[string]any)["command"]; raw != "kubectl login --token=abc" {
+		t.Errorf("decrypted command = %q, want the raw command", raw)
+	}
+}
+
+type recorderFunc func(ctx context.Context, event *Event) error
+
+func (f recorderFunc) Write(ctx context.Context, event *Event) error { return f(ctx, event) }
+func (f recorderFunc) Close() error                                  { return nil }
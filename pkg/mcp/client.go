@@ -45,6 +45,8 @@ type Tool struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	Server      string `json:"server,omitempty"`
+	// ReadOnly is whether the server annotates the tool as not modifying its environment
+	ReadOnly bool `json:"readOnly,omitempty"`
 
 	InputSchema *gollm.Schema `json:"inputSchema,omitempty"`
 }
@@ -203,7 +205,10 @@ func convertMCPToolsToTools(mcpTools []mcp.Tool) ([]Tool, error) {
 			Name:        mcpTool.Name,
 			Description: mcpTool.Description,
 		}
-		// TODO: Annotations (give hints about e.g. read-only, destructive, idempotent, open-world)
+		if hint := mcpTool.Annotations.ReadOnlyHint; hint != nil {
+			tool.ReadOnly = *hint
+		}
+		// TODO: Other annotations (give hints about e.g. destructive, idempotent, open-world)
 
 		if mcpTool.InputSchema.Type != "" {
 			schema, err := convertMCPInputSchema(&mcpTool.InputSchema)
@@ -342,11 +347,24 @@ func ensureClientConnected(client *mcpclient.Client) error {
 	return nil
 }
 
+// clientOptions returns the options of the MCP library client.
+func clientOptions(sampling mcpclient.SamplingHandler) []mcpclient.ClientOption {
+	if sampling == nil {
+		return nil
+	}
+	return []mcpclient.ClientOption{mcpclient.WithSamplingHandler(sampling)}
+}
+
 // initializeClientConnection initializes the MCP connection with proper handshake.
 func initializeClientConnection(ctx context.Context, client *mcpclient.Client) error {
 	initCtx, cancel := context.WithTimeout(ctx, DefaultConnectionTimeout)
 	defer cancel()
 
+	// Start installs the handlers of requests and notifications sent by the server
+	if err := client.Start(initCtx); err != nil {
+		return fmt.Errorf("starting MCP client: %w", err)
+	}
+
 	// Create initialize request with the structure expected by v0.31.0
 	initReq := mcp.InitializeRequest{
 		// The structure might differ in v0.31.0 - adapt as needed
@@ -17,8 +17,10 @@ package mcp
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
 	"k8s.io/klog/v2"
@@ -53,6 +55,94 @@ type ServerConfig struct {
 	UseStreaming bool `yaml:"use_streaming,omitempty"`
 	// SkipVerify skips TLS certificate verification for HTTPS connections
 	SkipVerify bool `yaml:"skip_verify,omitempty"`
+	// TLS configures the certificates of HTTPS connections
+	TLS *TLSConfig `yaml:"tls,omitempty"`
+	// Headers are added to the requests to HTTP-based MCP servers
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Resources are the URIs of resources of the server loaded as context for the
+	// session. Their updates are picked up if the server supports subscriptions.
+	Resources []string `yaml:"resources,omitempty"`
+	// Sampling lets the server request LLM completions through kubectl-ai
+	Sampling *SamplingConfig `yaml:"sampling,omitempty"`
+	// Trust controls which tool calls of the server need the approval of the user.
+	// Calls are approved like kubectl commands that modify resources if not set.
+	Trust TrustLevel `yaml:"trust,omitempty"`
+	// Tools filters and adjusts the tools of the server as they are registered.
+	// Changes to it are picked up without restarting kubectl-ai.
+	Tools *ToolsConfig `yaml:"tools,omitempty"`
+}
+
+// TrustLevel controls which tool calls of an MCP server need the approval of the user.
+type TrustLevel string
+
+const (
+	// TrustDefault asks for approval like for kubectl commands modifying resources,
+	// unless permission checks are skipped.
+	TrustDefault TrustLevel = ""
+	// TrustTrusted runs the tools without asking for approval.
+	TrustTrusted TrustLevel = "trusted"
+	// TrustAskAlways asks for approval of every call, even when permission checks are
+	// skipped with --skip-permissions or "don't ask me again".
+	TrustAskAlways TrustLevel = "ask-always"
+	// TrustReadOnly only runs the tools the server annotates as read-only, without
+	// asking for approval, and refuses the others.
+	TrustReadOnly TrustLevel = "read-only"
+)
+
+// ToolsConfig controls which tools of an MCP server are exposed to the LLM, and how
+type ToolsConfig struct {
+	// Allow lists the tools to expose, as glob patterns (e.g. "get_*"). All tools if empty.
+	Allow []string `yaml:"allow,omitempty"`
+	// Deny lists the tools to hide, as glob patterns. It takes precedence over Allow.
+	Deny []string `yaml:"deny,omitempty"`
+	// Prefix is prepended to the tool names shown to the LLM, e.g. "docs_".
+	// Defaults to the server name and an underscore.
+	Prefix string `yaml:"prefix,omitempty"`
+	// Descriptions overrides the descriptions of tools, by tool name
+	Descriptions map[string]string `yaml:"descriptions,omitempty"`
+	// MaxResultSize truncates tool results longer than this many bytes. No limit if zero.
+	MaxResultSize int `yaml:"maxResultSize,omitempty"`
+}
+
+// Allows reports whether the tool should be exposed. A nil config allows all tools.
+func (c *ToolsConfig) Allows(toolName string) bool {
+	if c == nil {
+		return true
+	}
+	for _, pattern := range c.Deny {
+		if matchToolName(pattern, toolName) {
+			return false
+		}
+	}
+	if len(c.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range c.Allow {
+		if matchToolName(pattern, toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+// Description returns the description of the tool, overridden if configured.
+func (c *ToolsConfig) Description(toolName, description string) string {
+	if c == nil {
+		return description
+	}
+	if override, ok := c.Descriptions[toolName]; ok {
+		return override
+	}
+	return description
+}
+
+func matchToolName(pattern, toolName string) bool {
+	matched, err := path.Match(pattern, toolName)
+	if err != nil {
+		klog.Warningf("Invalid MCP tool pattern %q: %v", pattern, err)
+		return false
+	}
+	return matched
 }
 
 // ===================================================================
@@ -169,6 +259,31 @@ func LoadConfig(path string) (*Config, error) {
 	return &config, nil
 }
 
+// SelectServers keeps only the servers with the given names, in the order of the config.
+// It fails if a name is not configured.
+func (c *Config) SelectServers(names []string) error {
+	var selected []ServerConfig
+	for _, name := range names {
+		found := false
+		for _, server := range c.Servers {
+			if server.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("MCP server %q is not configured", name)
+		}
+	}
+	for _, server := range c.Servers {
+		if slices.Contains(names, server.Name) {
+			selected = append(selected, server)
+		}
+	}
+	c.Servers = selected
+	return nil
+}
+
 // Save saves the configuration to the given path using atomic write
 func (c *Config) Save(path string) error {
 	if path == "" {
@@ -273,6 +388,41 @@ func ValidateServerConfig(config ServerConfig) error {
 		return fmt.Errorf("either URL or Command must be specified")
 	}
 
+	if config.Auth != nil {
+		switch config.Auth.Type {
+		case "", "none", "basic", "api-key":
+		case "bearer":
+			if config.Auth.Token != "" && config.Auth.TokenFile != "" {
+				return fmt.Errorf("auth token and tokenFile are mutually exclusive")
+			}
+		default:
+			return fmt.Errorf("auth type %q is not known, must be none, basic, bearer or api-key", config.Auth.Type)
+		}
+	}
+	if config.OAuthConfig != nil && config.OAuthConfig.ClientID == "" {
+		return fmt.Errorf("oauth clientID cannot be empty")
+	}
+	if config.TLS != nil && (config.TLS.CertFile == "") != (config.TLS.KeyFile == "") {
+		return fmt.Errorf("tls certFile and keyFile must be set together")
+	}
+
+	switch config.Trust {
+	case TrustDefault, TrustTrusted, TrustAskAlways, TrustReadOnly:
+	default:
+		return fmt.Errorf("trust %q is not known, must be trusted, ask-always or read-only", config.Trust)
+	}
+
+	if config.Tools != nil {
+		for _, pattern := range append(config.Tools.Allow, config.Tools.Deny...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+			}
+		}
+		if config.Tools.MaxResultSize < 0 {
+			return fmt.Errorf("tools maxResultSize cannot be negative")
+		}
+	}
+
 	// Additional validation could be added here:
 	// - Check if command exists and is executable
 	// - Validate environment variable format
@@ -308,6 +458,12 @@ func applyServerEnvironment(server *ServerConfig) {
 	if server.URL != "" && server.Auth != nil {
 		applyAuthEnvironmentVariables(server, prefix)
 	}
+	if server.URL != "" && server.OAuthConfig != nil {
+		if secret := os.Getenv(prefix + "CLIENT_SECRET"); secret != "" {
+			server.OAuthConfig.ClientSecret = secret
+			klog.V(2).InfoS("Using OAuth client secret from environment", "server", server.Name)
+		}
+	}
 
 	// Process command and arguments for stdio servers
 	if server.Command != "" {
@@ -0,0 +1,70 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestToolsConfigAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *ToolsConfig
+		tool   string
+		want   bool
+	}{
+		{"no config", nil, "anything", true},
+		{"empty allow list", &ToolsConfig{}, "anything", true},
+		{"allowed by pattern", &ToolsConfig{Allow: []string{"scan_*"}}, "scan_rbac", true},
+		{"not allowed", &ToolsConfig{Allow: []string{"scan_*"}}, "report", false},
+		{"deny wins over allow", &ToolsConfig{Allow: []string{"scan_*"}, Deny: []string{"scan_secrets"}}, "scan_secrets", false},
+		{"denied without allow list", &ToolsConfig{Deny: []string{"delete_*"}}, "delete_pod", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Allows(tt.tool); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolsConfigFromYAML(t *testing.T) {
+	// The keys documented for users must be the ones the config is parsed with
+	var config Config
+	err := yaml.Unmarshal([]byte(`
+servers:
+  - name: docs
+    url: http://localhost:8080/mcp
+    tools:
+      deny: ["write_*"]
+      prefix: "d_"
+      descriptions:
+        search: "Search the docs"
+      maxResultSize: 100
+`), &config)
+	if err != nil {
+		t.Fatalf("parsing config: %v", err)
+	}
+	tools := config.Servers[0].Tools
+	if tools == nil || tools.Prefix != "d_" || tools.MaxResultSize != 100 || tools.Allows("write_page") {
+		t.Fatalf("tools config = %+v, want the parsed settings", tools)
+	}
+	if got := tools.Description("search", "default"); got != "Search the docs" {
+		t.Errorf("Description() = %q, want the override", got)
+	}
+}
@@ -29,6 +29,15 @@ const (
 
 	// DefaultStabilizationDelay is the delay to allow servers to stabilize after connection
 	DefaultStabilizationDelay = 2 * time.Second
+
+	// DefaultConfigWatchInterval is how often the config file is checked for changes
+	DefaultConfigWatchInterval = 5 * time.Second
+
+	// DefaultHealthCheckInterval is how often connected servers are pinged
+	DefaultHealthCheckInterval = 15 * time.Second
+
+	// DefaultReconnectMaxDelay caps the backoff between reconnection attempts
+	DefaultReconnectMaxDelay = 5 * time.Minute
 )
 
 // Error message templates
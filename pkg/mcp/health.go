@@ -0,0 +1,212 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// ServerHealth is the last known health of an MCP server.
+type ServerHealth struct {
+	Name string
+	// Connected is whether the server answered the last check
+	Connected bool
+	// LastError is why the last check or reconnection attempt failed
+	LastError string
+	// LastCheck is when the server was last checked
+	LastCheck time.Time
+	// Failures counts the failed checks and reconnection attempts in a row
+	Failures int
+	// NextRetry is when the next reconnection attempt is due, if disconnected
+	NextRetry time.Time
+}
+
+// HealthChange reports that a server was disconnected or connected again.
+type HealthChange struct {
+	Server    string
+	Connected bool
+	// Err is why the server was disconnected
+	Err error
+}
+
+// Ping checks that the MCP server is still responding.
+func (c *Client) Ping(ctx context.Context) error {
+	if err := c.ensureConnected(); err != nil {
+		return err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, DefaultPingTimeout)
+	defer cancel()
+	return c.impl.getUnderlyingClient().Ping(pingCtx)
+}
+
+// MonitorHealth pings the connected servers every interval until ctx is done. A server
+// that does not respond is disconnected, and reconnected with exponential backoff,
+// as are the servers that failed to connect initially. onChange is called when a
+// server is disconnected or connected again; the tools of reconnected servers must
+// be registered again by the caller.
+func (m *Manager) MonitorHealth(ctx context.Context, interval time.Duration, onChange func(HealthChange)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	backoff := RetryConfig{
+		BaseDelay:  interval,
+		MaxDelay:   DefaultReconnectMaxDelay,
+		Multiplier: 2.0,
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, change := range m.checkHealth(ctx, time.Now(), backoff) {
+				onChange(change)
+			}
+		}
+	}
+}
+
+// checkHealth checks every configured server once, and returns the changes.
+func (m *Manager) checkHealth(ctx context.Context, now time.Time, backoff RetryConfig) []HealthChange {
+	var changes []HealthChange
+	for _, serverCfg := range m.configuredServers() {
+		name := serverCfg.Name
+		health := m.serverHealth(name)
+
+		if client, ok := m.GetClient(name); ok {
+			err := client.Ping(ctx)
+			m.mu.Lock()
+			health.LastCheck = now
+			if err == nil {
+				health.Connected, health.LastError, health.Failures = true, "", 0
+				m.mu.Unlock()
+				continue
+			}
+			klog.Warningf("MCP server %q is not responding, disconnecting: %v", name, err)
+			if closeErr := client.Close(); closeErr != nil {
+				klog.V(2).InfoS("Failed to close unresponsive MCP client", "server", name, "error", closeErr)
+			}
+			delete(m.clients, name)
+			health.Connected = false
+			health.LastError = err.Error()
+			health.Failures = 1
+			health.NextRetry = now.Add(calculateBackoffDelay(health.Failures, backoff))
+			m.mu.Unlock()
+			changes = append(changes, HealthChange{Server: name, Err: err})
+			continue
+		}
+
+		m.mu.RLock()
+		due := !now.Before(health.NextRetry)
+		m.mu.RUnlock()
+		if !due || ctx.Err() != nil {
+			continue
+		}
+
+		klog.V(2).InfoS("Reconnecting to MCP server", "server", name)
+		m.mu.RLock()
+		config := m.newClientConfig(serverCfg)
+		onResourceUpdate := m.resourceUpdated
+		m.mu.RUnlock()
+		client := NewClient(config)
+		err := client.Connect(ctx)
+
+		m.mu.Lock()
+		health.LastCheck = now
+		if err != nil {
+			health.LastError = err.Error()
+			health.Failures++
+			health.NextRetry = now.Add(calculateBackoffDelay(health.Failures, backoff))
+			m.mu.Unlock()
+			klog.V(2).InfoS("Failed to reconnect to MCP server", "server", name, "failures", health.Failures, "nextRetry", health.NextRetry, "error", err)
+			continue
+		}
+		m.clients[name] = client
+		health.Connected, health.LastError, health.Failures = true, "", 0
+		m.mu.Unlock()
+		// Subscriptions do not survive the connection
+		watchResources(ctx, client, serverCfg, onResourceUpdate)
+		klog.Infof("Reconnected to MCP server %q", name)
+		changes = append(changes, HealthChange{Server: name, Connected: true})
+	}
+	return changes
+}
+
+// serverHealth returns the health entry of a server, creating it if needed.
+func (m *Manager) serverHealth(name string) *ServerHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	health, ok := m.health[name]
+	if !ok {
+		_, connected := m.clients[name]
+		health = &ServerHealth{Name: name, Connected: connected}
+		m.health[name] = health
+	}
+	return health
+}
+
+// Health returns the health of the configured servers, in configuration order.
+func (m *Manager) Health() []ServerHealth {
+	var out []ServerHealth
+	for _, serverCfg := range m.configuredServers() {
+		health := m.serverHealth(serverCfg.Name)
+		m.mu.RLock()
+		h := *health
+		_, h.Connected = m.clients[serverCfg.Name]
+		m.mu.RUnlock()
+		out = append(out, h)
+	}
+	return out
+}
+
+// Check connects to the configured servers that are not connected yet, and returns the
+// health of all of them, with the reason connections failed.
+func (m *Manager) Check(ctx context.Context) []ServerHealth {
+	for _, serverCfg := range m.configuredServers() {
+		health := m.serverHealth(serverCfg.Name)
+		m.mu.RLock()
+		_, connected := m.clients[serverCfg.Name]
+		config := m.newClientConfig(serverCfg)
+		m.mu.RUnlock()
+		if connected {
+			continue
+		}
+
+		client := NewClient(config)
+		err := client.Connect(ctx)
+		m.mu.Lock()
+		health.LastCheck = time.Now()
+		if err != nil {
+			health.LastError = err.Error()
+			health.Failures++
+		} else {
+			m.clients[serverCfg.Name] = client
+			health.LastError = ""
+			health.Failures = 0
+		}
+		m.mu.Unlock()
+	}
+	return m.Health()
+}
+
+// configuredServers returns a copy of the server configurations, which the config
+// watcher may update concurrently.
+func (m *Manager) configuredServers() []ServerConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]ServerConfig(nil), m.config.Servers...)
+}
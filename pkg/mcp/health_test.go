@@ -0,0 +1,53 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckHealthReconnectBackoff(t *testing.T) {
+	m := NewManager(&Config{Servers: []ServerConfig{
+		{Name: "missing", Command: filepath.Join(t.TempDir(), "no-such-server")},
+	}})
+	backoff := RetryConfig{BaseDelay: time.Minute, MaxDelay: 3 * time.Minute, Multiplier: 2}
+	ctx := context.Background()
+	start := time.Now()
+
+	// Each attempt doubles the delay to the next one, up to MaxDelay
+	now := start
+	for i, wantDelay := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		if changes := m.checkHealth(ctx, now, backoff); len(changes) != 0 {
+			t.Fatalf("attempt %d: unexpected changes %+v", i+1, changes)
+		}
+		health := m.Health()[0]
+		if health.Connected || health.Failures != i+1 || health.LastError == "" {
+			t.Fatalf("attempt %d: unexpected health %+v", i+1, health)
+		}
+		if got := health.NextRetry.Sub(now); got != wantDelay {
+			t.Errorf("attempt %d: next retry in %v, want %v", i+1, got, wantDelay)
+		}
+
+		// Not retried before the delay is over
+		m.checkHealth(ctx, now.Add(wantDelay-time.Second), backoff)
+		if got := m.Health()[0].Failures; got != i+1 {
+			t.Errorf("attempt %d: retried before the backoff delay, failures = %d", i+1, got)
+		}
+		now = health.NextRetry
+	}
+}
@@ -16,11 +16,7 @@ package mcp
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/base64"
 	"fmt"
-	"net/http"
-	"time"
 
 	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
@@ -42,6 +38,8 @@ type httpClient struct {
 	useStreaming bool
 	skipVerify   bool
 	headers      map[string]string
+	tls          *TLSConfig
+	sampling     mcpclient.SamplingHandler
 	client       *mcpclient.Client
 }
 
@@ -56,6 +54,8 @@ func NewHTTPClient(config ClientConfig) MCPClient {
 		useStreaming: config.UseStreaming,
 		skipVerify:   config.SkipVerify,
 		headers:      config.Headers,
+		tls:          config.TLS,
+		sampling:     config.Sampling,
 	}
 }
 
@@ -117,78 +117,18 @@ func (c *httpClient) Connect(ctx context.Context) error {
 
 // createStreamingClient creates a streamable HTTP client for better performance
 func (c *httpClient) createStreamingClient() (*mcpclient.Client, error) {
-	// Set up options for the HTTP client
-	var options []transport.StreamableHTTPCOption
-
-	// Add timeout if specified (only when not using custom client)
-	if c.timeout > 0 {
-		options = append(options, transport.WithHTTPTimeout(time.Duration(c.timeout)*time.Second))
-	}
-
-	klog.V(2).InfoS("WARNING: TLS certificate verification is disabled", "server", c.name)
-	// Handle TLS verification skip by creating custom HTTP client
-	if c.skipVerify {
-		klog.V(2).InfoS("WARNING: TLS certificate verification is disabled", "server", c.name)
-
-		// Create custom HTTP client with TLS verification disabled
-		customClient := &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
-		}
-
-		// Add timeout to custom client if specified
-		if c.timeout > 0 {
-			customClient.Timeout = time.Duration(c.timeout) * time.Second
-		}
-
-		// Use the custom HTTP client
-		options = append(options, transport.WithHTTPBasicClient(customClient))
-	}
-
-	// Prepare headers map for authentication and custom headers
-	headers := make(map[string]string)
-
-	// Add custom headers from configuration first
-	for key, value := range c.headers {
-		headers[key] = value
-		klog.V(3).InfoS("Using custom header for HTTP client", "server", c.name, "header", key)
-	}
-
-	// Add authentication headers if specified (may override custom headers)
-	if c.auth != nil {
-		switch c.auth.Type {
-		case "basic":
-			auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(c.auth.Username+":"+c.auth.Password))
-			headers["Authorization"] = auth
-			klog.V(3).InfoS("Using basic auth for HTTP client", "server", c.name)
-		case "bearer":
-			headers["Authorization"] = "Bearer " + c.auth.Token
-			klog.V(3).InfoS("Using bearer auth for HTTP client", "server", c.name)
-		case "api-key":
-			headerName := "X-Api-Key"
-			if c.auth.HeaderName != "" {
-				headerName = c.auth.HeaderName
-			}
-			headers[headerName] = c.auth.ApiKey
-			klog.V(3).InfoS("Using API key auth for HTTP client", "server", c.name)
-		}
-	}
-
-	// Add headers if any were set
-	if len(headers) > 0 {
-		options = append(options, transport.WithHTTPHeaders(headers))
+	httpClient, err := c.newHTTPClient()
+	if err != nil {
+		return nil, err
 	}
 
 	klog.V(4).InfoS("Creating streamable HTTP client", "server", c.name, "url", c.url)
-	client, err := mcpclient.NewStreamableHttpClient(c.url, options...)
+	trans, err := transport.NewStreamableHTTP(c.url, transport.WithHTTPBasicClient(httpClient))
 	if err != nil {
 		return nil, fmt.Errorf("creating streamable HTTP client: %w", err)
 	}
 
-	return client, nil
+	return mcpclient.NewClient(trans, clientOptions(c.sampling)...), nil
 }
 
 // createStandardClient creates a standard HTTP client
@@ -206,34 +146,35 @@ func (c *httpClient) createOAuthClient(ctx context.Context) (*mcpclient.Client,
 
 	klog.V(3).InfoS("Creating OAuth HTTP client", "server", c.name, "client_id", c.oauthConfig.ClientID)
 
-	// Set up options for the HTTP client
-	var options []transport.StreamableHTTPCOption
+	// With a token endpoint and a secret, the client authenticates as itself. The token
+	// source caches the token and fetches a new one before it expires.
+	if c.oauthConfig.TokenURL != "" && c.oauthConfig.ClientSecret != "" {
+		return c.createStreamingClient()
+	}
+
+	httpClient, err := c.newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
 
-	// Create OAuth configuration for the transport
+	// Otherwise the transport discovers the authorization server from the MCP server
 	oauthCfg := transport.OAuthConfig{
 		ClientID:     c.oauthConfig.ClientID,
 		ClientSecret: c.oauthConfig.ClientSecret,
 		Scopes:       c.oauthConfig.Scopes,
 		RedirectURI:  c.oauthConfig.RedirectURL,
-		// Use the token URL as the auth server metadata URL if available
-		AuthServerMetadataURL: c.oauthConfig.TokenURL,
-	}
-
-	// Add OAuth configuration
-	options = append(options, transport.WithHTTPOAuth(oauthCfg))
-
-	// Add timeout if specified
-	if c.timeout > 0 {
-		options = append(options, transport.WithHTTPTimeout(time.Duration(c.timeout)*time.Second))
 	}
 
 	klog.V(4).InfoS("Creating OAuth streamable HTTP client", "server", c.name, "url", c.url)
-	client, err := mcpclient.NewStreamableHttpClient(c.url, options...)
+	trans, err := transport.NewStreamableHTTP(c.url,
+		transport.WithHTTPBasicClient(httpClient),
+		transport.WithHTTPOAuth(oauthCfg),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("creating OAuth HTTP client: %w", err)
 	}
 
-	return client, nil
+	return mcpclient.NewClient(trans, clientOptions(c.sampling)...), nil
 }
 
 // initializeConnection initializes the MCP connection with proper handshake
@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"k8s.io/klog/v2"
+)
+
+// newHTTPClient creates the HTTP client used to talk to the MCP server, with the
+// TLS options, custom headers and authentication of the server configuration.
+func (c *httpClient) newHTTPClient() (*http.Client, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = tlsConfig
+
+	var rt http.RoundTripper = &headerTransport{
+		next:    base,
+		headers: c.headers,
+		auth:    c.auth,
+	}
+
+	if c.usesClientCredentials() {
+		klog.V(3).InfoS("Using OAuth client credentials for HTTP client", "server", c.name, "token_url", c.oauthConfig.TokenURL)
+		cc := &clientcredentials.Config{
+			ClientID:     c.oauthConfig.ClientID,
+			ClientSecret: c.oauthConfig.ClientSecret,
+			TokenURL:     c.oauthConfig.TokenURL,
+			Scopes:       c.oauthConfig.Scopes,
+		}
+		// Fetch tokens with the same TLS options as the MCP server
+		tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: base})
+		rt = &oauth2.Transport{
+			Source: oauth2.ReuseTokenSource(nil, cc.TokenSource(tokenCtx)),
+			Base:   rt,
+		}
+	}
+
+	client := &http.Client{Transport: rt}
+	if c.timeout > 0 {
+		client.Timeout = time.Duration(c.timeout) * time.Second
+	}
+	return client, nil
+}
+
+// usesClientCredentials reports whether OAuth tokens are obtained with the client
+// credentials grant, rather than by discovering the authorization server.
+func (c *httpClient) usesClientCredentials() bool {
+	return c.oauthConfig != nil && c.oauthConfig.TokenURL != "" && c.oauthConfig.ClientSecret != ""
+}
+
+// tlsConfig builds the TLS configuration from the server options.
+func (c *httpClient) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{}
+	if c.skipVerify {
+		klog.V(2).InfoS("WARNING: TLS certificate verification is disabled", "server", c.name)
+		config.InsecureSkipVerify = true
+	}
+	if c.tls == nil {
+		return config, nil
+	}
+
+	config.ServerName = c.tls.ServerName
+	if c.tls.CAFile != "" {
+		pem, err := os.ReadFile(c.tls.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", c.tls.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if c.tls.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.tls.CertFile, c.tls.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// headerTransport adds the custom headers and the credentials of the server
+// configuration to requests.
+type headerTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+	auth    *AuthConfig
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	// Authentication headers may override custom headers
+	if t.auth != nil {
+		switch t.auth.Type {
+		case "basic":
+			req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(t.auth.Username+":"+t.auth.Password)))
+		case "bearer":
+			token := t.auth.Token
+			if t.auth.TokenFile != "" {
+				// Read on every request, so that rotated tokens (e.g. projected
+				// service account tokens) are picked up
+				b, err := os.ReadFile(t.auth.TokenFile)
+				if err != nil {
+					return nil, fmt.Errorf("reading bearer token: %w", err)
+				}
+				token = strings.TrimSpace(string(b))
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		case "api-key":
+			headerName := "X-Api-Key"
+			if t.auth.HeaderName != "" {
+				headerName = t.auth.HeaderName
+			}
+			req.Header.Set(headerName, t.auth.ApiKey)
+		}
+	}
+	return t.next.RoundTrip(req)
+}
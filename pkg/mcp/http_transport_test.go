@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHTTPClientAuth(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"oauth-token-%d","token_type":"Bearer","expires_in":3600}`, tokenRequests)
+	}))
+	defer tokenServer.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config ClientConfig
+		// rotate is written to the token file before the second request
+		rotate string
+		want   []string
+	}{
+		{
+			name: "token file is re-read",
+			config: ClientConfig{
+				Auth:    &AuthConfig{Type: "bearer", TokenFile: tokenFile},
+				Headers: map[string]string{"Authorization": "overridden"},
+			},
+			rotate: "second",
+			want:   []string{"Bearer first", "Bearer second"},
+		},
+		{
+			name: "client credentials token is reused",
+			config: ClientConfig{
+				OAuthConfig: &OAuthConfig{ClientID: "kubectl-ai", ClientSecret: "secret", TokenURL: tokenServer.URL},
+			},
+			want: []string{"Bearer oauth-token-1", "Bearer oauth-token-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = append(got, r.Header.Get("Authorization"))
+			}))
+			defer server.Close()
+
+			client, err := NewHTTPClient(tt.config).(*httpClient).newHTTPClient()
+			if err != nil {
+				t.Fatalf("newHTTPClient() error = %v", err)
+			}
+			for i := range tt.want {
+				if i == 1 && tt.rotate != "" {
+					if err := os.WriteFile(tokenFile, []byte(tt.rotate), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				}
+				resp, err := client.Get(server.URL)
+				if err != nil {
+					t.Fatalf("request %d: %v", i, err)
+				}
+				resp.Body.Close()
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Authorization headers = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
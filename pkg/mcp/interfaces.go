@@ -63,6 +63,11 @@ type ClientConfig struct {
 	UseStreaming bool              // Whether to use streaming HTTP for better performance
 	SkipVerify   bool              // Whether to skip TLS certificate verification for HTTPS connections
 	Headers      map[string]string // Custom headers to include in HTTP requests
+	TLS          *TLSConfig        // Certificates for HTTPS connections
+
+	// Sampling answers the LLM completion requests of the server; nil if the
+	// server may not make any
+	Sampling mcpclient.SamplingHandler
 
 	// No LLM configuration needed - MCP doesn't need to know about LLM models
 }
@@ -73,11 +78,23 @@ type AuthConfig struct {
 	Username   string // For basic auth
 	Password   string // For basic auth
 	Token      string // For bearer auth
+	TokenFile  string // For bearer auth, read on every request so rotated tokens are picked up
 	ApiKey     string // For API key auth
 	HeaderName string // Custom header name for API key
 }
 
-// OAuthConfig represents OAuth configuration for HTTP MCP servers
+// TLSConfig represents the TLS options of HTTPS MCP servers
+type TLSConfig struct {
+	CAFile     string // CA bundle to verify the server certificate with, instead of the system roots
+	CertFile   string // Client certificate, for mutual TLS
+	KeyFile    string // Key of the client certificate
+	ServerName string // Name to verify the server certificate against, if not the host of the URL
+}
+
+// OAuthConfig represents OAuth configuration for HTTP MCP servers.
+// With a TokenURL and ClientSecret, tokens are obtained with the client credentials
+// grant and refreshed before they expire. Otherwise the authorization server is
+// discovered from the MCP server.
 type OAuthConfig struct {
 	ClientID     string
 	ClientSecret string
@@ -17,6 +17,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -54,7 +55,15 @@ type MCPStatus struct {
 type Manager struct {
 	config  *Config
 	clients map[string]*Client
-	mu      sync.RWMutex
+	// health tracks the servers seen by MonitorHealth, by name
+	health map[string]*ServerHealth
+	// sampling answers the sampling requests of servers, if set
+	sampling SamplingHandler
+	// samplingUsed counts the sampling tokens used by each server
+	samplingUsed map[string]int
+	// resourceUpdated is called when a configured resource changes, if set
+	resourceUpdated ResourceUpdateHandler
+	mu              sync.RWMutex
 }
 
 // NewManager creates a new MCP manager with the given configuration
@@ -62,12 +71,16 @@ func NewManager(config *Config) *Manager {
 	return &Manager{
 		config:  config,
 		clients: make(map[string]*Client),
+		health:  make(map[string]*ServerHealth),
+
+		samplingUsed: make(map[string]int),
 	}
 }
 
 // InitializeManager creates and initializes the MCP manager
-// with configuration loaded from default paths
-func InitializeManager() (*Manager, error) {
+// with configuration loaded from default paths. If servers are given,
+// only those servers of the configuration are used.
+func InitializeManager(servers ...string) (*Manager, error) {
 	klog.V(1).Info("Initializing MCP client functionality")
 
 	config, err := LoadConfig("")
@@ -75,6 +88,11 @@ func InitializeManager() (*Manager, error) {
 		klog.V(2).Info("Failed to load MCP config", "error", err)
 		return nil, err
 	}
+	if len(servers) > 0 {
+		if err := config.SelectServers(servers); err != nil {
+			return nil, err
+		}
+	}
 
 	return NewManager(config), nil
 }
@@ -96,26 +114,7 @@ func (m *Manager) ConnectAll(ctx context.Context) error {
 			continue
 		}
 
-		// Convert environment map to slice
-		var envSlice []string
-		for k, v := range serverCfg.Env {
-			envSlice = append(envSlice, fmt.Sprintf("%s=%s", k, v))
-		}
-
-		// Create client config with environment map
-		config := ClientConfig{
-			Name:         serverCfg.Name,
-			Command:      serverCfg.Command,
-			Args:         serverCfg.Args,
-			Auth:         serverCfg.Auth,
-			OAuthConfig:  serverCfg.OAuthConfig,
-			Env:          envSlice,
-			URL:          serverCfg.URL,
-			Timeout:      serverCfg.Timeout,
-			UseStreaming: serverCfg.UseStreaming,
-			SkipVerify:   serverCfg.SkipVerify,
-		}
-
+		config := m.newClientConfig(serverCfg)
 		client := NewClient(config)
 		if err := client.Connect(ctx); err != nil {
 			err := fmt.Errorf(ErrServerConnectionFmt, serverCfg.Name, err)
@@ -125,6 +124,7 @@ func (m *Manager) ConnectAll(ctx context.Context) error {
 		}
 
 		m.clients[serverCfg.Name] = client
+		watchResources(ctx, client, serverCfg, m.resourceUpdated)
 		klog.V(2).Info("Connected to MCP server", "name", serverCfg.Name)
 	}
 
@@ -135,6 +135,35 @@ func (m *Manager) ConnectAll(ctx context.Context) error {
 	return nil
 }
 
+// newClientConfig returns the client configuration of a configured server.
+// It must be called with m.mu held.
+func (m *Manager) newClientConfig(serverCfg ServerConfig) ClientConfig {
+	// Convert environment map to slice
+	var envSlice []string
+	for k, v := range serverCfg.Env {
+		envSlice = append(envSlice, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	config := ClientConfig{
+		Name:         serverCfg.Name,
+		Command:      serverCfg.Command,
+		Args:         serverCfg.Args,
+		Auth:         serverCfg.Auth,
+		OAuthConfig:  serverCfg.OAuthConfig,
+		Env:          envSlice,
+		URL:          serverCfg.URL,
+		Timeout:      serverCfg.Timeout,
+		UseStreaming: serverCfg.UseStreaming,
+		SkipVerify:   serverCfg.SkipVerify,
+		TLS:          serverCfg.TLS,
+		Headers:      serverCfg.Headers,
+	}
+	if serverCfg.Sampling != nil && serverCfg.Sampling.Enabled && m.sampling != nil {
+		config.Sampling = &samplingAdapter{manager: m, server: serverCfg.Name, config: *serverCfg.Sampling}
+	}
+	return config
+}
+
 // Close closes all MCP client connections
 func (m *Manager) Close() error {
 	m.mu.Lock()
@@ -271,7 +300,14 @@ func (m *Manager) RegisterTools(ctx context.Context, registerCallback func(serve
 
 	toolCount := 0
 	for serverName, tools := range serverTools {
+		toolsConfig := m.ToolsConfig(serverName)
 		for _, toolInfo := range tools {
+			if !toolsConfig.Allows(toolInfo.Name) {
+				klog.V(2).Info("Skipping MCP tool filtered out by config", "server", serverName, "tool", toolInfo.Name)
+				continue
+			}
+			toolInfo.Description = toolsConfig.Description(toolInfo.Name, toolInfo.Description)
+
 			// Use the callback to register each tool
 			if err := registerCallback(serverName, toolInfo); err != nil {
 				klog.Warningf("Failed to register tool %s from server %s: %v", toolInfo.Name, serverName, err)
@@ -288,6 +324,93 @@ func (m *Manager) RegisterTools(ctx context.Context, registerCallback func(serve
 	return nil
 }
 
+// ToolsConfig returns the tool settings of a server, or nil if it has none.
+func (m *Manager) ToolsConfig(serverName string) *ToolsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, server := range m.config.Servers {
+		if server.Name == serverName {
+			return server.Tools
+		}
+	}
+	return nil
+}
+
+// TrustLevel returns the trust level of a configured server.
+func (m *Manager) TrustLevel(serverName string) TrustLevel {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, server := range m.config.Servers {
+		if server.Name == serverName {
+			return server.Trust
+		}
+	}
+	return TrustDefault
+}
+
+// WatchConfig checks the config file for changes until ctx is done. When it changes,
+// the tool settings of the connected servers are updated and onChange is called so
+// that tools can be registered again. Other changes require a restart.
+func (m *Manager) WatchConfig(ctx context.Context, onChange func()) {
+	path, err := DefaultConfigPath()
+	if err != nil {
+		klog.Warningf("Not watching MCP config for changes: %v", err)
+		return
+	}
+
+	lastModified := configModTime(path)
+	ticker := time.NewTicker(DefaultConfigWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		modified := configModTime(path)
+		if modified.Equal(lastModified) {
+			continue
+		}
+		lastModified = modified
+
+		config, err := LoadConfig(path)
+		if err != nil {
+			klog.Warningf("Ignoring changed MCP config: %v", err)
+			continue
+		}
+		klog.V(1).Info("MCP config changed, applying tool settings", "path", path)
+		m.applyToolsConfig(config)
+		onChange()
+	}
+}
+
+// applyToolsConfig takes the tool settings of the servers from config.
+func (m *Manager) applyToolsConfig(config *Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.config.Servers {
+		server := &m.config.Servers[i]
+		server.Tools = nil
+		for _, updated := range config.Servers {
+			if updated.Name == server.Name {
+				server.Tools = updated.Tools
+			}
+		}
+	}
+}
+
+func configModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
 // =============================================================================
 // Status Reporting
 // =============================================================================
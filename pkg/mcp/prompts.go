@@ -0,0 +1,142 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// Prompt is a prompt template provided by an MCP server.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	Server      string           `json:"server,omitempty"`
+}
+
+// PromptArgument is an argument of a prompt template.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ID returns the name the user invokes the prompt with, server/name.
+func (p Prompt) ID() string {
+	return p.Server + "/" + p.Name
+}
+
+// ListPrompts lists the prompts of the MCP server. Servers that do not advertise
+// the prompts capability have none.
+func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	if err := c.ensureConnected(); err != nil {
+		return nil, err
+	}
+	client := c.impl.getUnderlyingClient()
+	if client.GetServerCapabilities().Prompts == nil {
+		return nil, nil
+	}
+
+	result, err := client.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("listing prompts: %w", err)
+	}
+
+	prompts := make([]Prompt, 0, len(result.Prompts))
+	for _, p := range result.Prompts {
+		prompt := Prompt{Name: p.Name, Description: p.Description, Server: c.Name}
+		for _, arg := range p.Arguments {
+			prompt.Arguments = append(prompt.Arguments, PromptArgument{
+				Name:        arg.Name,
+				Description: arg.Description,
+				Required:    arg.Required,
+			})
+		}
+		prompts = append(prompts, prompt)
+	}
+	klog.V(2).InfoS("Listed prompts from MCP server", "count", len(prompts), "server", c.Name)
+	return prompts, nil
+}
+
+// GetPrompt renders a prompt of the MCP server with the given arguments, and returns
+// the text of its messages.
+func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[string]string) (string, error) {
+	klog.V(2).InfoS("Getting MCP prompt", "server", c.Name, "prompt", name, "args", arguments)
+
+	if err := c.ensureConnected(); err != nil {
+		return "", err
+	}
+
+	result, err := c.impl.getUnderlyingClient().GetPrompt(ctx, mcp.GetPromptRequest{
+		Params: mcp.GetPromptParams{Name: name, Arguments: arguments},
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting prompt %q: %w", name, err)
+	}
+	return promptMessagesText(result.Messages), nil
+}
+
+// promptMessagesText joins the content of prompt messages into a single text.
+// Content other than text and embedded text resources is skipped.
+func promptMessagesText(messages []mcp.PromptMessage) string {
+	var parts []string
+	for _, msg := range messages {
+		switch content := msg.Content.(type) {
+		case mcp.TextContent:
+			parts = append(parts, content.Text)
+		case mcp.EmbeddedResource:
+			if resource, ok := content.Resource.(mcp.TextResourceContents); ok {
+				parts = append(parts, resource.Text)
+			}
+		default:
+			klog.V(2).InfoS("Skipping unsupported MCP prompt content", "type", fmt.Sprintf("%T", msg.Content))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// ListPrompts returns the prompts of all connected servers, sorted by server and name.
+func (m *Manager) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var prompts []Prompt
+	for name, client := range m.clients {
+		serverPrompts, err := client.ListPrompts(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing prompts from MCP server %q: %w", name, err)
+		}
+		prompts = append(prompts, serverPrompts...)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].ID() < prompts[j].ID()
+	})
+	return prompts, nil
+}
+
+// GetPrompt renders a prompt of a connected server, see Client.GetPrompt.
+func (m *Manager) GetPrompt(ctx context.Context, serverName, name string, arguments map[string]string) (string, error) {
+	client, ok := m.GetClient(serverName)
+	if !ok {
+		return "", fmt.Errorf("MCP server %q is not connected", serverName)
+	}
+	return client.GetPrompt(ctx, name, arguments)
+}
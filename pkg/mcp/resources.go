@@ -0,0 +1,148 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// Resource is a resource of an MCP server loaded as context for the session.
+type Resource struct {
+	Server string
+	URI    string
+}
+
+// ID returns the name of the resource shown to the user, server/uri.
+func (r Resource) ID() string {
+	return r.Server + "/" + r.URI
+}
+
+// ResourceUpdateHandler is called when a server reports that one of its resources changed.
+// It is called from the goroutine reading the messages of the server, so it must not
+// make requests to the server itself.
+type ResourceUpdateHandler func(Resource)
+
+// ReadResource reads a resource of the MCP server, and returns its text content.
+// Binary content is skipped.
+func (c *Client) ReadResource(ctx context.Context, uri string) (string, error) {
+	klog.V(2).InfoS("Reading MCP resource", "server", c.Name, "uri", uri)
+
+	if err := c.ensureConnected(); err != nil {
+		return "", err
+	}
+
+	result, err := c.impl.getUnderlyingClient().ReadResource(ctx, mcp.ReadResourceRequest{
+		Params: mcp.ReadResourceParams{URI: uri},
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading resource %q: %w", uri, err)
+	}
+	return resourceContentsText(result.Contents), nil
+}
+
+// resourceContentsText joins the text contents of a resource.
+func resourceContentsText(contents []mcp.ResourceContents) string {
+	var parts []string
+	for _, content := range contents {
+		switch content := content.(type) {
+		case mcp.TextResourceContents:
+			parts = append(parts, content.Text)
+		case *mcp.TextResourceContents:
+			parts = append(parts, content.Text)
+		default:
+			klog.V(2).InfoS("Skipping unsupported MCP resource content", "type", fmt.Sprintf("%T", content))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// watchResources calls onUpdate when the server reports that one of its configured
+// resources changed, and subscribes to their updates. Servers that do not support
+// subscriptions are skipped: their resources are only read once.
+func watchResources(ctx context.Context, client *Client, serverCfg ServerConfig, onUpdate ResourceUpdateHandler) {
+	if len(serverCfg.Resources) == 0 || onUpdate == nil {
+		return
+	}
+	underlying := client.impl.getUnderlyingClient()
+	if caps := underlying.GetServerCapabilities().Resources; caps == nil || !caps.Subscribe {
+		klog.V(2).InfoS("MCP server does not support resource subscriptions", "server", serverCfg.Name)
+		return
+	}
+
+	configured := make(map[string]bool, len(serverCfg.Resources))
+	for _, uri := range serverCfg.Resources {
+		configured[uri] = true
+	}
+	underlying.OnNotification(func(notification mcp.JSONRPCNotification) {
+		uri, ok := resourceUpdatedURI(notification)
+		if !ok || !configured[uri] {
+			return
+		}
+		klog.V(2).InfoS("MCP resource updated", "server", serverCfg.Name, "uri", uri)
+		onUpdate(Resource{Server: serverCfg.Name, URI: uri})
+	})
+
+	for _, uri := range serverCfg.Resources {
+		err := underlying.Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: uri}})
+		if err != nil {
+			klog.Warningf("Failed to subscribe to MCP resource %q of server %q: %v", uri, serverCfg.Name, err)
+		}
+	}
+}
+
+// resourceUpdatedURI returns the URI of the resource a resource update notification is about.
+func resourceUpdatedURI(notification mcp.JSONRPCNotification) (string, bool) {
+	if notification.Method != mcp.MethodNotificationResourceUpdated {
+		return "", false
+	}
+	uri, ok := notification.Params.AdditionalFields["uri"].(string)
+	return uri, ok && uri != ""
+}
+
+// SetResourceUpdateHandler sets the function called when a configured resource changes.
+// It must be called before connecting to the servers.
+func (m *Manager) SetResourceUpdateHandler(handler ResourceUpdateHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resourceUpdated = handler
+}
+
+// Resources returns the configured resources of the connected servers, in configuration order.
+func (m *Manager) Resources() []Resource {
+	var resources []Resource
+	for _, serverCfg := range m.configuredServers() {
+		if _, ok := m.GetClient(serverCfg.Name); !ok {
+			continue
+		}
+		for _, uri := range serverCfg.Resources {
+			resources = append(resources, Resource{Server: serverCfg.Name, URI: uri})
+		}
+	}
+	return resources
+}
+
+// ReadResource reads a resource of a connected server, see Client.ReadResource.
+func (m *Manager) ReadResource(ctx context.Context, resource Resource) (string, error) {
+	client, ok := m.GetClient(resource.Server)
+	if !ok {
+		return "", fmt.Errorf("MCP server %q is not connected", resource.Server)
+	}
+	return client.ReadResource(ctx, resource.URI)
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestResourceUpdatedURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		wantURI string
+		wantOK  bool
+	}{
+		{
+			name:    "resource updated",
+			message: `{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///runbooks/oncall.md"}}`,
+			wantURI: "file:///runbooks/oncall.md",
+			wantOK:  true,
+		},
+		{
+			name:    "other notification",
+			message: `{"jsonrpc":"2.0","method":"notifications/tools/list_changed","params":{"uri":"file:///runbooks/oncall.md"}}`,
+		},
+		{
+			name:    "missing uri",
+			message: `{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notification mcp.JSONRPCNotification
+			if err := json.Unmarshal([]byte(tt.message), &notification); err != nil {
+				t.Fatalf("parsing notification: %v", err)
+			}
+			uri, ok := resourceUpdatedURI(notification)
+			if uri != tt.wantURI || ok != tt.wantOK {
+				t.Errorf("resourceUpdatedURI() = %q, %v, want %q, %v", uri, ok, tt.wantURI, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResourceContentsText(t *testing.T) {
+	contents := []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: "inventory://clusters", Text: "prod-eu"},
+		mcp.BlobResourceContents{URI: "inventory://logo", Blob: "aGVsbG8="},
+		mcp.TextResourceContents{URI: "inventory://clusters", Text: "prod-us"},
+	}
+	if got, want := resourceContentsText(contents), "prod-eu\n\nprod-us"; got != want {
+		t.Errorf("resourceContentsText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/klog/v2"
+)
+
+// DefaultSamplingMaxTokens caps the tokens of a sampling request if the server
+// configuration does not.
+const DefaultSamplingMaxTokens = 2048
+
+// SamplingConfig lets an MCP server request LLM completions through the model
+// kubectl-ai is configured with (MCP sampling).
+type SamplingConfig struct {
+	// Enabled advertises the sampling capability to the server
+	Enabled bool `yaml:"enabled,omitempty"`
+	// AutoApprove answers requests without asking the user first
+	AutoApprove bool `yaml:"autoApprove,omitempty"`
+	// MaxTokens caps the tokens a single request may ask for. Defaults to DefaultSamplingMaxTokens.
+	MaxTokens int `yaml:"maxTokens,omitempty"`
+	// TokenBudget caps the tokens used by the requests of the server over a session.
+	// Tokens are estimated from the length of prompts and responses. No limit if zero.
+	TokenBudget int `yaml:"tokenBudget,omitempty"`
+}
+
+// SamplingMessage is a message of a sampling request.
+type SamplingMessage struct {
+	// Role is "user" or "assistant"
+	Role string
+	Text string
+}
+
+// SamplingRequest is a request of an MCP server for an LLM completion.
+type SamplingRequest struct {
+	Server       string
+	SystemPrompt string
+	Messages     []SamplingMessage
+	// MaxTokens is the number of tokens the server asked for, capped by the configuration
+	MaxTokens int
+	// AutoApprove is set if the server configuration does not require the user's approval
+	AutoApprove bool
+}
+
+// SamplingResult is the completion returned to the server.
+type SamplingResult struct {
+	Text  string
+	Model string
+}
+
+// SamplingHandler answers sampling requests, typically by asking the user for
+// approval and calling the LLM.
+type SamplingHandler func(ctx context.Context, request *SamplingRequest) (*SamplingResult, error)
+
+// SetSamplingHandler sets the handler of the sampling requests of the servers that
+// have sampling enabled. It must be called before connecting to the servers.
+func (m *Manager) SetSamplingHandler(handler SamplingHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sampling = handler
+}
+
+// samplingAdapter answers the sampling requests of a server with the handler of the
+// manager, enforcing the limits of the server configuration.
+type samplingAdapter struct {
+	manager *Manager
+	server  string
+	config  SamplingConfig
+}
+
+func (a *samplingAdapter) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	req := &SamplingRequest{
+		Server:       a.server,
+		SystemPrompt: request.CreateMessageParams.SystemPrompt,
+		MaxTokens:    request.CreateMessageParams.MaxTokens,
+		AutoApprove:  a.config.AutoApprove,
+	}
+	promptLen := len(req.SystemPrompt)
+	for _, msg := range request.CreateMessageParams.Messages {
+		text, ok := samplingText(msg.Content)
+		if !ok {
+			return nil, fmt.Errorf("only text content is supported in sampling requests")
+		}
+		req.Messages = append(req.Messages, SamplingMessage{Role: string(msg.Role), Text: text})
+		promptLen += len(text)
+	}
+
+	maxTokens := a.config.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultSamplingMaxTokens
+	}
+	if req.MaxTokens <= 0 || req.MaxTokens > maxTokens {
+		req.MaxTokens = maxTokens
+	}
+
+	promptTokens := estimateTokens(promptLen)
+	if !a.manager.reserveSamplingTokens(a.server, promptTokens+req.MaxTokens, a.config.TokenBudget) {
+		klog.Warningf("Refused sampling request of MCP server %q: token budget of %d exhausted", a.server, a.config.TokenBudget)
+		return nil, fmt.Errorf("the sampling token budget of %d tokens is exhausted", a.config.TokenBudget)
+	}
+
+	a.manager.mu.RLock()
+	handler := a.manager.sampling
+	a.manager.mu.RUnlock()
+	result, err := handler(ctx, req)
+	if err != nil {
+		a.manager.reserveSamplingTokens(a.server, -(promptTokens + req.MaxTokens), 0)
+		return nil, err
+	}
+
+	// Charge what was actually used instead of the reservation
+	used := promptTokens + estimateTokens(len(result.Text))
+	a.manager.reserveSamplingTokens(a.server, used-(promptTokens+req.MaxTokens), 0)
+
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent(result.Text),
+		},
+		Model:      result.Model,
+		StopReason: "endTurn",
+	}, nil
+}
+
+// reserveSamplingTokens adds tokens to the sampling usage of a server, unless that
+// exceeds a non-zero budget. Negative tokens release a reservation.
+func (m *Manager) reserveSamplingTokens(server string, tokens, budget int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if budget > 0 && m.samplingUsed[server]+tokens > budget {
+		return false
+	}
+	m.samplingUsed[server] += tokens
+	return true
+}
+
+// samplingText returns the text of the content of a sampling message.
+func samplingText(content any) (string, bool) {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text, true
+	case *mcp.TextContent:
+		return c.Text, true
+	case map[string]any:
+		// Content is not always decoded into the concrete types
+		if c["type"] == "text" {
+			text, ok := c["text"].(string)
+			return text, ok
+		}
+	}
+	return "", false
+}
+
+// estimateTokens estimates the number of tokens of a text of n bytes.
+func estimateTokens(n int) int {
+	return (n + 3) / 4
+}
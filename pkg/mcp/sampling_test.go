@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSamplingAdapterLimits(t *testing.T) {
+	m := NewManager(&Config{})
+	var got []*SamplingRequest
+	m.SetSamplingHandler(func(ctx context.Context, req *SamplingRequest) (*SamplingResult, error) {
+		got = append(got, req)
+		// 100 tokens
+		return &SamplingResult{Text: strings.Repeat("x", 400), Model: "test-model"}, nil
+	})
+	adapter := &samplingAdapter{manager: m, server: "docs", config: SamplingConfig{MaxTokens: 500, TokenBudget: 700}}
+
+	request := func(maxTokens int) (*mcp.CreateMessageResult, error) {
+		req := mcp.CreateMessageRequest{}
+		req.MaxTokens = maxTokens
+		// 25 tokens
+		req.Messages = []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent(strings.Repeat("q", 100))}}
+		return adapter.CreateMessage(context.Background(), req)
+	}
+
+	result, err := request(4000)
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if got[0].MaxTokens != 500 {
+		t.Errorf("MaxTokens = %d, want it capped to 500", got[0].MaxTokens)
+	}
+	if result.Model != "test-model" {
+		t.Errorf("Model = %q, want test-model", result.Model)
+	}
+	if used := m.samplingUsed["docs"]; used != 125 {
+		t.Errorf("used tokens = %d, want 125", used)
+	}
+
+	// 125 used: reserving 25 + 500 more fits in the budget of 700, and uses 125 more
+	if _, err := request(500); err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+	// 250 used: reserving 25 + 500 more is over the budget
+	if _, err := request(500); err == nil || !strings.Contains(err.Error(), "budget") {
+		t.Errorf("third request error = %v, want budget error", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("handler called %d times, want 2", len(got))
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	mcp "github.com/mark3labs/mcp-go/mcp"
 	"k8s.io/klog/v2"
 )
@@ -29,20 +30,22 @@ import (
 
 // stdioClient is an MCP client that communicates via standard I/O
 type stdioClient struct {
-	name    string
-	command string
-	args    []string
-	env     []string
-	client  *mcpclient.Client
+	name     string
+	command  string
+	args     []string
+	env      []string
+	sampling mcpclient.SamplingHandler
+	client   *mcpclient.Client
 }
 
 // NewStdioClient creates a new stdio-based MCP client
 func NewStdioClient(config ClientConfig) MCPClient {
 	return &stdioClient{
-		name:    config.Name,
-		command: config.Command,
-		args:    config.Args,
-		env:     config.Env,
+		name:     config.Name,
+		command:  config.Command,
+		args:     config.Args,
+		env:      config.Env,
+		sampling: config.Sampling,
 	}
 }
 
@@ -75,10 +78,11 @@ func (c *stdioClient) Connect(ctx context.Context) error {
 	}
 
 	// Create the stdio MCP client
-	client, err := mcpclient.NewStdioMCPClient(expandedCmd, c.env, c.args...)
-	if err != nil {
+	stdioTransport := transport.NewStdio(expandedCmd, c.env, c.args...)
+	if err := stdioTransport.Start(context.Background()); err != nil {
 		return fmt.Errorf("creating stdio MCP client: %w", err)
 	}
+	client := mcpclient.NewClient(stdioTransport, clientOptions(c.sampling)...)
 
 	c.client = client
 
@@ -0,0 +1,200 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify posts notifications about the requests of the agent, e.g. a task that
+// completed or a command waiting for approval, to webhooks and Slack.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// EventType is what a notification is about.
+type EventType string
+
+const (
+	// EventCompleted is sent when a request is answered.
+	EventCompleted EventType = "completed"
+	// EventFailed is sent when a request ends with an error.
+	EventFailed EventType = "failed"
+	// EventApprovalRequired is sent when a request waits for the user to approve a command.
+	EventApprovalRequired EventType = "approval-required"
+	// EventFindingsChanged is sent when the findings of a scheduled check differ from its previous run.
+	EventFindingsChanged EventType = "findings-changed"
+)
+
+// Event is a notification, sent as is to webhooks.
+type Event struct {
+	Type      EventType `json:"type"`
+	SessionID string    `json:"sessionID"`
+	// Query is the request of the user.
+	Query string `json:"query"`
+	// Summary is the answer, the error, or the commands waiting for approval.
+	Summary string `json:"summary"`
+	// DurationSeconds is how long the request has been running.
+	DurationSeconds float64 `json:"durationSeconds"`
+	// URL links to the session in the web UI, if its URL is known.
+	URL       string    `json:"url,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Config configures where notifications are sent, and which.
+type Config struct {
+	// WebhookURL receives events as JSON POST requests.
+	WebhookURL string
+	// SlackWebhookURL is a Slack incoming webhook, posting events to its channel.
+	SlackWebhookURL string
+	// Events are the types of events to send. All if empty.
+	Events []EventType
+	// MinDuration is how long interactive requests must run to be notified about, so that
+	// users are not notified about requests they are watching. Tasks run once are always.
+	MinDuration time.Duration
+	// UIURL is the URL of the web UI, to link to sessions.
+	UIURL string
+}
+
+// maxSummaryLength truncates summaries, notifications are not the place for long answers.
+const maxSummaryLength = 1000
+
+// queueSize is the number of notifications buffered before they are dropped.
+const queueSize = 100
+
+// Notifier sends notifications in the background, so that slow endpoints do not slow down the agent.
+type Notifier struct {
+	config Config
+	client *http.Client
+	queue  chan Event
+	done   chan struct{}
+
+	closeOnce sync.Once
+}
+
+// New creates a Notifier, or returns nil if config has no destination.
+func New(config Config) *Notifier {
+	if config.WebhookURL == "" && config.SlackWebhookURL == "" {
+		return nil
+	}
+	n := &Notifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan Event, queueSize),
+		done:   make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// MinDuration returns how long interactive requests must run to be notified about.
+func (n *Notifier) MinDuration() time.Duration {
+	return n.config.MinDuration
+}
+
+// Notify queues event, unless its type is not configured. URL and Timestamp are set.
+func (n *Notifier) Notify(event Event) {
+	if len(n.config.Events) > 0 && !slices.Contains(n.config.Events, event.Type) {
+		return
+	}
+	if n.config.UIURL != "" && event.SessionID != "" {
+		event.URL = strings.TrimSuffix(n.config.UIURL, "/") + "/?session=" + url.QueryEscape(event.SessionID)
+	}
+	if len(event.Summary) > maxSummaryLength {
+		event.Summary = strings.ToValidUTF8(event.Summary[:maxSummaryLength], "") + "..."
+	}
+	event.Timestamp = time.Now()
+	select {
+	case n.queue <- event:
+	default:
+		klog.Warningf("Notifications are not keeping up, dropped %q notification", event.Type)
+	}
+}
+
+func (n *Notifier) run() {
+	defer close(n.done)
+	for event := range n.queue {
+		if n.config.WebhookURL != "" {
+			if err := n.post(n.config.WebhookURL, event); err != nil {
+				klog.Warningf("Sending notification to webhook: %v", err)
+			}
+		}
+		if n.config.SlackWebhookURL != "" {
+			if err := n.post(n.config.SlackWebhookURL, map[string]string{"text": slackText(event)}); err != nil {
+				klog.Warningf("Sending notification to Slack: %v", err)
+			}
+		}
+	}
+}
+
+func (n *Notifier) post(url string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling notification: %w", err)
+	}
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("posting notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("posting notification: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// slackText formats event in Slack mrkdwn.
+func slackText(event Event) string {
+	duration := time.Duration(event.DurationSeconds * float64(time.Second)).Round(time.Second)
+	var b strings.Builder
+	switch event.Type {
+	case EventCompleted:
+		fmt.Fprintf(&b, ":white_check_mark: *kubectl-ai* completed a task in %s", duration)
+	case EventFailed:
+		fmt.Fprintf(&b, ":x: *kubectl-ai* failed a task after %s", duration)
+	case EventApprovalRequired:
+		b.WriteString(":raising_hand: *kubectl-ai* is waiting for your approval")
+	case EventFindingsChanged:
+		b.WriteString(":mag: *kubectl-ai* found changes in a scheduled check")
+	default:
+		fmt.Fprintf(&b, "*kubectl-ai*: %s", event.Type)
+	}
+	fmt.Fprintf(&b, "\n*Task:* %s", slackEscape(event.Query))
+	if event.Summary != "" {
+		fmt.Fprintf(&b, "\n>%s", strings.ReplaceAll(slackEscape(event.Summary), "\n", "\n>"))
+	}
+	if event.URL != "" {
+		fmt.Fprintf(&b, "\n<%s|Open the session>", event.URL)
+	}
+	return b.String()
+}
+
+// slackEscape escapes the characters Slack uses for markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// Close sends the queued notifications and stops the notifier.
+func (n *Notifier) Close() error {
+	n.closeOnce.Do(func() { close(n.queue) })
+	<-n.done
+	return nil
+}
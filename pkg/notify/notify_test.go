@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// receiver records the JSON bodies POSTed to it.
+type receiver struct {
+	mu     sync.Mutex
+	bodies []map[string]any
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body map[string]any
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+}
+
+func TestNotifier(t *testing.T) {
+	webhook, slack := &receiver{}, &receiver{}
+	webhookServer, slackServer := httptest.NewServer(webhook), httptest.NewServer(slack)
+	defer webhookServer.Close()
+	defer slackServer.Close()
+
+	n := New(Config{
+		WebhookURL:      webhookServer.URL,
+		SlackWebhookURL: slackServer.URL,
+		Events:          []EventType{EventCompleted, EventApprovalRequired},
+		UIURL:           "https://kubectl-ai.example.com/",
+	})
+	n.Notify(Event{Type: EventFailed, SessionID: "s1", Query: "scale web"})
+	n.Notify(Event{Type: EventCompleted, SessionID: "s1", Query: "scale <web>", Summary: "Scaled web to 3 replicas.", DurationSeconds: 75})
+	n.Close()
+
+	if len(webhook.bodies) != 1 || len(slack.bodies) != 1 {
+		t.Fatalf("got %d webhook and %d Slack notifications, want the completed one only", len(webhook.bodies), len(slack.bodies))
+	}
+	event := webhook.bodies[0]
+	if event["type"] != "completed" || event["summary"] != "Scaled web to 3 replicas." || event["url"] != "https://kubectl-ai.example.com/?session=s1" {
+		t.Errorf("webhook event = %v", event)
+	}
+	text, _ := slack.bodies[0]["text"].(string)
+	for _, want := range []string{"completed a task in 1m15s", "scale &lt;web&gt;", ">Scaled web to 3 replicas.", "<https://kubectl-ai.example.com/?session=s1|Open the session>"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Slack text %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestNewWithoutDestination(t *testing.T) {
+	if n := New(Config{UIURL: "http://localhost:8888"}); n != nil {
+		t.Errorf("New() = %v, want nil without webhooks", n)
+	}
+}
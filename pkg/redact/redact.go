@@ -0,0 +1,251 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact removes secrets, such as tokens, passwords and private keys,
+// from text and structured values before they are stored or sent elsewhere.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces redacted values.
+const Placeholder = "[REDACTED]"
+
+// sensitiveKey matches the names of fields and headers holding secrets.
+var sensitiveKey = regexp.MustCompile(`(?i)^(.*[-_])?(password|passwd|secret|token|api[-_]?key|private[-_]?key|client[-_]key[-_]data|authorization|cookie|credentials?)$`)
+
+// rule replaces the matches of pattern with replacement, which can refer to groups.
+type rule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+var rules = []rule{
+	// PEM private keys
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), Placeholder},
+	// HTTP headers carrying credentials, e.g. in request dumps
+	{regexp.MustCompile(`(?im)^((?:proxy-)?authorization|x-goog-api-key|x-api-key|api-key|cookie):[^\r\n]*`), "$1: " + Placeholder},
+	// Bearer and basic credentials
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`), "$1 " + Placeholder},
+	// JSON Web Tokens, e.g. service account tokens
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), Placeholder},
+	// Cloud provider keys
+	{regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`), Placeholder},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z\-_]{35}\b`), Placeholder},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9\-_]{20,}\b`), Placeholder},
+	// key: value and key=value pairs with a sensitive key, in YAML, flags and env vars
+	{regexp.MustCompile(`(?i)\b([\w-]*(?:password|passwd|secret|token|api[-_]?key|client-key-data))(["']?\s*[:=]\s*["']?)[^\s"',}]+`), "${1}${2}" + Placeholder},
+}
+
+// lastAppliedAnnotation is where kubectl apply keeps a copy of the object, data included.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+var (
+	// secretKind matches the kind of Secret objects in YAML or JSON output.
+	secretKind = regexp.MustCompile(`(?m)^[\s-]*"?kind"?\s*:\s*"?Secret"?,?\s*$`)
+	// secretDataField matches the start of the data or stringData block of a Secret.
+	secretDataField = regexp.MustCompile(`^(\s*)(?:- )?"?(?:data|stringData)"?\s*:\s*\{?\s*$`)
+	// secretEntry matches a key and its value, in YAML or JSON.
+	secretEntry = regexp.MustCompile(`^(\s*(?:"[^"]*"|[^\s:"][^:]*)\s*:\s*)(.*?)(,?)\s*$`)
+	// lastApplied matches the last applied configuration annotation.
+	lastApplied = regexp.MustCompile(`^(\s*"?` + regexp.QuoteMeta(lastAppliedAnnotation) + `"?\s*:\s*)(.*?)(,?)\s*$`)
+)
+
+// SecretData returns text, YAML or JSON as printed by kubectl, with the values of the
+// data and stringData of the Secrets it holds replaced by Placeholder, along with the
+// copy of the Secrets kubectl apply keeps in an annotation. The keys are kept. Text
+// without Secrets is returned as is.
+func SecretData(text string) string {
+	if !secretKind.MatchString(text) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if m := lastApplied.FindStringSubmatch(line); m != nil {
+			out = append(out, m[1]+placeholderLike(m[2])+m[3])
+			// Skip the lines of a YAML block scalar
+			i = endOfBlock(lines, i, indentation(line)) - 1
+			continue
+		}
+		m := secretDataField.FindStringSubmatch(line)
+		if m == nil {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, line)
+		end := endOfBlock(lines, i, len(m[1]))
+		entryIndent := -1
+		for _, entry := range lines[i+1 : end] {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			if entryIndent == -1 {
+				entryIndent = indentation(entry)
+			}
+			// Deeper lines are the rest of multi-line values
+			if indentation(entry) != entryIndent {
+				continue
+			}
+			if e := secretEntry.FindStringSubmatch(entry); e != nil {
+				out = append(out, e[1]+placeholderLike(e[2])+e[3])
+			}
+		}
+		i = end - 1
+	}
+	return strings.Join(out, "\n")
+}
+
+// endOfBlock returns the index of the first line after lines[start] that is not indented
+// deeper than indent, ignoring empty lines.
+func endOfBlock(lines []string, start, indent int) int {
+	end := start + 1
+	for end < len(lines) && (strings.TrimSpace(lines[end]) == "" || indentation(lines[end]) > indent) {
+		end++
+	}
+	return end
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// placeholderLike returns Placeholder, quoted if value is a JSON string.
+func placeholderLike(value string) string {
+	if strings.HasPrefix(value, `"`) {
+		return `"` + Placeholder + `"`
+	}
+	return Placeholder
+}
+
+// String returns s with the secrets it contains replaced by Placeholder, including
+// the data of Kubernetes Secrets (see SecretData).
+func String(s string) string {
+	s = SecretData(s)
+	for _, r := range rules {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// Value returns a copy of v with secrets replaced by Placeholder: the values of
+// sensitive fields are replaced entirely, and secrets in other strings are redacted
+// with String. Structs are converted to their JSON form (maps and slices) first.
+// It reports whether anything was redacted.
+func Value(v any) (any, bool) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		// Not representable as JSON; fall back to redacting its string form
+		s := fmt.Sprint(v)
+		redacted := String(s)
+		return redacted, redacted != s
+	}
+	return redactGeneric(generic)
+}
+
+func redactGeneric(v any) (any, bool) {
+	switch v := v.(type) {
+	case string:
+		redacted := String(v)
+		return redacted, redacted != v
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		changed := false
+		for key, value := range v {
+			if isSecretValue(value) && sensitiveKey.MatchString(key) {
+				out[key] = Placeholder
+				changed = true
+				continue
+			}
+			redacted, ok := redactGeneric(value)
+			out[key] = redacted
+			changed = changed || ok
+		}
+		if v["kind"] == "Secret" {
+			changed = redactSecretObject(out) || changed
+		}
+		return out, changed
+	case []any:
+		out := make([]any, len(v))
+		changed := false
+		for i, value := range v {
+			redacted, ok := redactGeneric(value)
+			out[i] = redacted
+			changed = changed || ok
+		}
+		return out, changed
+	default:
+		return v, false
+	}
+}
+
+// redactSecretObject replaces the values of the data and stringData of a Secret, and the
+// copy kubectl apply keeps in an annotation. secret is modified in place.
+func redactSecretObject(secret map[string]any) bool {
+	changed := false
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := secret[field].(map[string]any)
+		if !ok {
+			continue
+		}
+		for key := range values {
+			values[key] = Placeholder
+			changed = true
+		}
+	}
+	if metadata, ok := secret["metadata"].(map[string]any); ok {
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			if _, ok := annotations[lastAppliedAnnotation]; ok {
+				annotations[lastAppliedAnnotation] = Placeholder
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// isSecretValue reports whether the value of a sensitive field should be replaced:
+// strings, and lists such as the values of HTTP headers.
+func isSecretValue(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
+
+// toGeneric converts v to the maps, slices and scalars of its JSON form.
+func toGeneric(v any) (any, error) {
+	switch v.(type) {
+	case nil, string:
+		return v, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
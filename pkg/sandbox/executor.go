@@ -17,8 +17,18 @@ package sandbox
 import (
 	"context"
 	"fmt"
+	"runtime"
+
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
 )
 
+// DefaultImage is the container image of Kubernetes sandboxes, if none is set.
+const DefaultImage = "bitnami/kubectl:latest"
+
+// DefaultNamespace is the namespace of the pods of Kubernetes sandboxes, if none is set.
+const DefaultNamespace = "computer"
+
 // Executor defines the interface for executing commands.
 type Executor interface {
 	// Execute runs a command and returns the result.
@@ -41,3 +51,38 @@ type ExecResult struct {
 func (e *ExecResult) String() string {
 	return fmt.Sprintf("Command: %q\nError: %q\nStdout: %q\nStderr: %q\nExitCode: %d\nStreamType: %q}", e.Command, e.Error, e.Stdout, e.Stderr, e.ExitCode, e.StreamType)
 }
+
+// NewExecutor creates the executor for a kind of sandbox: "k8s" runs commands in a
+// new pod using kubeconfig and image, "seatbelt" in a macOS sandbox, and "" locally.
+func NewExecutor(kind, kubeconfig, image string) (Executor, error) {
+	switch kind {
+	case "k8s":
+		name := fmt.Sprintf("kubectl-ai-sandbox-%s", uuid.New().String()[:8])
+		if image == "" {
+			image = DefaultImage
+		}
+		sb, err := NewKubernetesSandbox(name,
+			WithKubeconfig(kubeconfig),
+			WithImage(image),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sandbox: %w", err)
+		}
+		klog.Info("Created sandbox", "name", name, "image", image)
+		return sb, nil
+
+	case "seatbelt":
+		if runtime.GOOS != "darwin" {
+			return nil, fmt.Errorf("seatbelt sandbox is only supported on macOS")
+		}
+		klog.Info("Using Seatbelt executor")
+		return NewSeatbeltExecutor(), nil
+
+	case "":
+		// No sandbox, use local executor
+		return NewLocalExecutor(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown sandbox type: %s", kind)
+	}
+}
@@ -102,7 +102,7 @@ type Option func(*KubernetesSandbox) error
 func NewKubernetesSandbox(name string, opts ...Option) (*KubernetesSandbox, error) {
 	s := &KubernetesSandbox{
 		name:      name,
-		namespace: "computer", // default namespace
+		namespace: DefaultNamespace,
 	}
 
 	// Apply options
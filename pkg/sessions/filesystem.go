@@ -16,12 +16,16 @@ package sessions
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
 	"sigs.k8s.io/yaml"
@@ -52,11 +56,20 @@ func (f *filesystemStore) GetSession(id string) (*api.Session, error) {
 		return nil, err
 	}
 
+	name := meta.Name
+	if name == "" {
+		name = "Session " + id
+	}
+
 	chatStore := NewFileChatMessageStore(sessionPath)
 	return &api.Session{
 		ID:               id,
+		Name:             name,
+		Pinned:           meta.Pinned,
 		ProviderID:       meta.ProviderID,
 		ModelID:          meta.ModelID,
+		Owner:            meta.Owner,
+		Kubeconfig:       meta.Kubeconfig,
 		AgentState:       api.AgentStateIdle,
 		CreatedAt:        meta.CreatedAt,
 		LastModified:     meta.LastAccessed,
@@ -74,10 +87,14 @@ func (f *filesystemStore) CreateSession(session *api.Session) error {
 	session.ChatMessageStore = chatStore
 
 	meta := Metadata{
+		Name:         session.Name,
+		Pinned:       session.Pinned,
 		ProviderID:   session.ProviderID,
 		ModelID:      session.ModelID,
 		CreatedAt:    session.CreatedAt,
 		LastAccessed: session.LastModified,
+		Owner:        session.Owner,
+		Kubeconfig:   session.Kubeconfig,
 	}
 
 	data, err := yaml.Marshal(meta)
@@ -85,7 +102,7 @@ func (f *filesystemStore) CreateSession(session *api.Session) error {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(sessionPath, "metadata.yaml"), data, 0o644)
+	return writeFileAtomic(filepath.Join(sessionPath, "metadata.yaml"), data)
 }
 
 func (f *filesystemStore) UpdateSession(session *api.Session) error {
@@ -105,6 +122,8 @@ func (f *filesystemStore) UpdateSession(session *api.Session) error {
 		return err
 	}
 
+	meta.Name = session.Name
+	meta.Pinned = session.Pinned
 	meta.ProviderID = session.ProviderID
 	meta.ModelID = session.ModelID
 	meta.LastAccessed = session.LastModified
@@ -114,7 +133,7 @@ func (f *filesystemStore) UpdateSession(session *api.Session) error {
 		return err
 	}
 
-	return os.WriteFile(metadataPath, data, 0o644)
+	return writeFileAtomic(metadataPath, data)
 }
 
 func (f *filesystemStore) ListSessions() ([]*api.Session, error) {
@@ -151,6 +170,47 @@ func (f *filesystemStore) DeleteSession(id string) error {
 	return os.RemoveAll(sessionPath)
 }
 
+// runningFile marks a session whose agent is processing a request. It holds the PID of
+// the process running the agent.
+const runningFile = "running"
+
+// SetRunning marks the session as running in this process, or clears the mark.
+func (f *filesystemStore) SetRunning(id string, running bool) error {
+	path := filepath.Join(f.basePath, id, runningFile)
+	if !running {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return writeFileAtomic(path, []byte(strconv.Itoa(os.Getpid())))
+}
+
+// Interrupted reports whether the session is marked running by a process that is gone.
+func (f *filesystemStore) Interrupted(id string) bool {
+	data, err := os.ReadFile(filepath.Join(f.basePath, id, runningFile))
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return true
+	}
+	// Sessions are checked before this process starts any agent, so our own PID was left
+	// by an earlier process that had the same PID, e.g. PID 1 in a container.
+	return pid == os.Getpid() || !processAlive(pid)
+}
+
+// processAlive reports whether a process with the PID exists, signalling it with signal 0.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
 // FileChatMessageStore implements api.ChatMessageStore by persisting history to disk.
 type FileChatMessageStore struct {
 	Path string
@@ -211,13 +271,9 @@ func (s *FileChatMessageStore) AddChatMessage(record *api.Message) error {
 	}
 	defer f.Close()
 
-	if _, err := f.Write(data); err != nil {
-		return err
-	}
-	if _, err := f.WriteString("\n"); err != nil {
-		return err
-	}
-	return nil
+	// A single write, so that the line is never left without its newline
+	_, err = f.Write(append(data, '\n'))
+	return err
 }
 
 // SetChatMessages replaces the history file with the provided messages.
@@ -318,23 +374,40 @@ func (s *FileChatMessageStore) writeMessages(messages []*api.Message) error {
 		return err
 	}
 
-	f, err := os.OpenFile(s.HistoryPath(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
+	var b bytes.Buffer
 	for _, msg := range messages {
 		data, err := json.Marshal(msg)
 		if err != nil {
 			return err
 		}
-		if _, err := f.Write(data); err != nil {
-			return err
-		}
-		if _, err := f.WriteString("\n"); err != nil {
-			return err
-		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	return writeFileAtomic(s.HistoryPath(), b.Bytes())
+}
+
+// writeFileAtomic replaces the file at path with data, through a temporary file renamed
+// over it, so that the process stopping midway never leaves a truncated file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
 	}
-	return nil
+	return os.Rename(f.Name(), path)
 }
@@ -57,6 +57,8 @@ func (sm *SessionManager) NewSession(meta Metadata) (*api.Session, error) {
 		Name:         "Session " + sessionID,
 		ProviderID:   meta.ProviderID,
 		ModelID:      meta.ModelID,
+		Owner:        meta.Owner,
+		Kubeconfig:   meta.Kubeconfig,
 		AgentState:   api.AgentStateIdle,
 		CreatedAt:    now,
 		LastModified: now,
@@ -101,6 +103,79 @@ func (sm *SessionManager) GetLatestSession() (*api.Session, error) {
 	return latest, nil
 }
 
+// RenameSession sets the display name of a session.
+func (sm *SessionManager) RenameSession(id, name string) error {
+	session, err := sm.store.GetSession(id)
+	if err != nil {
+		return err
+	}
+	session.Name = name
+	return sm.store.UpdateSession(session)
+}
+
+// SetSessionPinned pins a session to the top of session lists, or unpins it.
+func (sm *SessionManager) SetSessionPinned(id string, pinned bool) error {
+	session, err := sm.store.GetSession(id)
+	if err != nil {
+		return err
+	}
+	session.Pinned = pinned
+	return sm.store.UpdateSession(session)
+}
+
+// SetSessionRunning records whether the agent of a session is processing a request, so that
+// a request interrupted by a crash can be recovered on the next start. It is a no-op for
+// stores that do not outlive the process.
+func (sm *SessionManager) SetSessionRunning(id string, running bool) error {
+	tracker, ok := sm.store.(runTracker)
+	if !ok {
+		return nil
+	}
+	return tracker.SetRunning(id, running)
+}
+
+// InterruptedSessions returns the sessions that were processing a request when the process
+// running their agent died.
+func (sm *SessionManager) InterruptedSessions() ([]*api.Session, error) {
+	tracker, ok := sm.store.(runTracker)
+	if !ok {
+		return nil, nil
+	}
+	sessions, err := sm.store.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	var interrupted []*api.Session
+	for _, session := range sessions {
+		if tracker.Interrupted(session.ID) {
+			interrupted = append(interrupted, session)
+		}
+	}
+	return interrupted, nil
+}
+
+// ForkSession creates a new session with the given name holding a copy of the messages of
+// the session, e.g. to keep a conversation before it is rewritten.
+func (sm *SessionManager) ForkSession(session *api.Session, name string) (*api.Session, error) {
+	fork, err := sm.NewSession(Metadata{
+		ProviderID: session.ProviderID,
+		ModelID:    session.ModelID,
+		Owner:      session.Owner,
+		Kubeconfig: session.Kubeconfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fork.Name = name
+	if err := sm.store.UpdateSession(fork); err != nil {
+		return nil, err
+	}
+	if err := fork.ChatMessageStore.SetChatMessages(session.AllMessages()); err != nil {
+		return nil, fmt.Errorf("copying messages: %w", err)
+	}
+	return fork, nil
+}
+
 func (sm *SessionManager) UpdateLastAccessed(session *api.Session) error {
 	session.LastModified = time.Now()
 	return sm.store.UpdateSession(session)
@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessions
+
+import (
+	"testing"
+)
+
+func TestRenameAndPinSession(t *testing.T) {
+	dir := t.TempDir()
+	memory := newMemoryStore()
+	tests := []struct {
+		name string
+		// store returns the store to use, and a new store over the same data, as after a restart
+		store func() Store
+	}{
+		{name: "memory", store: func() Store { return memory }},
+		{name: "filesystem", store: func() Store { return newFilesystemStore(dir) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := &SessionManager{store: tt.store()}
+			session, err := sm.NewSession(Metadata{ModelID: "test-model"})
+			if err != nil {
+				t.Fatalf("NewSession() failed: %v", err)
+			}
+
+			if err := sm.RenameSession(session.ID, "debug web"); err != nil {
+				t.Fatalf("RenameSession() failed: %v", err)
+			}
+			if err := sm.SetSessionPinned(session.ID, true); err != nil {
+				t.Fatalf("SetSessionPinned() failed: %v", err)
+			}
+
+			reopened := &SessionManager{store: tt.store()}
+			got, err := reopened.FindSessionByID(session.ID)
+			if err != nil {
+				t.Fatalf("FindSessionByID() failed: %v", err)
+			}
+			if got.Name != "debug web" || !got.Pinned {
+				t.Errorf("session is named %q, pinned %v; want debug web, pinned", got.Name, got.Pinned)
+			}
+			if got.ModelID != "test-model" {
+				t.Errorf("session model = %q after the update, want test-model", got.ModelID)
+			}
+
+			if err := reopened.SetSessionPinned(session.ID, false); err != nil {
+				t.Fatalf("SetSessionPinned() failed: %v", err)
+			}
+			list, err := sm.ListSessions()
+			if err != nil {
+				t.Fatalf("ListSessions() failed: %v", err)
+			}
+			for _, s := range list {
+				if s.ID == session.ID && (s.Pinned || s.Name != "debug web") {
+					t.Errorf("listed session is named %q, pinned %v; want debug web, unpinned", s.Name, s.Pinned)
+				}
+			}
+
+			if err := sm.RenameSession("missing", "x"); err == nil {
+				t.Errorf("RenameSession() of a missing session succeeded")
+			}
+			if err := sm.SetSessionPinned("missing", true); err == nil {
+				t.Errorf("SetSessionPinned() of a missing session succeeded")
+			}
+		})
+	}
+}
@@ -31,6 +31,10 @@ type Metadata struct {
 	ModelID      string    `json:"modelID"`
 	CreatedAt    time.Time `json:"createdAt"`
 	LastAccessed time.Time `json:"lastAccessed"`
+	Name         string    `json:"name,omitempty"`
+	Pinned       bool      `json:"pinned,omitempty"`
+	Owner        string    `json:"owner,omitempty"`
+	Kubeconfig   string    `json:"kubeconfig,omitempty"`
 }
 
 var defaultMemoryStore Store = newMemoryStore()
@@ -43,6 +47,13 @@ type Store interface {
 	DeleteSession(id string) error
 }
 
+// runTracker is implemented by stores that outlive the process, to find the sessions whose
+// agent was processing a request when the process died.
+type runTracker interface {
+	SetRunning(id string, running bool) error
+	Interrupted(id string) bool
+}
+
 func NewStore(backend string) (Store, error) {
 	switch backend {
 	case "memory":
@@ -0,0 +1,237 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/bundle"
+)
+
+// maxBundleResults limits the resources and matches returned by the bundle tools.
+const maxBundleResults = 200
+
+// BundleTools returns the tools retrieving the resources and logs of an attached support bundle.
+func BundleTools(b *bundle.Bundle) []Tool {
+	return []Tool{
+		&BundleListResources{bundle: b},
+		&BundleGetResource{bundle: b},
+		&BundleReadFile{bundle: b},
+		&BundleGrep{bundle: b},
+	}
+}
+
+// bundleTool implements the methods shared by the bundle tools, which only read the bundle.
+type bundleTool struct{}
+
+func (bundleTool) IsInteractive(args map[string]any) (bool, error) { return false, nil }
+
+func (bundleTool) CheckModifiesResource(args map[string]any) string { return "no" }
+
+// BundleListResources lists the resources and files of the bundle.
+type BundleListResources struct {
+	bundleTool
+	bundle *bundle.Bundle
+}
+
+func (t *BundleListResources) Name() string { return "bundle_list_resources" }
+
+func (t *BundleListResources) Description() string {
+	return `Lists the Kubernetes resources of the support bundle attached to the session, an offline snapshot of the cluster, optionally of a kind and namespace, with the files they are in. Without a kind, it also lists the log files of the bundle.`
+}
+
+func (t *BundleListResources) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"kind": {
+					Type:        gollm.TypeString,
+					Description: `Kind of the resources, e.g. Pod or Event. All kinds if empty.`,
+				},
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the resources. All namespaces if empty.`,
+				},
+			},
+		},
+	}
+}
+
+func (t *BundleListResources) Run(ctx context.Context, args map[string]any) (any, error) {
+	kind, _ := args["kind"].(string)
+	namespace, _ := args["namespace"].(string)
+	resources := t.bundle.FindResources(kind, namespace)
+	result := map[string]any{"total": len(resources)}
+	if len(resources) > maxBundleResults {
+		resources = resources[:maxBundleResults]
+		result["truncated"] = true
+	}
+	result["resources"] = resources
+	if kind == "" {
+		var logs []string
+		for _, f := range t.bundle.Files {
+			if f.Type == bundle.FileTypeLog {
+				logs = append(logs, f.Path)
+			}
+		}
+		result["logFiles"] = logs
+	}
+	return result, nil
+}
+
+// BundleGetResource returns the manifest of a resource of the bundle.
+type BundleGetResource struct {
+	bundleTool
+	bundle *bundle.Bundle
+}
+
+func (t *BundleGetResource) Name() string { return "bundle_get_resource" }
+
+func (t *BundleGetResource) Description() string {
+	return `Returns the YAML manifest, with its status, of a Kubernetes resource of the support bundle attached to the session.`
+}
+
+func (t *BundleGetResource) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"kind": {
+					Type:        gollm.TypeString,
+					Description: `Kind of the resource, e.g. Pod.`,
+				},
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the resource; it can be omitted if the name is unique.`,
+				},
+				"name": {
+					Type:        gollm.TypeString,
+					Description: `Name of the resource.`,
+				},
+			},
+			Required: []string{"kind", "name"},
+		},
+	}
+}
+
+func (t *BundleGetResource) Run(ctx context.Context, args map[string]any) (any, error) {
+	kind, _ := args["kind"].(string)
+	namespace, _ := args["namespace"].(string)
+	name, _ := args["name"].(string)
+	manifest, err := t.bundle.GetResource(kind, namespace, name)
+	if err != nil {
+		return map[string]any{"error": err.Error()}, nil
+	}
+	return manifest, nil
+}
+
+// BundleReadFile reads a file of the bundle, e.g. the logs of a container.
+type BundleReadFile struct {
+	bundleTool
+	bundle *bundle.Bundle
+}
+
+func (t *BundleReadFile) Name() string { return "bundle_read_file" }
+
+func (t *BundleReadFile) Description() string {
+	return `Reads a file of the support bundle attached to the session, e.g. the logs of a container. Long files are truncated to their end; read the last lines with tail_lines.`
+}
+
+func (t *BundleReadFile) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"path": {
+					Type:        gollm.TypeString,
+					Description: `Path of the file in the bundle, as listed by bundle_list_resources.`,
+				},
+				"tail_lines": {
+					Type:        gollm.TypeInteger,
+					Description: `Only read the last lines of the file. All if 0.`,
+				},
+			},
+			Required: []string{"path"},
+		},
+	}
+}
+
+func (t *BundleReadFile) Run(ctx context.Context, args map[string]any) (any, error) {
+	p, _ := args["path"].(string)
+	tail := 0
+	if n, ok := args["tail_lines"].(float64); ok {
+		tail = int(n)
+	}
+	content, err := t.bundle.ReadFile(p, tail)
+	if err != nil {
+		return map[string]any{"error": err.Error()}, nil
+	}
+	return content, nil
+}
+
+// BundleGrep searches the files of the bundle.
+type BundleGrep struct {
+	bundleTool
+	bundle *bundle.Bundle
+}
+
+func (t *BundleGrep) Name() string { return "bundle_grep" }
+
+func (t *BundleGrep) Description() string {
+	return `Searches the files of the support bundle attached to the session for lines matching a regular expression, e.g. errors in logs or the events of a resource.`
+}
+
+func (t *BundleGrep) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"pattern": {
+					Type:        gollm.TypeString,
+					Description: `Regular expression (Go syntax), e.g. (?i)error|oomkilled.`,
+				},
+				"files": {
+					Type:        gollm.TypeString,
+					Description: `Glob pattern of the paths or names of the files to search, e.g. *.txt or */kube-system/*. All files if empty.`,
+				},
+			},
+			Required: []string{"pattern"},
+		},
+	}
+}
+
+func (t *BundleGrep) Run(ctx context.Context, args map[string]any) (any, error) {
+	pattern, _ := args["pattern"].(string)
+	files, _ := args["files"].(string)
+	matches, truncated, err := t.bundle.Grep(pattern, files, maxBundleResults)
+	if err != nil {
+		return map[string]any{"error": err.Error()}, nil
+	}
+	result := map[string]any{"matches": matches}
+	if truncated {
+		result["truncated"] = true
+	}
+	return result, nil
+}
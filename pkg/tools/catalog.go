@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+)
+
+// Safety classifies tools by whether their calls modify resources.
+type Safety string
+
+const (
+	// SafetyReadOnly tools never modify resources.
+	SafetyReadOnly Safety = "read-only"
+	// SafetyModifying tools always modify resources.
+	SafetyModifying Safety = "modifying"
+	// SafetyPerCall tools may modify resources depending on their arguments, which is
+	// checked for each call.
+	SafetyPerCall Safety = "per-call"
+)
+
+// SafetyClassifier is implemented by tools whose safety cannot be told from
+// CheckModifiesResource without arguments, e.g. read-only unless an argument is set.
+type SafetyClassifier interface {
+	Safety() Safety
+}
+
+// ToolInfo describes a tool for clients, e.g. to render a tool palette.
+type ToolInfo struct {
+	// Name is the name the LLM calls the tool with, unique for the tools of MCP servers
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Parameters  *gollm.Schema `json:"parameters,omitempty"`
+	Safety      Safety        `json:"safety"`
+	// MCPServer is the server providing the tool, for the tools of MCP servers
+	MCPServer string `json:"mcpServer,omitempty"`
+}
+
+// Catalog describes the registered tools, sorted by name.
+func (t *Tools) Catalog() []ToolInfo {
+	var catalog []ToolInfo
+	for _, name := range t.Names() {
+		tool := t.tools[name]
+		info := ToolInfo{
+			Name:        name,
+			Description: tool.Description(),
+			Safety:      ClassifySafety(tool),
+		}
+		if def := tool.FunctionDefinition(); def != nil {
+			info.Parameters = def.Parameters
+		}
+		if mcpTool, ok := tool.(*MCPTool); ok {
+			info.MCPServer = mcpTool.ServerName()
+		}
+		catalog = append(catalog, info)
+	}
+	return catalog
+}
+
+// ClassifySafety returns the safety of a tool: its own classification, or the one of
+// CheckModifiesResource without arguments.
+func ClassifySafety(tool Tool) Safety {
+	if c, ok := tool.(SafetyClassifier); ok {
+		return c.Safety()
+	}
+	switch tool.CheckModifiesResource(map[string]any{}) {
+	case "no":
+		return SafetyReadOnly
+	case "yes":
+		return SafetyModifying
+	default:
+		return SafetyPerCall
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+)
+
+func TestCatalog(t *testing.T) {
+	executor := sandbox.NewLocalExecutor()
+	var tools Tools
+	tools.Init()
+	tools.RegisterTool(NewKubectlTool(executor))
+	tools.RegisterTool(NewNodeHealthTool(executor))
+	tools.RegisterTool(NewCheckConnectivityTool(executor))
+	tools.RegisterTool(NewProposeChangeTool(nil))
+
+	want := map[string]Safety{
+		"check_connectivity": SafetyPerCall,
+		"kubectl":            SafetyPerCall,
+		"node_health":        SafetyReadOnly,
+		"propose_change":     SafetyModifying,
+	}
+	catalog := tools.Catalog()
+	if len(catalog) != len(want) {
+		t.Fatalf("Catalog() = %+v, want %d tools", catalog, len(want))
+	}
+	for i, info := range catalog {
+		if i > 0 && catalog[i-1].Name > info.Name {
+			t.Errorf("Catalog() is not sorted: %q before %q", catalog[i-1].Name, info.Name)
+		}
+		if info.Safety != want[info.Name] {
+			t.Errorf("safety of %q = %q, want %q", info.Name, info.Safety, want[info.Name])
+		}
+		if info.Description == "" || info.Parameters == nil {
+			t.Errorf("%q has no description or parameters", info.Name)
+		}
+	}
+}
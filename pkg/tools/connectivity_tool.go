@@ -0,0 +1,667 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// maxConnectivityPaths limits the endpoints of a destination service evaluated.
+	maxConnectivityPaths = 10
+	// probeImage runs the connectivity probe, with nc.
+	probeImage = "busybox:1.36"
+	// probeTimeout is how long the probe waits for a connection, and twice how long the
+	// tool waits for the probe.
+	probeTimeout = 5 * time.Second
+)
+
+// CheckConnectivity reasons about whether a pod can reach a pod or service, from the
+// network policies, services and endpoints involved.
+type CheckConnectivity struct {
+	executor sandbox.Executor
+}
+
+func NewCheckConnectivityTool(executor sandbox.Executor) *CheckConnectivity {
+	return &CheckConnectivity{executor: executor}
+}
+
+func (t *CheckConnectivity) Name() string {
+	return "check_connectivity"
+}
+
+func (t *CheckConnectivity) Description() string {
+	return `Determines whether a source pod can reach a destination pod or service on a port: it collects the network policies selecting the source (egress) and the destination pods (ingress), the service and its endpoints, and evaluates which policies allow or block the traffic, reporting issues like services without ready endpoints or unknown ports.
+With "probe", it also tests the connection with nc from an ephemeral container added to the source pod, which shares its network and so its policies. Use it when a pod cannot reach another pod or a service.`
+}
+
+func (t *CheckConnectivity) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"source_namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the source pod.`,
+				},
+				"source_pod": {
+					Type:        gollm.TypeString,
+					Description: `Name of the source pod.`,
+				},
+				"destination_namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the destination.`,
+				},
+				"destination_pod": {
+					Type:        gollm.TypeString,
+					Description: `Name of the destination pod. Set either destination_pod or destination_service.`,
+				},
+				"destination_service": {
+					Type:        gollm.TypeString,
+					Description: `Name of the destination service.`,
+				},
+				"port": {
+					Type:        gollm.TypeString,
+					Description: `Port of the destination, a number or a name: a port of the service, or a container port of the pod. Optional for services with a single port.`,
+				},
+				"protocol": {
+					Type:        gollm.TypeString,
+					Description: `Protocol of the traffic: TCP (default), UDP or SCTP.`,
+				},
+				"probe": {
+					Type:        gollm.TypeBoolean,
+					Description: `Also test the connection from an ephemeral container added to the source pod. Only TCP is probed.`,
+				},
+			},
+			Required: []string{"source_namespace", "source_pod", "destination_namespace"},
+		},
+	}
+}
+
+func (t *CheckConnectivity) Run(ctx context.Context, args map[string]any) (any, error) {
+	sourceNamespace, _ := args["source_namespace"].(string)
+	sourcePod, _ := args["source_pod"].(string)
+	destinationNamespace, _ := args["destination_namespace"].(string)
+	destinationPod, _ := args["destination_pod"].(string)
+	destinationService, _ := args["destination_service"].(string)
+	port, _ := args["port"].(string)
+	protocol := corev1.Protocol(strings.ToUpper(stringArg(args, "protocol", "TCP")))
+	probe, _ := args["probe"].(bool)
+
+	// Names are passed to the shell of the executor
+	for _, n := range []struct{ name, value string }{
+		{"source_namespace", sourceNamespace}, {"destination_namespace", destinationNamespace},
+	} {
+		if errs := validation.IsDNS1123Label(n.value); len(errs) > 0 {
+			return &sandbox.ExecResult{Error: fmt.Sprintf("invalid %s %q: %s", n.name, n.value, strings.Join(errs, ", "))}, nil
+		}
+	}
+	for _, n := range []struct{ name, value string }{
+		{"source_pod", sourcePod}, {"destination_pod", destinationPod}, {"destination_service", destinationService},
+	} {
+		if n.value == "" && n.name != "source_pod" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(n.value); len(errs) > 0 {
+			return &sandbox.ExecResult{Error: fmt.Sprintf("invalid %s %q: %s", n.name, n.value, strings.Join(errs, ", "))}, nil
+		}
+	}
+	if (destinationPod == "") == (destinationService == "") {
+		return &sandbox.ExecResult{Error: "set either destination_pod or destination_service"}, nil
+	}
+	if protocol != corev1.ProtocolTCP && protocol != corev1.ProtocolUDP && protocol != corev1.ProtocolSCTP {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("unsupported protocol %q, supported protocols: TCP, UDP, SCTP", protocol)}, nil
+	}
+
+	c := &connectivityCheck{tool: t, ctx: ctx, namespaces: map[string]*corev1.Namespace{}, policies: map[string][]networkingv1.NetworkPolicy{}}
+	var source corev1.Pod
+	if failed, err := c.get("kubectl get pod "+sourcePod+" --namespace "+sourceNamespace+" -o json", &source); failed != nil || err != nil {
+		return failed, err
+	}
+	result := &connectivityResult{
+		Source:   connectivityEndpoint{Namespace: sourceNamespace, Pod: sourcePod, IP: source.Status.PodIP, Labels: source.Labels},
+		Protocol: string(protocol),
+	}
+	if source.Spec.HostNetwork {
+		result.Issues = append(result.Issues, "the source pod uses the host network: network policies do not select it, its traffic comes from the IP of its node")
+	}
+
+	var destinations []corev1.Pod
+	if destinationPod != "" {
+		var pod corev1.Pod
+		if failed, err := c.get("kubectl get pod "+destinationPod+" --namespace "+destinationNamespace+" -o json", &pod); failed != nil || err != nil {
+			return failed, err
+		}
+		if port == "" {
+			return &sandbox.ExecResult{Error: "port is required for pod destinations"}, nil
+		}
+		destinations = []corev1.Pod{pod}
+		result.Destination = connectivityEndpoint{Namespace: destinationNamespace, Pod: destinationPod, IP: pod.Status.PodIP, Labels: pod.Labels}
+	} else {
+		pods, targetPort, failed, err := c.serviceEndpoints(result, destinationNamespace, destinationService, port, protocol)
+		if failed != nil || err != nil {
+			return failed, err
+		}
+		destinations = pods
+		port = targetPort.String()
+	}
+
+	for _, destination := range destinations {
+		if len(result.Paths) == maxConnectivityPaths {
+			result.Truncated = true
+			break
+		}
+		path, failed, err := c.evaluate(&source, &destination, port, protocol)
+		if failed != nil || err != nil {
+			return failed, err
+		}
+		result.Paths = append(result.Paths, path)
+	}
+	result.Allowed = len(result.Paths) > 0
+	for _, path := range result.Paths {
+		result.Allowed = result.Allowed && path.Allowed
+	}
+
+	if probe {
+		if len(result.Paths) == 0 {
+			result.Issues = append(result.Issues, "no destination to probe")
+		} else if protocol != corev1.ProtocolTCP {
+			result.Issues = append(result.Issues, "only TCP connections are probed")
+		} else {
+			// Services are probed through their cluster IP, unless headless
+			target, targetPort := result.Paths[0].IP, result.Paths[0].Port
+			if destinationService != "" && net.ParseIP(result.Destination.IP) != nil {
+				target, targetPort = result.Destination.IP, result.ServicePort
+			}
+			probeResult, err := c.probe(&source, target, targetPort)
+			if err != nil {
+				return nil, err
+			}
+			result.Probe = probeResult
+		}
+	}
+	return result, nil
+}
+
+func (t *CheckConnectivity) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+// CheckModifiesResource returns "yes" for probes, which add an ephemeral container to
+// the source pod.
+func (t *CheckConnectivity) CheckModifiesResource(args map[string]any) string {
+	if probe, _ := args["probe"].(bool); probe {
+		return "yes"
+	}
+	return "no"
+}
+
+// Safety returns SafetyPerCall, as only probes modify resources.
+func (t *CheckConnectivity) Safety() Safety {
+	return SafetyPerCall
+}
+
+type connectivityResult struct {
+	Source      connectivityEndpoint `json:"source"`
+	Destination connectivityEndpoint `json:"destination"`
+	Protocol    string               `json:"protocol"`
+	// ServicePort is the port of the destination service, its endpoints listening on
+	// the ports of the paths
+	ServicePort int32 `json:"servicePort,omitempty"`
+	// Allowed is whether the policies allow the traffic to all the destination pods
+	Allowed bool `json:"allowed"`
+	// Paths are the evaluations of the traffic to each destination pod
+	Paths     []connectivityPath `json:"paths"`
+	Truncated bool               `json:"truncated,omitempty"`
+	// Issues are the problems found besides network policies, e.g. a service without endpoints
+	Issues []string           `json:"issues,omitempty"`
+	Probe  *connectivityProbe `json:"probe,omitempty"`
+}
+
+type connectivityEndpoint struct {
+	Namespace string            `json:"namespace"`
+	Pod       string            `json:"pod,omitempty"`
+	Service   string            `json:"service,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type connectivityPath struct {
+	Pod     string       `json:"pod"`
+	IP      string       `json:"ip"`
+	Port    int32        `json:"port"`
+	Allowed bool         `json:"allowed"`
+	Egress  policyResult `json:"egress"`
+	Ingress policyResult `json:"ingress"`
+}
+
+// policyResult is the evaluation of the network policies of one side of the traffic.
+type policyResult struct {
+	// Isolated is whether policies select the pod for this direction: only the traffic
+	// they allow is, otherwise all traffic is allowed
+	Isolated bool `json:"isolated"`
+	Allowed  bool `json:"allowed"`
+	// Policies are the policies selecting the pod, AllowedBy the ones allowing the traffic
+	Policies  []string `json:"policies,omitempty"`
+	AllowedBy []string `json:"allowedBy,omitempty"`
+	Note      string   `json:"note,omitempty"`
+}
+
+type connectivityProbe struct {
+	Command   string `json:"command"`
+	Container string `json:"container"`
+	Reachable bool   `json:"reachable"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// connectivityCheck caches the namespaces and policies read while evaluating paths.
+type connectivityCheck struct {
+	tool       *CheckConnectivity
+	ctx        context.Context
+	namespaces map[string]*corev1.Namespace
+	policies   map[string][]networkingv1.NetworkPolicy
+}
+
+func (c *connectivityCheck) get(command string, v any) (*sandbox.ExecResult, error) {
+	return kubectlGetJSON(c.ctx, c.tool.executor, command, v)
+}
+
+// serviceEndpoints returns the ready pods backing the port of a service, and the port
+// they listen on. Issues are added to result.
+func (c *connectivityCheck) serviceEndpoints(result *connectivityResult, namespace, name, port string, protocol corev1.Protocol) ([]corev1.Pod, intstr.IntOrString, *sandbox.ExecResult, error) {
+	var targetPort intstr.IntOrString
+	var service corev1.Service
+	if failed, err := c.get("kubectl get service "+name+" --namespace "+namespace+" -o json", &service); failed != nil || err != nil {
+		return nil, targetPort, failed, err
+	}
+	result.Destination = connectivityEndpoint{Namespace: namespace, Service: name, IP: service.Spec.ClusterIP}
+
+	var servicePort *corev1.ServicePort
+	var ports []string
+	for i, p := range service.Spec.Ports {
+		ports = append(ports, fmt.Sprintf("%d/%s (%s)", p.Port, p.Protocol, p.Name))
+		if p.Protocol == protocol && (port == "" && len(service.Spec.Ports) == 1 || port == p.Name || port == strconv.Itoa(int(p.Port))) {
+			servicePort = &service.Spec.Ports[i]
+		}
+	}
+	if servicePort == nil {
+		return nil, targetPort, &sandbox.ExecResult{Error: fmt.Sprintf("service %s has no %s port %q, its ports: %s", name, protocol, port, strings.Join(ports, ", "))}, nil
+	}
+	result.ServicePort = servicePort.Port
+	targetPort = servicePort.TargetPort
+	if targetPort.IntVal == 0 && targetPort.StrVal == "" {
+		targetPort = intstr.FromInt32(servicePort.Port)
+	}
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		result.Issues = append(result.Issues, "the service is an ExternalName service, resolving to "+service.Spec.ExternalName+": network policies only apply to the traffic to that name")
+		return nil, targetPort, nil, nil
+	}
+	if len(service.Spec.Selector) == 0 {
+		result.Issues = append(result.Issues, "the service has no selector: its endpoints are managed manually and are not evaluated")
+		return nil, targetPort, nil, nil
+	}
+
+	var slices discoveryv1.EndpointSliceList
+	if failed, err := c.get("kubectl get endpointslices --namespace "+namespace+" --selector "+discoveryv1.LabelServiceName+"="+name+" -o json", &slices); failed != nil || err != nil {
+		return nil, targetPort, failed, err
+	}
+	ready := map[string]bool{}
+	notReady := 0
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready[endpoint.TargetRef.Name] = true
+			} else {
+				notReady++
+			}
+		}
+	}
+	if len(ready) == 0 {
+		result.Issues = append(result.Issues, fmt.Sprintf("the service has no ready endpoints (%d not ready): check that pods match its selector %s and pass their readiness probes", notReady, labels.Set(service.Spec.Selector)))
+		return nil, targetPort, nil, nil
+	}
+	if notReady > 0 {
+		result.Issues = append(result.Issues, fmt.Sprintf("%d endpoints of the service are not ready and receive no traffic", notReady))
+	}
+
+	var pods corev1.PodList
+	// Label selectors do not contain single quotes
+	if failed, err := c.get("kubectl get pods --namespace "+namespace+" --selector '"+labels.Set(service.Spec.Selector).String()+"' -o json", &pods); failed != nil || err != nil {
+		return nil, targetPort, failed, err
+	}
+	var backends []corev1.Pod
+	for _, pod := range pods.Items {
+		if ready[pod.Name] {
+			backends = append(backends, pod)
+		}
+	}
+	return backends, targetPort, nil, nil
+}
+
+// evaluate evaluates the egress policies of source and the ingress policies of
+// destination for the traffic to port.
+func (c *connectivityCheck) evaluate(source, destination *corev1.Pod, port string, protocol corev1.Protocol) (connectivityPath, *sandbox.ExecResult, error) {
+	path := connectivityPath{Pod: destination.Name, IP: destination.Status.PodIP}
+	portNumber, ok := resolvePort(destination, port, protocol)
+	if !ok {
+		return path, &sandbox.ExecResult{Error: fmt.Sprintf("pod %s has no %s container port %q", destination.Name, protocol, port)}, nil
+	}
+	path.Port = portNumber
+
+	sourceNamespace, failed, err := c.namespace(source.Namespace)
+	if failed != nil || err != nil {
+		return path, failed, err
+	}
+	destinationNamespace, failed, err := c.namespace(destination.Namespace)
+	if failed != nil || err != nil {
+		return path, failed, err
+	}
+	sourcePolicies, failed, err := c.networkPolicies(source.Namespace)
+	if failed != nil || err != nil {
+		return path, failed, err
+	}
+	destinationPolicies, failed, err := c.networkPolicies(destination.Namespace)
+	if failed != nil || err != nil {
+		return path, failed, err
+	}
+
+	path.Egress = evaluatePolicies(sourcePolicies, source, networkingv1.PolicyTypeEgress, func(policy networkingv1.NetworkPolicy) bool {
+		for _, rule := range policy.Spec.Egress {
+			if matchesPeers(rule.To, policy.Namespace, destination, destinationNamespace) && matchesPorts(rule.Ports, destination, portNumber, protocol) {
+				return true
+			}
+		}
+		return false
+	})
+	if path.Egress.Isolated && !allowsDNS(sourcePolicies, source) {
+		path.Egress.Note = "no egress rule allows DNS (port 53): the source pod may not resolve service names"
+	}
+	path.Ingress = evaluatePolicies(destinationPolicies, destination, networkingv1.PolicyTypeIngress, func(policy networkingv1.NetworkPolicy) bool {
+		for _, rule := range policy.Spec.Ingress {
+			if matchesPeers(rule.From, policy.Namespace, source, sourceNamespace) && matchesPorts(rule.Ports, destination, portNumber, protocol) {
+				return true
+			}
+		}
+		return false
+	})
+	if destination.Spec.HostNetwork {
+		path.Ingress = policyResult{Allowed: true, Note: "the destination pod uses the host network: network policies do not select it"}
+	}
+	path.Allowed = path.Egress.Allowed && path.Ingress.Allowed
+	return path, nil, nil
+}
+
+func (c *connectivityCheck) namespace(name string) (*corev1.Namespace, *sandbox.ExecResult, error) {
+	if ns, ok := c.namespaces[name]; ok {
+		return ns, nil, nil
+	}
+	var ns corev1.Namespace
+	if failed, err := c.get("kubectl get namespace "+name+" -o json", &ns); failed != nil || err != nil {
+		return nil, failed, err
+	}
+	c.namespaces[name] = &ns
+	return &ns, nil, nil
+}
+
+func (c *connectivityCheck) networkPolicies(namespace string) ([]networkingv1.NetworkPolicy, *sandbox.ExecResult, error) {
+	if policies, ok := c.policies[namespace]; ok {
+		return policies, nil, nil
+	}
+	var list networkingv1.NetworkPolicyList
+	if failed, err := c.get("kubectl get networkpolicies --namespace "+namespace+" -o json", &list); failed != nil || err != nil {
+		return nil, failed, err
+	}
+	c.policies[namespace] = list.Items
+	return list.Items, nil, nil
+}
+
+// probe tests a TCP connection from an ephemeral container of source, waiting for it to
+// terminate.
+func (c *connectivityCheck) probe(source *corev1.Pod, ip string, port int32) (*connectivityProbe, error) {
+	container := "kubectl-ai-probe-" + uuid.New().String()[:8]
+	target := net.JoinHostPort(ip, strconv.Itoa(int(port)))
+	probe := &connectivityProbe{
+		Container: container,
+		Command:   fmt.Sprintf("nc -z -v -w %d %s %d", int(probeTimeout.Seconds()), ip, port),
+	}
+	command := fmt.Sprintf("kubectl debug %s --namespace %s --image %s --container %s --quiet -- %s", source.Name, source.Namespace, probeImage, container, probe.Command)
+	started, err := c.run(command)
+	if err != nil {
+		return probe, err
+	}
+	if started.Error != "" || started.ExitCode != 0 {
+		probe.Error = strings.TrimSpace(started.Error + " " + started.Stderr)
+		return probe, nil
+	}
+
+	deadline := time.Now().Add(2 * probeTimeout)
+	for {
+		var pod corev1.Pod
+		if failed, err := c.get("kubectl get pod "+source.Name+" --namespace "+source.Namespace+" -o json", &pod); failed != nil || err != nil {
+			if failed != nil {
+				probe.Error = strings.TrimSpace(failed.Error + " " + failed.Stderr)
+			}
+			return probe, err
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name == container && status.State.Terminated != nil {
+				probe.Reachable = status.State.Terminated.ExitCode == 0
+				logs, err := c.run("kubectl logs " + source.Name + " --namespace " + source.Namespace + " --container " + container)
+				if err != nil {
+					return probe, err
+				}
+				probe.Output = strings.TrimSpace(logs.Stdout + logs.Stderr)
+				return probe, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			probe.Error = fmt.Sprintf("the probe of %s did not complete in time, e.g. because its image could not be pulled", target)
+			return probe, nil
+		}
+		select {
+		case <-c.ctx.Done():
+			return probe, c.ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (c *connectivityCheck) run(command string) (*sandbox.ExecResult, error) {
+	kubeconfig := c.ctx.Value(KubeconfigKey).(string)
+	workDir := c.ctx.Value(WorkDirKey).(string)
+	env, err := kubectlEnv(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return c.tool.executor.Execute(c.ctx, command, env, workDir)
+}
+
+// evaluatePolicies evaluates the policies of a direction selecting pod: traffic is allowed
+// if none selects it, or one allows it.
+func evaluatePolicies(policies []networkingv1.NetworkPolicy, pod *corev1.Pod, direction networkingv1.PolicyType, allows func(networkingv1.NetworkPolicy) bool) policyResult {
+	result := policyResult{}
+	for _, policy := range policies {
+		if !hasPolicyType(policy, direction) || !selectorMatches(&policy.Spec.PodSelector, pod.Labels) {
+			continue
+		}
+		result.Isolated = true
+		result.Policies = append(result.Policies, policy.Name)
+		if allows(policy) {
+			result.AllowedBy = append(result.AllowedBy, policy.Name)
+		}
+	}
+	result.Allowed = !result.Isolated || len(result.AllowedBy) > 0
+	return result
+}
+
+// hasPolicyType returns whether the policy applies to the direction: policies without
+// policy types apply to ingress, and to egress if they have egress rules.
+func hasPolicyType(policy networkingv1.NetworkPolicy, direction networkingv1.PolicyType) bool {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return direction == networkingv1.PolicyTypeIngress || len(policy.Spec.Egress) > 0
+	}
+	for _, t := range policy.Spec.PolicyTypes {
+		if t == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPeers returns whether pod, in namespace, is one of the peers of a rule of a policy
+// of policyNamespace. Rules without peers match all pods.
+func matchesPeers(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, pod *corev1.Pod, namespace *corev1.Namespace) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		switch {
+		case peer.IPBlock != nil:
+			if ipBlockContains(peer.IPBlock, pod.Status.PodIP) {
+				return true
+			}
+		case peer.NamespaceSelector != nil:
+			if selectorMatches(peer.NamespaceSelector, namespace.Labels) && (peer.PodSelector == nil || selectorMatches(peer.PodSelector, pod.Labels)) {
+				return true
+			}
+		case peer.PodSelector != nil:
+			if pod.Namespace == policyNamespace && selectorMatches(peer.PodSelector, pod.Labels) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesPorts returns whether a rule allows the port of the destination pod. Rules without
+// ports allow all ports.
+func matchesPorts(ports []networkingv1.NetworkPolicyPort, destination *corev1.Pod, port int32, protocol corev1.Protocol) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		ruleProtocol := corev1.ProtocolTCP
+		if p.Protocol != nil {
+			ruleProtocol = *p.Protocol
+		}
+		if ruleProtocol != protocol {
+			continue
+		}
+		if p.Port == nil {
+			return true
+		}
+		rulePort, ok := resolvePort(destination, p.Port.String(), protocol)
+		if !ok {
+			continue
+		}
+		if rulePort == port || (p.EndPort != nil && port >= rulePort && port <= *p.EndPort) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsDNS returns whether the egress policies selecting pod allow DNS queries.
+func allowsDNS(policies []networkingv1.NetworkPolicy, pod *corev1.Pod) bool {
+	for _, policy := range policies {
+		if !hasPolicyType(policy, networkingv1.PolicyTypeEgress) || !selectorMatches(&policy.Spec.PodSelector, pod.Labels) {
+			continue
+		}
+		for _, rule := range policy.Spec.Egress {
+			if len(rule.Ports) == 0 {
+				return true
+			}
+			for _, p := range rule.Ports {
+				if p.Port == nil || p.Port.IntValue() == 53 || (p.EndPort != nil && p.Port.IntValue() <= 53 && *p.EndPort >= 53) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// resolvePort resolves a port number, or the name of a container port of pod.
+func resolvePort(pod *corev1.Pod, port string, protocol corev1.Protocol) (int32, bool) {
+	if n, err := strconv.ParseInt(port, 10, 32); err == nil {
+		return int32(n), true
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			containerProtocol := p.Protocol
+			if containerProtocol == "" {
+				containerProtocol = corev1.ProtocolTCP
+			}
+			if p.Name == port && containerProtocol == protocol {
+				return p.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func selectorMatches(selector *metav1.LabelSelector, set map[string]string) bool {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(set))
+}
+
+func ipBlockContains(block *networkingv1.IPBlock, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(addr) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, cidr, err := net.ParseCIDR(except); err == nil && cidr.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func stringArg(args map[string]any, name, def string) string {
+	if s, ok := args[name].(string); ok && s != "" {
+		return s
+	}
+	return def
+}
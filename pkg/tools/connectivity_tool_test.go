@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCheckConnectivity(t *testing.T) {
+	executor := kubectlOutputs{
+		"kubectl get pod frontend --namespace web": `{"metadata": {"name": "frontend", "namespace": "web", "labels": {"app": "frontend"}}, "status": {"podIP": "10.0.1.5"}}`,
+		"kubectl get pod batch --namespace web":    `{"metadata": {"name": "batch", "namespace": "web", "labels": {"app": "batch"}}, "status": {"podIP": "10.0.1.6"}}`,
+		"kubectl get service api --namespace shop": `{"spec": {"clusterIP": "10.96.0.10", "selector": {"app": "api"},
+			"ports": [{"name": "http", "port": 80, "protocol": "TCP", "targetPort": "http"}]}}`,
+		"kubectl get endpointslices --namespace shop": `{"items": [{"endpoints": [
+			{"addresses": ["10.0.2.7"], "conditions": {"ready": true}, "targetRef": {"kind": "Pod", "name": "api-0"}},
+			{"addresses": ["10.0.2.8"], "conditions": {"ready": false}, "targetRef": {"kind": "Pod", "name": "api-1"}}
+		]}]}`,
+		"kubectl get pods --namespace shop": `{"items": [
+			{"metadata": {"name": "api-0", "namespace": "shop", "labels": {"app": "api"}},
+				"spec": {"containers": [{"name": "api", "ports": [{"name": "http", "containerPort": 8080}]}]},
+				"status": {"podIP": "10.0.2.7"}},
+			{"metadata": {"name": "api-1", "namespace": "shop", "labels": {"app": "api"}}, "status": {"podIP": "10.0.2.8"}}
+		]}`,
+		"kubectl get namespace web":  `{"metadata": {"name": "web", "labels": {"kubernetes.io/metadata.name": "web"}}}`,
+		"kubectl get namespace shop": `{"metadata": {"name": "shop", "labels": {"kubernetes.io/metadata.name": "shop"}}}`,
+		"kubectl get networkpolicies --namespace web": `{"items": [
+			{"metadata": {"name": "egress", "namespace": "web"}, "spec": {"podSelector": {}, "policyTypes": ["Egress"],
+				"egress": [{"to": [{"namespaceSelector": {}}], "ports": [{"port": "http"}]}]}}
+		]}`,
+		"kubectl get networkpolicies --namespace shop": `{"items": [
+			{"metadata": {"name": "default-deny", "namespace": "shop"}, "spec": {"podSelector": {}}},
+			{"metadata": {"name": "allow-frontend", "namespace": "shop"}, "spec": {"podSelector": {"matchLabels": {"app": "api"}},
+				"ingress": [{
+					"from": [{"namespaceSelector": {"matchLabels": {"kubernetes.io/metadata.name": "web"}}, "podSelector": {"matchLabels": {"app": "frontend"}}}],
+					"ports": [{"port": 8080}]
+				}]}}
+		]}`,
+	}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	tests := []struct {
+		source      string
+		wantAllowed bool
+	}{
+		{source: "frontend", wantAllowed: true},
+		{source: "batch", wantAllowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			result, err := NewCheckConnectivityTool(executor).Run(ctx, map[string]any{
+				"source_namespace":      "web",
+				"source_pod":            tt.source,
+				"destination_namespace": "shop",
+				"destination_service":   "api",
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			check, ok := result.(*connectivityResult)
+			if !ok {
+				t.Fatalf("Run() = %v, want a connectivity result", result)
+			}
+			if check.Allowed != tt.wantAllowed {
+				t.Errorf("allowed = %v, want %v", check.Allowed, tt.wantAllowed)
+			}
+			if len(check.Paths) != 1 || check.Paths[0].Pod != "api-0" || check.Paths[0].Port != 8080 {
+				t.Fatalf("paths = %+v, want the ready api-0 on port 8080", check.Paths)
+			}
+			path := check.Paths[0]
+			if !path.Egress.Isolated || !path.Egress.Allowed || path.Egress.Note == "" {
+				t.Errorf("egress = %+v, want isolated, allowed, and a note about DNS", path.Egress)
+			}
+			if !slices.Equal(path.Ingress.Policies, []string{"default-deny", "allow-frontend"}) {
+				t.Errorf("ingress policies = %v", path.Ingress.Policies)
+			}
+			if got := len(path.Ingress.AllowedBy) > 0; got != tt.wantAllowed {
+				t.Errorf("ingress allowed by %v", path.Ingress.AllowedBy)
+			}
+			if len(check.Issues) != 1 {
+				t.Errorf("issues = %v, want the endpoint not ready", check.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+)
+
+// LabelProduction labels the kubeconfig contexts that switching to always needs approval.
+const LabelProduction = "production"
+
+// KubeContext allows switching to the kubeconfig contexts matching Name, a glob pattern,
+// with the use_context tool.
+type KubeContext struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// MatchKubeContext returns the first of allowed matching the context name.
+func MatchKubeContext(allowed []KubeContext, name string) (KubeContext, bool) {
+	for _, c := range allowed {
+		if ok, _ := path.Match(c.Name, name); ok {
+			return c, true
+		}
+	}
+	return KubeContext{}, false
+}
+
+// UseContext switches the kubeconfig context of the session among the allowed ones.
+type UseContext struct {
+	allowed []KubeContext
+	// available are the contexts of the kubeconfig that are allowed
+	available []string
+	switchTo  func(ctx context.Context, name string) error
+}
+
+// NewUseContextTool creates the use_context tool. available are the allowed contexts of
+// the kubeconfig, listed to the model; switchTo switches the session to a context.
+func NewUseContextTool(allowed []KubeContext, available []string, switchTo func(ctx context.Context, name string) error) *UseContext {
+	return &UseContext{allowed: allowed, available: available, switchTo: switchTo}
+}
+
+func (t *UseContext) Name() string {
+	return "use_context"
+}
+
+func (t *UseContext) Description() string {
+	var contexts []string
+	for _, name := range t.available {
+		c, _ := MatchKubeContext(t.allowed, name)
+		if len(c.Labels) > 0 {
+			name += " (" + strings.Join(c.Labels, ", ") + ")"
+		}
+		contexts = append(contexts, name)
+	}
+	return `Switches the kubeconfig context that the kubectl and bash tools use for the rest of the session, i.e. the cluster they run against. The user is told about the switch.
+Only these contexts are allowed: ` + strings.Join(contexts, ", ") + `.`
+}
+
+func (t *UseContext) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"context": {
+					Type:        gollm.TypeString,
+					Description: `Name of the kubeconfig context to switch to.`,
+				},
+			},
+			Required: []string{"context"},
+		},
+	}
+}
+
+func (t *UseContext) Run(ctx context.Context, args map[string]any) (any, error) {
+	name, _ := args["context"].(string)
+	if !slices.Contains(t.available, name) {
+		return map[string]any{"error": fmt.Sprintf("context %q is not allowed, allowed contexts: %s", name, strings.Join(t.available, ", "))}, nil
+	}
+	if err := t.switchTo(ctx, name); err != nil {
+		return map[string]any{"error": err.Error()}, nil
+	}
+	return map[string]any{"context": name}, nil
+}
+
+// IsProduction returns whether the call switches to a context labeled production.
+func (t *UseContext) IsProduction(args map[string]any) bool {
+	name, _ := args["context"].(string)
+	c, ok := MatchKubeContext(t.allowed, name)
+	return ok && slices.Contains(c.Labels, LabelProduction)
+}
+
+func (t *UseContext) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+// CheckModifiesResource returns "no": switching contexts does not modify the cluster,
+// switching to production contexts needs approval all the same.
+func (t *UseContext) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
@@ -0,0 +1,380 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxDescribedPods limits the pods of a workload described, the ones restarting most first.
+const maxDescribedPods = 20
+
+// workloadKinds maps the names and short names of the workload kinds to their resource.
+var workloadKinds = map[string]string{
+	"deployment":   "deployment",
+	"deployments":  "deployment",
+	"deploy":       "deployment",
+	"statefulset":  "statefulset",
+	"statefulsets": "statefulset",
+	"sts":          "statefulset",
+	"daemonset":    "daemonset",
+	"daemonsets":   "daemonset",
+	"ds":           "daemonset",
+}
+
+// DescribeWorkload gathers the status of a workload and of its pods in one call.
+type DescribeWorkload struct {
+	executor sandbox.Executor
+}
+
+func NewDescribeWorkloadTool(executor sandbox.Executor) *DescribeWorkload {
+	return &DescribeWorkload{executor: executor}
+}
+
+func (t *DescribeWorkload) Name() string {
+	return "describe_workload"
+}
+
+func (t *DescribeWorkload) Description() string {
+	return `Describes a deployment, statefulset or daemonset and its pods in a single structured result: the replica counts and conditions of the workload, the containers of its pod template with their resources and probes, and the status of every container of the pods, including init and ephemeral containers, with their restart counts and the reason of their last termination.
+Prefer it over separate kubectl get and describe calls to diagnose a workload that is not healthy.`
+}
+
+func (t *DescribeWorkload) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"kind": {
+					Type:        gollm.TypeString,
+					Description: `Kind of the workload: deployment, statefulset or daemonset.`,
+				},
+				"name": {
+					Type:        gollm.TypeString,
+					Description: `Name of the workload.`,
+				},
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the workload.`,
+				},
+			},
+			Required: []string{"kind", "name", "namespace"},
+		},
+	}
+}
+
+func (t *DescribeWorkload) Run(ctx context.Context, args map[string]any) (any, error) {
+	kindArg, _ := args["kind"].(string)
+	name, _ := args["name"].(string)
+	namespace, _ := args["namespace"].(string)
+	kind, ok := workloadKinds[strings.ToLower(kindArg)]
+	if !ok {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("unsupported workload kind %q, supported kinds: deployment, statefulset, daemonset", kindArg)}, nil
+	}
+	// Names are passed to the shell of the executor
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid name %q: %s", name, strings.Join(errs, ", "))}, nil
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))}, nil
+	}
+
+	get := func(command string, v any) (*sandbox.ExecResult, error) {
+		return kubectlGetJSON(ctx, t.executor, command, v)
+	}
+
+	description := &workloadDescription{Kind: kind, Name: name, Namespace: namespace}
+	var selector *metav1.LabelSelector
+	var template corev1.PodTemplateSpec
+	command := fmt.Sprintf("kubectl get %s %s --namespace %s -o json", kind, name, namespace)
+	var failed *sandbox.ExecResult
+	var err error
+	switch kind {
+	case "deployment":
+		var d appsv1.Deployment
+		if failed, err = get(command, &d); failed != nil || err != nil {
+			return failed, err
+		}
+		selector, template = d.Spec.Selector, d.Spec.Template
+		description.Status = workloadStatus{
+			Desired:            ptrOr(d.Spec.Replicas, 1),
+			Current:            d.Status.Replicas,
+			Ready:              d.Status.ReadyReplicas,
+			Updated:            d.Status.UpdatedReplicas,
+			Available:          d.Status.AvailableReplicas,
+			Generation:         d.Generation,
+			ObservedGeneration: d.Status.ObservedGeneration,
+		}
+		for _, c := range d.Status.Conditions {
+			description.Conditions = append(description.Conditions, workloadCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+		}
+	case "statefulset":
+		var s appsv1.StatefulSet
+		if failed, err = get(command, &s); failed != nil || err != nil {
+			return failed, err
+		}
+		selector, template = s.Spec.Selector, s.Spec.Template
+		description.Status = workloadStatus{
+			Desired:            ptrOr(s.Spec.Replicas, 1),
+			Current:            s.Status.Replicas,
+			Ready:              s.Status.ReadyReplicas,
+			Updated:            s.Status.UpdatedReplicas,
+			Available:          s.Status.AvailableReplicas,
+			Generation:         s.Generation,
+			ObservedGeneration: s.Status.ObservedGeneration,
+		}
+		for _, c := range s.Status.Conditions {
+			description.Conditions = append(description.Conditions, workloadCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+		}
+	case "daemonset":
+		var ds appsv1.DaemonSet
+		if failed, err = get(command, &ds); failed != nil || err != nil {
+			return failed, err
+		}
+		selector, template = ds.Spec.Selector, ds.Spec.Template
+		description.Status = workloadStatus{
+			Desired:            ds.Status.DesiredNumberScheduled,
+			Current:            ds.Status.CurrentNumberScheduled,
+			Ready:              ds.Status.NumberReady,
+			Updated:            ds.Status.UpdatedNumberScheduled,
+			Available:          ds.Status.NumberAvailable,
+			Generation:         ds.Generation,
+			ObservedGeneration: ds.Status.ObservedGeneration,
+		}
+		for _, c := range ds.Status.Conditions {
+			description.Conditions = append(description.Conditions, workloadCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+		}
+	}
+	description.Template = describePodTemplate(template.Spec)
+
+	labels, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return &sandbox.ExecResult{Command: command, Error: fmt.Sprintf("invalid selector of the workload: %v", err)}, nil
+	}
+	var pods corev1.PodList
+	// Label selectors do not contain single quotes
+	command = fmt.Sprintf("kubectl get pods --namespace %s --selector '%s' -o json", namespace, labels.String())
+	if failed, err = get(command, &pods); failed != nil || err != nil {
+		return failed, err
+	}
+	for _, pod := range pods.Items {
+		description.Pods = append(description.Pods, describePod(pod))
+	}
+	slices.SortFunc(description.Pods, func(a, b podDescription) int {
+		return cmp.Or(cmp.Compare(b.Restarts, a.Restarts), cmp.Compare(a.Name, b.Name))
+	})
+	description.TotalPods = len(description.Pods)
+	if len(description.Pods) > maxDescribedPods {
+		description.Pods = description.Pods[:maxDescribedPods]
+		description.Truncated = true
+	}
+	return description, nil
+}
+
+func (t *DescribeWorkload) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *DescribeWorkload) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+type workloadDescription struct {
+	Kind       string              `json:"kind"`
+	Name       string              `json:"name"`
+	Namespace  string              `json:"namespace"`
+	Status     workloadStatus      `json:"status"`
+	Conditions []workloadCondition `json:"conditions,omitempty"`
+	Template   podTemplate         `json:"podTemplate"`
+	// Pods are sorted by restarts, the ones restarting most first
+	Pods      []podDescription `json:"pods"`
+	TotalPods int              `json:"totalPods"`
+	Truncated bool             `json:"truncated,omitempty"`
+}
+
+// workloadStatus are the replica counts of a workload, or the scheduled pods of daemonsets.
+type workloadStatus struct {
+	Desired            int32 `json:"desired"`
+	Current            int32 `json:"current"`
+	Ready              int32 `json:"ready"`
+	Updated            int32 `json:"updated"`
+	Available          int32 `json:"available"`
+	Generation         int64 `json:"generation"`
+	ObservedGeneration int64 `json:"observedGeneration"`
+}
+
+type workloadCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type podTemplate struct {
+	ServiceAccount string          `json:"serviceAccount,omitempty"`
+	InitContainers []containerSpec `json:"initContainers,omitempty"`
+	Containers     []containerSpec `json:"containers"`
+}
+
+type containerSpec struct {
+	Name    string   `json:"name"`
+	Image   string   `json:"image"`
+	Command []string `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	// RestartPolicy is Always for sidecar init containers
+	RestartPolicy  *corev1.ContainerRestartPolicy `json:"restartPolicy,omitempty"`
+	Resources      corev1.ResourceRequirements    `json:"resources"`
+	LivenessProbe  *corev1.Probe                  `json:"livenessProbe,omitempty"`
+	ReadinessProbe *corev1.Probe                  `json:"readinessProbe,omitempty"`
+	StartupProbe   *corev1.Probe                  `json:"startupProbe,omitempty"`
+}
+
+type podDescription struct {
+	Name                string            `json:"name"`
+	Phase               string            `json:"phase"`
+	Reason              string            `json:"reason,omitempty"`
+	Node                string            `json:"node,omitempty"`
+	Ready               bool              `json:"ready"`
+	Restarts            int32             `json:"restarts"`
+	StartTime           *time.Time        `json:"startTime,omitempty"`
+	InitContainers      []containerStatus `json:"initContainers,omitempty"`
+	Containers          []containerStatus `json:"containers"`
+	EphemeralContainers []containerStatus `json:"ephemeralContainers,omitempty"`
+}
+
+type containerStatus struct {
+	Name            string       `json:"name"`
+	Image           string       `json:"image"`
+	Ready           bool         `json:"ready"`
+	RestartCount    int32        `json:"restartCount"`
+	State           string       `json:"state"`
+	LastTermination *termination `json:"lastTermination,omitempty"`
+}
+
+type termination struct {
+	Reason     string    `json:"reason,omitempty"`
+	ExitCode   int32     `json:"exitCode"`
+	Signal     int32     `json:"signal,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+func describePodTemplate(spec corev1.PodSpec) podTemplate {
+	template := podTemplate{ServiceAccount: spec.ServiceAccountName}
+	describe := func(c corev1.Container) containerSpec {
+		return containerSpec{
+			Name:           c.Name,
+			Image:          c.Image,
+			Command:        c.Command,
+			Args:           c.Args,
+			RestartPolicy:  c.RestartPolicy,
+			Resources:      c.Resources,
+			LivenessProbe:  c.LivenessProbe,
+			ReadinessProbe: c.ReadinessProbe,
+			StartupProbe:   c.StartupProbe,
+		}
+	}
+	for _, c := range spec.InitContainers {
+		template.InitContainers = append(template.InitContainers, describe(c))
+	}
+	for _, c := range spec.Containers {
+		template.Containers = append(template.Containers, describe(c))
+	}
+	return template
+}
+
+func describePod(pod corev1.Pod) podDescription {
+	description := podDescription{
+		Name:   pod.Name,
+		Phase:  string(pod.Status.Phase),
+		Reason: pod.Status.Reason,
+		Node:   pod.Spec.NodeName,
+	}
+	if pod.Status.StartTime != nil {
+		description.StartTime = &pod.Status.StartTime.Time
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			description.Ready = c.Status == corev1.ConditionTrue
+		}
+	}
+	describe := func(statuses []corev1.ContainerStatus) []containerStatus {
+		var described []containerStatus
+		for _, s := range statuses {
+			description.Restarts += s.RestartCount
+			described = append(described, describeContainerStatus(s))
+		}
+		return described
+	}
+	description.InitContainers = describe(pod.Status.InitContainerStatuses)
+	description.Containers = describe(pod.Status.ContainerStatuses)
+	description.EphemeralContainers = describe(pod.Status.EphemeralContainerStatuses)
+	return description
+}
+
+func describeContainerStatus(s corev1.ContainerStatus) containerStatus {
+	status := containerStatus{
+		Name:         s.Name,
+		Image:        s.Image,
+		Ready:        s.Ready,
+		RestartCount: s.RestartCount,
+		State:        describeContainerState(s.State),
+	}
+	if t := s.LastTerminationState.Terminated; t != nil {
+		status.LastTermination = &termination{
+			Reason:     t.Reason,
+			ExitCode:   t.ExitCode,
+			Signal:     t.Signal,
+			Message:    t.Message,
+			FinishedAt: t.FinishedAt.Time,
+		}
+	}
+	return status
+}
+
+// describeContainerState summarizes a container state in a line, e.g.
+// "waiting: CrashLoopBackOff".
+func describeContainerState(state corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "running since " + state.Running.StartedAt.UTC().Format(time.RFC3339)
+	case state.Waiting != nil:
+		return strings.TrimSuffix(fmt.Sprintf("waiting: %s: %s", state.Waiting.Reason, state.Waiting.Message), ": ")
+	case state.Terminated != nil:
+		return fmt.Sprintf("terminated: %s (exit code %d)", state.Terminated.Reason, state.Terminated.ExitCode)
+	}
+	return "unknown"
+}
+
+func ptrOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+)
+
+// kubectlOutputs replies to kubectl get commands with the output of the longest matching prefix.
+type kubectlOutputs map[string]string
+
+func (k kubectlOutputs) Execute(ctx context.Context, command string, env []string, workDir string) (*sandbox.ExecResult, error) {
+	longest := ""
+	for prefix := range k {
+		if strings.HasPrefix(command, prefix) && len(prefix) > len(longest) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return &sandbox.ExecResult{Command: command, Stderr: "not found", ExitCode: 1}, nil
+	}
+	return &sandbox.ExecResult{Command: command, Stdout: k[longest]}, nil
+}
+
+func (k kubectlOutputs) Close(ctx context.Context) error { return nil }
+
+func TestDescribeWorkload(t *testing.T) {
+	executor := kubectlOutputs{
+		"kubectl get deployment web --namespace shop -o json": `{
+			"metadata": {"name": "web", "generation": 3},
+			"spec": {
+				"replicas": 2,
+				"selector": {"matchLabels": {"app": "web"}},
+				"template": {"spec": {
+					"initContainers": [{"name": "migrate", "image": "migrate:1"}],
+					"containers": [{"name": "app", "image": "web:2", "livenessProbe": {"httpGet": {"path": "/healthz", "port": 8080}}}]
+				}}
+			},
+			"status": {"replicas": 2, "readyReplicas": 1, "observedGeneration": 3,
+				"conditions": [{"type": "Available", "status": "False", "reason": "MinimumReplicasUnavailable"}]}
+		}`,
+		"kubectl get pods --namespace shop --selector 'app=web' -o json": `{"items": [
+			{"metadata": {"name": "web-a"}, "status": {"phase": "Running",
+				"containerStatuses": [{"name": "app", "ready": true, "state": {"running": {"startedAt": "2025-01-01T00:00:00Z"}}}]}},
+			{"metadata": {"name": "web-b"}, "status": {"phase": "Running",
+				"initContainerStatuses": [{"name": "migrate", "ready": true, "state": {"terminated": {"reason": "Completed"}}}],
+				"containerStatuses": [{"name": "app", "restartCount": 4,
+					"state": {"waiting": {"reason": "CrashLoopBackOff"}},
+					"lastState": {"terminated": {"reason": "OOMKilled", "exitCode": 137}}}]}}
+		]}`,
+	}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	result, err := NewDescribeWorkloadTool(executor).Run(ctx, map[string]any{"kind": "deploy", "name": "web", "namespace": "shop"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	description, ok := result.(*workloadDescription)
+	if !ok {
+		t.Fatalf("Run() = %v, want a workload description", result)
+	}
+	if description.Status.Desired != 2 || description.Status.Ready != 1 {
+		t.Errorf("status = %+v, want 2 desired and 1 ready", description.Status)
+	}
+	if len(description.Conditions) != 1 || description.Conditions[0].Reason != "MinimumReplicasUnavailable" {
+		t.Errorf("conditions = %+v", description.Conditions)
+	}
+	if len(description.Template.InitContainers) != 1 || description.Template.Containers[0].LivenessProbe == nil {
+		t.Errorf("pod template = %+v, want the init container and the liveness probe", description.Template)
+	}
+	if description.TotalPods != 2 || description.Pods[0].Name != "web-b" {
+		t.Fatalf("pods = %+v, want web-b, restarting, first", description.Pods)
+	}
+	app := description.Pods[0].Containers[0]
+	if app.State != "waiting: CrashLoopBackOff" || app.LastTermination == nil || app.LastTermination.Reason != "OOMKilled" {
+		t.Errorf("container status = %+v, want waiting after an OOMKilled termination", app)
+	}
+	if len(description.Pods[0].InitContainers) != 1 {
+		t.Errorf("init container statuses = %+v", description.Pods[0].InitContainers)
+	}
+
+	for _, args := range []map[string]any{
+		{"kind": "job", "name": "web", "namespace": "shop"},
+		{"kind": "deployment", "name": "web; rm -rf /", "namespace": "shop"},
+		{"kind": "deployment", "name": "missing", "namespace": "shop"},
+	} {
+		result, err := NewDescribeWorkloadTool(executor).Run(ctx, args)
+		if err != nil {
+			t.Fatalf("Run(%v) error = %v", args, err)
+		}
+		if r, ok := result.(*sandbox.ExecResult); !ok || (r.Error == "" && r.ExitCode == 0) {
+			t.Errorf("Run(%v) = %v, want an error result", args, result)
+		}
+	}
+}
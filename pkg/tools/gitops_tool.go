@@ -0,0 +1,89 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/gitops"
+)
+
+// ProposeChange proposes changes to the cluster as commits to its GitOps repository,
+// and pull requests, instead of applying them.
+type ProposeChange struct {
+	proposer *gitops.Proposer
+}
+
+func NewProposeChangeTool(proposer *gitops.Proposer) *ProposeChange {
+	return &ProposeChange{proposer: proposer}
+}
+
+func (t *ProposeChange) Name() string {
+	return "propose_change"
+}
+
+func (t *ProposeChange) Description() string {
+	return `Proposes a change to the cluster as a commit to the Git repository its manifests are deployed from, and a pull request if configured.
+Changes to the cluster are not applied directly in this session: use this tool for every change, instead of kubectl apply, patch, edit, scale, delete and the like.
+Pass the full manifests of the resources as they should be, not patches; get the current ones with kubectl get -o yaml first, and drop the status and the fields set by the server (uid, resourceVersion, creationTimestamp, managedFields).`
+}
+
+func (t *ProposeChange) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"title": {
+					Type:        gollm.TypeString,
+					Description: `Short summary of the change, used as commit subject and pull request title.`,
+				},
+				"description": {
+					Type:        gollm.TypeString,
+					Description: `Why the change is needed and what it does, for the reviewers.`,
+				},
+				"manifests": {
+					Type:        gollm.TypeString,
+					Description: `The full YAML manifests of the changed resources, separated by ---.`,
+				},
+			},
+			Required: []string{"title", "manifests"},
+		},
+	}
+}
+
+func (t *ProposeChange) Run(ctx context.Context, args map[string]any) (any, error) {
+	title, _ := args["title"].(string)
+	description, _ := args["description"].(string)
+	manifests, _ := args["manifests"].(string)
+
+	proposal, err := t.proposer.Propose(ctx, gitops.Change{Title: title, Description: description, Manifests: manifests})
+	if err != nil {
+		return map[string]any{"error": err.Error()}, nil
+	}
+	return proposal, nil
+}
+
+func (t *ProposeChange) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+// CheckModifiesResource returns "yes": the change is not applied to the cluster,
+// but it is published to the repository, so it needs approval all the same.
+func (t *ProposeChange) CheckModifiesResource(args map[string]any) string {
+	return "yes"
+}
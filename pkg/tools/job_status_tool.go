@@ -0,0 +1,427 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/cron"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// maxJobStatusItems limits the jobs of a cronjob, and the pods of a job, reported.
+	maxJobStatusItems = 10
+	// defaultJobLogLines is the number of log lines of the failed pod returned by default.
+	defaultJobLogLines = 50
+	// defaultBackoffLimit is the backoff limit of jobs that do not set one.
+	defaultBackoffLimit = 6
+)
+
+// JobStatus reports the status of a job or cronjob, with the logs of its last failure.
+type JobStatus struct {
+	executor sandbox.Executor
+}
+
+func NewJobStatusTool(executor sandbox.Executor) *JobStatus {
+	return &JobStatus{executor: executor}
+}
+
+func (t *JobStatus) Name() string {
+	return "job_status"
+}
+
+func (t *JobStatus) Description() string {
+	return `Reports the status of a job or cronjob in a single structured result: the active, succeeded and failed pod counts and conditions of the job, whether its backoff limit or deadline was exceeded, its pods, and the logs of the most recent failed pod. For cronjobs, it also reports the schedule, the last schedule and success times, missed schedules, and the status of the recent jobs, with the logs of the most recent failure.
+Prefer it over separate kubectl calls to troubleshoot a job or cronjob.`
+}
+
+func (t *JobStatus) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"kind": {
+					Type:        gollm.TypeString,
+					Description: `Kind of the workload: job or cronjob.`,
+				},
+				"name": {
+					Type:        gollm.TypeString,
+					Description: `Name of the job or cronjob.`,
+				},
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the job or cronjob.`,
+				},
+				"tail_lines": {
+					Type:        gollm.TypeInteger,
+					Description: fmt.Sprintf(`Number of log lines of the failed pod to return. Defaults to %d.`, defaultJobLogLines),
+				},
+			},
+			Required: []string{"kind", "name", "namespace"},
+		},
+	}
+}
+
+func (t *JobStatus) Run(ctx context.Context, args map[string]any) (any, error) {
+	kind, _ := args["kind"].(string)
+	name, _ := args["name"].(string)
+	namespace, _ := args["namespace"].(string)
+	tailLines := defaultJobLogLines
+	if n, ok := intArg(args, "tail_lines"); ok {
+		tailLines = int(n)
+	}
+	// Names are passed to the shell of the executor
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid name %q: %s", name, strings.Join(errs, ", "))}, nil
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))}, nil
+	}
+
+	s := &jobStatusCheck{tool: t, ctx: ctx, namespace: namespace, tailLines: tailLines}
+	switch strings.ToLower(kind) {
+	case "job", "jobs":
+		var job batchv1.Job
+		if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get job "+name+" --namespace "+namespace+" -o json", &job); failed != nil || err != nil {
+			return failed, err
+		}
+		status, failed, err := s.job(job, true)
+		if failed != nil || err != nil {
+			return failed, err
+		}
+		return status, nil
+	case "cronjob", "cronjobs", "cj":
+		var cronJob batchv1.CronJob
+		if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get cronjob "+name+" --namespace "+namespace+" -o json", &cronJob); failed != nil || err != nil {
+			return failed, err
+		}
+		return s.cronJob(cronJob)
+	}
+	return &sandbox.ExecResult{Error: fmt.Sprintf("unsupported kind %q, supported kinds: job, cronjob", kind)}, nil
+}
+
+func (t *JobStatus) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *JobStatus) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+type cronJobStatus struct {
+	Name              string     `json:"name"`
+	Namespace         string     `json:"namespace"`
+	Schedule          string     `json:"schedule"`
+	TimeZone          string     `json:"timeZone,omitempty"`
+	Suspended         bool       `json:"suspended,omitempty"`
+	ConcurrencyPolicy string     `json:"concurrencyPolicy,omitempty"`
+	LastScheduleTime  *time.Time `json:"lastScheduleTime,omitempty"`
+	LastSuccessTime   *time.Time `json:"lastSuccessfulTime,omitempty"`
+	NextScheduleTime  *time.Time `json:"nextScheduleTime,omitempty"`
+	Active            []string   `json:"active,omitempty"`
+	// Jobs are the most recent jobs of the cronjob, the last one first
+	Jobs      []jobStatus `json:"jobs"`
+	TotalJobs int         `json:"totalJobs"`
+	// LastFailure is the most recent failed job, with its pods and logs
+	LastFailure *jobStatus `json:"lastFailure,omitempty"`
+	Issues      []string   `json:"issues,omitempty"`
+}
+
+type jobStatus struct {
+	Name           string              `json:"name"`
+	Created        time.Time           `json:"created"`
+	StartTime      *time.Time          `json:"startTime,omitempty"`
+	CompletionTime *time.Time          `json:"completionTime,omitempty"`
+	Completions    int32               `json:"completions"`
+	Parallelism    int32               `json:"parallelism"`
+	BackoffLimit   int32               `json:"backoffLimit"`
+	Active         int32               `json:"active"`
+	Succeeded      int32               `json:"succeeded"`
+	Failed         int32               `json:"failed"`
+	Suspended      bool                `json:"suspended,omitempty"`
+	Conditions     []workloadCondition `json:"conditions,omitempty"`
+	// BackoffLimitExceeded is set if the job failed after retrying its pods backoffLimit times
+	BackoffLimitExceeded bool `json:"backoffLimitExceeded,omitempty"`
+	DeadlineExceeded     bool `json:"deadlineExceeded,omitempty"`
+	// Pods are the most recent pods of the job, reported for single jobs and failures
+	Pods []jobPod `json:"pods,omitempty"`
+	// FailedPod is the most recent pod that failed, with its logs
+	FailedPod *jobPod `json:"failedPod,omitempty"`
+}
+
+type jobPod struct {
+	Name       string            `json:"name"`
+	Phase      string            `json:"phase"`
+	Reason     string            `json:"reason,omitempty"`
+	Node       string            `json:"node,omitempty"`
+	Created    time.Time         `json:"created"`
+	Containers []containerStatus `json:"containers,omitempty"`
+	Logs       string            `json:"logs,omitempty"`
+	// LogsError is set if the logs of a failed pod could not be read, e.g. if it was deleted
+	LogsError string `json:"logsError,omitempty"`
+}
+
+// jobStatusCheck reads the jobs and pods of a job_status call.
+type jobStatusCheck struct {
+	tool      *JobStatus
+	ctx       context.Context
+	namespace string
+	tailLines int
+}
+
+// job reports a job, with its pods and the logs of the last failed one if withPods is set.
+func (s *jobStatusCheck) job(job batchv1.Job, withPods bool) (*jobStatus, *sandbox.ExecResult, error) {
+	status := &jobStatus{
+		Name:         job.Name,
+		Created:      job.CreationTimestamp.Time,
+		Completions:  ptrOr(job.Spec.Completions, 1),
+		Parallelism:  ptrOr(job.Spec.Parallelism, 1),
+		BackoffLimit: ptrOr(job.Spec.BackoffLimit, defaultBackoffLimit),
+		Active:       job.Status.Active,
+		Succeeded:    job.Status.Succeeded,
+		Failed:       job.Status.Failed,
+		Suspended:    ptrOr(job.Spec.Suspend, false),
+	}
+	if job.Status.StartTime != nil {
+		status.StartTime = &job.Status.StartTime.Time
+	}
+	if job.Status.CompletionTime != nil {
+		status.CompletionTime = &job.Status.CompletionTime.Time
+	}
+	for _, c := range job.Status.Conditions {
+		status.Conditions = append(status.Conditions, workloadCondition{Type: string(c.Type), Status: string(c.Status), Reason: c.Reason, Message: c.Message})
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			status.BackoffLimitExceeded = c.Reason == batchv1.JobReasonBackoffLimitExceeded
+			status.DeadlineExceeded = c.Reason == batchv1.JobReasonDeadlineExceeded
+		}
+	}
+	if !withPods {
+		return status, nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, &sandbox.ExecResult{Error: fmt.Sprintf("invalid selector of job %s: %v", job.Name, err)}, nil
+	}
+	var pods corev1.PodList
+	// Label selectors do not contain single quotes
+	if failed, err := kubectlGetJSON(s.ctx, s.tool.executor, "kubectl get pods --namespace "+s.namespace+" --selector '"+selector.String()+"' -o json", &pods); failed != nil || err != nil {
+		return nil, failed, err
+	}
+	slices.SortFunc(pods.Items, func(a, b corev1.Pod) int {
+		return b.CreationTimestamp.Compare(a.CreationTimestamp.Time)
+	})
+	var lastFailure time.Time
+	var failedPod *corev1.Pod
+	var failedContainer string
+	for i, pod := range pods.Items {
+		if i < maxJobStatusItems {
+			status.Pods = append(status.Pods, describeJobPod(pod))
+		}
+		if at, container, ok := podFailure(pod); ok && at.After(lastFailure) {
+			lastFailure, failedPod, failedContainer = at, &pods.Items[i], container
+		}
+	}
+	if failedPod != nil {
+		p := describeJobPod(*failedPod)
+		logs, err := s.logs(*failedPod, failedContainer)
+		if err != nil {
+			return nil, nil, err
+		}
+		if logs.Error != "" || logs.ExitCode != 0 {
+			p.LogsError = strings.TrimSpace(logs.Error + " " + logs.Stderr)
+		} else {
+			p.Logs = logs.Stdout
+		}
+		status.FailedPod = &p
+	}
+	return status, nil, nil
+}
+
+// logs returns the logs of the failed container of pod: of its previous run if it was
+// restarted since.
+func (s *jobStatusCheck) logs(pod corev1.Pod, container string) (*sandbox.ExecResult, error) {
+	command := fmt.Sprintf("kubectl logs %s --namespace %s --container %s --tail %d", pod.Name, s.namespace, container, s.tailLines)
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.Name == container && c.State.Terminated == nil {
+			command += " --previous"
+		}
+	}
+	env, err := kubectlEnv(s.ctx.Value(KubeconfigKey).(string))
+	if err != nil {
+		return nil, err
+	}
+	return s.tool.executor.Execute(s.ctx, command, env, s.ctx.Value(WorkDirKey).(string))
+}
+
+func (s *jobStatusCheck) cronJob(cronJob batchv1.CronJob) (any, error) {
+	status := &cronJobStatus{
+		Name:              cronJob.Name,
+		Namespace:         cronJob.Namespace,
+		Schedule:          cronJob.Spec.Schedule,
+		TimeZone:          ptrOr(cronJob.Spec.TimeZone, ""),
+		Suspended:         ptrOr(cronJob.Spec.Suspend, false),
+		ConcurrencyPolicy: string(cronJob.Spec.ConcurrencyPolicy),
+	}
+	if cronJob.Status.LastScheduleTime != nil {
+		status.LastScheduleTime = &cronJob.Status.LastScheduleTime.Time
+	}
+	if cronJob.Status.LastSuccessfulTime != nil {
+		status.LastSuccessTime = &cronJob.Status.LastSuccessfulTime.Time
+	}
+	for _, ref := range cronJob.Status.Active {
+		status.Active = append(status.Active, ref.Name)
+	}
+	if status.Suspended {
+		status.Issues = append(status.Issues, "the cronjob is suspended: no jobs are scheduled")
+	}
+	s.checkSchedule(cronJob, status, time.Now())
+
+	var jobs batchv1.JobList
+	if failed, err := kubectlGetJSON(s.ctx, s.tool.executor, "kubectl get jobs --namespace "+s.namespace+" -o json", &jobs); failed != nil || err != nil {
+		return failed, err
+	}
+	var owned []batchv1.Job
+	for _, job := range jobs.Items {
+		if slices.ContainsFunc(job.OwnerReferences, func(ref metav1.OwnerReference) bool {
+			return ref.Kind == "CronJob" && ref.UID == cronJob.UID
+		}) {
+			owned = append(owned, job)
+		}
+	}
+	slices.SortFunc(owned, func(a, b batchv1.Job) int {
+		return b.CreationTimestamp.Compare(a.CreationTimestamp.Time)
+	})
+	status.TotalJobs = len(owned)
+	for i, job := range owned {
+		if i == maxJobStatusItems {
+			break
+		}
+		summary, failed, err := s.job(job, false)
+		if failed != nil || err != nil {
+			return failed, err
+		}
+		status.Jobs = append(status.Jobs, *summary)
+	}
+	// The most recent job with failed pods is reported in full
+	for _, job := range owned {
+		if job.Status.Failed > 0 {
+			lastFailure, failed, err := s.job(job, true)
+			if failed != nil || err != nil {
+				return failed, err
+			}
+			status.LastFailure = lastFailure
+			break
+		}
+	}
+	if status.TotalJobs == 0 && status.LastScheduleTime != nil {
+		status.Issues = append(status.Issues, "the jobs of the cronjob were deleted, see its successfulJobsHistoryLimit and failedJobsHistoryLimit")
+	}
+	return status, nil
+}
+
+// checkSchedule sets the next schedule time of the cronjob, and reports missed schedules.
+func (s *jobStatusCheck) checkSchedule(cronJob batchv1.CronJob, status *cronJobStatus, now time.Time) {
+	expr, location := cronJob.Spec.Schedule, time.UTC
+	zone := status.TimeZone
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if rest, ok := strings.CutPrefix(expr, prefix); ok {
+			zone, expr, _ = strings.Cut(rest, " ")
+		}
+	}
+	if zone != "" {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			status.Issues = append(status.Issues, fmt.Sprintf("unknown time zone %q", zone))
+			return
+		}
+		location = loc
+	}
+	schedule, err := cron.Parse(strings.TrimSpace(expr))
+	if err != nil {
+		status.Issues = append(status.Issues, fmt.Sprintf("cannot parse the schedule: %v", err))
+		return
+	}
+	if next := schedule.Next(now.In(location)); !next.IsZero() {
+		status.NextScheduleTime = &next
+	}
+	if status.Suspended {
+		return
+	}
+	last := cronJob.CreationTimestamp.Time
+	if cronJob.Status.LastScheduleTime != nil {
+		last = cronJob.Status.LastScheduleTime.Time
+	}
+	if last.IsZero() {
+		return
+	}
+	// Jobs start within seconds of their schedule, or the starting deadline
+	grace := time.Minute
+	if d := cronJob.Spec.StartingDeadlineSeconds; d != nil {
+		grace = max(grace, time.Duration(*d)*time.Second)
+	}
+	if expected := schedule.Next(last.In(location)); !expected.IsZero() && now.After(expected.Add(grace)) {
+		status.Issues = append(status.Issues, fmt.Sprintf("the job scheduled at %s did not start: check the events of the cronjob, its concurrencyPolicy (%s) and startingDeadlineSeconds", expected.Format(time.RFC3339), cmp.Or(status.ConcurrencyPolicy, "Allow")))
+	}
+}
+
+func describeJobPod(pod corev1.Pod) jobPod {
+	p := jobPod{
+		Name:    pod.Name,
+		Phase:   string(pod.Status.Phase),
+		Reason:  pod.Status.Reason,
+		Node:    pod.Spec.NodeName,
+		Created: pod.CreationTimestamp.Time,
+	}
+	for _, s := range pod.Status.InitContainerStatuses {
+		p.Containers = append(p.Containers, describeContainerStatus(s))
+	}
+	for _, s := range pod.Status.ContainerStatuses {
+		p.Containers = append(p.Containers, describeContainerStatus(s))
+	}
+	return p
+}
+
+// podFailure returns when the last container of pod failed and its name, if one did:
+// pods of jobs restarting on failure do not fail, their containers do.
+func podFailure(pod corev1.Pod) (time.Time, string, bool) {
+	var at time.Time
+	var container string
+	for _, s := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		for _, t := range []*corev1.ContainerStateTerminated{s.State.Terminated, s.LastTerminationState.Terminated} {
+			if t != nil && t.ExitCode != 0 && !t.FinishedAt.Time.Before(at) {
+				at, container = t.FinishedAt.Time, s.Name
+			}
+		}
+	}
+	if container == "" && pod.Status.Phase == corev1.PodFailed && len(pod.Spec.Containers) > 0 {
+		// e.g. evicted, or killed for exceeding activeDeadlineSeconds
+		return pod.CreationTimestamp.Time, pod.Spec.Containers[0].Name, true
+	}
+	return at, container, container != ""
+}
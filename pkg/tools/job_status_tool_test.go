@@ -0,0 +1,93 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJobStatus(t *testing.T) {
+	executor := kubectlOutputs{
+		"kubectl get job migrate --namespace shop": `{
+			"metadata": {"name": "migrate", "creationTimestamp": "2025-01-01T00:00:00Z"},
+			"spec": {"backoffLimit": 1, "selector": {"matchLabels": {"batch.kubernetes.io/controller-uid": "123"}}},
+			"status": {"failed": 2, "conditions": [{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"}]}
+		}`,
+		"kubectl get pods --namespace shop --selector 'batch.kubernetes.io/controller-uid=123'": `{"items": [
+			{"metadata": {"name": "migrate-a", "creationTimestamp": "2025-01-01T00:00:00Z"}, "spec": {"containers": [{"name": "migrate"}]},
+				"status": {"phase": "Failed", "containerStatuses": [{"name": "migrate", "state": {"terminated": {"exitCode": 1, "reason": "Error", "finishedAt": "2025-01-01T00:01:00Z"}}}]}},
+			{"metadata": {"name": "migrate-b", "creationTimestamp": "2025-01-01T00:02:00Z"}, "spec": {"containers": [{"name": "migrate"}]},
+				"status": {"phase": "Failed", "containerStatuses": [{"name": "migrate", "state": {"terminated": {"exitCode": 2, "reason": "Error", "finishedAt": "2025-01-01T00:03:00Z"}}}]}}
+		]}`,
+		"kubectl logs migrate-b --namespace shop --container migrate --tail 50": "relation \"orders\" does not exist\n",
+	}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	result, err := NewJobStatusTool(executor).Run(ctx, map[string]any{"kind": "job", "name": "migrate", "namespace": "shop"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	status, ok := result.(*jobStatus)
+	if !ok {
+		t.Fatalf("Run() = %v, want a job status", result)
+	}
+	if !status.BackoffLimitExceeded || status.Failed != 2 || status.BackoffLimit != 1 {
+		t.Errorf("status = %+v, want 2 failures exceeding the backoff limit of 1", status)
+	}
+	if len(status.Pods) != 2 || status.Pods[0].Name != "migrate-b" {
+		t.Errorf("pods = %+v, want the most recent first", status.Pods)
+	}
+	if status.FailedPod == nil || status.FailedPod.Name != "migrate-b" || !strings.Contains(status.FailedPod.Logs, "does not exist") {
+		t.Errorf("failed pod = %+v, want migrate-b with its logs", status.FailedPod)
+	}
+}
+
+func TestCronJobMissedSchedule(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+	lastSchedule := metav1.NewTime(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name       string
+		schedule   string
+		wantIssues int
+		wantNext   time.Time
+	}{
+		{name: "on time", schedule: "0 */4 * * *", wantNext: time.Date(2025, 1, 1, 16, 0, 0, 0, time.UTC)},
+		{name: "missed", schedule: "*/10 * * * *", wantIssues: 1, wantNext: time.Date(2025, 1, 1, 12, 40, 0, 0, time.UTC)},
+		{name: "time zone", schedule: "CRON_TZ=Europe/Paris 0 14 * * *", wantNext: time.Date(2025, 1, 1, 13, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cronJob := batchv1.CronJob{
+				Spec:   batchv1.CronJobSpec{Schedule: tt.schedule},
+				Status: batchv1.CronJobStatus{LastScheduleTime: &lastSchedule},
+			}
+			status := &cronJobStatus{}
+			(&jobStatusCheck{}).checkSchedule(cronJob, status, now)
+			if len(status.Issues) != tt.wantIssues {
+				t.Errorf("issues = %v, want %d", status.Issues, tt.wantIssues)
+			}
+			if status.NextScheduleTime == nil || !status.NextScheduleTime.Equal(tt.wantNext) {
+				t.Errorf("next schedule = %v, want %v", status.NextScheduleTime, tt.wantNext)
+			}
+		})
+	}
+}
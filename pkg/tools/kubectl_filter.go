@@ -15,6 +15,9 @@
 package tools
 
 import (
+	"fmt"
+	"path"
+	"slices"
 	"strings"
 
 	"k8s.io/klog/v2"
@@ -50,6 +53,87 @@ var (
 		},
 	}
 
+	// clusterInfoOps do not read or modify resources of a namespace
+	clusterInfoOps = map[string]bool{
+		"api-resources": true, "api-versions": true, "version": true,
+		"explain": true, "help": true, "completion": true, "options": true,
+	}
+
+	// indirectCommands run the commands in their arguments, where the namespaces of kubectl
+	// calls cannot be checked.
+	indirectCommands = map[string]bool{
+		"env": true, "xargs": true, "sudo": true, "command": true, "exec": true,
+		"timeout": true, "nohup": true, "nice": true, "time": true, "watch": true,
+		"alias": true,
+	}
+
+	// shellCommands run scripts or strings as commands, which cannot be checked at all.
+	shellCommands = map[string]bool{
+		"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+		"eval": true, "source": true, ".": true,
+	}
+
+	// clusterScopedResources are the resource types, and their short names, that do not
+	// belong to a namespace.
+	clusterScopedResources = map[string]bool{
+		"namespace": true, "namespaces": true, "ns": true,
+		"node": true, "nodes": true, "no": true,
+		"clusterrole": true, "clusterroles": true,
+		"clusterrolebinding": true, "clusterrolebindings": true,
+		"customresourcedefinition": true, "customresourcedefinitions": true, "crd": true, "crds": true,
+		"persistentvolume": true, "persistentvolumes": true, "pv": true,
+		"storageclass": true, "storageclasses": true, "sc": true,
+		"priorityclass": true, "priorityclasses": true, "pc": true,
+		"ingressclass": true, "ingressclasses": true,
+		"runtimeclass": true, "runtimeclasses": true,
+		"csidriver": true, "csidrivers": true, "csinode": true, "csinodes": true,
+		"volumeattachment": true, "volumeattachments": true,
+		"mutatingwebhookconfiguration": true, "mutatingwebhookconfigurations": true,
+		"validatingwebhookconfiguration": true, "validatingwebhookconfigurations": true,
+		"apiservice": true, "apiservices": true,
+		"certificatesigningrequest": true, "certificatesigningrequests": true, "csr": true,
+	}
+
+	// clusterOps act on the cluster or the kubeconfig rather than on resources of a namespace.
+	clusterOps = map[string]bool{
+		"cordon": true, "uncordon": true, "drain": true, "taint": true,
+		"certificate": true, "config": true, "proxy": true,
+	}
+
+	// clusterFlags point kubectl at another cluster, user or kubeconfig, or at raw API paths.
+	clusterFlags = map[string]bool{
+		"--context": true, "--kubeconfig": true, "--cluster": true, "--user": true,
+		"--server": true, "-s": true, "--token": true, "--raw": true,
+	}
+
+	// valueFlags are common kubectl flags whose value is a separate argument, skipped when
+	// looking for the resource type of a call.
+	valueFlags = map[string]bool{
+		"-o": true, "--output": true, "-l": true, "--selector": true, "-c": true, "--container": true,
+		"-f": true, "--filename": true, "-k": true, "--kustomize": true, "-L": true, "--label-columns": true,
+		"--field-selector": true, "--sort-by": true, "-p": true, "--patch": true, "--type": true,
+		"--template": true, "--as": true, "--as-group": true, "--tail": true, "--since": true,
+	}
+
+	// fileFlags are kubectl flags reading or writing local files, which only standard input
+	// and URLs are allowed for on shared servers.
+	fileFlags = map[string]bool{
+		"-f": true, "--filename": true, "-k": true, "--kustomize": true, "--from-file": true,
+		"--from-env-file": true, "--custom": true, "--output-directory": true, "--log-file": true,
+		"--certificate-authority": true, "--client-certificate": true, "--client-key": true,
+		"--cache-dir": true,
+	}
+
+	// localOps read or write local files, or serve on the local host.
+	localOps = map[string]bool{
+		"cp": true, "kustomize": true, "proxy": true, "plugin": true,
+	}
+
+	// readOnlyConfigOps are the kubectl config commands that do not change the kubeconfig.
+	readOnlyConfigOps = map[string]bool{
+		"view": true, "current-context": true, "get-contexts": true, "get-clusters": true, "get-users": true,
+	}
+
 	writeSubOps = map[string]map[string]bool{
 		"rollout": {
 			"pause":   true,
@@ -126,18 +210,7 @@ func analyzeCall(call *syntax.CallExpr) string {
 	}
 
 	// Extract command and arguments
-	var args []string
-	for _, arg := range call.Args {
-		lit := arg.Lit()
-		if lit == "" {
-			var sb strings.Builder
-			syntax.NewPrinter().Print(&sb, arg)
-			lit = strings.Trim(sb.String(), "'\"")
-		}
-		if lit != "" {
-			args = append(args, lit)
-		}
-	}
+	args := callArgs(call)
 
 	if len(args) == 0 {
 		klog.Warning("analyzeCall: no arguments extracted from call")
@@ -196,6 +269,23 @@ func analyzeCall(call *syntax.CallExpr) string {
 	return "unknown"
 }
 
+// callArgs returns the words of call, printing the ones that are not literals.
+func callArgs(call *syntax.CallExpr) []string {
+	var args []string
+	for _, arg := range call.Args {
+		lit := arg.Lit()
+		if lit == "" {
+			var sb strings.Builder
+			syntax.NewPrinter().Print(&sb, arg)
+			lit = strings.Trim(sb.String(), "'\"")
+		}
+		if lit != "" {
+			args = append(args, lit)
+		}
+	}
+	return args
+}
+
 // parseKubectlArgs extracts verb, subverb, and dry-run flag from kubectl arguments
 func parseKubectlArgs(args []string) (verb, subVerb string, hasDryRun bool) {
 	for _, arg := range args {
@@ -212,3 +302,256 @@ func parseKubectlArgs(args []string) (verb, subVerb string, hasDryRun bool) {
 	}
 	return verb, subVerb, hasDryRun
 }
+
+// CheckKubectlNamespaces returns an error if a kubectl call of command does not name one of
+// the allowed namespaces with -n or --namespace, spans all namespaces, or reaches beyond
+// them: cluster-scoped resources such as namespaces or nodes, and other contexts or
+// kubeconfigs. Calls that only read cluster information, like kubectl version, are allowed.
+// Commands that may run kubectl in ways that cannot be followed are refused (see
+// checkKubectlCalls).
+func CheckKubectlNamespaces(command string, allowed []string) error {
+	restriction := "only these namespaces are allowed: " + strings.Join(allowed, ", ")
+	return checkKubectlCalls(command, restriction, func(args []string) error {
+		return checkKubectlCallNamespaces(args, allowed)
+	})
+}
+
+// CheckKubectlContext returns an error if a kubectl call of command may run against another
+// context than the current one of the session: flags naming another context, kubeconfig,
+// cluster or user, and kubectl config commands changing the kubeconfig. The use_context tool
+// switches contexts instead, among the allowed ones. Commands that may run kubectl in ways
+// that cannot be followed are refused (see checkKubectlCalls).
+func CheckKubectlContext(command string) error {
+	return checkKubectlCalls(command, "kubeconfig contexts are restricted", checkKubectlCallContext)
+}
+
+// checkKubectlCalls calls check with the arguments of every kubectl call of command. It
+// returns an error for the commands that may run kubectl in ways that cannot be followed,
+// explaining it with restriction: wrappers such as env or xargs running kubectl, shells and
+// eval, command words that are not literal, e.g. variables, and settings of KUBECONFIG.
+func checkKubectlCalls(command string, restriction string, check func(args []string) error) error {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return fmt.Errorf("cannot check the command: %w", err)
+	}
+
+	var violation error
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if violation != nil {
+			return false
+		}
+		if assign, ok := node.(*syntax.Assign); ok && assign.Name != nil && assign.Name.Value == "KUBECONFIG" {
+			violation = fmt.Errorf("KUBECONFIG cannot be set when %s", restriction)
+		}
+		call, ok := node.(*syntax.CallExpr)
+		if !ok || violation != nil {
+			return violation == nil
+		}
+		args := callArgs(call)
+		if len(args) == 0 {
+			return true
+		}
+		name := path.Base(args[0])
+		switch {
+		case !isLiteralWord(call.Args[0]):
+			violation = fmt.Errorf("command %q is not a literal word, so it cannot be checked when %s", args[0], restriction)
+		case name == "kubectl":
+			violation = check(args[1:])
+		case strings.Contains(name, "kubectl"):
+			violation = fmt.Errorf("kubectl must be run as kubectl, not %q, when %s", args[0], restriction)
+		case shellCommands[name]:
+			violation = fmt.Errorf("%s cannot be used when %s", args[0], restriction)
+		case indirectCommands[name] && slices.ContainsFunc(call.Args[1:], func(word *syntax.Word) bool {
+			return !isLiteralWord(word) || strings.Contains(word.Lit(), "kubectl")
+		}):
+			violation = fmt.Errorf("kubectl commands must be run directly, %s cannot be used to run them when %s", args[0], restriction)
+		}
+		return violation == nil
+	})
+	return violation
+}
+
+// isLiteralWord reports whether word is a plain word, without quotes, expansions or escapes
+// that the shell would turn into something else.
+func isLiteralWord(word *syntax.Word) bool {
+	lit := word.Lit()
+	return lit != "" && !strings.Contains(lit, "\\")
+}
+
+func checkKubectlCallNamespaces(args []string, allowed []string) error {
+	var namespaces []string
+	verb, resource := "", ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag, _, _ := strings.Cut(arg, "=")
+		switch {
+		case arg == "-A" || arg == "--all-namespaces" || arg == "--all-namespaces=true":
+			return fmt.Errorf("kubectl commands may not span all namespaces, only these namespaces are allowed: %s", strings.Join(allowed, ", "))
+		case clusterFlags[flag]:
+			return fmt.Errorf("kubectl commands may not use %s when only these namespaces are allowed: %s", flag, strings.Join(allowed, ", "))
+		case arg == "-n" || arg == "--namespace":
+			if i+1 < len(args) {
+				namespaces = append(namespaces, args[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "--namespace="):
+			namespaces = append(namespaces, strings.TrimPrefix(arg, "--namespace="))
+		case strings.HasPrefix(arg, "-n") && !strings.HasPrefix(arg, "--"):
+			namespaces = append(namespaces, strings.TrimPrefix(strings.TrimPrefix(arg, "-n"), "="))
+		case valueFlags[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		case verb == "":
+			verb = arg
+		case resource == "":
+			resource = arg
+		}
+	}
+
+	if clusterOps[verb] {
+		return fmt.Errorf("kubectl %s is not allowed when only these namespaces are allowed: %s", verb, strings.Join(allowed, ", "))
+	}
+	// The resource type may be qualified with a name or API group, or be a list of types
+	for _, kind := range strings.Split(resource, ",") {
+		kind, _, _ = strings.Cut(kind, "/")
+		kind, _, _ = strings.Cut(kind, ".")
+		if clusterScopedResources[strings.ToLower(kind)] {
+			return fmt.Errorf("kubectl commands may not access cluster-scoped %s when only these namespaces are allowed: %s", kind, strings.Join(allowed, ", "))
+		}
+	}
+
+	if len(namespaces) == 0 {
+		if clusterInfoOps[verb] {
+			return nil
+		}
+		return fmt.Errorf("kubectl commands must set --namespace to one of the allowed namespaces: %s", strings.Join(allowed, ", "))
+	}
+	for _, namespace := range namespaces {
+		if err := CheckNamespace(namespace, allowed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkKubectlCallContext(args []string) error {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag, _, _ := strings.Cut(arg, "=")
+		switch {
+		case clusterFlags[flag] && flag != "--raw":
+			return fmt.Errorf("kubectl commands may not use %s when kubeconfig contexts are restricted, switch contexts with the use_context tool", flag)
+		case valueFlags[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) > 0 && positional[0] == "config" && (len(positional) < 2 || !readOnlyConfigOps[positional[1]]) {
+		return fmt.Errorf("the kubeconfig cannot be changed when kubeconfig contexts are restricted, switch contexts with the use_context tool")
+	}
+	return nil
+}
+
+// CheckNamespace returns an error if namespace is not one of the allowed namespaces.
+func CheckNamespace(namespace string, allowed []string) error {
+	if !slices.Contains(allowed, namespace) {
+		return fmt.Errorf("namespace %q is not allowed, only these namespaces are: %s", namespace, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// CheckKubectlSharedCommand returns an error unless command only runs kubectl on the
+// resources of the cluster with the kubeconfig it is given. It is for the agents of servers
+// shared by several users, which all run as the same OS user: other commands, redirections
+// other than here-documents, environment assignments, flags picking another kubeconfig,
+// context or credentials, flags reading or writing local files other than standard input,
+// kubectl cp, proxy and plugins, and changes of the kubeconfig are refused.
+func CheckKubectlSharedCommand(command string) error {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return fmt.Errorf("cannot check the command: %w", err)
+	}
+
+	var violation error
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if violation != nil {
+			return false
+		}
+		switch node := node.(type) {
+		case *syntax.Redirect:
+			if node.Op != syntax.Hdoc && node.Op != syntax.DashHdoc && node.Op != syntax.WordHdoc {
+				violation = fmt.Errorf("redirections to or from files are not allowed on a shared server")
+			}
+		case *syntax.DeclClause:
+			violation = fmt.Errorf("%s is not allowed on a shared server, only kubectl commands are", node.Variant.Value)
+		case *syntax.CallExpr:
+			switch {
+			case len(node.Assigns) > 0:
+				violation = fmt.Errorf("environment variables cannot be set on a shared server")
+			case len(node.Args) == 0:
+			case !isLiteralWord(node.Args[0]) || node.Args[0].Lit() != "kubectl":
+				violation = fmt.Errorf("only kubectl commands can be run on a shared server, not %q", callArgs(node)[0])
+			default:
+				violation = checkKubectlSharedCall(callArgs(node)[1:])
+			}
+		}
+		return violation == nil
+	})
+	return violation
+}
+
+func checkKubectlSharedCall(args []string) error {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			// The rest is the command of kubectl exec or debug, run in the cluster
+			break
+		}
+		flag, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue && len(flag) > 2 && flag[0] == '-' && flag[1] != '-' && (fileFlags[flag[:2]] || flag[:2] == "-o") {
+			// Short flags may be followed by their value, e.g. -fmanifest.yaml
+			flag, value, hasValue = flag[:2], flag[2:], true
+		}
+		switch {
+		case clusterFlags[flag]:
+			return fmt.Errorf("kubectl commands may not use %s on a shared server", flag)
+		case fileFlags[flag] || flag == "-o" || flag == "--output":
+			if !hasValue && i+1 < len(args) {
+				value = args[i+1]
+				i++
+			}
+			if fileFlags[flag] && value != "-" && !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+				return fmt.Errorf("kubectl commands may not use local files with %s on a shared server, only standard input", flag)
+			}
+			if strings.Contains(value, "-file") {
+				return fmt.Errorf("kubectl commands may not use local template files on a shared server")
+			}
+		case valueFlags[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 {
+		return nil
+	}
+
+	verb := positional[0]
+	switch {
+	case localOps[verb]:
+		return fmt.Errorf("kubectl %s is not allowed on a shared server", verb)
+	case verb == "config":
+		if len(positional) < 2 || !readOnlyConfigOps[positional[1]] {
+			return fmt.Errorf("the kubeconfig cannot be changed on a shared server")
+		}
+	case !readOnlyOps[verb] && !writeOps[verb] && readOnlySubOps[verb] == nil && writeSubOps[verb] == nil && !clusterInfoOps[verb]:
+		// Other commands may be kubectl plugins, run on the server
+		return fmt.Errorf("kubectl %s is not allowed on a shared server", verb)
+	}
+	return nil
+}
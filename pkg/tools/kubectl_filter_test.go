@@ -572,3 +572,152 @@ func TestKubectlAlwaysAtPosition0(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckKubectlNamespaces(t *testing.T) {
+	allowed := []string{"dev", "staging"}
+	testCases := []struct {
+		command string
+		allowed bool
+	}{
+		{"kubectl get pods -n dev", true},
+		{"kubectl get pods --namespace=staging -o yaml", true},
+		{"kubectl -ndev get pods", true},
+		{"kubectl get pods -n 'dev' | grep nginx", true},
+		{"kubectl version", true},
+		{"ls -l", true},
+		{"kubectl get pods", false},
+		{"kubectl get pods -n prod", false},
+		{"kubectl get pods -A", false},
+		{"kubectl get pods --all-namespaces -n dev", false},
+		{"kubectl get pods -n $NS", false},
+		{"kubectl get pods -n dev && kubectl delete pod x -n prod", false},
+		{"echo $(kubectl get secrets -n prod)", false},
+		{"env kubectl get secrets -A", false},
+		{"/usr/bin/env KUBECONFIG=x kubectl get pods -n dev", false},
+		{"bash -c 'kubectl get secrets -n prod'", false},
+		{"sh -c \"kubectl get pods -n dev\"", false},
+		{"echo pod | xargs kubectl delete pod -n prod", false},
+		{"sudo kubectl get pods -n prod", false},
+		{"timeout 10 kubectl get pods -n prod", false},
+		{"eval kubectl get pods -n prod", false},
+		{"env | grep KUBE", true},
+		// Shells and eval run strings that cannot be checked
+		{"bash -c 'ls -l'", false},
+		{"bash -c 'kube\"\"ctl get secrets -A'", false},
+		{"source ./script.sh", false},
+		// Variables, aliases, escapes and plugins hiding kubectl
+		{"k=kubectl; $k get pods -A", false},
+		{"$(which kubectl) get pods -A", false},
+		{"kube\"\"ctl get pods -A", false},
+		{"k\\ubectl get pods -A", false},
+		{"alias k=kubectl; k get pods -A", false},
+		{"env kube\"\"ctl get pods -A", false},
+		{"kubectl-ns prod", false},
+		{"/usr/local/bin/kubectl get pods -n dev", true},
+		// Cluster-scoped resources
+		{"kubectl delete namespace prod -n dev", false},
+		{"kubectl get ns -n dev", false},
+		{"kubectl create namespace test -n dev", false},
+		{"kubectl get clusterroles -n dev", false},
+		{"kubectl get -o yaml nodes -n dev", false},
+		{"kubectl get pods,nodes -n dev", false},
+		{"kubectl get crds -n dev", false},
+		{"kubectl get customresourcedefinitions.apiextensions.k8s.io -n dev", false},
+		{"kubectl describe node/worker-1 -n dev", false},
+		{"kubectl drain worker-1 -n dev", false},
+		{"kubectl config use-context prod -n dev", false},
+		{"kubectl get pod node-exporter-1 -n dev", true},
+		// Other clusters and kubeconfigs
+		{"kubectl --context prod get pods -n dev", false},
+		{"kubectl get pods -n dev --context=prod", false},
+		{"kubectl get pods -n dev --kubeconfig /tmp/other", false},
+		{"kubectl get pods -n dev --server https://prod:6443", false},
+		{"kubectl get --raw /api/v1/namespaces/prod/secrets -n dev", false},
+		{"KUBECONFIG=/tmp/other kubectl get pods -n dev", false},
+		{"export KUBECONFIG=/tmp/other; kubectl get pods -n dev", false},
+	}
+	for _, tc := range testCases {
+		err := CheckKubectlNamespaces(tc.command, allowed)
+		if (err == nil) != tc.allowed {
+			t.Errorf("CheckKubectlNamespaces(%q) = %v, want allowed %v", tc.command, err, tc.allowed)
+		}
+	}
+}
+
+func TestCheckKubectlContext(t *testing.T) {
+	testCases := []struct {
+		command string
+		allowed bool
+	}{
+		{"kubectl get pods -n dev", true},
+		{"kubectl delete pod web-0 -n prod", true},
+		{"kubectl config current-context", true},
+		{"kubectl get --raw /healthz", true},
+		{"ls -l", true},
+		{"kubectl --context prod delete pod web-0", false},
+		{"kubectl delete pod web-0 --context=prod", false},
+		{"kubectl --kubeconfig /tmp/other get pods", false},
+		{"kubectl get pods --cluster prod --user admin", false},
+		{"kubectl get pods -s https://prod:6443", false},
+		{"kubectl config use-context prod", false},
+		{"kubectl config set-context --current --namespace prod", false},
+		{"kubectl config", false},
+		{"KUBECONFIG=/tmp/other kubectl delete pod web-0", false},
+		{"export KUBECONFIG=/tmp/other && kubectl delete pod web-0", false},
+		{"k=kubectl; $k --context prod delete pod web-0", false},
+		{"bash -c 'kubectl --context prod delete pod web-0'", false},
+		{"env kubectl --context prod delete pod web-0", false},
+	}
+	for _, tc := range testCases {
+		err := CheckKubectlContext(tc.command)
+		if (err == nil) != tc.allowed {
+			t.Errorf("CheckKubectlContext(%q) = %v, want allowed %v", tc.command, err, tc.allowed)
+		}
+	}
+}
+
+func TestCheckKubectlSharedCommand(t *testing.T) {
+	testCases := []struct {
+		command string
+		allowed bool
+	}{
+		{"kubectl get pods -n dev", true},
+		{"kubectl get pods -A -o yaml", true},
+		{"kubectl logs deploy/web --tail 20", true},
+		{"kubectl exec web-0 -- tail -f /var/log/app.log", true},
+		{"kubectl apply -f - <<EOF\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: x\nEOF", true},
+		{"kubectl apply -f https://example.com/manifest.yaml", true},
+		{"kubectl config current-context", true},
+		{"kubectl rollout status deploy/web", true},
+		{"kubectl get pods -n dev | grep web", false},
+		{"cat /srv/kubeconfigs/bob/config", false},
+		{"ls /srv/kubeconfigs", false},
+		{"kubectl get pods $(cat /srv/kubeconfigs/bob/config)", false},
+		{"kubectl --kubeconfig /srv/kubeconfigs/bob/config get pods", false},
+		{"kubectl get pods --kubeconfig=/srv/kubeconfigs/bob/config", false},
+		{"kubectl --context bob get pods", false},
+		{"kubectl get pods --token abc", false},
+		{"KUBECONFIG=/srv/kubeconfigs/bob/config kubectl get pods", false},
+		{"export KUBECONFIG=/srv/kubeconfigs/bob/config", false},
+		{"kubectl apply -f /srv/kubeconfigs/bob/config", false},
+		{"kubectl apply -f/srv/kubeconfigs/bob/config", false},
+		{"kubectl create secret generic x --from-file=/srv/kubeconfigs/bob/config", false},
+		{"kubectl get pods -o go-template-file=/srv/kubeconfigs/bob/config", false},
+		{"kubectl get pods > /tmp/pods", false},
+		{"kubectl apply -f - < /srv/kubeconfigs/bob/config", false},
+		{"kubectl cp web-0:/etc/passwd /tmp/passwd", false},
+		{"kubectl kustomize /srv/kubeconfigs/bob", false},
+		{"kubectl proxy", false},
+		{"kubectl config use-context bob", false},
+		{"kubectl config view --kubeconfig /srv/kubeconfigs/bob/config", false},
+		{"kubectl someplugin", false},
+		{"k=kubectl; $k get pods", false},
+		{"/usr/bin/kubectl get pods", false},
+	}
+	for _, tc := range testCases {
+		err := CheckKubectlSharedCommand(tc.command)
+		if (err == nil) != tc.allowed {
+			t.Errorf("CheckKubectlSharedCommand(%q) = %v, want allowed %v", tc.command, err, tc.allowed)
+		}
+	}
+}
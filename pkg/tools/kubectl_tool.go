@@ -16,6 +16,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -117,7 +118,16 @@ func (t *Kubectl) Run(ctx context.Context, args map[string]any) (any, error) {
 		return &sandbox.ExecResult{Command: command, Error: err.Error()}, nil
 	}
 
-	// Prepare environment
+	env, err := kubectlEnv(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return ExecuteWithStreamingHandling(ctx, t.executor, command, workDir, env, DetectKubectlStreaming)
+}
+
+// kubectlEnv returns the environment of kubectl commands, using kubeconfig if set.
+func kubectlEnv(kubeconfig string) ([]string, error) {
 	env := os.Environ()
 	if kubeconfig != "" {
 		kubeconfig, err := ExpandShellVar(kubeconfig)
@@ -126,8 +136,29 @@ func (t *Kubectl) Run(ctx context.Context, args map[string]any) (any, error) {
 		}
 		env = append(env, "KUBECONFIG="+kubeconfig)
 	}
+	return env, nil
+}
 
-	return ExecuteWithStreamingHandling(ctx, t.executor, command, workDir, env, DetectKubectlStreaming)
+// kubectlGetJSON runs a kubectl command printing JSON with executor, and decodes its output
+// into v. If the command fails, its result is returned to report to the model.
+func kubectlGetJSON(ctx context.Context, executor sandbox.Executor, command string, v any) (*sandbox.ExecResult, error) {
+	kubeconfig := ctx.Value(KubeconfigKey).(string)
+	workDir := ctx.Value(WorkDirKey).(string)
+	env, err := kubectlEnv(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	result, err := executor.Execute(ctx, command, env, workDir)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" || result.ExitCode != 0 {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(result.Stdout), v); err != nil {
+		return &sandbox.ExecResult{Command: command, Error: fmt.Sprintf("parsing output: %v", err)}, nil
+	}
+	return nil, nil
 }
 
 // DetectKubectlStreaming checks if a kubectl command is a streaming command
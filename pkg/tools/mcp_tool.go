@@ -50,17 +50,61 @@ type MCPTool struct {
 	description string
 	schema      *gollm.FunctionDefinition
 	manager     *mcp.Manager
+
+	// namePrefix replaces the server name in the unique tool name, if set
+	namePrefix string
+	// maxResultSize truncates longer results, if positive
+	maxResultSize int
+	// trust is the trust level of the server
+	trust mcp.TrustLevel
+	// readOnly is whether the server annotates the tool as read-only
+	readOnly bool
+}
+
+// MCPToolOption configures an MCPTool.
+type MCPToolOption func(*MCPTool)
+
+// WithNamePrefix sets the prefix of the unique tool name, which defaults to the server name.
+func WithNamePrefix(prefix string) MCPToolOption {
+	return func(t *MCPTool) {
+		t.namePrefix = prefix
+	}
+}
+
+// WithMaxResultSize truncates tool results longer than size bytes.
+func WithMaxResultSize(size int) MCPToolOption {
+	return func(t *MCPTool) {
+		t.maxResultSize = size
+	}
+}
+
+// WithTrustLevel sets the trust level of the server, which decides whether calls need approval.
+func WithTrustLevel(trust mcp.TrustLevel) MCPToolOption {
+	return func(t *MCPTool) {
+		t.trust = trust
+	}
+}
+
+// WithReadOnly marks the tool as read-only, as annotated by the server.
+func WithReadOnly(readOnly bool) MCPToolOption {
+	return func(t *MCPTool) {
+		t.readOnly = readOnly
+	}
 }
 
 // NewMCPTool creates a new MCP tool wrapper.
-func NewMCPTool(serverName, toolName, description string, schema *gollm.FunctionDefinition, manager *mcp.Manager) *MCPTool {
-	return &MCPTool{
+func NewMCPTool(serverName, toolName, description string, schema *gollm.FunctionDefinition, manager *mcp.Manager, opts ...MCPToolOption) *MCPTool {
+	t := &MCPTool{
 		serverName:  serverName,
 		toolName:    toolName,
 		description: description,
 		schema:      schema,
 		manager:     manager,
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 // Name returns the tool name.
@@ -69,6 +113,9 @@ func (t *MCPTool) Name() string {
 }
 
 func (t *MCPTool) UniqueToolName() string {
+	if t.namePrefix != "" {
+		return t.namePrefix + t.toolName
+	}
 	return fmt.Sprintf("%s_%s", t.serverName, t.toolName)
 }
 
@@ -95,12 +142,28 @@ func (t *MCPTool) IsInteractive(args map[string]any) (bool, error) {
 
 // CheckModifiesResource determines if the command modifies kubernetes resources
 // For MCP tools, we'll conservatively assume they might modify resources
-// since we can't easily determine this for arbitrary external tools
+// since we can't easily determine this for arbitrary external tools,
+// unless the server is trusted, or read-only and the tool annotated as read-only.
 // Returns "yes", "no", or "unknown"
 func (t *MCPTool) CheckModifiesResource(args map[string]any) string {
-	// Since MCP tools can be arbitrary external tools and we don't have a way to know
-	// if they modify resources, we'll conservatively return "unknown"
-	return "unknown"
+	switch {
+	case t.trust == mcp.TrustTrusted:
+		return "no"
+	case t.trust == mcp.TrustReadOnly && t.readOnly:
+		return "no"
+	default:
+		return "unknown"
+	}
+}
+
+// TrustLevel returns the trust level of the server providing the tool.
+func (t *MCPTool) TrustLevel() mcp.TrustLevel {
+	return t.trust
+}
+
+// ReadOnly returns whether the server annotates the tool as read-only.
+func (t *MCPTool) ReadOnly() bool {
+	return t.readOnly
 }
 
 // Run executes the MCP tool by calling the appropriate MCP server.
@@ -124,5 +187,8 @@ func (t *MCPTool) Run(ctx context.Context, args map[string]any) (any, error) {
 		return nil, fmt.Errorf("calling MCP tool %q on server %q: %w", t.toolName, t.serverName, err)
 	}
 
+	if t.maxResultSize > 0 && len(result) > t.maxResultSize {
+		result = fmt.Sprintf("%s\n[truncated %d of %d bytes]", result[:t.maxResultSize], len(result)-t.maxResultSize, len(result))
+	}
 	return result, nil
 }
@@ -0,0 +1,344 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// maxNodes limits the nodes described by node_health, the unhealthy ones first.
+	maxNodes = 50
+	// maxNodeEvents limits the events of each node, the most recent first.
+	maxNodeEvents = 10
+)
+
+// nodeResources are the resources compared between allocatable and requested.
+var nodeResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage}
+
+// NodeHealth gathers the health and capacity of nodes in one call.
+type NodeHealth struct {
+	executor sandbox.Executor
+}
+
+func NewNodeHealthTool(executor sandbox.Executor) *NodeHealth {
+	return &NodeHealth{executor: executor}
+}
+
+func (t *NodeHealth) Name() string {
+	return "node_health"
+}
+
+func (t *NodeHealth) Description() string {
+	return `Reports the health and capacity of a node, or of all nodes, in a single structured result: the node conditions with the kubelet status and pressure signals, taints and schedulability, the allocatable resources against the requests and limits of the pods running on the node, and the recent events of the node.
+Prefer it over separate kubectl calls to answer capacity and scheduling questions, e.g. why pods are pending or evicted.`
+}
+
+func (t *NodeHealth) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"node": {
+					Type:        gollm.TypeString,
+					Description: `Name of the node. All nodes if empty.`,
+				},
+			},
+		},
+	}
+}
+
+func (t *NodeHealth) Run(ctx context.Context, args map[string]any) (any, error) {
+	name, _ := args["node"].(string)
+	var nodes []corev1.Node
+	podSelector := "status.phase!=Succeeded,status.phase!=Failed"
+	eventSelector := "involvedObject.kind=Node"
+	if name != "" {
+		// Names are passed to the shell of the executor
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return &sandbox.ExecResult{Error: fmt.Sprintf("invalid node name %q: %s", name, strings.Join(errs, ", "))}, nil
+		}
+		var node corev1.Node
+		if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get node "+name+" -o json", &node); failed != nil || err != nil {
+			return failed, err
+		}
+		nodes = []corev1.Node{node}
+		podSelector += ",spec.nodeName=" + name
+		eventSelector += ",involvedObject.name=" + name
+	} else {
+		var list corev1.NodeList
+		if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get nodes -o json", &list); failed != nil || err != nil {
+			return failed, err
+		}
+		nodes = list.Items
+	}
+
+	var pods corev1.PodList
+	if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get pods --all-namespaces --field-selector "+podSelector+" -o json", &pods); failed != nil || err != nil {
+		return failed, err
+	}
+	var events corev1.EventList
+	if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get events --all-namespaces --field-selector "+eventSelector+" -o json", &events); failed != nil || err != nil {
+		return failed, err
+	}
+
+	podsByNode := map[string][]corev1.Pod{}
+	for _, pod := range pods.Items {
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+	eventsByNode := map[string][]corev1.Event{}
+	for _, event := range events.Items {
+		eventsByNode[event.InvolvedObject.Name] = append(eventsByNode[event.InvolvedObject.Name], event)
+	}
+
+	result := &nodeHealthResult{Total: len(nodes)}
+	for _, node := range nodes {
+		health := describeNode(node, podsByNode[node.Name], eventsByNode[node.Name])
+		if health.Ready != string(corev1.ConditionTrue) {
+			result.NotReady++
+		}
+		result.Nodes = append(result.Nodes, health)
+	}
+	slices.SortFunc(result.Nodes, func(a, b nodeHealth) int {
+		return cmp.Or(cmp.Compare(b.problems(), a.problems()), cmp.Compare(a.Name, b.Name))
+	})
+	if len(result.Nodes) > maxNodes {
+		result.Nodes = result.Nodes[:maxNodes]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+func (t *NodeHealth) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *NodeHealth) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+type nodeHealthResult struct {
+	Total    int `json:"total"`
+	NotReady int `json:"notReady"`
+	// Nodes are sorted by the number of problems, the unhealthy ones first
+	Nodes     []nodeHealth `json:"nodes"`
+	Truncated bool         `json:"truncated,omitempty"`
+}
+
+type nodeHealth struct {
+	Name           string   `json:"name"`
+	Roles          []string `json:"roles,omitempty"`
+	KubeletVersion string   `json:"kubeletVersion"`
+	// Ready is the status of the Ready condition, Unknown if the kubelet stopped posting it
+	Ready         string `json:"ready"`
+	Unschedulable bool   `json:"unschedulable,omitempty"`
+	// Pressure are the conditions signaling a problem, e.g. MemoryPressure
+	Pressure   []string                `json:"pressure,omitempty"`
+	Conditions []nodeCondition         `json:"conditions"`
+	Taints     []corev1.Taint          `json:"taints,omitempty"`
+	Resources  map[string]nodeResource `json:"resources"`
+	Events     []nodeEvent             `json:"events,omitempty"`
+}
+
+// problems counts the signals of an unhealthy node.
+func (n nodeHealth) problems() int {
+	problems := len(n.Pressure)
+	if n.Ready != string(corev1.ConditionTrue) {
+		problems += 10
+	}
+	if n.Unschedulable {
+		problems++
+	}
+	return problems
+}
+
+type nodeCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastHeartbeatTime  time.Time `json:"lastHeartbeatTime"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// nodeResource compares the allocatable resources of a node to the requests and limits of
+// its pods, in percent of allocatable.
+type nodeResource struct {
+	Allocatable     string `json:"allocatable"`
+	Requests        string `json:"requests"`
+	Limits          string `json:"limits,omitempty"`
+	RequestsPercent int64  `json:"requestsPercent"`
+	LimitsPercent   int64  `json:"limitsPercent,omitempty"`
+}
+
+type nodeEvent struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count,omitempty"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+func describeNode(node corev1.Node, pods []corev1.Pod, events []corev1.Event) nodeHealth {
+	health := nodeHealth{
+		Name:           node.Name,
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+		Ready:          string(corev1.ConditionUnknown),
+		Unschedulable:  node.Spec.Unschedulable,
+		Taints:         node.Spec.Taints,
+		Resources:      map[string]nodeResource{},
+	}
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/"); ok {
+			health.Roles = append(health.Roles, role)
+		}
+	}
+	slices.Sort(health.Roles)
+
+	for _, c := range node.Status.Conditions {
+		health.Conditions = append(health.Conditions, nodeCondition{
+			Type:               string(c.Type),
+			Status:             string(c.Status),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastHeartbeatTime:  c.LastHeartbeatTime.Time,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+		if c.Type == corev1.NodeReady {
+			health.Ready = string(c.Status)
+		} else if c.Status == corev1.ConditionTrue {
+			health.Pressure = append(health.Pressure, string(c.Type))
+		}
+	}
+
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, pod := range pods {
+		podRequests, podLimits := podResources(pod)
+		addResources(requests, podRequests)
+		addResources(limits, podLimits)
+	}
+	for _, name := range nodeResources {
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok {
+			continue
+		}
+		r := nodeResource{Allocatable: allocatable.String()}
+		request, limit := requests[name], limits[name]
+		r.Requests = request.String()
+		if !limit.IsZero() {
+			r.Limits = limit.String()
+		}
+		if allocatable.MilliValue() > 0 {
+			r.RequestsPercent = request.MilliValue() * 100 / allocatable.MilliValue()
+			r.LimitsPercent = limit.MilliValue() * 100 / allocatable.MilliValue()
+		}
+		health.Resources[string(name)] = r
+	}
+	if allocatable, ok := node.Status.Allocatable[corev1.ResourcePods]; ok {
+		r := nodeResource{Allocatable: allocatable.String(), Requests: fmt.Sprint(len(pods))}
+		if allocatable.Value() > 0 {
+			r.RequestsPercent = int64(len(pods)) * 100 / allocatable.Value()
+		}
+		health.Resources[string(corev1.ResourcePods)] = r
+	}
+
+	slices.SortFunc(events, func(a, b corev1.Event) int {
+		return eventTime(b).Compare(eventTime(a))
+	})
+	for _, e := range events[:min(len(events), maxNodeEvents)] {
+		health.Events = append(health.Events, nodeEvent{
+			Type:     e.Type,
+			Reason:   e.Reason,
+			Message:  e.Message,
+			Count:    e.Count,
+			LastSeen: eventTime(e),
+		})
+	}
+	return health
+}
+
+// podResources returns the requests and limits of a pod, as the scheduler accounts them:
+// init containers run before the containers, except sidecars which run along.
+func podResources(pod corev1.Pod) (requests, limits corev1.ResourceList) {
+	requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResources(requests, c.Resources.Requests)
+		addResources(limits, c.Resources.Limits)
+	}
+	sidecarRequests, sidecarLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	initRequests, initLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResources(sidecarRequests, c.Resources.Requests)
+			addResources(sidecarLimits, c.Resources.Limits)
+			continue
+		}
+		// Init containers run one at a time, along the sidecars started before them
+		maxResources(initRequests, withResources(sidecarRequests, c.Resources.Requests))
+		maxResources(initLimits, withResources(sidecarLimits, c.Resources.Limits))
+	}
+	addResources(requests, sidecarRequests)
+	addResources(limits, sidecarLimits)
+	maxResources(requests, initRequests)
+	maxResources(limits, initLimits)
+	addResources(requests, pod.Spec.Overhead)
+	addResources(limits, pod.Spec.Overhead)
+	return requests, limits
+}
+
+func addResources(total, add corev1.ResourceList) {
+	for name, q := range add {
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+}
+
+func maxResources(total, other corev1.ResourceList) {
+	for name, q := range other {
+		if current, ok := total[name]; !ok || q.Cmp(current) > 0 {
+			total[name] = q.DeepCopy()
+		}
+	}
+}
+
+func withResources(base, add corev1.ResourceList) corev1.ResourceList {
+	sum := corev1.ResourceList{}
+	addResources(sum, base)
+	addResources(sum, add)
+	return sum
+}
+
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
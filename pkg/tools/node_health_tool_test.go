@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestNodeHealth(t *testing.T) {
+	executor := kubectlOutputs{
+		"kubectl get nodes -o json": `{"items": [
+			{"metadata": {"name": "node-a", "labels": {"node-role.kubernetes.io/worker": ""}},
+				"status": {"allocatable": {"cpu": "2", "memory": "4Gi", "pods": "110"},
+					"conditions": [{"type": "Ready", "status": "True"}]}},
+			{"metadata": {"name": "node-b"},
+				"spec": {"taints": [{"key": "node.kubernetes.io/memory-pressure", "effect": "NoSchedule"}]},
+				"status": {"allocatable": {"cpu": "2", "memory": "4Gi", "pods": "110"},
+					"conditions": [{"type": "Ready", "status": "True"}, {"type": "MemoryPressure", "status": "True", "reason": "KubeletHasInsufficientMemory"}]}}
+		]}`,
+		"kubectl get pods --all-namespaces": `{"items": [
+			{"spec": {"nodeName": "node-a",
+				"initContainers": [{"name": "init", "resources": {"requests": {"cpu": "1500m"}}}],
+				"containers": [{"name": "app", "resources": {"requests": {"cpu": "500m", "memory": "1Gi"}, "limits": {"memory": "2Gi"}}}]}},
+			{"spec": {"nodeName": "node-b",
+				"containers": [{"name": "app", "resources": {"requests": {"cpu": "1", "memory": "3Gi"}}}]}}
+		]}`,
+		"kubectl get events --all-namespaces": `{"items": [
+			{"involvedObject": {"kind": "Node", "name": "node-b"}, "type": "Warning", "reason": "EvictionThresholdMet", "lastTimestamp": "2025-01-01T00:00:00Z"}
+		]}`,
+	}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	result, err := NewNodeHealthTool(executor).Run(ctx, map[string]any{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	health, ok := result.(*nodeHealthResult)
+	if !ok {
+		t.Fatalf("Run() = %v, want a node health result", result)
+	}
+	if health.Total != 2 || health.NotReady != 0 {
+		t.Errorf("total = %d, not ready = %d, want 2 and 0", health.Total, health.NotReady)
+	}
+
+	// The node under pressure is reported first
+	b := health.Nodes[0]
+	if b.Name != "node-b" || !slices.Equal(b.Pressure, []string{"MemoryPressure"}) || len(b.Taints) != 1 {
+		t.Errorf("first node = %+v, want node-b under memory pressure", b)
+	}
+	if len(b.Events) != 1 || b.Events[0].Reason != "EvictionThresholdMet" {
+		t.Errorf("events = %+v", b.Events)
+	}
+	if memory := b.Resources["memory"]; memory.Requests != "3Gi" || memory.RequestsPercent != 75 {
+		t.Errorf("memory = %+v, want 3Gi requested, 75%%", memory)
+	}
+
+	// Init containers run before the containers: the pod requests the most of both
+	a := health.Nodes[1]
+	if cpu := a.Resources["cpu"]; cpu.Requests != "1500m" || cpu.RequestsPercent != 75 {
+		t.Errorf("cpu = %+v, want 1500m requested, 75%%", cpu)
+	}
+	if memory := a.Resources["memory"]; memory.Limits != "2Gi" || memory.LimitsPercent != 50 {
+		t.Errorf("memory = %+v, want a 2Gi limit, 50%%", memory)
+	}
+	if pods := a.Resources["pods"]; pods.Requests != "1" {
+		t.Errorf("pods = %+v, want 1", pods)
+	}
+	if !slices.Equal(a.Roles, []string{"worker"}) {
+		t.Errorf("roles = %v, want worker", a.Roles)
+	}
+}
@@ -0,0 +1,406 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// defaultGraphDepth and maxGraphDepth are the levels of children walked by default,
+	// and at most.
+	defaultGraphDepth = 3
+	maxGraphDepth     = 5
+	// maxGraphOwners limits the levels of owners walked, in case of cycles.
+	maxGraphOwners = 10
+	// maxGraphChildren limits the children of each object, and maxGraphNodes the objects
+	// of the graph.
+	maxGraphChildren = 20
+	maxGraphNodes    = 200
+)
+
+// resourcePattern matches the resources of kubectl get, e.g. pods or rollouts.argoproj.io.
+var resourcePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.-]*$`)
+
+// graphSkippedResources are not listed to find children: they are many, and owned by nothing
+// interesting to troubleshoot.
+var graphSkippedResources = []string{"events", "events.events.k8s.io", "leases.coordination.k8s.io"}
+
+// ObjectGraph walks the owners and the children of an object, following ownerReferences.
+type ObjectGraph struct {
+	executor sandbox.Executor
+}
+
+func NewObjectGraphTool(executor sandbox.Executor) *ObjectGraph {
+	return &ObjectGraph{executor: executor}
+}
+
+func (t *ObjectGraph) Name() string {
+	return "owners_and_children"
+}
+
+func (t *ObjectGraph) Description() string {
+	return `Walks the ownerReferences of an object of any kind, including custom resources, in both directions: up to the owners managing it, e.g. from a pod to its replicaset, deployment or Argo Rollout, and down to the objects it owns, e.g. from a custom resource to the resources its operator generated. Returns the object graph with the status of each object: phase, ready replicas or containers, and conditions.
+Use it to find which controller or operator manages an object, and what an operator created for a custom resource.`
+}
+
+func (t *ObjectGraph) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"resource": {
+					Type:        gollm.TypeString,
+					Description: `Resource of the object, as for kubectl get, e.g. pod, deployment or rollouts.argoproj.io.`,
+				},
+				"name": {
+					Type:        gollm.TypeString,
+					Description: `Name of the object.`,
+				},
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the object. Empty for cluster-scoped objects, whose children are looked up in all namespaces.`,
+				},
+				"depth": {
+					Type:        gollm.TypeInteger,
+					Description: fmt.Sprintf(`Levels of children to walk, up to %d. Defaults to %d.`, maxGraphDepth, defaultGraphDepth),
+				},
+			},
+			Required: []string{"resource", "name"},
+		},
+	}
+}
+
+// graphNode is an object of the graph, with its owners or its children.
+type graphNode struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	// Controller is set if the owner reference between the object and the one it is
+	// listed under is a controller reference, rather than only an owner reference
+	Controller bool          `json:"controller,omitempty"`
+	Status     *objectStatus `json:"status,omitempty"`
+	// Missing is set for owners that do not exist anymore
+	Missing  bool         `json:"missing,omitempty"`
+	Owners   []*graphNode `json:"owners,omitempty"`
+	Children []*graphNode `json:"children,omitempty"`
+	// TotalChildren is set if children were truncated
+	TotalChildren int `json:"totalChildren,omitempty"`
+
+	uid types.UID
+}
+
+// objectStatus summarizes the status of an object of any kind.
+type objectStatus struct {
+	Phase string `json:"phase,omitempty"`
+	// Ready is the number of ready replicas, or of ready containers for pods, e.g. 2/3
+	Ready      string   `json:"ready,omitempty"`
+	Restarts   int32    `json:"restarts,omitempty"`
+	Conditions []string `json:"conditions,omitempty"`
+}
+
+// graphObject is the metadata and status of an object of any kind.
+type graphObject struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        metav1.ObjectMeta `json:"metadata"`
+	Status          json.RawMessage   `json:"status"`
+}
+
+type graphObjectList struct {
+	Items []graphObject `json:"items"`
+}
+
+type objectGraphResult struct {
+	Object *graphNode `json:"object"`
+	// Truncated is set if the graph has more than the objects returned
+	Truncated bool `json:"truncated,omitempty"`
+	// Note reports the kinds that could not be listed to find children
+	Note string `json:"note,omitempty"`
+}
+
+func (t *ObjectGraph) Run(ctx context.Context, args map[string]any) (any, error) {
+	resource, _ := args["resource"].(string)
+	name, _ := args["name"].(string)
+	namespace, _ := args["namespace"].(string)
+	depth := int64(defaultGraphDepth)
+	if d, ok := intArg(args, "depth"); ok {
+		depth = min(d, maxGraphDepth)
+	}
+	// Names are passed to the shell of the executor
+	if !resourcePattern.MatchString(resource) {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid resource %q", resource)}, nil
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid name %q: %s", name, strings.Join(errs, ", "))}, nil
+	}
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return &sandbox.ExecResult{Error: fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))}, nil
+		}
+	}
+
+	var object graphObject
+	if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get "+resource+" "+name+namespaceFlag(namespace)+" -o json", &object); failed != nil || err != nil {
+		return failed, err
+	}
+	root := newGraphNode(object)
+	result := &objectGraphResult{Object: root}
+	nodes := 1
+
+	// Owners are in the namespace of the object, or cluster-scoped
+	owners := []*graphNode{root}
+	refs := [][]metav1.OwnerReference{object.Metadata.OwnerReferences}
+	for level := 0; level < maxGraphOwners && len(owners) > 0; level++ {
+		var nextOwners []*graphNode
+		var nextRefs [][]metav1.OwnerReference
+		for i, node := range owners {
+			for _, ref := range refs[i] {
+				if nodes == maxGraphNodes {
+					result.Truncated = true
+					break
+				}
+				owner, ownerRefs, err := t.owner(ctx, ref, object.Metadata.Namespace)
+				if err != nil {
+					return nil, err
+				}
+				node.Owners = append(node.Owners, owner)
+				nodes++
+				nextOwners = append(nextOwners, owner)
+				nextRefs = append(nextRefs, ownerRefs)
+			}
+		}
+		owners, refs = nextOwners, nextRefs
+	}
+
+	if depth > 0 {
+		children, note, err := t.listChildren(ctx, object.Metadata.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		result.Note = note
+		level := []*graphNode{root}
+		for d := int64(0); d < depth && len(level) > 0; d++ {
+			var next []*graphNode
+			for _, node := range level {
+				owned := children[node.uid]
+				if len(owned) > maxGraphChildren {
+					node.TotalChildren = len(owned)
+					owned = owned[:maxGraphChildren]
+					result.Truncated = true
+				}
+				for _, child := range owned {
+					if nodes == maxGraphNodes {
+						result.Truncated = true
+						break
+					}
+					c := newGraphNode(child)
+					c.Controller = isControlledBy(child.Metadata.OwnerReferences, node.uid)
+					node.Children = append(node.Children, c)
+					nodes++
+					next = append(next, c)
+				}
+			}
+			level = next
+		}
+	}
+	return result, nil
+}
+
+// owner gets the object of an owner reference, with its own owner references.
+func (t *ObjectGraph) owner(ctx context.Context, ref metav1.OwnerReference, namespace string) (*graphNode, []metav1.OwnerReference, error) {
+	node := &graphNode{Kind: ref.Kind, APIVersion: ref.APIVersion, Name: ref.Name, Controller: ptrOr(ref.Controller, false)}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil || !resourcePattern.MatchString(ref.Kind) || len(validation.IsDNS1123Subdomain(ref.Name)) > 0 {
+		node.Missing = true
+		return node, nil, nil
+	}
+	// Kinds are resolved by kubectl as resources, with their version and group
+	resource := ref.Kind
+	if gv.Group != "" {
+		resource += "." + gv.Version + "." + gv.Group
+	}
+	var owner graphObject
+	failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get "+resource+" "+ref.Name+namespaceFlag(namespace)+" -o json", &owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	if failed != nil || owner.Metadata.UID != ref.UID {
+		// Deleted, or deleted and created again
+		node.Missing = true
+		return node, nil, nil
+	}
+	node.Namespace = owner.Metadata.Namespace
+	node.Status = summarizeStatus(owner)
+	node.uid = owner.Metadata.UID
+	return node, owner.Metadata.OwnerReferences, nil
+}
+
+// listChildren lists the objects of all the kinds of the namespace, or of all namespaces,
+// by the UID of their owners. Kinds that cannot be listed are reported in a note.
+func (t *ObjectGraph) listChildren(ctx context.Context, namespace string) (map[types.UID][]graphObject, string, error) {
+	env, err := kubectlEnv(ctx.Value(KubeconfigKey).(string))
+	if err != nil {
+		return nil, "", err
+	}
+	workDir := ctx.Value(WorkDirKey).(string)
+	kinds, err := t.executor.Execute(ctx, "kubectl api-resources --verbs=list --namespaced=true -o name", env, workDir)
+	if err != nil {
+		return nil, "", err
+	}
+	if kinds.ExitCode != 0 && kinds.Stdout == "" {
+		return nil, "cannot list the kinds of the cluster to find children: " + strings.TrimSpace(kinds.Stderr), nil
+	}
+	var resources []string
+	for _, resource := range strings.Fields(kinds.Stdout) {
+		if resourcePattern.MatchString(resource) && !slices.Contains(graphSkippedResources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	if len(resources) == 0 {
+		return nil, "", nil
+	}
+
+	scope := namespaceFlag(namespace)
+	if namespace == "" {
+		scope = " --all-namespaces"
+	}
+	listed, err := t.executor.Execute(ctx, "kubectl get "+strings.Join(resources, ",")+scope+" -o json", env, workDir)
+	if err != nil {
+		return nil, "", err
+	}
+	var list graphObjectList
+	if err := json.Unmarshal([]byte(listed.Stdout), &list); err != nil {
+		return nil, "cannot list the objects of the namespace to find children: " + strings.TrimSpace(listed.Error+" "+listed.Stderr), nil
+	}
+	var note string
+	if listed.ExitCode != 0 {
+		// kubectl lists the kinds it can, e.g. without permission on some
+		note = "some kinds could not be listed, their objects are missing from the children: " + strings.TrimSpace(listed.Stderr)
+	}
+	children := map[types.UID][]graphObject{}
+	for _, item := range list.Items {
+		for _, ref := range item.Metadata.OwnerReferences {
+			children[ref.UID] = append(children[ref.UID], item)
+		}
+	}
+	for _, owned := range children {
+		slices.SortFunc(owned, func(a, b graphObject) int {
+			return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Metadata.Name, b.Metadata.Name))
+		})
+	}
+	return children, note, nil
+}
+
+func (t *ObjectGraph) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *ObjectGraph) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+func newGraphNode(object graphObject) *graphNode {
+	return &graphNode{
+		Kind:       object.Kind,
+		APIVersion: object.APIVersion,
+		Name:       object.Metadata.Name,
+		Namespace:  object.Metadata.Namespace,
+		Status:     summarizeStatus(object),
+		uid:        object.Metadata.UID,
+	}
+}
+
+func isControlledBy(refs []metav1.OwnerReference, uid types.UID) bool {
+	for _, ref := range refs {
+		if ref.UID == uid {
+			return ptrOr(ref.Controller, false)
+		}
+	}
+	return false
+}
+
+// genericStatus are the status fields shared by many kinds, including custom resources.
+type genericStatus struct {
+	Phase             string `json:"phase"`
+	Replicas          *int32 `json:"replicas"`
+	ReadyReplicas     *int32 `json:"readyReplicas"`
+	ContainerStatuses []struct {
+		Ready        bool  `json:"ready"`
+		RestartCount int32 `json:"restartCount"`
+	} `json:"containerStatuses"`
+	Conditions []struct {
+		Type   string `json:"type"`
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	} `json:"conditions"`
+}
+
+// summarizeStatus summarizes the status of an object, or returns nil if it has none known.
+func summarizeStatus(object graphObject) *objectStatus {
+	if len(object.Status) == 0 {
+		return nil
+	}
+	var s genericStatus
+	// Fields of other types, in custom resources, are left empty
+	_ = json.Unmarshal(object.Status, &s)
+	status := &objectStatus{Phase: s.Phase}
+	if s.Replicas != nil {
+		status.Ready = fmt.Sprintf("%d/%d", ptrOr(s.ReadyReplicas, 0), *s.Replicas)
+	}
+	if len(s.ContainerStatuses) > 0 {
+		ready := 0
+		for _, c := range s.ContainerStatuses {
+			if c.Ready {
+				ready++
+			}
+			status.Restarts += c.RestartCount
+		}
+		status.Ready = fmt.Sprintf("%d/%d", ready, len(s.ContainerStatuses))
+	}
+	for _, c := range s.Conditions {
+		condition := c.Type + "=" + c.Status
+		if c.Reason != "" {
+			condition += " (" + c.Reason + ")"
+		}
+		status.Conditions = append(status.Conditions, condition)
+	}
+	if status.Phase == "" && status.Ready == "" && len(status.Conditions) == 0 {
+		return nil
+	}
+	return status
+}
+
+func namespaceFlag(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return " --namespace " + namespace
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestObjectGraph(t *testing.T) {
+	executor := kubectlOutputs{
+		"kubectl get rs web-5d8f --namespace shop -o json": `{
+			"apiVersion": "apps/v1", "kind": "ReplicaSet",
+			"metadata": {"name": "web-5d8f", "namespace": "shop", "uid": "rs-uid",
+				"ownerReferences": [{"apiVersion": "argoproj.io/v1alpha1", "kind": "Rollout", "name": "web", "uid": "rollout-uid", "controller": true}]},
+			"status": {"replicas": 2, "readyReplicas": 1}
+		}`,
+		"kubectl get Rollout.v1alpha1.argoproj.io web --namespace shop -o json": `{
+			"apiVersion": "argoproj.io/v1alpha1", "kind": "Rollout",
+			"metadata": {"name": "web", "namespace": "shop", "uid": "rollout-uid"},
+			"status": {"phase": "Degraded", "replicas": 2, "readyReplicas": 1,
+				"conditions": [{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"}],
+				"canary": {"weights": {"canary": {"weight": 20}}}}
+		}`,
+		"kubectl api-resources --verbs=list --namespaced=true -o name": "events\npods\nreplicasets.apps\n",
+		"kubectl get pods,replicasets.apps --namespace shop -o json": `{"items": [
+			{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web-5d8f-b", "uid": "pod-b",
+				"ownerReferences": [{"kind": "ReplicaSet", "name": "web-5d8f", "uid": "rs-uid", "controller": true}]},
+				"status": {"phase": "Running", "containerStatuses": [{"ready": false, "restartCount": 4}]}},
+			{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web-5d8f-a", "uid": "pod-a",
+				"ownerReferences": [{"kind": "ReplicaSet", "name": "web-5d8f", "uid": "rs-uid", "controller": true}]},
+				"status": {"phase": "Running", "containerStatuses": [{"ready": true}]}},
+			{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "other", "uid": "pod-c"}}
+		]}`,
+	}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	result, err := NewObjectGraphTool(executor).Run(ctx, map[string]any{"resource": "rs", "name": "web-5d8f", "namespace": "shop"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	graph, ok := result.(*objectGraphResult)
+	if !ok {
+		t.Fatalf("Run() = %+v, want an object graph", result)
+	}
+	rs := graph.Object
+	if rs.Status == nil || rs.Status.Ready != "1/2" {
+		t.Errorf("replicaset status = %+v, want 1/2 ready", rs.Status)
+	}
+	if len(rs.Owners) != 1 {
+		t.Fatalf("owners = %+v, want the rollout", rs.Owners)
+	}
+	rollout := rs.Owners[0]
+	if rollout.Kind != "Rollout" || !rollout.Controller || rollout.Missing || rollout.Status == nil || rollout.Status.Phase != "Degraded" {
+		t.Errorf("owner = %+v, want the degraded rollout controlling the replicaset", rollout)
+	}
+	if want := "Progressing=False (ProgressDeadlineExceeded)"; len(rollout.Status.Conditions) != 1 || rollout.Status.Conditions[0] != want {
+		t.Errorf("rollout conditions = %v, want %q", rollout.Status.Conditions, want)
+	}
+	if len(rs.Children) != 2 || rs.Children[0].Name != "web-5d8f-a" || rs.Children[1].Name != "web-5d8f-b" {
+		t.Fatalf("children = %+v, want the two pods, sorted", rs.Children)
+	}
+	if pod := rs.Children[1]; !pod.Controller || pod.Status.Ready != "0/1" || pod.Status.Restarts != 4 {
+		t.Errorf("pod = %+v, want 0/1 ready with 4 restarts", pod.Status)
+	}
+	if graph.Truncated || graph.Note != "" {
+		t.Errorf("truncated = %v, note = %q, want the full graph", graph.Truncated, graph.Note)
+	}
+}
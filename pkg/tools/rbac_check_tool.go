@@ -0,0 +1,411 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxRBACGrants limits the grants returned by rbac_check, the ones of the subject first.
+const maxRBACGrants = 50
+
+// RBACCheck checks whether a user, group or service account may perform an action, and
+// which bindings grant it.
+type RBACCheck struct {
+	executor sandbox.Executor
+}
+
+func NewRBACCheckTool(executor sandbox.Executor) *RBACCheck {
+	return &RBACCheck{executor: executor}
+}
+
+func (t *RBACCheck) Name() string {
+	return "rbac_check"
+}
+
+func (t *RBACCheck) Description() string {
+	return `Checks with kubectl auth can-i whether the current user, or a user, group or service account impersonated with "as" and "as_groups", may perform a verb on a resource, and looks up the roles and bindings granting that verb on that resource, with their subjects.
+Use it to explain why a request is forbidden, e.g. for a service account: as system:serviceaccount:<namespace>:<name>. With "list", it lists all the actions allowed in the namespace instead.`
+}
+
+func (t *RBACCheck) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"verb": {
+					Type:        gollm.TypeString,
+					Description: `Verb of the request, e.g. get, list, create or delete.`,
+				},
+				"resource": {
+					Type:        gollm.TypeString,
+					Description: `Resource of the request, with its API group unless it is a core resource, and an optional subresource, e.g. pods, pods/log or deployments.apps.`,
+				},
+				"name": {
+					Type:        gollm.TypeString,
+					Description: `Name of the resource, to check a request on a single resource.`,
+				},
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the request. Cluster-wide if empty.`,
+				},
+				"as": {
+					Type:        gollm.TypeString,
+					Description: `User or service account to impersonate, e.g. jane@example.com or system:serviceaccount:default:builder.`,
+				},
+				"as_groups": {
+					Type:        gollm.TypeArray,
+					Items:       &gollm.Schema{Type: gollm.TypeString},
+					Description: `Groups to impersonate.`,
+				},
+				"list": {
+					Type:        gollm.TypeBoolean,
+					Description: `List all the actions allowed in the namespace, instead of checking a verb on a resource.`,
+				},
+			},
+		},
+	}
+}
+
+func (t *RBACCheck) Run(ctx context.Context, args map[string]any) (any, error) {
+	verb, _ := args["verb"].(string)
+	resourceArg, _ := args["resource"].(string)
+	name, _ := args["name"].(string)
+	namespace, _ := args["namespace"].(string)
+	as, _ := args["as"].(string)
+	list, _ := args["list"].(bool)
+	var groups []string
+	if values, ok := args["as_groups"].([]any); ok {
+		for _, v := range values {
+			if group, ok := v.(string); ok && group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return &sandbox.ExecResult{Error: fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))}, nil
+		}
+	}
+
+	kubeconfig := ctx.Value(KubeconfigKey).(string)
+	workDir := ctx.Value(WorkDirKey).(string)
+	env, err := kubectlEnv(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	command := []string{"kubectl", "auth", "can-i"}
+	if list {
+		command = append(command, "--list")
+	} else {
+		if verb == "" || resourceArg == "" {
+			return &sandbox.ExecResult{Error: "verb and resource are required, unless list is set"}, nil
+		}
+		target := resourceArg
+		if name != "" {
+			target += "/" + name
+		}
+		command = append(command, shellQuote(verb), shellQuote(target))
+	}
+	if namespace != "" {
+		command = append(command, "--namespace", namespace)
+	}
+	if as != "" {
+		command = append(command, "--as", shellQuote(as))
+	}
+	for _, group := range groups {
+		command = append(command, "--as-group", shellQuote(group))
+	}
+	canI, err := t.executor.Execute(ctx, strings.Join(command, " "), env, workDir)
+	if err != nil {
+		return nil, err
+	}
+	if list {
+		return canI, nil
+	}
+	// can-i prints yes or no, and exits with 1 for no: it failed if it printed neither
+	answer := strings.TrimSpace(canI.Stdout)
+	if answer != "yes" && !strings.HasPrefix(answer, "no") {
+		return canI, nil
+	}
+
+	result := &rbacCheckResult{
+		Command: canI.Command,
+		Allowed: answer == "yes",
+		Answer:  answer,
+	}
+	request := parseRBACRequest(verb, resourceArg, name)
+	grants, err := t.lookupGrants(ctx, request, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if grants.failed != nil {
+		result.LookupError = strings.TrimSpace(grants.failed.Error + " " + grants.failed.Stderr)
+	}
+	subject := newRBACSubject(as, groups)
+	for _, grant := range grants.grants {
+		if subject != nil {
+			matches := slices.ContainsFunc(grant.Subjects, subject.matches)
+			grant.MatchesSubject = &matches
+		}
+		result.GrantedBy = append(result.GrantedBy, grant)
+	}
+	slices.SortStableFunc(result.GrantedBy, func(a, b rbacGrant) int {
+		return boolRank(b.MatchesSubject) - boolRank(a.MatchesSubject)
+	})
+	result.TotalGrants = len(result.GrantedBy)
+	if len(result.GrantedBy) > maxRBACGrants {
+		result.GrantedBy = result.GrantedBy[:maxRBACGrants]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+func (t *RBACCheck) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *RBACCheck) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+type rbacCheckResult struct {
+	Command string `json:"command"`
+	Allowed bool   `json:"allowed"`
+	// Answer is the answer of kubectl auth can-i, which may give a reason
+	Answer string `json:"answer"`
+	// GrantedBy are the rules of roles granting the verb on the resource, with the
+	// bindings granting them and their subjects
+	GrantedBy   []rbacGrant `json:"grantedBy"`
+	TotalGrants int         `json:"totalGrants"`
+	Truncated   bool        `json:"truncated,omitempty"`
+	// LookupError is set if the roles and bindings could not be read, e.g. for lack of
+	// permissions
+	LookupError string `json:"lookupError,omitempty"`
+}
+
+type rbacGrant struct {
+	// Binding is the binding, e.g. RoleBinding default/builder
+	Binding string `json:"binding"`
+	// Role is the role bound, e.g. ClusterRole edit
+	Role string `json:"role"`
+	// Namespace is the namespace granted, empty for ClusterRoleBindings granting all
+	Namespace string            `json:"namespace,omitempty"`
+	Subjects  []rbacv1.Subject  `json:"subjects"`
+	Rule      rbacv1.PolicyRule `json:"rule"`
+	// MatchesSubject is whether the impersonated user, groups or service account are
+	// subjects of the binding
+	MatchesSubject *bool `json:"matchesSubject,omitempty"`
+}
+
+// rbacRequest is the verb on a resource looked up in the rules of roles.
+type rbacRequest struct {
+	verb        string
+	group       string
+	resource    string
+	subresource string
+	name        string
+	// anyGroup is set if the resource was named without its group
+	anyGroup bool
+}
+
+// parseRBACRequest parses resource as kubectl auth can-i does, e.g. deployments.apps/scale.
+func parseRBACRequest(verb, resource, name string) rbacRequest {
+	request := rbacRequest{verb: verb, name: name, anyGroup: true}
+	resource, request.subresource, _ = strings.Cut(resource, "/")
+	request.resource, request.group, _ = strings.Cut(resource, ".")
+	if request.group != "" || slices.Contains(coreResources, request.resource) {
+		request.anyGroup = false
+	}
+	return request
+}
+
+// coreResources are the common resources of the core API group, named without group.
+var coreResources = []string{
+	"pods", "services", "configmaps", "secrets", "serviceaccounts", "namespaces", "nodes",
+	"persistentvolumeclaims", "persistentvolumes", "events", "endpoints", "resourcequotas",
+	"limitranges", "replicationcontrollers",
+}
+
+// matches returns whether the rule grants the request, as the RBAC authorizer does.
+func (r rbacRequest) matches(rule rbacv1.PolicyRule) bool {
+	if !slices.Contains(rule.Verbs, rbacv1.VerbAll) && !slices.Contains(rule.Verbs, r.verb) {
+		return false
+	}
+	if !r.anyGroup && !slices.Contains(rule.APIGroups, rbacv1.APIGroupAll) && !slices.Contains(rule.APIGroups, r.group) {
+		return false
+	}
+	resource := r.resource
+	if r.subresource != "" {
+		resource += "/" + r.subresource
+	}
+	if !slices.ContainsFunc(rule.Resources, func(ruleResource string) bool {
+		return ruleResource == rbacv1.ResourceAll || ruleResource == resource ||
+			(r.subresource != "" && ruleResource == "*/"+r.subresource)
+	}) {
+		return false
+	}
+	return len(rule.ResourceNames) == 0 || slices.Contains(rule.ResourceNames, r.name)
+}
+
+type rbacGrants struct {
+	grants []rbacGrant
+	// failed is the result of the kubectl command that could not read roles or bindings
+	failed *sandbox.ExecResult
+}
+
+// rbacObjects are the roles and bindings of a kubectl get list, of mixed kinds.
+type rbacObjects struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+// lookupGrants finds the bindings granting the request cluster-wide, and in namespace
+// if set.
+func (t *RBACCheck) lookupGrants(ctx context.Context, request rbacRequest, namespace string) (rbacGrants, error) {
+	commands := []string{"kubectl get clusterroles,clusterrolebindings -o json"}
+	if namespace != "" {
+		commands = append(commands, "kubectl get roles,rolebindings --namespace "+namespace+" -o json")
+	}
+	clusterRoles := map[string]rbacv1.ClusterRole{}
+	roles := map[string]rbacv1.Role{}
+	var clusterBindings []rbacv1.ClusterRoleBinding
+	var bindings []rbacv1.RoleBinding
+	for _, command := range commands {
+		var objects rbacObjects
+		failed, err := kubectlGetJSON(ctx, t.executor, command, &objects)
+		if failed != nil || err != nil {
+			return rbacGrants{failed: failed}, err
+		}
+		for _, item := range objects.Items {
+			var meta struct {
+				Kind string `json:"kind"`
+			}
+			if err := json.Unmarshal(item, &meta); err != nil {
+				continue
+			}
+			switch meta.Kind {
+			case "ClusterRole":
+				var role rbacv1.ClusterRole
+				if json.Unmarshal(item, &role) == nil {
+					clusterRoles[role.Name] = role
+				}
+			case "Role":
+				var role rbacv1.Role
+				if json.Unmarshal(item, &role) == nil {
+					roles[role.Name] = role
+				}
+			case "ClusterRoleBinding":
+				var binding rbacv1.ClusterRoleBinding
+				if json.Unmarshal(item, &binding) == nil {
+					clusterBindings = append(clusterBindings, binding)
+				}
+			case "RoleBinding":
+				var binding rbacv1.RoleBinding
+				if json.Unmarshal(item, &binding) == nil {
+					bindings = append(bindings, binding)
+				}
+			}
+		}
+	}
+
+	rules := func(ref rbacv1.RoleRef) []rbacv1.PolicyRule {
+		if ref.Kind == "Role" {
+			return roles[ref.Name].Rules
+		}
+		return clusterRoles[ref.Name].Rules
+	}
+	var result rbacGrants
+	grant := func(binding, namespace string, ref rbacv1.RoleRef, subjects []rbacv1.Subject) {
+		for _, rule := range rules(ref) {
+			if request.matches(rule) {
+				result.grants = append(result.grants, rbacGrant{
+					Binding:   binding,
+					Role:      ref.Kind + " " + ref.Name,
+					Namespace: namespace,
+					Subjects:  subjects,
+					Rule:      rule,
+				})
+			}
+		}
+	}
+	for _, b := range clusterBindings {
+		grant("ClusterRoleBinding "+b.Name, "", b.RoleRef, b.Subjects)
+	}
+	for _, b := range bindings {
+		grant("RoleBinding "+b.Namespace+"/"+b.Name, b.Namespace, b.RoleRef, b.Subjects)
+	}
+	return result, nil
+}
+
+// rbacSubject is the impersonated user or service account, with its groups.
+type rbacSubject struct {
+	user   string
+	groups []string
+	// serviceAccount is the namespace and name of the service account impersonated
+	serviceAccount []string
+}
+
+func newRBACSubject(as string, groups []string) *rbacSubject {
+	if as == "" && len(groups) == 0 {
+		return nil
+	}
+	s := &rbacSubject{user: as, groups: groups}
+	if as != "" {
+		s.groups = append(s.groups, "system:authenticated")
+	}
+	if sa, ok := strings.CutPrefix(as, "system:serviceaccount:"); ok {
+		if namespace, name, ok := strings.Cut(sa, ":"); ok {
+			s.serviceAccount = []string{namespace, name}
+			s.groups = append(s.groups, "system:serviceaccounts", "system:serviceaccounts:"+namespace)
+		}
+	}
+	return s
+}
+
+func (s *rbacSubject) matches(subject rbacv1.Subject) bool {
+	switch subject.Kind {
+	case rbacv1.UserKind:
+		return subject.Name == s.user
+	case rbacv1.GroupKind:
+		return slices.Contains(s.groups, subject.Name)
+	case rbacv1.ServiceAccountKind:
+		return s.serviceAccount != nil && subject.Namespace == s.serviceAccount[0] && subject.Name == s.serviceAccount[1]
+	}
+	return false
+}
+
+func boolRank(b *bool) int {
+	if b != nil && *b {
+		return 1
+	}
+	return 0
+}
+
+// shellQuote quotes s as a single word for the shell running commands.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestRBACCheck(t *testing.T) {
+	executor := kubectlOutputs{
+		"kubectl auth can-i 'get' 'pods/log' --namespace shop --as 'system:serviceaccount:shop:builder'": "no\n",
+		"kubectl get clusterroles,clusterrolebindings -o json": `{"items": [
+			{"kind": "ClusterRole", "metadata": {"name": "view"}, "rules": [{"apiGroups": [""], "resources": ["pods", "pods/log"], "verbs": ["get", "list"]}]},
+			{"kind": "ClusterRole", "metadata": {"name": "deployer"}, "rules": [{"apiGroups": ["apps"], "resources": ["deployments"], "verbs": ["*"]}]},
+			{"kind": "ClusterRoleBinding", "metadata": {"name": "auditors"}, "roleRef": {"kind": "ClusterRole", "name": "view"},
+				"subjects": [{"kind": "Group", "name": "auditors"}]}
+		]}`,
+		"kubectl get roles,rolebindings --namespace shop -o json": `{"items": [
+			{"kind": "Role", "metadata": {"name": "logs", "namespace": "shop"}, "rules": [{"apiGroups": ["*"], "resources": ["*/log"], "verbs": ["get"]}]},
+			{"kind": "RoleBinding", "metadata": {"name": "ci", "namespace": "shop"}, "roleRef": {"kind": "Role", "name": "logs"},
+				"subjects": [{"kind": "ServiceAccount", "namespace": "shop", "name": "ci"}]},
+			{"kind": "RoleBinding", "metadata": {"name": "deployers", "namespace": "shop"}, "roleRef": {"kind": "ClusterRole", "name": "deployer"},
+				"subjects": [{"kind": "Group", "name": "system:serviceaccounts:shop"}]}
+		]}`,
+	}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	result, err := NewRBACCheckTool(executor).Run(ctx, map[string]any{
+		"verb":      "get",
+		"resource":  "pods/log",
+		"namespace": "shop",
+		"as":        "system:serviceaccount:shop:builder",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	check, ok := result.(*rbacCheckResult)
+	if !ok {
+		t.Fatalf("Run() = %v, want an RBAC check result", result)
+	}
+	if check.Allowed {
+		t.Errorf("allowed = true, want false")
+	}
+	var bindings []string
+	for _, grant := range check.GrantedBy {
+		bindings = append(bindings, grant.Binding)
+		if grant.MatchesSubject == nil || *grant.MatchesSubject {
+			t.Errorf("grant %s matches the service account, want not", grant.Binding)
+		}
+	}
+	if len(bindings) != 2 || bindings[0] != "ClusterRoleBinding auditors" || bindings[1] != "RoleBinding shop/ci" {
+		t.Errorf("granted by %v, want the auditors and ci bindings", bindings)
+	}
+}
+
+func TestRBACRequestMatches(t *testing.T) {
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"config"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"*/scale"}, Verbs: []string{"*"}},
+	}
+	tests := []struct {
+		verb, resource, name string
+		want                 bool
+	}{
+		{verb: "get", resource: "pods", want: true},
+		{verb: "delete", resource: "pods", want: false},
+		{verb: "get", resource: "pods", name: "web-0", want: true},
+		{verb: "get", resource: "secrets", name: "token", want: false},
+		{verb: "get", resource: "secrets", name: "config", want: true},
+		{verb: "update", resource: "deployments.apps/scale", want: true},
+		{verb: "update", resource: "deployments/scale", want: true},
+		{verb: "update", resource: "deployments.extensions/scale", want: false},
+		{verb: "update", resource: "deployments.apps", want: false},
+	}
+	for _, tt := range tests {
+		request := parseRBACRequest(tt.verb, tt.resource, tt.name)
+		if got := slices.ContainsFunc(rules, request.matches); got != tt.want {
+			t.Errorf("%s %s %s: matches = %v, want %v", tt.verb, tt.resource, tt.name, got, tt.want)
+		}
+	}
+}
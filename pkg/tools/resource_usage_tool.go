@@ -0,0 +1,370 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// defaultUnderusePercent is the usage, in percent of requests, under which containers
+	// are reported as overprovisioned.
+	defaultUnderusePercent = 10
+	// nearLimitPercent is the usage, in percent of limits, from which containers are
+	// reported at risk of throttling or OOM kills.
+	nearLimitPercent = 90
+	// maxUsageWorkloads limits the workloads reported, the ones with findings first.
+	maxUsageWorkloads = 50
+)
+
+// ResourceUsage compares the usage of pods, from the metrics API, to their requests and limits.
+type ResourceUsage struct {
+	executor sandbox.Executor
+}
+
+func NewResourceUsageTool(executor sandbox.Executor) *ResourceUsage {
+	return &ResourceUsage{executor: executor}
+}
+
+func (t *ResourceUsage) Name() string {
+	return "resource_usage"
+}
+
+func (t *ResourceUsage) Description() string {
+	return `Takes a snapshot of the CPU and memory usage of the containers of a namespace with kubectl top, and compares it to their requests and limits, per workload: containers using less than a threshold of their requests are overprovisioned, containers using more than their requests or close to their limits are underprovisioned. Reports the totals of the namespace, with the requests that could be reclaimed.
+Use it to answer right-sizing and capacity questions with data. Usage is a point-in-time snapshot: mention it, and prefer checking again at peak time before lowering requests. It needs the metrics API, e.g. metrics-server.`
+}
+
+func (t *ResourceUsage) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the pods.`,
+				},
+				"selector": {
+					Type:        gollm.TypeString,
+					Description: `Label selector of the pods, e.g. app=web. All the pods of the namespace if empty.`,
+				},
+				"threshold_percent": {
+					Type:        gollm.TypeInteger,
+					Description: fmt.Sprintf(`Usage, in percent of requests, under which containers are overprovisioned. Defaults to %d.`, defaultUnderusePercent),
+				},
+			},
+			Required: []string{"namespace"},
+		},
+	}
+}
+
+type resourceUsageResult struct {
+	Namespace        string         `json:"namespace"`
+	ThresholdPercent int64          `json:"thresholdPercent"`
+	CPU              namespaceUsage `json:"cpu"`
+	Memory           namespaceUsage `json:"memory"`
+	// Overprovisioned and Underprovisioned count the containers of workloads
+	Overprovisioned  int              `json:"overprovisioned"`
+	Underprovisioned int              `json:"underprovisioned"`
+	Workloads        []*workloadUsage `json:"workloads"`
+	// PodsWithoutMetrics are the running pods the metrics API has no usage for yet
+	PodsWithoutMetrics []string `json:"podsWithoutMetrics,omitempty"`
+	Truncated          bool     `json:"truncated,omitempty"`
+}
+
+// namespaceUsage totals the usage, requests and limits of the pods with metrics.
+type namespaceUsage struct {
+	Usage           string `json:"usage"`
+	Requests        string `json:"requests"`
+	Limits          string `json:"limits,omitempty"`
+	RequestsPercent int64  `json:"usagePercentOfRequests,omitempty"`
+	// Reclaimable is the sum of the requests overprovisioned containers do not use
+	Reclaimable string `json:"reclaimable,omitempty"`
+}
+
+type workloadUsage struct {
+	Kind       string            `json:"kind"`
+	Name       string            `json:"name"`
+	Pods       int               `json:"pods"`
+	Containers []*containerUsage `json:"containers"`
+
+	findings int
+}
+
+type containerUsage struct {
+	Name     string        `json:"name"`
+	CPU      containerUsed `json:"cpu"`
+	Memory   containerUsed `json:"memory"`
+	Findings []string      `json:"findings,omitempty"`
+
+	cpu, memory containerTotal
+}
+
+// containerUsed is the usage of a container across the pods of its workload, against the
+// requests and limits of each.
+type containerUsed struct {
+	Average        string `json:"average"`
+	Max            string `json:"max"`
+	Request        string `json:"request,omitempty"`
+	Limit          string `json:"limit,omitempty"`
+	RequestPercent int64  `json:"maxPercentOfRequest,omitempty"`
+	LimitPercent   int64  `json:"maxPercentOfLimit,omitempty"`
+}
+
+// containerTotal accumulates the usage of a container across pods, in millicores or bytes.
+type containerTotal struct {
+	sum, max, request, limit int64
+}
+
+func (t *ResourceUsage) Run(ctx context.Context, args map[string]any) (any, error) {
+	namespace, _ := args["namespace"].(string)
+	selector, _ := args["selector"].(string)
+	threshold := int64(defaultUnderusePercent)
+	if p, ok := intArg(args, "threshold_percent"); ok {
+		threshold = min(p, 100)
+	}
+	// Names are passed to the shell of the executor
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))}, nil
+	}
+	scope := " --namespace " + namespace
+	if selector != "" {
+		if _, err := labels.Parse(selector); err != nil {
+			return &sandbox.ExecResult{Error: fmt.Sprintf("invalid selector %q: %v", selector, err)}, nil
+		}
+		scope += " --selector " + shellQuote(selector)
+	}
+
+	var pods corev1.PodList
+	if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get pods"+scope+" --field-selector status.phase=Running -o json", &pods); failed != nil || err != nil {
+		return failed, err
+	}
+	env, err := kubectlEnv(ctx.Value(KubeconfigKey).(string))
+	if err != nil {
+		return nil, err
+	}
+	top, err := t.executor.Execute(ctx, "kubectl top pods"+scope+" --containers --no-headers", env, ctx.Value(WorkDirKey).(string))
+	if err != nil {
+		return nil, err
+	}
+	if top.Error != "" || top.ExitCode != 0 {
+		top.Error = strings.TrimSpace("cannot get the usage of the pods from the metrics API, is metrics-server installed? " + top.Error)
+		return top, nil
+	}
+	usage, err := parseTopContainers(top.Stdout)
+	if err != nil {
+		return &sandbox.ExecResult{Command: top.Command, Error: err.Error()}, nil
+	}
+
+	result := &resourceUsageResult{Namespace: namespace, ThresholdPercent: threshold}
+	var cpu, memory struct{ usage, requests, limits, reclaimable int64 }
+	workloads := map[string]*workloadUsage{}
+	for _, pod := range pods.Items {
+		podUsage, ok := usage[pod.Name]
+		if !ok {
+			result.PodsWithoutMetrics = append(result.PodsWithoutMetrics, pod.Name)
+			continue
+		}
+		kind, name := podWorkload(pod)
+		w := workloads[kind+"/"+name]
+		if w == nil {
+			w = &workloadUsage{Kind: kind, Name: name}
+			workloads[kind+"/"+name] = w
+		}
+		w.Pods++
+		for _, container := range pod.Spec.Containers {
+			used, ok := podUsage[container.Name]
+			if !ok {
+				continue
+			}
+			i := slices.IndexFunc(w.Containers, func(c *containerUsage) bool { return c.Name == container.Name })
+			if i < 0 {
+				w.Containers = append(w.Containers, &containerUsage{Name: container.Name})
+				i = len(w.Containers) - 1
+			}
+			c := w.Containers[i]
+			addUsage(&c.cpu, used[0], container.Resources.Requests.Cpu().MilliValue(), container.Resources.Limits.Cpu().MilliValue())
+			addUsage(&c.memory, used[1], container.Resources.Requests.Memory().Value(), container.Resources.Limits.Memory().Value())
+			cpu.usage += used[0]
+			cpu.requests += container.Resources.Requests.Cpu().MilliValue()
+			cpu.limits += container.Resources.Limits.Cpu().MilliValue()
+			memory.usage += used[1]
+			memory.requests += container.Resources.Requests.Memory().Value()
+			memory.limits += container.Resources.Limits.Memory().Value()
+		}
+	}
+
+	for _, w := range workloads {
+		for _, c := range w.Containers {
+			var over, under bool
+			c.CPU, over, under = c.cpu.summarize(w.Pods, threshold, formatMilliCPU, "cpu", "likely throttled", &c.Findings)
+			if over {
+				cpu.reclaimable += (c.cpu.request - c.cpu.max) * int64(w.Pods)
+			}
+			countProvisioning(result, w, over, under)
+			c.Memory, over, under = c.memory.summarize(w.Pods, threshold, formatMemory, "memory", "at risk of OOM kills", &c.Findings)
+			if over {
+				memory.reclaimable += (c.memory.request - c.memory.max) * int64(w.Pods)
+			}
+			countProvisioning(result, w, over, under)
+		}
+		result.Workloads = append(result.Workloads, w)
+	}
+	result.CPU = summarizeNamespaceUsage(cpu.usage, cpu.requests, cpu.limits, cpu.reclaimable, formatMilliCPU)
+	result.Memory = summarizeNamespaceUsage(memory.usage, memory.requests, memory.limits, memory.reclaimable, formatMemory)
+
+	slices.SortFunc(result.Workloads, func(a, b *workloadUsage) int {
+		return cmp.Or(cmp.Compare(b.findings, a.findings), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	if len(result.Workloads) > maxUsageWorkloads {
+		result.Workloads = result.Workloads[:maxUsageWorkloads]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+func (t *ResourceUsage) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *ResourceUsage) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+// parseTopContainers parses the output of kubectl top pods --containers --no-headers, into
+// the CPU, in millicores, and memory, in bytes, of the containers of each pod.
+func parseTopContainers(out string) (map[string]map[string][2]int64, error) {
+	usage := map[string]map[string][2]int64{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected output of kubectl top: %q", line)
+		}
+		cpu, err := resource.ParseQuantity(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("parsing the CPU usage %q: %w", fields[2], err)
+		}
+		memory, err := resource.ParseQuantity(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("parsing the memory usage %q: %w", fields[3], err)
+		}
+		if usage[fields[0]] == nil {
+			usage[fields[0]] = map[string][2]int64{}
+		}
+		usage[fields[0]][fields[1]] = [2]int64{cpu.MilliValue(), memory.Value()}
+	}
+	return usage, nil
+}
+
+// podWorkload returns the workload managing a pod: the deployment of its replicaset, its
+// controller, or the pod itself.
+func podWorkload(pod corev1.Pod) (string, string) {
+	for _, owner := range pod.OwnerReferences {
+		if !ptrOr(owner.Controller, false) {
+			continue
+		}
+		if hash := pod.Labels["pod-template-hash"]; owner.Kind == "ReplicaSet" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+		return owner.Kind, owner.Name
+	}
+	return "Pod", pod.Name
+}
+
+func addUsage(total *containerTotal, used, request, limit int64) {
+	total.sum += used
+	total.max = max(total.max, used)
+	total.request = max(total.request, request)
+	total.limit = max(total.limit, limit)
+}
+
+// summarize compares the usage of a container to its requests and limits, adds the findings,
+// and returns whether the container is overprovisioned or underprovisioned.
+func (c containerTotal) summarize(pods int, threshold int64, format func(int64) string, name, nearLimit string, findings *[]string) (containerUsed, bool, bool) {
+	used := containerUsed{Average: format(c.sum / int64(pods)), Max: format(c.max)}
+	var over, under bool
+	if c.request > 0 {
+		used.Request = format(c.request)
+		used.RequestPercent = c.max * 100 / c.request
+		switch {
+		case used.RequestPercent < threshold:
+			*findings = append(*findings, fmt.Sprintf("%s: using at most %d%% of its request, overprovisioned", name, used.RequestPercent))
+			over = true
+		case used.RequestPercent > 100:
+			*findings = append(*findings, fmt.Sprintf("%s: using up to %d%% of its request, underprovisioned", name, used.RequestPercent))
+			under = true
+		}
+	} else {
+		*findings = append(*findings, fmt.Sprintf("%s: no request, the scheduler does not reserve what it uses", name))
+	}
+	if c.limit > 0 {
+		used.Limit = format(c.limit)
+		used.LimitPercent = c.max * 100 / c.limit
+		if used.LimitPercent >= nearLimitPercent {
+			*findings = append(*findings, fmt.Sprintf("%s: using up to %d%% of its limit, %s", name, used.LimitPercent, nearLimit))
+			under = true
+		}
+	}
+	return used, over, under
+}
+
+func countProvisioning(result *resourceUsageResult, w *workloadUsage, over, under bool) {
+	if over {
+		result.Overprovisioned++
+		w.findings++
+	}
+	if under {
+		result.Underprovisioned++
+		w.findings++
+	}
+}
+
+func summarizeNamespaceUsage(usage, requests, limits, reclaimable int64, format func(int64) string) namespaceUsage {
+	u := namespaceUsage{Usage: format(usage), Requests: format(requests)}
+	if limits > 0 {
+		u.Limits = format(limits)
+	}
+	if requests > 0 {
+		u.RequestsPercent = usage * 100 / requests
+	}
+	if reclaimable > 0 {
+		u.Reclaimable = format(reclaimable)
+	}
+	return u
+}
+
+func formatMilliCPU(m int64) string {
+	return fmt.Sprintf("%dm", m)
+}
+
+func formatMemory(b int64) string {
+	return fmt.Sprintf("%dMi", (b+1<<19)>>20)
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestResourceUsage(t *testing.T) {
+	webPod := func(name string) string {
+		return `{"metadata": {"name": "` + name + `", "labels": {"pod-template-hash": "5d8f"},
+			"ownerReferences": [{"kind": "ReplicaSet", "name": "web-5d8f", "controller": true}]},
+			"spec": {"containers": [{"name": "app", "resources": {
+				"requests": {"cpu": "500m", "memory": "256Mi"}, "limits": {"memory": "256Mi"}}}]}}`
+	}
+	executor := kubectlOutputs{
+		"kubectl get pods --namespace shop --field-selector status.phase=Running -o json": `{"items": [` +
+			webPod("web-5d8f-a") + `,` + webPod("web-5d8f-b") + `,
+			{"metadata": {"name": "debug"}, "spec": {"containers": [{"name": "shell"}]}},
+			{"metadata": {"name": "starting"}, "spec": {"containers": [{"name": "app"}]}}
+		]}`,
+		"kubectl top pods --namespace shop --containers --no-headers": "web-5d8f-a   app     20m   200Mi\n" +
+			"web-5d8f-b   app     30m   250Mi\n" +
+			"debug        shell   1m    4Mi\n",
+	}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	result, err := NewResourceUsageTool(executor).Run(ctx, map[string]any{"namespace": "shop"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	usage, ok := result.(*resourceUsageResult)
+	if !ok {
+		t.Fatalf("Run() = %+v, want a usage snapshot", result)
+	}
+	if len(usage.Workloads) != 2 || usage.Workloads[0].Kind != "Deployment" || usage.Workloads[0].Name != "web" || usage.Workloads[0].Pods != 2 {
+		t.Fatalf("workloads = %+v, want the web deployment first", usage.Workloads)
+	}
+	app := usage.Workloads[0].Containers[0]
+	if app.CPU.Average != "25m" || app.CPU.Max != "30m" || app.CPU.RequestPercent != 6 {
+		t.Errorf("cpu = %+v, want 25m on average, at most 6%% of 500m", app.CPU)
+	}
+	if app.Memory.LimitPercent != 97 {
+		t.Errorf("memory = %+v, want 97%% of the limit", app.Memory)
+	}
+	if len(app.Findings) != 2 {
+		t.Errorf("findings = %q, want overprovisioned cpu and memory near its limit", app.Findings)
+	}
+	if usage.Overprovisioned != 1 || usage.Underprovisioned != 1 {
+		t.Errorf("overprovisioned = %d, underprovisioned = %d, want 1 and 1", usage.Overprovisioned, usage.Underprovisioned)
+	}
+	// Both pods leave 470m of their 500m request unused
+	if usage.CPU.Reclaimable != "940m" || usage.CPU.Requests != "1000m" || usage.CPU.Usage != "51m" {
+		t.Errorf("namespace cpu = %+v, want 940m reclaimable", usage.CPU)
+	}
+	if !slices.Equal(usage.PodsWithoutMetrics, []string{"starting"}) {
+		t.Errorf("pods without metrics = %v, want [starting]", usage.PodsWithoutMetrics)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// rolloutOperations are the operations of the rollout tool, and whether they modify the workload.
+var rolloutOperations = map[string]bool{
+	"status":  false,
+	"history": false,
+	"pause":   true,
+	"resume":  true,
+	"undo":    true,
+}
+
+// Rollout manages the rollouts of deployments, statefulsets and daemonsets.
+type Rollout struct {
+	executor sandbox.Executor
+}
+
+func NewRolloutTool(executor sandbox.Executor) *Rollout {
+	return &Rollout{executor: executor}
+}
+
+func (t *Rollout) Name() string {
+	return "rollout"
+}
+
+func (t *Rollout) Description() string {
+	return `Manages the rollout of a deployment, statefulset or daemonset with kubectl rollout: "status" and "history" report the rollout and its revisions, "pause" and "resume" pause and resume the rollout of a deployment, and "undo" rolls back to the previous revision, or to to_revision.
+Operations that change the workload report the revision before the change, previousRevision, to undo them with undo and to_revision. Use dry_run to check an undo first.`
+}
+
+func (t *Rollout) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"operation": {
+					Type:        gollm.TypeString,
+					Description: `Operation: status, history, pause, resume or undo.`,
+				},
+				"kind": {
+					Type:        gollm.TypeString,
+					Description: `Kind of the workload: deployment, statefulset or daemonset.`,
+				},
+				"name": {
+					Type:        gollm.TypeString,
+					Description: `Name of the workload.`,
+				},
+				"namespace": {
+					Type:        gollm.TypeString,
+					Description: `Namespace of the workload.`,
+				},
+				"revision": {
+					Type:        gollm.TypeInteger,
+					Description: `For history, the revision to show in detail, with its pod template.`,
+				},
+				"to_revision": {
+					Type:        gollm.TypeInteger,
+					Description: `For undo, the revision to roll back to. Defaults to the previous revision.`,
+				},
+				"dry_run": {
+					Type:        gollm.TypeBoolean,
+					Description: `For undo, only show the pod template it would roll back to, without changing the workload.`,
+				},
+			},
+			Required: []string{"operation", "kind", "name", "namespace"},
+		},
+	}
+}
+
+type rolloutResult struct {
+	Operation string `json:"operation"`
+	Command   string `json:"command"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Revision is the current revision of the workload, after the operation
+	Revision int64 `json:"revision,omitempty"`
+	// PreviousRevision is the revision before the operation changed the workload, to undo
+	// the change with to_revision
+	PreviousRevision int64 `json:"previousRevision,omitempty"`
+}
+
+func (t *Rollout) Run(ctx context.Context, args map[string]any) (any, error) {
+	operation, _ := args["operation"].(string)
+	kindArg, _ := args["kind"].(string)
+	name, _ := args["name"].(string)
+	namespace, _ := args["namespace"].(string)
+	dryRun, _ := args["dry_run"].(bool)
+
+	modifies, ok := rolloutOperations[operation]
+	if !ok {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("unsupported operation %q, supported operations: status, history, pause, resume, undo", operation)}, nil
+	}
+	kind, ok := workloadKinds[strings.ToLower(kindArg)]
+	if !ok {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("unsupported workload kind %q, supported kinds: deployment, statefulset, daemonset", kindArg)}, nil
+	}
+	if (operation == "pause" || operation == "resume") && kind != "deployment" {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("only the rollouts of deployments can be paused and resumed, not of %ss", kind)}, nil
+	}
+	// Names are passed to the shell of the executor
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid name %q: %s", name, strings.Join(errs, ", "))}, nil
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return &sandbox.ExecResult{Error: fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))}, nil
+	}
+
+	command := fmt.Sprintf("kubectl rollout %s %s/%s --namespace %s", operation, kind, name, namespace)
+	switch operation {
+	case "status":
+		// Report the current status instead of waiting for the rollout
+		command += " --watch=false"
+	case "history":
+		if revision, ok := intArg(args, "revision"); ok {
+			command += " --revision " + strconv.FormatInt(revision, 10)
+		}
+	case "undo":
+		if revision, ok := intArg(args, "to_revision"); ok {
+			command += " --to-revision " + strconv.FormatInt(revision, 10)
+		}
+		if dryRun {
+			command += " --dry-run=server"
+			modifies = false
+		}
+	}
+
+	result := &rolloutResult{Operation: operation, Command: command}
+	if modifies {
+		revision, failed, err := t.revision(ctx, kind, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if failed != nil {
+			return failed, nil
+		}
+		result.PreviousRevision = revision
+	}
+
+	env, err := kubectlEnv(ctx.Value(KubeconfigKey).(string))
+	if err != nil {
+		return nil, err
+	}
+	out, err := t.executor.Execute(ctx, command, env, ctx.Value(WorkDirKey).(string))
+	if err != nil {
+		return nil, err
+	}
+	result.Output = strings.TrimSpace(out.Stdout)
+	if out.Error != "" || out.ExitCode != 0 {
+		result.Error = strings.TrimSpace(out.Error + " " + out.Stderr)
+		return result, nil
+	}
+
+	revision, failed, err := t.revision(ctx, kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if failed == nil {
+		result.Revision = revision
+	}
+	if result.PreviousRevision == result.Revision {
+		// Pausing and resuming do not change the revision
+		result.PreviousRevision = 0
+	}
+	return result, nil
+}
+
+// revision returns the current revision of a workload: the revision of the replicaset of
+// deployments, and the latest controller revision of statefulsets and daemonsets.
+func (t *Rollout) revision(ctx context.Context, kind, name, namespace string) (int64, *sandbox.ExecResult, error) {
+	if kind == "deployment" {
+		var d appsv1.Deployment
+		if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get deployment "+name+" --namespace "+namespace+" -o json", &d); failed != nil || err != nil {
+			return 0, failed, err
+		}
+		revision, _ := strconv.ParseInt(d.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+		return revision, nil, nil
+	}
+
+	var workload metav1.PartialObjectMetadata
+	if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get "+kind+" "+name+" --namespace "+namespace+" -o json", &workload); failed != nil || err != nil {
+		return 0, failed, err
+	}
+	var revisions appsv1.ControllerRevisionList
+	if failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get controllerrevisions --namespace "+namespace+" -o json", &revisions); failed != nil || err != nil {
+		return 0, failed, err
+	}
+	var latest int64
+	for _, r := range revisions.Items {
+		for _, owner := range r.OwnerReferences {
+			if owner.UID == workload.UID {
+				latest = max(latest, r.Revision)
+			}
+		}
+	}
+	return latest, nil, nil
+}
+
+func (t *Rollout) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+// CheckModifiesResource returns "yes" for the operations changing the workload, except
+// undo dry runs.
+func (t *Rollout) CheckModifiesResource(args map[string]any) string {
+	operation, _ := args["operation"].(string)
+	modifies, ok := rolloutOperations[operation]
+	if !ok {
+		return "unknown"
+	}
+	if dryRun, _ := args["dry_run"].(bool); operation == "undo" && dryRun {
+		return "no"
+	}
+	if modifies {
+		return "yes"
+	}
+	return "no"
+}
+
+// intArg returns a positive integer argument: JSON numbers decode as float64.
+func intArg(args map[string]any, name string) (int64, bool) {
+	switch v := args[name].(type) {
+	case float64:
+		return int64(v), v > 0
+	case int:
+		return int64(v), v > 0
+	case int64:
+		return v, v > 0
+	}
+	return 0, false
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+)
+
+// rolloutCluster fakes a deployment rolled back by kubectl rollout undo.
+type rolloutCluster struct {
+	revision int
+	commands []string
+}
+
+func (c *rolloutCluster) Execute(ctx context.Context, command string, env []string, workDir string) (*sandbox.ExecResult, error) {
+	c.commands = append(c.commands, command)
+	switch command {
+	case "kubectl get deployment web --namespace shop -o json":
+		return &sandbox.ExecResult{Stdout: fmt.Sprintf(`{"metadata": {"annotations": {"deployment.kubernetes.io/revision": "%d"}}}`, c.revision)}, nil
+	case "kubectl rollout undo deployment/web --namespace shop --to-revision 2":
+		c.revision++
+		return &sandbox.ExecResult{Stdout: "deployment.apps/web rolled back"}, nil
+	case "kubectl rollout undo deployment/web --namespace shop --dry-run=server":
+		return &sandbox.ExecResult{Stdout: "deployment.apps/web Pod Template: ..."}, nil
+	}
+	return &sandbox.ExecResult{Stderr: "unexpected command", ExitCode: 1}, nil
+}
+
+func (c *rolloutCluster) Close(ctx context.Context) error { return nil }
+
+func TestRolloutUndo(t *testing.T) {
+	cluster := &rolloutCluster{revision: 3}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+	tool := NewRolloutTool(cluster)
+
+	args := map[string]any{"operation": "undo", "kind": "deploy", "name": "web", "namespace": "shop", "to_revision": float64(2)}
+	if got := tool.CheckModifiesResource(args); got != "yes" {
+		t.Errorf("CheckModifiesResource() = %q, want yes", got)
+	}
+	result, err := tool.Run(ctx, args)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	undo, ok := result.(*rolloutResult)
+	if !ok || undo.Error != "" {
+		t.Fatalf("Run() = %+v, want a rollout result", result)
+	}
+	if undo.PreviousRevision != 3 || undo.Revision != 4 {
+		t.Errorf("revisions = %d -> %d, want 3 -> 4", undo.PreviousRevision, undo.Revision)
+	}
+
+	dryRun := map[string]any{"operation": "undo", "kind": "deployment", "name": "web", "namespace": "shop", "dry_run": true}
+	if got := tool.CheckModifiesResource(dryRun); got != "no" {
+		t.Errorf("CheckModifiesResource(dry run) = %q, want no", got)
+	}
+	result, err = tool.Run(ctx, dryRun)
+	if err != nil {
+		t.Fatalf("Run(dry run) error = %v", err)
+	}
+	if r := result.(*rolloutResult); r.Error != "" || r.PreviousRevision != 0 || cluster.revision != 4 {
+		t.Errorf("dry run = %+v, want no change", r)
+	}
+
+	paused := map[string]any{"operation": "pause", "kind": "statefulset", "name": "db", "namespace": "shop"}
+	if result, _ := tool.Run(ctx, paused); result.(*sandbox.ExecResult).Error == "" {
+		t.Errorf("pausing a statefulset succeeded, want an error")
+	}
+}
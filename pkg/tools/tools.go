@@ -98,6 +98,15 @@ func (t *Tools) RegisterTool(tool Tool) {
 	t.tools[name] = tool
 }
 
+// RemoveMCPTools unregisters all the tools of MCP servers, so that they can be registered again.
+func (t *Tools) RemoveMCPTools() {
+	for name, tool := range t.tools {
+		if _, ok := tool.(*MCPTool); ok {
+			delete(t.tools, name)
+		}
+	}
+}
+
 // CloneWithExecutor creates a shallow copy of the Tools collection,
 // but clones any tools that need a session-specific executor (like CustomTool).
 func (t *Tools) CloneWithExecutor(executor sandbox.Executor) Tools {
@@ -176,12 +185,17 @@ type InvokeToolOptions struct {
 
 	// Executor is the executor for tool execution
 	Executor sandbox.Executor
+
+	// Justification is why the model makes the call, recorded with it.
+	Justification string
 }
 
 type ToolRequestEvent struct {
 	CallID    string         `json:"id,omitempty"`
 	Name      string         `json:"name,omitempty"`
 	Arguments map[string]any `json:"arguments,omitempty"`
+	// Justification is why the model makes the call, if it is required to say.
+	Justification string `json:"justification,omitempty"`
 }
 
 type ToolResponseEvent struct {
@@ -197,11 +211,12 @@ func (t *ToolCall) InvokeTool(ctx context.Context, opt InvokeToolOptions) (any,
 	callID := uuid.NewString()
 	recorder.Write(ctx, &journal.Event{
 		Timestamp: time.Now(),
-		Action:    "tool-request",
+		Action:    journal.ActionToolRequest,
 		Payload: ToolRequestEvent{
-			CallID:    callID,
-			Name:      t.name,
-			Arguments: t.arguments,
+			CallID:        callID,
+			Name:          t.name,
+			Arguments:     t.arguments,
+			Justification: opt.Justification,
 		},
 	})
 
@@ -223,7 +238,7 @@ func (t *ToolCall) InvokeTool(ctx context.Context, opt InvokeToolOptions) (any,
 		}
 		recorder.Write(ctx, &journal.Event{
 			Timestamp: time.Now(),
-			Action:    "tool-response",
+			Action:    journal.ActionToolResponse,
 			Payload:   ev,
 		})
 	}
@@ -0,0 +1,527 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// kubeconformDir is the directory of the work directory with the manifests and schemas
+	// of validate_manifest.
+	kubeconformDir = ".kubeconform"
+	// maxFileChunk is the size of the chunks files are written in, to fit in the arguments
+	// of a command.
+	maxFileChunk = 64 << 10
+	// maxValidationResults limits the invalid resources reported.
+	maxValidationResults = 50
+)
+
+// schemaLocation is where validate_manifest writes the schemas of the cluster, converted
+// from its OpenAPI schemas or CRDs, as a kubeconform schema location. kubeconform sets Group
+// to the version for the core group, e.g. v1 for v1 pods.
+const schemaLocation = kubeconformDir + "/schemas/{{ .Group }}/{{ .ResourceKind }}_{{ .ResourceAPIVersion }}.json"
+
+// missingSchemaPattern matches the errors of kubeconform for kinds without schema.
+var missingSchemaPattern = regexp.MustCompile(`could not find schema for (\S+)`)
+
+// ValidateManifest validates manifests against the schemas of the cluster with kubeconform,
+// without sending them to the API server. The schemas are converted from the OpenAPI
+// schemas served by the cluster, so nothing is downloaded.
+type ValidateManifest struct {
+	executor sandbox.Executor
+}
+
+func NewValidateManifestTool(executor sandbox.Executor) *ValidateManifest {
+	return &ValidateManifest{executor: executor}
+}
+
+func (t *ValidateManifest) Name() string {
+	return "validate_manifest"
+}
+
+func (t *ValidateManifest) Description() string {
+	return `Validates Kubernetes manifests with kubeconform, against the OpenAPI schemas served by the cluster (/openapi/v3), including its custom resources, without sending them to the API server or downloading schemas. Reports the resources that are invalid with the path and error of each invalid field, including unknown fields.
+Resources whose schema the cluster does not serve, e.g. on clusters older than 1.24 or without access to /openapi/v3, are reported as not validated, with a note. Custom resources are then validated against their CRDs if possible.
+Use it to check manifests before applying them, and fix the reported fields instead of retrying failed applies. Pass either the manifests, or the path of a file or directory of manifests.`
+}
+
+func (t *ValidateManifest) FunctionDefinition() *gollm.FunctionDefinition {
+	return &gollm.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &gollm.Schema{
+			Type: gollm.TypeObject,
+			Properties: map[string]*gollm.Schema{
+				"manifest": {
+					Type:        gollm.TypeString,
+					Description: `YAML or JSON manifests to validate, separated by ---.`,
+				},
+				"path": {
+					Type:        gollm.TypeString,
+					Description: `Path of a file or directory of manifests to validate, relative to the working directory.`,
+				},
+			},
+		},
+	}
+}
+
+// kubeconformOutput is the output of kubeconform -output json.
+type kubeconformOutput struct {
+	Resources []struct {
+		Filename         string `json:"filename"`
+		Kind             string `json:"kind"`
+		Name             string `json:"name"`
+		Version          string `json:"version"`
+		Status           string `json:"status"`
+		Msg              string `json:"msg"`
+		ValidationErrors []struct {
+			Path string `json:"path"`
+			Msg  string `json:"msg"`
+		} `json:"validationErrors"`
+	} `json:"resources"`
+}
+
+type manifestValidation struct {
+	Valid bool `json:"valid"`
+	// Resources are the resources that are invalid or could not be validated
+	Resources []*validatedResource `json:"resources,omitempty"`
+	// Truncated is set if more resources are invalid than returned
+	Truncated bool `json:"truncated,omitempty"`
+	// Note reports the resources whose schemas could not be retrieved
+	Note string `json:"note,omitempty"`
+}
+
+type validatedResource struct {
+	File       string `json:"file,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Name       string `json:"name,omitempty"`
+	// Status is invalid, or error if the resource could not be validated
+	Status string       `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	Fields []fieldError `json:"fields,omitempty"`
+}
+
+type fieldError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+func (t *ValidateManifest) Run(ctx context.Context, args map[string]any) (any, error) {
+	manifest, _ := args["manifest"].(string)
+	files, _ := args["path"].(string)
+	if (manifest == "") == (files == "") {
+		return &sandbox.ExecResult{Error: "pass either manifest or path"}, nil
+	}
+	if manifest != "" {
+		files = kubeconformDir + "/manifest.yaml"
+		if failed, err := writeExecutorFile(ctx, t.executor, files, manifest); failed != nil || err != nil {
+			return failed, err
+		}
+	}
+
+	out, failed, err := t.kubeconform(ctx, files)
+	if failed != nil || err != nil {
+		return failed, err
+	}
+
+	// Schemas are converted from the cluster the first time they are needed
+	missing := map[schema.GroupVersionKind]bool{}
+	for _, r := range out.Resources {
+		if m := missingSchemaPattern.FindStringSubmatch(r.Msg); m != nil {
+			missing[schema.FromAPIVersionAndKind(r.Version, r.Kind)] = true
+		}
+	}
+	var notes []string
+	if len(missing) > 0 {
+		written, note, err := t.writeOpenAPISchemas(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		if note != "" {
+			notes = append(notes, note)
+		}
+		if len(missing) > 0 {
+			// The custom resources of clusters not serving their schemas
+			crds, note, err := t.writeCRDSchemas(ctx, missing)
+			if err != nil {
+				return nil, err
+			}
+			if note != "" {
+				notes = append(notes, note)
+			}
+			written += crds
+		}
+		if written > 0 {
+			out, failed, err = t.kubeconform(ctx, files)
+			if failed != nil || err != nil {
+				return failed, err
+			}
+		}
+	}
+
+	result := &manifestValidation{Valid: true, Note: strings.Join(notes, "; ")}
+	for _, r := range out.Resources {
+		status := strings.TrimPrefix(r.Status, "status")
+		if status != "Invalid" && status != "Error" {
+			continue
+		}
+		result.Valid = false
+		if len(result.Resources) == maxValidationResults {
+			result.Truncated = true
+			continue
+		}
+		resource := &validatedResource{
+			File:       strings.TrimPrefix(r.Filename, kubeconformDir+"/"),
+			Kind:       r.Kind,
+			APIVersion: r.Version,
+			Name:       r.Name,
+			Status:     strings.ToLower(status),
+		}
+		for _, e := range r.ValidationErrors {
+			resource.Fields = append(resource.Fields, fieldError{Path: e.Path, Error: e.Msg})
+		}
+		if len(resource.Fields) == 0 {
+			resource.Error = r.Msg
+		}
+		result.Resources = append(result.Resources, resource)
+	}
+	return result, nil
+}
+
+// kubeconform validates files with the schemas written in the work directory.
+func (t *ValidateManifest) kubeconform(ctx context.Context, files string) (*kubeconformOutput, *sandbox.ExecResult, error) {
+	command := "kubeconform -output json -strict -schema-location " + shellQuote(schemaLocation) + " " + shellQuote(files)
+
+	env, err := kubectlEnv(ctx.Value(KubeconfigKey).(string))
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := t.executor.Execute(ctx, command, env, ctx.Value(WorkDirKey).(string))
+	if err != nil {
+		return nil, nil, err
+	}
+	// kubeconform exits with 1 if resources are invalid
+	var out kubeconformOutput
+	if err := json.Unmarshal([]byte(result.Stdout), &out); err != nil {
+		if strings.Contains(result.Stdout+result.Stderr, "kubeconform: command not found") || result.ExitCode == 127 {
+			return nil, &sandbox.ExecResult{Command: command, Error: "kubeconform is not installed, see https://github.com/yannh/kubeconform#installation"}, nil
+		}
+		result.Command = command
+		return nil, result, nil
+	}
+	return &out, nil, nil
+}
+
+// openAPIDocument is the part of the OpenAPI v3 documents of the cluster needed to validate
+// resources: the index at /openapi/v3 has the paths, the document of a group version the
+// schemas.
+type openAPIDocument struct {
+	Paths map[string]struct {
+		ServerRelativeURL string `json:"serverRelativeURL"`
+	} `json:"paths"`
+	Components struct {
+		Schemas map[string]map[string]any `json:"schemas"`
+	} `json:"components"`
+}
+
+// writeOpenAPISchemas writes the schemas of the missing kinds served by the cluster at
+// /openapi/v3, removing them from missing, and returns the number of schemas written, and a
+// note if the schemas cannot be retrieved.
+func (t *ValidateManifest) writeOpenAPISchemas(ctx context.Context, missing map[schema.GroupVersionKind]bool) (int, string, error) {
+	var index openAPIDocument
+	failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get --raw /openapi/v3", &index)
+	if err != nil {
+		return 0, "", err
+	}
+	if failed != nil {
+		return 0, "cannot get the OpenAPI schemas of the cluster from /openapi/v3: " + strings.TrimSpace(failed.Error+" "+failed.Stderr), nil
+	}
+
+	groupVersions := map[schema.GroupVersion]bool{}
+	for gvk := range missing {
+		groupVersions[gvk.GroupVersion()] = true
+	}
+	written := 0
+	for gv := range groupVersions {
+		key := "apis/" + gv.Group + "/" + gv.Version
+		if gv.Group == "" {
+			key = "api/" + gv.Version
+		}
+		p, ok := index.Paths[key]
+		if !ok {
+			continue
+		}
+		var doc openAPIDocument
+		failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get --raw "+shellQuote(p.ServerRelativeURL), &doc)
+		if err != nil {
+			return 0, "", err
+		}
+		if failed != nil {
+			return written, "cannot get the OpenAPI schemas of " + key + ": " + strings.TrimSpace(failed.Error+" "+failed.Stderr), nil
+		}
+		for name, s := range doc.Components.Schemas {
+			gvks, _ := s["x-kubernetes-group-version-kind"].([]any)
+			for _, v := range gvks {
+				m, _ := v.(map[string]any)
+				group, _ := m["group"].(string)
+				version, _ := m["version"].(string)
+				kind, _ := m["kind"].(string)
+				gvk := schema.GroupVersionKind{Group: group, Version: version, Kind: kind}
+				if !missing[gvk] {
+					continue
+				}
+				if failed, err := t.writeSchema(ctx, gvk, openAPIJSONSchema(doc.Components.Schemas, name)); failed != nil || err != nil {
+					if err != nil {
+						return 0, "", err
+					}
+					return written, "cannot write the schemas of the cluster: " + strings.TrimSpace(failed.Error+" "+failed.Stderr), nil
+				}
+				delete(missing, gvk)
+				written++
+			}
+		}
+	}
+	return written, "", nil
+}
+
+// writeSchema writes the JSON schema of a kind where kubeconform looks for it.
+func (t *ValidateManifest) writeSchema(ctx context.Context, gvk schema.GroupVersionKind, s map[string]any) (*sandbox.ExecResult, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("converting the schema of %s: %w", gvk, err)
+	}
+	group := gvk.Group
+	if group == "" {
+		group = gvk.Version
+	}
+	file := path.Join(kubeconformDir, "schemas", group, strings.ToLower(gvk.Kind)+"_"+gvk.Version+".json")
+	return writeExecutorFile(ctx, t.executor, file, string(b))
+}
+
+// openAPIJSONSchema converts the schema name of an OpenAPI v3 document to a standalone JSON
+// schema for kubeconform: the schemas it references, directly or not, are converted too and
+// included under components/schemas, where their references point.
+func openAPIJSONSchema(schemas map[string]map[string]any, name string) map[string]any {
+	s := convertOpenAPISchema(schemas[name])
+	components := map[string]any{}
+	pending := []string{name}
+	for len(pending) > 0 {
+		next := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		forEachRef(schemas[next], func(ref string) {
+			target, ok := strings.CutPrefix(ref, "#/components/schemas/")
+			if _, done := components[target]; !ok || done || schemas[target] == nil {
+				return
+			}
+			components[target] = convertOpenAPISchema(schemas[target])
+			pending = append(pending, target)
+		})
+	}
+	s["components"] = map[string]any{"schemas": components}
+	return s
+}
+
+// forEachRef calls f with the $ref of every schema nested in v.
+func forEachRef(v any, f func(ref string)) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			if ref, ok := value.(string); ok && k == "$ref" {
+				f(ref)
+				continue
+			}
+			forEachRef(value, f)
+		}
+	case []any:
+		for _, value := range v {
+			forEachRef(value, f)
+		}
+	}
+}
+
+// crdList is the part of CustomResourceDefinitions needed to validate custom resources.
+type crdList struct {
+	Items []struct {
+		Spec struct {
+			Group string `json:"group"`
+			Names struct {
+				Kind string `json:"kind"`
+			} `json:"names"`
+			Versions []struct {
+				Name   string `json:"name"`
+				Schema struct {
+					OpenAPIV3Schema map[string]any `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// writeCRDSchemas writes the schemas of the missing kinds defined by CRDs of the cluster,
+// removing them from missing, and returns the number of schemas written, and a note on the
+// kinds it could not find.
+func (t *ValidateManifest) writeCRDSchemas(ctx context.Context, missing map[schema.GroupVersionKind]bool) (int, string, error) {
+	var crds crdList
+	failed, err := kubectlGetJSON(ctx, t.executor, "kubectl get customresourcedefinitions -o json", &crds)
+	if err != nil {
+		return 0, "", err
+	}
+	if failed != nil {
+		return 0, "cannot get the custom resource definitions of the cluster to validate custom resources: " + strings.TrimSpace(failed.Error+" "+failed.Stderr), nil
+	}
+
+	written := 0
+	for _, crd := range crds.Items {
+		for _, v := range crd.Spec.Versions {
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}
+			if !missing[gvk] || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			if failed, err := t.writeSchema(ctx, gvk, crdJSONSchema(v.Schema.OpenAPIV3Schema)); failed != nil || err != nil {
+				if err != nil {
+					return 0, "", err
+				}
+				return 0, "cannot write the schemas of custom resources: " + strings.TrimSpace(failed.Error+" "+failed.Stderr), nil
+			}
+			delete(missing, gvk)
+			written++
+		}
+	}
+	var unknown []string
+	for gvk := range missing {
+		unknown = append(unknown, gvk.Kind+"."+gvk.Version+"."+gvk.Group)
+	}
+	if len(unknown) > 0 {
+		return written, "no schema was found for these kinds, not defined by the cluster: " + strings.Join(unknown, ", "), nil
+	}
+	return written, "", nil
+}
+
+// crdJSONSchema converts the OpenAPI schema of a CRD to a JSON schema for kubeconform:
+// nullable fields accept null, and objects refuse unknown fields, as the API server prunes
+// them, unless they preserve them.
+func crdJSONSchema(openAPI map[string]any) map[string]any {
+	s := convertOpenAPISchema(openAPI)
+	properties, _ := s["properties"].(map[string]any)
+	if properties == nil {
+		properties = map[string]any{}
+		s["properties"] = properties
+	}
+	for _, field := range []string{"apiVersion", "kind"} {
+		if _, ok := properties[field]; !ok {
+			properties[field] = map[string]any{"type": "string"}
+		}
+	}
+	if _, ok := properties["metadata"]; !ok {
+		properties["metadata"] = map[string]any{"type": "object"}
+	}
+	if _, ok := s["additionalProperties"]; !ok && s["x-kubernetes-preserve-unknown-fields"] != true {
+		s["additionalProperties"] = false
+	}
+	return s
+}
+
+func convertOpenAPISchema(openAPI map[string]any) map[string]any {
+	s := make(map[string]any, len(openAPI))
+	for k, v := range openAPI {
+		switch k {
+		case "properties":
+			if properties, ok := v.(map[string]any); ok {
+				converted := make(map[string]any, len(properties))
+				for name, p := range properties {
+					if p, ok := p.(map[string]any); ok {
+						converted[name] = convertOpenAPISchema(p)
+					}
+				}
+				v = converted
+			}
+		case "items", "additionalProperties", "not":
+			if p, ok := v.(map[string]any); ok {
+				v = convertOpenAPISchema(p)
+			}
+		case "allOf", "anyOf", "oneOf":
+			if list, ok := v.([]any); ok {
+				converted := make([]any, len(list))
+				for i, p := range list {
+					if p, ok := p.(map[string]any); ok {
+						converted[i] = convertOpenAPISchema(p)
+					} else {
+						converted[i] = p
+					}
+				}
+				v = converted
+			}
+		}
+		s[k] = v
+	}
+	if nullable, _ := s["nullable"].(bool); nullable {
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []any{typ, "null"}
+		}
+		delete(s, "nullable")
+	}
+	_, hasProperties := s["properties"]
+	_, hasAdditional := s["additionalProperties"]
+	if hasProperties && !hasAdditional && s["x-kubernetes-preserve-unknown-fields"] != true {
+		s["additionalProperties"] = false
+	}
+	return s
+}
+
+// writeExecutorFile writes a file, relative to the work directory, where the executor runs
+// commands. It returns the failed result, if any.
+func writeExecutorFile(ctx context.Context, executor sandbox.Executor, file, content string) (*sandbox.ExecResult, error) {
+	env, err := kubectlEnv(ctx.Value(KubeconfigKey).(string))
+	if err != nil {
+		return nil, err
+	}
+	workDir := ctx.Value(WorkDirKey).(string)
+	command := "mkdir -p " + shellQuote(path.Dir(file)) + " && : > " + shellQuote(file)
+	for {
+		result, err := executor.Execute(ctx, command, env, workDir)
+		if err != nil {
+			return nil, err
+		}
+		if result.Error != "" || result.ExitCode != 0 {
+			return result, nil
+		}
+		if content == "" {
+			return nil, nil
+		}
+		// Chunks fit in the arguments of commands, limited to 128 KiB each on Linux
+		chunk := content[:min(len(content), maxFileChunk)]
+		content = content[len(chunk):]
+		command = "printf '%s' " + shellQuote(chunk) + " >> " + shellQuote(file)
+	}
+}
+
+func (t *ValidateManifest) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *ValidateManifest) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
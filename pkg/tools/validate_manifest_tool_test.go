@@ -0,0 +1,226 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sandbox"
+)
+
+// kubeconformCluster fakes a cluster serving the OpenAPI schemas of apps/v1 but not of its
+// custom resources, kubeconform finding the schemas once written, and the files written by
+// the executor.
+type kubeconformCluster struct {
+	files    map[string]string
+	commands []string
+}
+
+func (c *kubeconformCluster) Execute(ctx context.Context, command string, env []string, workDir string) (*sandbox.ExecResult, error) {
+	c.commands = append(c.commands, command)
+	switch {
+	case command == "kubectl get --raw /openapi/v3":
+		return &sandbox.ExecResult{Stdout: `{"paths": {"api/v1": {"serverRelativeURL": "/openapi/v3/api/v1?hash=1"},
+			"apis/apps/v1": {"serverRelativeURL": "/openapi/v3/apis/apps/v1?hash=2"}}}`}, nil
+	case command == "kubectl get --raw '/openapi/v3/apis/apps/v1?hash=2'":
+		return &sandbox.ExecResult{Stdout: `{"components": {"schemas": {
+			"io.k8s.api.apps.v1.Deployment": {"type": "object", "properties": {
+				"apiVersion": {"type": "string"}, "kind": {"type": "string"},
+				"metadata": {"allOf": [{"$ref": "#/components/schemas/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"}]},
+				"spec": {"allOf": [{"$ref": "#/components/schemas/io.k8s.api.apps.v1.DeploymentSpec"}]}},
+				"x-kubernetes-group-version-kind": [{"group": "apps", "version": "v1", "kind": "Deployment"}]},
+			"io.k8s.api.apps.v1.DeploymentSpec": {"type": "object", "properties": {"replicas": {"type": "integer"}}},
+			"io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"io.k8s.api.apps.v1.StatefulSet": {"type": "object",
+				"x-kubernetes-group-version-kind": [{"group": "apps", "version": "v1", "kind": "StatefulSet"}]}}}}`}, nil
+	case command == "kubectl get customresourcedefinitions -o json":
+		return &sandbox.ExecResult{Stdout: `{"items": [{"spec": {"group": "example.com", "names": {"kind": "Widget"},
+			"versions": [{"name": "v1", "schema": {"openAPIV3Schema": {"type": "object", "properties": {
+				"spec": {"type": "object", "properties": {"size": {"type": "integer"}, "color": {"type": "string", "nullable": true}}},
+				"status": {"type": "object", "x-kubernetes-preserve-unknown-fields": true}}}}}]}}]}`}, nil
+	case strings.HasPrefix(command, "mkdir -p '"):
+		file := command[strings.Index(command, " > ")+3:]
+		c.files[strings.Trim(file, "'")] = ""
+		return &sandbox.ExecResult{}, nil
+	case strings.HasPrefix(command, "printf '%s' '"):
+		content, file, _ := strings.Cut(strings.TrimPrefix(command, "printf '%s' '"), "' >> ")
+		c.files[strings.Trim(file, "'")] += strings.ReplaceAll(content, `'\''`, "'")
+		return &sandbox.ExecResult{}, nil
+	case strings.Contains(command, "kubeconform -output json -strict"):
+		kind, version, schema, field := "Widget", "example.com/v1", ".kubeconform/schemas/example.com/widget_v1.json", "/spec/size"
+		if strings.Contains(c.files[".kubeconform/manifest.yaml"], "kind: Deployment") {
+			kind, version, schema, field = "Deployment", "apps/v1", ".kubeconform/schemas/apps/deployment_v1.json", "/spec/replicas"
+		}
+		if _, ok := c.files[schema]; !ok {
+			return &sandbox.ExecResult{ExitCode: 1, Stdout: `{"resources": [{"filename": ".kubeconform/manifest.yaml", "kind": "` + kind + `", "name": "w", "version": "` + version + `",
+				"status": "statusError", "msg": "could not find schema for ` + kind + `"}]}`}, nil
+		}
+		return &sandbox.ExecResult{ExitCode: 1, Stdout: `{"resources": [{"filename": ".kubeconform/manifest.yaml", "kind": "` + kind + `", "name": "w", "version": "` + version + `",
+			"status": "statusInvalid", "msg": "problem validating schema",
+			"validationErrors": [{"path": "` + field + `", "msg": "expected integer, but got string"}]}]}`}, nil
+	}
+	return &sandbox.ExecResult{Stderr: "unexpected command", ExitCode: 1}, nil
+}
+
+func (c *kubeconformCluster) Close(ctx context.Context) error { return nil }
+
+func TestValidateManifest(t *testing.T) {
+	cluster := &kubeconformCluster{files: map[string]string{}}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	manifest := "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\nspec:\n  size: 'large'\n"
+	result, err := NewValidateManifestTool(cluster).Run(ctx, map[string]any{"manifest": manifest})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	validation, ok := result.(*manifestValidation)
+	if !ok {
+		t.Fatalf("Run() = %+v, want a validation", result)
+	}
+	if got := cluster.files[".kubeconform/manifest.yaml"]; got != manifest {
+		t.Errorf("manifest written = %q, want %q", got, manifest)
+	}
+	if validation.Valid || len(validation.Resources) != 1 || validation.Note != "" {
+		t.Fatalf("validation = %+v, want the invalid widget", validation)
+	}
+	r := validation.Resources[0]
+	if r.Status != "invalid" || r.File != "manifest.yaml" || len(r.Fields) != 1 || r.Fields[0].Path != "/spec/size" {
+		t.Errorf("resource = %+v, want the invalid /spec/size field", r)
+	}
+
+	var schema struct {
+		AdditionalProperties bool `json:"additionalProperties"`
+		Properties           map[string]struct {
+			AdditionalProperties *bool `json:"additionalProperties"`
+			Properties           map[string]struct {
+				Type any `json:"type"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(cluster.files[".kubeconform/schemas/example.com/widget_v1.json"]), &schema); err != nil {
+		t.Fatalf("parsing the widget schema: %v", err)
+	}
+	if spec := schema.Properties["spec"]; spec.AdditionalProperties == nil || *spec.AdditionalProperties {
+		t.Errorf("spec refuses unknown fields = %v, want true", spec.AdditionalProperties)
+	}
+	if status := schema.Properties["status"]; status.AdditionalProperties != nil {
+		t.Errorf("status preserving unknown fields refuses them")
+	}
+	if _, ok := schema.Properties["kind"]; !ok {
+		t.Errorf("schema has no kind")
+	}
+	if typ, ok := schema.Properties["spec"].Properties["color"].Type.([]any); !ok || len(typ) != 2 {
+		t.Errorf("nullable color type = %v, want string or null", schema.Properties["spec"].Properties["color"].Type)
+	}
+
+	var kubeconform []string
+	for _, command := range cluster.commands {
+		if strings.Contains(command, "kubeconform -output json") {
+			kubeconform = append(kubeconform, command)
+		}
+	}
+	// Schemas are never downloaded
+	if len(kubeconform) != 2 || strings.Contains(kubeconform[0], "default") {
+		t.Errorf("kubeconform commands = %q, want 2 with the schemas of the cluster only", kubeconform)
+	}
+}
+
+func TestValidateManifestOpenAPISchemas(t *testing.T) {
+	cluster := &kubeconformCluster{files: map[string]string{}}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: w\nspec:\n  replicas: 'three'\n"
+	result, err := NewValidateManifestTool(cluster).Run(ctx, map[string]any{"manifest": manifest})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	validation, ok := result.(*manifestValidation)
+	if !ok {
+		t.Fatalf("Run() = %+v, want a validation", result)
+	}
+	if validation.Valid || len(validation.Resources) != 1 || validation.Resources[0].Fields[0].Path != "/spec/replicas" || validation.Note != "" {
+		t.Fatalf("validation = %+v, want the invalid deployment", validation)
+	}
+	for _, command := range cluster.commands {
+		if strings.Contains(command, "customresourcedefinitions") || strings.Contains(command, "api/v1?") {
+			t.Errorf("unneeded command %q", command)
+		}
+	}
+
+	// The schema is standalone, with the schemas it references
+	var schema struct {
+		AdditionalProperties bool `json:"additionalProperties"`
+		Components           struct {
+			Schemas map[string]struct {
+				AdditionalProperties bool `json:"additionalProperties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(cluster.files[".kubeconform/schemas/apps/deployment_v1.json"]), &schema); err != nil {
+		t.Fatalf("parsing the deployment schema: %v", err)
+	}
+	if schema.AdditionalProperties {
+		t.Errorf("deployment allows unknown fields")
+	}
+	components := schema.Components.Schemas
+	if _, ok := components["io.k8s.api.apps.v1.DeploymentSpec"]; !ok || len(components) != 2 {
+		t.Errorf("components = %v, want DeploymentSpec and ObjectMeta", components)
+	}
+	if spec := components["io.k8s.api.apps.v1.DeploymentSpec"]; spec.AdditionalProperties {
+		t.Errorf("deployment spec allows unknown fields")
+	}
+}
+
+func TestValidateManifestWithoutOpenAPI(t *testing.T) {
+	cluster := &kubeconformCluster{files: map[string]string{}}
+	ctx := context.WithValue(context.Background(), KubeconfigKey, "")
+	ctx = context.WithValue(ctx, WorkDirKey, t.TempDir())
+
+	// The cluster does not serve /openapi/v3, e.g. before 1.24
+	executor := &failingCommand{Executor: cluster, command: "kubectl get --raw /openapi/v3"}
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: w\nspec:\n  replicas: 'three'\n"
+	result, err := NewValidateManifestTool(executor).Run(ctx, map[string]any{"manifest": manifest})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	validation, ok := result.(*manifestValidation)
+	if !ok {
+		t.Fatalf("Run() = %+v, want a validation", result)
+	}
+	if validation.Valid || len(validation.Resources) != 1 || validation.Resources[0].Status != "error" {
+		t.Errorf("validation = %+v, want the deployment not validated", validation)
+	}
+	if !strings.Contains(validation.Note, "/openapi/v3") {
+		t.Errorf("note = %q, want it to explain the schemas are not served", validation.Note)
+	}
+}
+
+// failingCommand fails command, and runs the others with Executor.
+type failingCommand struct {
+	sandbox.Executor
+	command string
+}
+
+func (e *failingCommand) Execute(ctx context.Context, command string, env []string, workDir string) (*sandbox.ExecResult, error) {
+	if command == e.command {
+		return &sandbox.ExecResult{ExitCode: 1, Stderr: "the server could not find the requested resource"}, nil
+	}
+	return e.Executor.Execute(ctx, command, env, workDir)
+}
@@ -0,0 +1,438 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"k8s.io/klog/v2"
+)
+
+// authCookieName is the cookie holding the token for browser sessions.
+const authCookieName = "kubectl-ai-token"
+
+// ServerOptions configures security of the HTML UI server.
+type ServerOptions struct {
+	// AuthToken is a static token clients must present.
+	AuthToken string
+	// OIDCIssuerURL and OIDCClientID enable verification of OIDC ID tokens
+	// (for example forwarded by an authenticating proxy).
+	OIDCIssuerURL string
+	OIDCClientID  string
+	// TLSCertFile and TLSKeyFile enable serving over HTTPS.
+	TLSCertFile string
+	TLSKeyFile  string
+	// UserTokens maps static tokens to user names, identifying each user of a shared server.
+	UserTokens map[string]string
+	// MultiUser scopes sessions to the authenticated user that created them.
+	MultiUser bool
+	// KubeconfigDir holds a subdirectory of kubeconfig files per user in multi-user mode.
+	KubeconfigDir string
+}
+
+// Option is a functional option for configuring ServerOptions.
+type Option func(*ServerOptions)
+
+// WithAuthToken requires clients to present the given static token.
+func WithAuthToken(token string) Option {
+	return func(o *ServerOptions) {
+		o.AuthToken = token
+	}
+}
+
+// WithOIDC requires clients to present an ID token issued by issuerURL for clientID.
+func WithOIDC(issuerURL, clientID string) Option {
+	return func(o *ServerOptions) {
+		o.OIDCIssuerURL = issuerURL
+		o.OIDCClientID = clientID
+	}
+}
+
+// WithTLS serves the UI over HTTPS using the given certificate and key files.
+func WithTLS(certFile, keyFile string) Option {
+	return func(o *ServerOptions) {
+		o.TLSCertFile = certFile
+		o.TLSKeyFile = keyFile
+	}
+}
+
+// WithUsers serves multiple users, each identified by their token (token -> user name)
+// or by their OIDC identity. Sessions are only visible to the user that created them.
+func WithUsers(userTokens map[string]string) Option {
+	return func(o *ServerOptions) {
+		o.UserTokens = userTokens
+		o.MultiUser = true
+	}
+}
+
+// WithKubeconfigDir lets users pick a kubeconfig from <dir>/<user>/ when creating a session.
+func WithKubeconfigDir(dir string) Option {
+	return func(o *ServerOptions) {
+		o.KubeconfigDir = dir
+	}
+}
+
+func (o *ServerOptions) authEnabled() bool {
+	return o.AuthToken != "" || o.OIDCIssuerURL != "" || len(o.UserTokens) != 0
+}
+
+func (o *ServerOptions) tlsEnabled() bool {
+	return o.TLSCertFile != "" || o.TLSKeyFile != ""
+}
+
+func (o *ServerOptions) validate() error {
+	if (o.TLSCertFile == "") != (o.TLSKeyFile == "") {
+		return fmt.Errorf("both a TLS certificate and key file must be provided")
+	}
+	if (o.OIDCIssuerURL == "") != (o.OIDCClientID == "") {
+		return fmt.Errorf("both an OIDC issuer URL and client ID must be provided")
+	}
+	if o.MultiUser && len(o.UserTokens) == 0 && o.OIDCIssuerURL == "" {
+		return fmt.Errorf("serving multiple users requires user tokens or OIDC authentication")
+	}
+	if o.KubeconfigDir != "" && !o.MultiUser {
+		return fmt.Errorf("a per-user kubeconfig directory requires multi-user mode")
+	}
+	return nil
+}
+
+// isLoopbackAddress reports whether a listen address only accepts local connections.
+func isLoopbackAddress(listenAddress string) bool {
+	host, _, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+type userContextKey struct{}
+
+// contextWithUser returns a context carrying the authenticated user name.
+func contextWithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// userFromContext returns the authenticated user of a request, or "" if the
+// server does not distinguish users.
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userContextKey{}).(string)
+	return user
+}
+
+// authenticator checks credentials on incoming requests.
+type authenticator struct {
+	token      string
+	userTokens map[string]string // token -> user name
+	oidc       *oidcVerifier
+	// secureCookies marks the auth cookie Secure when serving over TLS
+	secureCookies bool
+}
+
+func newAuthenticator(ctx context.Context, opts *ServerOptions) (*authenticator, error) {
+	a := &authenticator{
+		token:         opts.AuthToken,
+		userTokens:    opts.UserTokens,
+		secureCookies: opts.tlsEnabled(),
+	}
+	if opts.OIDCIssuerURL != "" {
+		v, err := newOIDCVerifier(ctx, opts.OIDCIssuerURL, opts.OIDCClientID)
+		if err != nil {
+			return nil, fmt.Errorf("initializing OIDC verifier: %w", err)
+		}
+		a.oidc = v
+	}
+	return a, nil
+}
+
+// verify checks a token and returns the name of the user it identifies.
+// The shared static token identifies no particular user.
+func (a *authenticator) verify(ctx context.Context, token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	if a.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1 {
+		return "", true
+	}
+	for userToken, user := range a.userTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(userToken)) == 1 {
+			return user, true
+		}
+	}
+	if a.oidc != nil {
+		user, err := a.oidc.Verify(ctx, token)
+		if err != nil {
+			klog.FromContext(ctx).V(2).Info("rejecting OIDC token", "error", err)
+			return "", false
+		}
+		return user, true
+	}
+	return "", false
+}
+
+// middleware rejects requests without valid credentials. Credentials are read from
+// the Authorization header or the auth cookie. A browser can bootstrap the cookie by
+// opening the UI once with a ?token= query parameter.
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		if bearer, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+			if user, ok := a.verify(ctx, bearer); ok {
+				next.ServeHTTP(w, req.WithContext(contextWithUser(ctx, user)))
+				return
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if token := req.URL.Query().Get("token"); token != "" && req.Method == http.MethodGet && req.URL.Path == "/" {
+			if _, ok := a.verify(ctx, token); !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     authCookieName,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   a.secureCookies,
+				SameSite: http.SameSiteStrictMode,
+			})
+			// Drop the token from the URL so it does not linger in the browser history.
+			http.Redirect(w, req, "./", http.StatusFound)
+			return
+		}
+
+		if cookie, err := req.Cookie(authCookieName); err == nil {
+			if user, ok := a.verify(ctx, cookie.Value); ok {
+				next.ServeHTTP(w, req.WithContext(contextWithUser(ctx, user)))
+				return
+			}
+		}
+
+		http.Error(w, "unauthorized: open the UI with ?token=<token> or send an Authorization: Bearer header", http.StatusUnauthorized)
+	})
+}
+
+// crossOriginProtection rejects state-changing requests issued by other sites (CSRF).
+// Browsers always send Sec-Fetch-Site or Origin on such requests; non-browser
+// clients send neither and are allowed through.
+func crossOriginProtection(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		switch req.Header.Get("Sec-Fetch-Site") {
+		case "", "same-origin", "none":
+		default:
+			http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+			return
+		}
+
+		if origin := req.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != req.Host {
+				http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// oidcVerifier verifies ID tokens against the signing keys published by an OIDC issuer.
+type oidcVerifier struct {
+	issuer     string
+	clientID   string
+	jwksURI    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]any // kid -> public key
+	lastRefresh time.Time
+}
+
+// jwksMinRefreshInterval limits how often unknown key IDs trigger a JWKS fetch.
+const jwksMinRefreshInterval = time.Minute
+
+func newOIDCVerifier(ctx context.Context, issuer, clientID string) (*oidcVerifier, error) {
+	v := &oidcVerifier{
+		issuer:     strings.TrimSuffix(issuer, "/"),
+		clientID:   clientID,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       make(map[string]any),
+	}
+
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, v.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("fetching OIDC discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != v.issuer {
+		return nil, fmt.Errorf("OIDC issuer mismatch: configured %q, discovered %q", v.issuer, discovery.Issuer)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document has no jwks_uri")
+	}
+	// Tokens carry the issuer exactly as published by the provider
+	v.issuer = discovery.Issuer
+	v.jwksURI = discovery.JWKSURI
+
+	if err := v.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Verify checks the signature, issuer, audience and expiry of an ID token and
+// returns the user it identifies: the email claim if present, otherwise the subject.
+func (v *oidcVerifier) Verify(ctx context.Context, token string) (string, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return "", err
+	}
+	if email, ok := claims["email"].(string); ok && email != "" {
+		return email, nil
+	}
+	return claims.GetSubject()
+}
+
+func (v *oidcVerifier) key(ctx context.Context, kid string) (any, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	// The issuer may have rotated its keys
+	if time.Since(v.lastRefresh) >= jwksMinRefreshInterval {
+		if err := v.refreshKeysLocked(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := v.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (v *oidcVerifier) refreshKeys(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.refreshKeysLocked(ctx)
+}
+
+func (v *oidcVerifier) refreshKeysLocked(ctx context.Context) error {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	v.lastRefresh = time.Now()
+	if err := v.getJSON(ctx, v.jwksURI, &jwks); err != nil {
+		return fmt.Errorf("fetching OIDC signing keys: %w", err)
+	}
+
+	keys := make(map[string]any)
+	for _, k := range jwks.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				klog.Warningf("skipping malformed RSA key %q from %s", k.Kid, v.jwksURI)
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				klog.Warningf("skipping malformed EC key %q from %s", k.Kid, v.jwksURI)
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	v.keys = keys
+	return nil
+}
+
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
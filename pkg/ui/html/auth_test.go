@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	auth := &authenticator{token: "s3cret"}
+	handler := auth.middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		cookie     string
+		wantStatus int
+	}{
+		{name: "no credentials", path: "/api/sessions", wantStatus: http.StatusUnauthorized},
+		{name: "valid bearer", path: "/api/sessions", header: "Bearer s3cret", wantStatus: http.StatusOK},
+		{name: "invalid bearer", path: "/api/sessions", header: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "valid cookie", path: "/api/sessions", cookie: "s3cret", wantStatus: http.StatusOK},
+		{name: "token query sets cookie", path: "/?token=s3cret", wantStatus: http.StatusFound},
+		{name: "invalid token query", path: "/?token=nope", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: authCookieName, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareUserIdentity(t *testing.T) {
+	auth := &authenticator{userTokens: map[string]string{"a1": "alice", "b2": "bob"}}
+	var gotUser string
+	handler := auth.middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotUser = userFromContext(req.Context())
+	}))
+
+	for token, wantUser := range map[string]string{"a1": "alice", "b2": "bob"} {
+		gotUser = ""
+		req := httptest.NewRequest(http.MethodGet, "/api/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if gotUser != wantUser {
+			t.Errorf("token %q: got user %q, want %q", token, gotUser, wantUser)
+		}
+	}
+}
+
+func TestCrossOriginProtection(t *testing.T) {
+	handler := crossOriginProtection(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "GET is always allowed", method: http.MethodGet, headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, wantStatus: http.StatusOK},
+		{name: "non-browser POST", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "same-origin POST", method: http.MethodPost, headers: map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "http://example.com"}, wantStatus: http.StatusOK},
+		{name: "cross-site POST", method: http.MethodPost, headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, wantStatus: http.StatusForbidden},
+		{name: "mismatched origin DELETE", method: http.MethodDelete, headers: map[string]string{"Origin": "http://evil.test"}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://example.com/api/sessions", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/ui"
+	"k8s.io/klog/v2"
+)
+
+// Event types pushed to browser clients over SSE and WebSocket.
+const (
+	// eventSnapshot carries the full session state. Clients replace everything they have.
+	eventSnapshot = "snapshot"
+	// eventMessageAdded carries a message the client has not seen before.
+	eventMessageAdded = "message-added"
+	// eventMessageUpdated carries a new version of a previously sent message,
+	// e.g. model text that is still streaming in.
+	eventMessageUpdated = "message-updated"
+	// eventStateChanged carries a new agent state.
+	eventStateChanged = "state-changed"
+	// eventAgentStep carries a step of the agentic loop, e.g. an LLM call starting.
+	eventAgentStep = "agent-step"
+	// eventTyping tells the other clients of a session whether the user is typing in
+	// one of them. It is not part of the session state, so it has no sequence number.
+	eventTyping = "typing"
+)
+
+// sessionEvent is a single event pushed to browser clients.
+// Incremental events carry consecutive sequence numbers per session; a client
+// that observes a gap must resync from the state endpoint.
+type sessionEvent struct {
+	Type       string             `json:"type"`
+	SessionID  string             `json:"sessionId"`
+	Seq        uint64             `json:"seq"`
+	Message    *renderedMessage   `json:"message,omitempty"`
+	Messages   []*renderedMessage `json:"messages,omitempty"`
+	AgentState api.AgentState     `json:"agentState,omitempty"`
+	Step       *api.AgentEvent    `json:"step,omitempty"`
+	Typing     bool               `json:"typing,omitempty"`
+}
+
+// renderedMessage is a message with the blocks of the renderers of pkg/ui, which the
+// browser shows for the message types it has no dedicated view for.
+type renderedMessage struct {
+	*api.Message
+	Blocks []ui.Block `json:"Blocks,omitempty"`
+}
+
+func renderMessage(message *api.Message) *renderedMessage {
+	return &renderedMessage{Message: message, Blocks: ui.RenderMessage(message)}
+}
+
+// sessionEventStream tracks what has been sent for a session, so agent output
+// can be turned into incremental events.
+type sessionEventStream struct {
+	mu         sync.Mutex
+	seq        uint64
+	agentState api.AgentState
+	seen       map[string]bool // message ID -> already sent
+}
+
+func (u *HTMLUserInterface) eventStream(sessionID string) *sessionEventStream {
+	u.eventStreamsMu.Lock()
+	defer u.eventStreamsMu.Unlock()
+
+	s, ok := u.eventStreams[sessionID]
+	if !ok {
+		s = &sessionEventStream{seen: make(map[string]bool)}
+		u.eventStreams[sessionID] = s
+	}
+	return s
+}
+
+// isHiddenMessage reports whether a message is not shown in the browser.
+func isHiddenMessage(message *api.Message) bool {
+	return message.Type == api.MessageTypeUserInputRequest && message.Payload == ">>>"
+}
+
+// publishAgentOutput converts a message from the agent output channel into incremental
+// events for the session and publishes them.
+func (u *HTMLUserInterface) publishAgentOutput(session *api.Session, message *api.Message) {
+	stream := u.eventStream(session.ID)
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	var events []*sessionEvent
+	if message != nil && !isHiddenMessage(message) {
+		eventType := eventMessageAdded
+		if stream.seen[message.ID] {
+			eventType = eventMessageUpdated
+		}
+		stream.seen[message.ID] = true
+		events = append(events, &sessionEvent{Type: eventType, Message: renderMessage(message)})
+	}
+	if state := session.AgentState; state != stream.agentState {
+		stream.agentState = state
+		events = append(events, &sessionEvent{Type: eventStateChanged, AgentState: state})
+	}
+
+	u.publishEvents(session.ID, stream, events)
+}
+
+// publishAgentEvent publishes a step of the agentic loop of the session.
+func (u *HTMLUserInterface) publishAgentEvent(session *api.Session, event *api.AgentEvent) {
+	stream := u.eventStream(session.ID)
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	u.publishEvents(session.ID, stream, []*sessionEvent{{Type: eventAgentStep, Step: event}})
+}
+
+// publishEvents numbers the events of the session and publishes them; the caller holds stream.mu.
+func (u *HTMLUserInterface) publishEvents(sessionID string, stream *sessionEventStream, events []*sessionEvent) {
+	for _, event := range events {
+		stream.seq++
+		event.SessionID = sessionID
+		event.Seq = stream.seq
+		data, err := json.Marshal(event)
+		if err != nil {
+			klog.Errorf("Error marshaling %s event for broadcast: %v", event.Type, err)
+			continue
+		}
+		u.broadcaster.Publish(sessionID, data)
+	}
+}
+
+// handleGetSessionState returns a snapshot of the session, used by clients to resync
+// after missing incremental events.
+func (u *HTMLUserInterface) handleGetSessionState(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	id := req.PathValue("id")
+	if id == "" {
+		http.Error(w, "missing session id", http.StatusBadRequest)
+		return
+	}
+
+	agent, err := u.manager.GetAgent(ctx, id)
+	if err != nil {
+		log.Error(err, "getting agent for session")
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	data, err := u.getSessionStateJSON(agent.GetSession())
+	if err != nil {
+		log.Error(err, "getting session state")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/internal/mocks"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"go.uber.org/mock/gomock"
+)
+
+// newEventTestUI returns a UI that only publishes events, with its broadcaster running.
+func newEventTestUI(t *testing.T) *HTMLUserInterface {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	u := &HTMLUserInterface{broadcaster: NewBroadcaster(), eventStreams: make(map[string]*sessionEventStream)}
+	go u.broadcaster.Run(ctx)
+	return u
+}
+
+func receiveEvent(t *testing.T, client chan []byte) *sessionEvent {
+	t.Helper()
+	data, ok := receive(t, client)
+	if !ok {
+		t.Fatalf("client channel closed")
+	}
+	var event sessionEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("decoding event %s: %v", data, err)
+	}
+	return &event
+}
+
+func TestPublishEventsSequence(t *testing.T) {
+	u := newEventTestUI(t)
+	client := u.broadcaster.Subscribe("s1")
+	other := u.broadcaster.Subscribe("s2")
+
+	session := &api.Session{ID: "s1"}
+	message := &api.Message{ID: "m1", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "Hel"}
+	u.publishAgentOutput(session, message)
+	message.Payload = "Hello"
+	session.AgentState = api.AgentStateRunning
+	u.publishAgentOutput(session, message)
+	u.publishAgentEvent(session, &api.AgentEvent{Type: api.AgentEventLLMCallStarted})
+	u.publishAgentOutput(&api.Session{ID: "s2"}, &api.Message{ID: "m2", Type: api.MessageTypeText, Payload: "other"})
+
+	want := []struct {
+		eventType string
+		seq       uint64
+	}{
+		{eventMessageAdded, 1},
+		{eventMessageUpdated, 2},
+		{eventStateChanged, 3},
+		{eventAgentStep, 4},
+	}
+	for _, w := range want {
+		event := receiveEvent(t, client)
+		if event.Type != w.eventType || event.Seq != w.seq || event.SessionID != "s1" {
+			t.Errorf("event = %s #%d of %q, want %s #%d of s1", event.Type, event.Seq, event.SessionID, w.eventType, w.seq)
+		}
+	}
+	// Every session is numbered on its own
+	if event := receiveEvent(t, other); event.Seq != 1 {
+		t.Errorf("first event of another session has sequence number %d, want 1", event.Seq)
+	}
+}
+
+func TestPublishEventsGapAfterDrop(t *testing.T) {
+	u := newEventTestUI(t)
+	slow, fast := u.broadcaster.Subscribe("s"), u.broadcaster.Subscribe("s")
+
+	session := &api.Session{ID: "s"}
+	total := clientQueueSize + 10
+	for range total {
+		u.publishAgentEvent(session, &api.AgentEvent{Type: api.AgentEventLLMCallStarted})
+		receiveEvent(t, fast)
+	}
+	// Run handles the unsubscription after it delivered the last event to both clients
+	u.broadcaster.Unsubscribe("s", fast)
+
+	// A client tracking sequence numbers notices that the oldest events were dropped,
+	// while the ones it still gets are consecutive.
+	var seqs []uint64
+	for len(slow) > 0 {
+		seqs = append(seqs, receiveEvent(t, slow).Seq)
+	}
+	if len(seqs) != clientQueueSize {
+		t.Fatalf("slow client got %d events, want %d", len(seqs), clientQueueSize)
+	}
+	if seqs[0] == 1 {
+		t.Errorf("first event received has sequence number %d, want a gap after the dropped events", seqs[0])
+	}
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] != seqs[i-1]+1 {
+			t.Errorf("sequence numbers %d and %d are not consecutive", seqs[i-1], seqs[i])
+		}
+	}
+	if last := seqs[len(seqs)-1]; last != uint64(total) {
+		t.Errorf("last event has sequence number %d, want %d", last, total)
+	}
+}
+
+func TestGetSessionState(t *testing.T) {
+	u, server, sessionID := newTestServer(t, mocks.NewMockChat(gomock.NewController(t)))
+	session, err := u.manager.FindSessionByID(sessionID)
+	if err != nil {
+		t.Fatalf("finding session: %v", err)
+	}
+	for _, message := range []*api.Message{
+		{ID: "m1", Source: api.MessageSourceUser, Type: api.MessageTypeText, Payload: "hello"},
+		{ID: "m2", Source: api.MessageSourceAgent, Type: api.MessageTypeUserInputRequest, Payload: ">>>"},
+	} {
+		if err := session.ChatMessageStore.AddChatMessage(message); err != nil {
+			t.Fatalf("adding message: %v", err)
+		}
+	}
+	u.publishAgentEvent(session, &api.AgentEvent{Type: api.AgentEventLLMCallStarted})
+	u.publishAgentEvent(session, &api.AgentEvent{Type: api.AgentEventLLMCallFinished})
+
+	resp, err := http.Get(server.URL + "/api/sessions/" + sessionID + "/state")
+	if err != nil {
+		t.Fatalf("getting session state: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var snapshot sessionEvent
+	if err := json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		t.Fatalf("decoding session state: %v", err)
+	}
+	// The snapshot carries the sequence number of the last event, for clients to
+	// continue from; hidden messages are left out.
+	if snapshot.Type != eventSnapshot || snapshot.SessionID != sessionID || snapshot.Seq != 2 {
+		t.Errorf("snapshot = %s #%d of %q, want %s #2 of %q", snapshot.Type, snapshot.Seq, snapshot.SessionID, eventSnapshot, sessionID)
+	}
+	if len(snapshot.Messages) != 1 || snapshot.Messages[0].ID != "m1" {
+		t.Errorf("snapshot messages = %+v, want only m1", snapshot.Messages)
+	}
+
+	resp, err = http.Get(server.URL + "/api/sessions/unknown/state")
+	if err != nil {
+		t.Fatalf("getting session state: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status for an unknown session = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/ui"
+	"k8s.io/klog/v2"
+)
+
+// handleGetSessionTrace returns the journal events recorded for a session as a JSON SessionLog.
+func (u *HTMLUserInterface) handleGetSessionTrace(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	id := req.PathValue("id")
+	if id == "" {
+		http.Error(w, "missing session id", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := u.manager.FindSessionByID(id); err != nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	tracePath, ok := journal.RecordingPath(u.journal)
+	if !ok {
+		http.Error(w, "tracing to a file is not enabled (see --trace-path)", http.StatusNotFound)
+		return
+	}
+
+	sessionLog, err := journal.LoadSessionLog(tracePath, id)
+	if err != nil {
+		log.Error(err, "loading session trace")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "kubectl-ai-trace-"+id+".json"))
+	if err := json.NewEncoder(w).Encode(sessionLog); err != nil {
+		log.Error(err, "encoding session trace")
+	}
+}
+
+// handleExportSession returns the conversation of a session as a downloadable document.
+// Supported formats are markdown (the default) and json.
+func (u *HTMLUserInterface) handleExportSession(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	id := req.PathValue("id")
+	if id == "" {
+		http.Error(w, "missing session id", http.StatusBadRequest)
+		return
+	}
+
+	session, err := u.manager.FindSessionByID(id)
+	if err != nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	var messages []*api.Message
+	for _, message := range session.AllMessages() {
+		if isHiddenMessage(message) {
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "markdown", "md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "kubectl-ai-"+id+".md"))
+		fmt.Fprint(w, renderSessionMarkdown(session, messages))
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "kubectl-ai-"+id+".json"))
+		if err := json.NewEncoder(w).Encode(messages); err != nil {
+			log.Error(err, "encoding session export")
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unsupported format %q (supported: markdown, json)", format), http.StatusBadRequest)
+	}
+}
+
+// renderSessionMarkdown renders the conversation as a Markdown transcript.
+func renderSessionMarkdown(session *api.Session, messages []*api.Message) string {
+	var b strings.Builder
+
+	name := session.Name
+	if name == "" {
+		name = session.ID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	fmt.Fprintf(&b, "- Session: `%s`\n", session.ID)
+	if session.ProviderID != "" || session.ModelID != "" {
+		fmt.Fprintf(&b, "- Model: `%s/%s`\n", session.ProviderID, session.ModelID)
+	}
+	fmt.Fprintf(&b, "- Created: %s\n\n", session.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+
+	for _, message := range messages {
+		switch message.Type {
+		case api.MessageTypeText:
+			fmt.Fprintf(&b, "## %s\n\n%v\n\n", markdownSourceName(message.Source), message.Payload)
+		case api.MessageTypeThought:
+			fmt.Fprintf(&b, "<details>\n<summary>Thought</summary>\n\n%v\n\n</details>\n\n", message.Payload)
+		case api.MessageTypeError:
+			fmt.Fprintf(&b, "> **Error:** %s\n\n", api.ErrorPayloadFrom(message.Payload).Message)
+		case api.MessageTypeToolCallRequest:
+			fmt.Fprintf(&b, "**Running:**\n\n```shell\n%v\n```\n\n", message.Payload)
+		case api.MessageTypeToolCallResponse:
+			fmt.Fprintf(&b, "<details>\n<summary>Output</summary>\n\n```\n%s\n```\n\n</details>\n\n", markdownToolOutput(message.Payload))
+		case api.MessageTypeUserChoiceRequest:
+			var choice api.UserChoiceRequest
+			if decodePayload(message.Payload, &choice) == nil {
+				fmt.Fprintf(&b, "**Approval requested:**\n\n%s\n\n", choice.Prompt)
+			}
+		default:
+			// Diffs, plans and other messages, as rendered for every frontend
+			b.WriteString(ui.BlocksMarkdown(ui.RenderMessage(message)))
+		}
+	}
+
+	return b.String()
+}
+
+// decodePayload converts a message payload into out. Payloads are typed structs for live
+// sessions but generic maps for sessions loaded from disk, so go through JSON.
+func decodePayload(payload any, out any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func markdownSourceName(source api.MessageSource) string {
+	switch source {
+	case api.MessageSourceUser:
+		return "You"
+	case api.MessageSourceModel:
+		return "AI Assistant"
+	default:
+		return "kubectl-ai"
+	}
+}
+
+// markdownToolOutput extracts the most readable form of a tool result.
+func markdownToolOutput(payload any) string {
+	result, err := tools.ToolResultToMap(payload)
+	if err != nil {
+		return fmt.Sprintf("%v", payload)
+	}
+	if stdout, ok := result["stdout"].(string); ok {
+		if stderr, ok := result["stderr"].(string); ok && stderr != "" {
+			return stdout + "\n" + stderr
+		}
+		return stdout
+	}
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", payload)
+	}
+	return string(b)
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/internal/mocks"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"go.uber.org/mock/gomock"
+)
+
+// getExport exports the session with token, if any, and returns the response and its body.
+func getExport(t *testing.T, url, token string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("exporting session: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading export: %v", err)
+	}
+	return resp, string(body)
+}
+
+func TestExportSession(t *testing.T) {
+	u, server, sessionID := newTestServer(t, mocks.NewMockChat(gomock.NewController(t)))
+	session, err := u.manager.FindSessionByID(sessionID)
+	if err != nil {
+		t.Fatalf("finding session: %v", err)
+	}
+	for _, message := range []*api.Message{
+		{ID: "m1", Source: api.MessageSourceUser, Type: api.MessageTypeText, Payload: "list the pods"},
+		{ID: "m2", Source: api.MessageSourceAgent, Type: api.MessageTypeToolCallRequest, Payload: "kubectl get pods"},
+		{ID: "m3", Source: api.MessageSourceAgent, Type: api.MessageTypeToolCallResponse, Payload: map[string]any{"stdout": "web-0   Running"}},
+		{ID: "m4", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "One pod is running."},
+		{ID: "m5", Source: api.MessageSourceAgent, Type: api.MessageTypeUserInputRequest, Payload: ">>>"},
+	} {
+		if err := session.ChatMessageStore.AddChatMessage(message); err != nil {
+			t.Fatalf("adding message: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		format          string
+		wantStatus      int
+		wantContentType string
+		wantFilename    string
+		// wantBody are parts of the body, in order
+		wantBody []string
+	}{
+		{
+			name:            "default",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/markdown; charset=utf-8",
+			wantFilename:    "kubectl-ai-" + sessionID + ".md",
+			wantBody:        []string{"- Session: `" + sessionID + "`", "## You\n\nlist the pods", "```shell\nkubectl get pods\n```", "web-0   Running", "## AI Assistant\n\nOne pod is running."},
+		},
+		{
+			name:            "markdown",
+			format:          "markdown",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/markdown; charset=utf-8",
+			wantFilename:    "kubectl-ai-" + sessionID + ".md",
+			wantBody:        []string{"## You\n\nlist the pods", "## AI Assistant\n\nOne pod is running."},
+		},
+		{
+			name:            "json",
+			format:          "json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantFilename:    "kubectl-ai-" + sessionID + ".json",
+			wantBody:        []string{`"list the pods"`, `"kubectl get pods"`, `"One pod is running."`},
+		},
+		{
+			name:       "unsupported format",
+			format:     "xml",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   []string{`unsupported format "xml"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := server.URL + "/api/sessions/" + sessionID + "/export"
+			if tt.format != "" {
+				url += "?format=" + tt.format
+			}
+			resp, body := getExport(t, url, "")
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantContentType != "" {
+				if got := resp.Header.Get("Content-Type"); got != tt.wantContentType {
+					t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+				}
+				if got := resp.Header.Get("Content-Disposition"); !strings.Contains(got, `filename="`+tt.wantFilename+`"`) {
+					t.Errorf("Content-Disposition = %q, want the file name %q", got, tt.wantFilename)
+				}
+			}
+			rest := body
+			for _, want := range tt.wantBody {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("export does not contain %q after the previous parts:\n%s", want, body)
+				}
+				rest = rest[i+len(want):]
+			}
+			// The prompt for the next input is not part of the conversation
+			if strings.Contains(body, ">>>") {
+				t.Errorf("export contains the hidden input request:\n%s", body)
+			}
+		})
+	}
+
+	resp, body := getExport(t, server.URL+"/api/sessions/"+sessionID+"/export?format=json", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+	var messages []*api.Message
+	if err := json.Unmarshal([]byte(body), &messages); err != nil {
+		t.Fatalf("parsing the JSON export: %v", err)
+	}
+	if len(messages) != 4 || messages[0].ID != "m1" || messages[3].ID != "m4" {
+		t.Errorf("exported messages = %+v, want m1 to m4", messages)
+	}
+}
+
+func TestExportSessionNotFound(t *testing.T) {
+	_, server, _ := newTestServer(t, mocks.NewMockChat(gomock.NewController(t)))
+
+	for _, format := range []string{"markdown", "json"} {
+		resp, body := getExport(t, server.URL+"/api/sessions/no-such-session/export?format="+format, "")
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("exporting an unknown session as %s: status = %d, want %d: %s", format, resp.StatusCode, http.StatusNotFound, body)
+		}
+	}
+}
+
+func TestExportSessionMultiUser(t *testing.T) {
+	_, server, _ := newTestServer(t, mocks.NewMockChat(gomock.NewController(t)), WithUsers(map[string]string{
+		"alice-token": "alice",
+		"bob-token":   "bob",
+	}))
+	sessionManager, err := sessions.NewSessionManager("memory")
+	if err != nil {
+		t.Fatalf("creating session manager: %v", err)
+	}
+	session, err := sessionManager.NewSession(sessions.Metadata{ModelID: "test-model", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	if err := session.ChatMessageStore.AddChatMessage(&api.Message{ID: "m1", Source: api.MessageSourceUser, Type: api.MessageTypeText, Payload: "alice's secret plans"}); err != nil {
+		t.Fatalf("adding message: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "owner", token: "alice-token", wantStatus: http.StatusOK},
+		// Sessions of other users look like they do not exist
+		{name: "other user", token: "bob-token", wantStatus: http.StatusNotFound},
+		{name: "unauthenticated", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []string{"markdown", "json"} {
+				resp, body := getExport(t, server.URL+"/api/sessions/"+session.ID+"/export?format="+format, tt.token)
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("%s export: status = %d, want %d: %s", format, resp.StatusCode, tt.wantStatus, body)
+				}
+				if leaked := strings.Contains(body, "secret plans"); leaked != (tt.wantStatus == http.StatusOK) {
+					t.Errorf("%s export contains the conversation = %v, want %v", format, leaked, tt.wantStatus == http.StatusOK)
+				}
+			}
+		})
+	}
+}
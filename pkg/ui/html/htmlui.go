@@ -37,48 +37,84 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// topicMessage is a message published to the subscribers of a single topic.
+type topicMessage struct {
+	topic string
+	data  []byte
+	// except is a client not to send the message to, e.g. the one it comes from
+	except chan []byte
+}
+
+// subscription is a client channel registered for a topic.
+type subscription struct {
+	topic  string
+	client chan []byte
+}
+
 // Broadcaster manages a set of clients for Server-Sent Events.
+// Clients subscribe to a topic (the session ID) and only receive messages
+// published to that topic.
 type Broadcaster struct {
-	clients   map[chan []byte]bool
-	newClient chan chan []byte
-	delClient chan chan []byte
-	messages  chan []byte
+	clients   map[string]map[chan []byte]bool // topic -> clients
+	newClient chan subscription
+	delClient chan subscription
+	delTopic  chan string
+	messages  chan topicMessage
 	mu        sync.Mutex
+	// done is closed when Run returns, after which calls no longer wait for it
+	done chan struct{}
 }
 
 // NewBroadcaster creates a new Broadcaster instance.
 func NewBroadcaster() *Broadcaster {
 	b := &Broadcaster{
-		clients:   make(map[chan []byte]bool),
-		newClient: make(chan (chan []byte)),
-		delClient: make(chan (chan []byte)),
-		messages:  make(chan []byte, 10),
+		clients:   make(map[string]map[chan []byte]bool),
+		newClient: make(chan subscription),
+		delClient: make(chan subscription),
+		delTopic:  make(chan string),
+		messages:  make(chan topicMessage, 10),
+		done:      make(chan struct{}),
 	}
 	return b
 }
 
-// Run starts the broadcaster's event loop.
+// Run starts the broadcaster's event loop, until ctx is done.
 func (b *Broadcaster) Run(ctx context.Context) {
+	defer close(b.done)
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case client := <-b.newClient:
+		case sub := <-b.newClient:
 			b.mu.Lock()
-			b.clients[client] = true
+			if b.clients[sub.topic] == nil {
+				b.clients[sub.topic] = make(map[chan []byte]bool)
+			}
+			b.clients[sub.topic][sub.client] = true
+			b.mu.Unlock()
+		case sub := <-b.delClient:
+			b.mu.Lock()
+			// The client may already be gone if its topic was closed.
+			if clients, ok := b.clients[sub.topic]; ok && clients[sub.client] {
+				delete(clients, sub.client)
+				if len(clients) == 0 {
+					delete(b.clients, sub.topic)
+				}
+				close(sub.client)
+			}
 			b.mu.Unlock()
-		case client := <-b.delClient:
+		case topic := <-b.delTopic:
 			b.mu.Lock()
-			delete(b.clients, client)
-			close(client)
+			for client := range b.clients[topic] {
+				close(client)
+			}
+			delete(b.clients, topic)
 			b.mu.Unlock()
 		case msg := <-b.messages:
 			b.mu.Lock()
-			for client := range b.clients {
-				select {
-				case client <- msg:
-				default:
-					klog.Warning("SSE client buffer full, dropping message.")
+			for client := range b.clients[msg.topic] {
+				if client != msg.except {
+					deliverDropOldest(client, msg.data, msg.topic)
 				}
 			}
 			b.mu.Unlock()
@@ -86,9 +122,68 @@ func (b *Broadcaster) Run(ctx context.Context) {
 	}
 }
 
-// Broadcast sends a message to all connected clients.
-func (b *Broadcaster) Broadcast(msg []byte) {
-	b.messages <- msg
+// clientQueueSize is the number of events queued for each client. The events of a client
+// that falls further behind are dropped, oldest first; clients notice the gap in sequence
+// numbers and resync.
+const clientQueueSize = 64
+
+// deliverDropOldest queues data for a client without blocking, making room by dropping
+// the oldest queued event if the client is not keeping up.
+func deliverDropOldest(client chan []byte, data []byte, topic string) {
+	for {
+		select {
+		case client <- data:
+			return
+		default:
+		}
+		select {
+		case <-client:
+			klog.Warningf("Client of session %s is not keeping up, dropped its oldest event.", topic)
+		default:
+		}
+	}
+}
+
+// Subscribe registers a new client for the given topic and returns the
+// channel on which it will receive messages. Once the broadcaster is stopped,
+// the channel is closed right away.
+func (b *Broadcaster) Subscribe(topic string) chan []byte {
+	client := make(chan []byte, clientQueueSize)
+	select {
+	case b.newClient <- subscription{topic: topic, client: client}:
+	case <-b.done:
+		close(client)
+	}
+	return client
+}
+
+// Unsubscribe removes a client previously returned by Subscribe and closes its channel.
+func (b *Broadcaster) Unsubscribe(topic string, client chan []byte) {
+	select {
+	case b.delClient <- subscription{topic: topic, client: client}:
+	case <-b.done:
+	}
+}
+
+// CloseTopic disconnects all clients subscribed to the given topic by closing their channels.
+func (b *Broadcaster) CloseTopic(topic string) {
+	select {
+	case b.delTopic <- topic:
+	case <-b.done:
+	}
+}
+
+// Publish sends a message to all clients subscribed to the given topic.
+func (b *Broadcaster) Publish(topic string, msg []byte) {
+	b.PublishExcept(topic, msg, nil)
+}
+
+// PublishExcept sends a message to the clients subscribed to the given topic but except.
+func (b *Broadcaster) PublishExcept(topic string, msg []byte, except chan []byte) {
+	select {
+	case b.messages <- topicMessage{topic: topic, data: msg, except: except}:
+	case <-b.done:
+	}
 }
 
 type HTMLUserInterface struct {
@@ -102,26 +197,38 @@ type HTMLUserInterface struct {
 	defaultProvider string
 
 	markdownRenderer *glamour.TermRenderer
-	broadcasters     map[string]*Broadcaster
-	broadcastersMu   sync.Mutex
+	// broadcaster fans out session events to SSE clients, using the session ID as topic.
+	broadcaster *Broadcaster
+
+	// eventStreams tracks the incremental event sequence of each session
+	eventStreams   map[string]*sessionEventStream
+	eventStreamsMu sync.Mutex
 
-	broadcasterCancels map[string]context.CancelFunc
-	baseCtx            context.Context
+	options ServerOptions
 }
 
 var _ ui.UI = &HTMLUserInterface{}
 
-func NewHTMLUserInterface(manager *agent.AgentManager, sessionManager *sessions.SessionManager, defaultModel, defaultProvider string, listenAddress string, journal journal.Recorder) (*HTMLUserInterface, error) {
+func NewHTMLUserInterface(manager *agent.AgentManager, sessionManager *sessions.SessionManager, defaultModel, defaultProvider string, listenAddress string, journal journal.Recorder, opts ...Option) (*HTMLUserInterface, error) {
 	mux := http.NewServeMux()
 
+	var options ServerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
 	u := &HTMLUserInterface{
-		manager:            manager,
-		sessionManager:     sessionManager,
-		defaultModel:       defaultModel,
-		defaultProvider:    defaultProvider,
-		journal:            journal,
-		broadcasters:       make(map[string]*Broadcaster),
-		broadcasterCancels: make(map[string]context.CancelFunc),
+		manager:         manager,
+		sessionManager:  sessionManager,
+		defaultModel:    defaultModel,
+		defaultProvider: defaultProvider,
+		journal:         journal,
+		broadcaster:     NewBroadcaster(),
+		eventStreams:    make(map[string]*sessionEventStream),
+		options:         options,
 	}
 
 	// Register callback to listen to new agents
@@ -129,19 +236,40 @@ func NewHTMLUserInterface(manager *agent.AgentManager, sessionManager *sessions.
 		u.ensureAgentListener(a)
 	})
 
+	handler := crossOriginProtection(mux)
+	if options.authEnabled() {
+		auth, err := newAuthenticator(context.Background(), &options)
+		if err != nil {
+			return nil, err
+		}
+		handler = auth.middleware(handler)
+	} else if !isLoopbackAddress(listenAddress) {
+		klog.Warningf("HTML UI is listening on non-loopback address %q without authentication", listenAddress)
+		fmt.Fprintf(os.Stderr, "warning: the web UI on %s is reachable by anyone on the network; consider --ui-auth-token\n", listenAddress)
+	}
+
 	httpServer := &http.Server{
 		Addr:    listenAddress,
-		Handler: mux,
+		Handler: handler,
 	}
 
 	mux.HandleFunc("GET /", u.serveIndex)
+	mux.HandleFunc("GET /api/kubeconfigs", u.handleListKubeconfigs)
 	mux.HandleFunc("GET /api/sessions", u.handleListSessions)
 	mux.HandleFunc("POST /api/sessions", u.handleCreateSession)
-	mux.HandleFunc("POST /api/sessions/{id}/rename", u.handleRenameSession)
-	mux.HandleFunc("DELETE /api/sessions/{id}", u.handleDeleteSession)
-	mux.HandleFunc("GET /api/sessions/{id}/stream", u.handleSessionStream)
-	mux.HandleFunc("POST /api/sessions/{id}/send-message", u.handlePOSTSendMessage)
-	mux.HandleFunc("POST /api/sessions/{id}/choose-option", u.handlePOSTChooseOption)
+	mux.HandleFunc("GET /api/tools", u.handleListTools)
+	mux.HandleFunc("POST /api/sessions/{id}/rename", u.requireSessionAccess(u.handleRenameSession))
+	mux.HandleFunc("DELETE /api/sessions/{id}", u.requireSessionAccess(u.handleDeleteSession))
+	mux.HandleFunc("GET /api/sessions/{id}/state", u.requireSessionAccess(u.handleGetSessionState))
+	mux.HandleFunc("GET /api/sessions/{id}/stream", u.requireSessionAccess(u.handleSessionStream))
+	mux.HandleFunc("GET /api/sessions/{id}/trace", u.requireSessionAccess(u.handleGetSessionTrace))
+	mux.HandleFunc("GET /api/sessions/{id}/export", u.requireSessionAccess(u.handleExportSession))
+	mux.HandleFunc("GET /api/sessions/{id}/ws", u.requireSessionAccess(u.handleSessionWebSocket))
+	mux.HandleFunc("POST /api/sessions/{id}/send-message", u.requireSessionAccess(u.handlePOSTSendMessage))
+	mux.HandleFunc("POST /api/sessions/{id}/messages/{messageID}/edit", u.requireSessionAccess(u.handlePOSTEditMessage))
+	mux.HandleFunc("POST /api/sessions/{id}/choose-option", u.requireSessionAccess(u.handlePOSTChooseOption))
+	mux.HandleFunc("POST /api/sessions/{id}/cancel", u.requireSessionAccess(u.handlePOSTCancel))
+	mux.HandleFunc("POST /api/sessions/{id}/suggest", u.requireSessionAccess(u.handlePOSTSuggest))
 
 	httpServerListener, err := net.Listen("tcp", listenAddress)
 	if err != nil {
@@ -151,7 +279,11 @@ func NewHTMLUserInterface(manager *agent.AgentManager, sessionManager *sessions.
 	u.httpServerListener = httpServerListener
 	u.httpServer = httpServer
 
-	fmt.Fprintf(os.Stdout, "listening on http://%s\n", endpoint)
+	scheme := "http"
+	if options.tlsEnabled() {
+		scheme = "https"
+	}
+	fmt.Fprintf(os.Stdout, "listening on %s://%s\n", scheme, endpoint)
 
 	mdRenderer, err := glamour.NewTermRenderer(
 		glamour.WithAutoStyle(),
@@ -169,10 +301,19 @@ func NewHTMLUserInterface(manager *agent.AgentManager, sessionManager *sessions.
 func (u *HTMLUserInterface) Run(ctx context.Context) error {
 	g, gctx := errgroup.WithContext(ctx)
 
-	u.baseCtx = gctx
+	g.Go(func() error {
+		u.broadcaster.Run(gctx)
+		return nil
+	})
 
 	g.Go(func() error {
-		if err := u.httpServer.Serve(u.httpServerListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var err error
+		if u.options.tlsEnabled() {
+			err = u.httpServer.ServeTLS(u.httpServerListener, u.options.TLSCertFile, u.options.TLSKeyFile)
+		} else {
+			err = u.httpServer.Serve(u.httpServerListener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return fmt.Errorf("error running http server: %w", err)
 		}
 		return nil
@@ -219,12 +360,8 @@ func (u *HTMLUserInterface) handleSessionStream(w http.ResponseWriter, req *http
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
-	clientChan := make(chan []byte, 10)
-	broadcaster := u.getBroadcaster(id)
-	broadcaster.newClient <- clientChan
-	defer func() {
-		broadcaster.delClient <- clientChan
-	}()
+	clientChan := u.broadcaster.Subscribe(id)
+	defer u.broadcaster.Unsubscribe(id, clientChan)
 
 	log.Info("SSE client connected", "sessionID", id)
 
@@ -233,7 +370,7 @@ func (u *HTMLUserInterface) handleSessionStream(w http.ResponseWriter, req *http
 	if err != nil {
 		log.Error(err, "getting agent for session")
 	} else {
-		initialData, err = u.getSessionStateJSON(agent.Session)
+		initialData, err = u.getSessionStateJSON(agent.GetSession())
 	}
 
 	if err != nil {
@@ -248,7 +385,11 @@ func (u *HTMLUserInterface) handleSessionStream(w http.ResponseWriter, req *http
 		case <-ctx.Done():
 			log.Info("SSE client disconnected")
 			return
-		case msg := <-clientChan:
+		case msg, ok := <-clientChan:
+			if !ok {
+				log.Info("SSE topic closed", "sessionID", id)
+				return
+			}
 			fmt.Fprintf(w, "data: %s\n\n", msg)
 			flusher.Flush()
 		}
@@ -259,13 +400,20 @@ func (u *HTMLUserInterface) handleListSessions(w http.ResponseWriter, req *http.
 	ctx := req.Context()
 	log := klog.FromContext(ctx)
 
-	sessionsList, err := u.manager.ListSessions()
+	allSessions, err := u.manager.ListSessions()
 	if err != nil {
 		log.Error(err, "listing sessions")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	sessionsList := make([]*api.Session, 0, len(allSessions))
+	for _, session := range allSessions {
+		if u.canAccessSession(ctx, session) {
+			sessionsList = append(sessionsList, session)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(sessionsList); err != nil {
 		log.Error(err, "encoding sessions list")
@@ -276,9 +424,28 @@ func (u *HTMLUserInterface) handleCreateSession(w http.ResponseWriter, req *http
 	ctx := req.Context()
 	log := klog.FromContext(ctx)
 
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := userFromContext(ctx)
+	if u.options.MultiUser && user == "" {
+		http.Error(w, "sessions can only be created by identified users", http.StatusForbidden)
+		return
+	}
+
+	kubeconfig, err := u.resolveUserKubeconfig(user, req.FormValue("kubeconfig"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	meta := sessions.Metadata{
 		ModelID:    u.defaultModel,
 		ProviderID: u.defaultProvider,
+		Owner:      user,
+		Kubeconfig: kubeconfig,
 	}
 
 	session, err := u.sessionManager.NewSession(meta)
@@ -334,8 +501,8 @@ func (u *HTMLUserInterface) handleRenameSession(w http.ResponseWriter, req *http
 	if agent, err := u.manager.GetAgent(ctx, id); err == nil {
 		agent.Session.Name = newName
 		// Broadcast update
-		if data, err := u.getSessionStateJSON(agent.Session); err == nil {
-			u.getBroadcaster(id).Broadcast(data)
+		if data, err := u.getSessionStateJSON(agent.GetSession()); err == nil {
+			u.broadcaster.Publish(id, data)
 		}
 	}
 
@@ -359,14 +526,11 @@ func (u *HTMLUserInterface) handleDeleteSession(w http.ResponseWriter, req *http
 	}
 
 	// If anyone was listening to this session, they should know it's gone.
-	// We can close the broadcaster.
-	u.broadcastersMu.Lock()
-	if cancel, ok := u.broadcasterCancels[id]; ok {
-		cancel()
-		delete(u.broadcasterCancels, id)
-	}
-	delete(u.broadcasters, id)
-	u.broadcastersMu.Unlock()
+	u.broadcaster.CloseTopic(id)
+
+	u.eventStreamsMu.Lock()
+	delete(u.eventStreams, id)
+	u.eventStreamsMu.Unlock()
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -401,8 +565,52 @@ func (u *HTMLUserInterface) handlePOSTSendMessage(w http.ResponseWriter, req *ht
 		return
 	}
 
-	// Send the message to the agent
-	agent.Input <- &api.UserInputResponse{Query: q}
+	// Send the message to the agent, with the IDs of the messages it quotes, if any
+	agent.Input <- &api.UserInputResponse{Query: q, QuotedMessageIDs: req.Form["quote"]}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handlePOSTEditMessage replaces an earlier message of the user by the query q, and runs
+// the agent again from there. The conversation before the edit is kept in a new session.
+func (u *HTMLUserInterface) handlePOSTEditMessage(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	id := req.PathValue("id")
+	if id == "" {
+		http.Error(w, "missing session id", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.ParseForm(); err != nil {
+		log.Error(err, "parsing form")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	q := req.FormValue("q")
+	if q == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+
+	a, err := u.manager.GetAgent(ctx, id)
+	if err != nil {
+		log.Error(err, "getting agent")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := a.EditMessage(req.PathValue("messageID"), q); err != nil {
+		switch {
+		case errors.Is(err, agent.ErrAgentBusy):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -448,6 +656,30 @@ func (u *HTMLUserInterface) handlePOSTChooseOption(w http.ResponseWriter, req *h
 	w.WriteHeader(http.StatusOK)
 }
 
+// handlePOSTCancel cancels the request the agent is currently working on, if any.
+func (u *HTMLUserInterface) handlePOSTCancel(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	id := req.PathValue("id")
+	if id == "" {
+		http.Error(w, "missing session id", http.StatusBadRequest)
+		return
+	}
+
+	agent, err := u.manager.GetAgent(ctx, id)
+	if err != nil {
+		http.Error(w, "agent not found", http.StatusNotFound)
+		return
+	}
+
+	if !agent.CancelRequest() {
+		http.Error(w, "no request in progress", http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (u *HTMLUserInterface) Close() error {
 	var errs []error
 	if u.httpServerListener != nil {
@@ -458,14 +690,6 @@ func (u *HTMLUserInterface) Close() error {
 		}
 	}
 
-	u.broadcastersMu.Lock()
-	for id, cancel := range u.broadcasterCancels {
-		cancel()
-		delete(u.broadcasterCancels, id)
-	}
-	u.broadcasters = make(map[string]*Broadcaster)
-	u.broadcastersMu.Unlock()
-
 	return errors.Join(errs...)
 }
 
@@ -473,68 +697,57 @@ func (u *HTMLUserInterface) ClearScreen() {
 	// Not applicable for HTML UI
 }
 
+// getSessionStateJSON returns a snapshot event with the full state of the session.
 func (u *HTMLUserInterface) getSessionStateJSON(session *api.Session) ([]byte, error) {
+	stream := u.eventStream(session.ID)
+
+	// Hold the stream lock so the snapshot and its sequence number are consistent.
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
 	allMessages := session.AllMessages()
 	// Create a copy of the messages to avoid race conditions
-	var messages []*api.Message
+	var messages []*renderedMessage
 	for _, message := range allMessages {
-		if message.Type == api.MessageTypeUserInputRequest && message.Payload == ">>>" {
+		stream.seen[message.ID] = true
+		if isHiddenMessage(message) {
 			continue
 		}
-		messages = append(messages, message)
+		messages = append(messages, renderMessage(message))
 	}
 
-	agentState := session.AgentState
-
-	data := map[string]interface{}{
-		"messages":   messages,
-		"agentState": agentState,
-		"sessionId":  session.ID,
-	}
-	return json.Marshal(data)
-}
-
-func (u *HTMLUserInterface) getBroadcaster(sessionID string) *Broadcaster {
-	u.broadcastersMu.Lock()
-	defer u.broadcastersMu.Unlock()
-
-	if b, ok := u.broadcasters[sessionID]; ok {
-		return b
-	}
-
-	b := NewBroadcaster()
-	u.broadcasters[sessionID] = b
-
-	parent := u.baseCtx
-	if parent == nil {
-		parent = context.Background()
-	}
-	ctx, cancel := context.WithCancel(parent)
-	u.broadcasterCancels[sessionID] = cancel
-
-	// Start the broadcaster loop
-	go b.Run(ctx)
-
-	return b
+	return json.Marshal(&sessionEvent{
+		Type:       eventSnapshot,
+		SessionID:  session.ID,
+		Seq:        stream.seq,
+		Messages:   messages,
+		AgentState: session.AgentState,
+	})
 }
 
 func (u *HTMLUserInterface) ensureAgentListener(a *agent.Agent) {
-	// Start a goroutine to listen to this agent's output
+	// Start a goroutine to listen to this agent's output until the agent
+	// is closed (deleted or evicted for being idle).
 	go func() {
-		for range a.Output {
-			// Broadcast state
-			if a.Session == nil {
-				continue
-			}
-
-			data, err := u.getSessionStateJSON(a.Session)
-			if err != nil {
-				klog.Errorf("Error marshaling state for broadcast: %v", err)
-				continue
+		for {
+			select {
+			case <-a.Done():
+				return
+			case output, ok := <-a.Output:
+				if !ok {
+					return
+				}
+				if a.Session == nil {
+					continue
+				}
+				message, _ := output.(*api.Message)
+				u.publishAgentOutput(a.GetSession(), message)
+			case event := <-a.Events:
+				if a.Session == nil {
+					continue
+				}
+				u.publishAgentEvent(a.Session, event)
 			}
-
-			b := u.getBroadcaster(a.Session.ID)
-			b.Broadcast(data)
 		}
 	}()
 }
@@ -0,0 +1,178 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/internal/mocks"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"go.uber.org/mock/gomock"
+)
+
+// receive returns the next event of client, or fails the test after a while.
+func receive(t *testing.T, client chan []byte) (data []byte, ok bool) {
+	t.Helper()
+	select {
+	case data, ok = <-client:
+		return data, ok
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event received")
+		return nil, false
+	}
+}
+
+func TestBroadcasterTopics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewBroadcaster()
+	go b.Run(ctx)
+
+	a1, a2, other := b.Subscribe("a"), b.Subscribe("a"), b.Subscribe("b")
+	b.Publish("a", []byte("hello"))
+	for _, client := range []chan []byte{a1, a2} {
+		if data, _ := receive(t, client); string(data) != "hello" {
+			t.Errorf("received %q, want the event published to the topic", data)
+		}
+	}
+
+	b.Unsubscribe("a", a2)
+	if _, ok := receive(t, a2); ok {
+		t.Errorf("the channel of an unsubscribed client is not closed")
+	}
+
+	b.CloseTopic("a")
+	if _, ok := receive(t, a1); ok {
+		t.Errorf("the channel of a client of a closed topic is not closed")
+	}
+	// Unsubscribing after the topic was closed is harmless
+	b.Unsubscribe("a", a1)
+
+	b.Publish("b", []byte("other"))
+	if data, _ := receive(t, other); string(data) != "other" {
+		t.Errorf("received %q, want only the events of its own topic", data)
+	}
+}
+
+func TestBroadcasterSlowClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewBroadcaster()
+	go b.Run(ctx)
+
+	slow, fast := b.Subscribe("s"), b.Subscribe("s")
+	for i := range 2 * clientQueueSize {
+		b.Publish("s", []byte(fmt.Sprint(i)))
+		// The fast client keeps up while the slow one reads nothing
+		if data, _ := receive(t, fast); string(data) != fmt.Sprint(i) {
+			t.Fatalf("fast client received %q, want %d", data, i)
+		}
+	}
+	// Run handles the unsubscription after it delivered the last event to both clients
+	b.Unsubscribe("s", fast)
+
+	if len(slow) != clientQueueSize {
+		t.Fatalf("slow client has %d queued events, want %d", len(slow), clientQueueSize)
+	}
+	if data, _ := receive(t, slow); string(data) != fmt.Sprint(clientQueueSize) {
+		t.Errorf("oldest queued event of the slow client = %q, want the oldest ones dropped", data)
+	}
+}
+
+func TestBroadcasterStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := NewBroadcaster()
+	stopped := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(stopped)
+	}()
+	client := b.Subscribe("a")
+	cancel()
+	<-stopped
+
+	done := make(chan struct{})
+	go func() {
+		b.Publish("a", []byte("late"))
+		b.Unsubscribe("a", client)
+		b.CloseTopic("a")
+		if _, ok := <-b.Subscribe("a"); ok {
+			t.Errorf("subscribing to a stopped broadcaster returned an open channel")
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("calls blocked after the broadcaster stopped")
+	}
+}
+
+func TestCancelEndpoint(t *testing.T) {
+	chat := mocks.NewMockChat(gomock.NewController(t))
+	// The model takes until the request is cancelled
+	chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, contents ...any) (gollm.ChatResponseIterator, error) {
+			<-ctx.Done()
+			return nil, context.Cause(ctx)
+		})
+	_, server, sessionID := newTestServer(t, chat)
+	conn := dialSession(t, server, sessionID)
+
+	cancel := func(sessionID string) int {
+		t.Helper()
+		resp, err := http.Post(server.URL+"/api/sessions/"+sessionID+"/cancel", "", nil)
+		if err != nil {
+			t.Fatalf("posting cancel: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := cancel(sessionID); status != http.StatusConflict {
+		t.Errorf("cancelling without a request in progress: status = %d, want %d", status, http.StatusConflict)
+	}
+
+	resp, err := http.PostForm(server.URL+"/api/sessions/"+sessionID+"/send-message", url.Values{"q": {"list pods"}})
+	if err != nil {
+		t.Fatalf("sending message: %v", err)
+	}
+	resp.Body.Close()
+	waitForEvent(t, conn, func(event *wsEvent) bool {
+		return event.Type == eventAgentStep && event.Step.Type == api.AgentEventLLMCallStarted
+	})
+
+	if status := cancel(sessionID); status != http.StatusOK {
+		t.Errorf("cancelling a request in progress: status = %d, want %d", status, http.StatusOK)
+	}
+	waitForEvent(t, conn, func(event *wsEvent) bool {
+		return isMessage(api.MessageTypeError)(event) && strings.Contains(api.ErrorPayloadFrom(event.Message.Payload).Message, agent.ErrRequestCancelled.Error())
+	})
+	waitForEvent(t, conn, func(event *wsEvent) bool {
+		return event.Type == eventStateChanged && event.AgentState != api.AgentStateRunning
+	})
+
+	if status := cancel("unknown"); status != http.StatusNotFound {
+		t.Errorf("cancelling in an unknown session: status = %d, want %d", status, http.StatusNotFound)
+	}
+}
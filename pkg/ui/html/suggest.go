@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// suggestTimeout bounds the LLM call of a command suggestion: a late suggestion is useless,
+// the user has typed on.
+const suggestTimeout = 10 * time.Second
+
+// handlePOSTSuggest completes the kubectl command the user is typing, given by the partial
+// form value, in the context of the session. It returns the complete command as suggestion,
+// empty when there is none, and the UI shows what it adds as ghost text.
+func (u *HTMLUserInterface) handlePOSTSuggest(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	partial := req.FormValue("partial")
+
+	agent, err := u.manager.GetAgent(ctx, req.PathValue("id"))
+	if err != nil {
+		log.Error(err, "getting agent")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, suggestTimeout)
+	defer cancel()
+	suggestion, err := agent.SuggestCommand(ctx, partial)
+	if err != nil {
+		log.Error(err, "suggesting command")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"suggestion": suggestion}); err != nil {
+		log.Error(err, "encoding suggestion")
+	}
+}
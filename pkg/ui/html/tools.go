@@ -0,0 +1,58 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+	"k8s.io/klog/v2"
+)
+
+// handleListTools returns the catalog of the tools of a session, given by the session query
+// parameter: their names, descriptions, parameter schemas and safety classification.
+// Sessions have their own tools, e.g. of MCP servers or of attached bundles.
+func (u *HTMLUserInterface) handleListTools(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	id := req.URL.Query().Get("session")
+	if id == "" {
+		http.Error(w, "missing session", http.StatusBadRequest)
+		return
+	}
+	session, err := u.manager.FindSessionByID(id)
+	if err != nil || !u.canAccessSession(ctx, session) {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	agent, err := u.manager.GetAgent(ctx, id)
+	if err != nil {
+		log.Error(err, "getting agent")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	catalog := agent.Tools.Catalog()
+	if catalog == nil {
+		catalog = []tools.ToolInfo{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"tools": catalog}); err != nil {
+		log.Error(err, "encoding tools")
+	}
+}
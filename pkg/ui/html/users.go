@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"k8s.io/klog/v2"
+)
+
+// canAccessSession reports whether the user of ctx may see the session.
+// Without multi-user mode every authenticated client sees every session.
+func (u *HTMLUserInterface) canAccessSession(ctx context.Context, session *api.Session) bool {
+	if !u.options.MultiUser {
+		return true
+	}
+	user := userFromContext(ctx)
+	return user != "" && session.Owner == user
+}
+
+// requireSessionAccess wraps a handler for a /api/sessions/{id}/... route so that
+// sessions owned by other users look like they do not exist.
+func (u *HTMLUserInterface) requireSessionAccess(next http.HandlerFunc) http.HandlerFunc {
+	if !u.options.MultiUser {
+		return next
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		session, err := u.manager.FindSessionByID(req.PathValue("id"))
+		if err != nil || !u.canAccessSession(req.Context(), session) {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		next(w, req)
+	}
+}
+
+// userKubeconfigs returns the names of the kubeconfig files the user may choose from.
+func (u *HTMLUserInterface) userKubeconfigs(user string) ([]string, error) {
+	if u.options.KubeconfigDir == "" || user == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(u.options.KubeconfigDir, user))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading kubeconfig directory for user %q: %w", user, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// resolveUserKubeconfig returns the path of the named kubeconfig of the user.
+// An empty name selects the server's default kubeconfig.
+func (u *HTMLUserInterface) resolveUserKubeconfig(user, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	names, err := u.userKubeconfigs(user)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range names {
+		if n == name {
+			return filepath.Join(u.options.KubeconfigDir, user, name), nil
+		}
+	}
+	return "", fmt.Errorf("kubeconfig %q is not available", name)
+}
+
+// handleListKubeconfigs returns the kubeconfigs the current user can pick for new sessions.
+func (u *HTMLUserInterface) handleListKubeconfigs(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	names, err := u.userKubeconfigs(userFromContext(ctx))
+	if err != nil {
+		log.Error(err, "listing kubeconfigs")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if names == nil {
+		names = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"user":        userFromContext(ctx),
+		"kubeconfigs": names,
+	}); err != nil {
+		log.Error(err, "encoding kubeconfigs")
+	}
+}
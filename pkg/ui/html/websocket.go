@@ -0,0 +1,201 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// wsWriteTimeout bounds how long a single frame write may take.
+	wsWriteTimeout = 10 * time.Second
+	// wsPingInterval is how often the server pings idle clients to keep the connection alive.
+	wsPingInterval = 30 * time.Second
+	// wsMaxMessageSize is the largest client frame we accept.
+	wsMaxMessageSize = 1 << 20
+)
+
+// Client-to-server WebSocket command types.
+const (
+	wsCommandSendMessage  = "send-message"
+	wsCommandChooseOption = "choose-option"
+	wsCommandCancel       = "cancel"
+	wsCommandTyping       = "typing"
+)
+
+// wsCommand is a command sent by the browser over the WebSocket.
+type wsCommand struct {
+	Type   string `json:"type"`
+	Query  string `json:"query,omitempty"`
+	Choice int    `json:"choice,omitempty"`
+	// Quotes are the IDs of the earlier messages quoted in the query.
+	Quotes []string `json:"quotes,omitempty"`
+	// Typing is whether the user is typing, for typing commands.
+	Typing bool `json:"typing,omitempty"`
+}
+
+// The default origin check rejects cross-origin upgrades, which is what we want.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+// handleSessionWebSocket serves a bidirectional WebSocket for a session.
+// Server-to-client frames carry the same JSON payloads as the SSE stream;
+// client-to-server frames are wsCommand values.
+func (u *HTMLUserInterface) handleSessionWebSocket(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := klog.FromContext(ctx)
+
+	id := req.PathValue("id")
+	if id == "" {
+		http.Error(w, "missing session id", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := u.manager.FindSessionByID(id); err != nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, req, nil)
+	if err != nil {
+		// Upgrade has already written an error response
+		log.Error(err, "upgrading to websocket")
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(wsMaxMessageSize)
+
+	clientChan := u.broadcaster.Subscribe(id)
+	defer u.broadcaster.Unsubscribe(id, clientChan)
+
+	log.Info("WebSocket client connected", "sessionID", id)
+
+	// Errors from handling commands are reported back to the client by the writer loop,
+	// since a websocket connection supports only one concurrent writer.
+	replies := make(chan []byte, 10)
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			var cmd wsCommand
+			if err := conn.ReadJSON(&cmd); err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+					log.Error(err, "reading websocket command")
+				}
+				return
+			}
+			if err := u.handleWebSocketCommand(req, id, clientChan, &cmd); err != nil {
+				data, _ := json.Marshal(map[string]string{"error": err.Error(), "sessionId": id})
+				select {
+				case replies <- data:
+				default:
+				}
+			}
+		}
+	}()
+
+	write := func(data []byte) error {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, data)
+	}
+
+	if agent, err := u.manager.GetAgent(ctx, id); err != nil {
+		log.Error(err, "getting agent for session")
+	} else if initialData, err := u.getSessionStateJSON(agent.GetSession()); err != nil {
+		log.Error(err, "getting initial state for websocket client")
+	} else if err := write(initialData); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-readerDone:
+			log.Info("WebSocket client disconnected", "sessionID", id)
+			return
+		case msg, ok := <-clientChan:
+			if !ok {
+				// Session was deleted
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session closed"), time.Now().Add(wsWriteTimeout))
+				return
+			}
+			if err := write(msg); err != nil {
+				return
+			}
+		case reply := <-replies:
+			if err := write(reply); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleWebSocketCommand handles a command of the client subscribed with client.
+func (u *HTMLUserInterface) handleWebSocketCommand(req *http.Request, sessionID string, client chan []byte, cmd *wsCommand) error {
+	switch cmd.Type {
+	case wsCommandTyping:
+		// Typing notifications carry no state for the agent; they are only relayed to the
+		// other clients of the session.
+		data, err := json.Marshal(&sessionEvent{Type: eventTyping, SessionID: sessionID, Typing: cmd.Typing})
+		if err != nil {
+			return fmt.Errorf("marshaling typing event: %w", err)
+		}
+		u.broadcaster.PublishExcept(sessionID, data, client)
+		return nil
+	case wsCommandSendMessage, wsCommandChooseOption, wsCommandCancel:
+	default:
+		return fmt.Errorf("unknown command type %q", cmd.Type)
+	}
+
+	agent, err := u.manager.GetAgent(req.Context(), sessionID)
+	if err != nil {
+		return fmt.Errorf("getting agent: %w", err)
+	}
+
+	switch cmd.Type {
+	case wsCommandSendMessage:
+		if cmd.Query == "" {
+			return fmt.Errorf("missing query")
+		}
+		agent.Input <- &api.UserInputResponse{Query: cmd.Query, QuotedMessageIDs: cmd.Quotes}
+	case wsCommandChooseOption:
+		if cmd.Choice <= 0 {
+			return fmt.Errorf("invalid choice")
+		}
+		agent.Input <- &api.UserChoiceResponse{Choice: cmd.Choice}
+	case wsCommandCancel:
+		if !agent.CancelRequest() {
+			return fmt.Errorf("no request in progress")
+		}
+	}
+	return nil
+}
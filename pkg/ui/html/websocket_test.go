@@ -0,0 +1,203 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package html
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/gollm"
+	"github.com/GoogleCloudPlatform/kubectl-ai/internal/mocks"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/gorilla/websocket"
+	"go.uber.org/mock/gomock"
+)
+
+type fakePart struct {
+	text  string
+	calls []gollm.FunctionCall
+}
+
+func (p fakePart) AsText() (string, bool) { return p.text, p.text != "" }
+func (p fakePart) AsFunctionCalls() ([]gollm.FunctionCall, bool) {
+	return p.calls, p.calls != nil
+}
+
+type fakeCandidate struct{ parts []gollm.Part }
+
+func (c fakeCandidate) String() string      { return "" }
+func (c fakeCandidate) Parts() []gollm.Part { return c.parts }
+
+type fakeChatResponse struct{ candidate gollm.Candidate }
+
+func (r fakeChatResponse) UsageMetadata() any            { return nil }
+func (r fakeChatResponse) Candidates() []gollm.Candidate { return []gollm.Candidate{r.candidate} }
+
+// respond returns a streamed response of the LLM made of parts.
+func respond(parts ...gollm.Part) gollm.ChatResponseIterator {
+	return func(yield func(gollm.ChatResponse, error) bool) {
+		yield(fakeChatResponse{candidate: fakeCandidate{parts: parts}}, nil)
+	}
+}
+
+// newTestServer serves the web UI, configured by opts, for a new session, with agents
+// talking to chat.
+func newTestServer(t *testing.T, chat *mocks.MockChat, opts ...Option) (u *HTMLUserInterface, server *httptest.Server, sessionID string) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockClient(ctrl)
+	client.EXPECT().StartChat(gomock.Any(), "test-model").Return(chat).AnyTimes()
+	client.EXPECT().Close().Return(nil).AnyTimes()
+	chat.EXPECT().Initialize(gomock.Any()).Return(nil).AnyTimes()
+	chat.EXPECT().SetFunctionDefinitions(gomock.Any()).Return(nil).AnyTimes()
+
+	sessionManager, err := sessions.NewSessionManager("memory")
+	if err != nil {
+		t.Fatalf("creating session manager: %v", err)
+	}
+	session, err := sessionManager.NewSession(sessions.Metadata{ModelID: "test-model"})
+	if err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	manager := agent.NewAgentManager(func(ctx context.Context) (*agent.Agent, error) {
+		return &agent.Agent{LLM: client, Model: "test-model", MaxIterations: 5}, nil
+	}, sessionManager)
+	t.Cleanup(func() { manager.Close() })
+
+	u, err = NewHTMLUserInterface(manager, sessionManager, "test-model", "test", "127.0.0.1:0", nil, opts...)
+	if err != nil {
+		t.Fatalf("creating web UI: %v", err)
+	}
+	t.Cleanup(func() { u.httpServerListener.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go u.broadcaster.Run(ctx)
+
+	server = httptest.NewServer(u.httpServer.Handler)
+	t.Cleanup(server.Close)
+	return u, server, session.ID
+}
+
+// dialSession opens a WebSocket to the session and reads its snapshot.
+func dialSession(t *testing.T, server *httptest.Server, sessionID string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/sessions/" + sessionID + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dialing websocket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if event := readEvent(t, conn); event.Type != eventSnapshot {
+		t.Fatalf("first event = %q, want a snapshot", event.Type)
+	}
+	return conn
+}
+
+// wsEvent is an event or an error reply received over the WebSocket.
+type wsEvent struct {
+	sessionEvent
+	Error string `json:"error"`
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) *wsEvent {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event wsEvent
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("reading event: %v", err)
+	}
+	return &event
+}
+
+// waitForEvent reads events until one matches.
+func waitForEvent(t *testing.T, conn *websocket.Conn, match func(*wsEvent) bool) *wsEvent {
+	t.Helper()
+	for {
+		if event := readEvent(t, conn); match(event) {
+			return event
+		}
+	}
+}
+
+func sendCommand(t *testing.T, conn *websocket.Conn, cmd wsCommand) {
+	t.Helper()
+	if err := conn.WriteJSON(cmd); err != nil {
+		t.Fatalf("sending %s command: %v", cmd.Type, err)
+	}
+}
+
+func isMessage(messageType api.MessageType) func(*wsEvent) bool {
+	return func(event *wsEvent) bool {
+		return event.Type == eventMessageAdded && event.Message.Type == messageType
+	}
+}
+
+func TestWebSocketCommands(t *testing.T) {
+	chat := mocks.NewMockChat(gomock.NewController(t))
+	deleteCall := fakePart{calls: []gollm.FunctionCall{{ID: "1", Name: "kubectl", Arguments: map[string]any{"command": "kubectl delete pod web-0"}}}}
+	gomock.InOrder(
+		chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).Return(respond(deleteCall), nil),
+		// After the call is declined, the model takes until the request is cancelled
+		chat.EXPECT().SendStreaming(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, contents ...any) (gollm.ChatResponseIterator, error) {
+				<-ctx.Done()
+				return nil, context.Cause(ctx)
+			}),
+	)
+	_, server, sessionID := newTestServer(t, chat)
+	conn := dialSession(t, server, sessionID)
+
+	sendCommand(t, conn, wsCommand{Type: wsCommandSendMessage, Query: "delete web-0"})
+	waitForEvent(t, conn, isMessage(api.MessageTypeUserChoiceRequest))
+
+	sendCommand(t, conn, wsCommand{Type: wsCommandChooseOption, Choice: 3})
+	waitForEvent(t, conn, func(event *wsEvent) bool {
+		return event.Type == eventAgentStep && event.Step.Type == api.AgentEventLLMCallStarted && event.Step.Iteration == 1
+	})
+
+	sendCommand(t, conn, wsCommand{Type: wsCommandCancel})
+	waitForEvent(t, conn, func(event *wsEvent) bool {
+		return isMessage(api.MessageTypeError)(event) && strings.Contains(api.ErrorPayloadFrom(event.Message.Payload).Message, agent.ErrRequestCancelled.Error())
+	})
+
+	// Commands that cannot be handled are answered with an error
+	sendCommand(t, conn, wsCommand{Type: wsCommandChooseOption})
+	if event := waitForEvent(t, conn, func(event *wsEvent) bool { return event.Error != "" }); event.Error != "invalid choice" {
+		t.Errorf("error = %q, want invalid choice", event.Error)
+	}
+}
+
+func TestWebSocketTyping(t *testing.T) {
+	_, server, sessionID := newTestServer(t, mocks.NewMockChat(gomock.NewController(t)))
+	typist := dialSession(t, server, sessionID)
+	other := dialSession(t, server, sessionID)
+
+	sendCommand(t, typist, wsCommand{Type: wsCommandTyping, Typing: true})
+	event := waitForEvent(t, other, func(event *wsEvent) bool { return event.Type == eventTyping })
+	if !event.Typing || event.Seq != 0 {
+		t.Errorf("typing event = %+v, want typing without a sequence number", event.sessionEvent)
+	}
+
+	// The typist does not get its own typing events back
+	sendCommand(t, typist, wsCommand{Type: wsCommandChooseOption})
+	if event := readEvent(t, typist); event.Type == eventTyping {
+		t.Errorf("typing event sent back to the typist")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"k8s.io/klog/v2"
+)
+
+// Types of the events written by JSONUI.
+const (
+	JSONEventMessage     = "message"
+	JSONEventToolCall    = "tool-call"
+	JSONEventToolResult  = "tool-result"
+	JSONEventError       = "error"
+	JSONEventFinalAnswer = "final-answer"
+	JSONEventThought     = "thought"
+	JSONEventStatus      = "status"
+)
+
+// JSONEvent is a line of output of JSONUI.
+type JSONEvent struct {
+	Type      string    `json:"type"`
+	Source    string    `json:"source,omitempty"`
+	Content   any       `json:"content,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// JSONUI writes the messages of a non-interactive (RunOnce) agent as newline-delimited
+// JSON events, for use in shell pipelines and CI jobs. The last event is the final
+// answer of the model, or an error.
+type JSONUI struct {
+	agent *agent.Agent
+	out   io.Writer
+}
+
+var _ UI = &JSONUI{}
+
+func NewJSONUI(agent *agent.Agent, out io.Writer) *JSONUI {
+	return &JSONUI{agent: agent, out: out}
+}
+
+func (u *JSONUI) Run(ctx context.Context) error {
+	encoder := json.NewEncoder(u.out)
+	emit := func(event JSONEvent) error {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("writing json event: %w", err)
+		}
+		return nil
+	}
+
+	var finalAnswer, lastError string
+	// finish writes the final answer, or the error that stopped the agent.
+	finish := func() error {
+		err := u.agent.LastErr()
+		switch {
+		case err == nil:
+			if emitErr := emit(JSONEvent{Type: JSONEventFinalAnswer, Source: string(api.MessageSourceModel), Content: finalAnswer, Timestamp: time.Now()}); emitErr != nil {
+				return emitErr
+			}
+		case !strings.Contains(lastError, err.Error()):
+			if emitErr := emit(JSONEvent{Type: JSONEventError, Source: string(api.MessageSourceAgent), Content: err.Error(), Timestamp: time.Now()}); emitErr != nil {
+				return emitErr
+			}
+		}
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-u.agent.Output:
+			if !ok {
+				return finish()
+			}
+			message := msg.(*api.Message)
+			klog.Infof("agent output: %+v", message)
+
+			if event, ok := jsonEventFor(message); ok {
+				if err := emit(event); err != nil {
+					return err
+				}
+				switch event.Type {
+				case JSONEventMessage:
+					if message.Source == api.MessageSourceModel {
+						finalAnswer, _ = message.Payload.(string)
+					}
+				case JSONEventError:
+					lastError = api.ErrorPayloadFrom(message.Payload).Message
+				}
+			}
+
+			if u.agent.GetSession().AgentState == api.AgentStateExited {
+				return finish()
+			}
+		}
+	}
+}
+
+// jsonEventFor converts an agent message to an event. Requests for user input are
+// not reported, since a RunOnce agent exits instead of waiting for them.
+func jsonEventFor(message *api.Message) (JSONEvent, bool) {
+	event := JSONEvent{
+		Source:    string(message.Source),
+		Content:   message.Payload,
+		Timestamp: message.Timestamp,
+	}
+	switch message.Type {
+	case api.MessageTypeText:
+		if message.Source == api.MessageSourceUser {
+			return event, false
+		}
+		event.Type = JSONEventMessage
+	case api.MessageTypeThought:
+		event.Type = JSONEventThought
+	case api.MessageTypeStatus:
+		event.Type = JSONEventStatus
+	case api.MessageTypeToolCallRequest:
+		event.Type = JSONEventToolCall
+	case api.MessageTypeToolCallResponse:
+		event.Type = JSONEventToolResult
+	case api.MessageTypeError:
+		event.Type = JSONEventError
+	default:
+		return event, false
+	}
+	return event, true
+}
+
+func (u *JSONUI) ClearScreen() {
+}
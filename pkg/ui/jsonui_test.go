@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+func TestJSONUI(t *testing.T) {
+	a := &agent.Agent{
+		Output:  make(chan any, 10),
+		Session: &api.Session{AgentState: api.AgentStateRunning},
+	}
+	messages := []*api.Message{
+		{Source: api.MessageSourceUser, Type: api.MessageTypeText, Payload: "how many pods?"},
+		{Source: api.MessageSourceModel, Type: api.MessageTypeToolCallRequest, Payload: "kubectl get pods"},
+		{Source: api.MessageSourceAgent, Type: api.MessageTypeToolCallResponse, Payload: map[string]any{"stdout": "nginx"}},
+		{Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "There is one pod."},
+		{Source: api.MessageSourceAgent, Type: api.MessageTypeUserInputRequest, Payload: ">>>"},
+	}
+	for _, message := range messages {
+		a.Output <- message
+	}
+	close(a.Output)
+
+	var out bytes.Buffer
+	if err := NewJSONUI(a, &out).Run(context.Background()); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	var gotTypes []string
+	var last JSONEvent
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if err := json.Unmarshal([]byte(line), &last); err != nil {
+			t.Fatalf("line %q is not a JSON event: %v", line, err)
+		}
+		gotTypes = append(gotTypes, last.Type)
+	}
+
+	wantTypes := []string{JSONEventToolCall, JSONEventToolResult, JSONEventMessage, JSONEventFinalAnswer}
+	if strings.Join(gotTypes, ",") != strings.Join(wantTypes, ",") {
+		t.Errorf("event types = %v, want %v", gotTypes, wantTypes)
+	}
+	if last.Content != "There is one pod." {
+		t.Errorf("final answer = %v, want %q", last.Content, "There is one pod.")
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+)
+
+// BlockKind is how a Block is presented.
+type BlockKind string
+
+const (
+	// BlockMarkdown is Markdown text.
+	BlockMarkdown BlockKind = "markdown"
+	// BlockCode is preformatted text, e.g. a command or its output, highlighted per Language.
+	BlockCode BlockKind = "code"
+	// BlockDiff is a change from Diff.Before to Diff.After.
+	BlockDiff BlockKind = "diff"
+	// BlockTable is a Table. Text keeps the table as aligned text, for plain frontends.
+	BlockTable BlockKind = "table"
+	// BlockChoice is a question to the user with its options, from Choice.
+	BlockChoice BlockKind = "choice"
+	// BlockPlan is a checklist of steps, from Plan.
+	BlockPlan BlockKind = "plan"
+)
+
+// Tones of blocks, which frontends show with their own colors.
+const (
+	ToneError = "error"
+	// ToneMuted is for secondary content, such as thoughts and statuses.
+	ToneMuted = "muted"
+)
+
+// Block is a part of a rendered message, independent of the frontend: the TUI, the terminal
+// and the web UI each present blocks in their own way.
+type Block struct {
+	Kind BlockKind `json:"kind"`
+	// Title is a heading shown above the block, e.g. "Thought" or the file of a diff.
+	Title string `json:"title,omitempty"`
+	// Text is the Markdown of markdown blocks, and the content of code and table blocks.
+	Text string `json:"text,omitempty"`
+	// Language of code blocks, e.g. shell, json or yaml, if known.
+	Language string `json:"language,omitempty"`
+	Tone     string `json:"tone,omitempty"`
+	// Collapsed blocks are folded by default, showing their title or first lines.
+	Collapsed bool `json:"collapsed,omitempty"`
+
+	Diff   *api.Diff              `json:"diff,omitempty"`
+	Table  *Table                 `json:"table,omitempty"`
+	Choice *api.UserChoiceRequest `json:"choice,omitempty"`
+	Plan   *api.Plan              `json:"plan,omitempty"`
+}
+
+// Table is the content of a BlockTable block.
+type Table struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// MessageRenderer renders a message into blocks. Rendering no block hides the message.
+type MessageRenderer func(message *api.Message) []Block
+
+var (
+	renderersMu sync.RWMutex
+	// typeRenderers render messages by type, and payloadRenderers by the Go type of their
+	// payload, for message types without a renderer.
+	typeRenderers    = map[api.MessageType]MessageRenderer{}
+	payloadRenderers = map[reflect.Type]MessageRenderer{}
+)
+
+// RegisterMessageRenderer sets the renderer of the messages of a type, for all frontends.
+func RegisterMessageRenderer(messageType api.MessageType, renderer MessageRenderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	typeRenderers[messageType] = renderer
+}
+
+// RegisterPayloadRenderer sets the renderer of the messages with payloads of the type of
+// payload, e.g. (*api.Diff)(nil), for the message types without a renderer of their own.
+func RegisterPayloadRenderer(payload any, renderer MessageRenderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	payloadRenderers[reflect.TypeOf(payload)] = renderer
+}
+
+func init() {
+	RegisterMessageRenderer(api.MessageTypeText, renderText)
+	RegisterMessageRenderer(api.MessageTypeUserInputRequest, renderUserInputRequest)
+	RegisterMessageRenderer(api.MessageTypeThought, renderThought)
+	RegisterMessageRenderer(api.MessageTypeStatus, renderStatus)
+	RegisterMessageRenderer(api.MessageTypeError, renderError)
+	RegisterMessageRenderer(api.MessageTypeToolCallRequest, renderToolCallRequest)
+	RegisterMessageRenderer(api.MessageTypeToolCallResponse, renderToolCallResponse)
+	RegisterMessageRenderer(api.MessageTypeUserChoiceRequest, renderChoice)
+	RegisterMessageRenderer(api.MessageTypeDiff, renderDiff)
+	RegisterMessageRenderer(api.MessageTypePlan, renderPlan)
+	RegisterPayloadRenderer((*api.Diff)(nil), renderDiff)
+	RegisterPayloadRenderer((*api.Plan)(nil), renderPlan)
+}
+
+// RenderMessage renders a message with the renderer of its type, or else of its payload.
+// Messages that no renderer knows are shown as their text, or else as JSON, so that new
+// message types appear in every frontend.
+func RenderMessage(message *api.Message) []Block {
+	renderersMu.RLock()
+	renderer, ok := typeRenderers[message.Type]
+	if !ok && message.Payload != nil {
+		renderer, ok = payloadRenderers[reflect.TypeOf(message.Payload)]
+	}
+	renderersMu.RUnlock()
+	if ok {
+		return renderer(message)
+	}
+
+	if text, ok := message.Payload.(string); ok {
+		return []Block{{Kind: BlockMarkdown, Text: text}}
+	}
+	b, err := json.MarshalIndent(message.Payload, "", "  ")
+	if err != nil {
+		return []Block{{Kind: BlockCode, Text: fmt.Sprint(message.Payload)}}
+	}
+	return []Block{{Kind: BlockCode, Title: string(message.Type), Text: string(b), Language: "json"}}
+}
+
+func renderText(message *api.Message) []Block {
+	return []Block{{Kind: BlockMarkdown, Text: fmt.Sprint(message.Payload)}}
+}
+
+func renderUserInputRequest(message *api.Message) []Block {
+	// ">>>" only prompts for the next query
+	if message.Payload == ">>>" {
+		return nil
+	}
+	return renderText(message)
+}
+
+func renderThought(message *api.Message) []Block {
+	return []Block{{Kind: BlockMarkdown, Title: "Thought", Text: fmt.Sprint(message.Payload), Tone: ToneMuted, Collapsed: true}}
+}
+
+func renderStatus(message *api.Message) []Block {
+	return []Block{{Kind: BlockMarkdown, Text: fmt.Sprint(message.Payload), Tone: ToneMuted}}
+}
+
+func renderError(message *api.Message) []Block {
+	payload := api.ErrorPayloadFrom(message.Payload)
+	text := payload.Message
+	if payload.Guidance != "" {
+		text += "\n\n" + payload.Guidance
+	}
+	return []Block{{Kind: BlockMarkdown, Title: "Error", Text: text, Tone: ToneError}}
+}
+
+func renderToolCallRequest(message *api.Message) []Block {
+	return []Block{{Kind: BlockCode, Title: "Running", Text: fmt.Sprint(message.Payload), Language: "shell"}}
+}
+
+func renderToolCallResponse(message *api.Message) []Block {
+	result, err := tools.ToolResultToMap(message.Payload)
+	if err != nil {
+		return []Block{{Kind: BlockCode, Title: "Output", Text: fmt.Sprint(message.Payload), Collapsed: true}}
+	}
+	output := strings.TrimRight(formatToolCallResponse(result), "\n")
+	if table := parseTable(output); table != nil {
+		return []Block{{Kind: BlockTable, Title: "Output", Text: output, Table: table, Collapsed: true}}
+	}
+	return []Block{{Kind: BlockCode, Title: "Output", Text: output, Language: detectOutputLanguage(output), Collapsed: true}}
+}
+
+func renderChoice(message *api.Message) []Block {
+	var choice api.UserChoiceRequest
+	if decodePayload(message.Payload, &choice) != nil {
+		return nil
+	}
+	return []Block{{Kind: BlockChoice, Text: choice.Prompt, Choice: &choice}}
+}
+
+func renderDiff(message *api.Message) []Block {
+	var diff api.Diff
+	if decodePayload(message.Payload, &diff) != nil {
+		return nil
+	}
+	return []Block{{Kind: BlockDiff, Title: diff.Title, Diff: &diff}}
+}
+
+func renderPlan(message *api.Message) []Block {
+	var plan api.Plan
+	if decodePayload(message.Payload, &plan) != nil {
+		return nil
+	}
+	return []Block{{Kind: BlockPlan, Title: plan.Title, Plan: &plan}}
+}
+
+// decodePayload converts a message payload into out. Payloads are typed structs for live
+// sessions but generic maps for sessions loaded from disk, so go through JSON.
+func decodePayload(payload any, out any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// detectOutputLanguage guesses whether tool output is JSON or YAML, for syntax highlighting.
+func detectOutputLanguage(output string) string {
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" {
+		return ""
+	}
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return "json"
+	}
+	if strings.HasPrefix(trimmed, "apiVersion:") || strings.Contains(trimmed, "\napiVersion:") || strings.HasPrefix(trimmed, "---") {
+		return "yaml"
+	}
+	return ""
+}
+
+// tableHeader matches the header of kubectl tables: upper-case column names separated by at
+// least two spaces, e.g. "NAME   READY   STATUS".
+var tableHeader = regexp.MustCompile(`^[A-Z][A-Z0-9()/%:._-]*( ?[A-Z0-9()/%:._-]+)*( {2,}[A-Z][A-Z0-9()/%:._-]*( ?[A-Z0-9()/%:._-]+)*)+\s*$`)
+
+// parseTable parses the aligned tables of kubectl, returning nil for other output. Cells
+// are cut at the offsets of the columns of the header, as kubectl aligns them.
+func parseTable(output string) *Table {
+	lines := strings.Split(output, "\n")
+	if len(lines) < 2 || !tableHeader.MatchString(lines[0]) {
+		return nil
+	}
+	header := lines[0]
+	var offsets []int
+	for i := range header {
+		if header[i] != ' ' && (i == 0 || i >= 2 && header[i-2:i] == "  ") {
+			offsets = append(offsets, i)
+		}
+	}
+
+	table := &Table{}
+	cut := func(line string) []string {
+		cells := make([]string, len(offsets))
+		for i, start := range offsets {
+			end := len(line)
+			if i+1 < len(offsets) {
+				end = min(offsets[i+1], len(line))
+			}
+			if start < end {
+				cells[i] = strings.TrimSpace(line[start:end])
+			}
+		}
+		return cells
+	}
+	table.Columns = cut(header)
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// A cell running into the next column means the output is not aligned as a table
+		for _, offset := range offsets[1:] {
+			if offset < len(line) && line[offset-1] != ' ' {
+				return nil
+			}
+		}
+		table.Rows = append(table.Rows, cut(line))
+	}
+	return table
+}
+
+// BlocksMarkdown renders blocks as Markdown, for plain text frontends and transcripts.
+func BlocksMarkdown(blocks []Block) string {
+	var b strings.Builder
+	for _, block := range blocks {
+		if block.Title != "" && block.Kind != BlockChoice {
+			fmt.Fprintf(&b, "**%s:**\n\n", block.Title)
+		}
+		switch block.Kind {
+		case BlockMarkdown:
+			b.WriteString(block.Text + "\n\n")
+		case BlockCode, BlockTable:
+			fmt.Fprintf(&b, "```%s\n%s\n```\n\n", block.Language, block.Text)
+		case BlockDiff:
+			fmt.Fprintf(&b, "```diff\n--- before\n+++ after\n%s```\n\n", naiveDiff(block.Diff.Before, block.Diff.After))
+		case BlockChoice:
+			b.WriteString(block.Text + "\n\n")
+			for i, option := range block.Choice.Options {
+				fmt.Fprintf(&b, "%d. %s\n", i+1, option.Label)
+			}
+			b.WriteString("\n")
+		case BlockPlan:
+			for _, step := range block.Plan.Steps {
+				fmt.Fprintf(&b, "- %s %s\n", planStepMarker(step.Status), step.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// planStepMarker returns the checkbox of a plan step with the status.
+func planStepMarker(status string) string {
+	switch status {
+	case "done":
+		return "[x]"
+	case "in-progress":
+		return "[~]"
+	case "failed":
+		return "[!]"
+	}
+	return "[ ]"
+}
+
+// naiveDiff renders all removed lines followed by all added lines.
+// It is only meant for plain text; the browser renders an aligned diff.
+func naiveDiff(before, after string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(before, "\n") {
+		b.WriteString("-" + line + "\n")
+	}
+	for _, line := range strings.Split(after, "\n") {
+		b.WriteString("+" + line + "\n")
+	}
+	return b.String()
+}
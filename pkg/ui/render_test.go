@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+func TestRenderMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *api.Message
+		want    []Block
+	}{
+		{
+			name:    "prompt for the next query",
+			message: &api.Message{Type: api.MessageTypeUserInputRequest, Payload: ">>>"},
+			want:    nil,
+		},
+		{
+			name: "kubectl table",
+			message: &api.Message{Type: api.MessageTypeToolCallResponse, Payload: map[string]any{
+				"stdout": "NAME    READY   STATUS    NOMINATED NODE\nweb-1   1/1     Running   <none>\n",
+			}},
+			want: []Block{{
+				Kind:  BlockTable,
+				Title: "Output",
+				Text:  "NAME    READY   STATUS    NOMINATED NODE\nweb-1   1/1     Running   <none>",
+				Table: &Table{
+					Columns: []string{"NAME", "READY", "STATUS", "NOMINATED NODE"},
+					Rows:    [][]string{{"web-1", "1/1", "Running", "<none>"}},
+				},
+				Collapsed: true,
+			}},
+		},
+		{
+			name:    "diff loaded from disk",
+			message: &api.Message{Type: api.MessageTypeDiff, Payload: map[string]any{"title": "deployment/web", "before": "a", "after": "b"}},
+			want:    []Block{{Kind: BlockDiff, Title: "deployment/web", Diff: &api.Diff{Title: "deployment/web", Before: "a", After: "b"}}},
+		},
+		{
+			name:    "payload type of an unknown message type",
+			message: &api.Message{Type: "change-preview", Payload: &api.Plan{Steps: []api.PlanStep{{Description: "scale web"}}}},
+			want:    []Block{{Kind: BlockPlan, Plan: &api.Plan{Steps: []api.PlanStep{{Description: "scale web"}}}}},
+		},
+		{
+			name:    "unknown message type",
+			message: &api.Message{Type: "usage", Payload: map[string]int{"tokens": 42}},
+			want:    []Block{{Kind: BlockCode, Title: "usage", Text: "{\n  \"tokens\": 42\n}", Language: "json"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMessage(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RenderMessage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTableRejectsUnalignedOutput(t *testing.T) {
+	for _, output := range []string{
+		"deployment.apps/web scaled",
+		"NAME   READY\nweb-1-with-a-long-name 1/1",
+	} {
+		if table := parseTable(output); table != nil {
+			t.Errorf("parseTable(%q) = %+v, want nil", output, table)
+		}
+	}
+}
+
+func TestDetectOutputLanguage(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"", ""},
+		{`{"kind": "Pod"}`, "json"},
+		{"[1, 2]", "json"},
+		{"{not json", ""},
+		{"apiVersion: v1\nkind: Pod", "yaml"},
+		{"---\nkind: Pod", "yaml"},
+		{"# pods\napiVersion: v1", "yaml"},
+		{"NAME   READY\nweb-1  1/1", ""},
+	}
+	for _, tt := range tests {
+		if got := detectOutputLanguage(tt.output); got != tt.want {
+			t.Errorf("detectOutputLanguage(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
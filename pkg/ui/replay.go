@@ -0,0 +1,267 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+)
+
+// Kinds of replay steps.
+const (
+	ReplayStepMessage  = "message"
+	ReplayStepToolCall = "tool-call"
+	ReplayStepLLMCall  = "llm-call"
+)
+
+// ReplayStep is a step of a recorded session: a message, or a tool or LLM call
+// with how long it took.
+type ReplayStep struct {
+	Kind  string
+	Title string
+	Body  string
+	// Offset is the time of the step since the first event of the session.
+	Offset time.Duration
+	// Duration is how long a tool or LLM call took, if its end was recorded.
+	Duration time.Duration
+}
+
+// ReplaySteps reconstructs the steps of a session from its journal. Tool calls are
+// paired with their responses, and LLM calls with the HTTP response that ended them.
+// Tool call messages are skipped in favor of the tool events, which carry timings.
+func ReplaySteps(log *journal.SessionLog) []ReplayStep {
+	events := append([]*journal.Event(nil), log.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	if len(events) == 0 {
+		return nil
+	}
+	start := events[0].Timestamp
+
+	var steps []ReplayStep
+	// pendingTools maps tool call IDs to their step, and pendingLLM is the step of the
+	// last LLM request awaiting a response; -1 if none.
+	pendingTools := map[string]int{}
+	pendingLLM := -1
+	for _, event := range events {
+		offset := event.Timestamp.Sub(start)
+		switch event.Action {
+		case journal.ActionAgentMessage:
+			var message api.Message
+			if decodeEventPayload(event.Payload, &message) != nil {
+				continue
+			}
+			if step, ok := replayMessageStep(&message); ok {
+				step.Offset = offset
+				steps = append(steps, step)
+			}
+
+		case journal.ActionToolRequest:
+			var request tools.ToolRequestEvent
+			if decodeEventPayload(event.Payload, &request) != nil {
+				continue
+			}
+			body := ""
+			if b, err := json.MarshalIndent(request.Arguments, "", "  "); err == nil {
+				body = "Arguments:\n" + string(b)
+			}
+			pendingTools[request.CallID] = len(steps)
+			steps = append(steps, ReplayStep{
+				Kind:   ReplayStepToolCall,
+				Title:  replayToolTitle(request),
+				Body:   body,
+				Offset: offset,
+			})
+
+		case journal.ActionToolResponse:
+			var response tools.ToolResponseEvent
+			if decodeEventPayload(event.Payload, &response) != nil {
+				continue
+			}
+			i, ok := pendingTools[response.CallID]
+			if !ok {
+				continue
+			}
+			delete(pendingTools, response.CallID)
+			step := &steps[i]
+			step.Duration = offset - step.Offset
+			if response.Error != "" {
+				step.Body += "\n\nError: " + response.Error
+			}
+			if output := replayToolOutput(response.Response); output != "" {
+				step.Body += "\n\nOutput:\n" + output
+			}
+
+		case journal.ActionPermissionDecision:
+			var decision agent.PermissionDecisionEvent
+			if decodeEventPayload(event.Payload, &decision) != nil {
+				continue
+			}
+			steps = append(steps, replayDecisionStep(decision, offset))
+
+		case journal.ActionHTTPRequest:
+			pendingLLM = len(steps)
+			steps = append(steps, ReplayStep{
+				Kind:   ReplayStepLLMCall,
+				Title:  "LLM call",
+				Offset: offset,
+			})
+
+		case journal.ActionHTTPResponse, journal.ActionHTTPError:
+			if pendingLLM < 0 {
+				continue
+			}
+			step := &steps[pendingLLM]
+			pendingLLM = -1
+			step.Duration = offset - step.Offset
+			if status, ok := event.GetString("status"); ok {
+				step.Title += " (" + status + ")"
+			}
+			if detail, ok := event.GetString("detail"); ok {
+				step.Title += " (failed)"
+				step.Body = detail
+			}
+		}
+	}
+	return steps
+}
+
+// replayMessageStep converts a conversation message to a step, if it is worth showing.
+func replayMessageStep(message *api.Message) (ReplayStep, bool) {
+	step := ReplayStep{Kind: ReplayStepMessage}
+	switch message.Type {
+	case api.MessageTypeText:
+		step.Title = replaySenderName(message.Source)
+		step.Body = fmt.Sprint(message.Payload)
+	case api.MessageTypeThought:
+		step.Title = "Thought"
+		step.Body = fmt.Sprint(message.Payload)
+	case api.MessageTypeError:
+		errPayload := api.ErrorPayloadFrom(message.Payload)
+		step.Title = "Error (" + string(errPayload.Category) + ")"
+		step.Body = strings.TrimSpace(errPayload.Message + "\n\n" + errPayload.Guidance)
+	case api.MessageTypeUserChoiceRequest:
+		var choice api.UserChoiceRequest
+		if decodeEventPayload(message.Payload, &choice) != nil {
+			return step, false
+		}
+		step.Title = "Approval requested"
+		step.Body = choice.Prompt
+	default:
+		return step, false
+	}
+	return step, true
+}
+
+// replayDecisionStep shows which commands were approved, and by whom.
+func replayDecisionStep(decision agent.PermissionDecisionEvent, offset time.Duration) ReplayStep {
+	var b strings.Builder
+	if decision.AutoApproved {
+		b.WriteString("Approved without asking, permission checks are skipped.\n\n")
+	}
+	for _, command := range decision.Commands {
+		mark := "declined"
+		if command.Approved {
+			mark = "approved"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", mark, command.Command)
+		if command.Justification != "" {
+			fmt.Fprintf(&b, "  justification: %s\n", command.Justification)
+		}
+	}
+	return ReplayStep{
+		Kind:   ReplayStepMessage,
+		Title:  "Permission " + decision.Decision,
+		Body:   strings.TrimRight(b.String(), "\n"),
+		Offset: offset,
+	}
+}
+
+func replaySenderName(source api.MessageSource) string {
+	switch source {
+	case api.MessageSourceUser:
+		return "You"
+	case api.MessageSourceModel:
+		return "AI Assistant"
+	default:
+		return "kubectl-ai"
+	}
+}
+
+func replayToolTitle(request tools.ToolRequestEvent) string {
+	if command, ok := request.Arguments["command"].(string); ok {
+		return "Tool " + request.Name + ": " + command
+	}
+	return "Tool " + request.Name
+}
+
+func replayToolOutput(response any) string {
+	if response == nil {
+		return ""
+	}
+	result, err := tools.ToolResultToMap(response)
+	if err != nil {
+		return fmt.Sprint(response)
+	}
+	return strings.TrimRight(formatToolCallResponse(result), "\n")
+}
+
+// decodeEventPayload converts the generic payload of a parsed journal event into out.
+func decodeEventPayload(payload any, out any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// formatReplayTiming formats the offset and duration of a step, e.g. "+1.5s, took 320ms".
+func formatReplayTiming(step ReplayStep) string {
+	timing := "+" + step.Offset.Round(time.Millisecond).String()
+	if step.Duration > 0 {
+		timing += ", took " + step.Duration.Round(time.Millisecond).String()
+	}
+	return timing
+}
+
+// RenderReplayMarkdown renders the steps of a session as a Markdown transcript.
+func RenderReplayMarkdown(sessionID string, steps []ReplayStep) string {
+	var b strings.Builder
+	if sessionID == "" {
+		sessionID = "(unknown session)"
+	}
+	fmt.Fprintf(&b, "# Replay of %s\n\n", sessionID)
+	for _, step := range steps {
+		fmt.Fprintf(&b, "## %s\n\n_%s_\n\n", step.Title, formatReplayTiming(step))
+		if step.Body == "" {
+			continue
+		}
+		if step.Kind == ReplayStepMessage {
+			fmt.Fprintf(&b, "%s\n\n", step.Body)
+		} else {
+			fmt.Fprintf(&b, "```\n%s\n```\n\n", step.Body)
+		}
+	}
+	return b.String()
+}
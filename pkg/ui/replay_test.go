@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/journal"
+)
+
+func TestReplaySteps(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// Events as parsed from a trace file, with generic payloads
+	log := &journal.SessionLog{SessionID: "s1", Events: []*journal.Event{
+		{Timestamp: at(0), Action: journal.ActionAgentMessage, Payload: map[string]any{"Source": "user", "Type": "text", "Payload": "list pods"}},
+		{Timestamp: at(10), Action: journal.ActionHTTPRequest, Payload: map[string]any{"request": "POST /"}},
+		{Timestamp: at(510), Action: journal.ActionHTTPResponse, Payload: map[string]any{"status": "200 OK"}},
+		{Timestamp: at(520), Action: journal.ActionAgentMessage, Payload: map[string]any{"Source": "agent", "Type": "tool-call-request", "Payload": "kubectl get pods"}},
+		{Timestamp: at(530), Action: journal.ActionToolRequest, Payload: map[string]any{"id": "c1", "name": "kubectl", "arguments": map[string]any{"command": "kubectl get pods"}}},
+		{Timestamp: at(830), Action: journal.ActionToolResponse, Payload: map[string]any{"id": "c1", "response": map[string]any{"stdout": "pod-a Running"}}},
+		{Timestamp: at(900), Action: journal.ActionAgentMessage, Payload: map[string]any{"Source": "model", "Type": "text", "Payload": "One pod is running."}},
+	}}
+
+	steps := ReplaySteps(log)
+	var titles []string
+	for _, step := range steps {
+		titles = append(titles, step.Title)
+	}
+	want := []string{"You", "LLM call (200 OK)", "Tool kubectl: kubectl get pods", "AI Assistant"}
+	if strings.Join(titles, "|") != strings.Join(want, "|") {
+		t.Fatalf("step titles = %q, want %q", titles, want)
+	}
+
+	if steps[1].Duration != 500*time.Millisecond {
+		t.Errorf("LLM call duration = %v, want 500ms", steps[1].Duration)
+	}
+	tool := steps[2]
+	if tool.Offset != 530*time.Millisecond || tool.Duration != 300*time.Millisecond {
+		t.Errorf("tool call offset, duration = %v, %v, want 530ms, 300ms", tool.Offset, tool.Duration)
+	}
+	if !strings.Contains(tool.Body, "pod-a Running") {
+		t.Errorf("tool call body %q does not contain the output", tool.Body)
+	}
+
+	markdown := RenderReplayMarkdown(log.SessionID, steps)
+	if !strings.Contains(markdown, "_+530ms, took 300ms_") {
+		t.Errorf("markdown does not contain the tool call timing:\n%s", markdown)
+	}
+}
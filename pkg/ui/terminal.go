@@ -47,6 +47,7 @@ const (
 	colorGreen colorValue = "green"
 	colorWhite colorValue = "white"
 	colorRed   colorValue = "red"
+	colorGray  colorValue = "gray"
 )
 
 type styleOption func(s *computedStyle)
@@ -113,8 +114,12 @@ func getCustomTerminalWidth() int {
 func NewTerminalUI(agent *agent.Agent, useTTYForInput bool, showToolOutput bool, journal journal.Recorder) (*TerminalUI, error) {
 	width := getCustomTerminalWidth()
 
+	style := glamour.WithAutoStyle()
+	if noColor() {
+		style = glamour.WithStandardStyle("notty")
+	}
 	options := []glamour.TermRendererOption{
-		glamour.WithAutoStyle(),
+		style,
 		glamour.WithPreservedNewLines(),
 		glamour.WithEmoji(),
 	}
@@ -262,7 +267,17 @@ func (u *TerminalUI) handleMessage(msg *api.Message) {
 		}
 	case api.MessageTypeError:
 		styleOptions = append(styleOptions, foreground(colorRed))
-		text = msg.Payload.(string)
+		errPayload := api.ErrorPayloadFrom(msg.Payload)
+		text = errPayload.Message
+		if errPayload.Guidance != "" {
+			text += "\n" + errPayload.Guidance
+		}
+	case api.MessageTypeThought:
+		styleOptions = append(styleOptions, foreground(colorGray))
+		text = fmt.Sprintf("  Thought: %s\n", thoughtSummary(fmt.Sprint(msg.Payload)))
+	case api.MessageTypeStatus:
+		styleOptions = append(styleOptions, foreground(colorGray))
+		text = fmt.Sprintf("  %v\n", msg.Payload)
 	case api.MessageTypeToolCallRequest:
 		styleOptions = append(styleOptions, foreground(colorGreen))
 		text = fmt.Sprintf("\n  Running: %s\n", msg.Payload.(string))
@@ -428,8 +443,12 @@ func (u *TerminalUI) handleMessage(msg *api.Message) {
 		u.agent.Input <- &api.UserChoiceResponse{Choice: choice}
 		return
 	default:
-		klog.Warningf("unsupported message type: %v", msg.Type)
-		return
+		// Other messages, e.g. diffs and plans, are shown as the Markdown of their blocks
+		text = BlocksMarkdown(RenderMessage(msg))
+		if text == "" {
+			return
+		}
+		styleOptions = append(styleOptions, renderMarkdown())
 	}
 
 	computedStyle := &computedStyle{}
@@ -458,6 +477,9 @@ func (u *TerminalUI) handleMessage(msg *api.Message) {
 	case colorWhite:
 		fmt.Printf("\033[37m")
 		reset += "\033[0m"
+	case colorGray:
+		fmt.Printf("\033[90m")
+		reset += "\033[0m"
 
 	case "":
 	default:
@@ -467,6 +489,17 @@ func (u *TerminalUI) handleMessage(msg *api.Message) {
 	fmt.Printf("%s%s", printText, reset)
 }
 
+// thoughtSummary collapses the reasoning of the model to its first line, with the
+// number of lines left out.
+func thoughtSummary(thought string) string {
+	lines := strings.Split(strings.TrimSpace(thought), "\n")
+	summary := strings.TrimSpace(lines[0])
+	if len(lines) > 1 {
+		summary += fmt.Sprintf(" (%d more lines)", len(lines)-1)
+	}
+	return summary
+}
+
 func (u *TerminalUI) ClearScreen() {
 	fmt.Print("\033[H\033[2J")
 }
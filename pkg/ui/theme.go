@@ -0,0 +1,188 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the color palette of the TUI. Colors are ANSI 256 color numbers or hex
+// values, as accepted by lipgloss.Color.
+type Theme struct {
+	// Base is the built-in theme that unset colors are taken from: "dark" or "light".
+	// Custom themes default to the one matching the terminal background.
+	Base string `json:"base,omitempty"`
+	// Accent is used for the spinner and the command palette border.
+	Accent string `json:"accent,omitempty"`
+	// Selected is used for the selected item of lists and tool outputs.
+	Selected string `json:"selected,omitempty"`
+	// Dim is used for borders, hints and other secondary text.
+	Dim string `json:"dim,omitempty"`
+	// Sender is used for the names of message senders.
+	Sender string `json:"sender,omitempty"`
+	// PlainGlyphs replaces box drawing characters and other symbols with ASCII,
+	// for terminals and fonts that lack them.
+	PlainGlyphs bool `json:"plainGlyphs,omitempty"`
+}
+
+var builtinThemes = map[string]Theme{
+	"dark":  {Base: "dark", Accent: "63", Selected: "170", Dim: "241", Sender: "5"},
+	"light": {Base: "light", Accent: "57", Selected: "127", Dim: "244", Sender: "90"},
+}
+
+// ResolveTheme returns the theme to use for a --ui-theme value: "auto" (or empty),
+// "dark", "light", or "custom" for the custom theme from the config file.
+// Auto picks dark or light from the terminal background.
+func ResolveTheme(name string, custom *Theme) (Theme, error) {
+	switch name {
+	case "", "auto":
+		return builtinThemes[backgroundTheme()], nil
+	case "dark", "light":
+		return builtinThemes[name], nil
+	case "custom":
+		if custom == nil {
+			return Theme{}, fmt.Errorf("theme %q requires uiCustomTheme in the config file", name)
+		}
+		t := *custom
+		if t.Base == "" {
+			t.Base = backgroundTheme()
+		}
+		base, ok := builtinThemes[t.Base]
+		if !ok {
+			return Theme{}, fmt.Errorf("custom theme base %q is not known, must be dark or light", t.Base)
+		}
+		if t.Accent == "" {
+			t.Accent = base.Accent
+		}
+		if t.Selected == "" {
+			t.Selected = base.Selected
+		}
+		if t.Dim == "" {
+			t.Dim = base.Dim
+		}
+		if t.Sender == "" {
+			t.Sender = base.Sender
+		}
+		return t, nil
+	default:
+		return Theme{}, fmt.Errorf("theme %q is not known, must be one of auto, dark, light or custom", name)
+	}
+}
+
+func backgroundTheme() string {
+	if lipgloss.HasDarkBackground() {
+		return "dark"
+	}
+	return "light"
+}
+
+// noColor reports whether the user asked for output without colors (https://no-color.org).
+// lipgloss already honors it; glamour styles need to be picked accordingly.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// markdownStyle returns the glamour option rendering markdown for the theme.
+func (t Theme) markdownStyle() glamour.TermRendererOption {
+	if noColor() {
+		return glamour.WithStandardStyle("notty")
+	}
+	return glamour.WithStandardStyle(t.Base)
+}
+
+// glyphSet holds the symbols drawn by the TUI.
+type glyphSet struct {
+	prompt    string
+	cursor    string
+	collapsed string
+	expanded  string
+	ellipsis  string
+	separator string
+	rounded   lipgloss.Border
+	normal    lipgloss.Border
+}
+
+var (
+	unicodeGlyphs = glyphSet{
+		prompt:    "┃ ",
+		cursor:    "▍",
+		collapsed: "▸",
+		expanded:  "▾",
+		ellipsis:  "…",
+		separator: "·",
+		rounded:   lipgloss.RoundedBorder(),
+		normal:    lipgloss.NormalBorder(),
+	}
+	asciiGlyphs = glyphSet{
+		prompt:    "| ",
+		cursor:    "_",
+		collapsed: ">",
+		expanded:  "v",
+		ellipsis:  "...",
+		separator: "-",
+		rounded:   lipgloss.ASCIIBorder(),
+		normal:    lipgloss.ASCIIBorder(),
+	}
+)
+
+var (
+	activeTheme = builtinThemes["dark"]
+	glyphs      = unicodeGlyphs
+)
+
+func init() {
+	applyTheme(activeTheme)
+}
+
+// applyTheme sets the colors and glyphs of all TUI styles.
+func applyTheme(t Theme) {
+	activeTheme = t
+	glyphs = unicodeGlyphs
+	if t.PlainGlyphs {
+		glyphs = asciiGlyphs
+	}
+
+	accent := lipgloss.Color(t.Accent)
+	selected := lipgloss.Color(t.Selected)
+	dim := lipgloss.Color(t.Dim)
+
+	spinnerStyle = lipgloss.NewStyle().Foreground(accent)
+	helpStyle = lipgloss.NewStyle().Foreground(dim).Margin(1, 0)
+	dotStyle = helpStyle.UnsetMargins()
+	durationStyle = dotStyle
+	selectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(selected)
+	paginationStyle = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
+	toolOutputStyle = lipgloss.NewStyle().Border(glyphs.rounded).BorderForeground(dim).Padding(0, 1)
+	toolSelectedStyle = toolOutputStyle.BorderForeground(selected)
+	toolHeaderStyle = lipgloss.NewStyle().Foreground(dim)
+
+	paletteStyle = lipgloss.NewStyle().Border(glyphs.rounded).BorderForeground(accent).Padding(0, 1)
+	paletteSelectedStyle = lipgloss.NewStyle().Foreground(selected)
+	completionStyle = lipgloss.NewStyle().Foreground(dim)
+
+	sidebarStyle = lipgloss.NewStyle().Border(glyphs.normal, false, true, false, false).BorderForeground(dim).PaddingRight(1)
+	sidebarCurrentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Sender))
+	sidebarSelectedStyle = lipgloss.NewStyle().Foreground(selected)
+	sidebarDimStyle = lipgloss.NewStyle().Foreground(dim)
+
+	inspectorStyle = lipgloss.NewStyle().Border(glyphs.normal, false, false, false, true).BorderForeground(dim).PaddingLeft(1)
+	inspectorTabStyle = lipgloss.NewStyle().Foreground(dim)
+	inspectorActiveStyle = lipgloss.NewStyle().Foreground(selected).Underline(true)
+}
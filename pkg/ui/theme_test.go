@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestResolveTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		custom  *Theme
+		want    Theme
+		wantErr bool
+	}{
+		{name: "dark", want: builtinThemes["dark"]},
+		{name: "light", want: builtinThemes["light"]},
+		{name: "solarized", wantErr: true},
+		{name: "custom", wantErr: true},
+		{
+			name:   "custom",
+			custom: &Theme{Base: "light", Accent: "#ff8800", PlainGlyphs: true},
+			want:   Theme{Base: "light", Accent: "#ff8800", Selected: "127", Dim: "244", Sender: "90", PlainGlyphs: true},
+		},
+		{name: "custom", custom: &Theme{Base: "sepia"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveTheme(tt.name, tt.custom)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ResolveTheme(%q, %+v) error = %v, want error %v", tt.name, tt.custom, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ResolveTheme(%q, %+v) = %+v, want %+v", tt.name, tt.custom, got, tt.want)
+		}
+	}
+
+	// Auto, and custom themes without a base, follow the terminal background
+	for _, name := range []string{"", "auto"} {
+		if got, err := ResolveTheme(name, nil); err != nil || got != builtinThemes[got.Base] {
+			t.Errorf("ResolveTheme(%q) = %+v, %v, want a built-in theme", name, got, err)
+		}
+	}
+	if got, err := ResolveTheme("custom", &Theme{Dim: "240"}); err != nil || got.Base == "" || got.Dim != "240" || got.Accent != builtinThemes[got.Base].Accent {
+		t.Errorf("ResolveTheme(custom without base) = %+v, %v, want the colors of a built-in base", got, err)
+	}
+}
+
+func TestThemeNoColor(t *testing.T) {
+	render := func() string {
+		t.Helper()
+		renderer, err := glamour.NewTermRenderer(builtinThemes["dark"].markdownStyle())
+		if err != nil {
+			t.Fatalf("creating renderer: %v", err)
+		}
+		out, err := renderer.Render("**Pods** are `running`")
+		if err != nil {
+			t.Fatalf("rendering: %v", err)
+		}
+		return out
+	}
+
+	t.Setenv("NO_COLOR", "")
+	if out := render(); out == ansi.Strip(out) {
+		t.Errorf("markdown rendered without escape sequences: %q", out)
+	}
+	t.Setenv("NO_COLOR", "1")
+	if out := render(); out != ansi.Strip(out) {
+		t.Errorf("markdown rendered with escape sequences although NO_COLOR is set: %q", out)
+	}
+}
+
+func TestThemePlainGlyphs(t *testing.T) {
+	t.Cleanup(func() { applyTheme(builtinThemes["dark"]) })
+	message := toolResponse("t1", toolPreviewLines+1)
+
+	applyTheme(Theme{Base: "dark", PlainGlyphs: true})
+	m := newTestModel(t, message)
+	m.selectedTool = message.ID
+	rendered := ansi.Strip(m.renderMessage(message))
+	if i := strings.IndexFunc(rendered, func(r rune) bool { return r > unicode.MaxASCII }); i >= 0 {
+		t.Errorf("tool output drawn with plain glyphs has non-ASCII characters at %d:\n%s", i, rendered)
+	}
+	if !strings.Contains(rendered, "> Output") || !strings.Contains(rendered, "... 1 more lines") {
+		t.Errorf("tool output drawn with plain glyphs has no ASCII markers:\n%s", rendered)
+	}
+
+	applyTheme(builtinThemes["dark"])
+	if rendered := ansi.Strip(m.renderMessage(message)); !strings.Contains(rendered, "▸ Output") {
+		t.Errorf("tool output drawn with the default glyphs has no collapsed marker:\n%s", rendered)
+	}
+}
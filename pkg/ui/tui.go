@@ -25,6 +25,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
 	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -38,19 +39,29 @@ import (
 const listHeight = 5
 
 var (
-	spinnerStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("63"))
-	helpStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Margin(1, 0)
-	dotStyle          = helpStyle.UnsetMargins()
-	durationStyle     = dotStyle
-	appStyle          = lipgloss.NewStyle().Margin(1, 2, 0, 2)
-	titleStyle        = lipgloss.NewStyle().MarginLeft(2)
-	listStyle         = lipgloss.NewStyle().MarginBottom(2)
-	itemStyle         = lipgloss.NewStyle().PaddingLeft(4)
-	selectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("170"))
-	paginationStyle   = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
-	quitTextStyle     = lipgloss.NewStyle().Margin(1, 0, 2, 4)
+	appStyle      = lipgloss.NewStyle().Margin(1, 2, 0, 2)
+	titleStyle    = lipgloss.NewStyle().MarginLeft(2)
+	listStyle     = lipgloss.NewStyle().MarginBottom(2)
+	itemStyle     = lipgloss.NewStyle().PaddingLeft(4)
+	quitTextStyle = lipgloss.NewStyle().Margin(1, 0, 2, 4)
 )
 
+// Styles depending on the theme, set by applyTheme
+var (
+	spinnerStyle      lipgloss.Style
+	helpStyle         lipgloss.Style
+	dotStyle          lipgloss.Style
+	durationStyle     lipgloss.Style
+	selectedItemStyle lipgloss.Style
+	paginationStyle   lipgloss.Style
+	toolOutputStyle   lipgloss.Style
+	toolSelectedStyle lipgloss.Style
+	toolHeaderStyle   lipgloss.Style
+)
+
+// toolPreviewLines is how many lines of a collapsed tool output are shown.
+const toolPreviewLines = 5
+
 type item string
 
 func (i item) FilterValue() string { return "" }
@@ -61,13 +72,27 @@ func (d itemDelegate) Height() int                             { return 1 }
 func (d itemDelegate) Spacing() int                            { return 0 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
-	i, ok := listItem.(item)
-	if !ok {
+	var str string
+	switch i := listItem.(type) {
+	case item:
+		// Number the options after the approval checklist, if any
+		number := 1
+		for _, other := range m.Items()[:index] {
+			if _, ok := other.(item); ok {
+				number++
+			}
+		}
+		str = fmt.Sprintf("%d. %s", number, i)
+	case approvalItem:
+		check := "[ ]"
+		if i.approved {
+			check = "[x]"
+		}
+		str = check + " " + i.command
+	default:
 		return
 	}
 
-	str := fmt.Sprintf("%d. %s", index+1, i)
-
 	fn := itemStyle.Render
 	if index == m.Index() {
 		fn = func(s ...string) string {
@@ -99,7 +124,9 @@ type TUI struct {
 	agent   *agent.Agent
 }
 
-func NewTUI(agent *agent.Agent) *TUI {
+// NewTUI returns a TUI for the agent, drawn with the given theme.
+func NewTUI(agent *agent.Agent, theme Theme) *TUI {
+	applyTheme(theme)
 	return &TUI{
 		program: tea.NewProgram(newModel(agent), tea.WithAltScreen()),
 		agent:   agent,
@@ -160,6 +187,41 @@ type model struct {
 	list     list.Model
 	choice   string
 	username string // cached username
+
+	// choiceRequest is the choice the options list was built for, identified by
+	// its message ID, and approvals holds the checklist state of its commands.
+	choiceRequest *api.UserChoiceRequest
+	choiceID      string
+	approvals     []bool
+
+	// expandedTools holds the IDs of tool outputs shown in full.
+	expandedTools map[string]bool
+	// selectedTool is the ID of the tool output that ctrl+o toggles.
+	selectedTool string
+
+	// streaming is the model response that is still being streamed in, if any.
+	// It is replaced by the persisted message with the same ID once complete.
+	streaming *api.Message
+	// status is the transient status of the agent, e.g. waiting for provider quota, shown
+	// below the messages until the next message.
+	status string
+	// renderCache holds rendered persisted messages.
+	renderCache *messageRenderCache
+
+	palette   commandPalette
+	sidebar   sessionSidebar
+	inspector resourceInspector
+	search    chatSearch
+
+	// suggestion is the complete kubectl command suggested for the input, shown as ghost
+	// text and accepted with tab.
+	suggestion string
+
+	// editing is the earlier message of the user being edited in the input, if any.
+	editing *api.Message
+
+	// width and height of the terminal
+	width, height int
 }
 
 func newModel(agent *agent.Agent) model {
@@ -167,7 +229,7 @@ func newModel(agent *agent.Agent) model {
 	ta.Placeholder = "Send a message..."
 	ta.Focus()
 
-	ta.Prompt = "┃ "
+	ta.Prompt = glyphs.prompt
 	ta.CharLimit = 280
 
 	ta.SetWidth(30)
@@ -206,9 +268,15 @@ Type a message and press Enter to send.`)
 		viewport: vp,
 		list:     l,
 		// a lipgloss style for the sender
-		senderStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("5")),
-		username:    getCurrentUsername(),
-		err:         nil,
+		senderStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color(activeTheme.Sender)),
+		username:      getCurrentUsername(),
+		expandedTools: make(map[string]bool),
+		renderCache:   newMessageRenderCache(),
+		palette:       newCommandPalette(agent),
+		sidebar:       newSessionSidebar(agent.SessionBackend),
+		inspector:     newResourceInspector(agent.RunKubectl),
+		search:        newChatSearch(),
+		err:           nil,
 	}
 }
 
@@ -217,6 +285,91 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if result, ok := msg.(inspectorResultMsg); ok {
+		m.inspector.handleResult(result)
+		return m, nil
+	}
+	switch msg := msg.(type) {
+	case suggestTickMsg:
+		return m, m.fetchSuggestion(msg)
+	case suggestResultMsg:
+		if msg.partial == m.textarea.Value() {
+			m.suggestion = msg.suggestion
+		}
+		return m, nil
+	}
+	if key, ok := msg.(tea.KeyMsg); ok {
+		if m.sidebar.open {
+			resumeID, cmd := m.sidebar.update(key)
+			if !m.sidebar.open {
+				m.layout()
+			}
+			if resumeID != "" {
+				m.sidebar.toggle()
+				m.layout()
+				if resumeID != m.agent.GetSession().ID {
+					m.submitQuery("/resume-session " + resumeID)
+				}
+			}
+			return m, cmd
+		}
+		if m.inspector.open {
+			cmd := m.inspector.update(key)
+			if !m.inspector.open {
+				m.layout()
+			}
+			return m, cmd
+		}
+		if m.search.active {
+			return m, m.updateSearch(key)
+		}
+		if m.palette.open {
+			entry, cmd := m.palette.update(key)
+			if entry != nil {
+				if entry.query != "" {
+					m.submitQuery(entry.query)
+				} else {
+					m.textarea.SetValue(entry.insert)
+				}
+			}
+			return m, cmd
+		}
+		switch key.String() {
+		case "ctrl+p":
+			return m, m.palette.show()
+		case "ctrl+s":
+			m.sidebar.toggle()
+			m.layout()
+			return m, nil
+		case "ctrl+r":
+			m.inspector.toggle(m.agent.GetSession().AllMessages())
+			m.layout()
+			return m, nil
+		case "ctrl+f":
+			return m, m.startSearch()
+		case "ctrl+e":
+			if m.startEdit() {
+				return m, nil
+			}
+		case "esc":
+			if m.editing != nil {
+				m.cancelEdit()
+				return m, nil
+			}
+		case " ":
+			if m.toggleApproval() {
+				return m, nil
+			}
+		case "tab":
+			if m.acceptSuggestion() {
+				return m, nil
+			}
+			if completions := inputCompletions(m.textarea.Value(), toolNames(m.agent)); len(completions) > 0 {
+				m.textarea.SetValue(completeInput(m.textarea.Value(), completions))
+				return m, nil
+			}
+		}
+	}
 
 	var (
 		tiCmd   tea.Cmd
@@ -224,56 +377,60 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		listCmd tea.Cmd
 	)
 
+	previousInput := m.textarea.Value()
 	m.textarea, tiCmd = m.textarea.Update(msg)
 	m.viewport, vpCmd = m.viewport.Update(msg)
 	m.list, listCmd = m.list.Update(msg)
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.viewport.Width = msg.Width
-		m.textarea.SetWidth(msg.Width)
-		if m.agent.GetSession().AgentState == api.AgentStateWaitingForInput {
-			m.list.SetWidth(msg.Width)
-			// m.viewport.Height = msg.Height - m.list.Height() - lipgloss.Height(gap)
-			// TODO: keeping the height of the viewport the same as the height of the textarea for now to avoid jerky UI
-			m.viewport.Height = msg.Height - m.textarea.Height() - lipgloss.Height(gap)
-		} else {
-			m.viewport.Height = msg.Height - m.textarea.Height() - lipgloss.Height(gap)
-		}
-		if len(m.renderedMessages()) > 0 {
-			// Wrap content before setting it.
-			m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.renderedMessages(), "\n")))
-		}
-		m.viewport.GotoBottom()
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc, tea.KeyCtrlD:
 			return m, tea.Quit
+		case tea.KeyTab, tea.KeyShiftTab:
+			m.selectToolOutput(msg.Type == tea.KeyTab)
+			m.setContent(strings.Join(m.renderedMessages(), "\n"))
+			return m, nil
+		case tea.KeyCtrlO:
+			if m.selectedTool == "" {
+				m.selectToolOutput(false)
+			}
+			if m.selectedTool != "" {
+				m.expandedTools[m.selectedTool] = !m.expandedTools[m.selectedTool]
+				m.setContent(strings.Join(m.renderedMessages(), "\n"))
+			}
+			return m, nil
 		case tea.KeyEnter:
 			if m.agent.GetSession().AgentState == api.AgentStateWaitingForInput {
-				i, ok := m.list.SelectedItem().(item)
-				if ok {
-					m.choice = string(i)
-					choiceIndex := m.list.Index()
-					m.agent.Input <- &api.UserChoiceResponse{Choice: choiceIndex + 1}
-				}
+				m.chooseOption()
+				return m, nil
+			}
+			if m.editing != nil {
+				m.submitEdit()
 				return m, nil
 			}
 
-			m.messages = append(m.messages, &api.Message{
-				Source:  api.MessageSourceUser,
-				Type:    api.MessageTypeText,
-				Payload: m.textarea.Value(),
-			})
-			m.viewport.SetContent(strings.Join(m.renderedMessages(), "\n"))
-			m.agent.Input <- &api.UserInputResponse{Query: m.textarea.Value()}
+			m.submitQuery(m.textarea.Value())
 			m.textarea.Reset()
-			m.viewport.GotoBottom()
 		}
 	case *api.Message:
 		m.messages = m.agent.GetSession().AllMessages()
-		m.viewport.SetContent(strings.Join(m.renderedMessages(), "\n"))
-		m.viewport.GotoBottom()
+		m.syncChoiceRequest()
+		m.streaming = nil
+		m.status = ""
+		if msg.Type == api.MessageTypeStatus {
+			m.status = fmt.Sprint(msg.Payload)
+		}
+		if isPartialResponse(msg, m.messages) {
+			m.streaming = msg
+		}
+		m.setContent(strings.Join(m.renderedMessages(), "\n"))
+		if !m.search.active {
+			m.viewport.GotoBottom()
+		}
 
 	// We handle errors just like any other message
 	case errMsg:
@@ -281,8 +438,60 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	return m, tea.Batch(tiCmd, vpCmd, listCmd)
+	return m, tea.Batch(tiCmd, vpCmd, listCmd, m.updateSuggestion(previousInput))
+
+}
 
+// layout sizes the chat for the terminal, leaving room for the sidebar when it is open.
+func (m *model) layout() {
+	width := m.width
+	if m.sidebar.open {
+		width -= sidebarWidth
+	}
+	if m.inspector.open {
+		inspectorWidth := m.width * 2 / 5
+		m.inspector.setSize(inspectorWidth, m.height)
+		width -= inspectorWidth
+	}
+	m.viewport.Width = width
+	m.textarea.SetWidth(width)
+	if m.agent.GetSession().AgentState == api.AgentStateWaitingForInput {
+		m.list.SetWidth(width)
+		// m.viewport.Height = msg.Height - m.list.Height() - lipgloss.Height(gap)
+		// TODO: keeping the height of the viewport the same as the height of the textarea for now to avoid jerky UI
+		m.viewport.Height = m.height - m.textarea.Height() - lipgloss.Height(gap)
+	} else {
+		m.viewport.Height = m.height - m.textarea.Height() - lipgloss.Height(gap)
+	}
+	if len(m.renderedMessages()) > 0 {
+		// Wrap content before setting it.
+		m.setContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(strings.Join(m.renderedMessages(), "\n")))
+	}
+	if m.search.active {
+		m.scrollToMatch()
+	} else {
+		m.viewport.GotoBottom()
+	}
+}
+
+// setContent shows the rendered conversation in the viewport, marking search matches.
+func (m *model) setContent(content string) {
+	if m.search.active {
+		content = m.search.highlight(content)
+	}
+	m.viewport.SetContent(content)
+}
+
+// submitQuery shows the query in the chat and sends it to the agent.
+func (m *model) submitQuery(query string) {
+	m.messages = append(m.messages, &api.Message{
+		Source:  api.MessageSourceUser,
+		Type:    api.MessageTypeText,
+		Payload: query,
+	})
+	m.setContent(strings.Join(m.renderedMessages(), "\n"))
+	m.agent.Input <- &api.UserInputResponse{Query: query}
+	m.viewport.GotoBottom()
 }
 
 func (m model) renderedMessages() []string {
@@ -293,19 +502,146 @@ func (m model) renderedMessages() []string {
 		if message.Type == api.MessageTypeUserInputRequest && message.Payload == ">>>" {
 			continue
 		}
-		messages = append(messages, m.renderMessage(message))
+		if message.Type == api.MessageTypeToolCallResponse {
+			// Depends on selection and expansion state, so never cached
+			messages = append(messages, m.renderMessage(message))
+			continue
+		}
+		messages = append(messages, m.renderCache.get(message.ID, m.viewport.Width, func() string {
+			return m.renderMessage(message)
+		}))
+	}
+	if m.streaming != nil {
+		messages = append(messages, strings.TrimRight(m.renderMessage(m.streaming), "\n")+" "+glyphs.cursor)
+	}
+	if m.status != "" {
+		messages = append(messages, toolHeaderStyle.Render(m.status))
 	}
 	return messages
 }
 
+// maxRenderCacheEntries bounds the number of rendered messages kept, e.g. across sessions.
+const maxRenderCacheEntries = 1000
+
+// messageRenderCache holds rendered messages by ID, for a single width. It is cleared
+// when the width changes, and when it is full.
+type messageRenderCache struct {
+	width    int
+	rendered map[string]string
+}
+
+func newMessageRenderCache() *messageRenderCache {
+	return &messageRenderCache{rendered: make(map[string]string)}
+}
+
+// get returns the message with the given ID rendered at width, calling render if it is
+// not cached yet.
+func (c *messageRenderCache) get(id string, width int, render func() string) string {
+	if width != c.width {
+		clear(c.rendered)
+		c.width = width
+	}
+	if rendered, ok := c.rendered[id]; ok {
+		return rendered
+	}
+	if len(c.rendered) >= maxRenderCacheEntries {
+		clear(c.rendered)
+	}
+	rendered := render()
+	c.rendered[id] = rendered
+	return rendered
+}
+
+// isPartialResponse reports whether msg is a streamed model response that has not been
+// persisted to the session yet.
+func isPartialResponse(msg *api.Message, persisted []*api.Message) bool {
+	if msg.Source != api.MessageSourceModel || msg.Type != api.MessageTypeText {
+		return false
+	}
+	// The persisted message, if any, is almost always the most recent one
+	for i := len(persisted) - 1; i >= 0; i-- {
+		if persisted[i].ID == msg.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// selectToolOutput moves the tool output selection to the next (or previous) tool output,
+// wrapping around. With nothing selected, it starts from the most recent one.
+func (m *model) selectToolOutput(next bool) {
+	var ids []string
+	for _, message := range m.agent.GetSession().AllMessages() {
+		if message.Type == api.MessageTypeToolCallResponse {
+			ids = append(ids, message.ID)
+		}
+	}
+	if len(ids) == 0 {
+		m.selectedTool = ""
+		return
+	}
+
+	current := -1
+	for i, id := range ids {
+		if id == m.selectedTool {
+			current = i
+		}
+	}
+	switch {
+	case current == -1:
+		current = len(ids) - 1
+	case next:
+		current = (current + 1) % len(ids)
+	default:
+		current = (current - 1 + len(ids)) % len(ids)
+	}
+	m.selectedTool = ids[current]
+}
+
 func (m model) View() string {
 	if m.quitting {
 		return quitTextStyle.Render("Not safe to quit yet.")
 	}
+	var panels []string
+	if m.sidebar.open {
+		panels = append(panels, m.sidebar.view(m.height, m.agent.GetSession().ID))
+	}
+	panels = append(panels, m.chatView())
+	if m.inspector.open {
+		panels = append(panels, m.inspector.view(m.width*2/5, m.height))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, panels...)
+}
+
+// chatView renders the conversation and the input area.
+func (m model) chatView() string {
+	if m.palette.open {
+		palette := m.palette.view(m.viewport.Width)
+		// The palette is taller than the input, so give up the bottom of the viewport for it
+		lines := strings.Split(m.viewport.View(), "\n")
+		if hidden := lipgloss.Height(palette) - m.textarea.Height(); hidden > 0 && hidden < len(lines) {
+			lines = lines[:len(lines)-hidden]
+		}
+		return strings.Join(lines, "\n") + gap + palette
+	}
+
+	if m.search.active {
+		return m.viewport.View() + gap + m.search.view()
+	}
+
+	separator := gap
+	if m.editing != nil {
+		separator = "\n" + completionStyle.MaxWidth(m.viewport.Width).Render("editing a message - enter: regenerate from it  ctrl+e: earlier message  esc: cancel") + "\n"
+	} else if completions := inputCompletions(m.textarea.Value(), toolNames(m.agent)); len(completions) > 0 {
+		separator = "\n" + completionStyle.MaxWidth(m.viewport.Width).Render("tab: "+strings.Join(completions, "  ")) + "\n"
+	} else if ghost := m.ghostText(); ghost != "" {
+		// The textarea cannot show ghost text inline, so show it completing the input above it
+		separator = "\n" + lipgloss.NewStyle().MaxWidth(m.viewport.Width).Render(completionStyle.Render("tab: ")+m.textarea.Value()+completionStyle.Render(ghost)) + "\n"
+	}
 	mainView := fmt.Sprintf(
 		"%s%s",
 		m.viewport.View(),
-		gap,
+		separator,
 	)
 	if m.agent.GetSession().AgentState == api.AgentStateWaitingForInput {
 		var choiceRequest *api.UserChoiceRequest
@@ -316,12 +652,6 @@ func (m model) View() string {
 		}
 
 		if choiceRequest != nil {
-			items := make([]list.Item, len(choiceRequest.Options))
-			for i, option := range choiceRequest.Options {
-				items[i] = item(option.Label)
-			}
-			m.list.SetItems(items)
-			m.list.Title = "Select an option:"
 			mainView += listStyle.Render(m.list.View())
 		} else {
 			mainView += m.textarea.View()
@@ -344,7 +674,7 @@ func (m model) renderMessage(message *api.Message) string {
 	glamourRenderWidth := m.viewport.Width - m.viewport.Style.GetHorizontalFrameSize() - lipgloss.Width(text)
 
 	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		activeTheme.markdownStyle(),
 		glamour.WithWordWrap(glamourRenderWidth),
 	)
 	if err != nil {
@@ -352,32 +682,87 @@ func (m model) renderMessage(message *api.Message) string {
 		return fmt.Sprintf("error rendering message: %v", err)
 	}
 
-	var renderedText string
-	var contentToRender string
+	var rendered strings.Builder
+	for _, block := range RenderMessage(message) {
+		if block.Collapsed {
+			// Collapsed blocks are shown folded, without the sender
+			if block.Kind == BlockMarkdown {
+				rendered.WriteString(toolHeaderStyle.Render(fmt.Sprintf("%s %s: %s", glyphs.collapsed, block.Title, thoughtSummary(block.Text))) + "\n")
+			} else {
+				rendered.WriteString(m.renderToolOutput(message, block, renderer))
+			}
+			continue
+		}
 
-	switch p := message.Payload.(type) {
-	case string:
-		contentToRender = p
-	case *api.UserChoiceRequest:
-		contentToRender = p.Prompt
-	default:
-		return "" // Don't render unknown payload types
+		contentToRender := blockMarkdown(block)
+		renderedText, err := renderer.Render(contentToRender)
+		if err != nil {
+			klog.Errorf("failed to render markdown: %v", err)
+			renderedText = contentToRender // Fallback to non-rendered
+		}
+		rendered.WriteString(text + renderedText)
 	}
+	return rendered.String()
+}
 
-	switch message.Type {
-	case api.MessageTypeToolCallRequest:
-		contentToRender = fmt.Sprintf("Running: `%s`", contentToRender)
-	case api.MessageTypeError:
-		contentToRender = fmt.Sprintf("Error: %s", contentToRender)
-	case api.MessageTypeToolCallResponse:
-		return "" // Or a summary
+// blockMarkdown returns the Markdown shown in the chat for a block. Titles prefix single
+// line content, and the options of choices are left to the options list.
+func blockMarkdown(block Block) string {
+	switch {
+	case block.Kind == BlockMarkdown && block.Title != "":
+		return fmt.Sprintf("%s: %s", block.Title, block.Text)
+	case block.Kind == BlockCode && block.Title != "" && !strings.Contains(block.Text, "\n"):
+		return fmt.Sprintf("%s: `%s`", block.Title, block.Text)
+	case block.Kind == BlockChoice:
+		return block.Text
 	}
+	return BlocksMarkdown([]Block{block})
+}
 
-	renderedText, err = renderer.Render(contentToRender)
+// toolOutputText returns the text of a tool result as shown in the chat.
+func toolOutputText(message *api.Message) (string, error) {
+	result, err := tools.ToolResultToMap(message.Payload)
 	if err != nil {
-		klog.Errorf("failed to render markdown: %v", err)
-		return text + contentToRender // Fallback to non-rendered
+		return "", err
+	}
+	return strings.TrimRight(formatToolCallResponse(result), "\n"), nil
+}
+
+// renderToolOutput renders a collapsible block, such as a tool result, as a bordered block.
+// Collapsed blocks show the first few lines and how many were hidden; expanded blocks
+// show everything, highlighted when the output looks like YAML or JSON.
+func (m model) renderToolOutput(message *api.Message, block Block, renderer *glamour.TermRenderer) string {
+	output := block.Text
+	lines := strings.Split(output, "\n")
+
+	expanded := m.expandedTools[message.ID]
+	marker := glyphs.collapsed
+	if expanded {
+		marker = glyphs.expanded
 	}
+	header := toolHeaderStyle.Render(fmt.Sprintf("%s %s (%d lines) %s tab to select, ctrl+o to toggle", marker, block.Title, len(lines), glyphs.separator))
 
-	return text + renderedText
+	shown := lines
+	if !expanded && len(lines) > toolPreviewLines {
+		shown = lines[:toolPreviewLines]
+	}
+	body := strings.Join(shown, "\n")
+	if lang := block.Language; expanded && lang != "" {
+		if highlighted, err := renderer.Render(fmt.Sprintf("```%s\n%s\n```", lang, body)); err == nil {
+			body = strings.Trim(highlighted, "\n")
+		}
+	}
+	if hidden := len(lines) - len(shown); hidden > 0 {
+		body += "\n" + toolHeaderStyle.Render(fmt.Sprintf("%s %d more lines", glyphs.ellipsis, hidden))
+	}
+
+	style := toolOutputStyle
+	if message.ID == m.selectedTool {
+		style = toolSelectedStyle
+	}
+	width := m.viewport.Width - style.GetHorizontalFrameSize()
+	if width > 0 {
+		style = style.Width(width)
+	}
+	return style.Render(header+"\n"+body) + "\n"
 }
@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// approvalItem is a command in the approval checklist, shown above the options when
+// several commands await approval.
+type approvalItem struct {
+	command  string
+	approved bool
+}
+
+func (i approvalItem) FilterValue() string { return "" }
+
+// choiceItems returns the list items for a choice request: a checklist of the commands
+// awaiting approval, if there are several, followed by the options.
+func choiceItems(req *api.UserChoiceRequest, approvals []bool) []list.Item {
+	var items []list.Item
+	if len(req.Commands) > 1 {
+		for i, command := range req.Commands {
+			items = append(items, approvalItem{command: command, approved: i < len(approvals) && approvals[i]})
+		}
+	}
+	for _, option := range req.Options {
+		items = append(items, item(option.Label))
+	}
+	return items
+}
+
+// syncChoiceRequest resets the options list when a new choice request arrives, with
+// every command approved.
+func (m *model) syncChoiceRequest() {
+	if len(m.messages) == 0 {
+		return
+	}
+	last := m.messages[len(m.messages)-1]
+	req, ok := last.Payload.(*api.UserChoiceRequest)
+	if !ok || last.Type != api.MessageTypeUserChoiceRequest || last.ID == m.choiceID {
+		return
+	}
+
+	m.choiceID = last.ID
+	m.choiceRequest = req
+	m.approvals = make([]bool, len(req.Commands))
+	for i := range m.approvals {
+		m.approvals[i] = true
+	}
+
+	items := choiceItems(req, m.approvals)
+	m.list.SetItems(items)
+	m.list.SetHeight(max(listHeight, len(items)+2))
+	m.list.Select(0)
+	if len(req.Commands) > 1 {
+		m.list.Title = "Select the commands to run (space to toggle), then an option:"
+	} else {
+		m.list.Title = "Select an option:"
+	}
+}
+
+// toggleApproval flips the checklist entry under the cursor. It reports false if the
+// cursor is not on the checklist.
+func (m *model) toggleApproval() bool {
+	if m.choiceRequest == nil || m.agent.GetSession().AgentState != api.AgentStateWaitingForInput {
+		return false
+	}
+	if _, ok := m.list.SelectedItem().(approvalItem); !ok {
+		return false
+	}
+	i := m.list.Index()
+	m.approvals[i] = !m.approvals[i]
+	m.list.SetItems(choiceItems(m.choiceRequest, m.approvals))
+	return true
+}
+
+// chooseOption sends the option under the cursor to the agent, along with the
+// per-command selections when there is a checklist.
+func (m *model) chooseOption() {
+	if m.toggleApproval() {
+		return
+	}
+	i, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return
+	}
+	m.choice = string(i)
+
+	response := &api.UserChoiceResponse{Choice: m.list.Index() + 1}
+	if m.choiceRequest != nil && len(m.choiceRequest.Commands) > 1 {
+		response.Choice -= len(m.choiceRequest.Commands)
+		response.Selections = append([]bool(nil), m.approvals...)
+	}
+	m.agent.Input <- response
+}
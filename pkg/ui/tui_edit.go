@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+)
+
+// startEdit loads the previous message of the user into the input to edit it: the last one,
+// or the one before the message being edited. It reports whether there was one to edit.
+func (m *model) startEdit() bool {
+	if state := m.agent.GetSession().AgentState; state != api.AgentStateIdle && state != api.AgentStateDone {
+		return false
+	}
+	messages := m.agent.GetSession().AllMessages()
+	end := len(messages)
+	if m.editing != nil {
+		for i, message := range messages {
+			if message.ID == m.editing.ID {
+				end = i
+			}
+		}
+	}
+	for i := end - 1; i >= 0; i-- {
+		message := messages[i]
+		if text, ok := message.Payload.(string); ok && message.Source == api.MessageSourceUser && message.Type == api.MessageTypeText {
+			m.editing = message
+			m.textarea.SetValue(text)
+			m.suggestion = ""
+			return true
+		}
+	}
+	return m.editing != nil
+}
+
+// cancelEdit leaves the edit, clearing the input.
+func (m *model) cancelEdit() {
+	m.editing = nil
+	m.textarea.Reset()
+}
+
+// submitEdit replaces the message being edited by the input, and shows the conversation up
+// to it until the agent sends the regenerated answer.
+func (m *model) submitEdit() {
+	edited := m.editing
+	query := m.textarea.Value()
+	m.editing = nil
+	m.textarea.Reset()
+	if err := m.agent.EditMessage(edited.ID, query); err != nil {
+		m.status = fmt.Sprintf("Cannot edit the message: %v", err)
+		m.setContent(strings.Join(m.renderedMessages(), "\n"))
+		return
+	}
+	for i, message := range m.messages {
+		if message.ID == edited.ID {
+			m.messages = m.messages[:i]
+			break
+		}
+	}
+	m.messages = append(m.messages, &api.Message{
+		Source:  api.MessageSourceUser,
+		Type:    api.MessageTypeText,
+		Payload: query,
+	})
+	m.setContent(strings.Join(m.renderedMessages(), "\n"))
+	m.viewport.GotoBottom()
+}
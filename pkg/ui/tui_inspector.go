@@ -0,0 +1,335 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/redact"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxInspectorResources bounds how many detected resources the inspector lists.
+	maxInspectorResources = 20
+	// inspectorTimeout bounds each kubectl call made by the inspector.
+	inspectorTimeout = 20 * time.Second
+)
+
+// Styles depending on the theme, set by applyTheme
+var (
+	inspectorStyle       lipgloss.Style
+	inspectorTabStyle    lipgloss.Style
+	inspectorActiveStyle lipgloss.Style
+)
+
+// resourceKinds maps the names and short names kubectl accepts to the object kind.
+var resourceKinds = map[string]string{
+	"pod": "Pod", "pods": "Pod", "po": "Pod",
+	"deployment": "Deployment", "deployments": "Deployment", "deploy": "Deployment",
+	"replicaset": "ReplicaSet", "replicasets": "ReplicaSet", "rs": "ReplicaSet",
+	"statefulset": "StatefulSet", "statefulsets": "StatefulSet", "sts": "StatefulSet",
+	"daemonset": "DaemonSet", "daemonsets": "DaemonSet", "ds": "DaemonSet",
+	"job": "Job", "jobs": "Job",
+	"cronjob": "CronJob", "cronjobs": "CronJob", "cj": "CronJob",
+	"service": "Service", "services": "Service", "svc": "Service",
+	"ingress": "Ingress", "ingresses": "Ingress", "ing": "Ingress",
+	"configmap": "ConfigMap", "configmaps": "ConfigMap", "cm": "ConfigMap",
+	"secret": "Secret", "secrets": "Secret",
+	"persistentvolumeclaim": "PersistentVolumeClaim", "persistentvolumeclaims": "PersistentVolumeClaim", "pvc": "PersistentVolumeClaim",
+	"persistentvolume": "PersistentVolume", "persistentvolumes": "PersistentVolume", "pv": "PersistentVolume",
+	"node": "Node", "nodes": "Node", "no": "Node",
+	"namespace": "Namespace", "namespaces": "Namespace", "ns": "Namespace",
+	"serviceaccount": "ServiceAccount", "serviceaccounts": "ServiceAccount", "sa": "ServiceAccount",
+}
+
+// clusterScopedKinds are kinds that have no namespace.
+var clusterScopedKinds = map[string]bool{"Node": true, "Namespace": true, "PersistentVolume": true}
+
+var (
+	// kind/name, as in "deployment/nginx"
+	slashResourceRe = regexp.MustCompile(`\b([A-Za-z]+)/([a-z0-9](?:[-a-z0-9.]*[a-z0-9])?)\b`)
+	// kubectl <verb> kind name, as in "kubectl describe pod nginx-abc"
+	kubectlResourceRe = regexp.MustCompile(`kubectl\s+(?:get|describe|edit|delete|logs|label|annotate|scale)\s+([A-Za-z]+)\s+([a-z0-9](?:[-a-z0-9.]*[a-z0-9])?)\b`)
+	// kind `name`, as in "the pod `nginx-abc` is crashing"
+	quotedResourceRe = regexp.MustCompile("(?i)\\b([a-z]+)\\s+`([a-z0-9](?:[-a-z0-9.]*[a-z0-9])?)`")
+	namespaceFlagRe  = regexp.MustCompile(`(?:-n|--namespace)[= ]([a-z0-9](?:[-a-z0-9]*[a-z0-9])?)\b`)
+	namespaceTextRe  = regexp.MustCompile("(?i)namespace\\s+`([a-z0-9](?:[-a-z0-9]*[a-z0-9])?)`|`([a-z0-9](?:[-a-z0-9]*[a-z0-9])?)`\\s+namespace")
+)
+
+// resourceRef identifies a Kubernetes object mentioned in the conversation.
+type resourceRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (r resourceRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + " " + r.Name
+	}
+	return r.Kind + " " + r.Namespace + "/" + r.Name
+}
+
+// detectResources finds the Kubernetes objects mentioned in text. The namespace of a
+// reference is taken from a -n flag or "namespace `x`" on the same line, if any.
+func detectResources(text string) []resourceRef {
+	var refs []resourceRef
+	for _, line := range strings.Split(text, "\n") {
+		namespace := ""
+		if m := namespaceFlagRe.FindStringSubmatch(line); m != nil {
+			namespace = m[1]
+		} else if m := namespaceTextRe.FindStringSubmatch(line); m != nil {
+			namespace = m[1] + m[2]
+		}
+		for _, re := range []*regexp.Regexp{slashResourceRe, kubectlResourceRe, quotedResourceRe} {
+			for _, m := range re.FindAllStringSubmatch(line, -1) {
+				kind, ok := resourceKinds[strings.ToLower(m[1])]
+				if !ok {
+					continue
+				}
+				ref := resourceRef{Kind: kind, Name: m[2]}
+				if !clusterScopedKinds[kind] {
+					ref.Namespace = namespace
+				}
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// inspectorTab is one of the views of a resource.
+type inspectorTab int
+
+const (
+	inspectorYAML inspectorTab = iota
+	inspectorDescribe
+	inspectorEvents
+)
+
+var inspectorTabNames = []string{"yaml", "describe", "events"}
+
+// inspectorResultMsg carries kubectl output fetched for the inspector.
+type inspectorResultMsg struct {
+	ref    resourceRef
+	tab    inspectorTab
+	output string
+}
+
+// resourceInspector is a side panel, opened with ctrl+r, that shows the YAML,
+// describe output and events of objects mentioned in the conversation.
+type resourceInspector struct {
+	open bool
+	// kubectl runs kubectl in a namespace like the tools of the agent, see agent.RunKubectl
+	kubectl kubectlRunner
+	refs    []resourceRef
+	cursor  int
+	// selected is the resource being shown, nil while picking one from the list
+	selected *resourceRef
+	tab      inspectorTab
+	outputs  map[inspectorTab]string
+	viewport viewport.Model
+}
+
+// kubectlRunner runs kubectl with args in namespace and returns its output.
+type kubectlRunner func(ctx context.Context, namespace string, args ...string) (string, error)
+
+func newResourceInspector(kubectl kubectlRunner) resourceInspector {
+	return resourceInspector{
+		kubectl:  kubectl,
+		outputs:  make(map[inspectorTab]string),
+		viewport: viewport.New(0, 0),
+	}
+}
+
+// scan collects the resources mentioned in the session, most recent first.
+func (i *resourceInspector) scan(messages []*api.Message) {
+	seen := make(map[resourceRef]bool)
+	i.refs = nil
+	for idx := len(messages) - 1; idx >= 0 && len(i.refs) < maxInspectorResources; idx-- {
+		text, ok := messages[idx].Payload.(string)
+		if !ok {
+			continue
+		}
+		switch messages[idx].Type {
+		case api.MessageTypeText, api.MessageTypeToolCallRequest:
+		default:
+			continue
+		}
+		for _, ref := range detectResources(text) {
+			if !seen[ref] && len(i.refs) < maxInspectorResources {
+				seen[ref] = true
+				i.refs = append(i.refs, ref)
+			}
+		}
+	}
+	if i.cursor >= len(i.refs) {
+		i.cursor = max(len(i.refs)-1, 0)
+	}
+}
+
+func (i *resourceInspector) toggle(messages []*api.Message) {
+	i.open = !i.open
+	if i.open {
+		i.scan(messages)
+	}
+}
+
+func (i *resourceInspector) setSize(width, height int) {
+	i.viewport.Width = width - inspectorStyle.GetHorizontalFrameSize()
+	// Leave room for the title and tabs
+	i.viewport.Height = max(height-2, 1)
+}
+
+// update handles a key press while the inspector has focus.
+func (i *resourceInspector) update(msg tea.KeyMsg) tea.Cmd {
+	if i.selected == nil {
+		switch msg.String() {
+		case "esc", "ctrl+r":
+			i.open = false
+		case "up", "k":
+			if i.cursor > 0 {
+				i.cursor--
+			}
+		case "down", "j":
+			if i.cursor < len(i.refs)-1 {
+				i.cursor++
+			}
+		case "enter":
+			if i.cursor < len(i.refs) {
+				ref := i.refs[i.cursor]
+				i.selected = &ref
+				i.outputs = make(map[inspectorTab]string)
+				return i.show(inspectorYAML)
+			}
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		i.selected = nil
+	case "ctrl+r":
+		i.open = false
+	case "tab":
+		return i.show((i.tab + 1) % inspectorTab(len(inspectorTabNames)))
+	case "shift+tab":
+		return i.show((i.tab + inspectorTab(len(inspectorTabNames)) - 1) % inspectorTab(len(inspectorTabNames)))
+	case "r":
+		delete(i.outputs, i.tab)
+		return i.show(i.tab)
+	default:
+		var cmd tea.Cmd
+		i.viewport, cmd = i.viewport.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// show switches to a tab, fetching its content if it has not been fetched yet.
+func (i *resourceInspector) show(tab inspectorTab) tea.Cmd {
+	i.tab = tab
+	if output, ok := i.outputs[tab]; ok {
+		i.viewport.SetContent(output)
+		i.viewport.GotoTop()
+		return nil
+	}
+	i.viewport.SetContent("Loading...")
+	return fetchResource(i.kubectl, *i.selected, tab)
+}
+
+// handleResult stores fetched output, ignoring results for a resource no longer shown.
+func (i *resourceInspector) handleResult(msg inspectorResultMsg) {
+	if i.selected == nil || *i.selected != msg.ref {
+		return
+	}
+	i.outputs[msg.tab] = msg.output
+	if msg.tab == i.tab {
+		i.viewport.SetContent(msg.output)
+		i.viewport.GotoTop()
+	}
+}
+
+// fetchResource runs kubectl for one tab of a resource in the background.
+func fetchResource(kubectl kubectlRunner, ref resourceRef, tab inspectorTab) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), inspectorTimeout)
+		defer cancel()
+
+		var args []string
+		switch tab {
+		case inspectorYAML:
+			args = []string{"get", strings.ToLower(ref.Kind), ref.Name, "-o", "yaml"}
+		case inspectorDescribe:
+			args = []string{"describe", strings.ToLower(ref.Kind), ref.Name}
+		case inspectorEvents:
+			args = []string{"get", "events", "--sort-by=.lastTimestamp",
+				"--field-selector", fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", ref.Kind, ref.Name)}
+		}
+
+		output, err := kubectl(ctx, ref.Namespace, args...)
+		if err == nil && tab == inspectorYAML {
+			// Never show the data of Secrets, or other secrets the YAML holds
+			output = redact.String(output)
+		}
+		if err != nil {
+			output = strings.TrimSpace(fmt.Sprintf("%v\n%s", err, output))
+		}
+		return inspectorResultMsg{ref: ref, tab: tab, output: output}
+	}
+}
+
+func (i *resourceInspector) view(width, height int) string {
+	contentWidth := width - inspectorStyle.GetHorizontalFrameSize()
+	line := lipgloss.NewStyle().MaxWidth(contentWidth)
+
+	var b strings.Builder
+	if i.selected == nil {
+		b.WriteString(sidebarTitleStyle.Render("Resources in this conversation") + "\n")
+		if len(i.refs) == 0 {
+			b.WriteString(sidebarDimStyle.Render("No Kubernetes objects mentioned yet.") + "\n")
+		}
+		for idx, ref := range i.refs {
+			text := "  " + ref.String()
+			if idx == i.cursor {
+				text = sidebarSelectedStyle.Render("> " + ref.String())
+			}
+			b.WriteString(line.Render(text) + "\n")
+		}
+		b.WriteString(sidebarDimStyle.Render("enter: inspect " + glyphs.separator + " esc: close"))
+	} else {
+		b.WriteString(line.Render(sidebarTitleStyle.Render(i.selected.String())) + "\n")
+		var tabs []string
+		for tab, name := range inspectorTabNames {
+			if inspectorTab(tab) == i.tab {
+				tabs = append(tabs, inspectorActiveStyle.Render(name))
+			} else {
+				tabs = append(tabs, inspectorTabStyle.Render(name))
+			}
+		}
+		b.WriteString(strings.Join(tabs, " ") + inspectorTabStyle.Render("  tab "+glyphs.separator+" r refresh "+glyphs.separator+" esc back") + "\n")
+		b.WriteString(i.viewport.View())
+	}
+
+	return inspectorStyle.Width(contentWidth).Height(height).MaxHeight(height).Render(b.String())
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/redact"
+)
+
+func TestDetectResources(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []resourceRef
+	}{
+		{
+			name: "kubectl command with namespace flag",
+			text: "kubectl describe pod nginx-7d9f -n web",
+			want: []resourceRef{{Kind: "Pod", Namespace: "web", Name: "nginx-7d9f"}},
+		},
+		{
+			name: "kind/name",
+			text: "kubectl rollout restart deployment/frontend --namespace=shop",
+			want: []resourceRef{{Kind: "Deployment", Namespace: "shop", Name: "frontend"}},
+		},
+		{
+			name: "quoted name in prose",
+			text: "The service `api` in namespace `prod` has no endpoints.",
+			want: []resourceRef{{Kind: "Service", Namespace: "prod", Name: "api"}, {Kind: "Namespace", Name: "prod"}},
+		},
+		{
+			name: "cluster scoped kind ignores namespace",
+			text: "kubectl get node worker-1 -n default",
+			want: []resourceRef{{Kind: "Node", Name: "worker-1"}},
+		},
+		{
+			name: "unknown kinds and prose are ignored",
+			text: "apiVersion apps/v1 means the pod is running",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectResources(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectResources(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchResourceRedactsSecrets(t *testing.T) {
+	var gotNamespace string
+	var gotArgs []string
+	kubectl := func(ctx context.Context, namespace string, args ...string) (string, error) {
+		gotNamespace, gotArgs = namespace, args
+		return `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"data":{"db-pass":"aHVudGVyMg=="}}'
+data:
+  db-pass: aHVudGVyMg==
+`, nil
+	}
+
+	ref := resourceRef{Kind: "Secret", Namespace: "dev", Name: "db"}
+	msg := fetchResource(kubectl, ref, inspectorYAML)().(inspectorResultMsg)
+	if gotNamespace != "dev" || !reflect.DeepEqual(gotArgs, []string{"get", "secret", "db", "-o", "yaml"}) {
+		t.Errorf("kubectl ran in %q with %v", gotNamespace, gotArgs)
+	}
+	if strings.Contains(msg.output, "aHVudGVyMg==") {
+		t.Errorf("secret data is shown:\n%s", msg.output)
+	}
+	if !strings.Contains(msg.output, "db-pass: "+redact.Placeholder) {
+		t.Errorf("secret keys are not shown:\n%s", msg.output)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paletteMaxVisible is the number of palette entries shown at once.
+const paletteMaxVisible = 8
+
+// Styles depending on the theme, set by applyTheme
+var (
+	paletteStyle         lipgloss.Style
+	paletteSelectedStyle lipgloss.Style
+	completionStyle      lipgloss.Style
+)
+
+// paletteEntry is an action offered by the command palette.
+type paletteEntry struct {
+	title       string
+	description string
+	// query is sent to the agent when the entry is chosen.
+	query string
+	// insert replaces the input when the entry is chosen, for entries that need more typing.
+	insert string
+}
+
+// commandPalette is a filterable list of meta commands and tools, opened with ctrl+p.
+type commandPalette struct {
+	open    bool
+	filter  textinput.Model
+	entries []paletteEntry
+	cursor  int
+}
+
+func newCommandPalette(a *agent.Agent) commandPalette {
+	filter := textinput.New()
+	filter.Placeholder = "Type to search commands and tools..."
+	filter.Prompt = "> "
+
+	var entries []paletteEntry
+	for _, cmd := range agent.MetaCommands() {
+		entry := paletteEntry{
+			title:       "/" + cmd.Name,
+			description: cmd.Description,
+		}
+		if cmd.Args != "" {
+			entry.title += " " + cmd.Args
+			entry.insert = "/" + cmd.Name + " "
+		} else {
+			entry.query = "/" + cmd.Name
+		}
+		entries = append(entries, entry)
+	}
+	for _, name := range toolNames(a) {
+		description := ""
+		if tool := a.Tools.Lookup(name); tool != nil {
+			description, _, _ = strings.Cut(tool.Description(), "\n")
+		}
+		entries = append(entries, paletteEntry{
+			title:       "@" + name,
+			description: description,
+			insert:      "@" + name + " ",
+		})
+	}
+
+	return commandPalette{filter: filter, entries: entries}
+}
+
+func toolNames(a *agent.Agent) []string {
+	if a == nil {
+		return nil
+	}
+	return a.Tools.Names()
+}
+
+func (p *commandPalette) show() tea.Cmd {
+	p.open = true
+	p.cursor = 0
+	p.filter.Reset()
+	return p.filter.Focus()
+}
+
+func (p *commandPalette) hide() {
+	p.open = false
+	p.filter.Blur()
+}
+
+// matches returns the entries whose title contains the filter as a subsequence.
+func (p *commandPalette) matches() []paletteEntry {
+	query := strings.ToLower(strings.TrimSpace(p.filter.Value()))
+	if query == "" {
+		return p.entries
+	}
+	var matched []paletteEntry
+	for _, entry := range p.entries {
+		if isSubsequence(query, strings.ToLower(entry.title)) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// isSubsequence reports whether all runes of needle appear in haystack, in order.
+func isSubsequence(needle, haystack string) bool {
+	remaining := []rune(needle)
+	for _, r := range haystack {
+		if len(remaining) == 0 {
+			break
+		}
+		if r == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
+
+// update handles a key press while the palette is open. It returns the chosen entry, if any.
+func (p *commandPalette) update(msg tea.KeyMsg) (*paletteEntry, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+p", "ctrl+c":
+		p.hide()
+		return nil, nil
+	case "up", "shift+tab":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return nil, nil
+	case "down", "tab":
+		if p.cursor < len(p.matches())-1 {
+			p.cursor++
+		}
+		return nil, nil
+	case "enter":
+		matches := p.matches()
+		p.hide()
+		if p.cursor < len(matches) {
+			return &matches[p.cursor], nil
+		}
+		return nil, nil
+	}
+
+	var cmd tea.Cmd
+	p.filter, cmd = p.filter.Update(msg)
+	p.cursor = 0
+	return nil, cmd
+}
+
+func (p *commandPalette) view(width int) string {
+	matches := p.matches()
+
+	// Keep the cursor in view
+	start := 0
+	if p.cursor >= paletteMaxVisible {
+		start = p.cursor - paletteMaxVisible + 1
+	}
+	end := min(start+paletteMaxVisible, len(matches))
+
+	var b strings.Builder
+	b.WriteString(p.filter.View())
+	b.WriteString("\n")
+	if len(matches) == 0 {
+		b.WriteString(completionStyle.Render("  no matches"))
+	}
+	for i := start; i < end; i++ {
+		line := fmt.Sprintf("%-24s %s", matches[i].title, completionStyle.Render(matches[i].description))
+		if i == p.cursor {
+			line = paletteSelectedStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(lipgloss.NewStyle().MaxWidth(width-paletteStyle.GetHorizontalFrameSize()).Render(line) + "\n")
+	}
+	b.WriteString(completionStyle.Render("enter: run " + glyphs.separator + " esc: close"))
+
+	style := paletteStyle
+	if w := width - style.GetHorizontalFrameSize(); w > 0 {
+		style = style.Width(w)
+	}
+	return style.Render(b.String())
+}
+
+// inputCompletions returns completions for the text being typed: meta commands after a
+// leading "/", or tool names after "@".
+func inputCompletions(input string, tools []string) []string {
+	if strings.HasPrefix(input, "/") && !strings.Contains(input, " ") {
+		var completions []string
+		for _, cmd := range agent.MetaCommands() {
+			if name := "/" + cmd.Name; strings.HasPrefix(name, input) {
+				completions = append(completions, name)
+			}
+		}
+		return completions
+	}
+
+	lastSpace := strings.LastIndexAny(input, " \t")
+	word := input[lastSpace+1:]
+	if !strings.HasPrefix(word, "@") {
+		return nil
+	}
+	var completions []string
+	for _, name := range tools {
+		if candidate := "@" + name; strings.HasPrefix(candidate, word) {
+			completions = append(completions, candidate)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// completeInput completes the last word of input to the longest common prefix of completions.
+func completeInput(input string, completions []string) string {
+	if len(completions) == 0 {
+		return input
+	}
+	prefix := completions[0]
+	for _, c := range completions[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(completions) == 1 {
+		prefix += " "
+	}
+	lastSpace := strings.LastIndexAny(input, " \t")
+	return input[:lastSpace+1] + prefix
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/tools"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testPalette() commandPalette {
+	a := &agent.Agent{}
+	a.Tools.Init()
+	a.Tools.RegisterTool(tools.NewKubectlTool(nil))
+	return newCommandPalette(a)
+}
+
+func typeKeys(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestCommandPaletteMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{name: "tool", filter: "@kub", want: []string{"@kubectl"}},
+		{name: "subsequence", filter: "clea", want: []string{"/clear"}},
+		{name: "case insensitive", filter: "CLEAR", want: []string{"/clear"}},
+		{name: "arguments are part of the title", filter: "session_id", want: []string{"/resume-session <session_id>"}},
+		{name: "surrounding spaces", filter: "  models ", want: []string{"/models"}},
+		{name: "no matches", filter: "zzz", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPalette()
+			p.filter.SetValue(tt.filter)
+			var got []string
+			for _, entry := range p.matches() {
+				got = append(got, entry.title)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matches(%q) = %q, want %q", tt.filter, got, tt.want)
+			}
+		})
+	}
+
+	p := testPalette()
+	if got, want := len(p.matches()), len(agent.MetaCommands())+1; got != want {
+		t.Errorf("matches() without filter = %d entries, want all %d", got, want)
+	}
+}
+
+func TestCommandPaletteUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []tea.KeyMsg
+		wantCursor int
+		wantOpen   bool
+		// wantChosen is the title of the chosen entry, if any
+		wantChosen string
+	}{
+		{name: "down and up", keys: []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyUp}}, wantCursor: 1, wantOpen: true},
+		{name: "up at the top", keys: []tea.KeyMsg{{Type: tea.KeyUp}}, wantCursor: 0, wantOpen: true},
+		{name: "down at the bottom", keys: []tea.KeyMsg{typeKeys("exit"), {Type: tea.KeyDown}, {Type: tea.KeyDown}}, wantCursor: 0, wantOpen: true},
+		{name: "typing resets the cursor", keys: []tea.KeyMsg{{Type: tea.KeyDown}, typeKeys("s")}, wantCursor: 0, wantOpen: true},
+		{name: "enter chooses the selected entry", keys: []tea.KeyMsg{typeKeys("model"), {Type: tea.KeyDown}, {Type: tea.KeyEnter}}, wantChosen: "/models"},
+		{name: "enter without matches", keys: []tea.KeyMsg{typeKeys("zzz"), {Type: tea.KeyEnter}}},
+		{name: "escape closes", keys: []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyEsc}}, wantCursor: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPalette()
+			p.show()
+			var chosen *paletteEntry
+			for _, key := range tt.keys {
+				chosen, _ = p.update(key)
+			}
+			if p.open != tt.wantOpen {
+				t.Errorf("open = %v, want %v", p.open, tt.wantOpen)
+			}
+			if tt.wantOpen && p.cursor != tt.wantCursor {
+				t.Errorf("cursor = %d, want %d", p.cursor, tt.wantCursor)
+			}
+			if chosen == nil && tt.wantChosen != "" || chosen != nil && chosen.title != tt.wantChosen {
+				t.Errorf("chosen = %+v, want %q", chosen, tt.wantChosen)
+			}
+		})
+	}
+}
+
+func TestCommandPaletteExecute(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		// wantQuery is sent to the agent, wantInput replaces the input
+		wantQuery string
+		wantInput string
+	}{
+		{name: "command", filter: "clea", wantQuery: "/clear"},
+		{name: "command with arguments", filter: "resume", wantInput: "/resume-session "},
+		{name: "tool", filter: "@kub", wantInput: "@kubectl "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			m.agent.Input = make(chan any, 1)
+			m.textarea = textarea.New()
+			m.palette = testPalette()
+
+			var updated tea.Model = m
+			for _, key := range []tea.KeyMsg{{Type: tea.KeyCtrlP}, typeKeys(tt.filter), {Type: tea.KeyEnter}} {
+				updated, _ = updated.Update(key)
+			}
+			m = updated.(model)
+
+			if m.palette.open {
+				t.Errorf("the palette is still open")
+			}
+			if got := m.textarea.Value(); got != tt.wantInput {
+				t.Errorf("input = %q, want %q", got, tt.wantInput)
+			}
+			select {
+			case input := <-m.agent.Input:
+				if got := input.(*api.UserInputResponse).Query; got != tt.wantQuery {
+					t.Errorf("query = %q, want %q", got, tt.wantQuery)
+				}
+			default:
+				if tt.wantQuery != "" {
+					t.Errorf("no query sent, want %q", tt.wantQuery)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// replayListWidth is the width of the list of steps, borders excluded.
+const replayListWidth = 36
+
+// ReplayTUI is a read-only viewer of a recorded session, stepping through its
+// messages and tool calls.
+type ReplayTUI struct {
+	program *tea.Program
+}
+
+// NewReplayTUI creates a viewer for the steps of a session.
+func NewReplayTUI(sessionID string, steps []ReplayStep, theme Theme) *ReplayTUI {
+	applyTheme(theme)
+	return &ReplayTUI{
+		program: tea.NewProgram(newReplayModel(sessionID, steps), tea.WithAltScreen()),
+	}
+}
+
+func (u *ReplayTUI) Run() error {
+	_, err := u.program.Run()
+	return err
+}
+
+type replayModel struct {
+	sessionID string
+	steps     []ReplayStep
+	current   int
+	viewport  viewport.Model
+	width     int
+	height    int
+}
+
+func newReplayModel(sessionID string, steps []ReplayStep) replayModel {
+	return replayModel{
+		sessionID: sessionID,
+		steps:     steps,
+		viewport:  viewport.New(80, 20),
+	}
+}
+
+func (m replayModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m replayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.viewport.Width = max(msg.Width-replayListWidth-3, 20)
+		m.viewport.Height = max(msg.Height-4, 5)
+		m.showStep()
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "n", "right", "l", "tab":
+			m.moveTo(m.current + 1)
+			return m, nil
+		case "p", "left", "h", "shift+tab":
+			m.moveTo(m.current - 1)
+			return m, nil
+		case "g", "home":
+			m.moveTo(0)
+			return m, nil
+		case "G", "end":
+			m.moveTo(len(m.steps) - 1)
+			return m, nil
+		}
+	}
+
+	// Other keys scroll the body of the step
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m *replayModel) moveTo(i int) {
+	if i < 0 || i >= len(m.steps) || i == m.current {
+		return
+	}
+	m.current = i
+	m.showStep()
+}
+
+// showStep shows the body of the current step in the viewport.
+func (m *replayModel) showStep() {
+	if len(m.steps) == 0 {
+		m.viewport.SetContent("This journal has no recorded messages or tool calls.")
+		return
+	}
+	body := m.steps[m.current].Body
+	m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(body))
+	m.viewport.GotoTop()
+}
+
+func (m replayModel) View() string {
+	sep := " " + glyphs.separator + " "
+	header := fmt.Sprintf("Replay of %s", m.sessionID)
+	if len(m.steps) > 0 {
+		step := m.steps[m.current]
+		header += sep + fmt.Sprintf("step %d/%d", m.current+1, len(m.steps)) + sep + formatReplayTiming(step)
+	}
+	help := completionStyle.Render("n/p: next/previous step" + sep + "g/G: first/last" + sep + "up/down: scroll" + sep + "q: quit")
+
+	body := m.viewport.View()
+	if len(m.steps) > 0 {
+		body = sidebarCurrentStyle.Render(m.steps[m.current].Title) + "\n\n" + body
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		lipgloss.JoinHorizontal(lipgloss.Top, m.stepList(), body),
+		help,
+	)
+}
+
+// stepList renders the titles of the steps around the current one.
+func (m replayModel) stepList() string {
+	height := max(m.viewport.Height, 5)
+	first := max(min(m.current-height/2, len(m.steps)-height), 0)
+	last := min(first+height, len(m.steps))
+
+	var lines []string
+	for i := first; i < last; i++ {
+		step := m.steps[i]
+		marker := "  "
+		if step.Kind != ReplayStepMessage {
+			marker = glyphs.collapsed + " "
+		}
+		line := ansi.Truncate(marker+strings.ReplaceAll(step.Title, "\n", " "), replayListWidth, glyphs.ellipsis)
+		switch {
+		case i == m.current:
+			line = sidebarSelectedStyle.Render(line)
+		case step.Kind == ReplayStepLLMCall:
+			line = sidebarDimStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return sidebarStyle.Width(replayListWidth).Height(height).Render(strings.Join(lines, "\n"))
+}
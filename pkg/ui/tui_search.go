@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchMatchStyle marks search matches. Reverse video stays visible without colors.
+var searchMatchStyle = lipgloss.NewStyle().Reverse(true)
+
+// chatSearch finds text in the conversation, opened with ctrl+f. Matching lines are
+// highlighted and the viewport jumps between them, newest first. Tool outputs with
+// matches are expanded while searching so that hidden lines can be found too.
+type chatSearch struct {
+	active bool
+	input  textinput.Model
+	// matches are the viewport lines containing the query, and current indexes the
+	// one scrolled to.
+	matches []int
+	current int
+	// expanded holds the tool outputs expanded by the search, collapsed when it ends.
+	expanded []string
+}
+
+func newChatSearch() chatSearch {
+	input := textinput.New()
+	input.Prompt = "search: "
+	input.CharLimit = 100
+	return chatSearch{input: input}
+}
+
+// query returns the lower-cased search text.
+func (s *chatSearch) query() string {
+	return strings.ToLower(s.input.Value())
+}
+
+// highlight records the lines of content matching the query and marks the matches.
+// Matching lines lose their other styling, which cannot be combined with the marks.
+func (s *chatSearch) highlight(content string) string {
+	s.matches = nil
+	query := s.query()
+	if query == "" {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		lower := strings.ToLower(plain)
+		if !strings.Contains(lower, query) {
+			continue
+		}
+		s.matches = append(s.matches, i)
+		if len(lower) != len(plain) {
+			// Lower-casing changed the byte offsets; mark the whole line instead
+			lines[i] = searchMatchStyle.Render(plain)
+			continue
+		}
+		var b strings.Builder
+		for {
+			idx := strings.Index(lower, query)
+			if idx < 0 {
+				break
+			}
+			b.WriteString(plain[:idx])
+			b.WriteString(searchMatchStyle.Render(plain[idx : idx+len(query)]))
+			plain, lower = plain[idx+len(query):], lower[idx+len(query):]
+		}
+		b.WriteString(plain)
+		lines[i] = b.String()
+	}
+	if s.current < 0 || s.current >= len(s.matches) {
+		s.current = len(s.matches) - 1
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *chatSearch) view() string {
+	sep := " " + glyphs.separator + " "
+	help := "enter/up: older" + sep + "down: newer" + sep + "esc: close"
+	switch {
+	case s.query() == "":
+	case len(s.matches) == 0:
+		help = "no matches" + sep + help
+	default:
+		help = fmt.Sprintf("%d/%d", s.current+1, len(s.matches)) + sep + help
+	}
+	return s.input.View() + "  " + completionStyle.Render(help)
+}
+
+func (m *model) startSearch() tea.Cmd {
+	m.search.active = true
+	m.search.input.Reset()
+	m.search.matches = nil
+	return m.search.input.Focus()
+}
+
+func (m *model) endSearch() {
+	m.search.active = false
+	m.search.input.Blur()
+	for _, id := range m.search.expanded {
+		delete(m.expandedTools, id)
+	}
+	m.search.expanded = nil
+	m.setContent(strings.Join(m.renderedMessages(), "\n"))
+	m.viewport.GotoBottom()
+}
+
+// updateSearch handles a key press while searching.
+func (m *model) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "ctrl+f", "ctrl+c":
+		m.endSearch()
+		return nil
+	case "enter", "up", "shift+tab":
+		if m.search.current > 0 {
+			m.search.current--
+		}
+		m.scrollToMatch()
+		return nil
+	case "down", "tab":
+		if m.search.current < len(m.search.matches)-1 {
+			m.search.current++
+		}
+		m.scrollToMatch()
+		return nil
+	}
+
+	previous := m.search.input.Value()
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	if m.search.input.Value() != previous {
+		m.expandMatchingTools()
+		m.setContent(strings.Join(m.renderedMessages(), "\n"))
+		m.search.current = len(m.search.matches) - 1
+		m.scrollToMatch()
+	}
+	return cmd
+}
+
+// expandMatchingTools expands the tool outputs containing the query, so that matches
+// in their collapsed lines are shown.
+func (m *model) expandMatchingTools() {
+	query := m.search.query()
+	if query == "" {
+		return
+	}
+	for _, message := range m.agent.GetSession().AllMessages() {
+		if message.Type != api.MessageTypeToolCallResponse || m.expandedTools[message.ID] {
+			continue
+		}
+		output, err := toolOutputText(message)
+		if err != nil || !strings.Contains(strings.ToLower(output), query) {
+			continue
+		}
+		m.expandedTools[message.ID] = true
+		m.search.expanded = append(m.search.expanded, message.ID)
+	}
+}
+
+// scrollToMatch centers the current match in the viewport.
+func (m *model) scrollToMatch() {
+	if m.search.current < 0 || m.search.current >= len(m.search.matches) {
+		return
+	}
+	m.viewport.SetYOffset(max(m.search.matches[m.search.current]-m.viewport.Height/2, 0))
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestChatSearchHighlight(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		content     string
+		wantMatches []int
+	}{
+		{
+			name:        "empty query",
+			query:       "",
+			content:     "pod/nginx\npod/redis",
+			wantMatches: nil,
+		},
+		{
+			name:        "case insensitive",
+			query:       "NGINX",
+			content:     "AI: pods\npod/nginx Running\nservice/Nginx",
+			wantMatches: []int{1, 2},
+		},
+		{
+			name:        "styled lines",
+			query:       "crashloop",
+			content:     "\x1b[31mCrashLoopBackOff\x1b[0m\nRunning",
+			wantMatches: []int{0},
+		},
+		{
+			name:        "no matches",
+			query:       "etcd",
+			content:     "pod/nginx",
+			wantMatches: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newChatSearch()
+			s.input.SetValue(tt.query)
+			got := s.highlight(tt.content)
+
+			if !reflect.DeepEqual(s.matches, tt.wantMatches) {
+				t.Errorf("matches = %v, want %v", s.matches, tt.wantMatches)
+			}
+			if len(s.matches) > 0 && (s.current < 0 || s.current >= len(s.matches)) {
+				t.Errorf("current = %d, out of range of %d matches", s.current, len(s.matches))
+			}
+			// Highlighting must not change the text itself
+			if ansi.Strip(got) != ansi.Strip(tt.content) {
+				t.Errorf("highlighted text = %q, want %q", ansi.Strip(got), ansi.Strip(tt.content))
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"k8s.io/klog/v2"
+)
+
+// sidebarWidth is the width of the sessions sidebar, including its border.
+const sidebarWidth = 32
+
+var sidebarTitleStyle = lipgloss.NewStyle().Bold(true)
+
+// Styles depending on the theme, set by applyTheme
+var (
+	sidebarStyle         lipgloss.Style
+	sidebarCurrentStyle  lipgloss.Style
+	sidebarSelectedStyle lipgloss.Style
+	sidebarDimStyle      lipgloss.Style
+)
+
+type sidebarMode int
+
+const (
+	sidebarBrowsing sidebarMode = iota
+	sidebarSearching
+	sidebarRenaming
+)
+
+// sessionSidebar lists the saved sessions, opened with ctrl+s. Sessions can be
+// renamed (r), pinned (p), searched (/) and resumed (enter).
+type sessionSidebar struct {
+	open     bool
+	mode     sidebarMode
+	manager  *sessions.SessionManager
+	sessions []*api.Session
+	cursor   int
+	search   string
+	input    textinput.Model
+	err      error
+}
+
+func newSessionSidebar(backend string) sessionSidebar {
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = 100
+
+	s := sessionSidebar{input: input}
+	manager, err := sessions.NewSessionManager(backend)
+	if err != nil {
+		klog.Errorf("Failed to create session manager for the sidebar: %v", err)
+		s.err = err
+	}
+	s.manager = manager
+	return s
+}
+
+// refresh reloads the sessions, pinned ones first and then most recently modified.
+func (s *sessionSidebar) refresh() {
+	if s.manager == nil {
+		return
+	}
+	list, err := s.manager.ListSessions()
+	if err != nil {
+		s.err = err
+		return
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Pinned != list[j].Pinned {
+			return list[i].Pinned
+		}
+		return list[i].LastModified.After(list[j].LastModified)
+	})
+	s.sessions = list
+	s.err = nil
+	s.clampCursor()
+}
+
+// visible returns the sessions matching the search.
+func (s *sessionSidebar) visible() []*api.Session {
+	query := strings.ToLower(s.search)
+	if s.mode == sidebarSearching {
+		query = strings.ToLower(s.input.Value())
+	}
+	if query == "" {
+		return s.sessions
+	}
+	var matched []*api.Session
+	for _, session := range s.sessions {
+		if isSubsequence(query, strings.ToLower(session.Name+" "+session.ID)) {
+			matched = append(matched, session)
+		}
+	}
+	return matched
+}
+
+func (s *sessionSidebar) selected() *api.Session {
+	visible := s.visible()
+	if s.cursor < len(visible) {
+		return visible[s.cursor]
+	}
+	return nil
+}
+
+func (s *sessionSidebar) clampCursor() {
+	if n := len(s.visible()); s.cursor >= n {
+		s.cursor = max(n-1, 0)
+	}
+}
+
+func (s *sessionSidebar) toggle() {
+	s.open = !s.open
+	s.mode = sidebarBrowsing
+	s.input.Blur()
+	if s.open {
+		s.refresh()
+	}
+}
+
+// update handles a key press while the sidebar has focus. It returns the ID of a
+// session the user chose to resume, if any.
+func (s *sessionSidebar) update(msg tea.KeyMsg) (string, tea.Cmd) {
+	switch s.mode {
+	case sidebarSearching, sidebarRenaming:
+		switch msg.String() {
+		case "esc":
+			s.mode = sidebarBrowsing
+			s.input.Blur()
+			return "", nil
+		case "enter":
+			if s.mode == sidebarSearching {
+				s.search = s.input.Value()
+			} else if session := s.selected(); session != nil && s.manager != nil {
+				if name := strings.TrimSpace(s.input.Value()); name != "" {
+					if err := s.manager.RenameSession(session.ID, name); err != nil {
+						s.err = err
+					}
+				}
+			}
+			s.mode = sidebarBrowsing
+			s.input.Blur()
+			s.refresh()
+			return "", nil
+		}
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		if s.mode == sidebarSearching {
+			s.cursor = 0
+		}
+		return "", cmd
+	}
+
+	switch msg.String() {
+	case "esc", "ctrl+s":
+		if s.search != "" && msg.String() == "esc" {
+			s.search = ""
+			return "", nil
+		}
+		s.toggle()
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.visible())-1 {
+			s.cursor++
+		}
+	case "/":
+		s.mode = sidebarSearching
+		s.input.SetValue(s.search)
+		return "", s.input.Focus()
+	case "r":
+		if session := s.selected(); session != nil {
+			s.mode = sidebarRenaming
+			s.input.SetValue(session.Name)
+			return "", s.input.Focus()
+		}
+	case "p":
+		if session := s.selected(); session != nil && s.manager != nil {
+			if err := s.manager.SetSessionPinned(session.ID, !session.Pinned); err != nil {
+				s.err = err
+			}
+			s.refresh()
+		}
+	case "enter":
+		if session := s.selected(); session != nil {
+			return session.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *sessionSidebar) view(height int, currentSessionID string) string {
+	width := sidebarWidth - sidebarStyle.GetHorizontalFrameSize()
+	line := lipgloss.NewStyle().MaxWidth(width)
+
+	var lines []string
+	lines = append(lines, sidebarTitleStyle.Render("Sessions"))
+	switch {
+	case s.mode == sidebarSearching:
+		lines = append(lines, "/"+s.input.View())
+	case s.mode == sidebarRenaming:
+		lines = append(lines, "rename: "+s.input.View())
+	case s.search != "":
+		lines = append(lines, sidebarDimStyle.Render("/"+s.search+" (esc to clear)"))
+	default:
+		lines = append(lines, "")
+	}
+
+	// Leave room for the title, status line and key help
+	rows := max(height-5, 1)
+	visible := s.visible()
+	start := 0
+	if s.cursor >= rows {
+		start = s.cursor - rows + 1
+	}
+	for i := start; i < len(visible) && i < start+rows; i++ {
+		session := visible[i]
+		cursor, pin := " ", " "
+		if i == s.cursor {
+			cursor = ">"
+		}
+		if session.Pinned {
+			pin = "*"
+		}
+		text := cursor + pin + " " + session.Name
+		switch {
+		case i == s.cursor:
+			text = sidebarSelectedStyle.Render(text)
+		case session.ID == currentSessionID:
+			text = sidebarCurrentStyle.Render(text)
+		}
+		lines = append(lines, line.Render(text))
+	}
+	if len(visible) == 0 {
+		lines = append(lines, sidebarDimStyle.Render("  no sessions"))
+	}
+
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	if s.err != nil {
+		lines = append(lines, line.Render(sidebarDimStyle.Render("error: "+s.err.Error())))
+	} else {
+		lines = append(lines, "")
+	}
+	lines = append(lines, line.Render(sidebarDimStyle.Render("enter r p / esc")))
+
+	return sidebarStyle.Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// suggestDelay is how long the input must stay unchanged before a completion of the
+	// kubectl command being typed is asked for.
+	suggestDelay = 400 * time.Millisecond
+	// suggestTimeout bounds the LLM call of a suggestion.
+	suggestTimeout = 10 * time.Second
+)
+
+// suggestTickMsg fires suggestDelay after the input changed to partial.
+type suggestTickMsg struct {
+	partial string
+}
+
+// suggestResultMsg carries the completion of partial, empty if there is none.
+type suggestResultMsg struct {
+	partial    string
+	suggestion string
+}
+
+// updateSuggestion drops the suggestion when the input no longer leads to it, and schedules
+// a new one when the user pauses typing a kubectl command.
+func (m *model) updateSuggestion(previous string) tea.Cmd {
+	input := m.textarea.Value()
+	if input == previous {
+		return nil
+	}
+	if !strings.HasPrefix(m.suggestion, input) {
+		m.suggestion = ""
+	}
+	if m.suggestion != "" || !strings.Contains(input, "kubectl ") {
+		return nil
+	}
+	return tea.Tick(suggestDelay, func(time.Time) tea.Msg {
+		return suggestTickMsg{partial: input}
+	})
+}
+
+// fetchSuggestion asks the agent to complete partial, if the user has not typed on.
+func (m *model) fetchSuggestion(msg suggestTickMsg) tea.Cmd {
+	if msg.partial != m.textarea.Value() {
+		return nil
+	}
+	agent := m.agent
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), suggestTimeout)
+		defer cancel()
+		suggestion, err := agent.SuggestCommand(ctx, msg.partial)
+		if err != nil {
+			klog.V(2).Infof("suggesting a command for %q: %v", msg.partial, err)
+		}
+		return suggestResultMsg{partial: msg.partial, suggestion: suggestion}
+	}
+}
+
+// ghostText returns what the suggestion adds to the input, if it still applies.
+func (m *model) ghostText() string {
+	input := m.textarea.Value()
+	if input == "" || !strings.HasPrefix(m.suggestion, input) {
+		return ""
+	}
+	return m.suggestion[len(input):]
+}
+
+// acceptSuggestion completes the input with the suggestion, reporting whether there was one.
+func (m *model) acceptSuggestion() bool {
+	if m.ghostText() == "" {
+		return false
+	}
+	m.textarea.SetValue(m.suggestion)
+	m.suggestion = ""
+	return true
+}
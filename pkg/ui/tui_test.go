@@ -0,0 +1,160 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/agent"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/api"
+	"github.com/GoogleCloudPlatform/kubectl-ai/pkg/sessions"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// newTestModel returns a TUI model for a session holding messages.
+func newTestModel(t *testing.T, messages ...*api.Message) model {
+	t.Helper()
+	store := sessions.NewInMemoryChatStore()
+	for _, message := range messages {
+		if err := store.AddChatMessage(message); err != nil {
+			t.Fatalf("adding message: %v", err)
+		}
+	}
+	return model{
+		agent:         &agent.Agent{Session: &api.Session{ChatMessageStore: store}},
+		viewport:      viewport.New(80, 20),
+		expandedTools: make(map[string]bool),
+		renderCache:   newMessageRenderCache(),
+	}
+}
+
+func toolResponse(id string, lines int) *api.Message {
+	var output []string
+	for i := range lines {
+		output = append(output, fmt.Sprintf("line %d", i+1))
+	}
+	return &api.Message{ID: id, Source: api.MessageSourceAgent, Type: api.MessageTypeToolCallResponse, Payload: map[string]any{"stdout": strings.Join(output, "\n")}}
+}
+
+func TestRenderToolOutputCollapsible(t *testing.T) {
+	message := toolResponse("t1", toolPreviewLines+3)
+	m := newTestModel(t, message)
+
+	collapsed := ansi.Strip(m.renderMessage(message))
+	if !strings.Contains(collapsed, fmt.Sprintf("line %d", toolPreviewLines)) || strings.Contains(collapsed, fmt.Sprintf("line %d", toolPreviewLines+1)) {
+		t.Errorf("collapsed output does not show only the first %d lines:\n%s", toolPreviewLines, collapsed)
+	}
+	if want := fmt.Sprintf("%s 3 more lines", glyphs.ellipsis); !strings.Contains(collapsed, want) {
+		t.Errorf("collapsed output does not contain %q:\n%s", want, collapsed)
+	}
+	if !strings.Contains(collapsed, glyphs.collapsed+" Output (8 lines)") {
+		t.Errorf("collapsed output has no collapsed header:\n%s", collapsed)
+	}
+
+	m.expandedTools[message.ID] = true
+	expanded := ansi.Strip(m.renderMessage(message))
+	if !strings.Contains(expanded, fmt.Sprintf("line %d", toolPreviewLines+3)) || strings.Contains(expanded, "more lines") {
+		t.Errorf("expanded output does not show every line:\n%s", expanded)
+	}
+	if !strings.Contains(expanded, glyphs.expanded+" Output (8 lines)") {
+		t.Errorf("expanded output has no expanded header:\n%s", expanded)
+	}
+
+	// Short outputs are shown in full even when collapsed
+	short := toolResponse("t2", 2)
+	if rendered := ansi.Strip(m.renderMessage(short)); strings.Contains(rendered, "more lines") || !strings.Contains(rendered, "line 2") {
+		t.Errorf("short output is not shown in full:\n%s", rendered)
+	}
+}
+
+func TestSelectToolOutput(t *testing.T) {
+	m := newTestModel(t,
+		toolResponse("t1", 1),
+		&api.Message{ID: "m1", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "done"},
+		toolResponse("t2", 1),
+		toolResponse("t3", 1),
+	)
+
+	var got []string
+	// The most recent output is selected first, then the selection wraps around
+	for _, next := range []bool{true, true, true, false, false} {
+		m.selectToolOutput(next)
+		got = append(got, m.selectedTool)
+	}
+	if want := "t3,t1,t2,t1,t3"; strings.Join(got, ",") != want {
+		t.Errorf("selected tool outputs = %v, want %s", got, want)
+	}
+
+	empty := newTestModel(t)
+	empty.selectedTool = "gone"
+	empty.selectToolOutput(true)
+	if empty.selectedTool != "" {
+		t.Errorf("selected tool output = %q without any, want none", empty.selectedTool)
+	}
+}
+
+func TestMessageRenderCache(t *testing.T) {
+	c := newMessageRenderCache()
+	renders := 0
+	get := func(id string, width int) string {
+		return c.get(id, width, func() string {
+			renders++
+			return fmt.Sprintf("%s@%d", id, width)
+		})
+	}
+
+	if got := get("m1", 80); got != "m1@80" || renders != 1 {
+		t.Errorf("get() = %q after %d renders, want m1@80 after 1", got, renders)
+	}
+	if got := get("m1", 80); got != "m1@80" || renders != 1 {
+		t.Errorf("get() = %q after %d renders, want the cached m1@80", got, renders)
+	}
+
+	// Changing the width drops the messages rendered at the old one
+	if got := get("m1", 100); got != "m1@100" || renders != 2 {
+		t.Errorf("get() = %q after %d renders, want m1@100 after 2", got, renders)
+	}
+	if len(c.rendered) != 1 {
+		t.Errorf("cache holds %d messages after a width change, want 1", len(c.rendered))
+	}
+
+	for i := range 2 * maxRenderCacheEntries {
+		get(fmt.Sprint(i), 100)
+	}
+	if len(c.rendered) > maxRenderCacheEntries {
+		t.Errorf("cache holds %d messages, want at most %d", len(c.rendered), maxRenderCacheEntries)
+	}
+}
+
+func TestRenderedMessagesStreaming(t *testing.T) {
+	persisted := &api.Message{ID: "m1", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "first answer"}
+	m := newTestModel(t, persisted)
+	m.streaming = &api.Message{ID: "m2", Source: api.MessageSourceModel, Type: api.MessageTypeText, Payload: "partial"}
+
+	rendered := m.renderedMessages()
+	if len(rendered) != 2 {
+		t.Fatalf("rendered %d messages, want the persisted one and the streaming one", len(rendered))
+	}
+	if streaming := ansi.Strip(rendered[1]); !strings.Contains(streaming, "partial") || !strings.HasSuffix(streaming, " "+glyphs.cursor) {
+		t.Errorf("streaming message = %q, want it followed by a cursor", streaming)
+	}
+	// Only persisted messages are cached; the streaming one changes with every update
+	if _, ok := m.renderCache.rendered["m2"]; ok || len(m.renderCache.rendered) != 1 {
+		t.Errorf("render cache = %v, want only m1", m.renderCache.rendered)
+	}
+}